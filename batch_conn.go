@@ -0,0 +1,62 @@
+package quic
+
+import (
+	"net"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+)
+
+// A datagram is a packet read by a batchConn
+type datagram struct {
+	// data is the buffer that the packet is read into.
+	// It is resliced to the length of the packet.
+	data       []byte
+	remoteAddr net.Addr
+	ecn        protocol.ECN
+}
+
+// A batchConn reads and writes multiple packets with a single system call, if the platform supports this.
+// Reading and writing may happen concurrently, but ReadBatch and WriteBatch must not be called concurrently with themselves.
+type batchConn interface {
+	// ReadBatch reads at least one, and at most len(msgs) packets.
+	// It returns the number of packets read.
+	ReadBatch(msgs []datagram) (int, error)
+	// WriteBatch sends the packets to addr, using the ECN codepoint ecn.
+	WriteBatch(packets [][]byte, addr net.Addr, ecn protocol.ECN) error
+}
+
+// basicBatchConn is the portable fallback.
+// It reads and writes one packet per system call.
+type basicBatchConn struct {
+	conn net.PacketConn
+	oob  []byte
+}
+
+var _ batchConn = &basicBatchConn{}
+
+func newBasicBatchConn(c net.PacketConn) *basicBatchConn {
+	return &basicBatchConn{
+		conn: c,
+		oob:  make([]byte, ecnOOBSize),
+	}
+}
+
+func (c *basicBatchConn) ReadBatch(msgs []datagram) (int, error) {
+	n, addr, ecn, err := readFromWithECN(c.conn, msgs[0].data[:cap(msgs[0].data)], c.oob)
+	if err != nil {
+		return 0, err
+	}
+	msgs[0].data = msgs[0].data[:n]
+	msgs[0].remoteAddr = addr
+	msgs[0].ecn = ecn
+	return 1, nil
+}
+
+func (c *basicBatchConn) WriteBatch(packets [][]byte, addr net.Addr, ecn protocol.ECN) error {
+	for _, p := range packets {
+		if err := writeToWithECN(c.conn, p, addr, ecn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,347 @@
+// +build linux
+
+package quic
+
+import (
+	"errors"
+	"net"
+	"runtime"
+	"strconv"
+	"syscall"
+	"unsafe"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+)
+
+const (
+	// udpSegment is the UDP_SEGMENT socket option, used for UDP generic segmentation offload (GSO).
+	// It is not defined in the syscall package.
+	udpSegment = 103
+	// maxGSOSegments is the maximum number of segments the kernel accepts in a single GSO send
+	maxGSOSegments = 64
+	// maxGSOSize is the maximum payload of a single GSO send.
+	// It is limited by the maximum size of an IP packet.
+	maxGSOSize = 65535 - 40 - 8
+)
+
+// The syscall package doesn't define the numbers of the recvmmsg and sendmmsg system calls for all architectures.
+// On other architectures, packets are not batched.
+var mmsgSyscalls = map[string]struct{ recvmmsg, sendmmsg uintptr }{
+	"amd64": {recvmmsg: 299, sendmmsg: 307},
+	"386":   {recvmmsg: 337, sendmmsg: 345},
+	"arm":   {recvmmsg: 365, sendmmsg: 374},
+	"arm64": {recvmmsg: 243, sendmmsg: 269},
+}
+
+// gsoOOBSize is the size of the ancillary data used to send a GSO batch
+var gsoOOBSize = syscall.CmsgSpace(2)
+
+// mmsghdr is the struct mmsghdr used by recvmmsg and sendmmsg
+type mmsghdr struct {
+	Hdr syscall.Msghdr
+	Len uint32
+}
+
+// mmsgConn reads packets using recvmmsg, and writes them using sendmmsg.
+// If the kernel supports it, consecutive packets of the same size are sent using UDP GSO.
+type mmsgConn struct {
+	*basicBatchConn
+
+	rawConn     syscall.RawConn
+	sysRecvmmsg uintptr
+	sysSendmmsg uintptr
+	isIPv6      bool // the socket is an IPv6 socket, which might be a dual-stack socket
+	gso         bool
+
+	readMsgs   []mmsghdr
+	readIovecs []syscall.Iovec
+	readNames  []syscall.RawSockaddrAny
+	readOOB    []byte
+
+	writeMsgs        []mmsghdr
+	writeIovecs      []syscall.Iovec
+	writeOOB         []byte
+	writeSegments    []int // the number of packets sent in every message
+	writeSockaddr4   syscall.RawSockaddrInet4
+	writeSockaddr6   syscall.RawSockaddrInet6
+	writeSockaddrLen uint32
+}
+
+var _ batchConn = &mmsgConn{}
+
+// newBatchConn creates a batchConn.
+// Packets are only batched if the net.PacketConn is a *net.UDPConn.
+func newBatchConn(c net.PacketConn) batchConn {
+	udpConn, ok := c.(*net.UDPConn)
+	if !ok {
+		return newBasicBatchConn(c)
+	}
+	syscalls, ok := mmsgSyscalls[runtime.GOARCH]
+	if !ok {
+		return newBasicBatchConn(c)
+	}
+	rawConn, err := udpConn.SyscallConn()
+	if err != nil {
+		return newBasicBatchConn(c)
+	}
+	var sockaddr syscall.Sockaddr
+	var sockaddrErr, gsoErr error
+	if err := rawConn.Control(func(fd uintptr) {
+		sockaddr, sockaddrErr = syscall.Getsockname(int(fd))
+		_, gsoErr = syscall.GetsockoptInt(int(fd), syscall.IPPROTO_UDP, udpSegment)
+	}); err != nil || sockaddrErr != nil {
+		return newBasicBatchConn(c)
+	}
+	_, isIPv6 := sockaddr.(*syscall.SockaddrInet6)
+	return &mmsgConn{
+		basicBatchConn: newBasicBatchConn(c),
+		rawConn:        rawConn,
+		sysRecvmmsg:    syscalls.recvmmsg,
+		sysSendmmsg:    syscalls.sendmmsg,
+		isIPv6:         isIPv6,
+		gso:            gsoErr == nil,
+		readMsgs:       make([]mmsghdr, protocol.MaxBatchSize),
+		readIovecs:     make([]syscall.Iovec, protocol.MaxBatchSize),
+		readNames:      make([]syscall.RawSockaddrAny, protocol.MaxBatchSize),
+		readOOB:        make([]byte, protocol.MaxBatchSize*ecnOOBSize),
+		writeMsgs:      make([]mmsghdr, protocol.MaxBatchSize),
+		writeIovecs:    make([]syscall.Iovec, protocol.MaxBatchSize),
+		writeOOB:       make([]byte, protocol.MaxBatchSize*(ecnOOBSize+gsoOOBSize)),
+		writeSegments:  make([]int, protocol.MaxBatchSize),
+	}
+}
+
+func (c *mmsgConn) ReadBatch(msgs []datagram) (int, error) {
+	if len(msgs) > len(c.readMsgs) {
+		msgs = msgs[:len(c.readMsgs)]
+	}
+	for i := range msgs {
+		buf := msgs[i].data[:cap(msgs[i].data)]
+		c.readIovecs[i].Base = &buf[0]
+		c.readIovecs[i].SetLen(len(buf))
+		oob := c.readOOB[i*ecnOOBSize : (i+1)*ecnOOBSize]
+		c.readMsgs[i] = mmsghdr{}
+		hdr := &c.readMsgs[i].Hdr
+		hdr.Name = (*byte)(unsafe.Pointer(&c.readNames[i]))
+		hdr.Namelen = syscall.SizeofSockaddrAny
+		hdr.Iov = &c.readIovecs[i]
+		setIovlen(hdr, 1)
+		hdr.Control = &oob[0]
+		hdr.SetControllen(len(oob))
+	}
+	var n int
+	var operr error
+	if err := c.rawConn.Read(func(fd uintptr) bool {
+		r, _, errno := syscall.Syscall6(c.sysRecvmmsg, fd, uintptr(unsafe.Pointer(&c.readMsgs[0])), uintptr(len(msgs)), 0, 0, 0)
+		if errno == syscall.EAGAIN {
+			return false
+		}
+		if errno != 0 {
+			operr = errno
+		}
+		n = int(r)
+		return true
+	}); err != nil {
+		return 0, err
+	}
+	if operr != nil {
+		return 0, &net.OpError{Op: "recvmmsg", Net: "udp", Err: operr}
+	}
+	for i := 0; i < n; i++ {
+		msg := &c.readMsgs[i]
+		msgs[i].data = msgs[i].data[:msg.Len]
+		msgs[i].remoteAddr = sockaddrToUDPAddr(&c.readNames[i])
+		msgs[i].ecn = parseECN(c.readOOB[i*ecnOOBSize : i*ecnOOBSize+int(msg.Hdr.Controllen)])
+	}
+	return n, nil
+}
+
+func (c *mmsgConn) WriteBatch(packets [][]byte, addr net.Addr, ecn protocol.ECN) error {
+	udpAddr, ok := addr.(*net.UDPAddr)
+	if !ok || len(packets) > len(c.writeIovecs) {
+		return c.basicBatchConn.WriteBatch(packets, addr, ecn)
+	}
+	name, err := c.setWriteSockaddr(udpAddr)
+	if err != nil {
+		return err
+	}
+	var ecnOOB []byte
+	if ecn != protocol.ECNNon {
+		ecnOOB = ecnControlMessage(udpAddr, ecn)
+	}
+
+	var numMsgs int
+	oob := c.writeOOB[:0]
+	for i := 0; i < len(packets); {
+		numSegments := 1
+		if c.gso {
+			numSegments = gsoSegments(packets[i:])
+		}
+		for j := 0; j < numSegments; j++ {
+			c.writeIovecs[i+j].Base = &packets[i+j][0]
+			c.writeIovecs[i+j].SetLen(len(packets[i+j]))
+		}
+		oobStart := len(oob)
+		oob = append(oob, ecnOOB...)
+		if numSegments > 1 {
+			oob = appendGSOControlMessage(oob, len(packets[i]))
+		}
+		c.writeMsgs[numMsgs] = mmsghdr{}
+		hdr := &c.writeMsgs[numMsgs].Hdr
+		hdr.Name = name
+		hdr.Namelen = c.writeSockaddrLen
+		hdr.Iov = &c.writeIovecs[i]
+		setIovlen(hdr, numSegments)
+		if len(oob) > oobStart {
+			hdr.Control = &oob[oobStart]
+			hdr.SetControllen(len(oob) - oobStart)
+		}
+		c.writeSegments[numMsgs] = numSegments
+		numMsgs++
+		i += numSegments
+	}
+
+	var sent int
+	for sent < numMsgs {
+		n, err := c.sendmmsg(c.writeMsgs[sent:numMsgs])
+		if err != nil {
+			if c.gso && err == syscall.EIO {
+				// GSO is not supported by the network device
+				utils.Infof("Sending packets using GSO failed. Disabling GSO.")
+				c.gso = false
+				var sentPackets int
+				for _, s := range c.writeSegments[:sent] {
+					sentPackets += s
+				}
+				return c.WriteBatch(packets[sentPackets:], addr, ecn)
+			}
+			return &net.OpError{Op: "sendmmsg", Net: "udp", Addr: addr, Err: err}
+		}
+		sent += n
+	}
+	return nil
+}
+
+func (c *mmsgConn) sendmmsg(msgs []mmsghdr) (int, error) {
+	var n int
+	var operr error
+	if err := c.rawConn.Write(func(fd uintptr) bool {
+		r, _, errno := syscall.Syscall6(c.sysSendmmsg, fd, uintptr(unsafe.Pointer(&msgs[0])), uintptr(len(msgs)), 0, 0, 0)
+		if errno == syscall.EAGAIN {
+			return false
+		}
+		if errno != 0 {
+			operr = errno
+		}
+		n = int(r)
+		return true
+	}); err != nil {
+		return 0, err
+	}
+	return n, operr
+}
+
+// setWriteSockaddr sets the address that packets are sent to
+func (c *mmsgConn) setWriteSockaddr(addr *net.UDPAddr) (*byte, error) {
+	if c.isIPv6 {
+		ip := addr.IP.To16()
+		if ip == nil {
+			return nil, errors.New("invalid IP address: " + addr.IP.String())
+		}
+		c.writeSockaddr6 = syscall.RawSockaddrInet6{Family: syscall.AF_INET6}
+		setPort(&c.writeSockaddr6.Port, addr.Port)
+		copy(c.writeSockaddr6.Addr[:], ip)
+		if addr.Zone != "" {
+			if iface, err := net.InterfaceByName(addr.Zone); err == nil {
+				c.writeSockaddr6.Scope_id = uint32(iface.Index)
+			} else if id, err := strconv.Atoi(addr.Zone); err == nil {
+				c.writeSockaddr6.Scope_id = uint32(id)
+			}
+		}
+		c.writeSockaddrLen = syscall.SizeofSockaddrInet6
+		return (*byte)(unsafe.Pointer(&c.writeSockaddr6)), nil
+	}
+	ip := addr.IP.To4()
+	if ip == nil {
+		return nil, errors.New("can't send to an IPv6 address on an IPv4 socket: " + addr.IP.String())
+	}
+	c.writeSockaddr4 = syscall.RawSockaddrInet4{Family: syscall.AF_INET}
+	setPort(&c.writeSockaddr4.Port, addr.Port)
+	copy(c.writeSockaddr4.Addr[:], ip)
+	c.writeSockaddrLen = syscall.SizeofSockaddrInet4
+	return (*byte)(unsafe.Pointer(&c.writeSockaddr4)), nil
+}
+
+// gsoSegments determines how many packets can be sent with a single GSO send.
+// All segments must have the same size, except for the last one, which may be smaller.
+func gsoSegments(packets [][]byte) int {
+	size := len(packets[0])
+	total := size
+	n := 1
+	for _, p := range packets[1:] {
+		if len(p) > size || n >= maxGSOSegments || total+len(p) > maxGSOSize {
+			break
+		}
+		n++
+		total += len(p)
+		if len(p) < size {
+			break
+		}
+	}
+	return n
+}
+
+func appendGSOControlMessage(b []byte, segmentSize int) []byte {
+	start := len(b)
+	for i := 0; i < gsoOOBSize; i++ {
+		b = append(b, 0)
+	}
+	h := (*syscall.Cmsghdr)(unsafe.Pointer(&b[start]))
+	h.Level = syscall.IPPROTO_UDP
+	h.Type = udpSegment
+	h.SetLen(syscall.CmsgLen(2))
+	*(*uint16)(unsafe.Pointer(&b[start+syscall.CmsgLen(0)])) = uint16(segmentSize)
+	return b
+}
+
+func sockaddrToUDPAddr(rsa *syscall.RawSockaddrAny) net.Addr {
+	switch rsa.Addr.Family {
+	case syscall.AF_INET:
+		sa := (*syscall.RawSockaddrInet4)(unsafe.Pointer(rsa))
+		return &net.UDPAddr{
+			IP:   net.IPv4(sa.Addr[0], sa.Addr[1], sa.Addr[2], sa.Addr[3]),
+			Port: getPort(&sa.Port),
+		}
+	case syscall.AF_INET6:
+		sa := (*syscall.RawSockaddrInet6)(unsafe.Pointer(rsa))
+		addr := &net.UDPAddr{
+			IP:   make(net.IP, net.IPv6len),
+			Port: getPort(&sa.Port),
+		}
+		copy(addr.IP, sa.Addr[:])
+		if sa.Scope_id != 0 {
+			addr.Zone = strconv.Itoa(int(sa.Scope_id))
+		}
+		return addr
+	}
+	return nil
+}
+
+// The port in a sockaddr is stored in network byte order.
+func setPort(p *uint16, port int) {
+	b := (*[2]byte)(unsafe.Pointer(p))
+	b[0] = byte(port >> 8)
+	b[1] = byte(port)
+}
+
+func getPort(p *uint16) int {
+	b := (*[2]byte)(unsafe.Pointer(p))
+	return int(b[0])<<8 | int(b[1])
+}
+
+// setIovlen sets the number of iovecs of a message.
+// The type of the Iovlen field depends on the architecture, but it always has the size of a size_t.
+func setIovlen(hdr *syscall.Msghdr, n int) {
+	*(*uintptr)(unsafe.Pointer(&hdr.Iovlen)) = uintptr(n)
+}
@@ -0,0 +1,152 @@
+// +build linux
+
+package quic
+
+import (
+	"bytes"
+	"net"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("mmsg batch conn", func() {
+	var server, client *net.UDPConn
+
+	listen := func(network string, ip net.IP) *net.UDPConn {
+		conn, err := net.ListenUDP(network, &net.UDPAddr{IP: ip})
+		Expect(err).ToNot(HaveOccurred())
+		return conn
+	}
+
+	BeforeEach(func() {
+		server = listen("udp4", net.IPv4(127, 0, 0, 1))
+		client = listen("udp4", net.IPv4(127, 0, 0, 1))
+	})
+
+	AfterEach(func() {
+		server.Close()
+		client.Close()
+	})
+
+	newMsgs := func(n int) []datagram {
+		msgs := make([]datagram, n)
+		for i := range msgs {
+			msgs[i].data = make([]byte, 0, protocol.MaxReceivePacketSize)
+		}
+		return msgs
+	}
+
+	// readPackets reads packets from a net.UDPConn, until no packet arrives for some time
+	readPackets := func(conn *net.UDPConn) [][]byte {
+		var packets [][]byte
+		for {
+			b := make([]byte, 2000)
+			conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+			n, _, err := conn.ReadFrom(b)
+			if err != nil {
+				return packets
+			}
+			packets = append(packets, b[:n])
+		}
+	}
+
+	It("batches on a *net.UDPConn", func() {
+		Expect(newBatchConn(server)).To(BeAssignableToTypeOf(&mmsgConn{}))
+		Expect(newBatchConn(newMockPacketConn())).To(BeAssignableToTypeOf(&basicBatchConn{}))
+	})
+
+	It("reads multiple packets", func() {
+		Expect(enableECN(server)).To(BeTrue())
+		for i := 0; i < 10; i++ {
+			Expect(writeToWithECN(client, []byte{byte(i)}, server.LocalAddr(), protocol.ECT0)).To(Succeed())
+		}
+		conn := newBatchConn(server)
+		msgs := newMsgs(8)
+		var received []byte
+		for len(received) < 10 {
+			n, err := conn.ReadBatch(msgs)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(BeNumerically(">=", 1))
+			for _, msg := range msgs[:n] {
+				Expect(msg.data).To(HaveLen(1))
+				Expect(msg.remoteAddr.String()).To(Equal(client.LocalAddr().String()))
+				Expect(msg.ecn).To(Equal(protocol.ECT0))
+				received = append(received, msg.data[0])
+				msg.data = msg.data[:0]
+			}
+		}
+		Expect(received).To(Equal([]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}))
+	})
+
+	It("returns an error when reading from a closed connection", func() {
+		conn := newBatchConn(server)
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			server.Close()
+		}()
+		_, err := conn.ReadBatch(newMsgs(1))
+		Expect(err).To(HaveOccurred())
+	})
+
+	Context("writing", func() {
+		packets := [][]byte{
+			bytes.Repeat([]byte{'a'}, 1000),
+			bytes.Repeat([]byte{'b'}, 1000),
+			bytes.Repeat([]byte{'c'}, 1000),
+			bytes.Repeat([]byte{'d'}, 500),
+			bytes.Repeat([]byte{'e'}, 1200),
+			bytes.Repeat([]byte{'f'}, 10),
+		}
+
+		It("writes packets", func() {
+			conn := newBatchConn(client).(*mmsgConn)
+			conn.gso = false
+			Expect(conn.WriteBatch(packets, server.LocalAddr(), protocol.ECNNon)).To(Succeed())
+			Expect(readPackets(server)).To(Equal(packets))
+		})
+
+		It("writes packets using GSO", func() {
+			conn := newBatchConn(client).(*mmsgConn)
+			if !conn.gso {
+				Skip("GSO not supported")
+			}
+			Expect(conn.WriteBatch(packets, server.LocalAddr(), protocol.ECT0)).To(Succeed())
+			Expect(readPackets(server)).To(Equal(packets))
+		})
+
+		It("writes to IPv4 addresses on a dual-stack socket", func() {
+			client.Close()
+			client = listen("udp", nil)
+			conn := newBatchConn(client).(*mmsgConn)
+			Expect(conn.isIPv6).To(BeTrue())
+			Expect(conn.WriteBatch(packets, server.LocalAddr(), protocol.ECT0)).To(Succeed())
+			Expect(readPackets(server)).To(Equal(packets))
+		})
+	})
+
+	It("determines the number of GSO segments", func() {
+		p := func(l int) []byte { return make([]byte, l) }
+		Expect(gsoSegments([][]byte{p(100)})).To(Equal(1))
+		Expect(gsoSegments([][]byte{p(100), p(100), p(100)})).To(Equal(3))
+		// the last segment may be smaller
+		Expect(gsoSegments([][]byte{p(100), p(100), p(50), p(100)})).To(Equal(3))
+		// larger packets can't be sent in the same batch
+		Expect(gsoSegments([][]byte{p(100), p(100), p(200)})).To(Equal(2))
+		// the batch is limited by the number of segments ...
+		packets := make([][]byte, 2*maxGSOSegments)
+		for i := range packets {
+			packets[i] = p(100)
+		}
+		Expect(gsoSegments(packets)).To(Equal(maxGSOSegments))
+		// ... and by the size
+		packets = make([][]byte, maxGSOSegments)
+		for i := range packets {
+			packets[i] = p(1400)
+		}
+		Expect(gsoSegments(packets)).To(Equal(maxGSOSize / 1400))
+	})
+})
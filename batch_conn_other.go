@@ -0,0 +1,11 @@
+// +build !linux
+
+package quic
+
+import "net"
+
+// newBatchConn creates a batchConn.
+// Batching is only implemented on Linux.
+func newBatchConn(c net.PacketConn) batchConn {
+	return newBasicBatchConn(c)
+}
@@ -0,0 +1,46 @@
+package quic
+
+import (
+	"net"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Basic batch conn", func() {
+	var (
+		c          *basicBatchConn
+		packetConn *mockPacketConn
+	)
+
+	BeforeEach(func() {
+		packetConn = newMockPacketConn()
+		c = newBasicBatchConn(packetConn)
+	})
+
+	It("reads one packet at a time", func() {
+		packetConn.dataReadFrom = &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1337}
+		packetConn.dataToRead <- []byte("foo")
+		packetConn.dataToRead <- []byte("bar")
+		msgs := []datagram{
+			{data: make([]byte, 0, 10)},
+			{data: make([]byte, 0, 10)},
+		}
+		n, err := c.ReadBatch(msgs)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(n).To(Equal(1))
+		Expect(msgs[0].data).To(Equal([]byte("foo")))
+		Expect(msgs[0].remoteAddr).To(Equal(packetConn.dataReadFrom))
+		Expect(msgs[0].ecn).To(Equal(protocol.ECNNon))
+	})
+
+	It("writes packets one by one", func() {
+		addr := &net.UDPAddr{IP: net.IPv4(192, 168, 100, 200), Port: 1337}
+		err := c.WriteBatch([][]byte{[]byte("foo"), []byte("bar")}, addr, protocol.ECT0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(packetConn.dataWritten.Bytes()).To(Equal([]byte("foobar")))
+		Expect(packetConn.dataWrittenTo).To(Equal(addr))
+	})
+})
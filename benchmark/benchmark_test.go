@@ -16,6 +16,22 @@ import (
 	. "github.com/onsi/gomega"
 )
 
+// unbatchedConn hides the *net.UDPConn from quic-go.
+// Packets are then read and written one by one, using the portable fallback.
+// Note that this also disables ECN.
+type unbatchedConn struct {
+	net.PacketConn
+}
+
+func listenUDP(batching bool) net.PacketConn {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+	Expect(err).ToNot(HaveOccurred())
+	if !batching {
+		return &unbatchedConn{PacketConn: conn}
+	}
+	return conn
+}
+
 func init() {
 	var _ = Describe("Benchmarks", func() {
 		dataLen := size * /* MB */ 1e6
@@ -23,63 +39,79 @@ func init() {
 		rand.Seed(GinkgoRandomSeed())
 		rand.Read(data) // no need to check for an error. math.Rand.Read never errors
 
+		transfer := func(b Benchmarker, version protocol.VersionNumber, batching bool) {
+			var ln quic.Listener
+			serverAddr := make(chan net.Addr)
+			handshakeChan := make(chan struct{})
+			// start the server
+			go func() {
+				defer GinkgoRecover()
+				var err error
+				ln, err = quic.Listen(
+					listenUDP(batching),
+					testdata.GetTLSConfig(),
+					&quic.Config{Versions: []protocol.VersionNumber{version}},
+				)
+				Expect(err).ToNot(HaveOccurred())
+				serverAddr <- ln.Addr()
+				sess, err := ln.Accept()
+				Expect(err).ToNot(HaveOccurred())
+				// wait for the client to complete the handshake before sending the data
+				// this should not be necessary, but due to timing issues on the CIs, this is necessary to avoid sending too many undecryptable packets
+				<-handshakeChan
+				str, err := sess.OpenStream()
+				Expect(err).ToNot(HaveOccurred())
+				_, err = str.Write(data)
+				Expect(err).ToNot(HaveOccurred())
+				err = str.Close()
+				Expect(err).ToNot(HaveOccurred())
+			}()
+
+			// start the client
+			addr := <-serverAddr
+			conn := listenUDP(batching)
+			sess, err := quic.Dial(
+				conn,
+				addr,
+				addr.String(),
+				&tls.Config{InsecureSkipVerify: true},
+				&quic.Config{Versions: []protocol.VersionNumber{version}},
+			)
+			Expect(err).ToNot(HaveOccurred())
+			close(handshakeChan)
+			str, err := sess.AcceptStream()
+			Expect(err).ToNot(HaveOccurred())
+
+			buf := &bytes.Buffer{}
+			cpuTime := systemCPUTime()
+			// measure the time it takes to download the dataLen bytes
+			// note we're measuring the time for the transfer, i.e. excluding the handshake
+			runtime := b.Time("transfer time", func() {
+				_, err := io.Copy(buf, str)
+				Expect(err).NotTo(HaveOccurred())
+			})
+			cpuTime = systemCPUTime() - cpuTime
+			Expect(buf.Bytes()).To(Equal(data))
+
+			b.RecordValue("transfer rate [MB/s]", float64(dataLen)/1e6/runtime.Seconds())
+			// the system CPU time is dominated by the system calls used for sending and receiving packets
+			b.RecordValue("system CPU time [ms/MB]", float64(cpuTime.Nanoseconds())/1e6/(float64(dataLen)/1e6))
+
+			ln.Close()
+			sess.Close(nil)
+			conn.Close()
+		}
+
 		for i := range protocol.SupportedVersions {
 			version := protocol.SupportedVersions[i]
 
 			Context(fmt.Sprintf("with version %s", version), func() {
 				Measure(fmt.Sprintf("transferring a %d MB file", size), func(b Benchmarker) {
-					var ln quic.Listener
-					serverAddr := make(chan net.Addr)
-					handshakeChan := make(chan struct{})
-					// start the server
-					go func() {
-						defer GinkgoRecover()
-						var err error
-						ln, err = quic.ListenAddr(
-							"localhost:0",
-							testdata.GetTLSConfig(),
-							&quic.Config{Versions: []protocol.VersionNumber{version}},
-						)
-						Expect(err).ToNot(HaveOccurred())
-						serverAddr <- ln.Addr()
-						sess, err := ln.Accept()
-						Expect(err).ToNot(HaveOccurred())
-						// wait for the client to complete the handshake before sending the data
-						// this should not be necessary, but due to timing issues on the CIs, this is necessary to avoid sending too many undecryptable packets
-						<-handshakeChan
-						str, err := sess.OpenStream()
-						Expect(err).ToNot(HaveOccurred())
-						_, err = str.Write(data)
-						Expect(err).ToNot(HaveOccurred())
-						err = str.Close()
-						Expect(err).ToNot(HaveOccurred())
-					}()
-
-					// start the client
-					addr := <-serverAddr
-					sess, err := quic.DialAddr(
-						addr.String(),
-						&tls.Config{InsecureSkipVerify: true},
-						&quic.Config{Versions: []protocol.VersionNumber{version}},
-					)
-					Expect(err).ToNot(HaveOccurred())
-					close(handshakeChan)
-					str, err := sess.AcceptStream()
-					Expect(err).ToNot(HaveOccurred())
-
-					buf := &bytes.Buffer{}
-					// measure the time it takes to download the dataLen bytes
-					// note we're measuring the time for the transfer, i.e. excluding the handshake
-					runtime := b.Time("transfer time", func() {
-						_, err := io.Copy(buf, str)
-						Expect(err).NotTo(HaveOccurred())
-					})
-					Expect(buf.Bytes()).To(Equal(data))
-
-					b.RecordValue("transfer rate [MB/s]", float64(dataLen)/1e6/runtime.Seconds())
+					transfer(b, version, true)
+				}, samples)
 
-					ln.Close()
-					sess.Close(nil)
+				Measure(fmt.Sprintf("transferring a %d MB file, reading and writing one packet per system call", size), func(b Benchmarker) {
+					transfer(b, version, false)
 				}, samples)
 			})
 		}
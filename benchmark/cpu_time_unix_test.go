@@ -0,0 +1,17 @@
+// +build !windows
+
+package benchmark
+
+import (
+	"syscall"
+	"time"
+)
+
+// systemCPUTime returns the CPU time that the kernel spent executing system calls for this process
+func systemCPUTime() time.Duration {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return 0
+	}
+	return time.Duration(usage.Stime.Nano())
+}
@@ -4,23 +4,60 @@ import (
 	"sync"
 
 	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
 )
 
-var bufferPool sync.Pool
+// bufferPool holds the packet buffers that are available for reuse.
+// We don't use a sync.Pool here, since putting a slice into a sync.Pool allocates.
+var bufferPool = make(chan []byte, protocol.MaxPooledPacketBuffers)
 
-func getPacketBuffer() []byte {
-	return bufferPool.Get().([]byte)
+// getPacketBuffer returns a buffer that can hold a packet of the given size.
+// Its capacity is at least protocol.MaxReceivePacketSize.
+func getPacketBuffer(size protocol.ByteCount) []byte {
+	select {
+	case buf := <-bufferPool:
+		if cap(buf) >= int(size) {
+			return buf
+		}
+		// The buffer is too small. This only happens if the packet size differs between configs.
+	default:
+	}
+	return make([]byte, 0, utils.MaxByteCount(size, protocol.MaxReceivePacketSize))
 }
 
 func putPacketBuffer(buf []byte) {
-	if cap(buf) != int(protocol.MaxReceivePacketSize) {
+	if cap(buf) < int(protocol.MaxReceivePacketSize) {
 		panic("putPacketBuffer called with packet of wrong size!")
 	}
-	bufferPool.Put(buf[:0])
+	select {
+	case bufferPool <- buf[:0]:
+	default:
+		// the pool is full
+	}
 }
 
-func init() {
-	bufferPool.New = func() interface{} {
-		return make([]byte, 0, protocol.MaxReceivePacketSize)
-	}
+// receiveBufferSize is the size of the buffers that packets are read into.
+// It allows receiving packets of the size probed by path MTU discovery, and packets of up to protocol.MaxReceivePacketSize bytes sent by peers using a smaller Config.MaxPacketSize.
+func receiveBufferSize(config *Config) protocol.ByteCount {
+	return utils.MaxByteCount(protocol.ByteCount(config.MaxPacketSize), protocol.MaxReceivePacketSize)
+}
+
+var receivedPacketPool = sync.Pool{
+	New: func() interface{} {
+		return &receivedPacket{}
+	},
+}
+
+// getReceivedPacket returns a receivedPacket, whose header points to the zeroed Header embedded in the receivedPacket.
+func getReceivedPacket() *receivedPacket {
+	p := receivedPacketPool.Get().(*receivedPacket)
+	p.header = &p.hdr
+	return p
+}
+
+// putReceivedPacket returns a receivedPacket obtained from getReceivedPacket.
+// It must not be used afterwards.
+func putReceivedPacket(p *receivedPacket) {
+	*p = receivedPacket{}
+	receivedPacketPool.Put(p)
 }
@@ -1,7 +1,10 @@
 package quic
 
 import (
+	"testing"
+
 	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/wire"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -9,24 +12,65 @@ import (
 
 var _ = Describe("Buffer Pool", func() {
 	It("returns buffers of correct len and cap", func() {
-		buf := getPacketBuffer()
+		buf := getPacketBuffer(protocol.MaxReceivePacketSize)
 		Expect(buf).To(HaveLen(0))
 		Expect(buf).To(HaveCap(int(protocol.MaxReceivePacketSize)))
 	})
 
+	It("returns buffers of at least protocol.MaxReceivePacketSize bytes", func() {
+		buf := getPacketBuffer(1000)
+		Expect(cap(buf)).To(BeNumerically(">=", int(protocol.MaxReceivePacketSize)))
+	})
+
+	It("returns buffers for larger packets", func() {
+		putPacketBuffer(getPacketBuffer(protocol.MaxReceivePacketSize))
+		// the pooled buffer is too small
+		buf := getPacketBuffer(9000)
+		Expect(buf).To(HaveLen(0))
+		Expect(cap(buf)).To(BeNumerically(">=", 9000))
+		putPacketBuffer(buf)
+	})
+
 	It("zeroes put buffers' length", func() {
 		for i := 0; i < 1000; i++ {
-			buf := getPacketBuffer()
+			buf := getPacketBuffer(protocol.MaxReceivePacketSize)
 			putPacketBuffer(buf[0:10])
-			buf = getPacketBuffer()
+			buf = getPacketBuffer(protocol.MaxReceivePacketSize)
 			Expect(buf).To(HaveLen(0))
-			Expect(buf).To(HaveCap(int(protocol.MaxReceivePacketSize)))
+			Expect(cap(buf)).To(BeNumerically(">=", int(protocol.MaxReceivePacketSize)))
 		}
 	})
 
+	It("doesn't allocate when reusing buffers", func() {
+		putPacketBuffer(getPacketBuffer(protocol.MaxReceivePacketSize))
+		allocs := testing.AllocsPerRun(100, func() {
+			putPacketBuffer(getPacketBuffer(protocol.MaxReceivePacketSize))
+		})
+		Expect(allocs).To(BeZero())
+	})
+
+	It("sizes the receive buffers for the maximum packet size", func() {
+		Expect(receiveBufferSize(&Config{MaxPacketSize: 1300})).To(Equal(protocol.MaxReceivePacketSize))
+		Expect(receiveBufferSize(&Config{MaxPacketSize: 9000})).To(Equal(protocol.ByteCount(9000)))
+	})
+
 	It("panics if wrong-sized buffers are passed", func() {
 		Expect(func() {
 			putPacketBuffer([]byte{0})
 		}).To(Panic())
 	})
+
+	It("returns received packets with a zeroed header", func() {
+		p := getReceivedPacket()
+		Expect(p.header).To(BeIdenticalTo(&p.hdr))
+		p.header.PacketNumber = 0x42
+		p.data = []byte("foobar")
+		putReceivedPacket(p)
+		for i := 0; i < 100; i++ {
+			p = getReceivedPacket()
+			Expect(p.data).To(BeNil())
+			Expect(*p.header).To(Equal(wire.Header{}))
+			putReceivedPacket(p)
+		}
+	})
 })
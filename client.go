@@ -86,6 +86,7 @@ func Dial(
 	}
 
 	clientConfig := populateClientConfig(config)
+	enableECN(pconn)
 	c := &client{
 		conn:                   &conn{pconn: pconn, currentAddr: remoteAddr},
 		connectionID:           connID,
@@ -132,6 +133,20 @@ func populateClientConfig(config *Config) *Config {
 	if maxReceiveConnectionFlowControlWindow == 0 {
 		maxReceiveConnectionFlowControlWindow = protocol.DefaultMaxReceiveConnectionFlowControlWindowClient
 	}
+	maxIncomingStreams := config.MaxIncomingStreams
+	if maxIncomingStreams <= 0 {
+		maxIncomingStreams = protocol.DefaultMaxIncomingStreams
+	}
+	minPacketSize, maxPacketSize := populatePacketSizes(config.MinPacketSize, config.MaxPacketSize)
+
+	congestionControl := config.CongestionControl
+	if congestionControl == nil {
+		congestionControl = NewCubic
+	}
+	initialPacingBurst := config.InitialPacingBurst
+	if initialPacingBurst <= 0 {
+		initialPacingBurst = protocol.DefaultInitialPacingBurst
+	}
 
 	return &Config{
 		Versions:                              versions,
@@ -140,8 +155,75 @@ func populateClientConfig(config *Config) *Config {
 		RequestConnectionIDOmission:           config.RequestConnectionIDOmission,
 		MaxReceiveStreamFlowControlWindow:     maxReceiveStreamFlowControlWindow,
 		MaxReceiveConnectionFlowControlWindow: maxReceiveConnectionFlowControlWindow,
-		KeepAlive: config.KeepAlive,
+		MaxIncomingStreams:                    maxIncomingStreams,
+		MinPacketSize:                         minPacketSize,
+		MaxPacketSize:                         maxPacketSize,
+		KeepAlive:                             config.KeepAlive,
+		FEC:                                   populateFECConfig(config.FEC),
+		Multipath:                             config.Multipath,
+		WriteCoalescingDelay:                  config.WriteCoalescingDelay,
+		CongestionControl:                     congestionControl,
+		InitialPacingBurst:                    initialPacingBurst,
+		AckPolicy:                             populateAckPolicy(config.AckPolicy),
+	}
+}
+
+// populateAckPolicy fills in the default values for the ACK policy, and limits the maximum ACK delay to the allowed range
+// it may be called with nil, in which case the default policy is returned
+func populateAckPolicy(policy *AckPolicy) *AckPolicy {
+	if policy == nil {
+		policy = &AckPolicy{}
+	}
+	packetThreshold := policy.PacketThreshold
+	if packetThreshold <= 0 {
+		packetThreshold = protocol.RetransmittablePacketsBeforeAck
+	}
+	maxAckDelay := policy.MaxAckDelay
+	if maxAckDelay <= 0 {
+		maxAckDelay = protocol.AckSendDelay
+	}
+	return &AckPolicy{
+		PacketThreshold:  packetThreshold,
+		MaxAckDelay:      utils.MinDuration(maxAckDelay, protocol.MaxAckDelayLimit),
+		IgnoreReordering: policy.IgnoreReordering,
+	}
+}
+
+// populateFECConfig fills in the default values for the FEC parameters, and limits them to the allowed range
+// it may be called with nil, if FEC is disabled
+func populateFECConfig(config *FECConfig) *FECConfig {
+	if config == nil {
+		return nil
+	}
+	dataPackets := config.DataPackets
+	if dataPackets <= 0 {
+		dataPackets = protocol.DefaultFECDataPackets
+	}
+	repairPackets := config.RepairPackets
+	if repairPackets <= 0 {
+		repairPackets = protocol.DefaultFECRepairPackets
+	}
+	return &FECConfig{
+		DataPackets:   utils.Min(dataPackets, protocol.MaxFECDataPackets),
+		RepairPackets: utils.Min(repairPackets, protocol.MaxFECRepairPackets),
+	}
+}
+
+// populatePacketSizes fills in the default values for the packet size bounds used by path MTU discovery, and limits them to the allowed range
+func populatePacketSizes(minPacketSize, maxPacketSize uint64) (uint64, uint64) {
+	if minPacketSize < uint64(protocol.MaxPacketSize) {
+		minPacketSize = uint64(protocol.MaxPacketSize)
+	}
+	if maxPacketSize == 0 {
+		maxPacketSize = uint64(protocol.MaxReceivePacketSize)
 	}
+	if maxPacketSize > uint64(protocol.MaxUDPPayloadSize) {
+		maxPacketSize = uint64(protocol.MaxUDPPayloadSize)
+	}
+	if minPacketSize > maxPacketSize {
+		minPacketSize = maxPacketSize
+	}
+	return minPacketSize, maxPacketSize
 }
 
 func (c *client) dial() error {
@@ -169,9 +251,16 @@ func (c *client) dialTLS() error {
 	params := &handshake.TransportParameters{
 		StreamFlowControlWindow:     protocol.ReceiveStreamFlowControlWindow,
 		ConnectionFlowControlWindow: protocol.ReceiveConnectionFlowControlWindow,
-		MaxStreams:                  protocol.MaxIncomingStreams,
+		MaxStreams:                  uint32(c.config.MaxIncomingStreams),
+		MaxBidiStreamID:             initialMaxIncomingStreamID(c.config.MaxIncomingStreams, protocol.PerspectiveClient),
 		IdleTimeout:                 c.config.IdleTimeout,
 		OmitConnectionID:            c.config.RequestConnectionIDOmission,
+		FEC:                         c.config.FEC != nil,
+		Multipath:                   c.config.Multipath != nil,
+		MaxAckDelay:                 c.config.AckPolicy.MaxAckDelay,
+		AckFrequency:                true,
+		ExpiredStreamData:           true,
+		MaxPacketSize:               receiveBufferSize(c.config),
 	}
 	csc := handshake.NewCryptoStreamConn(nil)
 	extHandler := handshake.NewExtensionHandlerClient(params, c.initialVersion, c.config.Versions, c.version)
@@ -243,11 +332,12 @@ func (c *client) listen() {
 	for {
 		var n int
 		var addr net.Addr
-		data := getPacketBuffer()
-		data = data[:protocol.MaxReceivePacketSize]
-		// The packet size should not exceed protocol.MaxReceivePacketSize bytes
+		var ecn protocol.ECN
+		data := getPacketBuffer(receiveBufferSize(c.config))
+		data = data[:cap(data)]
+		// The packet size should not exceed the receive buffer size
 		// If it does, we only read a truncated packet, which will then end up undecryptable
-		n, addr, err = c.conn.Read(data)
+		n, addr, ecn, err = c.conn.Read(data)
 		if err != nil {
 			if !strings.HasSuffix(err.Error(), "use of closed network connection") {
 				c.mutex.Lock()
@@ -258,16 +348,17 @@ func (c *client) listen() {
 			}
 			break
 		}
-		c.handlePacket(addr, data[:n])
+		c.handlePacket(addr, data[:n], ecn)
 	}
 }
 
-func (c *client) handlePacket(remoteAddr net.Addr, packet []byte) {
-	rcvTime := time.Now()
+func (c *client) handlePacket(remoteAddr net.Addr, packet []byte, ecn protocol.ECN) {
+	p := getReceivedPacket()
+	p.rcvTime = time.Now()
 
 	r := bytes.NewReader(packet)
-	hdr, err := wire.ParseHeaderSentByServer(r, c.version)
-	if err != nil {
+	hdr := p.header
+	if err := wire.ParseHeaderSentByServerInto(r, c.version, hdr); err != nil {
 		utils.Errorf("error parsing packet from %s: %s", remoteAddr.String(), err.Error())
 		// drop this packet if we can't parse the header
 		return
@@ -327,12 +418,10 @@ func (c *client) handlePacket(remoteAddr net.Addr, packet []byte) {
 
 	// TODO: validate packet number and connection ID on Retry packets (for IETF QUIC)
 
-	c.session.handlePacket(&receivedPacket{
-		remoteAddr: remoteAddr,
-		header:     hdr,
-		data:       packet[len(packet)-r.Len():],
-		rcvTime:    rcvTime,
-	})
+	p.remoteAddr = remoteAddr
+	p.data = packet[len(packet)-r.Len():]
+	p.ecn = ecn
+	c.session.handlePacket(p)
 }
 
 func (c *client) handleVersionNegotiationPacket(hdr *wire.Header) error {
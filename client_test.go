@@ -6,6 +6,7 @@ import (
 	"errors"
 	"net"
 	"os"
+	"reflect"
 	"sync/atomic"
 	"time"
 
@@ -202,15 +203,28 @@ var _ = Describe("Client", func() {
 		})
 
 		It("setups with the right values", func() {
+			congestionControl := NewFixedWindow(10000)
 			config := &Config{
 				HandshakeTimeout:            1337 * time.Minute,
 				IdleTimeout:                 42 * time.Hour,
 				RequestConnectionIDOmission: true,
+				MaxIncomingStreams:          1234,
+				MinPacketSize:               1300,
+				MaxPacketSize:               1400,
+				WriteCoalescingDelay:        10 * time.Millisecond,
+				CongestionControl:           congestionControl,
+				InitialPacingBurst:          20,
 			}
 			c := populateClientConfig(config)
 			Expect(c.HandshakeTimeout).To(Equal(1337 * time.Minute))
 			Expect(c.IdleTimeout).To(Equal(42 * time.Hour))
 			Expect(c.RequestConnectionIDOmission).To(BeTrue())
+			Expect(c.MaxIncomingStreams).To(Equal(1234))
+			Expect(c.MinPacketSize).To(BeEquivalentTo(1300))
+			Expect(c.MaxPacketSize).To(BeEquivalentTo(1400))
+			Expect(c.WriteCoalescingDelay).To(Equal(10 * time.Millisecond))
+			Expect(reflect.ValueOf(c.CongestionControl)).To(Equal(reflect.ValueOf(congestionControl)))
+			Expect(c.InitialPacingBurst).To(Equal(20))
 		})
 
 		It("fills in default values if options are not set in the Config", func() {
@@ -219,6 +233,63 @@ var _ = Describe("Client", func() {
 			Expect(c.HandshakeTimeout).To(Equal(protocol.DefaultHandshakeTimeout))
 			Expect(c.IdleTimeout).To(Equal(protocol.DefaultIdleTimeout))
 			Expect(c.RequestConnectionIDOmission).To(BeFalse())
+			Expect(c.MaxIncomingStreams).To(Equal(protocol.DefaultMaxIncomingStreams))
+			Expect(c.MinPacketSize).To(BeEquivalentTo(protocol.MaxPacketSize))
+			Expect(c.MaxPacketSize).To(BeEquivalentTo(protocol.MaxReceivePacketSize))
+			Expect(c.FEC).To(BeNil())
+			Expect(c.Multipath).To(BeNil())
+			Expect(reflect.ValueOf(c.CongestionControl)).To(Equal(reflect.ValueOf(NewCubic)))
+			Expect(c.InitialPacingBurst).To(Equal(protocol.DefaultInitialPacingBurst))
+		})
+
+		It("fills in default values for FEC", func() {
+			c := populateClientConfig(&Config{FEC: &FECConfig{}})
+			Expect(c.FEC).To(Equal(&FECConfig{
+				DataPackets:   protocol.DefaultFECDataPackets,
+				RepairPackets: protocol.DefaultFECRepairPackets,
+			}))
+		})
+
+		It("limits the FEC parameters", func() {
+			c := populateClientConfig(&Config{FEC: &FECConfig{DataPackets: 1000, RepairPackets: 1000}})
+			Expect(c.FEC).To(Equal(&FECConfig{
+				DataPackets:   protocol.MaxFECDataPackets,
+				RepairPackets: protocol.MaxFECRepairPackets,
+			}))
+		})
+
+		It("fills in default values for the ACK policy", func() {
+			c := populateClientConfig(&Config{})
+			Expect(c.AckPolicy).To(Equal(&AckPolicy{
+				PacketThreshold: protocol.RetransmittablePacketsBeforeAck,
+				MaxAckDelay:     protocol.AckSendDelay,
+			}))
+		})
+
+		It("copies the ACK policy, and limits the maximum ACK delay", func() {
+			c := populateClientConfig(&Config{AckPolicy: &AckPolicy{PacketThreshold: 2, MaxAckDelay: 5 * time.Millisecond, IgnoreReordering: true}})
+			Expect(c.AckPolicy).To(Equal(&AckPolicy{PacketThreshold: 2, MaxAckDelay: 5 * time.Millisecond, IgnoreReordering: true}))
+			c = populateClientConfig(&Config{AckPolicy: &AckPolicy{MaxAckDelay: time.Second}})
+			Expect(c.AckPolicy.MaxAckDelay).To(Equal(protocol.MaxAckDelayLimit))
+		})
+
+		It("limits the packet sizes", func() {
+			c := populateClientConfig(&Config{MinPacketSize: 1000, MaxPacketSize: 100000})
+			Expect(c.MinPacketSize).To(BeEquivalentTo(protocol.MaxPacketSize))
+			Expect(c.MaxPacketSize).To(BeEquivalentTo(protocol.MaxUDPPayloadSize))
+			c = populateClientConfig(&Config{MinPacketSize: 1400, MaxPacketSize: 1300})
+			Expect(c.MinPacketSize).To(BeEquivalentTo(1300))
+			Expect(c.MaxPacketSize).To(BeEquivalentTo(1300))
+		})
+
+		It("allows packet sizes larger than 1452 bytes, for jumbo frames", func() {
+			c := populateClientConfig(&Config{MaxPacketSize: 9000})
+			Expect(c.MaxPacketSize).To(BeEquivalentTo(9000))
+		})
+
+		It("copies the multipath config", func() {
+			c := populateClientConfig(&Config{Multipath: &MultipathConfig{Scheduler: SchedulerRedundant}})
+			Expect(c.Multipath).To(Equal(&MultipathConfig{Scheduler: SchedulerRedundant}))
 		})
 
 		It("errors when receiving an error from the connection", func() {
@@ -256,7 +327,7 @@ var _ = Describe("Client", func() {
 				b := &bytes.Buffer{}
 				err := ph.Write(b, protocol.PerspectiveServer, protocol.VersionWhatever)
 				Expect(err).ToNot(HaveOccurred())
-				cl.handlePacket(nil, b.Bytes())
+				cl.handlePacket(nil, b.Bytes(), protocol.ECNNon)
 				Expect(cl.versionNegotiated).To(BeTrue())
 				Expect(cl.versionNegotiationChan).To(BeClosed())
 			})
@@ -346,17 +417,17 @@ var _ = Describe("Client", func() {
 				newVersion := protocol.VersionNumber(77)
 				Expect(newVersion).ToNot(Equal(cl.version))
 				Expect(config.Versions).To(ContainElement(newVersion))
-				cl.handlePacket(nil, wire.ComposeGQUICVersionNegotiation(0x1337, []protocol.VersionNumber{newVersion}))
+				cl.handlePacket(nil, wire.ComposeGQUICVersionNegotiation(0x1337, []protocol.VersionNumber{newVersion}), protocol.ECNNon)
 				Eventually(func() uint32 { return atomic.LoadUint32(&sessionCounter) }).Should(BeEquivalentTo(2))
 				newVersion = protocol.VersionNumber(78)
 				Expect(newVersion).ToNot(Equal(cl.version))
 				Expect(config.Versions).To(ContainElement(newVersion))
-				cl.handlePacket(nil, wire.ComposeGQUICVersionNegotiation(0x1337, []protocol.VersionNumber{newVersion}))
+				cl.handlePacket(nil, wire.ComposeGQUICVersionNegotiation(0x1337, []protocol.VersionNumber{newVersion}), protocol.ECNNon)
 				Consistently(func() uint32 { return atomic.LoadUint32(&sessionCounter) }).Should(BeEquivalentTo(2))
 			})
 
 			It("errors if no matching version is found", func() {
-				cl.handlePacket(nil, wire.ComposeGQUICVersionNegotiation(0x1337, []protocol.VersionNumber{1}))
+				cl.handlePacket(nil, wire.ComposeGQUICVersionNegotiation(0x1337, []protocol.VersionNumber{1}), protocol.ECNNon)
 				Expect(cl.session.(*mockSession).closed).To(BeTrue())
 				Expect(cl.session.(*mockSession).closeReason).To(MatchError(qerr.InvalidVersion))
 			})
@@ -365,13 +436,13 @@ var _ = Describe("Client", func() {
 				v := protocol.VersionNumber(111)
 				Expect(v).ToNot(Equal(cl.version))
 				Expect(config.Versions).ToNot(ContainElement(v))
-				cl.handlePacket(nil, wire.ComposeGQUICVersionNegotiation(0x1337, []protocol.VersionNumber{v}))
+				cl.handlePacket(nil, wire.ComposeGQUICVersionNegotiation(0x1337, []protocol.VersionNumber{v}), protocol.ECNNon)
 				Expect(cl.session.(*mockSession).closed).To(BeTrue())
 				Expect(cl.session.(*mockSession).closeReason).To(MatchError(qerr.InvalidVersion))
 			})
 
 			It("changes to the version preferred by the quic.Config", func() {
-				cl.handlePacket(nil, wire.ComposeGQUICVersionNegotiation(0x1337, []protocol.VersionNumber{config.Versions[2], config.Versions[1]}))
+				cl.handlePacket(nil, wire.ComposeGQUICVersionNegotiation(0x1337, []protocol.VersionNumber{config.Versions[2], config.Versions[1]}), protocol.ECNNon)
 				Expect(cl.version).To(Equal(config.Versions[1]))
 			})
 
@@ -379,21 +450,21 @@ var _ = Describe("Client", func() {
 				// if the version was not yet negotiated, handlePacket would return a VersionNegotiationMismatch error, see above test
 				cl.versionNegotiated = true
 				Expect(sess.packetCount).To(BeZero())
-				cl.handlePacket(nil, wire.ComposeGQUICVersionNegotiation(0x1337, []protocol.VersionNumber{1}))
+				cl.handlePacket(nil, wire.ComposeGQUICVersionNegotiation(0x1337, []protocol.VersionNumber{1}), protocol.ECNNon)
 				Expect(cl.versionNegotiated).To(BeTrue())
 				Expect(sess.packetCount).To(BeZero())
 			})
 
 			It("drops version negotiation packets that contain the offered version", func() {
 				ver := cl.version
-				cl.handlePacket(nil, wire.ComposeGQUICVersionNegotiation(0x1337, []protocol.VersionNumber{ver}))
+				cl.handlePacket(nil, wire.ComposeGQUICVersionNegotiation(0x1337, []protocol.VersionNumber{ver}), protocol.ECNNon)
 				Expect(cl.version).To(Equal(ver))
 			})
 		})
 	})
 
 	It("ignores packets with an invalid public header", func() {
-		cl.handlePacket(addr, []byte("invalid packet"))
+		cl.handlePacket(addr, []byte("invalid packet"), protocol.ECNNon)
 		Expect(sess.packetCount).To(BeZero())
 		Expect(sess.closed).To(BeFalse())
 	})
@@ -406,7 +477,7 @@ var _ = Describe("Client", func() {
 			PacketNumber:     1,
 			PacketNumberLen:  1,
 		}).Write(buf, protocol.PerspectiveServer, protocol.VersionWhatever)
-		cl.handlePacket(addr, buf.Bytes())
+		cl.handlePacket(addr, buf.Bytes(), protocol.ECNNon)
 		Expect(sess.packetCount).To(BeZero())
 		Expect(sess.closed).To(BeFalse())
 	})
@@ -418,7 +489,7 @@ var _ = Describe("Client", func() {
 			PacketNumber:    1,
 			PacketNumberLen: 1,
 		}).Write(buf, protocol.PerspectiveServer, protocol.VersionWhatever)
-		cl.handlePacket(addr, buf.Bytes())
+		cl.handlePacket(addr, buf.Bytes(), protocol.ECNNon)
 		Expect(sess.packetCount).To(BeZero())
 		Expect(sess.closed).To(BeFalse())
 	})
@@ -571,28 +642,28 @@ var _ = Describe("Client", func() {
 
 	Context("Public Reset handling", func() {
 		It("closes the session when receiving a Public Reset", func() {
-			cl.handlePacket(addr, wire.WritePublicReset(cl.connectionID, 1, 0))
+			cl.handlePacket(addr, wire.WritePublicReset(cl.connectionID, 1, 0), protocol.ECNNon)
 			Expect(cl.session.(*mockSession).closed).To(BeTrue())
 			Expect(cl.session.(*mockSession).closedRemote).To(BeTrue())
 			Expect(cl.session.(*mockSession).closeReason.(*qerr.QuicError).ErrorCode).To(Equal(qerr.PublicReset))
 		})
 
 		It("ignores Public Resets with the wrong connection ID", func() {
-			cl.handlePacket(addr, wire.WritePublicReset(cl.connectionID+1, 1, 0))
+			cl.handlePacket(addr, wire.WritePublicReset(cl.connectionID+1, 1, 0), protocol.ECNNon)
 			Expect(cl.session.(*mockSession).closed).To(BeFalse())
 			Expect(cl.session.(*mockSession).closedRemote).To(BeFalse())
 		})
 
 		It("ignores Public Resets from the wrong remote address", func() {
 			spoofedAddr := &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 5678}
-			cl.handlePacket(spoofedAddr, wire.WritePublicReset(cl.connectionID, 1, 0))
+			cl.handlePacket(spoofedAddr, wire.WritePublicReset(cl.connectionID, 1, 0), protocol.ECNNon)
 			Expect(cl.session.(*mockSession).closed).To(BeFalse())
 			Expect(cl.session.(*mockSession).closedRemote).To(BeFalse())
 		})
 
 		It("ignores unparseable Public Resets", func() {
 			pr := wire.WritePublicReset(cl.connectionID, 1, 0)
-			cl.handlePacket(addr, pr[:len(pr)-5])
+			cl.handlePacket(addr, pr[:len(pr)-5], protocol.ECNNon)
 			Expect(cl.session.(*mockSession).closed).To(BeFalse())
 			Expect(cl.session.(*mockSession).closedRemote).To(BeFalse())
 		})
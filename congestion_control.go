@@ -0,0 +1,92 @@
+package quic
+
+import (
+	"net"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/congestion"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+)
+
+// NewCubic creates a Cubic congestion controller.
+// This is the default congestion controller.
+// It uses HyStart++ to exit slow start, with the default parameters.
+func NewCubic(remoteAddr net.Addr, rttStats *RTTStats) CongestionControl {
+	return NewCubicWithSlowStart(nil)(remoteAddr, rttStats)
+}
+
+// NewReno creates a NewReno congestion controller.
+// It uses HyStart++ to exit slow start, with the default parameters.
+func NewReno(remoteAddr net.Addr, rttStats *RTTStats) CongestionControl {
+	return NewRenoWithSlowStart(nil)(remoteAddr, rttStats)
+}
+
+// NewCubicWithSlowStart returns a function that creates Cubic congestion controllers using the given slow start configuration,
+// to be used as Config.CongestionControl.
+// If config is nil, HyStart++ is used with the default parameters.
+func NewCubicWithSlowStart(config *SlowStartConfig) func(net.Addr, *RTTStats) CongestionControl {
+	return newCubicSender(false, config)
+}
+
+// NewRenoWithSlowStart returns a function that creates NewReno congestion controllers using the given slow start configuration,
+// to be used as Config.CongestionControl.
+// If config is nil, HyStart++ is used with the default parameters.
+func NewRenoWithSlowStart(config *SlowStartConfig) func(net.Addr, *RTTStats) CongestionControl {
+	return newCubicSender(true, config)
+}
+
+func newCubicSender(reno bool, config *SlowStartConfig) func(net.Addr, *RTTStats) CongestionControl {
+	return func(_ net.Addr, rttStats *RTTStats) CongestionControl {
+		return congestion.NewCubicSender(
+			congestion.DefaultClock{},
+			rttStats,
+			reno,
+			congestion.NewSlowStart(config),
+			protocol.InitialCongestionWindow,
+			protocol.DefaultMaxCongestionWindow,
+		)
+	}
+}
+
+// NewBBR creates a BBR congestion controller.
+// BBR estimates the bottleneck bandwidth and the round-trip propagation time of the path, and paces packets accordingly.
+// It doesn't reduce its sending rate in response to random packet loss.
+func NewBBR(_ net.Addr, rttStats *RTTStats) CongestionControl {
+	return congestion.NewBBRSender(
+		congestion.DefaultClock{},
+		rttStats,
+		protocol.InitialCongestionWindow,
+		protocol.DefaultMaxCongestionWindow,
+	)
+}
+
+// NewLEDBAT creates a LEDBAT congestion controller, intended for background transfers.
+// LEDBAT is a scavenger: it uses the capacity that other connections leave unused, and backs off when the queuing delay rises above the target of 60ms.
+// Since Cubic and NewReno only back off on packet loss, connections using LEDBAT yield to them.
+func NewLEDBAT(remoteAddr net.Addr, rttStats *RTTStats) CongestionControl {
+	return NewLEDBATWithTarget(0)(remoteAddr, rttStats)
+}
+
+// NewLEDBATWithTarget returns a function that creates LEDBAT congestion controllers with the given target queuing delay,
+// to be used as Config.CongestionControl.
+// The queuing delay is the difference between the current RTT and the minimum RTT of the connection.
+// If target is 0, the default of 60ms is used.
+func NewLEDBATWithTarget(target time.Duration) func(net.Addr, *RTTStats) CongestionControl {
+	return func(_ net.Addr, rttStats *RTTStats) CongestionControl {
+		return congestion.NewLEDBATSender(
+			rttStats,
+			target,
+			protocol.InitialCongestionWindow,
+			protocol.DefaultMaxCongestionWindow,
+		)
+	}
+}
+
+// NewFixedWindow returns a function that creates congestion controllers with a congestion window of a fixed size,
+// to be used as Config.CongestionControl.
+// The congestion window doesn't react to packet loss. This is only intended for testing.
+func NewFixedWindow(window ByteCount) func(net.Addr, *RTTStats) CongestionControl {
+	return func(_ net.Addr, rttStats *RTTStats) CongestionControl {
+		return congestion.NewFixedWindowSender(rttStats, window)
+	}
+}
@@ -3,11 +3,17 @@ package quic
 import (
 	"net"
 	"sync"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
 )
 
 type connection interface {
 	Write([]byte) error
-	Read([]byte) (int, net.Addr, error)
+	WriteWithECN([]byte, protocol.ECN) error
+	// WriteBatch writes multiple packets, using as few system calls as possible
+	WriteBatch([][]byte, protocol.ECN) error
+	// Read reads a packet, and the ECN codepoint it was received with
+	Read([]byte) (int, net.Addr, protocol.ECN, error)
 	Close() error
 	LocalAddr() net.Addr
 	RemoteAddr() net.Addr
@@ -19,6 +25,9 @@ type conn struct {
 
 	pconn       net.PacketConn
 	currentAddr net.Addr
+
+	oob       []byte    // the buffer for the ancillary data, only used by Read
+	batchConn batchConn // created when WriteBatch is called for the first time
 }
 
 var _ connection = &conn{}
@@ -28,8 +37,22 @@ func (c *conn) Write(p []byte) error {
 	return err
 }
 
-func (c *conn) Read(p []byte) (int, net.Addr, error) {
-	return c.pconn.ReadFrom(p)
+func (c *conn) WriteWithECN(p []byte, ecn protocol.ECN) error {
+	return writeToWithECN(c.pconn, p, c.currentAddr, ecn)
+}
+
+func (c *conn) WriteBatch(packets [][]byte, ecn protocol.ECN) error {
+	if c.batchConn == nil {
+		c.batchConn = newBatchConn(c.pconn)
+	}
+	return c.batchConn.WriteBatch(packets, c.currentAddr, ecn)
+}
+
+func (c *conn) Read(p []byte) (int, net.Addr, protocol.ECN, error) {
+	if c.oob == nil {
+		c.oob = make([]byte, ecnOOBSize)
+	}
+	return readFromWithECN(c.pconn, p, c.oob)
 }
 
 func (c *conn) SetCurrentRemoteAddr(addr net.Addr) {
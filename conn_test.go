@@ -6,6 +6,8 @@ import (
 	"net"
 	"time"
 
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -78,15 +80,31 @@ var _ = Describe("Connection", func() {
 		Expect(packetConn.dataWrittenTo.String()).To(Equal("192.168.100.200:1337"))
 	})
 
+	It("writes with ECN, if the connection doesn't support it", func() {
+		err := c.WriteWithECN([]byte("foobar"), protocol.ECT0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(packetConn.dataWritten.Bytes()).To(Equal([]byte("foobar")))
+		Expect(packetConn.dataWrittenTo.String()).To(Equal("192.168.100.200:1337"))
+	})
+
+	It("writes a batch of packets", func() {
+		err := c.WriteBatch([][]byte{[]byte("foo"), []byte("bar")}, protocol.ECNNon)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(packetConn.dataWritten.Bytes()).To(Equal([]byte("foobar")))
+		Expect(packetConn.dataWrittenTo.String()).To(Equal("192.168.100.200:1337"))
+	})
+
 	It("reads", func() {
 		packetConn.dataToRead <- []byte("foo")
 		packetConn.dataReadFrom = &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1336}
 		p := make([]byte, 10)
-		n, raddr, err := c.Read(p)
+		n, raddr, ecn, err := c.Read(p)
 		Expect(err).ToNot(HaveOccurred())
 		Expect(raddr.String()).To(Equal("127.0.0.1:1336"))
 		Expect(n).To(Equal(3))
 		Expect(p[0:3]).To(Equal([]byte("foo")))
+		// the ECN codepoint can only be read from a *net.UDPConn
+		Expect(ecn).To(Equal(protocol.ECNNon))
 	})
 
 	It("gets the remote address", func() {
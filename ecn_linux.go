@@ -0,0 +1,121 @@
+// +build linux
+
+package quic
+
+import (
+	"net"
+	"syscall"
+	"unsafe"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+)
+
+// ecnOOBSize is the size of the buffer used to receive the ancillary data of a packet
+var ecnOOBSize = syscall.CmsgSpace(4)
+
+// enableECN requests the TOS / TCLASS field to be reported for received packets.
+// ECN is only supported if the net.PacketConn is a *net.UDPConn.
+func enableECN(c net.PacketConn) bool {
+	udpConn, ok := c.(*net.UDPConn)
+	if !ok {
+		return false
+	}
+	rawConn, err := udpConn.SyscallConn()
+	if err != nil {
+		return false
+	}
+	var errIPv4, errIPv6 error
+	if err := rawConn.Control(func(fd uintptr) {
+		// A dual-stack socket receives IPv4 packets as well, so we set both options.
+		// For IPv4 sockets, setting the IPv6 option fails.
+		errIPv4 = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IP, syscall.IP_RECVTOS, 1)
+		errIPv6 = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IPV6, syscall.IPV6_RECVTCLASS, 1)
+	}); err != nil {
+		return false
+	}
+	return errIPv4 == nil || errIPv6 == nil
+}
+
+// readFromWithECN reads a packet, and the ECN codepoint it was received with.
+// oob is the buffer used for the ancillary data, it must be at least ecnOOBSize bytes long.
+func readFromWithECN(c net.PacketConn, b, oob []byte) (int, net.Addr, protocol.ECN, error) {
+	udpConn, ok := c.(*net.UDPConn)
+	if !ok {
+		n, addr, err := c.ReadFrom(b)
+		return n, addr, protocol.ECNNon, err
+	}
+	n, oobn, _, addr, err := udpConn.ReadMsgUDP(b, oob)
+	if err != nil {
+		// don't return a typed nil
+		return n, nil, protocol.ECNNon, err
+	}
+	return n, addr, parseECN(oob[:oobn]), nil
+}
+
+func parseECN(oob []byte) protocol.ECN {
+	if len(oob) == 0 {
+		return protocol.ECNNon
+	}
+	msgs, err := syscall.ParseSocketControlMessage(oob)
+	if err != nil {
+		return protocol.ECNNon
+	}
+	for _, msg := range msgs {
+		if len(msg.Data) == 0 {
+			continue
+		}
+		switch {
+		case msg.Header.Level == syscall.IPPROTO_IP && msg.Header.Type == syscall.IP_TOS:
+			// the kernel reports the TOS as a single byte
+			return protocol.ECN(msg.Data[0] & 0x3)
+		case msg.Header.Level == syscall.IPPROTO_IPV6 && msg.Header.Type == syscall.IPV6_TCLASS:
+			// the kernel reports the traffic class as an int
+			if len(msg.Data) < 4 {
+				continue
+			}
+			return protocol.ECN(*(*int32)(unsafe.Pointer(&msg.Data[0])) & 0x3)
+		}
+	}
+	return protocol.ECNNon
+}
+
+// writeToWithECN sends a packet with the ECN codepoint ecn.
+func writeToWithECN(c net.PacketConn, b []byte, addr net.Addr, ecn protocol.ECN) error {
+	udpConn, ok := c.(*net.UDPConn)
+	udpAddr, isUDPAddr := addr.(*net.UDPAddr)
+	if !ok || !isUDPAddr || ecn == protocol.ECNNon {
+		_, err := c.WriteTo(b, addr)
+		return err
+	}
+	_, _, err := udpConn.WriteMsgUDP(b, ecnControlMessage(udpAddr, ecn), udpAddr)
+	return err
+}
+
+// ecnControlMessages holds the ancillary data to set the ECN codepoint of an outgoing packet,
+// for IPv4 (index 0) and IPv6 (index 1) packets.
+// IPv4 packets sent on a dual-stack socket also use IP_TOS.
+var ecnControlMessages = [2][4][]byte{}
+
+func init() {
+	for ecn := protocol.ECNNon; ecn <= protocol.ECNCE; ecn++ {
+		ecnControlMessages[0][ecn] = newECNControlMessage(syscall.IPPROTO_IP, syscall.IP_TOS, ecn)
+		ecnControlMessages[1][ecn] = newECNControlMessage(syscall.IPPROTO_IPV6, syscall.IPV6_TCLASS, ecn)
+	}
+}
+
+func ecnControlMessage(addr *net.UDPAddr, ecn protocol.ECN) []byte {
+	if addr.IP.To4() != nil {
+		return ecnControlMessages[0][ecn&0x3]
+	}
+	return ecnControlMessages[1][ecn&0x3]
+}
+
+func newECNControlMessage(level, typ int32, ecn protocol.ECN) []byte {
+	oob := make([]byte, syscall.CmsgSpace(4))
+	h := (*syscall.Cmsghdr)(unsafe.Pointer(&oob[0]))
+	h.Level = level
+	h.Type = typ
+	h.SetLen(syscall.CmsgLen(4))
+	*(*int32)(unsafe.Pointer(&oob[syscall.CmsgLen(0)])) = int32(ecn)
+	return oob
+}
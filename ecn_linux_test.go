@@ -0,0 +1,49 @@
+// +build linux
+
+package quic
+
+import (
+	"net"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ECN", func() {
+	runTest := func(network string, ip net.IP) {
+		server, err := net.ListenUDP(network, &net.UDPAddr{IP: ip})
+		if err != nil {
+			Skip("can't listen on " + ip.String())
+		}
+		defer server.Close()
+		Expect(enableECN(server)).To(BeTrue())
+		client, err := net.ListenUDP(network, &net.UDPAddr{IP: ip})
+		Expect(err).ToNot(HaveOccurred())
+		defer client.Close()
+
+		b := make([]byte, 100)
+		oob := make([]byte, ecnOOBSize)
+		for _, ecn := range []protocol.ECN{protocol.ECT0, protocol.ECT1, protocol.ECNCE, protocol.ECNNon} {
+			Expect(writeToWithECN(client, []byte("foobar"), server.LocalAddr(), ecn)).To(Succeed())
+			n, addr, receivedECN, err := readFromWithECN(server, b, oob)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b[:n]).To(Equal([]byte("foobar")))
+			Expect(addr.String()).To(Equal(client.LocalAddr().String()))
+			Expect(receivedECN).To(Equal(ecn))
+		}
+	}
+
+	It("sends and receives ECN codepoints over IPv4", func() {
+		runTest("udp4", net.IPv4(127, 0, 0, 1))
+	})
+
+	It("sends and receives ECN codepoints over IPv6", func() {
+		runTest("udp6", net.IPv6loopback)
+	})
+
+	It("doesn't enable ECN if the connection is not a *net.UDPConn", func() {
+		Expect(enableECN(newMockPacketConn())).To(BeFalse())
+	})
+})
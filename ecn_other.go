@@ -0,0 +1,27 @@
+// +build !linux
+
+package quic
+
+import (
+	"net"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+)
+
+// ecnOOBSize is the size of the buffer used to receive the ancillary data of a packet
+var ecnOOBSize = 0
+
+// enableECN is only implemented on Linux
+func enableECN(net.PacketConn) bool { return false }
+
+// readFromWithECN reads a packet. The ECN codepoint can't be read on this platform.
+func readFromWithECN(c net.PacketConn, b, _ []byte) (int, net.Addr, protocol.ECN, error) {
+	n, addr, err := c.ReadFrom(b)
+	return n, addr, protocol.ECNNon, err
+}
+
+// writeToWithECN sends a packet. The ECN codepoint can't be set on this platform.
+func writeToWithECN(c net.PacketConn, b []byte, addr net.Addr, _ protocol.ECN) error {
+	_, err := c.WriteTo(b, addr)
+	return err
+}
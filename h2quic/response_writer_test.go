@@ -41,15 +41,21 @@ func newMockStream(id protocol.StreamID) *mockStream {
 	return s
 }
 
-func (s *mockStream) Close() error                          { s.closed = true; s.ctxCancel(); return nil }
-func (s *mockStream) CancelRead(quic.ErrorCode) error       { s.reset = true; return nil }
-func (s *mockStream) CancelWrite(quic.ErrorCode) error      { panic("not implemented") }
-func (s *mockStream) CloseRemote(offset protocol.ByteCount) { s.remoteClosed = true; s.ctxCancel() }
-func (s mockStream) StreamID() protocol.StreamID            { return s.id }
-func (s *mockStream) Context() context.Context              { return s.ctx }
-func (s *mockStream) SetDeadline(time.Time) error           { panic("not implemented") }
-func (s *mockStream) SetReadDeadline(time.Time) error       { panic("not implemented") }
-func (s *mockStream) SetWriteDeadline(time.Time) error      { panic("not implemented") }
+func (s *mockStream) Close() error                                { s.closed = true; s.ctxCancel(); return nil }
+func (s *mockStream) CancelRead(quic.ErrorCode) error             { s.reset = true; return nil }
+func (s *mockStream) CancelWrite(quic.ErrorCode) error            { panic("not implemented") }
+func (s *mockStream) CloseRemote(offset protocol.ByteCount)       { s.remoteClosed = true; s.ctxCancel() }
+func (s mockStream) StreamID() protocol.StreamID                  { return s.id }
+func (s *mockStream) Context() context.Context                    { return s.ctx }
+func (s *mockStream) SetDeadline(time.Time) error                 { panic("not implemented") }
+func (s *mockStream) SetReadDeadline(time.Time) error             { panic("not implemented") }
+func (s *mockStream) SetWriteDeadline(time.Time) error            { panic("not implemented") }
+func (s *mockStream) SetDeliveryDeadline(time.Time) error         { panic("not implemented") }
+func (s *mockStream) SetWriteCoalescingDelay(time.Duration) error { panic("not implemented") }
+func (s *mockStream) Flush() error                                { panic("not implemented") }
+func (s *mockStream) ReadChunk() (uint64, []byte, bool, error) {
+	panic("not implemented")
+}
 
 func (s *mockStream) Read(p []byte) (int, error) {
 	n, _ := s.dataToRead.Read(p)
@@ -78,7 +78,12 @@ func (s *mockSession) RemoteAddr() net.Addr {
 func (s *mockSession) Context() context.Context {
 	return s.ctx
 }
-func (s *mockSession) ConnectionState() quic.ConnectionState { panic("not implemented") }
+func (s *mockSession) ConnectionState() quic.ConnectionState  { panic("not implemented") }
+func (s *mockSession) AddPath(net.PacketConn) error           { panic("not implemented") }
+func (s *mockSession) SetMaxIncomingStreams(int) error        { panic("not implemented") }
+func (s *mockSession) StreamIDBlockedCount() uint64           { panic("not implemented") }
+func (s *mockSession) BandwidthEstimate() quic.Bandwidth      { panic("not implemented") }
+func (s *mockSession) SetPeerAckPolicy(*quic.AckPolicy) error { panic("not implemented") }
 
 var _ = Describe("H2 server", func() {
 	var (
@@ -0,0 +1,227 @@
+package self_test
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"sync"
+	"time"
+
+	quic "github.com/lucas-clemente/quic-go"
+	"github.com/lucas-clemente/quic-go/integrationtests/tools/testserver"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/testdata"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// recordingCongestionControl records how a congestion controller is used
+type recordingCongestionControl struct {
+	quic.CongestionControl
+
+	mutex            sync.Mutex
+	packetsAcked     int
+	maxBytesInFlight quic.ByteCount
+}
+
+func (c *recordingCongestionControl) OnPacketSent(sentTime time.Time, bytesInFlight quic.ByteCount, pn quic.PacketNumber, bytes quic.ByteCount, isRetransmittable bool) bool {
+	c.mutex.Lock()
+	if bytesInFlight > c.maxBytesInFlight {
+		c.maxBytesInFlight = bytesInFlight
+	}
+	c.mutex.Unlock()
+	return c.CongestionControl.OnPacketSent(sentTime, bytesInFlight, pn, bytes, isRetransmittable)
+}
+
+func (c *recordingCongestionControl) OnPacketAcked(pn quic.PacketNumber, ackedBytes, bytesInFlight quic.ByteCount) {
+	c.mutex.Lock()
+	c.packetsAcked++
+	c.mutex.Unlock()
+	c.CongestionControl.OnPacketAcked(pn, ackedBytes, bytesInFlight)
+}
+
+func (c *recordingCongestionControl) MaxBytesInFlight() quic.ByteCount {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.maxBytesInFlight
+}
+
+func (c *recordingCongestionControl) PacketsAcked() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.packetsAcked
+}
+
+var _ = Describe("Congestion Control", func() {
+	data := testserver.GeneratePRData(1024 * 1024)
+
+	for _, v := range append(protocol.SupportedVersions, protocol.VersionTLS) {
+		version := v
+
+		Context(fmt.Sprintf("with QUIC version %s", version), func() {
+			// download runs a server that sends data to a client, using the congestion controller created by newCC.
+			// It returns the congestion controller used by the server.
+			download := func(newCC func(net.Addr, *quic.RTTStats) quic.CongestionControl) *recordingCongestionControl {
+				var cc *recordingCongestionControl
+				serverConfig := &quic.Config{
+					Versions: []protocol.VersionNumber{version},
+					CongestionControl: func(remoteAddr net.Addr, rttStats *quic.RTTStats) quic.CongestionControl {
+						defer GinkgoRecover()
+						Expect(cc).To(BeNil())
+						cc = &recordingCongestionControl{CongestionControl: newCC(remoteAddr, rttStats)}
+						return cc
+					},
+				}
+				ln, err := quic.ListenAddr("localhost:0", testdata.GetTLSConfig(), serverConfig)
+				Expect(err).ToNot(HaveOccurred())
+				defer ln.Close()
+
+				serverDone := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					defer close(serverDone)
+					sess, err := ln.Accept()
+					Expect(err).ToNot(HaveOccurred())
+					str, err := sess.AcceptStream()
+					Expect(err).ToNot(HaveOccurred())
+					_, err = str.Write(data)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(str.Close()).To(Succeed())
+				}()
+
+				sess, err := quic.DialAddr(
+					fmt.Sprintf("127.0.0.1:%d", ln.Addr().(*net.UDPAddr).Port),
+					&tls.Config{ServerName: "quic.clemente.io", InsecureSkipVerify: true},
+					&quic.Config{Versions: []protocol.VersionNumber{version}},
+				)
+				Expect(err).ToNot(HaveOccurred())
+				defer sess.Close(nil)
+				str, err := sess.OpenStreamSync()
+				Expect(err).ToNot(HaveOccurred())
+				// the server only accepts the stream once it receives data on it
+				_, err = str.Write([]byte{0})
+				Expect(err).ToNot(HaveOccurred())
+				received, err := ioutil.ReadAll(str)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(received).To(Equal(data))
+				Eventually(serverDone).Should(BeClosed())
+				Expect(cc).ToNot(BeNil())
+				return cc
+			}
+
+			// Packets are sent as long as the bytes in flight are smaller than the congestion window.
+			// Packets that are not retransmittable (e.g. ACK-only packets) can be sent when the congestion window is full.
+			// When the PTO expires, 2 probe packets are sent, regardless of the congestion window.
+			const maxBytesInFlightOverhead = 3 * protocol.MaxReceivePacketSize
+
+			It("uses the congestion controller from the config", func() {
+				const window = 10 * 1000
+				cc := download(quic.NewFixedWindow(window))
+				Expect(cc.PacketsAcked()).To(BeNumerically(">", len(data)/int(protocol.MaxReceivePacketSize)))
+				Expect(cc.MaxBytesInFlight()).To(BeNumerically(">", window/2))
+				Expect(cc.MaxBytesInFlight()).To(BeNumerically("<", window+maxBytesInFlightOverhead))
+			})
+
+			It("allows more data in flight with a larger congestion window", func() {
+				const window = 100 * 1000
+				cc := download(quic.NewFixedWindow(window))
+				Expect(cc.MaxBytesInFlight()).To(BeNumerically(">", 10*1000+maxBytesInFlightOverhead))
+				Expect(cc.MaxBytesInFlight()).To(BeNumerically("<", window+maxBytesInFlightOverhead))
+			})
+
+			It("uses NewReno", func() {
+				cc := download(quic.NewReno)
+				Expect(cc.PacketsAcked()).ToNot(BeZero())
+			})
+
+			It("uses LEDBAT", func() {
+				cc := download(quic.NewLEDBAT)
+				Expect(cc.PacketsAcked()).ToNot(BeZero())
+			})
+
+			It("chooses the congestion controller for every session", func() {
+				backgroundConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+				Expect(err).ToNot(HaveOccurred())
+				defer backgroundConn.Close()
+				foregroundConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+				Expect(err).ToNot(HaveOccurred())
+				defer foregroundConn.Close()
+
+				var mutex sync.Mutex
+				controllers := make(map[string]string) // client address -> congestion controller
+				serverConfig := &quic.Config{
+					Versions: []protocol.VersionNumber{version},
+					CongestionControl: func(remoteAddr net.Addr, rttStats *quic.RTTStats) quic.CongestionControl {
+						mutex.Lock()
+						defer mutex.Unlock()
+						if remoteAddr.String() == backgroundConn.LocalAddr().String() {
+							controllers[remoteAddr.String()] = "LEDBAT"
+							return quic.NewLEDBAT(remoteAddr, rttStats)
+						}
+						controllers[remoteAddr.String()] = "Cubic"
+						return quic.NewCubic(remoteAddr, rttStats)
+					},
+				}
+				ln, err := quic.ListenAddr("localhost:0", testdata.GetTLSConfig(), serverConfig)
+				Expect(err).ToNot(HaveOccurred())
+				defer ln.Close()
+
+				go func() {
+					defer GinkgoRecover()
+					for i := 0; i < 2; i++ {
+						sess, err := ln.Accept()
+						Expect(err).ToNot(HaveOccurred())
+						go func() {
+							defer GinkgoRecover()
+							str, err := sess.AcceptStream()
+							Expect(err).ToNot(HaveOccurred())
+							_, err = str.Write(data)
+							Expect(err).ToNot(HaveOccurred())
+							Expect(str.Close()).To(Succeed())
+						}()
+					}
+				}()
+
+				var wg sync.WaitGroup
+				wg.Add(2)
+				for _, c := range []net.PacketConn{backgroundConn, foregroundConn} {
+					go func(pconn net.PacketConn) {
+						defer GinkgoRecover()
+						defer wg.Done()
+						sess, err := quic.Dial(
+							pconn,
+							ln.Addr(),
+							fmt.Sprintf("127.0.0.1:%d", ln.Addr().(*net.UDPAddr).Port),
+							&tls.Config{ServerName: "quic.clemente.io", InsecureSkipVerify: true},
+							&quic.Config{Versions: []protocol.VersionNumber{version}},
+						)
+						Expect(err).ToNot(HaveOccurred())
+						defer sess.Close(nil)
+						str, err := sess.OpenStreamSync()
+						Expect(err).ToNot(HaveOccurred())
+						_, err = str.Write([]byte{0})
+						Expect(err).ToNot(HaveOccurred())
+						received, err := ioutil.ReadAll(str)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(received).To(Equal(data))
+					}(c)
+				}
+				wg.Wait()
+
+				mutex.Lock()
+				defer mutex.Unlock()
+				Expect(controllers).To(Equal(map[string]string{
+					backgroundConn.LocalAddr().String(): "LEDBAT",
+					foregroundConn.LocalAddr().String(): "Cubic",
+				}))
+			})
+
+			It("estimates the bandwidth", func() {
+				cc := download(quic.NewCubic)
+				Expect(cc.BandwidthEstimate()).ToNot(BeZero())
+			})
+		})
+	}
+})
@@ -4,6 +4,7 @@ import (
 	"crypto/tls"
 	"fmt"
 	"net"
+	"time"
 
 	quic "github.com/lucas-clemente/quic-go"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
@@ -78,6 +79,38 @@ var _ = Describe("Handshake tests", func() {
 		})
 	})
 
+	Context("with multiple sockets", func() {
+		for _, v := range []protocol.VersionNumber{protocol.Version39, protocol.VersionTLS} {
+			version := v
+
+			It(fmt.Sprintf("handshakes with many clients using %s", version), func() {
+				serverConfig.Versions = []protocol.VersionNumber{version}
+				serverConfig.NumSockets = 4
+				runServer()
+				const numClients = 16
+				errChan := make(chan error, numClients)
+				for i := 0; i < numClients; i++ {
+					go func() {
+						sess, err := quic.DialAddr(
+							fmt.Sprintf("127.0.0.1:%d", server.Addr().(*net.UDPAddr).Port),
+							&tls.Config{ServerName: "quic.clemente.io", InsecureSkipVerify: true},
+							&quic.Config{Versions: []protocol.VersionNumber{version}},
+						)
+						if err == nil {
+							sess.Close(nil)
+						}
+						errChan <- err
+					}()
+				}
+				for i := 0; i < numClients; i++ {
+					var err error
+					Eventually(errChan, 5*time.Second).Should(Receive(&err))
+					Expect(err).ToNot(HaveOccurred())
+				}
+			})
+		}
+	})
+
 	Context("Certifiate validation", func() {
 		for _, v := range []protocol.VersionNumber{protocol.Version39, protocol.VersionTLS} {
 			version := v
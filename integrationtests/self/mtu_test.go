@@ -0,0 +1,122 @@
+package self_test
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"sync"
+
+	quic "github.com/lucas-clemente/quic-go"
+	"github.com/lucas-clemente/quic-go/integrationtests/tools/testserver"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/testdata"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// packetSizeConn records the size of the largest packet received on a net.PacketConn
+type packetSizeConn struct {
+	net.PacketConn
+
+	mutex         sync.Mutex
+	maxPacketSize int
+}
+
+func (c *packetSizeConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	n, addr, err := c.PacketConn.ReadFrom(b)
+	c.mutex.Lock()
+	if n > c.maxPacketSize {
+		c.maxPacketSize = n
+	}
+	c.mutex.Unlock()
+	return n, addr, err
+}
+
+func (c *packetSizeConn) MaxPacketSize() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.maxPacketSize
+}
+
+var _ = Describe("Path MTU discovery", func() {
+	data := testserver.GeneratePRData(1024 * 1024)
+
+	for _, v := range append(protocol.SupportedVersions, protocol.VersionTLS) {
+		version := v
+
+		Context(fmt.Sprintf("with QUIC version %s", version), func() {
+			// download runs a server that sends data to a client.
+			// It returns the size of the largest packet that the client received.
+			download := func(serverConfig, clientConfig *quic.Config) int {
+				ln, err := quic.ListenAddr("localhost:0", testdata.GetTLSConfig(), serverConfig)
+				Expect(err).ToNot(HaveOccurred())
+				defer ln.Close()
+
+				go func() {
+					defer GinkgoRecover()
+					sess, err := ln.Accept()
+					Expect(err).ToNot(HaveOccurred())
+					str, err := sess.AcceptStream()
+					Expect(err).ToNot(HaveOccurred())
+					_, err = str.Write(data)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(str.Close()).To(Succeed())
+				}()
+
+				udpConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+				Expect(err).ToNot(HaveOccurred())
+				conn := &packetSizeConn{PacketConn: udpConn}
+				sess, err := quic.Dial(
+					conn,
+					ln.Addr(),
+					"quic.clemente.io:443",
+					&tls.Config{InsecureSkipVerify: true},
+					clientConfig,
+				)
+				Expect(err).ToNot(HaveOccurred())
+				defer sess.Close(nil)
+				str, err := sess.OpenStreamSync()
+				Expect(err).ToNot(HaveOccurred())
+				// the server only accepts the stream once it receives data on it
+				_, err = str.Write([]byte{0})
+				Expect(err).ToNot(HaveOccurred())
+				received, err := ioutil.ReadAll(str)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(received).To(Equal(data))
+				return conn.MaxPacketSize()
+			}
+
+			It("sends packets larger than the minimum packet size", func() {
+				serverConfig := &quic.Config{Versions: []protocol.VersionNumber{version}}
+				clientConfig := &quic.Config{Versions: []protocol.VersionNumber{version}}
+				Expect(download(serverConfig, clientConfig)).To(BeNumerically(">", protocol.MaxPacketSize))
+			})
+
+			It("sends packets larger than 1500 bytes, if both peers allow it", func() {
+				// the MTU of the loopback interface is large enough for jumbo frames
+				serverConfig := &quic.Config{
+					Versions:      []protocol.VersionNumber{version},
+					MaxPacketSize: 9000,
+				}
+				clientConfig := &quic.Config{
+					Versions:      []protocol.VersionNumber{version},
+					MaxPacketSize: 9000,
+				}
+				size := download(serverConfig, clientConfig)
+				Expect(size).To(BeNumerically(">", 1500))
+				Expect(size).To(BeNumerically("<=", 9000))
+			})
+
+			It("doesn't send larger packets if path MTU discovery is disabled", func() {
+				serverConfig := &quic.Config{
+					Versions:      []protocol.VersionNumber{version},
+					MaxPacketSize: uint64(protocol.MaxPacketSize),
+				}
+				clientConfig := &quic.Config{Versions: []protocol.VersionNumber{version}}
+				Expect(download(serverConfig, clientConfig)).To(BeNumerically("<=", protocol.MaxPacketSize))
+			})
+		})
+	}
+})
@@ -0,0 +1,119 @@
+package self_test
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"sync"
+
+	quic "github.com/lucas-clemente/quic-go"
+	"github.com/lucas-clemente/quic-go/integrationtests/tools/testserver"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/testdata"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// countingConn counts the bytes received on a net.PacketConn
+type countingConn struct {
+	net.PacketConn
+
+	mutex     sync.Mutex
+	bytesRead int
+}
+
+func (c *countingConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	n, addr, err := c.PacketConn.ReadFrom(b)
+	c.mutex.Lock()
+	c.bytesRead += n
+	c.mutex.Unlock()
+	return n, addr, err
+}
+
+func (c *countingConn) BytesRead() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.bytesRead
+}
+
+var _ = Describe("Multipath", func() {
+	// The paths are bound to different loopback addresses.
+	// On Linux, the whole 127.0.0.0/8 range is routed to the loopback interface.
+	const (
+		firstAddr  = "127.0.0.1:0"
+		secondAddr = "127.0.0.2:0"
+	)
+
+	data := testserver.GeneratePRData(5 * 1024 * 1024)
+
+	listenUDP := func(addr string) net.PacketConn {
+		udpAddr, err := net.ResolveUDPAddr("udp", addr)
+		Expect(err).ToNot(HaveOccurred())
+		conn, err := net.ListenUDP("udp", udpAddr)
+		if err != nil {
+			Skip(fmt.Sprintf("Test requires the loopback address %s: %s", addr, err))
+		}
+		return conn
+	}
+
+	for _, v := range append(protocol.SupportedVersions, protocol.VersionTLS) {
+		version := v
+
+		Context(fmt.Sprintf("with QUIC version %s", version), func() {
+			// download runs a server that sends data to a client using two paths.
+			// It returns the number of bytes that the client received on the second path.
+			download := func(scheduler quic.PathScheduler) int {
+				config := &quic.Config{
+					Versions:  []protocol.VersionNumber{version},
+					Multipath: &quic.MultipathConfig{Scheduler: scheduler},
+				}
+				ln, err := quic.ListenAddr(firstAddr, testdata.GetTLSConfig(), config)
+				Expect(err).ToNot(HaveOccurred())
+				defer ln.Close()
+
+				go func() {
+					defer GinkgoRecover()
+					sess, err := ln.Accept()
+					Expect(err).ToNot(HaveOccurred())
+					str, err := sess.AcceptStream()
+					Expect(err).ToNot(HaveOccurred())
+					_, err = str.Write(data)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(str.Close()).To(Succeed())
+				}()
+
+				sess, err := quic.Dial(
+					listenUDP(firstAddr),
+					ln.Addr(),
+					"quic.clemente.io:443",
+					&tls.Config{InsecureSkipVerify: true},
+					config,
+				)
+				Expect(err).ToNot(HaveOccurred())
+				defer sess.Close(nil)
+				secondPath := &countingConn{PacketConn: listenUDP(secondAddr)}
+				Expect(sess.AddPath(secondPath)).To(Succeed())
+
+				str, err := sess.OpenStreamSync()
+				Expect(err).ToNot(HaveOccurred())
+				// the server only accepts the stream once it receives data on it
+				_, err = str.Write([]byte{0})
+				Expect(err).ToNot(HaveOccurred())
+				received, err := ioutil.ReadAll(str)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(received).To(Equal(data))
+				return secondPath.BytesRead()
+			}
+
+			It("uses both paths with the lowest-RTT scheduler", func() {
+				Expect(download(quic.SchedulerLowestRTT)).ToNot(BeZero())
+			})
+
+			It("sends packets on both paths with the redundant scheduler", func() {
+				Expect(download(quic.SchedulerRedundant)).To(BeNumerically(">", len(data)/4))
+			})
+		})
+	}
+})
@@ -6,6 +6,7 @@ import (
 	"net"
 	"time"
 
+	"github.com/lucas-clemente/quic-go/internal/congestion"
 	"github.com/lucas-clemente/quic-go/internal/handshake"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 )
@@ -25,6 +26,34 @@ type ConnectionState = handshake.ConnectionState
 // An ErrorCode is an application-defined error code.
 type ErrorCode = protocol.ApplicationErrorCode
 
+// A ByteCount is a number of bytes.
+type ByteCount = protocol.ByteCount
+
+// A PacketNumber is a QUIC packet number.
+type PacketNumber = protocol.PacketNumber
+
+// CongestionControl is the interface implemented by congestion controllers.
+// Every connection (and every path of a multipath connection) uses its own congestion controller,
+// which is created by Config.CongestionControl.
+// Warning: This API should not be considered stable and might change soon.
+type CongestionControl = congestion.SendAlgorithm
+
+// RTTStats are the RTT estimates of a connection, which are used by the congestion controller.
+type RTTStats = congestion.RTTStats
+
+// Bandwidth is a bandwidth, in bits per second.
+type Bandwidth = congestion.Bandwidth
+
+// SlowStartConfig configures how the Cubic and NewReno congestion controllers exit slow start,
+// see NewCubicWithSlowStart and NewRenoWithSlowStart.
+// By default, HyStart++ (RFC 9406) is used: when the RTT increases, the congestion window grows more slowly for a few rounds before slow start is exited.
+// If the RTT decreases during that time, slow start continues.
+type SlowStartConfig = congestion.SlowStartConfig
+
+// A BandwidthSample is a sample of the delivery rate of a connection.
+// Congestion controllers receive a sample for every acknowledged packet.
+type BandwidthSample = congestion.BandwidthSample
+
 // Stream is the interface implemented by QUIC streams
 type Stream interface {
 	// StreamID returns the stream ID.
@@ -35,6 +64,14 @@ type Stream interface {
 	// If the stream was canceled by the peer, the error implements the StreamError
 	// interface, and Canceled() == true.
 	io.Reader
+	// ReadChunk reads the next chunk of data that was received on the stream,
+	// without waiting for data at lower offsets to arrive (out-of-order delivery).
+	// It returns the offset of the chunk in the stream, and fin == true for the chunk
+	// that completes the stream. Afterwards, ReadChunk returns io.EOF.
+	// Data that was already consumed by Read is not returned again.
+	// Once ReadChunk was called, Read must not be used any more.
+	// ReadChunk can be made to time out, see SetReadDeadline.
+	ReadChunk() (offset uint64, data []byte, fin bool, err error)
 	// Write writes data to the stream.
 	// Write can be made to time out and return a net.Error with Timeout() == true
 	// after a fixed time limit; see SetDeadline and SetWriteDeadline.
@@ -69,6 +106,22 @@ type Stream interface {
 	// some of the data was successfully written.
 	// A zero value for t means Write will not time out.
 	SetWriteDeadline(t time.Time) error
+	// SetDeliveryDeadline sets the delivery deadline for data passed to future Write calls.
+	// Once the deadline has passed, lost data is not retransmitted any more,
+	// and the peer is told to skip over it.
+	// A zero value for t means that data is delivered reliably.
+	// This is an experimental extension. It returns an error if the peer didn't announce support for it in the handshake.
+	SetDeliveryDeadline(t time.Time) error
+	// SetWriteCoalescingDelay enables the coalescing of small writes.
+	// Data passed to Write is held back for up to the delay, until enough data was written to fill a packet.
+	// This avoids sending many small packets for many small writes.
+	// Write returns without waiting for data that is held back to be sent.
+	// A zero value means that data is sent immediately.
+	// The default value is Config.WriteCoalescingDelay.
+	SetWriteCoalescingDelay(d time.Duration) error
+	// Flush sends the data held back by write coalescing immediately.
+	// It doesn't wait for the data to be sent.
+	Flush() error
 	// SetDeadline sets the read and write deadlines associated
 	// with the connection. It is equivalent to calling both
 	// SetReadDeadline and SetWriteDeadline.
@@ -81,6 +134,8 @@ type ReceiveStream interface {
 	StreamID() StreamID
 	// see Stream.Read
 	io.Reader
+	// see Stream.ReadChunk
+	ReadChunk() (offset uint64, data []byte, fin bool, err error)
 	// see Stream.CancelRead
 	CancelRead(ErrorCode) error
 	// see Stream.SetReadDealine
@@ -101,6 +156,12 @@ type SendStream interface {
 	Context() context.Context
 	// see Stream.SetWriteDeadline
 	SetWriteDeadline(t time.Time) error
+	// see Stream.SetDeliveryDeadline
+	SetDeliveryDeadline(t time.Time) error
+	// see Stream.SetWriteCoalescingDelay
+	SetWriteCoalescingDelay(d time.Duration) error
+	// see Stream.Flush
+	Flush() error
 }
 
 // StreamError is returned by Read and Write when the peer cancels the stream.
@@ -134,6 +195,27 @@ type Session interface {
 	// ConnectionState returns basic details about the QUIC connection.
 	// Warning: This API should not be considered stable and might change soon.
 	ConnectionState() ConnectionState
+	// AddPath starts using an additional path for this connection, sending and receiving packets on the given PacketConn.
+	// It can only be used by the client, after the handshake completed, and only if both peers enabled multipath.
+	// The session takes ownership of the PacketConn, and closes it when the session is closed.
+	AddPath(net.PacketConn) error
+	// SetMaxIncomingStreams sets the maximum number of concurrent streams that the peer is allowed to open.
+	// An increased limit is announced to the peer immediately.
+	// Since a limit can't be revoked once it was announced, a lower limit only takes effect as soon as the peer closes streams.
+	// This is only supported for IETF QUIC.
+	SetMaxIncomingStreams(int) error
+	// StreamIDBlockedCount returns the number of STREAM_ID_BLOCKED frames received from the peer.
+	// The peer sends a STREAM_ID_BLOCKED frame when it would like to open a new stream, but is blocked by our stream limit.
+	StreamIDBlockedCount() uint64
+	// BandwidthEstimate returns the bandwidth estimate of the congestion controller, based on the delivery rate of the connection.
+	// It returns 0 if no estimate is available yet.
+	// Warning: This API should not be considered stable and might change soon.
+	BandwidthEstimate() Bandwidth
+	// SetPeerAckPolicy asks the peer to change the frequency at which it acknowledges our packets, using an ACK_FREQUENCY frame.
+	// Sending fewer ACKs reduces the processing overhead on high-bandwidth transfers.
+	// It can only be used after the handshake completed, and only if the peer supports the ACK_FREQUENCY extension.
+	// Warning: This API should not be considered stable and might change soon.
+	SetPeerAckPolicy(*AckPolicy) error
 }
 
 // Config contains all configuration data needed for a QUIC server or client.
@@ -166,10 +248,109 @@ type Config struct {
 	// MaxReceiveConnectionFlowControlWindow is the connection-level flow control window for receiving data.
 	// If this value is zero, it will default to 1.5 MB for the server and 15 MB for the client.
 	MaxReceiveConnectionFlowControlWindow uint64
+	// MaxIncomingStreams is the maximum number of concurrent streams that the peer is allowed to open.
+	// It can be changed for a running session using Session.SetMaxIncomingStreams.
+	// If this value is zero, it will default to 100.
+	MaxIncomingStreams int
 	// KeepAlive defines whether this peer will periodically send PING frames to keep the connection alive.
 	KeepAlive bool
+	// MinPacketSize is the size of the packets sent before path MTU discovery found that larger packets can be sent.
+	// It includes the QUIC packet header, but excludes the UDP and IP header.
+	// If this value is zero, it will default to 1200. Smaller values are increased to 1200.
+	MinPacketSize uint64
+	// MaxPacketSize is the largest packet size that is probed by path MTU discovery.
+	// It is limited by the maximum packet size that the peer accepts. Values larger than 1452 bytes allow using jumbo frames.
+	// The receive buffers are sized accordingly, such that the peer can send packets of the same size.
+	// If this value is zero, it will default to 1452. Path MTU discovery is disabled if it is not larger than MinPacketSize.
+	// Path MTU discovery is not used for multipath connections.
+	MaxPacketSize uint64
+	// FEC enables forward error correction.
+	// Repair packets are only sent if the peer enabled FEC as well.
+	// If nil, lost packets are only recovered by retransmissions.
+	FEC *FECConfig
+	// Multipath enables the use of multiple paths for a single connection.
+	// Additional paths are added by the client, using Session.AddPath.
+	// If nil, or if the peer didn't enable multipath, only a single path is used.
+	Multipath *MultipathConfig
+	// NumSockets is the number of sockets that ListenAddr opens on the listening address.
+	// On Linux, the sockets share the port using SO_REUSEPORT, and each socket is read from in its own go routine.
+	// The kernel distributes incoming packets to the sockets by the hash of their 4-tuple.
+	// On other platforms, only a single socket is used. Only applies to the server.
+	// If this value is zero, it will default to 1.
+	NumSockets int
+	// WriteCoalescingDelay is the time that small writes on a stream are held back, such that they can be sent in a single packet.
+	// It can be changed for every stream using SetWriteCoalescingDelay.
+	// If this value is zero, data is sent immediately.
+	WriteCoalescingDelay time.Duration
+	// CongestionControl creates the congestion controller for a connection.
+	// It is called for every connection, and for every path of a multipath connection, with the remote address of the connection or path.
+	// This allows a server to choose a different congestion controller for every connection.
+	// Built-in congestion controllers are created by NewCubic, NewReno, NewBBR, NewLEDBAT and NewFixedWindow.
+	// They ignore the remote address, such that they can be used as CongestionControl directly, or called from a function that chooses between them.
+	// The slow start of Cubic and NewReno can be configured using NewCubicWithSlowStart and NewRenoWithSlowStart.
+	// NewLEDBAT creates a scavenger congestion controller for background transfers, which yields to other connections.
+	// If not set, NewCubic is used.
+	CongestionControl func(remoteAddr net.Addr, rttStats *RTTStats) CongestionControl
+	// InitialPacingBurst is the number of packets that are sent without pacing at the beginning of a connection.
+	// Afterwards, packets are paced at the rate set by the congestion controller.
+	// If this value is zero, it will default to 10.
+	InitialPacingBurst int
+	// AckPolicy determines when we acknowledge packets received from the peer.
+	// The maximum ACK delay is announced to the peer in the transport parameters.
+	// The peer can change the policy for a running session using an ACK_FREQUENCY frame, see Session.SetPeerAckPolicy.
+	// If nil, the default policy is used.
+	AckPolicy *AckPolicy
 }
 
+// AckPolicy configures when ACKs are sent.
+// An ACK is sent as soon as PacketThreshold retransmittable packets were received,
+// or MaxAckDelay after the first unacknowledged retransmittable packet was received, whichever happens first.
+type AckPolicy struct {
+	// PacketThreshold is the number of retransmittable packets that are received before an ACK is sent.
+	// If this value is zero, it defaults to 10. A value of 1 means that every retransmittable packet is acknowledged immediately.
+	PacketThreshold int
+	// MaxAckDelay is the maximum time that an ACK is delayed.
+	// If this value is zero, it defaults to 25ms. The maximum value is 255ms.
+	MaxAckDelay time.Duration
+	// IgnoreReordering disables sending an ACK immediately when a packet arrives out of order.
+	IgnoreReordering bool
+}
+
+// FECConfig configures forward error correction.
+// Outgoing packets are grouped into blocks of DataPackets packets, and RepairPackets repair packets are sent for every block.
+// The receiver can recover up to RepairPackets lost packets of every block, without waiting for a retransmission.
+// More repair packets per block increase the resilience to bursty losses, at the cost of bandwidth.
+// When no more packets are sent, the current block is finished after a short delay, so that the last packets are protected as well.
+// Repair packets are subject to congestion control and pacing, like all other packets.
+type FECConfig struct {
+	// DataPackets is the number of packets in a block.
+	// If this value is zero, it defaults to 10. The maximum value is 32.
+	DataPackets int
+	// RepairPackets is the number of repair packets sent for every block.
+	// If this value is zero, it defaults to 1. The maximum value is 8.
+	RepairPackets int
+}
+
+// MultipathConfig configures the use of multiple paths.
+// Every path has its own packet number space, RTT estimate, congestion controller and loss detection.
+type MultipathConfig struct {
+	// Scheduler decides on which path a packet is sent.
+	// If not set, SchedulerLowestRTT is used.
+	Scheduler PathScheduler
+}
+
+// A PathScheduler decides on which path a packet is sent.
+type PathScheduler int
+
+const (
+	// SchedulerLowestRTT sends every packet on the path with the lowest smoothed RTT that is not congestion limited.
+	// Paths without an RTT estimate are only used when all other paths are congestion limited.
+	SchedulerLowestRTT PathScheduler = iota
+	// SchedulerRedundant sends every packet on all paths that are not congestion limited.
+	// This reduces latency and increases the resilience against packet loss, at the cost of bandwidth.
+	SchedulerRedundant
+)
+
 // A Listener for incoming QUIC connections
 type Listener interface {
 	// Close the server, sending CONNECTION_CLOSE frames to each peer.
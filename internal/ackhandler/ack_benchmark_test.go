@@ -0,0 +1,104 @@
+package ackhandler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/congestion"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/wire"
+)
+
+// benchmarkReceivedAck sends 2*numRanges packets, and processes an ACK frame that acknowledges every other packet,
+// followed by an ACK frame that acknowledges all of them.
+// The first ACK frame declares the packets that it doesn't acknowledge lost (packet threshold),
+// which the second ACK frame then detects as spurious losses.
+func benchmarkReceivedAck(b *testing.B, numRanges int) {
+	rttStats := &congestion.RTTStats{}
+	cong := congestion.NewCubicSender(congestion.DefaultClock{}, rttStats, false, congestion.NewSlowStart(nil), protocol.InitialCongestionWindow, protocol.DefaultMaxCongestionWindow)
+	handler := NewSentPacketHandler(rttStats, cong, protocol.DefaultInitialPacingBurst*protocol.DefaultTCPMSS).(*sentPacketHandler)
+	handler.SetHandshakeComplete()
+	frames := []wire.Frame{&wire.PingFrame{}}
+	ackRanges := make([]wire.AckRange, numRanges)
+	var packetNumber, ackPacketNumber protocol.PacketNumber = 1, 1
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// use a large RTT, such that no packets are declared lost by the time threshold
+		rttStats.UpdateRTT(time.Hour, 0, time.Now())
+		first := packetNumber
+		for j := 0; j < 2*numRanges; j++ {
+			err := handler.SentPacket(&Packet{
+				PacketNumber:    packetNumber,
+				Frames:          frames,
+				Length:          1,
+				EncryptionLevel: protocol.EncryptionForwardSecure,
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+			packetNumber++
+		}
+		// the ACK ranges are sorted in descending order
+		for j := range ackRanges {
+			p := first + protocol.PacketNumber(2*(numRanges-j)-1)
+			ackRanges[j] = wire.AckRange{First: p, Last: p}
+		}
+		ack := &wire.AckFrame{
+			LargestAcked: ackRanges[0].Last,
+			LowestAcked:  ackRanges[numRanges-1].First,
+			AckRanges:    ackRanges,
+		}
+		if err := handler.ReceivedAck(ack, ackPacketNumber, protocol.EncryptionForwardSecure, time.Now()); err != nil {
+			b.Fatal(err)
+		}
+		ackPacketNumber++
+		ack = &wire.AckFrame{LargestAcked: packetNumber - 1, LowestAcked: first}
+		if err := handler.ReceivedAck(ack, ackPacketNumber, protocol.EncryptionForwardSecure, time.Now()); err != nil {
+			b.Fatal(err)
+		}
+		ackPacketNumber++
+		// drain the retransmission queue, as the session would
+		for handler.DequeuePacketForRetransmission() != nil {
+		}
+		if handler.packetHistory.Len() != 0 {
+			b.Fatalf("%d packets outstanding", handler.packetHistory.Len())
+		}
+	}
+}
+
+func BenchmarkReceivedAck10Ranges(b *testing.B)  { benchmarkReceivedAck(b, 10) }
+func BenchmarkReceivedAck100Ranges(b *testing.B) { benchmarkReceivedAck(b, 100) }
+func BenchmarkReceivedAck500Ranges(b *testing.B) { benchmarkReceivedAck(b, 500) }
+
+// benchmarkGetAckFrame receives 2*numRanges packets, every other packet being reordered,
+// and generates an ACK frame after every packet.
+func benchmarkGetAckFrame(b *testing.B, numRanges int) {
+	handler := NewReceivedPacketHandler(protocol.VersionWhatever).(*receivedPacketHandler)
+	var packetNumber protocol.PacketNumber = 1
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		first := packetNumber
+		for j := 0; j < numRanges; j++ {
+			if err := handler.ReceivedPacket(packetNumber+1, time.Now(), true); err != nil {
+				b.Fatal(err)
+			}
+			handler.GetAckFrame()
+			packetNumber += 2
+		}
+		for p := first; p < packetNumber; p += 2 {
+			if err := handler.ReceivedPacket(p, time.Now(), true); err != nil {
+				b.Fatal(err)
+			}
+			handler.GetAckFrame()
+		}
+		handler.IgnoreBelow(packetNumber)
+	}
+}
+
+func BenchmarkGetAckFrame10Ranges(b *testing.B)  { benchmarkGetAckFrame(b, 10) }
+func BenchmarkGetAckFrame100Ranges(b *testing.B) { benchmarkGetAckFrame(b, 100) }
+func BenchmarkGetAckFrame500Ranges(b *testing.B) { benchmarkGetAckFrame(b, 500) }
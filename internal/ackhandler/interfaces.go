@@ -3,16 +3,25 @@ package ackhandler
 import (
 	"time"
 
+	"github.com/lucas-clemente/quic-go/internal/congestion"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/internal/wire"
 )
 
 // SentPacketHandler handles ACKs received for outgoing packets
 type SentPacketHandler interface {
-	// SentPacket may modify the packet
+	// SentPacket may modify the packet.
+	// It sets the ECN codepoint that the packet must be sent with.
 	SentPacket(packet *Packet) error
 	ReceivedAck(ackFrame *wire.AckFrame, withPacketNumber protocol.PacketNumber, encLevel protocol.EncryptionLevel, recvTime time.Time) error
 	SetHandshakeComplete()
+	// SetMaxAckDelay sets the maximum time that the peer delays ACKs.
+	// It is used to calculate the probe timeout.
+	SetMaxAckDelay(time.Duration)
+	// EnableECN enables sending of ECT(0) marked packets.
+	// It must only be called if the peer can report ECN counts in its ACK frames.
+	// ECN is disabled again if the ACK frames show that the ECN marks were cleared on the path.
+	EnableECN()
 
 	// SendingAllowed says if a packet can be sent.
 	// Sending packets might not be possible because:
@@ -20,14 +29,22 @@ type SentPacketHandler interface {
 	// * we're tracking the maximum number of sent packets
 	SendingAllowed() bool
 	// TimeUntilSend is the time when the next packet should be sent.
-	// It is used for pacing packets.
+	// It is used for pacing packets. The zero time means that a packet can be sent immediately.
 	TimeUntilSend() time.Time
 	// ShouldSendNumPackets returns the number of packets that should be sent immediately.
 	// It always returns a number greater or equal than 1.
-	// A number greater than 1 is returned when the pacing delay is smaller than the minimum pacing delay.
+	// A number greater than 1 is returned when the pacer's budget allows sending a burst of packets.
 	// Note that the number of packets is only calculated based on the pacing algorithm.
 	// Before sending any packet, SendingAllowed() must be called to learn if we can actually send it.
 	ShouldSendNumPackets() int
+	// ShouldSendProbePacket says if a probe packet has to be sent, because the probe timeout expired.
+	// Probe packets are sent even if the congestion window is full. If there's no data to send, a PING frame has to be sent.
+	ShouldSendProbePacket() bool
+	// SetAppLimited is called when sending is allowed, but there's no data to send.
+	// Bandwidth samples taken for packets sent until the bytes currently in flight are acknowledged are marked as application-limited.
+	SetAppLimited()
+	// BandwidthEstimate returns the bandwidth estimate of the congestion controller.
+	BandwidthEstimate() congestion.Bandwidth
 
 	GetStopWaitingFrame(force bool) *wire.StopWaitingFrame
 	GetLowestPacketNotConfirmedAcked() protocol.PacketNumber
@@ -41,7 +58,11 @@ type SentPacketHandler interface {
 // ReceivedPacketHandler handles ACKs needed to send for incoming packets
 type ReceivedPacketHandler interface {
 	ReceivedPacket(packetNumber protocol.PacketNumber, rcvTime time.Time, shouldInstigateAck bool) error
+	// ReceivedECN counts the ECN codepoint of a received packet
+	ReceivedECN(protocol.ECN)
 	IgnoreBelow(protocol.PacketNumber)
+	// SetAckPolicy sets when ACKs are sent
+	SetAckPolicy(packetThreshold int, maxAckDelay time.Duration, ignoreReordering bool)
 
 	GetAlarmTimeout() time.Time
 	GetAckFrame() *wire.AckFrame
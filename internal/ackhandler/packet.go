@@ -3,20 +3,36 @@ package ackhandler
 import (
 	"time"
 
+	"github.com/lucas-clemente/quic-go/internal/congestion"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/internal/wire"
 )
 
 // A Packet is a packet
-// +gen linkedlist
 type Packet struct {
 	PacketNumber    protocol.PacketNumber
 	Frames          []wire.Frame
 	Length          protocol.ByteCount
 	EncryptionLevel protocol.EncryptionLevel
+	// IsMTUProbePacket is set for packets sent by path MTU discovery.
+	// When such a packet is lost, it is queued for retransmission (so the session learns about the loss),
+	// but the loss is not reported to the congestion controller.
+	IsMTUProbePacket bool
+	// IsRepairPacket is set for packets that only contain an FEC frame.
+	// They count towards the bytes in flight, but they are not retransmitted when lost.
+	IsRepairPacket bool
+	// ECN is the ECN codepoint that the packet is sent with. It is set by the SentPacketHandler.
+	ECN protocol.ECN
 
-	largestAcked protocol.PacketNumber // if the packet contains an ACK, the LargestAcked value of that ACK
-	sendTime     time.Time
+	largestAcked  protocol.PacketNumber // if the packet contains an ACK, the LargestAcked value of that ACK
+	sendTime      time.Time
+	deliveryState congestion.DeliveryState // used to calculate a bandwidth sample when the packet is acknowledged
+}
+
+// An AckListener is a frame that is notified when the packet it was sent in is acknowledged
+type AckListener interface {
+	wire.Frame
+	OnAcked()
 }
 
 // GetFramesForRetransmission gets all the frames for retransmission
@@ -33,3 +49,12 @@ func (p *Packet) GetFramesForRetransmission() []wire.Frame {
 	}
 	return fs
 }
+
+// onAcked notifies all AckListeners in the packet
+func (p *Packet) onAcked() {
+	for _, frame := range p.Frames {
+		if l, ok := frame.(AckListener); ok {
+			l.OnAcked()
+		}
+	}
+}
@@ -14,7 +14,10 @@ type receivedPacketHandler struct {
 
 	packetHistory *receivedPacketHistory
 
-	ackSendDelay time.Duration
+	// the ACK policy
+	ackSendDelay     time.Duration
+	packetThreshold  int
+	ignoreReordering bool
 
 	packetsReceivedSinceLastAck                int
 	retransmittablePacketsReceivedSinceLastAck int
@@ -22,15 +25,19 @@ type receivedPacketHandler struct {
 	ackAlarm                                   time.Time
 	lastAck                                    *wire.AckFrame
 
+	// ecnCounts is nil until the first packet with an ECN codepoint is received
+	ecnCounts *wire.ECNCounts
+
 	version protocol.VersionNumber
 }
 
 // NewReceivedPacketHandler creates a new receivedPacketHandler
 func NewReceivedPacketHandler(version protocol.VersionNumber) ReceivedPacketHandler {
 	return &receivedPacketHandler{
-		packetHistory: newReceivedPacketHistory(),
-		ackSendDelay:  protocol.AckSendDelay,
-		version:       version,
+		packetHistory:   newReceivedPacketHistory(),
+		ackSendDelay:    protocol.AckSendDelay,
+		packetThreshold: protocol.RetransmittablePacketsBeforeAck,
+		version:         version,
 	}
 }
 
@@ -51,6 +58,37 @@ func (h *receivedPacketHandler) ReceivedPacket(packetNumber protocol.PacketNumbe
 	return nil
 }
 
+// ReceivedECN counts the ECN codepoint of a received packet.
+// For IETF QUIC, the counts are reported in the ACK frames.
+func (h *receivedPacketHandler) ReceivedECN(ecn protocol.ECN) {
+	if ecn == protocol.ECNNon {
+		return
+	}
+	if h.ecnCounts == nil {
+		h.ecnCounts = &wire.ECNCounts{}
+	}
+	switch ecn {
+	case protocol.ECT0:
+		h.ecnCounts.ECT0++
+	case protocol.ECT1:
+		h.ecnCounts.ECT1++
+	case protocol.ECNCE:
+		h.ecnCounts.CE++
+		// the peer should learn about congestion as fast as possible
+		h.ackQueued = true
+		h.ackAlarm = time.Time{}
+	}
+}
+
+// SetAckPolicy sets the ACK policy.
+// An ACK is sent when packetThreshold retransmittable packets were received, or maxAckDelay after the first of them was received.
+// Unless ignoreReordering is set, an ACK is sent immediately when a packet arrives out of order.
+func (h *receivedPacketHandler) SetAckPolicy(packetThreshold int, maxAckDelay time.Duration, ignoreReordering bool) {
+	h.packetThreshold = packetThreshold
+	h.ackSendDelay = maxAckDelay
+	h.ignoreReordering = ignoreReordering
+}
+
 // IgnoreBelow sets a lower limit for acking packets.
 // Packets with packet numbers smaller than p will not be acked.
 func (h *receivedPacketHandler) IgnoreBelow(p protocol.PacketNumber) {
@@ -70,19 +108,21 @@ func (h *receivedPacketHandler) maybeQueueAck(packetNumber protocol.PacketNumber
 		h.ackQueued = true
 	}
 
-	// if the packet number is smaller than the largest acked packet, it must have been reported missing with the last ACK
-	// note that it cannot be a duplicate because they're already filtered out by ReceivedPacket()
-	if h.lastAck != nil && packetNumber < h.lastAck.LargestAcked {
-		h.ackQueued = true
-	}
+	if !h.ignoreReordering {
+		// if the packet number is smaller than the largest acked packet, it must have been reported missing with the last ACK
+		// note that it cannot be a duplicate because they're already filtered out by ReceivedPacket()
+		if h.lastAck != nil && packetNumber < h.lastAck.LargestAcked {
+			h.ackQueued = true
+		}
 
-	// check if a new missing range above the previously was created
-	if h.lastAck != nil && h.packetHistory.GetHighestAckRange().First > h.lastAck.LargestAcked {
-		h.ackQueued = true
+		// check if a new missing range above the previously was created
+		if h.lastAck != nil && h.packetHistory.GetHighestAckRange().First > h.lastAck.LargestAcked {
+			h.ackQueued = true
+		}
 	}
 
 	if !h.ackQueued && shouldInstigateAck {
-		if h.retransmittablePacketsReceivedSinceLastAck >= protocol.RetransmittablePacketsBeforeAck {
+		if h.retransmittablePacketsReceivedSinceLastAck >= h.packetThreshold {
 			h.ackQueued = true
 		} else {
 			if h.ackAlarm.IsZero() {
@@ -112,6 +152,10 @@ func (h *receivedPacketHandler) GetAckFrame() *wire.AckFrame {
 	if len(ackRanges) > 1 {
 		ack.AckRanges = ackRanges
 	}
+	if h.ecnCounts != nil && h.version.UsesIETFFrameFormat() {
+		ecnCounts := *h.ecnCounts
+		ack.ECN = &ecnCounts
+	}
 
 	h.lastAck = ack
 	h.ackAlarm = time.Time{}
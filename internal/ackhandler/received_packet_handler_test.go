@@ -152,6 +152,46 @@ var _ = Describe("receivedPacketHandler", func() {
 				Expect(ack.HasMissingRanges()).To(BeTrue())
 				Expect(ack).ToNot(BeNil())
 			})
+
+			Context("using a custom ACK policy", func() {
+				It("queues an ACK after the configured number of retransmittable packets", func() {
+					handler.SetAckPolicy(2, protocol.AckSendDelay, false)
+					receiveAndAck10Packets()
+					err := handler.ReceivedPacket(11, time.Time{}, true)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(handler.ackQueued).To(BeFalse())
+					err = handler.ReceivedPacket(12, time.Time{}, true)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(handler.ackQueued).To(BeTrue())
+				})
+
+				It("sets the timer using the configured max ACK delay", func() {
+					handler.SetAckPolicy(protocol.RetransmittablePacketsBeforeAck, 5*time.Millisecond, false)
+					receiveAndAck10Packets()
+					rcvTime := time.Now()
+					err := handler.ReceivedPacket(11, rcvTime, true)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(handler.ackQueued).To(BeFalse())
+					Expect(handler.GetAlarmTimeout()).To(Equal(rcvTime.Add(5 * time.Millisecond)))
+				})
+
+				It("doesn't queue an ACK for packets arriving out of order, if reordering is ignored", func() {
+					handler.SetAckPolicy(protocol.RetransmittablePacketsBeforeAck, protocol.AckSendDelay, true)
+					receiveAndAck10Packets()
+					err := handler.ReceivedPacket(11, time.Time{}, true)
+					Expect(err).ToNot(HaveOccurred())
+					err = handler.ReceivedPacket(13, time.Time{}, true)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(handler.ackQueued).To(BeFalse())
+					Expect(handler.GetAckFrame()).ToNot(BeNil())
+					err = handler.ReceivedPacket(12, time.Time{}, true)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(handler.ackQueued).To(BeFalse())
+					err = handler.ReceivedPacket(20, time.Time{}, true)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(handler.ackQueued).To(BeFalse())
+				})
+			})
 		})
 
 		Context("ACK generation", func() {
@@ -227,6 +267,56 @@ var _ = Describe("receivedPacketHandler", func() {
 				}))
 			})
 
+			It("doesn't report ECN counts if no ECN-marked packets were received", func() {
+				handler.version = protocol.VersionTLS
+				err := handler.ReceivedPacket(1, time.Time{}, true)
+				Expect(err).ToNot(HaveOccurred())
+				handler.ReceivedECN(protocol.ECNNon)
+				ack := handler.GetAckFrame()
+				Expect(ack).ToNot(BeNil())
+				Expect(ack.ECN).To(BeNil())
+			})
+
+			It("reports ECN counts", func() {
+				handler.version = protocol.VersionTLS
+				for i := 1; i <= 6; i++ {
+					err := handler.ReceivedPacket(protocol.PacketNumber(i), time.Time{}, true)
+					Expect(err).ToNot(HaveOccurred())
+				}
+				handler.ReceivedECN(protocol.ECT0)
+				handler.ReceivedECN(protocol.ECT0)
+				handler.ReceivedECN(protocol.ECT0)
+				handler.ReceivedECN(protocol.ECT1)
+				handler.ReceivedECN(protocol.ECNCE)
+				handler.ReceivedECN(protocol.ECNNon)
+				ack := handler.GetAckFrame()
+				Expect(ack).ToNot(BeNil())
+				Expect(ack.ECN).To(Equal(&wire.ECNCounts{ECT0: 3, ECT1: 1, CE: 1}))
+			})
+
+			It("doesn't report ECN counts for gQUIC", func() {
+				handler.version = protocol.Version39
+				err := handler.ReceivedPacket(1, time.Time{}, true)
+				Expect(err).ToNot(HaveOccurred())
+				handler.ReceivedECN(protocol.ECT0)
+				ack := handler.GetAckFrame()
+				Expect(ack).ToNot(BeNil())
+				Expect(ack.ECN).To(BeNil())
+			})
+
+			It("queues an ACK when a CE-marked packet is received", func() {
+				err := handler.ReceivedPacket(1, time.Time{}, true)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(handler.GetAckFrame()).ToNot(BeNil())
+				err = handler.ReceivedPacket(2, time.Now(), true)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(handler.ackQueued).To(BeFalse())
+				Expect(handler.GetAlarmTimeout()).ToNot(BeZero())
+				handler.ReceivedECN(protocol.ECNCE)
+				Expect(handler.ackQueued).To(BeTrue())
+				Expect(handler.GetAlarmTimeout()).To(BeZero())
+			})
+
 			It("accepts packets below the lower limit", func() {
 				handler.IgnoreBelow(6)
 				err := handler.ReceivedPacket(2, time.Time{}, true)
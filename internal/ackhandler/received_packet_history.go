@@ -9,69 +9,111 @@ import (
 
 // The receivedPacketHistory stores if a packet number has already been received.
 // It does not store packet contents.
+// The ranges of received packet numbers are kept in a ring buffer, sorted by packet number.
+// Most packets extend the last range, or create a new range at the end, and DeleteBelow removes
+// ranges from the front, so that the ranges only have to be moved when packets are reordered.
 type receivedPacketHistory struct {
-	ranges *utils.PacketIntervalList
+	ranges    []utils.PacketInterval // the length is always a power of 2
+	head      int                    // index of the lowest range
+	numRanges int
 
 	lowestInReceivedPacketNumbers protocol.PacketNumber
 }
 
 var errTooManyOutstandingReceivedAckRanges = qerr.Error(qerr.TooManyOutstandingReceivedPackets, "Too many outstanding received ACK ranges")
 
+const receivedPacketHistoryInitialSize = 8
+
 // newReceivedPacketHistory creates a new received packet history
 func newReceivedPacketHistory() *receivedPacketHistory {
 	return &receivedPacketHistory{
-		ranges: utils.NewPacketIntervalList(),
+		ranges: make([]utils.PacketInterval, receivedPacketHistoryInitialSize),
 	}
 }
 
+// rangeAt returns the i-th lowest range
+func (h *receivedPacketHistory) rangeAt(i int) *utils.PacketInterval {
+	return &h.ranges[(h.head+i)&(len(h.ranges)-1)]
+}
+
 // ReceivedPacket registers a packet with PacketNumber p and updates the ranges
 func (h *receivedPacketHistory) ReceivedPacket(p protocol.PacketNumber) error {
-	if h.ranges.Len() >= protocol.MaxTrackedReceivedAckRanges {
+	if h.numRanges >= protocol.MaxTrackedReceivedAckRanges {
 		return errTooManyOutstandingReceivedAckRanges
 	}
 
-	if h.ranges.Len() == 0 {
-		h.ranges.PushBack(utils.PacketInterval{Start: p, End: p})
-		return nil
+	// Find the first range that ends at or above p-1.
+	// In most cases, this is the last range, so check that one first.
+	i := h.numRanges
+	if h.numRanges > 0 && h.rangeAt(h.numRanges-1).End+1 >= p {
+		i = h.searchRange(p)
 	}
 
-	for el := h.ranges.Back(); el != nil; el = el.Prev() {
-		// p already included in an existing range. Nothing to do here
-		if p >= el.Value.Start && p <= el.Value.End {
-			return nil
-		}
+	if i == h.numRanges { // create a new range at the end
+		h.insertRange(i, utils.PacketInterval{Start: p, End: p})
+		return nil
+	}
 
-		var rangeExtended bool
-		if el.Value.End == p-1 { // extend a range at the end
-			rangeExtended = true
-			el.Value.End = p
-		} else if el.Value.Start == p+1 { // extend a range at the beginning
-			rangeExtended = true
-			el.Value.Start = p
+	r := h.rangeAt(i)
+	// p already included in an existing range. Nothing to do here
+	if p >= r.Start && p <= r.End {
+		return nil
+	}
+	if r.End+1 == p { // extend a range at the end
+		r.End = p
+		// maybe it is possible to merge two ranges into one
+		if i+1 < h.numRanges && h.rangeAt(i+1).Start == p+1 {
+			r.End = h.rangeAt(i + 1).End
+			h.removeRange(i + 1)
 		}
+		return nil
+	}
+	if r.Start == p+1 { // extend a range at the beginning
+		r.Start = p
+		return nil
+	}
+	// create a new range before this range
+	h.insertRange(i, utils.PacketInterval{Start: p, End: p})
+	return nil
+}
 
-		// if a range was extended (either at the beginning or at the end, maybe it is possible to merge two ranges into one)
-		if rangeExtended {
-			prev := el.Prev()
-			if prev != nil && prev.Value.End+1 == el.Value.Start { // merge two ranges
-				prev.Value.End = el.Value.End
-				h.ranges.Remove(el)
-				return nil
-			}
-			return nil // if the two ranges were not merge, we're done here
+// searchRange returns the index of the first range that ends at or above p-1
+func (h *receivedPacketHistory) searchRange(p protocol.PacketNumber) int {
+	lo, hi := 0, h.numRanges
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		if h.rangeAt(mid).End+1 < p {
+			lo = mid + 1
+		} else {
+			hi = mid
 		}
+	}
+	return lo
+}
 
-		// create a new range at the end
-		if p > el.Value.End {
-			h.ranges.InsertAfter(utils.PacketInterval{Start: p, End: p}, el)
-			return nil
+// insertRange inserts a range at index i, moving the following ranges back
+func (h *receivedPacketHistory) insertRange(i int, r utils.PacketInterval) {
+	if h.numRanges == len(h.ranges) {
+		ranges := make([]utils.PacketInterval, 2*len(h.ranges))
+		for j := 0; j < h.numRanges; j++ {
+			ranges[j] = *h.rangeAt(j)
 		}
+		h.ranges = ranges
+		h.head = 0
 	}
+	h.numRanges++
+	for j := h.numRanges - 1; j > i; j-- {
+		*h.rangeAt(j) = *h.rangeAt(j - 1)
+	}
+	*h.rangeAt(i) = r
+}
 
-	// create a new range at the beginning
-	h.ranges.InsertBefore(utils.PacketInterval{Start: p, End: p}, h.ranges.Front())
-
-	return nil
+// removeRange removes the range at index i, moving the following ranges forward
+func (h *receivedPacketHistory) removeRange(i int) {
+	for j := i; j < h.numRanges-1; j++ {
+		*h.rangeAt(j) = *h.rangeAt(j + 1)
+	}
+	h.numRanges--
 }
 
 // DeleteBelow deletes all entries below (but not including) p
@@ -81,39 +123,38 @@ func (h *receivedPacketHistory) DeleteBelow(p protocol.PacketNumber) {
 	}
 	h.lowestInReceivedPacketNumbers = p
 
-	nextEl := h.ranges.Front()
-	for el := h.ranges.Front(); nextEl != nil; el = nextEl {
-		nextEl = el.Next()
-
-		if p > el.Value.Start && p <= el.Value.End {
-			el.Value.Start = p
-		} else if el.Value.End < p { // delete a whole range
-			h.ranges.Remove(el)
-		} else { // no ranges affected. Nothing to do
-			return
+	for h.numRanges > 0 {
+		r := h.rangeAt(0)
+		if r.End < p { // delete a whole range
+			h.head = (h.head + 1) & (len(h.ranges) - 1)
+			h.numRanges--
+			continue
+		}
+		if p > r.Start {
+			r.Start = p
 		}
+		return
 	}
 }
 
 // GetAckRanges gets a slice of all AckRanges that can be used in an AckFrame
 func (h *receivedPacketHistory) GetAckRanges() []wire.AckRange {
-	if h.ranges.Len() == 0 {
+	if h.numRanges == 0 {
 		return nil
 	}
 
-	ackRanges := make([]wire.AckRange, h.ranges.Len())
-	i := 0
-	for el := h.ranges.Back(); el != nil; el = el.Prev() {
-		ackRanges[i] = wire.AckRange{First: el.Value.Start, Last: el.Value.End}
-		i++
+	ackRanges := make([]wire.AckRange, h.numRanges)
+	for i := range ackRanges {
+		r := h.rangeAt(h.numRanges - 1 - i)
+		ackRanges[i] = wire.AckRange{First: r.Start, Last: r.End}
 	}
 	return ackRanges
 }
 
 func (h *receivedPacketHistory) GetHighestAckRange() wire.AckRange {
 	ackRange := wire.AckRange{}
-	if h.ranges.Len() > 0 {
-		r := h.ranges.Back().Value
+	if h.numRanges > 0 {
+		r := h.rangeAt(h.numRanges - 1)
 		ackRange.First = r.Start
 		ackRange.Last = r.End
 	}
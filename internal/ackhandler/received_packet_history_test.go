@@ -20,22 +20,22 @@ var _ = Describe("receivedPacketHistory", func() {
 	Context("ranges", func() {
 		It("adds the first packet", func() {
 			hist.ReceivedPacket(4)
-			Expect(hist.ranges.Len()).To(Equal(1))
-			Expect(hist.ranges.Front().Value).To(Equal(utils.PacketInterval{Start: 4, End: 4}))
+			Expect(hist.numRanges).To(Equal(1))
+			Expect(*hist.rangeAt(0)).To(Equal(utils.PacketInterval{Start: 4, End: 4}))
 		})
 
 		It("doesn't care about duplicate packets", func() {
 			hist.ReceivedPacket(4)
-			Expect(hist.ranges.Len()).To(Equal(1))
-			Expect(hist.ranges.Front().Value).To(Equal(utils.PacketInterval{Start: 4, End: 4}))
+			Expect(hist.numRanges).To(Equal(1))
+			Expect(*hist.rangeAt(0)).To(Equal(utils.PacketInterval{Start: 4, End: 4}))
 		})
 
 		It("adds a few consecutive packets", func() {
 			hist.ReceivedPacket(4)
 			hist.ReceivedPacket(5)
 			hist.ReceivedPacket(6)
-			Expect(hist.ranges.Len()).To(Equal(1))
-			Expect(hist.ranges.Front().Value).To(Equal(utils.PacketInterval{Start: 4, End: 6}))
+			Expect(hist.numRanges).To(Equal(1))
+			Expect(*hist.rangeAt(0)).To(Equal(utils.PacketInterval{Start: 4, End: 6}))
 		})
 
 		It("doesn't care about a duplicate packet contained in an existing range", func() {
@@ -43,69 +43,69 @@ var _ = Describe("receivedPacketHistory", func() {
 			hist.ReceivedPacket(5)
 			hist.ReceivedPacket(6)
 			hist.ReceivedPacket(5)
-			Expect(hist.ranges.Len()).To(Equal(1))
-			Expect(hist.ranges.Front().Value).To(Equal(utils.PacketInterval{Start: 4, End: 6}))
+			Expect(hist.numRanges).To(Equal(1))
+			Expect(*hist.rangeAt(0)).To(Equal(utils.PacketInterval{Start: 4, End: 6}))
 		})
 
 		It("extends a range at the front", func() {
 			hist.ReceivedPacket(4)
 			hist.ReceivedPacket(3)
-			Expect(hist.ranges.Len()).To(Equal(1))
-			Expect(hist.ranges.Front().Value).To(Equal(utils.PacketInterval{Start: 3, End: 4}))
+			Expect(hist.numRanges).To(Equal(1))
+			Expect(*hist.rangeAt(0)).To(Equal(utils.PacketInterval{Start: 3, End: 4}))
 		})
 
 		It("creates a new range when a packet is lost", func() {
 			hist.ReceivedPacket(4)
 			hist.ReceivedPacket(6)
-			Expect(hist.ranges.Len()).To(Equal(2))
-			Expect(hist.ranges.Front().Value).To(Equal(utils.PacketInterval{Start: 4, End: 4}))
-			Expect(hist.ranges.Back().Value).To(Equal(utils.PacketInterval{Start: 6, End: 6}))
+			Expect(hist.numRanges).To(Equal(2))
+			Expect(*hist.rangeAt(0)).To(Equal(utils.PacketInterval{Start: 4, End: 4}))
+			Expect(*hist.rangeAt(hist.numRanges - 1)).To(Equal(utils.PacketInterval{Start: 6, End: 6}))
 		})
 
 		It("creates a new range in between two ranges", func() {
 			hist.ReceivedPacket(4)
 			hist.ReceivedPacket(10)
-			Expect(hist.ranges.Len()).To(Equal(2))
+			Expect(hist.numRanges).To(Equal(2))
 			hist.ReceivedPacket(7)
-			Expect(hist.ranges.Len()).To(Equal(3))
-			Expect(hist.ranges.Front().Value).To(Equal(utils.PacketInterval{Start: 4, End: 4}))
-			Expect(hist.ranges.Front().Next().Value).To(Equal(utils.PacketInterval{Start: 7, End: 7}))
-			Expect(hist.ranges.Back().Value).To(Equal(utils.PacketInterval{Start: 10, End: 10}))
+			Expect(hist.numRanges).To(Equal(3))
+			Expect(*hist.rangeAt(0)).To(Equal(utils.PacketInterval{Start: 4, End: 4}))
+			Expect(*hist.rangeAt(1)).To(Equal(utils.PacketInterval{Start: 7, End: 7}))
+			Expect(*hist.rangeAt(hist.numRanges - 1)).To(Equal(utils.PacketInterval{Start: 10, End: 10}))
 		})
 
 		It("creates a new range before an existing range for a belated packet", func() {
 			hist.ReceivedPacket(6)
 			hist.ReceivedPacket(4)
-			Expect(hist.ranges.Len()).To(Equal(2))
-			Expect(hist.ranges.Front().Value).To(Equal(utils.PacketInterval{Start: 4, End: 4}))
-			Expect(hist.ranges.Back().Value).To(Equal(utils.PacketInterval{Start: 6, End: 6}))
+			Expect(hist.numRanges).To(Equal(2))
+			Expect(*hist.rangeAt(0)).To(Equal(utils.PacketInterval{Start: 4, End: 4}))
+			Expect(*hist.rangeAt(hist.numRanges - 1)).To(Equal(utils.PacketInterval{Start: 6, End: 6}))
 		})
 
 		It("extends a previous range at the end", func() {
 			hist.ReceivedPacket(4)
 			hist.ReceivedPacket(7)
 			hist.ReceivedPacket(5)
-			Expect(hist.ranges.Len()).To(Equal(2))
-			Expect(hist.ranges.Front().Value).To(Equal(utils.PacketInterval{Start: 4, End: 5}))
-			Expect(hist.ranges.Back().Value).To(Equal(utils.PacketInterval{Start: 7, End: 7}))
+			Expect(hist.numRanges).To(Equal(2))
+			Expect(*hist.rangeAt(0)).To(Equal(utils.PacketInterval{Start: 4, End: 5}))
+			Expect(*hist.rangeAt(hist.numRanges - 1)).To(Equal(utils.PacketInterval{Start: 7, End: 7}))
 		})
 
 		It("extends a range at the front", func() {
 			hist.ReceivedPacket(4)
 			hist.ReceivedPacket(7)
 			hist.ReceivedPacket(6)
-			Expect(hist.ranges.Len()).To(Equal(2))
-			Expect(hist.ranges.Front().Value).To(Equal(utils.PacketInterval{Start: 4, End: 4}))
-			Expect(hist.ranges.Back().Value).To(Equal(utils.PacketInterval{Start: 6, End: 7}))
+			Expect(hist.numRanges).To(Equal(2))
+			Expect(*hist.rangeAt(0)).To(Equal(utils.PacketInterval{Start: 4, End: 4}))
+			Expect(*hist.rangeAt(hist.numRanges - 1)).To(Equal(utils.PacketInterval{Start: 6, End: 7}))
 		})
 
 		It("closes a range", func() {
 			hist.ReceivedPacket(6)
 			hist.ReceivedPacket(4)
-			Expect(hist.ranges.Len()).To(Equal(2))
+			Expect(hist.numRanges).To(Equal(2))
 			hist.ReceivedPacket(5)
-			Expect(hist.ranges.Len()).To(Equal(1))
-			Expect(hist.ranges.Front().Value).To(Equal(utils.PacketInterval{Start: 4, End: 6}))
+			Expect(hist.numRanges).To(Equal(1))
+			Expect(*hist.rangeAt(0)).To(Equal(utils.PacketInterval{Start: 4, End: 6}))
 		})
 
 		It("closes a range in the middle", func() {
@@ -113,19 +113,38 @@ var _ = Describe("receivedPacketHistory", func() {
 			hist.ReceivedPacket(10)
 			hist.ReceivedPacket(4)
 			hist.ReceivedPacket(6)
-			Expect(hist.ranges.Len()).To(Equal(4))
+			Expect(hist.numRanges).To(Equal(4))
 			hist.ReceivedPacket(5)
-			Expect(hist.ranges.Len()).To(Equal(3))
-			Expect(hist.ranges.Front().Value).To(Equal(utils.PacketInterval{Start: 1, End: 1}))
-			Expect(hist.ranges.Front().Next().Value).To(Equal(utils.PacketInterval{Start: 4, End: 6}))
-			Expect(hist.ranges.Back().Value).To(Equal(utils.PacketInterval{Start: 10, End: 10}))
+			Expect(hist.numRanges).To(Equal(3))
+			Expect(*hist.rangeAt(0)).To(Equal(utils.PacketInterval{Start: 1, End: 1}))
+			Expect(*hist.rangeAt(1)).To(Equal(utils.PacketInterval{Start: 4, End: 6}))
+			Expect(*hist.rangeAt(hist.numRanges - 1)).To(Equal(utils.PacketInterval{Start: 10, End: 10}))
+		})
+
+		It("inserts and merges ranges when the ring buffer wraps around", func() {
+			for i := protocol.PacketNumber(1); i <= receivedPacketHistoryInitialSize; i++ {
+				Expect(hist.ReceivedPacket(2 * i)).To(Succeed())
+			}
+			hist.DeleteBelow(11) // deletes the ranges of packets 2 to 10
+			for i := protocol.PacketNumber(receivedPacketHistoryInitialSize + 1); i <= 2*receivedPacketHistoryInitialSize; i++ {
+				Expect(hist.ReceivedPacket(2 * i)).To(Succeed())
+			}
+			Expect(hist.numRanges).To(Equal(2*receivedPacketHistoryInitialSize - 5))
+			Expect(hist.ReceivedPacket(17)).To(Succeed()) // merges 16 and 18
+			Expect(hist.ReceivedPacket(25)).To(Succeed()) // merges 24 and 26
+			Expect(hist.ReceivedPacket(13)).To(Succeed()) // merges 12 and 14
+			Expect(hist.numRanges).To(Equal(2*receivedPacketHistoryInitialSize - 8))
+			Expect(*hist.rangeAt(0)).To(Equal(utils.PacketInterval{Start: 12, End: 14}))
+			Expect(*hist.rangeAt(1)).To(Equal(utils.PacketInterval{Start: 16, End: 18}))
+			Expect(*hist.rangeAt(4)).To(Equal(utils.PacketInterval{Start: 24, End: 26}))
+			Expect(*hist.rangeAt(hist.numRanges - 1)).To(Equal(utils.PacketInterval{Start: 32, End: 32}))
 		})
 	})
 
 	Context("deleting", func() {
 		It("does nothing when the history is empty", func() {
 			hist.DeleteBelow(5)
-			Expect(hist.ranges.Len()).To(BeZero())
+			Expect(hist.numRanges).To(BeZero())
 		})
 
 		It("deletes a range", func() {
@@ -133,8 +152,8 @@ var _ = Describe("receivedPacketHistory", func() {
 			hist.ReceivedPacket(5)
 			hist.ReceivedPacket(10)
 			hist.DeleteBelow(6)
-			Expect(hist.ranges.Len()).To(Equal(1))
-			Expect(hist.ranges.Front().Value).To(Equal(utils.PacketInterval{Start: 10, End: 10}))
+			Expect(hist.numRanges).To(Equal(1))
+			Expect(*hist.rangeAt(0)).To(Equal(utils.PacketInterval{Start: 10, End: 10}))
 		})
 
 		It("deletes multiple ranges", func() {
@@ -142,8 +161,8 @@ var _ = Describe("receivedPacketHistory", func() {
 			hist.ReceivedPacket(5)
 			hist.ReceivedPacket(10)
 			hist.DeleteBelow(8)
-			Expect(hist.ranges.Len()).To(Equal(1))
-			Expect(hist.ranges.Front().Value).To(Equal(utils.PacketInterval{Start: 10, End: 10}))
+			Expect(hist.numRanges).To(Equal(1))
+			Expect(*hist.rangeAt(0)).To(Equal(utils.PacketInterval{Start: 10, End: 10}))
 		})
 
 		It("adjusts a range, if packets are delete from an existing range", func() {
@@ -153,8 +172,8 @@ var _ = Describe("receivedPacketHistory", func() {
 			hist.ReceivedPacket(6)
 			hist.ReceivedPacket(7)
 			hist.DeleteBelow(5)
-			Expect(hist.ranges.Len()).To(Equal(1))
-			Expect(hist.ranges.Front().Value).To(Equal(utils.PacketInterval{Start: 5, End: 7}))
+			Expect(hist.numRanges).To(Equal(1))
+			Expect(*hist.rangeAt(0)).To(Equal(utils.PacketInterval{Start: 5, End: 7}))
 		})
 
 		It("adjusts a range, if only one packet remains in the range", func() {
@@ -162,16 +181,16 @@ var _ = Describe("receivedPacketHistory", func() {
 			hist.ReceivedPacket(5)
 			hist.ReceivedPacket(10)
 			hist.DeleteBelow(5)
-			Expect(hist.ranges.Len()).To(Equal(2))
-			Expect(hist.ranges.Front().Value).To(Equal(utils.PacketInterval{Start: 5, End: 5}))
-			Expect(hist.ranges.Back().Value).To(Equal(utils.PacketInterval{Start: 10, End: 10}))
+			Expect(hist.numRanges).To(Equal(2))
+			Expect(*hist.rangeAt(0)).To(Equal(utils.PacketInterval{Start: 5, End: 5}))
+			Expect(*hist.rangeAt(hist.numRanges - 1)).To(Equal(utils.PacketInterval{Start: 10, End: 10}))
 		})
 
 		It("keeps a one-packet range, if deleting up to the packet directly below", func() {
 			hist.ReceivedPacket(4)
 			hist.DeleteBelow(4)
-			Expect(hist.ranges.Len()).To(Equal(1))
-			Expect(hist.ranges.Front().Value).To(Equal(utils.PacketInterval{Start: 4, End: 4}))
+			Expect(hist.numRanges).To(Equal(1))
+			Expect(*hist.rangeAt(0)).To(Equal(utils.PacketInterval{Start: 4, End: 4}))
 		})
 
 		Context("DoS protection", func() {
@@ -20,6 +20,8 @@ func IsFrameRetransmittable(f wire.Frame) bool {
 		return false
 	case *wire.AckFrame:
 		return false
+	case *wire.FECFrame:
+		return false
 	default:
 		return true
 	}
@@ -34,3 +36,14 @@ func HasRetransmittableFrames(fs []wire.Frame) bool {
 	}
 	return false
 }
+
+// ShouldInstigateAck returns true if a packet containing these frames should be acknowledged like a retransmittable packet.
+// This is the case for repair packets as well, since they count towards the sender's bytes in flight.
+func ShouldInstigateAck(fs []wire.Frame) bool {
+	for _, f := range fs {
+		if _, ok := f.(*wire.FECFrame); ok || IsFrameRetransmittable(f) {
+			return true
+		}
+	}
+	return false
+}
@@ -12,6 +12,7 @@ var _ = Describe("retransmittable frames", func() {
 	for fl, el := range map[wire.Frame]bool{
 		&wire.AckFrame{}:             false,
 		&wire.StopWaitingFrame{}:     false,
+		&wire.FECFrame{}:             false,
 		&wire.BlockedFrame{}:         true,
 		&wire.ConnectionCloseFrame{}: true,
 		&wire.GoawayFrame{}:          true,
@@ -42,4 +43,10 @@ var _ = Describe("retransmittable frames", func() {
 			Expect(HasRetransmittableFrames([]wire.Frame{f})).To(Equal(e))
 		})
 	}
+
+	It("instigates ACKs for packets with retransmittable frames, and for repair packets", func() {
+		Expect(ShouldInstigateAck([]wire.Frame{&wire.AckFrame{}, &wire.StopWaitingFrame{}})).To(BeFalse())
+		Expect(ShouldInstigateAck([]wire.Frame{&wire.AckFrame{}, &wire.PingFrame{}})).To(BeTrue())
+		Expect(ShouldInstigateAck([]wire.Frame{&wire.FECFrame{}})).To(BeTrue())
+	})
 })
@@ -15,27 +15,39 @@ import (
 
 const (
 	// Maximum reordering in time space before time based loss detection considers a packet lost.
-	// In fraction of an RTT.
-	timeReorderingFraction = 1.0 / 8
+	// Specified as an RTT multiplier.
+	// The threshold is increased up to maxTimeThreshold when spurious losses are detected.
+	defaultTimeThreshold = 9.0 / 8
+	maxTimeThreshold     = 2.0
+	// Maximum reordering in packets before packet threshold loss detection considers a packet lost.
+	// The threshold is increased up to maxPacketThreshold when spurious losses are detected.
+	defaultPacketThreshold = 3
+	maxPacketThreshold     = 20
 	// The default RTT used before an RTT sample is taken.
 	// Note: This constant is also defined in the congestion package.
 	defaultInitialRTT = 100 * time.Millisecond
-	// defaultRTOTimeout is the RTO time on new connections
-	defaultRTOTimeout = 500 * time.Millisecond
-	// Minimum time in the future a tail loss probe alarm may be set for.
-	minTPLTimeout = 10 * time.Millisecond
-	// Minimum time in the future an RTO alarm may be set for.
-	minRTOTimeout = 200 * time.Millisecond
-	// maxRTOTimeout is the maximum RTO time
-	maxRTOTimeout = 60 * time.Second
+	// timerGranularity is the granularity of the loss detection timers
+	timerGranularity = time.Millisecond
+	// Persistent congestion is detected if all packets sent during this many PTOs are lost.
+	persistentCongestionThreshold = 3
+	// numProbePackets is the number of probe packets sent when the PTO expires
+	numProbePackets = 2
+	// maxPTOTimeout is the maximum PTO, including the exponential backoff
+	maxPTOTimeout = 60 * time.Second
 )
 
+// A lostPacket is a packet that was declared lost.
+// It is kept to detect spurious losses, i.e. when the packet is acknowledged later.
+type lostPacket struct {
+	packetNumber protocol.PacketNumber
+	sendTime     time.Time
+}
+
 // ErrDuplicateOrOutOfOrderAck occurs when a duplicate or an out-of-order ACK is received
 var ErrDuplicateOrOutOfOrderAck = errors.New("SentPacketHandler: Duplicate or out-of-order ACK")
 
 type sentPacketHandler struct {
 	lastSentPacketNumber protocol.PacketNumber
-	nextPacketSendTime   time.Time
 	skippedPackets       []protocol.PacketNumber
 
 	largestAcked                 protocol.PacketNumber
@@ -45,51 +57,73 @@ type sentPacketHandler struct {
 	// once we receive an ACK from the peer for packet 20, the lowestPacketNotConfirmedAcked is 101
 	lowestPacketNotConfirmedAcked protocol.PacketNumber
 
-	packetHistory      *PacketList
+	packetHistory      *sentPacketHistory
 	stopWaitingManager stopWaitingManager
 
+	ackedPackets []*Packet // reused for every ACK frame received
+
 	retransmissionQueue []*Packet
 
 	bytesInFlight protocol.ByteCount
 
-	congestion congestion.SendAlgorithm
-	rttStats   *congestion.RTTStats
+	congestion       congestion.SendAlgorithm
+	pacer            *congestion.Pacer
+	bandwidthSampler congestion.BandwidthSampler
+	rttStats         *congestion.RTTStats
 
 	handshakeComplete bool
-	// The number of times the handshake packets have been retransmitted without receiving an ack.
-	handshakeCount uint32
-
-	// The number of times an RTO has been sent without receiving an ack.
-	rtoCount uint32
-
-	// The time at which the next packet will be considered lost based on early transmit or exceeding the reordering window in time.
+	// maxAckDelay is the maximum time the peer delays ACKs
+	maxAckDelay time.Duration
+
+	// The number of times the PTO expired without receiving an ACK.
+	ptoCount uint32
+	// numProbesToSend is the number of probe packets that still have to be sent, after the PTO expired.
+	// Probe packets are sent even if the congestion window is full.
+	numProbesToSend int
+	// lastSentRetransmittableTime is the time the last retransmittable packet was sent.
+	// The PTO is calculated from this time.
+	lastSentRetransmittableTime time.Time
+
+	// The time at which the next packet will be considered lost based on exceeding the reordering window in time.
 	lossTime time.Time
+	// The reordering thresholds used for loss detection. They adapt to the reordering observed.
+	timeThreshold   float64
+	packetThreshold protocol.PacketNumber
+	// lostPackets are the packets that were declared lost, in the order they were declared lost
+	lostPackets []lostPacket
+
+	// firstRTTSampleTime is the time the first RTT sample was taken.
+	// Only packets sent after that time are used to detect persistent congestion.
+	firstRTTSampleTime time.Time
+	// largestAckedSentTime is the latest send time of all packets that were acknowledged.
+	largestAckedSentTime time.Time
 
 	// The alarm timeout
 	alarm time.Time
-}
 
-// NewSentPacketHandler creates a new sentPacketHandler
-func NewSentPacketHandler(rttStats *congestion.RTTStats) SentPacketHandler {
-	congestion := congestion.NewCubicSender(
-		congestion.DefaultClock{},
-		rttStats,
-		false, /* don't use reno since chromium doesn't (why?) */
-		protocol.InitialCongestionWindow,
-		protocol.DefaultMaxCongestionWindow,
-	)
+	ecnEnabled bool
+	// ecnCounts are the ECN counts of the last ACK frame received
+	ecnCounts wire.ECNCounts
+}
 
+// NewSentPacketHandler creates a new sentPacketHandler.
+// The first initialPacingBurst bytes are sent without pacing.
+func NewSentPacketHandler(rttStats *congestion.RTTStats, cong congestion.SendAlgorithm, initialPacingBurst protocol.ByteCount) SentPacketHandler {
 	return &sentPacketHandler{
-		packetHistory:      NewPacketList(),
+		packetHistory:      newSentPacketHistory(),
 		stopWaitingManager: stopWaitingManager{},
 		rttStats:           rttStats,
-		congestion:         congestion,
+		congestion:         cong,
+		pacer:              congestion.NewPacer(initialPacingBurst),
+		maxAckDelay:        protocol.AckSendDelay,
+		timeThreshold:      defaultTimeThreshold,
+		packetThreshold:    defaultPacketThreshold,
 	}
 }
 
 func (h *sentPacketHandler) lowestUnacked() protocol.PacketNumber {
-	if f := h.packetHistory.Front(); f != nil {
-		return f.Value.PacketNumber
+	if p := h.packetHistory.FirstOutstanding(); p != nil {
+		return p.PacketNumber
 	}
 	return h.largestAcked + 1
 }
@@ -105,6 +139,14 @@ func (h *sentPacketHandler) SetHandshakeComplete() {
 	h.handshakeComplete = true
 }
 
+func (h *sentPacketHandler) SetMaxAckDelay(d time.Duration) {
+	h.maxAckDelay = d
+}
+
+func (h *sentPacketHandler) EnableECN() {
+	h.ecnEnabled = true
+}
+
 func (h *sentPacketHandler) SentPacket(packet *Packet) error {
 	if protocol.PacketNumber(len(h.retransmissionQueue)+h.packetHistory.Len()+1) > protocol.MaxTrackedSentPackets {
 		return errors.New("Too many outstanding non-acked and non-retransmitted packets")
@@ -120,6 +162,11 @@ func (h *sentPacketHandler) SentPacket(packet *Packet) error {
 
 	now := time.Now()
 	h.lastSentPacketNumber = packet.PacketNumber
+	if h.ecnEnabled {
+		packet.ECN = protocol.ECT0
+	} else {
+		packet.ECN = protocol.ECNNon
+	}
 
 	var largestAcked protocol.PacketNumber
 	if len(packet.Frames) > 0 {
@@ -130,12 +177,19 @@ func (h *sentPacketHandler) SentPacket(packet *Packet) error {
 
 	packet.Frames = stripNonRetransmittableFrames(packet.Frames)
 	isRetransmittable := len(packet.Frames) != 0
+	// repair packets are not retransmitted, but they use the capacity of the path like any other packet
+	inFlight := isRetransmittable || packet.IsRepairPacket
+	if isRetransmittable && h.numProbesToSend > 0 {
+		h.numProbesToSend--
+	}
 
-	if isRetransmittable {
+	if inFlight {
+		h.lastSentRetransmittableTime = now
 		packet.sendTime = now
 		packet.largestAcked = largestAcked
+		packet.deliveryState = h.bandwidthSampler.OnPacketSent(now, h.bytesInFlight)
 		h.bytesInFlight += packet.Length
-		h.packetHistory.PushBack(*packet)
+		h.packetHistory.SentPacket(packet)
 	}
 
 	h.congestion.OnPacketSent(
@@ -143,12 +197,13 @@ func (h *sentPacketHandler) SentPacket(packet *Packet) error {
 		h.bytesInFlight,
 		packet.PacketNumber,
 		packet.Length,
-		isRetransmittable,
+		inFlight,
 	)
 
-	h.nextPacketSendTime = utils.MaxTime(h.nextPacketSendTime, now).Add(h.congestion.TimeUntilSend(h.bytesInFlight))
+	h.pacer.SentPacket(now, packet.Length)
+	h.updatePacingRate()
 
-	h.updateLossDetectionAlarm(now)
+	h.updateLossDetectionAlarm()
 	return nil
 }
 
@@ -164,8 +219,14 @@ func (h *sentPacketHandler) ReceivedAck(ackFrame *wire.AckFrame, withPacketNumbe
 	}
 	h.largestReceivedPacketWithAck = withPacketNumber
 
+	// This has to happen before repeated ACKs are ignored:
+	// When a packet arrives late, the peer might send an ACK frame that only acknowledges this packet.
+	h.detectSpuriousLosses(ackFrame, rcvTime)
+
 	// ignore repeated ACK (ACKs that don't have a higher LargestAcked than the last ACK)
 	if ackFrame.LargestAcked < h.lowestUnacked() {
+		// a spurious loss might have changed the congestion window
+		h.updatePacingRate()
 		return nil
 	}
 	h.largestAcked = ackFrame.LargestAcked
@@ -180,171 +241,292 @@ func (h *sentPacketHandler) ReceivedAck(ackFrame *wire.AckFrame, withPacketNumbe
 		h.congestion.MaybeExitSlowStart()
 	}
 
-	ackedPackets, err := h.determineNewlyAckedPackets(ackFrame)
-	if err != nil {
-		return err
-	}
-
+	ackedPackets := h.determineNewlyAckedPackets(ackFrame)
+	largestAckedSentTime := h.largestAckedSentTime
 	if len(ackedPackets) > 0 {
+		h.ptoCount = 0
+		h.numProbesToSend = 0
+		// count the ECT packets now, the acknowledged packets are removed from the history below
+		var numECT uint64
 		for _, p := range ackedPackets {
-			if encLevel < p.Value.EncryptionLevel {
-				return fmt.Errorf("Received ACK with encryption level %s that acks a packet %d (encryption level %s)", encLevel, p.Value.PacketNumber, p.Value.EncryptionLevel)
+			if p.ECN != protocol.ECNNon {
+				numECT++
+			}
+		}
+		for _, p := range ackedPackets {
+			if encLevel < p.EncryptionLevel {
+				return fmt.Errorf("Received ACK with encryption level %s that acks a packet %d (encryption level %s)", encLevel, p.PacketNumber, p.EncryptionLevel)
 			}
 			// largestAcked == 0 either means that the packet didn't contain an ACK, or it just acked packet 0
 			// It is safe to ignore the corner case of packets that just acked packet 0, because
 			// the lowestPacketNotConfirmedAcked is only used to limit the number of ACK ranges we will send.
-			if p.Value.largestAcked != 0 {
-				h.lowestPacketNotConfirmedAcked = utils.MaxPacketNumber(h.lowestPacketNotConfirmedAcked, p.Value.largestAcked+1)
+			if p.largestAcked != 0 {
+				h.lowestPacketNotConfirmedAcked = utils.MaxPacketNumber(h.lowestPacketNotConfirmedAcked, p.largestAcked+1)
 			}
-			h.onPacketAcked(p)
-			h.congestion.OnPacketAcked(p.Value.PacketNumber, p.Value.Length, h.bytesInFlight)
+			if p.sendTime.After(largestAckedSentTime) {
+				largestAckedSentTime = p.sendTime
+			}
+			packetNumber, length := p.PacketNumber, p.Length
+			sample := h.bandwidthSampler.OnPacketAcked(rcvTime, length, p.deliveryState)
+			if err := h.onPacketAcked(p); err != nil {
+				return err
+			}
+			h.congestion.OnBandwidthSample(sample)
+			h.congestion.OnPacketAcked(packetNumber, length, h.bytesInFlight)
 		}
+		h.processECNCounts(ackFrame, numECT)
 	}
 
-	h.detectLostPackets(rcvTime)
-	h.updateLossDetectionAlarm(rcvTime)
+	h.detectLostPackets(rcvTime, ackFrame)
+	h.largestAckedSentTime = largestAckedSentTime
+	h.updateLossDetectionAlarm()
 
 	h.garbageCollectSkippedPackets()
 	h.stopWaitingManager.ReceivedAck(ackFrame)
+	h.updatePacingRate()
 
 	return nil
 }
 
-func (h *sentPacketHandler) GetLowestPacketNotConfirmedAcked() protocol.PacketNumber {
-	return h.lowestPacketNotConfirmedAcked
+// updatePacingRate sets the pacing rate for the current congestion window and bytes in flight.
+// It is called whenever they change, such that the next packet is paced at the new rate.
+func (h *sentPacketHandler) updatePacingRate() {
+	h.pacer.SetPacingRate(h.congestion.PacingRate(h.bytesInFlight))
 }
 
-func (h *sentPacketHandler) determineNewlyAckedPackets(ackFrame *wire.AckFrame) ([]*PacketElement, error) {
-	var ackedPackets []*PacketElement
-	ackRangeIndex := 0
-	for el := h.packetHistory.Front(); el != nil; el = el.Next() {
-		packet := el.Value
-		packetNumber := packet.PacketNumber
-
-		// Ignore packets below the LowestAcked
-		if packetNumber < ackFrame.LowestAcked {
+// detectSpuriousLosses checks if the ACK frame acknowledges packets that were declared lost.
+// Such a loss was caused by reordering. The congestion controller is notified,
+// and the reordering thresholds are increased, such that the reordering observed doesn't lead to spurious losses in the future.
+func (h *sentPacketHandler) detectSpuriousLosses(ackFrame *wire.AckFrame, rcvTime time.Time) {
+	if len(h.lostPackets) == 0 {
+		return
+	}
+	rtt := h.lossDetectionRTT()
+	lostPackets := h.lostPackets[:0]
+	for _, p := range h.lostPackets {
+		if !ackFrame.AcksPacket(p.packetNumber) {
+			lostPackets = append(lostPackets, p)
 			continue
 		}
-		// Break after LargestAcked is reached
-		if packetNumber > ackFrame.LargestAcked {
-			break
+		utils.Debugf("\tPacket 0x%x was declared lost, but was acknowledged (spurious loss)", p.packetNumber)
+		h.congestion.OnSpuriousLoss(p.packetNumber)
+		if reordering := ackFrame.LargestAcked - p.packetNumber + 1; reordering > h.packetThreshold {
+			h.packetThreshold = utils.MinPacketNumber(reordering, maxPacketThreshold)
+		}
+		if threshold := float64(rcvTime.Sub(p.sendTime)) / float64(rtt); threshold > h.timeThreshold {
+			h.timeThreshold = math.Min(threshold, maxTimeThreshold)
 		}
+	}
+	h.lostPackets = lostPackets
+}
 
-		if ackFrame.HasMissingRanges() {
-			ackRange := ackFrame.AckRanges[len(ackFrame.AckRanges)-1-ackRangeIndex]
+// processECNCounts validates the ECN counts of an ACK frame, and reports CE marks to the congestion controller.
+// If the counts didn't increase by (at least) the number of newly acknowledged ECT packets (numECT),
+// the ECN marks were cleared on the path, or the peer doesn't report them. ECN is then disabled.
+func (h *sentPacketHandler) processECNCounts(ackFrame *wire.AckFrame, numECT uint64) {
+	if !h.ecnEnabled {
+		return
+	}
+	if ackFrame.ECN == nil {
+		if numECT > 0 {
+			h.disableECN()
+		}
+		return
+	}
+	counts := *ackFrame.ECN
+	if counts.ECT0+counts.ECT1+counts.CE < h.ecnCounts.ECT0+h.ecnCounts.ECT1+h.ecnCounts.CE+numECT {
+		h.disableECN()
+		return
+	}
+	if counts.CE > h.ecnCounts.CE {
+		h.congestion.OnCongestionExperienced(ackFrame.LargestAcked, h.bytesInFlight)
+	}
+	h.ecnCounts = counts
+}
 
-			for packetNumber > ackRange.Last && ackRangeIndex < len(ackFrame.AckRanges)-1 {
-				ackRangeIndex++
-				ackRange = ackFrame.AckRanges[len(ackFrame.AckRanges)-1-ackRangeIndex]
-			}
+func (h *sentPacketHandler) disableECN() {
+	utils.Infof("ECN validation failed. Disabling ECN.")
+	h.ecnEnabled = false
+}
 
-			if packetNumber >= ackRange.First { // packet i contained in ACK range
-				if packetNumber > ackRange.Last {
-					return nil, fmt.Errorf("BUG: ackhandler would have acked wrong packet 0x%x, while evaluating range 0x%x -> 0x%x", packetNumber, ackRange.First, ackRange.Last)
-				}
-				ackedPackets = append(ackedPackets, el)
-			}
-		} else {
-			ackedPackets = append(ackedPackets, el)
+func (h *sentPacketHandler) GetLowestPacketNotConfirmedAcked() protocol.PacketNumber {
+	return h.lowestPacketNotConfirmedAcked
+}
+
+// determineNewlyAckedPackets looks up the packets acknowledged by an ACK frame in the packet history.
+// The packets are returned in increasing order of their packet numbers.
+// The returned slice is only valid until the next call.
+func (h *sentPacketHandler) determineNewlyAckedPackets(ackFrame *wire.AckFrame) []*Packet {
+	h.ackedPackets = h.ackedPackets[:0]
+	collect := func(p *Packet) bool {
+		h.ackedPackets = append(h.ackedPackets, p)
+		return true
+	}
+	if !ackFrame.HasMissingRanges() {
+		h.packetHistory.IterateRange(ackFrame.LowestAcked, ackFrame.LargestAcked, collect)
+		return h.ackedPackets
+	}
+	lowestUnacked := h.lowestUnacked()
+	// the ACK ranges are sorted in descending order
+	for i := len(ackFrame.AckRanges) - 1; i >= 0; i-- {
+		ackRange := ackFrame.AckRanges[i]
+		if ackRange.Last < lowestUnacked {
+			continue
 		}
+		h.packetHistory.IterateRange(ackRange.First, ackRange.Last, collect)
 	}
-	return ackedPackets, nil
+	return h.ackedPackets
 }
 
 func (h *sentPacketHandler) maybeUpdateRTT(largestAcked protocol.PacketNumber, ackDelay time.Duration, rcvTime time.Time) bool {
-	for el := h.packetHistory.Front(); el != nil; el = el.Next() {
-		packet := el.Value
-		if packet.PacketNumber == largestAcked {
-			h.rttStats.UpdateRTT(rcvTime.Sub(packet.sendTime), ackDelay, rcvTime)
-			return true
-		}
-		// Packets are sorted by number, so we can stop searching
-		if packet.PacketNumber > largestAcked {
-			break
+	if p := h.packetHistory.GetPacket(largestAcked); p != nil {
+		h.rttStats.UpdateRTT(rcvTime.Sub(p.sendTime), ackDelay, rcvTime)
+		if h.firstRTTSampleTime.IsZero() {
+			h.firstRTTSampleTime = rcvTime
 		}
+		return true
 	}
 	return false
 }
 
-func (h *sentPacketHandler) updateLossDetectionAlarm(now time.Time) {
+func (h *sentPacketHandler) updateLossDetectionAlarm() {
 	// Cancel the alarm if no packets are outstanding
 	if h.packetHistory.Len() == 0 {
 		h.alarm = time.Time{}
 		return
 	}
 
-	// TODO(#497): TLP
-	if !h.handshakeComplete {
-		h.alarm = now.Add(h.computeHandshakeTimeout())
-	} else if !h.lossTime.IsZero() {
-		// Early retransmit timer or time loss detection.
+	if !h.lossTime.IsZero() {
+		// time threshold loss detection
 		h.alarm = h.lossTime
-	} else {
-		// RTO
-		h.alarm = now.Add(h.computeRTOTimeout())
+		return
 	}
+	// PTO, with exponential backoff
+	h.alarm = h.lastSentRetransmittableTime.Add(h.computeBackedOffPTOTimeout())
 }
 
-func (h *sentPacketHandler) detectLostPackets(now time.Time) {
-	h.lossTime = time.Time{}
+// lossDetectionRTT is the RTT that the time threshold of the loss detection is applied to
+func (h *sentPacketHandler) lossDetectionRTT() time.Duration {
+	rtt := utils.MaxDuration(h.rttStats.LatestRTT(), h.rttStats.SmoothedRTT())
+	if rtt == 0 {
+		return defaultInitialRTT
+	}
+	return rtt
+}
 
-	maxRTT := float64(utils.MaxDuration(h.rttStats.LatestRTT(), h.rttStats.SmoothedRTT()))
-	delayUntilLost := time.Duration((1.0 + timeReorderingFraction) * maxRTT)
+// detectLostPackets declares packets lost that were sent before the largest acknowledged packet,
+// if they were sent packetThreshold packets or more than timeThreshold RTTs earlier.
+// ackFrame is the ACK frame that was just received. It is nil if loss detection was triggered by the alarm.
+func (h *sentPacketHandler) detectLostPackets(now time.Time, ackFrame *wire.AckFrame) {
+	h.lossTime = time.Time{}
 
-	var lostPackets []*PacketElement
-	for el := h.packetHistory.Front(); el != nil; el = el.Next() {
-		packet := el.Value
+	lossDelay := utils.MaxDuration(time.Duration(h.timeThreshold*float64(h.lossDetectionRTT())), timerGranularity)
 
+	var lostPackets []*Packet
+	h.packetHistory.Iterate(func(packet *Packet) bool {
 		if packet.PacketNumber > h.largestAcked {
-			break
+			return false
 		}
 
 		timeSinceSent := now.Sub(packet.sendTime)
-		if timeSinceSent > delayUntilLost {
-			lostPackets = append(lostPackets, el)
+		if timeSinceSent > lossDelay || h.largestAcked >= packet.PacketNumber+h.packetThreshold {
+			lostPackets = append(lostPackets, packet)
 		} else if h.lossTime.IsZero() {
 			// Note: This conditional is only entered once per call
-			h.lossTime = now.Add(delayUntilLost - timeSinceSent)
+			h.lossTime = now.Add(lossDelay - timeSinceSent)
 		}
+		return true
+	})
+
+	// Persistent congestion is detected if all packets sent during the persistent congestion period were lost.
+	// Only packets sent after the first RTT sample, and after the last packet acknowledged by a previous ACK frame, are considered.
+	// The period starts anew if the ACK frame acknowledges a packet sent in between two lost packets.
+	persistentCongestionPeriod := persistentCongestionThreshold * h.computePTOTimeout()
+	var periodStart, previous *Packet
+	var persistentCongestion bool
+	for _, p := range lostPackets {
+		packet := h.queuePacketForRetransmission(p)
+		// a lost MTU probe doesn't indicate congestion
+		if packet.IsMTUProbePacket {
+			continue
+		}
+		h.congestion.OnPacketLost(packet.PacketNumber, packet.Length, h.bytesInFlight)
+		h.lostPackets = append(h.lostPackets, lostPacket{packetNumber: packet.PacketNumber, sendTime: packet.sendTime})
+		if len(h.lostPackets) > protocol.MaxTrackedLostPackets {
+			h.lostPackets = h.lostPackets[1:]
+		}
+		if h.firstRTTSampleTime.IsZero() || !packet.sendTime.After(h.firstRTTSampleTime) || !packet.sendTime.After(h.largestAckedSentTime) {
+			continue
+		}
+		if periodStart == nil || (ackFrame != nil && acksPacketBetween(ackFrame, previous.PacketNumber, packet.PacketNumber)) {
+			periodStart = packet
+		}
+		previous = packet
+		if packet.sendTime.Sub(periodStart.sendTime) > persistentCongestionPeriod {
+			persistentCongestion = true
+		}
+	}
+	if persistentCongestion {
+		utils.Infof("Persistent congestion detected. Reducing the congestion window to the minimum.")
+		h.congestion.OnPersistentCongestion()
 	}
+}
 
-	if len(lostPackets) > 0 {
-		for _, p := range lostPackets {
-			h.queuePacketForRetransmission(p)
-			h.congestion.OnPacketLost(p.Value.PacketNumber, p.Value.Length, h.bytesInFlight)
+// acksPacketBetween says if the ACK frame acknowledges any packet number between from and to (exclusive).
+func acksPacketBetween(ackFrame *wire.AckFrame, from, to protocol.PacketNumber) bool {
+	if !ackFrame.HasMissingRanges() {
+		return ackFrame.LowestAcked < to && ackFrame.LargestAcked > from
+	}
+	for _, r := range ackFrame.AckRanges {
+		if r.First < to && r.Last > from {
+			return true
 		}
 	}
+	return false
 }
 
 func (h *sentPacketHandler) OnAlarm() {
 	now := time.Now()
 
-	// TODO(#497): TLP
-	if !h.handshakeComplete {
-		h.queueHandshakePacketsForRetransmission()
-		h.handshakeCount++
-	} else if !h.lossTime.IsZero() {
-		// Early retransmit or time loss detection
-		h.detectLostPackets(now)
+	if !h.lossTime.IsZero() {
+		// time threshold loss detection
+		h.detectLostPackets(now, nil)
 	} else {
-		// RTO
-		h.retransmitOldestTwoPackets()
-		h.rtoCount++
+		h.onPTO()
 	}
 
-	h.updateLossDetectionAlarm(now)
+	h.updateLossDetectionAlarm()
+	h.updatePacingRate()
+}
+
+// onPTO is called when the probe timeout expires.
+// Before the handshake completes, all handshake packets are retransmitted.
+// Otherwise, probe packets are sent to elicit an ACK from the peer (RFC 9002, Section 6.2.4).
+// The probe packets are new packets, the outstanding packets stay in flight.
+// Once a probe packet is acknowledged, the loss detection declares the outstanding packets lost.
+func (h *sentPacketHandler) onPTO() {
+	h.ptoCount++
+	if !h.handshakeComplete && h.queueHandshakePacketsForRetransmission() {
+		return
+	}
+	utils.Debugf("\tPTO expired, sending %d probe packets, %d outstanding", numProbePackets, h.packetHistory.Len())
+	h.numProbesToSend = numProbePackets
+}
+
+// ShouldSendProbePacket says if a probe packet has to be sent, because the PTO expired.
+// If there's no new data to send, the probe packet should contain a PING frame.
+func (h *sentPacketHandler) ShouldSendProbePacket() bool {
+	return h.numProbesToSend > 0
 }
 
 func (h *sentPacketHandler) GetAlarmTimeout() time.Time {
 	return h.alarm
 }
 
-func (h *sentPacketHandler) onPacketAcked(packetElement *PacketElement) {
-	h.bytesInFlight -= packetElement.Value.Length
-	h.rtoCount = 0
-	h.handshakeCount = 0
-	// TODO(#497): h.tlpCount = 0
-	h.packetHistory.Remove(packetElement)
+func (h *sentPacketHandler) onPacketAcked(p *Packet) error {
+	h.bytesInFlight -= p.Length
+	p.onAcked()
+	return h.packetHistory.Remove(p.PacketNumber)
 }
 
 func (h *sentPacketHandler) DequeuePacketForRetransmission() *Packet {
@@ -376,84 +558,84 @@ func (h *sentPacketHandler) SendingAllowed() bool {
 	}
 	// Workaround for #555:
 	// Always allow sending of retransmissions. This should probably be limited
-	// to RTOs, but we currently don't have a nice way of distinguishing them.
+	// to probe packets, but we currently don't have a nice way of distinguishing them.
 	haveRetransmissions := len(h.retransmissionQueue) > 0
-	return !maxTrackedLimited && (!congestionLimited || haveRetransmissions)
+	return !maxTrackedLimited && (!congestionLimited || haveRetransmissions || h.numProbesToSend > 0)
 }
 
-func (h *sentPacketHandler) TimeUntilSend() time.Time {
-	return h.nextPacketSendTime
+func (h *sentPacketHandler) SetAppLimited() {
+	h.bandwidthSampler.OnAppLimited(h.bytesInFlight)
 }
 
-func (h *sentPacketHandler) ShouldSendNumPackets() int {
-	delay := h.congestion.TimeUntilSend(h.bytesInFlight)
-	if delay == 0 || delay > protocol.MinPacingDelay {
-		return 1
-	}
-	return int(math.Ceil(float64(protocol.MinPacingDelay) / float64(delay)))
+func (h *sentPacketHandler) BandwidthEstimate() congestion.Bandwidth {
+	return h.congestion.BandwidthEstimate()
 }
 
-func (h *sentPacketHandler) retransmitOldestTwoPackets() {
-	if p := h.packetHistory.Front(); p != nil {
-		h.queueRTO(p)
-	}
-	if p := h.packetHistory.Front(); p != nil {
-		h.queueRTO(p)
-	}
+func (h *sentPacketHandler) TimeUntilSend() time.Time {
+	return h.pacer.TimeUntilSend()
 }
 
-func (h *sentPacketHandler) queueRTO(el *PacketElement) {
-	packet := &el.Value
-	utils.Debugf(
-		"\tQueueing packet 0x%x for retransmission (RTO), %d outstanding",
-		packet.PacketNumber,
-		h.packetHistory.Len(),
-	)
-	h.queuePacketForRetransmission(el)
-	h.congestion.OnPacketLost(packet.PacketNumber, packet.Length, h.bytesInFlight)
-	h.congestion.OnRetransmissionTimeout(true)
+func (h *sentPacketHandler) ShouldSendNumPackets() int {
+	if h.pacer.PacingRate() == 0 {
+		return 1
+	}
+	return utils.Max(int(h.pacer.Budget(time.Now())/protocol.DefaultTCPMSS), 1)
 }
 
-func (h *sentPacketHandler) queueHandshakePacketsForRetransmission() {
-	var handshakePackets []*PacketElement
-	for el := h.packetHistory.Front(); el != nil; el = el.Next() {
-		if el.Value.EncryptionLevel < protocol.EncryptionForwardSecure {
-			handshakePackets = append(handshakePackets, el)
+// queueHandshakePacketsForRetransmission queues all outstanding handshake packets for retransmission.
+// It returns if any packets were queued.
+func (h *sentPacketHandler) queueHandshakePacketsForRetransmission() bool {
+	var handshakePackets []*Packet
+	h.packetHistory.Iterate(func(p *Packet) bool {
+		if p.EncryptionLevel < protocol.EncryptionForwardSecure {
+			handshakePackets = append(handshakePackets, p)
 		}
+		return true
+	})
+	for _, p := range handshakePackets {
+		h.queuePacketForRetransmission(p)
 	}
-	for _, el := range handshakePackets {
-		h.queuePacketForRetransmission(el)
-	}
+	return len(handshakePackets) > 0
 }
 
-func (h *sentPacketHandler) queuePacketForRetransmission(packetElement *PacketElement) {
-	packet := &packetElement.Value
+// queuePacketForRetransmission moves a packet from the packet history to the retransmission queue.
+// Repair packets are not retransmitted, they are only removed from the history.
+// The history reuses the memory of removed packets, so it returns the copy of the packet.
+func (h *sentPacketHandler) queuePacketForRetransmission(p *Packet) *Packet {
+	packet := new(Packet)
+	*packet = *p
 	h.bytesInFlight -= packet.Length
-	h.retransmissionQueue = append(h.retransmissionQueue, packet)
-	h.packetHistory.Remove(packetElement)
+	if !packet.IsRepairPacket {
+		h.retransmissionQueue = append(h.retransmissionQueue, packet)
+	}
+	if err := h.packetHistory.Remove(packet.PacketNumber); err != nil {
+		utils.Errorf("sentPacketHandler BUG: %s", err.Error())
+	}
 	h.stopWaitingManager.QueuedRetransmissionForPacketNumber(packet.PacketNumber)
+	return packet
 }
 
-func (h *sentPacketHandler) computeHandshakeTimeout() time.Duration {
-	duration := 2 * h.rttStats.SmoothedRTT()
-	if duration == 0 {
-		duration = 2 * defaultInitialRTT
+// computePTOTimeout calculates the probe timeout, without exponential backoff.
+// Before the handshake completes, the peer doesn't delay ACKs for handshake packets.
+func (h *sentPacketHandler) computePTOTimeout() time.Duration {
+	if h.rttStats.SmoothedRTT() == 0 {
+		return 2 * defaultInitialRTT
+	}
+	pto := h.rttStats.SmoothedRTT() + utils.MaxDuration(4*h.rttStats.MeanDeviation(), timerGranularity)
+	if h.handshakeComplete {
+		pto += h.maxAckDelay
 	}
-	duration = utils.MaxDuration(duration, minTPLTimeout)
-	// exponential backoff
-	// There's an implicit limit to this set by the handshake timeout.
-	return duration << h.handshakeCount
+	return pto
 }
 
-func (h *sentPacketHandler) computeRTOTimeout() time.Duration {
-	rto := h.congestion.RetransmissionDelay()
-	if rto == 0 {
-		rto = defaultRTOTimeout
+// computeBackedOffPTOTimeout calculates the probe timeout with exponential backoff, limited to maxPTOTimeout.
+func (h *sentPacketHandler) computeBackedOffPTOTimeout() time.Duration {
+	pto := h.computePTOTimeout()
+	// stop doubling once the limit is reached, such that large PTO counts don't overflow
+	for i := uint32(0); i < h.ptoCount && pto < maxPTOTimeout; i++ {
+		pto <<= 1
 	}
-	rto = utils.MaxDuration(rto, minRTOTimeout)
-	// Exponential backoff
-	rto = rto << h.rtoCount
-	return utils.MinDuration(rto, maxRTOTimeout)
+	return utils.MinDuration(pto, maxPTOTimeout)
 }
 
 func (h *sentPacketHandler) skippedPacketsAcked(ackFrame *wire.AckFrame) bool {
@@ -1,6 +1,7 @@
 package ackhandler
 
 import (
+	"sort"
 	"time"
 
 	"github.com/golang/mock/gomock"
@@ -25,6 +26,14 @@ func nonRetransmittablePacket(num protocol.PacketNumber) *Packet {
 	return &Packet{PacketNumber: num, Length: 1, Frames: []wire.Frame{&wire.AckFrame{}}}
 }
 
+// ackListenerFrame is a frame that records when the packet it was sent in is acknowledged
+type ackListenerFrame struct {
+	wire.PingFrame
+	acked bool
+}
+
+func (f *ackListenerFrame) OnAcked() { f.acked = true }
+
 func handshakePacket(num protocol.PacketNumber) *Packet {
 	return &Packet{
 		PacketNumber:    num,
@@ -42,7 +51,8 @@ var _ = Describe("SentPacketHandler", func() {
 
 	BeforeEach(func() {
 		rttStats := &congestion.RTTStats{}
-		handler = NewSentPacketHandler(rttStats).(*sentPacketHandler)
+		cong := congestion.NewCubicSender(congestion.DefaultClock{}, rttStats, false, congestion.NewSlowStart(nil), protocol.InitialCongestionWindow, protocol.DefaultMaxCongestionWindow)
+		handler = NewSentPacketHandler(rttStats, cong, protocol.DefaultInitialPacingBurst*protocol.DefaultTCPMSS).(*sentPacketHandler)
 		handler.SetHandshakeComplete()
 		streamFrame = wire.StreamFrame{
 			StreamID: 5,
@@ -50,13 +60,31 @@ var _ = Describe("SentPacketHandler", func() {
 		}
 	})
 
-	getPacketElement := func(p protocol.PacketNumber) *PacketElement {
-		for el := handler.packetHistory.Front(); el != nil; el = el.Next() {
-			if el.Value.PacketNumber == p {
-				return el
-			}
+	getPacket := func(p protocol.PacketNumber) *Packet {
+		return handler.packetHistory.GetPacket(p)
+	}
+
+	expectInPacketHistory := func(expected []protocol.PacketNumber) {
+		var packets []protocol.PacketNumber
+		handler.packetHistory.Iterate(func(p *Packet) bool {
+			packets = append(packets, p.PacketNumber)
+			return true
+		})
+		ExpectWithOffset(1, packets).To(Equal(expected))
+	}
+
+	// expectInPacketHistoryOrLost checks that the packets are either still outstanding, or were declared lost
+	expectInPacketHistoryOrLost := func(expected []protocol.PacketNumber) {
+		var packets []protocol.PacketNumber
+		for _, p := range handler.retransmissionQueue {
+			packets = append(packets, p.PacketNumber)
 		}
-		return nil
+		handler.packetHistory.Iterate(func(p *Packet) bool {
+			packets = append(packets, p.PacketNumber)
+			return true
+		})
+		sort.Slice(packets, func(i, j int) bool { return packets[i] < packets[j] })
+		ExpectWithOffset(1, packets).To(Equal(expected))
 	}
 
 	It("gets the LeastUnacked packet number", func() {
@@ -73,8 +101,7 @@ var _ = Describe("SentPacketHandler", func() {
 			err = handler.SentPacket(&packet2)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(handler.lastSentPacketNumber).To(Equal(protocol.PacketNumber(2)))
-			Expect(handler.packetHistory.Front().Value.PacketNumber).To(Equal(protocol.PacketNumber(1)))
-			Expect(handler.packetHistory.Back().Value.PacketNumber).To(Equal(protocol.PacketNumber(2)))
+			expectInPacketHistory([]protocol.PacketNumber{1, 2})
 			Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(3)))
 			Expect(handler.skippedPackets).To(BeEmpty())
 		})
@@ -88,8 +115,7 @@ var _ = Describe("SentPacketHandler", func() {
 			err = handler.SentPacket(&packet2)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(handler.lastSentPacketNumber).To(Equal(protocol.PacketNumber(1)))
-			Expect(handler.packetHistory.Front().Value.PacketNumber).To(Equal(protocol.PacketNumber(0)))
-			Expect(handler.packetHistory.Back().Value.PacketNumber).To(Equal(protocol.PacketNumber(1)))
+			expectInPacketHistory([]protocol.PacketNumber{0, 1})
 			Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(3)))
 			Expect(handler.skippedPackets).To(BeEmpty())
 		})
@@ -98,7 +124,7 @@ var _ = Describe("SentPacketHandler", func() {
 			packet := Packet{PacketNumber: 1, Frames: []wire.Frame{&streamFrame}, Length: 1}
 			err := handler.SentPacket(&packet)
 			Expect(err).ToNot(HaveOccurred())
-			Expect(handler.packetHistory.Front().Value.sendTime.Unix()).To(BeNumerically("~", time.Now().Unix(), 1))
+			Expect(handler.packetHistory.FirstOutstanding().sendTime.Unix()).To(BeNumerically("~", time.Now().Unix(), 1))
 		})
 
 		It("does not store non-retransmittable packets", func() {
@@ -116,10 +142,7 @@ var _ = Describe("SentPacketHandler", func() {
 				err = handler.SentPacket(&packet2)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(handler.lastSentPacketNumber).To(Equal(protocol.PacketNumber(3)))
-				el := handler.packetHistory.Front()
-				Expect(el.Value.PacketNumber).To(Equal(protocol.PacketNumber(1)))
-				el = el.Next()
-				Expect(el.Value.PacketNumber).To(Equal(protocol.PacketNumber(3)))
+				expectInPacketHistory([]protocol.PacketNumber{1, 3})
 				Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(3)))
 				Expect(handler.skippedPackets).To(HaveLen(1))
 				Expect(handler.skippedPackets[0]).To(Equal(protocol.PacketNumber(2)))
@@ -227,14 +250,6 @@ var _ = Describe("SentPacketHandler", func() {
 			Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(len(packets))))
 		})
 
-		expectInPacketHistory := func(expected []protocol.PacketNumber) {
-			var packets []protocol.PacketNumber
-			for el := handler.packetHistory.Front(); el != nil; el = el.Next() {
-				packets = append(packets, el.Value.PacketNumber)
-			}
-			ExpectWithOffset(1, packets).To(Equal(expected))
-		}
-
 		Context("ACK validation", func() {
 			It("rejects duplicate ACKs", func() {
 				largestAcked := 3
@@ -244,10 +259,11 @@ var _ = Describe("SentPacketHandler", func() {
 				}
 				err := handler.ReceivedAck(&ack, 1337, protocol.EncryptionUnencrypted, time.Now())
 				Expect(err).ToNot(HaveOccurred())
-				Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(len(packets) - 3)))
+				// packets 1 to 3 were acknowledged, packet 0 was declared lost
+				Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(len(packets) - 4)))
 				err = handler.ReceivedAck(&ack, 1337, protocol.EncryptionUnencrypted, time.Now())
 				Expect(err).To(MatchError(ErrDuplicateOrOutOfOrderAck))
-				Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(len(packets) - 3)))
+				Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(len(packets) - 4)))
 			})
 
 			It("rejects out of order ACKs", func() {
@@ -278,11 +294,12 @@ var _ = Describe("SentPacketHandler", func() {
 				}
 				err := handler.ReceivedAck(&ack, 1337, protocol.EncryptionUnencrypted, time.Now())
 				Expect(err).ToNot(HaveOccurred())
-				Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(len(packets) - 3)))
+				// packets 1 to 3 were acknowledged, packet 0 was declared lost
+				Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(len(packets) - 4)))
 				err = handler.ReceivedAck(&ack, 1337+1, protocol.EncryptionUnencrypted, time.Now())
 				Expect(err).ToNot(HaveOccurred())
 				Expect(handler.largestAcked).To(Equal(protocol.PacketNumber(3)))
-				Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(len(packets) - 3)))
+				Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(len(packets) - 4)))
 			})
 
 			It("rejects ACKs for skipped packets", func() {
@@ -318,12 +335,7 @@ var _ = Describe("SentPacketHandler", func() {
 				err := handler.ReceivedAck(&ack, 1, protocol.EncryptionUnencrypted, time.Now())
 				Expect(err).ToNot(HaveOccurred())
 				Expect(handler.largestAcked).To(Equal(protocol.PacketNumber(5)))
-				el := handler.packetHistory.Front()
-				for i := 6; i <= 10; i++ {
-					Expect(el.Value.PacketNumber).To(Equal(protocol.PacketNumber(i)))
-					el = el.Next()
-				}
-				Expect(el.Value.PacketNumber).To(Equal(protocol.PacketNumber(12)))
+				expectInPacketHistory([]protocol.PacketNumber{6, 7, 8, 9, 10, 12})
 			})
 
 			It("rejects an ACK that acks packets with a higher encryption level", func() {
@@ -349,13 +361,7 @@ var _ = Describe("SentPacketHandler", func() {
 				}
 				err := handler.ReceivedAck(&ack, 1, protocol.EncryptionUnencrypted, time.Now())
 				Expect(err).ToNot(HaveOccurred())
-				el := handler.packetHistory.Front()
-				Expect(el.Value.PacketNumber).To(Equal(protocol.PacketNumber(0)))
-				el = el.Next()
-				Expect(el.Value.PacketNumber).To(Equal(protocol.PacketNumber(9)))
-				el = el.Next()
-				Expect(el.Value.PacketNumber).To(Equal(protocol.PacketNumber(10)))
-				Expect(el.Next().Value.PacketNumber).To(Equal(protocol.PacketNumber(12)))
+				expectInPacketHistoryOrLost([]protocol.PacketNumber{0, 9, 10, 12})
 			})
 
 			It("acks packet 0", func() {
@@ -379,7 +385,19 @@ var _ = Describe("SentPacketHandler", func() {
 				}
 				err := handler.ReceivedAck(&ack, 1, protocol.EncryptionUnencrypted, time.Now())
 				Expect(err).ToNot(HaveOccurred())
-				expectInPacketHistory([]protocol.PacketNumber{0, 4, 5, 10, 12})
+				expectInPacketHistoryOrLost([]protocol.PacketNumber{0, 4, 5, 10, 12})
+			})
+
+			It("notifies the frames of acknowledged packets", func() {
+				f1 := &ackListenerFrame{}
+				f2 := &ackListenerFrame{}
+				Expect(handler.SentPacket(&Packet{PacketNumber: 13, Frames: []wire.Frame{f1}, Length: 1})).To(Succeed())
+				Expect(handler.SentPacket(&Packet{PacketNumber: 14, Frames: []wire.Frame{f2}, Length: 1})).To(Succeed())
+				ack := wire.AckFrame{LargestAcked: 13, LowestAcked: 13}
+				err := handler.ReceivedAck(&ack, 1, protocol.EncryptionForwardSecure, time.Now())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(f1.acked).To(BeTrue())
+				Expect(f2.acked).To(BeFalse())
 			})
 
 			It("does not ack packets below the LowestAcked", func() {
@@ -389,7 +407,7 @@ var _ = Describe("SentPacketHandler", func() {
 				}
 				err := handler.ReceivedAck(&ack, 1, protocol.EncryptionUnencrypted, time.Now())
 				Expect(err).ToNot(HaveOccurred())
-				expectInPacketHistory([]protocol.PacketNumber{0, 1, 2, 9, 10, 12})
+				expectInPacketHistoryOrLost([]protocol.PacketNumber{0, 1, 2, 9, 10, 12})
 			})
 
 			It("handles an ACK with multiple missing packet ranges", func() {
@@ -405,46 +423,46 @@ var _ = Describe("SentPacketHandler", func() {
 				}
 				err := handler.ReceivedAck(&ack, 1, protocol.EncryptionUnencrypted, time.Now())
 				Expect(err).ToNot(HaveOccurred())
-				expectInPacketHistory([]protocol.PacketNumber{0, 2, 4, 5, 8, 10, 12})
+				expectInPacketHistoryOrLost([]protocol.PacketNumber{0, 2, 4, 5, 8, 10, 12})
 			})
 
 			It("processes an ACK frame that would be sent after a late arrival of a packet", func() {
-				largestObserved := 6
+				largestObserved := 5
 				ack1 := wire.AckFrame{
 					LargestAcked: protocol.PacketNumber(largestObserved),
-					LowestAcked:  1,
+					LowestAcked:  0,
 					AckRanges: []wire.AckRange{
 						{First: 4, Last: protocol.PacketNumber(largestObserved)},
-						{First: 1, Last: 2},
+						{First: 0, Last: 2},
 					},
 				}
 				err := handler.ReceivedAck(&ack1, 1, protocol.EncryptionUnencrypted, time.Now())
 				Expect(err).ToNot(HaveOccurred())
 				Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(len(packets) - 5)))
-				expectInPacketHistory([]protocol.PacketNumber{0, 3, 7, 8, 9, 10, 12})
+				expectInPacketHistory([]protocol.PacketNumber{3, 6, 7, 8, 9, 10, 12})
 				ack2 := wire.AckFrame{
 					LargestAcked: protocol.PacketNumber(largestObserved),
-					LowestAcked:  1,
+					LowestAcked:  0,
 				}
 				err = handler.ReceivedAck(&ack2, 2, protocol.EncryptionUnencrypted, time.Now())
 				Expect(err).ToNot(HaveOccurred())
 				Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(len(packets) - 6)))
-				expectInPacketHistory([]protocol.PacketNumber{0, 7, 8, 9, 10, 12})
+				expectInPacketHistory([]protocol.PacketNumber{6, 7, 8, 9, 10, 12})
 			})
 
 			It("processes an ACK frame that would be sent after a late arrival of a packet and another packet", func() {
 				ack1 := wire.AckFrame{
-					LargestAcked: 6,
+					LargestAcked: 5,
 					LowestAcked:  0,
 					AckRanges: []wire.AckRange{
-						{First: 4, Last: 6},
+						{First: 4, Last: 5},
 						{First: 0, Last: 2},
 					},
 				}
 				err := handler.ReceivedAck(&ack1, 1, protocol.EncryptionUnencrypted, time.Now())
 				Expect(err).ToNot(HaveOccurred())
-				Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(len(packets) - 6)))
-				expectInPacketHistory([]protocol.PacketNumber{3, 7, 8, 9, 10, 12})
+				Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(len(packets) - 5)))
+				expectInPacketHistory([]protocol.PacketNumber{3, 6, 7, 8, 9, 10, 12})
 				ack2 := wire.AckFrame{
 					LargestAcked: 7,
 					LowestAcked:  1,
@@ -458,25 +476,25 @@ var _ = Describe("SentPacketHandler", func() {
 			It("processes an ACK that contains old ACK ranges", func() {
 				ack1 := wire.AckFrame{
 					LargestAcked: 6,
-					LowestAcked:  1,
+					LowestAcked:  0,
 				}
 				err := handler.ReceivedAck(&ack1, 1, protocol.EncryptionUnencrypted, time.Now())
 				Expect(err).ToNot(HaveOccurred())
-				expectInPacketHistory([]protocol.PacketNumber{0, 7, 8, 9, 10, 12})
-				Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(len(packets) - 6)))
+				expectInPacketHistory([]protocol.PacketNumber{7, 8, 9, 10, 12})
+				Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(len(packets) - 7)))
 				ack2 := wire.AckFrame{
-					LargestAcked: 10,
+					LargestAcked: 9,
 					LowestAcked:  1,
 					AckRanges: []wire.AckRange{
-						{First: 8, Last: 10},
+						{First: 8, Last: 9},
 						{First: 3, Last: 3},
 						{First: 1, Last: 1},
 					},
 				}
 				err = handler.ReceivedAck(&ack2, 2, protocol.EncryptionUnencrypted, time.Now())
 				Expect(err).ToNot(HaveOccurred())
-				Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(len(packets) - 6 - 3)))
-				expectInPacketHistory([]protocol.PacketNumber{0, 7, 12})
+				Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(len(packets) - 7 - 2)))
+				expectInPacketHistory([]protocol.PacketNumber{7, 10, 12})
 			})
 		})
 
@@ -484,9 +502,9 @@ var _ = Describe("SentPacketHandler", func() {
 			It("computes the RTT", func() {
 				now := time.Now()
 				// First, fake the sent times of the first, second and last packet
-				getPacketElement(1).Value.sendTime = now.Add(-10 * time.Minute)
-				getPacketElement(2).Value.sendTime = now.Add(-5 * time.Minute)
-				getPacketElement(6).Value.sendTime = now.Add(-1 * time.Minute)
+				getPacket(1).sendTime = now.Add(-10 * time.Minute)
+				getPacket(2).sendTime = now.Add(-5 * time.Minute)
+				getPacket(6).sendTime = now.Add(-1 * time.Minute)
 				// Now, check that the proper times are used when calculating the deltas
 				err := handler.ReceivedAck(&wire.AckFrame{LargestAcked: 1}, 1, protocol.EncryptionUnencrypted, time.Now())
 				Expect(err).NotTo(HaveOccurred())
@@ -503,7 +521,7 @@ var _ = Describe("SentPacketHandler", func() {
 				now := time.Now()
 				// make sure the rttStats have a min RTT, so that the delay is used
 				handler.rttStats.UpdateRTT(5*time.Minute, 0, time.Now())
-				getPacketElement(1).Value.sendTime = now.Add(-10 * time.Minute)
+				getPacket(1).sendTime = now.Add(-10 * time.Minute)
 				err := handler.ReceivedAck(&wire.AckFrame{LargestAcked: 1, DelayTime: 5 * time.Minute}, 1, protocol.EncryptionUnencrypted, time.Now())
 				Expect(err).NotTo(HaveOccurred())
 				Expect(handler.rttStats.LatestRTT()).To(BeNumerically("~", 5*time.Minute, 1*time.Second))
@@ -577,9 +595,9 @@ var _ = Describe("SentPacketHandler", func() {
 		})
 
 		It("dequeues a packet for retransmission", func() {
-			getPacketElement(1).Value.sendTime = time.Now().Add(-time.Hour)
+			getPacket(1).sendTime = time.Now().Add(-time.Hour)
 			handler.OnAlarm()
-			Expect(getPacketElement(1)).To(BeNil())
+			Expect(getPacket(1)).To(BeNil())
 			Expect(handler.retransmissionQueue).To(HaveLen(1))
 			Expect(handler.retransmissionQueue[0].PacketNumber).To(Equal(protocol.PacketNumber(1)))
 			packet := handler.DequeuePacketForRetransmission()
@@ -593,7 +611,7 @@ var _ = Describe("SentPacketHandler", func() {
 				if i == 2 { // packet 2 was already acked in BeforeEach
 					continue
 				}
-				handler.queuePacketForRetransmission(getPacketElement(i))
+				handler.queuePacketForRetransmission(getPacket(i))
 			}
 			Expect(handler.retransmissionQueue).To(HaveLen(6))
 			handler.SetHandshakeComplete()
@@ -615,7 +633,7 @@ var _ = Describe("SentPacketHandler", func() {
 			})
 
 			It("gets a STOP_WAITING frame after queueing a retransmission", func() {
-				handler.queuePacketForRetransmission(getPacketElement(5))
+				handler.queuePacketForRetransmission(getPacket(5))
 				Expect(handler.GetStopWaitingFrame(false)).To(Equal(&wire.StopWaitingFrame{LeastUnacked: 6}))
 			})
 		})
@@ -651,18 +669,96 @@ var _ = Describe("SentPacketHandler", func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(2)))
 
-		handler.packetHistory.Front().Value.sendTime = time.Now().Add(-time.Hour)
+		handler.packetHistory.FirstOutstanding().sendTime = time.Now().Add(-time.Hour)
 		handler.OnAlarm()
 
 		Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(0)))
 	})
 
+	Context("ECN", func() {
+		var cong *mocks.MockSendAlgorithm
+
+		BeforeEach(func() {
+			cong = mocks.NewMockSendAlgorithm(mockCtrl)
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			cong.EXPECT().PacingRate(gomock.Any()).AnyTimes()
+			cong.EXPECT().MaybeExitSlowStart().AnyTimes()
+			cong.EXPECT().OnBandwidthSample(gomock.Any()).AnyTimes()
+			cong.EXPECT().OnPacketAcked(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			handler.congestion = cong
+			handler.EnableECN()
+		})
+
+		It("doesn't send ECT(0) packets if ECN is not enabled", func() {
+			handler.ecnEnabled = false
+			p := retransmittablePacket(1)
+			Expect(handler.SentPacket(p)).To(Succeed())
+			Expect(p.ECN).To(Equal(protocol.ECNNon))
+		})
+
+		It("sends ECT(0) packets", func() {
+			p := retransmittablePacket(1)
+			Expect(handler.SentPacket(p)).To(Succeed())
+			Expect(p.ECN).To(Equal(protocol.ECT0))
+			Expect(handler.packetHistory.FirstOutstanding().ECN).To(Equal(protocol.ECT0))
+		})
+
+		It("reports CE marks to the congestion controller", func() {
+			for i := 1; i <= 3; i++ {
+				Expect(handler.SentPacket(retransmittablePacket(protocol.PacketNumber(i)))).To(Succeed())
+			}
+			cong.EXPECT().OnCongestionExperienced(protocol.PacketNumber(2), protocol.ByteCount(1))
+			ack := &wire.AckFrame{LargestAcked: 2, LowestAcked: 1, ECN: &wire.ECNCounts{ECT0: 1, CE: 1}}
+			err := handler.ReceivedAck(ack, 1, protocol.EncryptionForwardSecure, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.ecnEnabled).To(BeTrue())
+		})
+
+		It("only reports new CE marks", func() {
+			for i := 1; i <= 3; i++ {
+				Expect(handler.SentPacket(retransmittablePacket(protocol.PacketNumber(i)))).To(Succeed())
+			}
+			cong.EXPECT().OnCongestionExperienced(protocol.PacketNumber(1), gomock.Any())
+			ack := &wire.AckFrame{LargestAcked: 1, LowestAcked: 1, ECN: &wire.ECNCounts{CE: 1}}
+			err := handler.ReceivedAck(ack, 1, protocol.EncryptionForwardSecure, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			ack = &wire.AckFrame{LargestAcked: 3, LowestAcked: 1, ECN: &wire.ECNCounts{ECT0: 2, CE: 1}}
+			err = handler.ReceivedAck(ack, 2, protocol.EncryptionForwardSecure, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.ecnEnabled).To(BeTrue())
+		})
+
+		It("disables ECN if the ACK doesn't contain ECN counts", func() {
+			Expect(handler.SentPacket(retransmittablePacket(1))).To(Succeed())
+			err := handler.ReceivedAck(&wire.AckFrame{LargestAcked: 1, LowestAcked: 1}, 1, protocol.EncryptionForwardSecure, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.ecnEnabled).To(BeFalse())
+			p := retransmittablePacket(2)
+			Expect(handler.SentPacket(p)).To(Succeed())
+			Expect(p.ECN).To(Equal(protocol.ECNNon))
+		})
+
+		It("disables ECN if the ECN marks were cleared on the path", func() {
+			for i := 1; i <= 3; i++ {
+				Expect(handler.SentPacket(retransmittablePacket(protocol.PacketNumber(i)))).To(Succeed())
+			}
+			ack := &wire.AckFrame{LargestAcked: 1, LowestAcked: 1, ECN: &wire.ECNCounts{ECT0: 1}}
+			err := handler.ReceivedAck(ack, 1, protocol.EncryptionForwardSecure, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.ecnEnabled).To(BeTrue())
+			// packets 2 and 3 arrived without ECN marks
+			ack = &wire.AckFrame{LargestAcked: 3, LowestAcked: 1, ECN: &wire.ECNCounts{ECT0: 1}}
+			err = handler.ReceivedAck(ack, 2, protocol.EncryptionForwardSecure, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.ecnEnabled).To(BeFalse())
+		})
+	})
+
 	Context("congestion", func() {
 		var cong *mocks.MockSendAlgorithm
 
 		BeforeEach(func() {
 			cong = mocks.NewMockSendAlgorithm(mockCtrl)
-			cong.EXPECT().RetransmissionDelay().AnyTimes()
 			handler.congestion = cong
 		})
 
@@ -674,7 +770,7 @@ var _ = Describe("SentPacketHandler", func() {
 				protocol.ByteCount(42),
 				true,
 			)
-			cong.EXPECT().TimeUntilSend(gomock.Any())
+			cong.EXPECT().PacingRate(gomock.Any())
 			p := &Packet{
 				PacketNumber: 1,
 				Length:       42,
@@ -686,8 +782,9 @@ var _ = Describe("SentPacketHandler", func() {
 
 		It("should call MaybeExitSlowStart and OnPacketAcked", func() {
 			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
-			cong.EXPECT().TimeUntilSend(gomock.Any()).Times(2)
+			cong.EXPECT().PacingRate(gomock.Any()).Times(3)
 			cong.EXPECT().MaybeExitSlowStart()
+			cong.EXPECT().OnBandwidthSample(gomock.Any())
 			cong.EXPECT().OnPacketAcked(
 				protocol.PacketNumber(1),
 				protocol.ByteCount(1),
@@ -699,24 +796,151 @@ var _ = Describe("SentPacketHandler", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 
-		It("should call MaybeExitSlowStart and OnPacketLost", func() {
-			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
-			cong.EXPECT().TimeUntilSend(gomock.Any()).Times(3)
-			cong.EXPECT().OnRetransmissionTimeout(true).Times(2)
-			cong.EXPECT().OnPacketLost(
-				protocol.PacketNumber(1),
-				protocol.ByteCount(1),
-				protocol.ByteCount(2),
+		It("passes bandwidth samples to the congestion controller", func() {
+			var samples []congestion.BandwidthSample
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+			cong.EXPECT().PacingRate(gomock.Any()).Times(3)
+			cong.EXPECT().MaybeExitSlowStart()
+			gomock.InOrder(
+				cong.EXPECT().OnBandwidthSample(gomock.Any()).Do(func(s congestion.BandwidthSample) { samples = append(samples, s) }),
+				cong.EXPECT().OnPacketAcked(protocol.PacketNumber(1), gomock.Any(), gomock.Any()),
+				cong.EXPECT().OnBandwidthSample(gomock.Any()).Do(func(s congestion.BandwidthSample) { samples = append(samples, s) }),
+				cong.EXPECT().OnPacketAcked(protocol.PacketNumber(2), gomock.Any(), gomock.Any()),
 			)
-			cong.EXPECT().OnPacketLost(
-				protocol.PacketNumber(2),
-				protocol.ByteCount(1),
-				protocol.ByteCount(1),
+			for i := 1; i <= 2; i++ {
+				p := retransmittablePacket(protocol.PacketNumber(i))
+				p.Length = 1000
+				handler.SentPacket(p)
+			}
+			err := handler.ReceivedAck(&wire.AckFrame{LargestAcked: 2, LowestAcked: 1}, 1, protocol.EncryptionForwardSecure, time.Now().Add(time.Second))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(samples).To(HaveLen(2))
+			Expect(samples[0].Delivered).To(Equal(protocol.ByteCount(1000)))
+			Expect(samples[1].Delivered).To(Equal(protocol.ByteCount(2000)))
+			Expect(samples[1].IsAppLimited).To(BeFalse())
+			// 2000 bytes were delivered in (slightly more than) one second
+			Expect(samples[1].Bandwidth).To(BeNumerically("~", 2000*congestion.BytesPerSecond, 10*congestion.BytesPerSecond))
+		})
+
+		It("marks bandwidth samples as application-limited", func() {
+			var sample congestion.BandwidthSample
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			cong.EXPECT().PacingRate(gomock.Any()).Times(2)
+			cong.EXPECT().MaybeExitSlowStart()
+			cong.EXPECT().OnBandwidthSample(gomock.Any()).Do(func(s congestion.BandwidthSample) { sample = s })
+			cong.EXPECT().OnPacketAcked(gomock.Any(), gomock.Any(), gomock.Any())
+			handler.SetAppLimited()
+			handler.SentPacket(retransmittablePacket(1))
+			err := handler.ReceivedAck(&wire.AckFrame{LargestAcked: 1, LowestAcked: 1}, 1, protocol.EncryptionForwardSecure, time.Now().Add(time.Second))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sample.IsAppLimited).To(BeTrue())
+		})
+
+		It("returns the bandwidth estimate of the congestion controller", func() {
+			cong.EXPECT().BandwidthEstimate().Return(1337 * congestion.BytesPerSecond)
+			Expect(handler.BandwidthEstimate()).To(Equal(1337 * congestion.BytesPerSecond))
+		})
+
+		It("should call MaybeExitSlowStart and OnPacketLost", func() {
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(5)
+			cong.EXPECT().PacingRate(gomock.Any()).Times(6)
+			cong.EXPECT().MaybeExitSlowStart()
+			cong.EXPECT().OnBandwidthSample(gomock.Any())
+			cong.EXPECT().OnPacketAcked(protocol.PacketNumber(5), gomock.Any(), gomock.Any())
+			gomock.InOrder(
+				cong.EXPECT().OnPacketLost(protocol.PacketNumber(1), protocol.ByteCount(1), protocol.ByteCount(3)),
+				cong.EXPECT().OnPacketLost(protocol.PacketNumber(2), protocol.ByteCount(1), protocol.ByteCount(2)),
 			)
+			for i := protocol.PacketNumber(1); i <= 5; i++ {
+				handler.SentPacket(retransmittablePacket(i))
+			}
+			// packets 1 and 2 are lost, since packet 5 was acknowledged
+			err := handler.ReceivedAck(&wire.AckFrame{LargestAcked: 5, LowestAcked: 5}, 1, protocol.EncryptionForwardSecure, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("declares the packets lost when a probe packet is acknowledged, not when the PTO expires", func() {
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			cong.EXPECT().PacingRate(gomock.Any()).AnyTimes()
+			cong.EXPECT().MaybeExitSlowStart().AnyTimes()
+			cong.EXPECT().OnBandwidthSample(gomock.Any()).AnyTimes()
+			cong.EXPECT().OnPacketAcked(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			// take an RTT sample
 			handler.SentPacket(retransmittablePacket(1))
+			getPacket(1).sendTime = time.Now().Add(-time.Minute)
+			err := handler.ReceivedAck(&wire.AckFrame{LargestAcked: 1, LowestAcked: 1}, 1, protocol.EncryptionForwardSecure, time.Now().Add(-time.Minute+100*time.Millisecond))
+			Expect(err).ToNot(HaveOccurred())
+			// The tail of the connection is lost.
+			// Packets 2 to 5 are sent during more than the persistent congestion period.
+			period := persistentCongestionThreshold * handler.computePTOTimeout()
+			now := time.Now()
+			for pn := protocol.PacketNumber(2); pn <= 5; pn++ {
+				handler.SentPacket(retransmittablePacket(pn))
+				getPacket(pn).sendTime = now.Add(-2*period + time.Duration(pn-2)*period/2)
+			}
+			handler.OnAlarm() // PTO
+			Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(4)))
+			expectInPacketHistory([]protocol.PacketNumber{2, 3, 4, 5})
+			Expect(handler.DequeuePacketForRetransmission()).To(BeNil())
+			// probe packets are sent even if the congestion window is full
+			cong.EXPECT().GetCongestionWindow()
+			Expect(handler.SendingAllowed()).To(BeTrue())
+			Expect(handler.ShouldSendProbePacket()).To(BeTrue())
+			handler.SentPacket(retransmittablePacket(6))
+			handler.SentPacket(retransmittablePacket(7))
+			Expect(handler.ShouldSendProbePacket()).To(BeFalse())
+			// the ACK for the first probe packet declares the original packets lost
+			for pn := protocol.PacketNumber(2); pn <= 5; pn++ {
+				cong.EXPECT().OnPacketLost(pn, protocol.ByteCount(1), gomock.Any())
+			}
+			cong.EXPECT().OnPersistentCongestion()
+			err = handler.ReceivedAck(&wire.AckFrame{LargestAcked: 6, LowestAcked: 6}, 2, protocol.EncryptionForwardSecure, now.Add(100*time.Millisecond))
+			Expect(err).ToNot(HaveOccurred())
+			expectInPacketHistory([]protocol.PacketNumber{7})
+			for pn := protocol.PacketNumber(2); pn <= 5; pn++ {
+				p := handler.DequeuePacketForRetransmission()
+				Expect(p).ToNot(BeNil())
+				Expect(p.PacketNumber).To(Equal(pn))
+			}
+		})
+
+		It("doesn't call OnPacketLost for MTU probe packets detected lost by an ACK", func() {
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+			cong.EXPECT().PacingRate(gomock.Any()).Times(3)
+			cong.EXPECT().MaybeExitSlowStart()
+			cong.EXPECT().OnBandwidthSample(gomock.Any())
+			cong.EXPECT().OnPacketAcked(protocol.PacketNumber(2), gomock.Any(), gomock.Any())
+			probe := retransmittablePacket(1)
+			probe.IsMTUProbePacket = true
+			handler.SentPacket(probe)
+			handler.packetHistory.FirstOutstanding().sendTime = time.Now().Add(-time.Hour)
 			handler.SentPacket(retransmittablePacket(2))
+			err := handler.ReceivedAck(&wire.AckFrame{LargestAcked: 2, LowestAcked: 2}, 1, protocol.EncryptionForwardSecure, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			p := handler.DequeuePacketForRetransmission()
+			Expect(p).ToNot(BeNil())
+			Expect(p.IsMTUProbePacket).To(BeTrue())
+		})
+
+		It("counts repair packets towards the bytes in flight, but doesn't retransmit them", func() {
+			cong.EXPECT().OnPacketSent(gomock.Any(), protocol.ByteCount(2), protocol.PacketNumber(2), protocol.ByteCount(1), true)
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(4)
+			cong.EXPECT().PacingRate(gomock.Any()).Times(6)
+			cong.EXPECT().MaybeExitSlowStart()
+			cong.EXPECT().OnBandwidthSample(gomock.Any()).Times(2)
+			cong.EXPECT().OnPacketAcked(gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+			cong.EXPECT().OnPacketLost(protocol.PacketNumber(2), protocol.ByteCount(1), gomock.Any())
+			handler.SentPacket(retransmittablePacket(1))
+			handler.SentPacket(&Packet{PacketNumber: 2, Length: 1, Frames: []wire.Frame{&wire.FECFrame{}}, IsRepairPacket: true})
+			Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(2)))
 			handler.SentPacket(retransmittablePacket(3))
-			handler.OnAlarm() // RTO, meaning 2 lost packets
+			handler.SentPacket(retransmittablePacket(4))
+			handler.SentPacket(retransmittablePacket(5))
+			// packet 2 is lost, since packet 5 was acknowledged
+			err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{First: 5, Last: 5}, {First: 1, Last: 1}}, LargestAcked: 5, LowestAcked: 1}, 1, protocol.EncryptionForwardSecure, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(2)))
+			Expect(handler.DequeuePacketForRetransmission()).To(BeNil())
 		})
 
 		It("allows or denies sending based on congestion", func() {
@@ -743,63 +967,136 @@ var _ = Describe("SentPacketHandler", func() {
 			Expect(handler.SendingAllowed()).To(BeTrue())
 		})
 
-		It("gets the pacing delay", func() {
-			handler.bytesInFlight = 100
+		It("doesn't pace packets, if the congestion controller doesn't set a pacing rate", func() {
 			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
-			cong.EXPECT().TimeUntilSend(protocol.ByteCount(100)).Return(time.Hour)
-			handler.SentPacket(&Packet{PacketNumber: 1})
-			Expect(handler.TimeUntilSend()).To(BeTemporally("~", time.Now().Add(time.Hour), time.Second))
+			cong.EXPECT().PacingRate(gomock.Any()).Return(congestion.Bandwidth(0))
+			handler.SentPacket(&Packet{PacketNumber: 1, Length: protocol.DefaultTCPMSS})
+			Expect(handler.TimeUntilSend()).To(BeZero())
+			Expect(handler.ShouldSendNumPackets()).To(Equal(1))
 		})
 
-		It("allows sending of one packet, if it should be sent immediately", func() {
-			cong.EXPECT().TimeUntilSend(gomock.Any()).Return(time.Duration(0))
-			Expect(handler.ShouldSendNumPackets()).To(Equal(1))
+		It("sends the initial burst without pacing", func() {
+			rate := congestion.BandwidthFromDelta(protocol.DefaultTCPMSS, 10*time.Millisecond)
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(protocol.DefaultInitialPacingBurst)
+			cong.EXPECT().PacingRate(gomock.Any()).Return(rate).Times(protocol.DefaultInitialPacingBurst)
+			for i := 1; i <= protocol.DefaultInitialPacingBurst; i++ {
+				Expect(handler.TimeUntilSend()).To(BeZero())
+				handler.SentPacket(&Packet{PacketNumber: protocol.PacketNumber(i), Length: protocol.DefaultTCPMSS})
+			}
+			Expect(handler.TimeUntilSend()).To(BeTemporally("~", time.Now().Add(10*time.Millisecond), time.Millisecond))
 		})
 
-		It("allows sending of multiple packets, if the pacing delay is smaller than the minimum", func() {
-			pacingDelay := protocol.MinPacingDelay / 10
-			cong.EXPECT().TimeUntilSend(gomock.Any()).Return(pacingDelay)
-			Expect(handler.ShouldSendNumPackets()).To(Equal(10))
+		It("updates the pacing rate when an ACK is received", func() {
+			rate := congestion.BandwidthFromDelta(protocol.DefaultTCPMSS, 10*time.Millisecond)
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			cong.EXPECT().PacingRate(gomock.Any()).Return(rate)
+			handler.SentPacket(retransmittablePacket(1))
+			// use up the initial burst
+			handler.pacer.SentPacket(time.Now(), protocol.DefaultInitialPacingBurst*protocol.DefaultTCPMSS)
+			Expect(handler.TimeUntilSend()).To(BeTemporally("~", time.Now().Add(10*time.Millisecond), time.Millisecond))
+			// the ACK doubles the pacing rate, before the next packet is sent
+			cong.EXPECT().MaybeExitSlowStart()
+			cong.EXPECT().OnBandwidthSample(gomock.Any())
+			cong.EXPECT().OnPacketAcked(gomock.Any(), gomock.Any(), gomock.Any())
+			cong.EXPECT().PacingRate(gomock.Any()).Return(2 * rate)
+			err := handler.ReceivedAck(&wire.AckFrame{LargestAcked: 1, LowestAcked: 1}, 1, protocol.EncryptionForwardSecure, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.TimeUntilSend()).To(BeTemporally("~", time.Now().Add(5*time.Millisecond), time.Millisecond))
+		})
+
+		It("updates the pacing rate when the alarm fires", func() {
+			rate := congestion.BandwidthFromDelta(protocol.DefaultTCPMSS, 10*time.Millisecond)
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			cong.EXPECT().PacingRate(gomock.Any()).Return(rate)
+			handler.SentPacket(retransmittablePacket(1))
+			// use up the initial burst
+			handler.pacer.SentPacket(time.Now(), protocol.DefaultInitialPacingBurst*protocol.DefaultTCPMSS)
+			Expect(handler.TimeUntilSend()).To(BeTemporally("~", time.Now().Add(10*time.Millisecond), time.Millisecond))
+			cong.EXPECT().PacingRate(gomock.Any()).Return(rate / 2)
+			handler.OnAlarm() // PTO
+			Expect(handler.TimeUntilSend()).To(BeTemporally("~", time.Now().Add(20*time.Millisecond), time.Millisecond))
 		})
 
-		It("allows sending of multiple packets, if the pacing delay is smaller than the minimum, and not a fraction", func() {
-			pacingDelay := protocol.MinPacingDelay * 2 / 5
-			cong.EXPECT().TimeUntilSend(gomock.Any()).Return(pacingDelay)
-			Expect(handler.ShouldSendNumPackets()).To(Equal(3))
+		It("allows sending of multiple packets, if the pacer's budget allows it", func() {
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			cong.EXPECT().PacingRate(gomock.Any()).Return(congestion.BandwidthFromDelta(protocol.DefaultTCPMSS, time.Second))
+			handler.SentPacket(&Packet{PacketNumber: 1, Length: protocol.DefaultTCPMSS})
+			Expect(handler.ShouldSendNumPackets()).To(Equal(protocol.DefaultInitialPacingBurst - 1))
 		})
 	})
 
-	Context("calculating RTO", func() {
-		It("uses default RTO", func() {
-			Expect(handler.computeRTOTimeout()).To(Equal(defaultRTOTimeout))
+	Context("calculating the PTO", func() {
+		It("uses the default RTT", func() {
+			Expect(handler.computePTOTimeout()).To(Equal(2 * defaultInitialRTT))
 		})
 
-		It("uses RTO from rttStats", func() {
+		It("uses the RTT from rttStats", func() {
 			rtt := time.Second
-			expected := rtt + rtt/2*4
 			handler.rttStats.UpdateRTT(rtt, 0, time.Now())
-			Expect(handler.computeRTOTimeout()).To(Equal(expected))
+			Expect(handler.computePTOTimeout()).To(Equal(rtt + rtt/2*4 + protocol.AckSendDelay))
 		})
 
-		It("limits RTO min", func() {
-			rtt := time.Millisecond
+		It("uses the maximum ACK delay of the peer", func() {
+			handler.SetMaxAckDelay(100 * time.Millisecond)
+			rtt := time.Second
 			handler.rttStats.UpdateRTT(rtt, 0, time.Now())
-			Expect(handler.computeRTOTimeout()).To(Equal(minRTOTimeout))
+			Expect(handler.computePTOTimeout()).To(Equal(rtt + rtt/2*4 + 100*time.Millisecond))
 		})
 
-		It("limits RTO max", func() {
-			rtt := time.Hour
+		It("doesn't include the maximum ACK delay during the handshake", func() {
+			handler.handshakeComplete = false
+			rtt := time.Second
 			handler.rttStats.UpdateRTT(rtt, 0, time.Now())
-			Expect(handler.computeRTOTimeout()).To(Equal(maxRTOTimeout))
+			Expect(handler.computePTOTimeout()).To(Equal(rtt + rtt/2*4))
 		})
 
-		It("implements exponential backoff", func() {
-			handler.rtoCount = 0
-			Expect(handler.computeRTOTimeout()).To(Equal(defaultRTOTimeout))
-			handler.rtoCount = 1
-			Expect(handler.computeRTOTimeout()).To(Equal(2 * defaultRTOTimeout))
-			handler.rtoCount = 2
-			Expect(handler.computeRTOTimeout()).To(Equal(4 * defaultRTOTimeout))
+		It("backs off exponentially", func() {
+			pto := handler.computePTOTimeout()
+			Expect(handler.computeBackedOffPTOTimeout()).To(Equal(pto))
+			handler.ptoCount = 3
+			Expect(handler.computeBackedOffPTOTimeout()).To(Equal(8 * pto))
+		})
+
+		It("limits the PTO", func() {
+			handler.rttStats.UpdateRTT(time.Second, 0, time.Now())
+			handler.ptoCount = 5
+			Expect(handler.computeBackedOffPTOTimeout()).To(Equal(maxPTOTimeout))
+		})
+
+		It("doesn't overflow for large PTO counts", func() {
+			handler.ptoCount = 200
+			Expect(handler.computeBackedOffPTOTimeout()).To(Equal(maxPTOTimeout))
+		})
+
+		It("sets the alarm to the maximum PTO", func() {
+			handler.SentPacket(retransmittablePacket(1))
+			handler.ptoCount = 20
+			handler.updateLossDetectionAlarm()
+			Expect(handler.GetAlarmTimeout()).To(Equal(handler.lastSentRetransmittableTime.Add(maxPTOTimeout)))
+		})
+	})
+
+	Context("Packet threshold loss detection", func() {
+		It("detects packets as lost when a packet sent 3 packets later is acknowledged", func() {
+			for i := protocol.PacketNumber(1); i <= 5; i++ {
+				handler.SentPacket(retransmittablePacket(i))
+			}
+			err := handler.ReceivedAck(&wire.AckFrame{LargestAcked: 4, LowestAcked: 4}, 1, protocol.EncryptionForwardSecure, time.Now())
+			Expect(err).NotTo(HaveOccurred())
+			expectInPacketHistory([]protocol.PacketNumber{2, 3, 5})
+			Expect(handler.lossTime.IsZero()).To(BeFalse())
+			p := handler.DequeuePacketForRetransmission()
+			Expect(p).ToNot(BeNil())
+			Expect(p.PacketNumber).To(Equal(protocol.PacketNumber(1)))
+			Expect(handler.DequeuePacketForRetransmission()).To(BeNil())
+		})
+
+		It("doesn't count skipped packet numbers as later packets", func() {
+			handler.SentPacket(retransmittablePacket(1))
+			handler.SentPacket(retransmittablePacket(3))
+			err := handler.ReceivedAck(&wire.AckFrame{LargestAcked: 3, LowestAcked: 3}, 1, protocol.EncryptionForwardSecure, time.Now())
+			Expect(err).NotTo(HaveOccurred())
+			expectInPacketHistory([]protocol.PacketNumber{1})
 		})
 	})
 
@@ -820,7 +1117,7 @@ var _ = Describe("SentPacketHandler", func() {
 			Expect(handler.lossTime.Sub(time.Now())).To(BeNumerically("~", time.Hour*9/8, time.Minute))
 			Expect(handler.GetAlarmTimeout().Sub(time.Now())).To(BeNumerically("~", time.Hour*9/8, time.Minute))
 
-			handler.packetHistory.Front().Value.sendTime = time.Now().Add(-2 * time.Hour)
+			handler.packetHistory.FirstOutstanding().sendTime = time.Now().Add(-2 * time.Hour)
 			handler.OnAlarm()
 			Expect(handler.DequeuePacketForRetransmission()).NotTo(BeNil())
 		})
@@ -837,13 +1134,171 @@ var _ = Describe("SentPacketHandler", func() {
 			err = handler.ReceivedAck(&wire.AckFrame{LargestAcked: 1, LowestAcked: 1}, 1, protocol.EncryptionUnencrypted, time.Now().Add(time.Hour))
 			Expect(err).NotTo(HaveOccurred())
 			Expect(handler.lossTime.IsZero()).To(BeTrue())
-			Expect(handler.GetAlarmTimeout().Sub(time.Now())).To(BeNumerically("~", handler.computeRTOTimeout(), time.Minute))
+			Expect(handler.GetAlarmTimeout().Sub(time.Now())).To(BeNumerically("~", handler.computePTOTimeout(), time.Minute))
 
-			// This means RTO, so both packets should be lost
+			// This means PTO, so probe packets are sent
 			handler.OnAlarm()
-			Expect(handler.DequeuePacketForRetransmission()).ToNot(BeNil())
-			Expect(handler.DequeuePacketForRetransmission()).ToNot(BeNil())
+			Expect(handler.ShouldSendProbePacket()).To(BeTrue())
 			Expect(handler.DequeuePacketForRetransmission()).To(BeNil())
+			expectInPacketHistory([]protocol.PacketNumber{2, 3})
+		})
+	})
+
+	Context("spurious loss detection", func() {
+		var (
+			cong     *mocks.MockSendAlgorithm
+			sendTime time.Time
+		)
+
+		BeforeEach(func() {
+			cong = mocks.NewMockSendAlgorithm(mockCtrl)
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			cong.EXPECT().PacingRate(gomock.Any()).AnyTimes()
+			cong.EXPECT().MaybeExitSlowStart().AnyTimes()
+			cong.EXPECT().OnBandwidthSample(gomock.Any()).AnyTimes()
+			cong.EXPECT().OnPacketAcked(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			cong.EXPECT().OnPacketLost(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			handler.congestion = cong
+			sendTime = time.Now()
+		})
+
+		// loseFirstPackets sends packets 1 to 10, and declares packets 1 to 7 lost, by acknowledging packet 10.
+		// This takes an RTT sample of 100ms.
+		loseFirstPackets := func() {
+			for i := protocol.PacketNumber(1); i <= 10; i++ {
+				handler.SentPacket(retransmittablePacket(i))
+				getPacket(i).sendTime = sendTime
+			}
+			err := handler.ReceivedAck(&wire.AckFrame{LargestAcked: 10, LowestAcked: 10}, 1, protocol.EncryptionForwardSecure, sendTime.Add(100*time.Millisecond))
+			Expect(err).ToNot(HaveOccurred())
+			expectInPacketHistory([]protocol.PacketNumber{8, 9})
+			Expect(handler.rttStats.LatestRTT()).To(Equal(100 * time.Millisecond))
+		}
+
+		It("notifies the congestion controller when a lost packet is acknowledged", func() {
+			loseFirstPackets()
+			ack := &wire.AckFrame{
+				LargestAcked: 10,
+				LowestAcked:  1,
+				AckRanges: []wire.AckRange{
+					{First: 10, Last: 10},
+					{First: 6, Last: 7},
+					{First: 1, Last: 1},
+				},
+			}
+			gomock.InOrder(
+				cong.EXPECT().OnSpuriousLoss(protocol.PacketNumber(1)),
+				cong.EXPECT().OnSpuriousLoss(protocol.PacketNumber(6)),
+				cong.EXPECT().OnSpuriousLoss(protocol.PacketNumber(7)),
+			)
+			err := handler.ReceivedAck(ack, 2, protocol.EncryptionForwardSecure, sendTime.Add(110*time.Millisecond))
+			Expect(err).ToNot(HaveOccurred())
+			// the spurious losses are only reported once
+			err = handler.ReceivedAck(ack, 3, protocol.EncryptionForwardSecure, sendTime.Add(110*time.Millisecond))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("detects spurious losses in ACK frames that don't acknowledge any new packets", func() {
+			loseFirstPackets()
+			// packets 1 to 7 haven't arrived yet
+			err := handler.ReceivedAck(&wire.AckFrame{LargestAcked: 10, LowestAcked: 8}, 2, protocol.EncryptionForwardSecure, sendTime.Add(100*time.Millisecond))
+			Expect(err).ToNot(HaveOccurred())
+			handler.SentPacket(retransmittablePacket(11))
+			// this ACK frame would be ignored, since its LargestAcked is smaller than the lowest unacked packet
+			for i := protocol.PacketNumber(1); i <= 7; i++ {
+				cong.EXPECT().OnSpuriousLoss(i)
+			}
+			err = handler.ReceivedAck(&wire.AckFrame{LargestAcked: 10, LowestAcked: 1}, 3, protocol.EncryptionForwardSecure, sendTime.Add(110*time.Millisecond))
+			Expect(err).ToNot(HaveOccurred())
+			expectInPacketHistory([]protocol.PacketNumber{11})
+		})
+
+		It("increases the packet threshold", func() {
+			loseFirstPackets()
+			cong.EXPECT().OnSpuriousLoss(protocol.PacketNumber(3))
+			ack := &wire.AckFrame{
+				LargestAcked: 10,
+				LowestAcked:  3,
+				AckRanges: []wire.AckRange{
+					{First: 10, Last: 10},
+					{First: 3, Last: 3},
+				},
+			}
+			err := handler.ReceivedAck(ack, 2, protocol.EncryptionForwardSecure, sendTime.Add(110*time.Millisecond))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.packetThreshold).To(Equal(protocol.PacketNumber(8)))
+			Expect(handler.timeThreshold).To(Equal(defaultTimeThreshold))
+		})
+
+		It("increases the time threshold", func() {
+			loseFirstPackets()
+			cong.EXPECT().OnSpuriousLoss(protocol.PacketNumber(7))
+			ack := &wire.AckFrame{
+				LargestAcked: 10,
+				LowestAcked:  7,
+				AckRanges: []wire.AckRange{
+					{First: 10, Last: 10},
+					{First: 7, Last: 7},
+				},
+			}
+			err := handler.ReceivedAck(ack, 2, protocol.EncryptionForwardSecure, sendTime.Add(150*time.Millisecond))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.timeThreshold).To(Equal(1.5))
+			Expect(handler.packetThreshold).To(Equal(protocol.PacketNumber(defaultPacketThreshold + 1)))
+		})
+
+		It("limits the thresholds", func() {
+			for i := protocol.PacketNumber(1); i <= 50; i++ {
+				handler.SentPacket(retransmittablePacket(i))
+				getPacket(i).sendTime = sendTime
+			}
+			err := handler.ReceivedAck(&wire.AckFrame{LargestAcked: 50, LowestAcked: 50}, 1, protocol.EncryptionForwardSecure, sendTime.Add(100*time.Millisecond))
+			Expect(err).ToNot(HaveOccurred())
+			cong.EXPECT().OnSpuriousLoss(protocol.PacketNumber(1))
+			ack := &wire.AckFrame{
+				LargestAcked: 50,
+				LowestAcked:  1,
+				AckRanges: []wire.AckRange{
+					{First: 50, Last: 50},
+					{First: 1, Last: 1},
+				},
+			}
+			err = handler.ReceivedAck(ack, 2, protocol.EncryptionForwardSecure, sendTime.Add(time.Second))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.timeThreshold).To(Equal(maxTimeThreshold))
+			Expect(handler.packetThreshold).To(Equal(protocol.PacketNumber(maxPacketThreshold)))
+		})
+
+		It("uses the increased packet threshold for loss detection", func() {
+			handler.packetThreshold = 5
+			for i := protocol.PacketNumber(1); i <= 6; i++ {
+				handler.SentPacket(retransmittablePacket(i))
+			}
+			err := handler.ReceivedAck(&wire.AckFrame{LargestAcked: 6, LowestAcked: 6}, 1, protocol.EncryptionForwardSecure, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			expectInPacketHistory([]protocol.PacketNumber{2, 3, 4, 5})
+		})
+
+		It("uses the increased time threshold for loss detection", func() {
+			handler.timeThreshold = 1.5
+			handler.SentPacket(retransmittablePacket(1))
+			handler.SentPacket(retransmittablePacket(2))
+			getPacket(1).sendTime = sendTime
+			getPacket(2).sendTime = sendTime
+			err := handler.ReceivedAck(&wire.AckFrame{LargestAcked: 2, LowestAcked: 2}, 1, protocol.EncryptionForwardSecure, sendTime.Add(100*time.Millisecond))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.lossTime).To(Equal(sendTime.Add(150 * time.Millisecond)))
+		})
+
+		It("limits the number of lost packets tracked", func() {
+			last := protocol.PacketNumber(protocol.MaxTrackedLostPackets + 10)
+			for i := protocol.PacketNumber(1); i <= last; i++ {
+				handler.SentPacket(retransmittablePacket(i))
+			}
+			err := handler.ReceivedAck(&wire.AckFrame{LargestAcked: last, LowestAcked: last}, 1, protocol.EncryptionForwardSecure, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.lostPackets).To(HaveLen(protocol.MaxTrackedLostPackets))
+			Expect(handler.lostPackets[0].packetNumber).To(Equal(last - 2 - protocol.MaxTrackedLostPackets))
 		})
 	})
 
@@ -852,7 +1307,7 @@ var _ = Describe("SentPacketHandler", func() {
 			handler.handshakeComplete = false
 		})
 
-		It("detects the handshake timeout", func() {
+		It("retransmits all handshake packets when the PTO expires", func() {
 			// send handshake packets: 1, 2, 4
 			// send a forward-secure packet: 3
 			err := handler.SentPacket(handshakePacket(1))
@@ -867,8 +1322,8 @@ var _ = Describe("SentPacketHandler", func() {
 			err = handler.ReceivedAck(&wire.AckFrame{LargestAcked: 1, LowestAcked: 1}, 1, protocol.EncryptionSecure, time.Now())
 			Expect(err).NotTo(HaveOccurred())
 			Expect(handler.lossTime.IsZero()).To(BeTrue())
-			handshakeTimeout := handler.computeHandshakeTimeout()
-			Expect(handler.GetAlarmTimeout().Sub(time.Now())).To(BeNumerically("~", handshakeTimeout, time.Minute))
+			pto := handler.computePTOTimeout()
+			Expect(handler.GetAlarmTimeout().Sub(time.Now())).To(BeNumerically("~", pto, time.Minute))
 
 			handler.OnAlarm()
 			p := handler.DequeuePacketForRetransmission()
@@ -877,34 +1332,190 @@ var _ = Describe("SentPacketHandler", func() {
 			p = handler.DequeuePacketForRetransmission()
 			Expect(p).ToNot(BeNil())
 			Expect(p.PacketNumber).To(Equal(protocol.PacketNumber(4)))
-			Expect(handler.packetHistory.Len()).To(Equal(1))
-			Expect(handler.packetHistory.Front().Value.PacketNumber).To(Equal(protocol.PacketNumber(3)))
-			Expect(handler.handshakeCount).To(BeEquivalentTo(1))
+			Expect(handler.DequeuePacketForRetransmission()).To(BeNil())
+			expectInPacketHistory([]protocol.PacketNumber{3})
+			Expect(handler.ptoCount).To(BeEquivalentTo(1))
 			// make sure the exponential backoff is used
-			Expect(handler.computeHandshakeTimeout()).To(BeNumerically("~", 2*handshakeTimeout, time.Minute))
+			Expect(handler.GetAlarmTimeout().Sub(handler.lastSentRetransmittableTime)).To(Equal(2 * pto))
+		})
+
+		It("sends probe packets if no handshake packets are outstanding", func() {
+			err := handler.SentPacket(retransmittablePacket(1))
+			Expect(err).ToNot(HaveOccurred())
+			handler.OnAlarm()
+			Expect(handler.ShouldSendProbePacket()).To(BeTrue())
+			Expect(handler.DequeuePacketForRetransmission()).To(BeNil())
+			expectInPacketHistory([]protocol.PacketNumber{1})
 		})
 	})
 
-	Context("RTO retransmission", func() {
-		It("queues two packets if RTO expires", func() {
+	Context("probe timeout", func() {
+		It("sends two probe packets if the PTO expires", func() {
+			for i := protocol.PacketNumber(1); i <= 3; i++ {
+				err := handler.SentPacket(retransmittablePacket(i))
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			handler.rttStats.UpdateRTT(10*time.Second, 0, time.Now())
+			handler.updateLossDetectionAlarm()
+			Expect(handler.lossTime.IsZero()).To(BeTrue())
+			Expect(handler.GetAlarmTimeout().Sub(time.Now())).To(BeNumerically("~", handler.computePTOTimeout(), time.Second))
+
+			Expect(handler.ShouldSendProbePacket()).To(BeFalse())
+			handler.OnAlarm()
+			Expect(handler.ptoCount).To(BeEquivalentTo(1))
+			// the outstanding packets are neither retransmitted nor removed from the bytes in flight
+			Expect(handler.DequeuePacketForRetransmission()).To(BeNil())
+			expectInPacketHistory([]protocol.PacketNumber{1, 2, 3})
+			Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(3)))
+			Expect(handler.ShouldSendProbePacket()).To(BeTrue())
+			Expect(handler.SentPacket(retransmittablePacket(4))).To(Succeed())
+			Expect(handler.ShouldSendProbePacket()).To(BeTrue())
+			// non-retransmittable packets don't elicit an ACK, so they are not probe packets
+			Expect(handler.SentPacket(nonRetransmittablePacket(5))).To(Succeed())
+			Expect(handler.ShouldSendProbePacket()).To(BeTrue())
+			Expect(handler.SentPacket(retransmittablePacket(6))).To(Succeed())
+			Expect(handler.ShouldSendProbePacket()).To(BeFalse())
+		})
+
+		It("allows sending probe packets when congestion limited", func() {
+			Expect(handler.SentPacket(retransmittablePacket(1))).To(Succeed())
+			handler.bytesInFlight = protocol.DefaultMaxCongestionWindow * protocol.DefaultTCPMSS
+			Expect(handler.SendingAllowed()).To(BeFalse())
+			handler.OnAlarm()
+			Expect(handler.SendingAllowed()).To(BeTrue())
+		})
+
+		It("doesn't send probe packets after receiving an ACK", func() {
+			for i := protocol.PacketNumber(1); i <= 3; i++ {
+				Expect(handler.SentPacket(retransmittablePacket(i))).To(Succeed())
+			}
+			handler.OnAlarm()
+			Expect(handler.ShouldSendProbePacket()).To(BeTrue())
+			err := handler.ReceivedAck(&wire.AckFrame{LargestAcked: 3, LowestAcked: 3}, 1, protocol.EncryptionForwardSecure, time.Now())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(handler.ShouldSendProbePacket()).To(BeFalse())
+		})
+
+		It("sets the alarm relative to the last retransmittable packet sent", func() {
 			err := handler.SentPacket(retransmittablePacket(1))
 			Expect(err).NotTo(HaveOccurred())
-			err = handler.SentPacket(retransmittablePacket(2))
+			handler.lastSentRetransmittableTime = time.Now().Add(-time.Second)
+			err = handler.SentPacket(nonRetransmittablePacket(2))
 			Expect(err).NotTo(HaveOccurred())
+			Expect(handler.GetAlarmTimeout()).To(Equal(handler.lastSentRetransmittableTime.Add(handler.computePTOTimeout())))
+		})
 
-			handler.rttStats.UpdateRTT(time.Hour, 0, time.Now())
-			Expect(handler.lossTime.IsZero()).To(BeTrue())
-			Expect(handler.GetAlarmTimeout().Sub(time.Now())).To(BeNumerically("~", handler.computeRTOTimeout(), time.Minute))
+		It("uses exponential backoff", func() {
+			for i := protocol.PacketNumber(1); i <= 3; i++ {
+				err := handler.SentPacket(retransmittablePacket(i))
+				Expect(err).NotTo(HaveOccurred())
+			}
+			pto := handler.computePTOTimeout()
+			sentTime := handler.lastSentRetransmittableTime
+			Expect(handler.GetAlarmTimeout()).To(Equal(sentTime.Add(pto)))
+			handler.OnAlarm()
+			Expect(handler.GetAlarmTimeout()).To(Equal(sentTime.Add(2 * pto)))
+			handler.OnAlarm()
+			Expect(handler.ptoCount).To(BeEquivalentTo(2))
+			Expect(handler.GetAlarmTimeout()).To(Equal(sentTime.Add(4 * pto)))
+			err := handler.SentPacket(retransmittablePacket(4))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(handler.GetAlarmTimeout()).To(Equal(handler.lastSentRetransmittableTime.Add(4 * pto)))
+		})
 
+		It("resets the PTO count when an ACK is received", func() {
+			for i := protocol.PacketNumber(1); i <= 3; i++ {
+				err := handler.SentPacket(retransmittablePacket(i))
+				Expect(err).NotTo(HaveOccurred())
+			}
 			handler.OnAlarm()
-			p := handler.DequeuePacketForRetransmission()
-			Expect(p).ToNot(BeNil())
-			Expect(p.PacketNumber).To(Equal(protocol.PacketNumber(1)))
-			p = handler.DequeuePacketForRetransmission()
-			Expect(p).ToNot(BeNil())
-			Expect(p.PacketNumber).To(Equal(protocol.PacketNumber(2)))
+			Expect(handler.ptoCount).To(BeEquivalentTo(1))
+			err := handler.ReceivedAck(&wire.AckFrame{LargestAcked: 3, LowestAcked: 3}, 1, protocol.EncryptionForwardSecure, time.Now())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(handler.ptoCount).To(BeZero())
+		})
+	})
+
+	Context("persistent congestion", func() {
+		var cong *mocks.MockSendAlgorithm
+
+		BeforeEach(func() {
+			cong = mocks.NewMockSendAlgorithm(mockCtrl)
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			cong.EXPECT().PacingRate(gomock.Any()).AnyTimes()
+			cong.EXPECT().MaybeExitSlowStart().AnyTimes()
+			cong.EXPECT().OnBandwidthSample(gomock.Any()).AnyTimes()
+			cong.EXPECT().OnPacketAcked(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			cong.EXPECT().OnPacketLost(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			handler.congestion = cong
+			// take an RTT sample, long before the packets used in the tests are sent
+			handler.SentPacket(retransmittablePacket(1))
+			getPacket(1).sendTime = time.Now().Add(-time.Minute)
+			err := handler.ReceivedAck(&wire.AckFrame{LargestAcked: 1, LowestAcked: 1}, 1, protocol.EncryptionForwardSecure, time.Now().Add(-time.Minute+100*time.Millisecond))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		// sendPackets sends packets, and sets their send times interval apart, ending in one second
+		sendPackets := func(first, last protocol.PacketNumber, interval time.Duration) {
+			for pn := first; pn <= last; pn++ {
+				handler.SentPacket(retransmittablePacket(pn))
+			}
+			now := time.Now().Add(time.Second)
+			for pn := first; pn <= last; pn++ {
+				getPacket(pn).sendTime = now.Add(-time.Duration(last-pn) * interval)
+			}
+		}
+
+		It("detects persistent congestion", func() {
+			period := persistentCongestionThreshold * handler.computePTOTimeout()
+			sendPackets(2, 10, period/4)
+			cong.EXPECT().OnPersistentCongestion()
+			err := handler.ReceivedAck(&wire.AckFrame{LargestAcked: 10, LowestAcked: 10}, 2, protocol.EncryptionForwardSecure, time.Now().Add(time.Second))
+			Expect(err).ToNot(HaveOccurred())
+		})
 
-			Expect(handler.rtoCount).To(BeEquivalentTo(1))
+		It("doesn't detect persistent congestion if the lost packets were sent in a short period", func() {
+			period := persistentCongestionThreshold * handler.computePTOTimeout()
+			sendPackets(2, 10, period/10)
+			err := handler.ReceivedAck(&wire.AckFrame{LargestAcked: 10, LowestAcked: 10}, 2, protocol.EncryptionForwardSecure, time.Now().Add(time.Second))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("doesn't detect persistent congestion if a packet sent in between was acknowledged", func() {
+			period := persistentCongestionThreshold * handler.computePTOTimeout()
+			sendPackets(2, 10, period/4)
+			ack := &wire.AckFrame{
+				LargestAcked: 10,
+				LowestAcked:  6,
+				AckRanges: []wire.AckRange{
+					{First: 10, Last: 10},
+					{First: 6, Last: 6},
+				},
+			}
+			err := handler.ReceivedAck(ack, 2, protocol.EncryptionForwardSecure, time.Now().Add(time.Second))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("doesn't detect persistent congestion if a later packet was acknowledged before", func() {
+			period := persistentCongestionThreshold * handler.computePTOTimeout()
+			sendPackets(2, 10, period/3)
+			// packet 5 is sent right before packet 6, so it is not declared lost when packet 6 is acknowledged
+			getPacket(5).sendTime = getPacket(6).sendTime.Add(-10 * time.Millisecond)
+			err := handler.ReceivedAck(&wire.AckFrame{LargestAcked: 6, LowestAcked: 6}, 2, protocol.EncryptionForwardSecure, getPacket(6).sendTime.Add(100*time.Millisecond))
+			Expect(err).ToNot(HaveOccurred())
+			expectInPacketHistory([]protocol.PacketNumber{5, 7, 8, 9, 10})
+			// packets 5, 7, 8 and 9 are lost
+			err = handler.ReceivedAck(&wire.AckFrame{LargestAcked: 10, LowestAcked: 10}, 3, protocol.EncryptionForwardSecure, time.Now().Add(time.Second))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("doesn't detect persistent congestion for packets sent before the first RTT sample", func() {
+			period := persistentCongestionThreshold * handler.computePTOTimeout()
+			sendPackets(2, 10, period/4)
+			handler.firstRTTSampleTime = time.Now().Add(time.Second)
+			err := handler.ReceivedAck(&wire.AckFrame{LargestAcked: 10, LowestAcked: 10}, 2, protocol.EncryptionForwardSecure, time.Now().Add(time.Second))
+			Expect(err).ToNot(HaveOccurred())
 		})
 	})
 })
@@ -0,0 +1,143 @@
+package ackhandler
+
+import (
+	"fmt"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+)
+
+// The sentPacketHistory holds the packets that were sent and not yet acknowledged or declared lost.
+// It is a ring buffer indexed by packet number: the packet with number p is stored in the slot at
+// distance p - firstPacketNumber from the head.
+// Slots of packets that were skipped, not retransmittable or already removed are left empty.
+type sentPacketHistory struct {
+	slots []sentPacketHistorySlot // the length is always a power of 2
+
+	head              int // index of the slot of firstPacketNumber
+	numSlots          int // number of slots in use, from firstPacketNumber to the packet sent last
+	firstPacketNumber protocol.PacketNumber
+	numOutstanding    int
+}
+
+type sentPacketHistorySlot struct {
+	packet      Packet
+	outstanding bool
+}
+
+const sentPacketHistoryInitialSize = 32
+
+func newSentPacketHistory() *sentPacketHistory {
+	return &sentPacketHistory{
+		slots: make([]sentPacketHistorySlot, sentPacketHistoryInitialSize),
+	}
+}
+
+// SentPacket adds a packet to the history.
+// Packets must be added in increasing order of their packet numbers.
+func (h *sentPacketHistory) SentPacket(p *Packet) {
+	if h.numOutstanding == 0 {
+		h.head = 0
+		h.numSlots = 0
+		h.firstPacketNumber = p.PacketNumber
+	}
+	n := int(p.PacketNumber-h.firstPacketNumber) + 1
+	if n > len(h.slots) {
+		h.grow(n)
+	}
+	h.numSlots = n
+	slot := &h.slots[h.index(p.PacketNumber)]
+	slot.packet = *p
+	slot.outstanding = true
+	h.numOutstanding++
+}
+
+// grow increases the capacity of the ring buffer such that it holds at least n slots
+func (h *sentPacketHistory) grow(n int) {
+	size := 2 * len(h.slots)
+	for size < n {
+		size *= 2
+	}
+	slots := make([]sentPacketHistorySlot, size)
+	for i := 0; i < h.numSlots; i++ {
+		slots[i] = h.slots[(h.head+i)&(len(h.slots)-1)]
+	}
+	h.slots = slots
+	h.head = 0
+}
+
+func (h *sentPacketHistory) index(p protocol.PacketNumber) int {
+	return (h.head + int(p-h.firstPacketNumber)) & (len(h.slots) - 1)
+}
+
+// GetPacket returns the packet with packet number p, or nil if it is not outstanding.
+// The returned packet is only valid until the next call to Remove or SentPacket.
+func (h *sentPacketHistory) GetPacket(p protocol.PacketNumber) *Packet {
+	if p < h.firstPacketNumber || int(p-h.firstPacketNumber) >= h.numSlots {
+		return nil
+	}
+	slot := &h.slots[h.index(p)]
+	if !slot.outstanding {
+		return nil
+	}
+	return &slot.packet
+}
+
+// Iterate iterates through all outstanding packets, in increasing order of their packet numbers.
+// The callback must not modify the history.
+func (h *sentPacketHistory) Iterate(cb func(*Packet) (cont bool)) {
+	h.IterateRange(h.firstPacketNumber, h.firstPacketNumber+protocol.PacketNumber(h.numSlots-1), cb)
+}
+
+// IterateRange iterates through the outstanding packets with packet numbers from first to last (including both).
+// The callback must not modify the history.
+func (h *sentPacketHistory) IterateRange(first, last protocol.PacketNumber, cb func(*Packet) (cont bool)) {
+	if h.numSlots == 0 {
+		return
+	}
+	if first < h.firstPacketNumber {
+		first = h.firstPacketNumber
+	}
+	if highest := h.firstPacketNumber + protocol.PacketNumber(h.numSlots-1); last > highest {
+		last = highest
+	}
+	for p := first; p <= last; p++ {
+		slot := &h.slots[h.index(p)]
+		if !slot.outstanding {
+			continue
+		}
+		if !cb(&slot.packet) {
+			return
+		}
+	}
+}
+
+// FirstOutstanding returns the outstanding packet with the lowest packet number, or nil if there are none.
+func (h *sentPacketHistory) FirstOutstanding() *Packet {
+	if h.numOutstanding == 0 {
+		return nil
+	}
+	// The slot at the head is always occupied, see Remove.
+	return &h.slots[h.head].packet
+}
+
+// Len returns the number of outstanding packets
+func (h *sentPacketHistory) Len() int {
+	return h.numOutstanding
+}
+
+// Remove removes the packet with packet number p.
+// Packets returned by GetPacket, Iterate and FirstOutstanding for other packet numbers remain valid.
+func (h *sentPacketHistory) Remove(p protocol.PacketNumber) error {
+	if h.GetPacket(p) == nil {
+		return fmt.Errorf("packet %d not found in sent packet history", p)
+	}
+	h.slots[h.index(p)] = sentPacketHistorySlot{}
+	h.numOutstanding--
+	// advance the head to the next outstanding packet
+	for h.numSlots > 0 && !h.slots[h.head].outstanding {
+		h.head = (h.head + 1) & (len(h.slots) - 1)
+		h.numSlots--
+		h.firstPacketNumber++
+	}
+	return nil
+}
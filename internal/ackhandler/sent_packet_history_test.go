@@ -0,0 +1,142 @@
+package ackhandler
+
+import (
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SentPacketHistory", func() {
+	var hist *sentPacketHistory
+
+	BeforeEach(func() {
+		hist = newSentPacketHistory()
+	})
+
+	expectInHistory := func(packetNumbers []protocol.PacketNumber) {
+		var pns []protocol.PacketNumber
+		hist.Iterate(func(p *Packet) bool {
+			pns = append(pns, p.PacketNumber)
+			return true
+		})
+		ExpectWithOffset(1, pns).To(Equal(packetNumbers))
+		ExpectWithOffset(1, hist.Len()).To(Equal(len(packetNumbers)))
+		if len(packetNumbers) > 0 {
+			ExpectWithOffset(1, hist.FirstOutstanding().PacketNumber).To(Equal(packetNumbers[0]))
+		} else {
+			ExpectWithOffset(1, hist.FirstOutstanding()).To(BeNil())
+		}
+	}
+
+	It("saves sent packets", func() {
+		hist.SentPacket(&Packet{PacketNumber: 1})
+		hist.SentPacket(&Packet{PacketNumber: 3})
+		hist.SentPacket(&Packet{PacketNumber: 4})
+		expectInHistory([]protocol.PacketNumber{1, 3, 4})
+	})
+
+	It("gets packets by packet number", func() {
+		hist.SentPacket(&Packet{PacketNumber: 10, Length: 1})
+		hist.SentPacket(&Packet{PacketNumber: 12, Length: 2})
+		Expect(hist.GetPacket(10).Length).To(Equal(protocol.ByteCount(1)))
+		Expect(hist.GetPacket(12).Length).To(Equal(protocol.ByteCount(2)))
+		Expect(hist.GetPacket(9)).To(BeNil())
+		Expect(hist.GetPacket(11)).To(BeNil())
+		Expect(hist.GetPacket(13)).To(BeNil())
+	})
+
+	It("removes packets", func() {
+		for i := protocol.PacketNumber(1); i <= 5; i++ {
+			hist.SentPacket(&Packet{PacketNumber: i})
+		}
+		Expect(hist.Remove(3)).To(Succeed())
+		expectInHistory([]protocol.PacketNumber{1, 2, 4, 5})
+		Expect(hist.Remove(1)).To(Succeed())
+		expectInHistory([]protocol.PacketNumber{2, 4, 5})
+		Expect(hist.Remove(2)).To(Succeed())
+		expectInHistory([]protocol.PacketNumber{4, 5})
+		Expect(hist.GetPacket(3)).To(BeNil())
+		Expect(hist.Remove(5)).To(Succeed())
+		Expect(hist.Remove(4)).To(Succeed())
+		expectInHistory(nil)
+	})
+
+	It("errors when removing a packet that is not outstanding", func() {
+		hist.SentPacket(&Packet{PacketNumber: 1})
+		hist.SentPacket(&Packet{PacketNumber: 3})
+		Expect(hist.Remove(2)).To(MatchError("packet 2 not found in sent packet history"))
+		Expect(hist.Remove(4)).To(MatchError("packet 4 not found in sent packet history"))
+		Expect(hist.Remove(1)).To(Succeed())
+		Expect(hist.Remove(1)).To(MatchError("packet 1 not found in sent packet history"))
+	})
+
+	It("releases the frames of removed packets", func() {
+		hist.SentPacket(&Packet{PacketNumber: 1, Frames: retransmittablePacket(1).Frames})
+		hist.SentPacket(&Packet{PacketNumber: 2})
+		Expect(hist.Remove(1)).To(Succeed())
+		for _, slot := range hist.slots {
+			Expect(slot.packet.Frames).To(BeNil())
+		}
+	})
+
+	It("starts over when all packets were removed", func() {
+		hist.SentPacket(&Packet{PacketNumber: 1})
+		Expect(hist.Remove(1)).To(Succeed())
+		hist.SentPacket(&Packet{PacketNumber: 1000})
+		expectInHistory([]protocol.PacketNumber{1000})
+		Expect(hist.slots).To(HaveLen(sentPacketHistoryInitialSize))
+	})
+
+	It("grows, when the ring buffer wraps around", func() {
+		for i := protocol.PacketNumber(0); i < sentPacketHistoryInitialSize; i++ {
+			hist.SentPacket(&Packet{PacketNumber: i, Length: protocol.ByteCount(i)})
+		}
+		for i := protocol.PacketNumber(0); i < sentPacketHistoryInitialSize/2; i++ {
+			Expect(hist.Remove(i)).To(Succeed())
+		}
+		// the second half of these packets is stored at the beginning of the ring buffer
+		for i := protocol.PacketNumber(sentPacketHistoryInitialSize); i < 3*sentPacketHistoryInitialSize; i++ {
+			hist.SentPacket(&Packet{PacketNumber: i, Length: protocol.ByteCount(i)})
+		}
+		Expect(len(hist.slots)).To(BeNumerically(">", sentPacketHistoryInitialSize))
+		var expected []protocol.PacketNumber
+		for i := protocol.PacketNumber(sentPacketHistoryInitialSize / 2); i < 3*sentPacketHistoryInitialSize; i++ {
+			expected = append(expected, i)
+			Expect(hist.GetPacket(i).Length).To(Equal(protocol.ByteCount(i)))
+		}
+		expectInHistory(expected)
+	})
+
+	Context("iterating", func() {
+		BeforeEach(func() {
+			for _, pn := range []protocol.PacketNumber{2, 3, 5, 6, 8} {
+				hist.SentPacket(&Packet{PacketNumber: pn})
+			}
+		})
+
+		It("stops iterating", func() {
+			var pns []protocol.PacketNumber
+			hist.Iterate(func(p *Packet) bool {
+				pns = append(pns, p.PacketNumber)
+				return p.PacketNumber < 5
+			})
+			Expect(pns).To(Equal([]protocol.PacketNumber{2, 3, 5}))
+		})
+
+		It("iterates over a range of packet numbers", func() {
+			getRange := func(first, last protocol.PacketNumber) []protocol.PacketNumber {
+				var pns []protocol.PacketNumber
+				hist.IterateRange(first, last, func(p *Packet) bool {
+					pns = append(pns, p.PacketNumber)
+					return true
+				})
+				return pns
+			}
+			Expect(getRange(3, 6)).To(Equal([]protocol.PacketNumber{3, 5, 6}))
+			Expect(getRange(0, 2)).To(Equal([]protocol.PacketNumber{2}))
+			Expect(getRange(7, 100)).To(Equal([]protocol.PacketNumber{8}))
+			Expect(getRange(9, 100)).To(BeEmpty())
+			Expect(getRange(4, 4)).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,45 @@
+package congestion
+
+import "github.com/lucas-clemente/quic-go/internal/protocol"
+
+// bandwidthEstimatorWindow is the number of round trips that the BandwidthEstimator remembers samples for
+const bandwidthEstimatorWindow = 10
+
+// The BandwidthEstimator calculates a filtered bandwidth estimate from bandwidth samples.
+// The estimate is the maximum delivery rate sampled within the last bandwidthEstimatorWindow round trips.
+// Samples taken while the sender was application-limited are only used if they increase the estimate,
+// since they only show a lower bound of the available bandwidth.
+type BandwidthEstimator struct {
+	maxBandwidth *maxBandwidthFilter
+
+	// round trips are counted using the number of bytes delivered:
+	// a round trip ends when a packet that was sent after the start of the round is acknowledged
+	roundTripCount     uint64
+	nextRoundDelivered protocol.ByteCount
+}
+
+// NewBandwidthEstimator creates a new BandwidthEstimator
+func NewBandwidthEstimator() *BandwidthEstimator {
+	return &BandwidthEstimator{maxBandwidth: newMaxBandwidthFilter(bandwidthEstimatorWindow)}
+}
+
+// OnBandwidthSample adds a sample
+func (e *BandwidthEstimator) OnBandwidthSample(sample BandwidthSample) {
+	if sample.PriorDelivered >= e.nextRoundDelivered {
+		e.roundTripCount++
+		e.nextRoundDelivered = sample.PriorDelivered + sample.Delivered
+	}
+	if sample.Bandwidth == 0 {
+		return
+	}
+	if sample.IsAppLimited && sample.Bandwidth < e.maxBandwidth.GetBest() {
+		return
+	}
+	e.maxBandwidth.Update(sample.Bandwidth, e.roundTripCount)
+}
+
+// BandwidthEstimate returns the bandwidth estimate.
+// It returns 0 if no sample was taken yet.
+func (e *BandwidthEstimator) BandwidthEstimate() Bandwidth {
+	return e.maxBandwidth.GetBest()
+}
@@ -0,0 +1,87 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Bandwidth estimator", func() {
+	var (
+		estimator *BandwidthEstimator
+		delivered protocol.ByteCount
+	)
+
+	BeforeEach(func() {
+		estimator = NewBandwidthEstimator()
+		delivered = 0
+	})
+
+	// addRound adds one sample per round trip
+	addRound := func(bw Bandwidth, appLimited bool) {
+		estimator.OnBandwidthSample(BandwidthSample{
+			Bandwidth:      bw,
+			Delivered:      10 * protocol.DefaultTCPMSS,
+			Interval:       100 * time.Millisecond,
+			PriorDelivered: delivered,
+			IsAppLimited:   appLimited,
+		})
+		delivered += 10 * protocol.DefaultTCPMSS
+	}
+
+	It("is zero before the first sample", func() {
+		Expect(estimator.BandwidthEstimate()).To(BeZero())
+	})
+
+	It("ignores samples without a bandwidth", func() {
+		addRound(0, false)
+		Expect(estimator.BandwidthEstimate()).To(BeZero())
+	})
+
+	It("returns the maximum sample", func() {
+		addRound(100*BytesPerSecond, false)
+		addRound(300*BytesPerSecond, false)
+		addRound(200*BytesPerSecond, false)
+		Expect(estimator.BandwidthEstimate()).To(Equal(300 * BytesPerSecond))
+	})
+
+	It("forgets samples after 10 round trips", func() {
+		addRound(300*BytesPerSecond, false)
+		for i := 0; i < bandwidthEstimatorWindow; i++ {
+			addRound(100*BytesPerSecond, false)
+			Expect(estimator.BandwidthEstimate()).To(Equal(300 * BytesPerSecond))
+		}
+		addRound(100*BytesPerSecond, false)
+		Expect(estimator.BandwidthEstimate()).To(Equal(100 * BytesPerSecond))
+	})
+
+	It("counts round trips by the bytes delivered", func() {
+		addRound(300*BytesPerSecond, false)
+		// many samples within a single round trip
+		for i := 0; i < 5*bandwidthEstimatorWindow; i++ {
+			estimator.OnBandwidthSample(BandwidthSample{
+				Bandwidth:      100 * BytesPerSecond,
+				Delivered:      protocol.DefaultTCPMSS,
+				PriorDelivered: delivered,
+			})
+		}
+		Expect(estimator.BandwidthEstimate()).To(Equal(300 * BytesPerSecond))
+	})
+
+	It("ignores application-limited samples that would decrease the estimate", func() {
+		addRound(300*BytesPerSecond, false)
+		for i := 0; i < 2*bandwidthEstimatorWindow; i++ {
+			addRound(100*BytesPerSecond, true)
+		}
+		Expect(estimator.BandwidthEstimate()).To(Equal(300 * BytesPerSecond))
+	})
+
+	It("uses application-limited samples that increase the estimate", func() {
+		addRound(100*BytesPerSecond, false)
+		addRound(300*BytesPerSecond, true)
+		Expect(estimator.BandwidthEstimate()).To(Equal(300 * BytesPerSecond))
+	})
+})
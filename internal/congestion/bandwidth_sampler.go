@@ -0,0 +1,107 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+)
+
+// A DeliveryState is the state of the BandwidthSampler at the time a packet was sent.
+// It is saved with every sent packet, and used to calculate a BandwidthSample when the packet is acknowledged.
+type DeliveryState struct {
+	// Delivered is the number of bytes that were acknowledged when the packet was sent.
+	Delivered protocol.ByteCount
+	// DeliveredTime is the time when the last of these bytes was acknowledged.
+	DeliveredTime time.Time
+	// FirstSentTime is the send time of the packet that was acknowledged last when the packet was sent.
+	FirstSentTime time.Time
+	// SentTime is the time the packet was sent.
+	SentTime time.Time
+	// IsAppLimited is set if the packet was sent while the sender was application-limited.
+	IsAppLimited bool
+}
+
+// A BandwidthSample is a sample of the delivery rate, taken when a packet is acknowledged.
+type BandwidthSample struct {
+	// Bandwidth is the delivery rate in the interval between sending and acknowledging the packet.
+	// It is 0 if no valid sample could be calculated.
+	Bandwidth Bandwidth
+	// Delivered is the number of bytes that were acknowledged during that interval.
+	Delivered protocol.ByteCount
+	// Interval is the length of the sampling interval.
+	Interval time.Duration
+	// PriorDelivered is the number of bytes that were acknowledged when the packet was sent.
+	PriorDelivered protocol.ByteCount
+	// IsAppLimited is set if the packet was sent while the sender was application-limited.
+	// The sample then only shows a lower bound of the available bandwidth.
+	IsAppLimited bool
+}
+
+// The BandwidthSampler calculates the delivery rate of a connection.
+// It implements the algorithm described in draft-cheng-iccrg-delivery-rate-estimation.
+// For every packet, the delivery rate is measured as the number of bytes acknowledged between sending and acknowledging that packet,
+// divided by the time that passed.
+// Since ACKs can be compressed or aggregated, the sampling interval is the longer of the send interval and the ACK interval.
+type BandwidthSampler struct {
+	delivered     protocol.ByteCount
+	deliveredTime time.Time
+	firstSentTime time.Time
+	// appLimitedUntil is the value of delivered at which the application-limited phase ends.
+	// It is 0 if the sender is not application-limited.
+	appLimitedUntil protocol.ByteCount
+}
+
+// OnAppLimited is called when the sender is application-limited,
+// i.e. when it could send more packets, but ran out of data to send.
+// All packets sent until the bytes currently in flight are acknowledged are marked as application-limited.
+func (s *BandwidthSampler) OnAppLimited(bytesInFlight protocol.ByteCount) {
+	s.appLimitedUntil = utils.MaxByteCount(s.delivered+bytesInFlight, 1)
+}
+
+// OnPacketSent is called when a retransmittable packet is sent.
+// bytesInFlight are the bytes in flight before sending the packet.
+// The returned DeliveryState must be passed to OnPacketAcked when the packet is acknowledged.
+func (s *BandwidthSampler) OnPacketSent(sentTime time.Time, bytesInFlight protocol.ByteCount) DeliveryState {
+	// If no packets are in flight, a new sampling interval starts now.
+	// Otherwise the time the connection was idle would be counted as part of the interval.
+	if bytesInFlight == 0 {
+		s.firstSentTime = sentTime
+		s.deliveredTime = sentTime
+	}
+	return DeliveryState{
+		Delivered:     s.delivered,
+		DeliveredTime: s.deliveredTime,
+		FirstSentTime: s.firstSentTime,
+		SentTime:      sentTime,
+		IsAppLimited:  s.appLimitedUntil != 0,
+	}
+}
+
+// OnPacketAcked is called when a packet is acknowledged.
+// state is the DeliveryState that was returned by OnPacketSent for this packet.
+func (s *BandwidthSampler) OnPacketAcked(ackTime time.Time, bytes protocol.ByteCount, state DeliveryState) BandwidthSample {
+	s.delivered += bytes
+	s.deliveredTime = ackTime
+	if state.SentTime.After(s.firstSentTime) {
+		s.firstSentTime = state.SentTime
+	}
+	if s.appLimitedUntil != 0 && s.delivered >= s.appLimitedUntil {
+		s.appLimitedUntil = 0
+	}
+
+	sendInterval := state.SentTime.Sub(state.FirstSentTime)
+	ackInterval := ackTime.Sub(state.DeliveredTime)
+	interval := utils.MaxDuration(sendInterval, ackInterval)
+	delivered := s.delivered - state.Delivered
+	sample := BandwidthSample{
+		Delivered:      delivered,
+		Interval:       interval,
+		PriorDelivered: state.Delivered,
+		IsAppLimited:   state.IsAppLimited,
+	}
+	if interval > 0 {
+		sample.Bandwidth = BandwidthFromDelta(delivered, interval)
+	}
+	return sample
+}
@@ -0,0 +1,143 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Bandwidth sampler", func() {
+	var (
+		sampler       BandwidthSampler
+		now           time.Time
+		bytesInFlight protocol.ByteCount
+		states        map[protocol.PacketNumber]DeliveryState
+	)
+
+	BeforeEach(func() {
+		sampler = BandwidthSampler{}
+		now = time.Now()
+		bytesInFlight = 0
+		states = make(map[protocol.PacketNumber]DeliveryState)
+	})
+
+	sendPacket := func(pn protocol.PacketNumber) {
+		states[pn] = sampler.OnPacketSent(now, bytesInFlight)
+		bytesInFlight += protocol.DefaultTCPMSS
+	}
+
+	ackPacket := func(pn protocol.PacketNumber) BandwidthSample {
+		bytesInFlight -= protocol.DefaultTCPMSS
+		return sampler.OnPacketAcked(now, protocol.DefaultTCPMSS, states[pn])
+	}
+
+	It("samples the bandwidth of a single packet", func() {
+		sendPacket(1)
+		now = now.Add(100 * time.Millisecond)
+		sample := ackPacket(1)
+		Expect(sample.Delivered).To(Equal(protocol.DefaultTCPMSS))
+		Expect(sample.Interval).To(Equal(100 * time.Millisecond))
+		Expect(sample.Bandwidth).To(Equal(BandwidthFromDelta(protocol.DefaultTCPMSS, 100*time.Millisecond)))
+	})
+
+	It("samples the bandwidth of paced packets", func() {
+		// send one packet every 10ms, with an RTT of 100ms
+		var sample BandwidthSample
+		for i := 1; i <= 30; i++ {
+			if i > 10 {
+				sample = ackPacket(protocol.PacketNumber(i - 10))
+			}
+			sendPacket(protocol.PacketNumber(i))
+			now = now.Add(10 * time.Millisecond)
+		}
+		Expect(sample.Delivered).To(Equal(10 * protocol.DefaultTCPMSS))
+		Expect(sample.Interval).To(Equal(100 * time.Millisecond))
+		Expect(sample.Bandwidth).To(Equal(BandwidthFromDelta(protocol.DefaultTCPMSS, 10*time.Millisecond)))
+	})
+
+	It("uses the send interval, if it is longer than the ACK interval", func() {
+		// this happens if ACKs are compressed
+		state := DeliveryState{
+			DeliveredTime: now.Add(40 * time.Millisecond),
+			FirstSentTime: now,
+			SentTime:      now.Add(50 * time.Millisecond),
+		}
+		sample := sampler.OnPacketAcked(now.Add(70*time.Millisecond), 1000, state)
+		Expect(sample.Interval).To(Equal(50 * time.Millisecond))
+	})
+
+	It("uses the ACK interval, if it is longer than the send interval", func() {
+		// this happens if packets are sent in bursts
+		state := DeliveryState{
+			DeliveredTime: now,
+			FirstSentTime: now,
+			SentTime:      now.Add(time.Millisecond),
+		}
+		sample := sampler.OnPacketAcked(now.Add(30*time.Millisecond), 1000, state)
+		Expect(sample.Interval).To(Equal(30 * time.Millisecond))
+	})
+
+	It("doesn't count idle periods", func() {
+		sendPacket(1)
+		now = now.Add(100 * time.Millisecond)
+		ackPacket(1)
+		now = now.Add(10 * time.Second)
+		sendPacket(2)
+		now = now.Add(100 * time.Millisecond)
+		sample := ackPacket(2)
+		Expect(sample.Delivered).To(Equal(protocol.DefaultTCPMSS))
+		Expect(sample.Interval).To(Equal(100 * time.Millisecond))
+	})
+
+	It("doesn't return a bandwidth for a zero interval", func() {
+		sendPacket(1)
+		sample := ackPacket(1)
+		Expect(sample.Bandwidth).To(BeZero())
+	})
+
+	It("reports the bytes delivered before sending the packet", func() {
+		sendPacket(1)
+		sendPacket(2)
+		now = now.Add(100 * time.Millisecond)
+		Expect(ackPacket(1).PriorDelivered).To(BeZero())
+		Expect(ackPacket(2).PriorDelivered).To(BeZero())
+		sendPacket(3)
+		now = now.Add(100 * time.Millisecond)
+		Expect(ackPacket(3).PriorDelivered).To(Equal(2 * protocol.DefaultTCPMSS))
+	})
+
+	Context("application-limited", func() {
+		It("marks samples as application-limited", func() {
+			sendPacket(1)
+			sampler.OnAppLimited(bytesInFlight)
+			sendPacket(2)
+			now = now.Add(100 * time.Millisecond)
+			Expect(ackPacket(1).IsAppLimited).To(BeFalse())
+			Expect(ackPacket(2).IsAppLimited).To(BeTrue())
+		})
+
+		It("marks samples as application-limited if nothing is in flight", func() {
+			sampler.OnAppLimited(0)
+			sendPacket(1)
+			now = now.Add(100 * time.Millisecond)
+			Expect(ackPacket(1).IsAppLimited).To(BeTrue())
+		})
+
+		It("ends the application-limited phase when the bytes in flight are acknowledged", func() {
+			sendPacket(1)
+			sendPacket(2)
+			sampler.OnAppLimited(bytesInFlight)
+			now = now.Add(100 * time.Millisecond)
+			ackPacket(1)
+			sendPacket(3)
+			ackPacket(2)
+			sendPacket(4)
+			now = now.Add(100 * time.Millisecond)
+			Expect(ackPacket(3).IsAppLimited).To(BeTrue())
+			Expect(ackPacket(4).IsAppLimited).To(BeFalse())
+		})
+	})
+})
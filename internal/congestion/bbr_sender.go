@@ -0,0 +1,443 @@
+package congestion
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+)
+
+// BBR (Bottleneck Bandwidth and Round-trip propagation time) models the network path
+// using the maximum delivery rate and the minimum RTT observed by the connection.
+// It paces packets at (a multiple of) the estimated bottleneck bandwidth,
+// and limits the bytes in flight to (a multiple of) the bandwidth-delay product.
+// Unlike loss-based congestion controllers, it doesn't reduce its sending rate in response to random packet loss.
+// This implementation follows draft-cardwell-iccrg-bbr-congestion-control and Chromium's BbrSender.
+
+type bbrMode uint8
+
+const (
+	// bbrStartup is the initial mode: it ramps up the sending rate exponentially to find the bottleneck bandwidth
+	bbrStartup bbrMode = iota
+	// bbrDrain drains the queue that was created during startup
+	bbrDrain
+	// bbrProbeBW is the steady state: it cycles the pacing gain to probe for more bandwidth
+	bbrProbeBW
+	// bbrProbeRTT reduces the bytes in flight to the minimum to measure the round-trip propagation time
+	bbrProbeRTT
+)
+
+func (m bbrMode) String() string {
+	switch m {
+	case bbrStartup:
+		return "STARTUP"
+	case bbrDrain:
+		return "DRAIN"
+	case bbrProbeBW:
+		return "PROBE_BW"
+	case bbrProbeRTT:
+		return "PROBE_RTT"
+	default:
+		return "unknown BBR mode"
+	}
+}
+
+type bbrRecoveryState uint8
+
+const (
+	bbrNotInRecovery bbrRecoveryState = iota
+	// bbrConservation allows an additional packet to be sent for every packet acknowledged in the first round of recovery
+	bbrConservation
+	// bbrGrowth allows the recovery window to grow in slow start fashion after the first round of recovery
+	bbrGrowth
+)
+
+const (
+	// bbrHighGain is the gain used in STARTUP. It is the smallest gain that allows the sending rate to double every round trip.
+	// 2.885 = 2/ln(2)
+	bbrHighGain = 2.885
+	// bbrDrainGain is the gain used in DRAIN, draining the queue created during STARTUP in a single round trip.
+	bbrDrainGain = 1 / bbrHighGain
+	// bbrCongestionWindowGain is the gain applied to the bandwidth-delay product in PROBE_BW.
+	// It allows for ACKs being delayed or aggregated.
+	bbrCongestionWindowGain = 2.0
+	// bbrGainCycleLength is the number of phases of the pacing gain cycle in PROBE_BW.
+	bbrGainCycleLength = 8
+	// bbrBandwidthWindowSize is the number of round trips that the maximum bandwidth filter remembers samples for.
+	bbrBandwidthWindowSize = bbrGainCycleLength + 2
+	// bbrMinRTTExpiry is the time after which the minimum RTT is considered stale, and PROBE_RTT is entered.
+	bbrMinRTTExpiry = 10 * time.Second
+	// bbrProbeRTTTime is the time that is spent in PROBE_RTT.
+	bbrProbeRTTTime = 200 * time.Millisecond
+	// STARTUP is left if the bandwidth estimate didn't grow by bbrStartupGrowthTarget for bbrRoundTripsWithoutGrowthBeforeExitingStartup rounds.
+	bbrStartupGrowthTarget                         = 1.25
+	bbrRoundTripsWithoutGrowthBeforeExitingStartup = 3
+	// bbrMinCongestionWindow is the minimum congestion window, and the congestion window used in PROBE_RTT.
+	bbrMinCongestionWindow = 4 * protocol.DefaultTCPMSS
+)
+
+// bbrPacingGainCycle are the pacing gains used in PROBE_BW.
+// Every phase lasts one (minimum) RTT. The sender first probes for more bandwidth,
+// then drains the queue that might have been created, and then cruises at the estimated bandwidth.
+var bbrPacingGainCycle = [bbrGainCycleLength]float64{1.25, 0.75, 1, 1, 1, 1, 1, 1}
+
+type bbrSender struct {
+	clock    Clock
+	rttStats *RTTStats
+
+	mode bbrMode
+
+	maxBandwidth *maxBandwidthFilter
+	// the sample that was passed to OnBandwidthSample, to be processed in OnPacketAcked
+	sample    BandwidthSample
+	hasSample bool
+	// isAppLimited is set if the last sample was taken while the sender was application-limited
+	isAppLimited bool
+
+	minRTT          time.Duration
+	minRTTTimestamp time.Time
+
+	// round trips are counted using packet numbers: a round trip ends when a packet sent after the start of the round is acknowledged
+	roundTripCount          uint64
+	currentRoundTripEnd     protocol.PacketNumber
+	largestSentPacketNumber protocol.PacketNumber
+
+	congestionWindow        protocol.ByteCount
+	initialCongestionWindow protocol.ByteCount
+	maxCongestionWindow     protocol.ByteCount
+
+	pacingGain           float64
+	congestionWindowGain float64
+
+	// STARTUP
+	isAtFullBandwidth          bool
+	bandwidthAtLastRound       Bandwidth
+	roundsWithoutBandwidthGain int
+
+	// PROBE_BW
+	cycleCurrentOffset int
+	lastCycleStart     time.Time
+
+	// PROBE_RTT
+	// exitProbeRTTAt is set once the bytes in flight have been reduced to the minimum congestion window
+	exitProbeRTTAt      time.Time
+	probeRTTRoundPassed bool
+
+	recoveryState bbrRecoveryState
+	// recovery ends when a packet sent after the start of recovery is acknowledged
+	endRecoveryAt  protocol.PacketNumber
+	recoveryWindow protocol.ByteCount
+}
+
+var _ SendAlgorithm = &bbrSender{}
+
+// NewBBRSender makes a new BBR sender
+func NewBBRSender(clock Clock, rttStats *RTTStats, initialCongestionWindow, initialMaxCongestionWindow protocol.PacketNumber) SendAlgorithm {
+	b := &bbrSender{
+		clock:                   clock,
+		rttStats:                rttStats,
+		maxBandwidth:            newMaxBandwidthFilter(bbrBandwidthWindowSize),
+		congestionWindow:        protocol.ByteCount(initialCongestionWindow) * protocol.DefaultTCPMSS,
+		initialCongestionWindow: protocol.ByteCount(initialCongestionWindow) * protocol.DefaultTCPMSS,
+		maxCongestionWindow:     protocol.ByteCount(initialMaxCongestionWindow) * protocol.DefaultTCPMSS,
+	}
+	b.enterStartupMode()
+	return b
+}
+
+// PacingRate returns the pacing rate, which is the bandwidth estimate multiplied by the pacing gain
+func (b *bbrSender) PacingRate(protocol.ByteCount) Bandwidth {
+	return b.pacingRate()
+}
+
+// pacingRate is the rate that packets are sent at
+func (b *bbrSender) pacingRate() Bandwidth {
+	bandwidth := b.BandwidthEstimate()
+	if bandwidth == 0 {
+		// Before the first bandwidth sample, the initial congestion window is sent within one RTT.
+		rtt := b.rttStats.SmoothedRTT()
+		if rtt == 0 {
+			rtt = time.Duration(b.rttStats.InitialRTTus()) * time.Microsecond
+		}
+		return Bandwidth(bbrHighGain * float64(BandwidthFromDelta(b.initialCongestionWindow, rtt)))
+	}
+	return Bandwidth(b.pacingGain * float64(bandwidth))
+}
+
+func (b *bbrSender) OnPacketSent(sentTime time.Time, bytesInFlight protocol.ByteCount, packetNumber protocol.PacketNumber, bytes protocol.ByteCount, isRetransmittable bool) bool {
+	if !isRetransmittable {
+		return false
+	}
+	b.largestSentPacketNumber = packetNumber
+	return true
+}
+
+func (b *bbrSender) GetCongestionWindow() protocol.ByteCount {
+	if b.mode == bbrProbeRTT {
+		return bbrMinCongestionWindow
+	}
+	if b.InRecovery() {
+		return utils.MinByteCount(b.congestionWindow, b.recoveryWindow)
+	}
+	return b.congestionWindow
+}
+
+// MaybeExitSlowStart does nothing, since BBR decides itself when to leave STARTUP.
+func (b *bbrSender) MaybeExitSlowStart() {}
+
+// OnBandwidthSample saves the sample. It is processed in OnPacketAcked.
+func (b *bbrSender) OnBandwidthSample(sample BandwidthSample) {
+	b.sample = sample
+	b.hasSample = true
+}
+
+func (b *bbrSender) OnPacketAcked(packetNumber protocol.PacketNumber, ackedBytes protocol.ByteCount, bytesInFlight protocol.ByteCount) {
+	now := b.clock.Now()
+	isRoundStart := b.updateRoundTripCounter(packetNumber)
+	b.updateRecoveryState(packetNumber, isRoundStart)
+
+	var minRTTExpired bool
+	if b.hasSample {
+		b.hasSample = false
+		b.isAppLimited = b.sample.IsAppLimited
+		// Samples taken while application-limited only show a lower bound of the bandwidth.
+		if b.sample.Bandwidth > 0 && (!b.sample.IsAppLimited || b.sample.Bandwidth >= b.BandwidthEstimate()) {
+			b.maxBandwidth.Update(b.sample.Bandwidth, b.roundTripCount)
+		}
+		minRTTExpired = b.updateMinRTT(now)
+	}
+
+	if b.mode == bbrProbeBW {
+		b.updateGainCyclePhase(now, bytesInFlight)
+	}
+	if isRoundStart && !b.isAtFullBandwidth {
+		b.checkIfFullBandwidthReached()
+	}
+	b.maybeExitStartupOrDrain(now, bytesInFlight)
+	b.maybeEnterOrExitProbeRTT(now, bytesInFlight, isRoundStart, minRTTExpired)
+
+	b.calculateCongestionWindow(ackedBytes)
+	b.calculateRecoveryWindow(ackedBytes, bytesInFlight)
+}
+
+func (b *bbrSender) OnPacketLost(packetNumber protocol.PacketNumber, lostBytes protocol.ByteCount, bytesInFlight protocol.ByteCount) {
+	if b.InRecovery() {
+		b.recoveryWindow = utils.MaxByteCount(b.recoveryWindow-utils.MinByteCount(lostBytes, b.recoveryWindow), bbrMinCongestionWindow)
+		return
+	}
+	// A new loss event starts recovery.
+	// In the first round, only one packet is sent for every packet that is acknowledged (packet conservation).
+	b.recoveryState = bbrConservation
+	b.endRecoveryAt = b.largestSentPacketNumber
+	b.recoveryWindow = utils.MaxByteCount(bytesInFlight, bbrMinCongestionWindow)
+	// start a new round trip, such that conservation lasts for one round
+	b.currentRoundTripEnd = b.largestSentPacketNumber
+}
+
+// OnSpuriousLoss does nothing.
+// BBR doesn't reduce its congestion window on loss, and recovery ends after one round trip anyway.
+func (b *bbrSender) OnSpuriousLoss(protocol.PacketNumber) {}
+
+// OnCongestionExperienced does nothing. BBR doesn't react to ECN marks.
+func (b *bbrSender) OnCongestionExperienced(protocol.PacketNumber, protocol.ByteCount) {}
+
+// OnPersistentCongestion reduces the congestion window to the minimum.
+// The lost packets were already reported, so the sender is in recovery.
+func (b *bbrSender) OnPersistentCongestion() {
+	b.congestionWindow = bbrMinCongestionWindow
+}
+
+// BandwidthEstimate returns the estimated bottleneck bandwidth
+func (b *bbrSender) BandwidthEstimate() Bandwidth {
+	return b.maxBandwidth.GetBest()
+}
+
+// InRecovery says if the sender is in loss recovery
+func (b *bbrSender) InRecovery() bool {
+	return b.recoveryState != bbrNotInRecovery
+}
+
+func (b *bbrSender) updateRoundTripCounter(ackedPacketNumber protocol.PacketNumber) bool {
+	if ackedPacketNumber <= b.currentRoundTripEnd {
+		return false
+	}
+	b.roundTripCount++
+	b.currentRoundTripEnd = b.largestSentPacketNumber
+	return true
+}
+
+func (b *bbrSender) updateRecoveryState(ackedPacketNumber protocol.PacketNumber, isRoundStart bool) {
+	switch b.recoveryState {
+	case bbrConservation:
+		if isRoundStart {
+			b.recoveryState = bbrGrowth
+		}
+		fallthrough
+	case bbrGrowth:
+		if ackedPacketNumber > b.endRecoveryAt {
+			b.recoveryState = bbrNotInRecovery
+		}
+	}
+}
+
+// updateMinRTT updates the minimum RTT, using the latest RTT sample.
+// It returns if the minimum RTT expired, which means that PROBE_RTT should be entered.
+func (b *bbrSender) updateMinRTT(now time.Time) bool {
+	rtt := b.rttStats.LatestRTT()
+	if rtt == 0 {
+		return false
+	}
+	expired := b.minRTT != 0 && now.Sub(b.minRTTTimestamp) > bbrMinRTTExpiry
+	if expired || b.minRTT == 0 || rtt < b.minRTT {
+		b.minRTT = rtt
+		b.minRTTTimestamp = now
+	}
+	return expired
+}
+
+// getMinRTT returns the minimum RTT, or the initial RTT if no RTT sample was taken yet
+func (b *bbrSender) getMinRTT() time.Duration {
+	if b.minRTT == 0 {
+		return time.Duration(b.rttStats.InitialRTTus()) * time.Microsecond
+	}
+	return b.minRTT
+}
+
+// getTargetCongestionWindow returns the bandwidth-delay product, multiplied by gain.
+func (b *bbrSender) getTargetCongestionWindow(gain float64) protocol.ByteCount {
+	bdp := float64(b.BandwidthEstimate()) / float64(BytesPerSecond) * b.getMinRTT().Seconds()
+	window := protocol.ByteCount(gain * bdp)
+	if window == 0 {
+		// no bandwidth sample yet
+		window = protocol.ByteCount(gain * float64(b.initialCongestionWindow))
+	}
+	return utils.MaxByteCount(window, bbrMinCongestionWindow)
+}
+
+func (b *bbrSender) enterStartupMode() {
+	b.mode = bbrStartup
+	b.pacingGain = bbrHighGain
+	b.congestionWindowGain = bbrHighGain
+}
+
+func (b *bbrSender) enterProbeBandwidthMode(now time.Time) {
+	b.mode = bbrProbeBW
+	b.congestionWindowGain = bbrCongestionWindowGain
+	// Start the gain cycle at a random phase, such that flows sharing a bottleneck don't synchronize.
+	// The phase that drains the queue (gain 0.75) is never used first, since the queue was just drained.
+	b.cycleCurrentOffset = rand.Intn(bbrGainCycleLength - 1)
+	if b.cycleCurrentOffset >= 1 {
+		b.cycleCurrentOffset++
+	}
+	b.lastCycleStart = now
+	b.pacingGain = bbrPacingGainCycle[b.cycleCurrentOffset]
+}
+
+func (b *bbrSender) updateGainCyclePhase(now time.Time, bytesInFlight protocol.ByteCount) {
+	// Every phase lasts (at least) one minimum RTT.
+	shouldAdvance := now.Sub(b.lastCycleStart) > b.getMinRTT()
+	// When probing for bandwidth, stay in the phase until the bytes in flight actually increased,
+	// unless there are losses, which means that the bottleneck queue is full.
+	if b.pacingGain > 1 && !b.InRecovery() && bytesInFlight < b.getTargetCongestionWindow(b.pacingGain) {
+		shouldAdvance = false
+	}
+	// When draining the queue, leave the phase as soon as the bytes in flight dropped to the bandwidth-delay product.
+	if b.pacingGain < 1 && bytesInFlight <= b.getTargetCongestionWindow(1) {
+		shouldAdvance = true
+	}
+	if shouldAdvance {
+		b.cycleCurrentOffset = (b.cycleCurrentOffset + 1) % bbrGainCycleLength
+		b.lastCycleStart = now
+		b.pacingGain = bbrPacingGainCycle[b.cycleCurrentOffset]
+	}
+}
+
+// checkIfFullBandwidthReached is called at the start of every round in STARTUP.
+// The bottleneck bandwidth is reached if the bandwidth estimate didn't grow significantly for a few rounds.
+// When the sender is application-limited, the bandwidth estimate is not expected to grow.
+func (b *bbrSender) checkIfFullBandwidthReached() {
+	if b.isAppLimited {
+		return
+	}
+	target := Bandwidth(float64(b.bandwidthAtLastRound) * bbrStartupGrowthTarget)
+	if bandwidth := b.BandwidthEstimate(); bandwidth >= target {
+		b.bandwidthAtLastRound = bandwidth
+		b.roundsWithoutBandwidthGain = 0
+		return
+	}
+	b.roundsWithoutBandwidthGain++
+	if b.roundsWithoutBandwidthGain >= bbrRoundTripsWithoutGrowthBeforeExitingStartup {
+		b.isAtFullBandwidth = true
+	}
+}
+
+func (b *bbrSender) maybeExitStartupOrDrain(now time.Time, bytesInFlight protocol.ByteCount) {
+	if b.mode == bbrStartup && b.isAtFullBandwidth {
+		b.mode = bbrDrain
+		b.pacingGain = bbrDrainGain
+		b.congestionWindowGain = bbrHighGain
+	}
+	if b.mode == bbrDrain && bytesInFlight <= b.getTargetCongestionWindow(1) {
+		b.enterProbeBandwidthMode(now)
+	}
+}
+
+func (b *bbrSender) maybeEnterOrExitProbeRTT(now time.Time, bytesInFlight protocol.ByteCount, isRoundStart, minRTTExpired bool) {
+	if minRTTExpired && b.mode != bbrProbeRTT {
+		b.mode = bbrProbeRTT
+		b.pacingGain = 1
+		// The exit time is set once the bytes in flight dropped to the minimum congestion window.
+		b.exitProbeRTTAt = time.Time{}
+	}
+	if b.mode != bbrProbeRTT {
+		return
+	}
+	if b.exitProbeRTTAt.IsZero() {
+		// Allow for one additional packet, since the packet that was just acknowledged might be counted already.
+		if bytesInFlight < bbrMinCongestionWindow+protocol.MaxPacketSize {
+			b.exitProbeRTTAt = now.Add(bbrProbeRTTTime)
+			b.probeRTTRoundPassed = false
+		}
+		return
+	}
+	if isRoundStart {
+		b.probeRTTRoundPassed = true
+	}
+	if b.probeRTTRoundPassed && !now.Before(b.exitProbeRTTAt) {
+		b.minRTTTimestamp = now
+		if b.isAtFullBandwidth {
+			b.enterProbeBandwidthMode(now)
+		} else {
+			b.enterStartupMode()
+		}
+	}
+}
+
+func (b *bbrSender) calculateCongestionWindow(ackedBytes protocol.ByteCount) {
+	if b.mode == bbrProbeRTT {
+		return
+	}
+	target := b.getTargetCongestionWindow(b.congestionWindowGain)
+	if b.isAtFullBandwidth {
+		// Slowly move towards the target, such that the window isn't reduced abruptly.
+		b.congestionWindow = utils.MinByteCount(target, b.congestionWindow+ackedBytes)
+	} else if b.congestionWindow < target {
+		// In STARTUP, the window is never reduced.
+		b.congestionWindow += ackedBytes
+	}
+	b.congestionWindow = utils.MaxByteCount(b.congestionWindow, bbrMinCongestionWindow)
+	b.congestionWindow = utils.MinByteCount(b.congestionWindow, b.maxCongestionWindow)
+}
+
+func (b *bbrSender) calculateRecoveryWindow(ackedBytes, bytesInFlight protocol.ByteCount) {
+	if !b.InRecovery() {
+		return
+	}
+	if b.recoveryState == bbrGrowth {
+		b.recoveryWindow += ackedBytes
+	}
+	// Always allow sending at least as many bytes as were just acknowledged (packet conservation).
+	b.recoveryWindow = utils.MaxByteCount(b.recoveryWindow, bytesInFlight+ackedBytes)
+	b.recoveryWindow = utils.MaxByteCount(b.recoveryWindow, bbrMinCongestionWindow)
+}
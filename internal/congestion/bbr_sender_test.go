@@ -0,0 +1,279 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// a simulatedPacket is a packet sent over the simulated link
+type simulatedPacket struct {
+	packetNumber  protocol.PacketNumber
+	deliveryState DeliveryState
+	sentTime      time.Time
+	// ackTime is the time when the packet is acknowledged (or declared lost)
+	ackTime time.Time
+	lost    bool
+}
+
+var _ = Describe("BBR sender", func() {
+	const (
+		linkBandwidth = 10 * 1000 * 1000 * BitsPerSecond
+		linkRTT       = 50 * time.Millisecond
+		// the bandwidth-delay product of the link
+		linkBDP = protocol.ByteCount(uint64(linkBandwidth) / uint64(BytesPerSecond) * uint64(linkRTT) / uint64(time.Second))
+	)
+
+	var (
+		sender        *bbrSender
+		clock         mockClock
+		rttStats      *RTTStats
+		sampler       BandwidthSampler
+		bytesInFlight protocol.ByteCount
+		packetNumber  protocol.PacketNumber
+		packets       []*simulatedPacket
+		linkFreeAt    time.Time
+		nextSendTime  time.Time
+		// every lossInterval-th packet is lost
+		lossInterval protocol.PacketNumber
+		// onAck is called after every packet acknowledged
+		onAck func()
+		// bytesDelivered are the bytes delivered by the link
+		bytesDelivered protocol.ByteCount
+	)
+
+	BeforeEach(func() {
+		clock = mockClock{}
+		rttStats = NewRTTStats()
+		sender = NewBBRSender(&clock, rttStats, initialCongestionWindowPackets, MaxCongestionWindow).(*bbrSender)
+		sampler = BandwidthSampler{}
+		bytesInFlight = 0
+		packetNumber = 0
+		packets = nil
+		linkFreeAt = time.Time{}
+		nextSendTime = time.Time{}
+		lossInterval = 0
+		onAck = nil
+		bytesDelivered = 0
+	})
+
+	sendPacket := func() {
+		now := clock.Now()
+		packetNumber++
+		p := &simulatedPacket{
+			packetNumber:  packetNumber,
+			deliveryState: sampler.OnPacketSent(now, bytesInFlight),
+			sentTime:      now,
+			lost:          lossInterval != 0 && packetNumber%lossInterval == 0,
+		}
+		bytesInFlight += protocol.DefaultTCPMSS
+		sender.OnPacketSent(now, bytesInFlight, packetNumber, protocol.DefaultTCPMSS, true)
+		// packets are queued at the bottleneck, and transmitted at the link bandwidth
+		linkFreeAt = utils.MaxTime(linkFreeAt, now).Add(time.Duration(uint64(protocol.DefaultTCPMSS) * uint64(BytesPerSecond) * uint64(time.Second) / uint64(linkBandwidth)))
+		p.ackTime = linkFreeAt.Add(linkRTT)
+		packets = append(packets, p)
+		// packets are sent at the pacing rate
+		pacingDelay := time.Duration(uint64(protocol.DefaultTCPMSS) * uint64(BytesPerSecond) * uint64(time.Second) / uint64(sender.PacingRate(bytesInFlight)))
+		nextSendTime = utils.MaxTime(nextSendTime, now).Add(pacingDelay)
+	}
+
+	receiveAck := func(p *simulatedPacket) {
+		now := clock.Now()
+		bytesInFlight -= protocol.DefaultTCPMSS
+		if p.lost {
+			sender.OnPacketLost(p.packetNumber, protocol.DefaultTCPMSS, bytesInFlight)
+			return
+		}
+		bytesDelivered += protocol.DefaultTCPMSS
+		rttStats.UpdateRTT(now.Sub(p.sentTime), 0, now)
+		sender.OnBandwidthSample(sampler.OnPacketAcked(now, protocol.DefaultTCPMSS, p.deliveryState))
+		sender.OnPacketAcked(p.packetNumber, protocol.DefaultTCPMSS, bytesInFlight)
+		if onAck != nil {
+			onAck()
+		}
+	}
+
+	// simulate runs a bulk transfer over the simulated link
+	simulate := func(duration time.Duration) {
+		end := clock.Now().Add(duration)
+		for clock.Now().Before(end) {
+			for !nextSendTime.After(clock.Now()) && bytesInFlight < sender.GetCongestionWindow() {
+				sendPacket()
+			}
+			// advance the clock to the next event
+			next := end
+			if len(packets) > 0 && packets[0].ackTime.Before(next) {
+				next = packets[0].ackTime
+			}
+			if bytesInFlight < sender.GetCongestionWindow() && nextSendTime.After(clock.Now()) && nextSendTime.Before(next) {
+				next = nextSendTime
+			}
+			clock.Advance(next.Sub(clock.Now()))
+			for len(packets) > 0 && !packets[0].ackTime.After(clock.Now()) {
+				p := packets[0]
+				packets = packets[1:]
+				receiveAck(p)
+			}
+		}
+	}
+
+	// simulateUntilProbeBW runs the simulation until BBR enters PROBE_BW
+	simulateUntilProbeBW := func() {
+		for i := 0; i < 100 && sender.mode != bbrProbeBW; i++ {
+			simulate(linkRTT)
+		}
+		Expect(sender.mode).To(Equal(bbrProbeBW))
+	}
+
+	It("starts in STARTUP", func() {
+		Expect(sender.mode).To(Equal(bbrStartup))
+		Expect(sender.pacingGain).To(Equal(bbrHighGain))
+		Expect(sender.GetCongestionWindow()).To(Equal(protocol.ByteCount(initialCongestionWindowPackets) * protocol.DefaultTCPMSS))
+		Expect(sender.BandwidthEstimate()).To(BeZero())
+	})
+
+	It("paces the initial congestion window over the initial RTT, using the high gain", func() {
+		rate := Bandwidth(bbrHighGain * float64(BandwidthFromDelta(protocol.ByteCount(initialCongestionWindowPackets)*protocol.DefaultTCPMSS, 100*time.Millisecond)))
+		Expect(sender.PacingRate(0)).To(Equal(rate))
+	})
+
+	It("finds the bottleneck bandwidth and the RTT", func() {
+		simulate(2 * time.Second)
+		Expect(sender.isAtFullBandwidth).To(BeTrue())
+		Expect(sender.BandwidthEstimate()).To(BeNumerically("~", linkBandwidth, linkBandwidth/10))
+		Expect(sender.minRTT).To(BeNumerically("~", linkRTT, 2*time.Millisecond))
+	})
+
+	It("doesn't leave STARTUP when application-limited", func() {
+		// send a single packet per round trip
+		for i := 0; i < 10; i++ {
+			sampler.OnAppLimited(bytesInFlight)
+			sendPacket()
+			clock.Advance(packets[0].ackTime.Sub(clock.Now()))
+			receiveAck(packets[0])
+			packets = packets[1:]
+		}
+		Expect(sender.BandwidthEstimate()).ToNot(BeZero())
+		Expect(sender.isAtFullBandwidth).To(BeFalse())
+		Expect(sender.mode).To(Equal(bbrStartup))
+	})
+
+	It("leaves STARTUP and drains the queue", func() {
+		modes := []bbrMode{sender.mode}
+		onAck = func() {
+			if sender.mode != modes[len(modes)-1] {
+				modes = append(modes, sender.mode)
+			}
+		}
+		simulate(time.Second)
+		Expect(modes).To(Equal([]bbrMode{bbrStartup, bbrDrain, bbrProbeBW}))
+		Expect(sender.mode).To(Equal(bbrProbeBW))
+	})
+
+	It("doesn't build a queue in PROBE_BW", func() {
+		simulateUntilProbeBW()
+		// wait for the packets sent in DRAIN to be acknowledged
+		simulate(2 * linkRTT)
+		var maxRTT time.Duration
+		onAck = func() { maxRTT = utils.MaxDuration(maxRTT, rttStats.LatestRTT()) }
+		simulate(2 * time.Second)
+		Expect(maxRTT).To(BeNumerically("<", linkRTT*3/2))
+		Expect(bytesDelivered).To(BeNumerically(">", protocol.ByteCount(float64(linkBDP)*2*float64(time.Second)/float64(linkRTT)*0.9)))
+	})
+
+	It("cycles through the pacing gains in PROBE_BW", func() {
+		simulateUntilProbeBW()
+		gains := make(map[float64]bool)
+		onAck = func() { gains[sender.pacingGain] = true }
+		simulate(time.Second)
+		Expect(gains).To(HaveLen(3))
+		Expect(gains).To(HaveKey(1.25))
+		Expect(gains).To(HaveKey(0.75))
+		Expect(gains).To(HaveKey(1.0))
+	})
+
+	It("paces packets at the pacing gain times the bandwidth estimate", func() {
+		simulateUntilProbeBW()
+		Expect(sender.PacingRate(0)).To(Equal(Bandwidth(sender.pacingGain * float64(sender.BandwidthEstimate()))))
+	})
+
+	It("limits the congestion window to twice the bandwidth-delay product", func() {
+		simulateUntilProbeBW()
+		simulate(time.Second)
+		Expect(sender.GetCongestionWindow()).To(Equal(sender.getTargetCongestionWindow(bbrCongestionWindowGain)))
+		Expect(sender.GetCongestionWindow()).To(BeNumerically("~", 2*linkBDP, linkBDP/5))
+	})
+
+	It("enters PROBE_RTT when the minimum RTT expires", func() {
+		simulateUntilProbeBW()
+		var probeRTTStart, probeRTTEnd time.Time
+		onAck = func() {
+			if sender.mode == bbrProbeRTT {
+				if probeRTTStart.IsZero() {
+					probeRTTStart = clock.Now()
+				}
+				Expect(sender.GetCongestionWindow()).To(Equal(bbrMinCongestionWindow))
+			} else if !probeRTTStart.IsZero() && probeRTTEnd.IsZero() {
+				probeRTTEnd = clock.Now()
+			}
+		}
+		simulate(bbrMinRTTExpiry + time.Second)
+		Expect(probeRTTStart).ToNot(BeZero())
+		Expect(probeRTTEnd).ToNot(BeZero())
+		Expect(probeRTTEnd.Sub(probeRTTStart)).To(BeNumerically(">=", bbrProbeRTTTime))
+		Expect(sender.mode).To(Equal(bbrProbeBW))
+		Expect(sender.minRTT).To(BeNumerically("~", linkRTT, 2*time.Millisecond))
+	})
+
+	It("keeps the sending rate when there's random packet loss", func() {
+		lossInterval = 50 // 2% packet loss
+		simulateUntilProbeBW()
+		bytesDelivered = 0
+		simulate(2 * time.Second)
+		Expect(sender.BandwidthEstimate()).To(BeNumerically("~", linkBandwidth, linkBandwidth/10))
+		// the link is almost fully utilized
+		Expect(bytesDelivered).To(BeNumerically(">", protocol.ByteCount(float64(linkBDP)*2*float64(time.Second)/float64(linkRTT)*0.85)))
+	})
+
+	Context("recovery", func() {
+		BeforeEach(func() {
+			simulateUntilProbeBW()
+		})
+
+		It("reduces the congestion window to the bytes in flight when a packet is lost", func() {
+			packets[0].lost = true
+			receiveAck(packets[0])
+			Expect(sender.InRecovery()).To(BeTrue())
+			Expect(sender.GetCongestionWindow()).To(Equal(utils.MaxByteCount(bytesInFlight, bbrMinCongestionWindow)))
+		})
+
+		It("only starts recovery once per round trip", func() {
+			packets[0].lost = true
+			receiveAck(packets[0])
+			cwnd := sender.GetCongestionWindow()
+			packets[1].lost = true
+			receiveAck(packets[1])
+			Expect(sender.InRecovery()).To(BeTrue())
+			Expect(sender.GetCongestionWindow()).To(Equal(cwnd - protocol.DefaultTCPMSS))
+		})
+
+		It("reduces the congestion window to the minimum on persistent congestion", func() {
+			packets[0].lost = true
+			receiveAck(packets[0])
+			sender.OnPersistentCongestion()
+			Expect(sender.GetCongestionWindow()).To(Equal(bbrMinCongestionWindow))
+		})
+
+		It("leaves recovery when a packet sent after the loss is acknowledged", func() {
+			packets[0].lost = true
+			simulate(linkRTT / 2)
+			Expect(sender.InRecovery()).To(BeTrue())
+			simulate(3 * linkRTT)
+			Expect(sender.InRecovery()).To(BeFalse())
+		})
+	})
+})
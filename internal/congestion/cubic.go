@@ -124,6 +124,15 @@ func (c *Cubic) OnApplicationLimited() {
 	}
 }
 
+// OnCongestionWindowDecay is called when the congestion window was reduced after an idle period.
+// The app-limited period isn't shifted out of the epoch, since the curve of the old epoch would restore the window used before the idle period.
+// Instead, a new epoch is started, in which the window grows back towards the window used before the idle period, like after a loss event.
+func (c *Cubic) OnCongestionWindowDecay(congestionWindowBeforeIdle protocol.PacketNumber) {
+	c.lastMaxCongestionWindow = congestionWindowBeforeIdle
+	c.epoch = time.Time{}
+	c.appLimitedStartTime = time.Time{}
+}
+
 // CongestionWindowAfterPacketLoss computes a new congestion window to use after
 // a loss event. Returns the new congestion window in packets. The new
 // congestion window is a multiplicative decrease of our current window.
@@ -158,6 +167,8 @@ func (c *Cubic) CongestionWindowAfterAck(currentCongestionWindow protocol.Packet
 		// First ACK after a loss event.
 		c.epoch = currentTime   // Start of epoch.
 		c.ackedPacketsCount = 1 // Reset count.
+		// An app-limited period that started before this epoch must not shift it.
+		c.appLimitedStartTime = time.Time{}
 		// Reset estimated_tcp_congestion_window_ to be in sync with cubic.
 		c.estimatedTCPcongestionWindow = currentCongestionWindow
 		if c.lastMaxCongestionWindow <= currentCongestionWindow {
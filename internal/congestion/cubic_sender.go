@@ -14,11 +14,13 @@ const (
 )
 
 type cubicSender struct {
-	hybridSlowStart HybridSlowStart
-	prr             PrrSender
-	rttStats        *RTTStats
-	stats           connectionStats
-	cubic           *Cubic
+	slowStart SlowStart
+	prr       PrrSender
+	rttStats  *RTTStats
+	stats     connectionStats
+	cubic     *Cubic
+
+	bandwidthEstimator *BandwidthEstimator
 
 	reno bool
 
@@ -28,9 +30,25 @@ type cubicSender struct {
 	// Track the largest packet that has been acked.
 	largestAckedPacketNumber protocol.PacketNumber
 
+	// The time the last retransmittable packet was sent.
+	// Used to detect idle periods.
+	lastSentTime time.Time
+
 	// Track the largest packet number outstanding when a CWND cutback occurs.
 	largestSentAtLastCutback protocol.PacketNumber
 
+	// The state before the last CWND cutback.
+	// It is restored if all packets declared lost in that loss event turn out to be spurious losses.
+	priorCongestionWindow         protocol.PacketNumber
+	priorSlowstartThreshold       protocol.PacketNumber
+	priorLargestSentAtLastCutback protocol.PacketNumber
+	priorCubic                    Cubic
+	// The packet number of the loss that caused the last CWND cutback.
+	firstLostPacketAtLastCutback protocol.PacketNumber
+	// Number of packets declared lost in the last loss event that weren't acknowledged later.
+	// It is 0 if the cutback can't be undone.
+	numLostSinceLastCutback int
+
 	// Congestion window in packets.
 	congestionWindow protocol.PacketNumber
 
@@ -56,14 +74,18 @@ type cubicSender struct {
 	// ACK counter for the Reno implementation.
 	congestionWindowCount protocol.ByteCount
 
+	// ACK counter for slow start, if the slow start algorithm slows down the growth of the congestion window.
+	slowStartAckCount int
+
 	initialCongestionWindow    protocol.PacketNumber
 	initialMaxCongestionWindow protocol.PacketNumber
 }
 
 // NewCubicSender makes a new cubic sender
-func NewCubicSender(clock Clock, rttStats *RTTStats, reno bool, initialCongestionWindow, initialMaxCongestionWindow protocol.PacketNumber) SendAlgorithmWithDebugInfo {
+func NewCubicSender(clock Clock, rttStats *RTTStats, reno bool, slowStart SlowStart, initialCongestionWindow, initialMaxCongestionWindow protocol.PacketNumber) SendAlgorithmWithDebugInfo {
 	return &cubicSender{
 		rttStats:                   rttStats,
+		slowStart:                  slowStart,
 		initialCongestionWindow:    initialCongestionWindow,
 		initialMaxCongestionWindow: initialMaxCongestionWindow,
 		congestionWindow:           initialCongestionWindow,
@@ -72,23 +94,29 @@ func NewCubicSender(clock Clock, rttStats *RTTStats, reno bool, initialCongestio
 		maxTCPCongestionWindow:     initialMaxCongestionWindow,
 		numConnections:             defaultNumConnections,
 		cubic:                      NewCubic(clock),
+		bandwidthEstimator:         NewBandwidthEstimator(),
 		reno:                       reno,
 	}
 }
 
-// TimeUntilSend returns when the next packet should be sent.
-func (c *cubicSender) TimeUntilSend(bytesInFlight protocol.ByteCount) time.Duration {
+// PacingRate returns the pacing rate.
+// It is 2*cwnd/rtt in slow start, and 1.25*cwnd/rtt in congestion avoidance.
+func (c *cubicSender) PacingRate(bytesInFlight protocol.ByteCount) Bandwidth {
 	if c.InRecovery() {
 		// PRR is used when in recovery.
 		if c.prr.TimeUntilSend(c.GetCongestionWindow(), bytesInFlight, c.GetSlowStartThreshold()) == 0 {
 			return 0
 		}
 	}
-	delay := c.rttStats.SmoothedRTT() / time.Duration(2*c.GetCongestionWindow()/protocol.DefaultTCPMSS)
-	if !c.InSlowStart() { // adjust delay, such that it's 1.25*cwd/rtt
-		delay = delay * 8 / 5
+	srtt := c.rttStats.SmoothedRTT()
+	if srtt == 0 {
+		return 0
 	}
-	return delay
+	rate := BandwidthFromDelta(c.GetCongestionWindow(), srtt)
+	if c.InSlowStart() {
+		return 2 * rate
+	}
+	return rate * 5 / 4
 }
 
 func (c *cubicSender) OnPacketSent(sentTime time.Time, bytesInFlight protocol.ByteCount, packetNumber protocol.PacketNumber, bytes protocol.ByteCount, isRetransmittable bool) bool {
@@ -100,11 +128,47 @@ func (c *cubicSender) OnPacketSent(sentTime time.Time, bytesInFlight protocol.By
 		// PRR is used when in recovery.
 		c.prr.OnPacketSent(bytes)
 	}
+	// If no other packets are in flight, the sender might have been idle.
+	if bytesInFlight <= bytes && !c.lastSentTime.IsZero() {
+		c.maybeDecayCongestionWindow(sentTime.Sub(c.lastSentTime))
+	}
+	c.lastSentTime = sentTime
 	c.largestSentPacketNumber = packetNumber
-	c.hybridSlowStart.OnPacketSent(packetNumber)
+	c.slowStart.OnPacketSent(packetNumber)
 	return true
 }
 
+// maybeDecayCongestionWindow implements congestion window validation (RFC 2861).
+// After an idle period longer than the RTO, the congestion window doesn't reflect the current state of the network any more.
+// It is halved for every RTO the sender was idle, but never reduced below the restart window.
+// The slow start threshold is raised to 3/4 of the old window, so that slow start quickly grows the window back.
+// While the sender is application-limited, but not idle, the congestion window is neither increased nor decreased.
+func (c *cubicSender) maybeDecayCongestionWindow(idle time.Duration) {
+	rto := c.retransmissionTimeout()
+	if rto == 0 || idle <= rto {
+		return
+	}
+	restartWindow := utils.MinPacketNumber(c.initialCongestionWindow, c.congestionWindow)
+	congestionWindowBeforeIdle := c.congestionWindow
+	c.slowstartThreshold = utils.MaxPacketNumber(c.slowstartThreshold, 3*c.congestionWindow/4)
+	for ; idle > rto && c.congestionWindow > restartWindow; idle -= rto {
+		c.congestionWindow /= 2
+	}
+	c.congestionWindow = utils.MaxPacketNumber(c.congestionWindow, restartWindow)
+	c.congestionWindowCount = 0
+	c.cubic.OnCongestionWindowDecay(congestionWindowBeforeIdle)
+}
+
+// retransmissionTimeout is the RTO, as defined by RFC 6298.
+// It is 0 if there's no RTT estimate yet.
+func (c *cubicSender) retransmissionTimeout() time.Duration {
+	srtt := c.rttStats.SmoothedRTT()
+	if srtt == 0 {
+		return 0
+	}
+	return srtt + 4*c.rttStats.MeanDeviation()
+}
+
 func (c *cubicSender) InRecovery() bool {
 	return c.largestAckedPacketNumber <= c.largestSentAtLastCutback && c.largestAckedPacketNumber != 0
 }
@@ -130,7 +194,7 @@ func (c *cubicSender) SlowstartThreshold() protocol.PacketNumber {
 }
 
 func (c *cubicSender) MaybeExitSlowStart() {
-	if c.InSlowStart() && c.hybridSlowStart.ShouldExitSlowStart(c.rttStats.LatestRTT(), c.rttStats.MinRTT(), c.GetCongestionWindow()/protocol.DefaultTCPMSS) {
+	if c.InSlowStart() && c.slowStart.ShouldExitSlowStart(c.rttStats.LatestRTT(), c.rttStats.MinRTT(), c.GetCongestionWindow()/protocol.DefaultTCPMSS) {
 		c.ExitSlowstart()
 	}
 }
@@ -144,7 +208,7 @@ func (c *cubicSender) OnPacketAcked(ackedPacketNumber protocol.PacketNumber, ack
 	}
 	c.maybeIncreaseCwnd(ackedPacketNumber, ackedBytes, bytesInFlight)
 	if c.InSlowStart() {
-		c.hybridSlowStart.OnPacketAcked(ackedPacketNumber)
+		c.slowStart.OnPacketAcked(ackedPacketNumber)
 	}
 }
 
@@ -152,6 +216,14 @@ func (c *cubicSender) OnPacketLost(packetNumber protocol.PacketNumber, lostBytes
 	// TCP NewReno (RFC6582) says that once a loss occurs, any losses in packets
 	// already sent should be treated as a single loss event, since it's expected.
 	if packetNumber <= c.largestSentAtLastCutback {
+		if c.numLostSinceLastCutback > 0 {
+			if packetNumber > c.firstLostPacketAtLastCutback {
+				c.numLostSinceLastCutback++
+			} else {
+				// We can't tell if this packet belongs to the last loss event.
+				c.numLostSinceLastCutback = 0
+			}
+		}
 		if c.lastCutbackExitedSlowstart {
 			c.stats.slowstartPacketsLost++
 			c.stats.slowstartBytesLost += lostBytes
@@ -169,7 +241,48 @@ func (c *cubicSender) OnPacketLost(packetNumber protocol.PacketNumber, lostBytes
 	if c.InSlowStart() {
 		c.stats.slowstartPacketsLost++
 	}
+	c.priorCongestionWindow = c.congestionWindow
+	c.priorSlowstartThreshold = c.slowstartThreshold
+	c.priorLargestSentAtLastCutback = c.largestSentAtLastCutback
+	c.priorCubic = *c.cubic
+	c.firstLostPacketAtLastCutback = packetNumber
+	c.numLostSinceLastCutback = 1
+	c.reduceCongestionWindow(bytesInFlight)
+}
+
+// OnSpuriousLoss undoes the last CWND cutback (similar to the Eifel response algorithm, RFC 4015),
+// once all packets declared lost in the last loss event were acknowledged.
+// Spurious losses of packets from earlier loss events are ignored.
+func (c *cubicSender) OnSpuriousLoss(packetNumber protocol.PacketNumber) {
+	if c.numLostSinceLastCutback == 0 || packetNumber < c.firstLostPacketAtLastCutback || packetNumber > c.largestSentAtLastCutback {
+		return
+	}
+	c.numLostSinceLastCutback--
+	if c.numLostSinceLastCutback > 0 {
+		return
+	}
+	c.congestionWindow = utils.MaxPacketNumber(c.congestionWindow, c.priorCongestionWindow)
+	c.slowstartThreshold = utils.MaxPacketNumber(c.slowstartThreshold, c.priorSlowstartThreshold)
+	// continue on the cubic curve from before the loss, instead of starting a new epoch
+	*c.cubic = c.priorCubic
+	// leave recovery
+	c.largestSentAtLastCutback = c.priorLargestSentAtLastCutback
+}
 
+// OnCongestionExperienced is called when the peer reports packets that were marked CE.
+// largestAcked is the largest packet number acknowledged by the ACK frame that reported the marks.
+func (c *cubicSender) OnCongestionExperienced(largestAcked protocol.PacketNumber, bytesInFlight protocol.ByteCount) {
+	// Like for losses, we only react to CE marks once per window.
+	if largestAcked <= c.largestSentAtLastCutback {
+		return
+	}
+	c.lastCutbackExitedSlowstart = c.InSlowStart()
+	// CE marks are never spurious
+	c.numLostSinceLastCutback = 0
+	c.reduceCongestionWindow(bytesInFlight)
+}
+
+func (c *cubicSender) reduceCongestionWindow(bytesInFlight protocol.ByteCount) {
 	c.prr.OnPacketLost(bytesInFlight)
 
 	// TODO(chromium): Separate out all of slow start into a separate class.
@@ -213,7 +326,12 @@ func (c *cubicSender) maybeIncreaseCwnd(ackedPacketNumber protocol.PacketNumber,
 	}
 	if c.InSlowStart() {
 		// TCP slow start, exponential growth, increase by one for each ACK.
-		c.congestionWindow++
+		// The slow start algorithm might slow down the growth, e.g. HyStart++ in conservative slow start.
+		c.slowStartAckCount++
+		if c.slowStartAckCount >= c.slowStart.GrowthDivisor() {
+			c.congestionWindow++
+			c.slowStartAckCount = 0
+		}
 		return
 	}
 	if c.reno {
@@ -240,19 +358,19 @@ func (c *cubicSender) isCwndLimited(bytesInFlight protocol.ByteCount) bool {
 	return slowStartLimited || availableBytes <= maxBurstBytes
 }
 
+// OnBandwidthSample updates the bandwidth estimate
+func (c *cubicSender) OnBandwidthSample(sample BandwidthSample) {
+	c.bandwidthEstimator.OnBandwidthSample(sample)
+}
+
 // BandwidthEstimate returns the current bandwidth estimate
 func (c *cubicSender) BandwidthEstimate() Bandwidth {
-	srtt := c.rttStats.SmoothedRTT()
-	if srtt == 0 {
-		// If we haven't measured an rtt, the bandwidth estimate is unknown.
-		return 0
-	}
-	return BandwidthFromDelta(c.GetCongestionWindow(), srtt)
+	return c.bandwidthEstimator.BandwidthEstimate()
 }
 
-// HybridSlowStart returns the hybrid slow start instance for testing
-func (c *cubicSender) HybridSlowStart() *HybridSlowStart {
-	return &c.hybridSlowStart
+// SlowStart returns the slow start algorithm for testing
+func (c *cubicSender) SlowStart() SlowStart {
+	return c.slowStart
 }
 
 // SetNumEmulatedConnections sets the number of emulated connections
@@ -261,13 +379,11 @@ func (c *cubicSender) SetNumEmulatedConnections(n int) {
 	c.cubic.SetNumConnections(c.numConnections)
 }
 
-// OnRetransmissionTimeout is called on an retransmission timeout
-func (c *cubicSender) OnRetransmissionTimeout(packetsRetransmitted bool) {
+// OnPersistentCongestion is called when persistent congestion is detected
+func (c *cubicSender) OnPersistentCongestion() {
 	c.largestSentAtLastCutback = 0
-	if !packetsRetransmitted {
-		return
-	}
-	c.hybridSlowStart.Restart()
+	c.numLostSinceLastCutback = 0
+	c.slowStart.Restart()
 	c.cubic.Reset()
 	c.slowstartThreshold = c.congestionWindow / 2
 	c.congestionWindow = c.minCongestionWindow
@@ -275,14 +391,18 @@ func (c *cubicSender) OnRetransmissionTimeout(packetsRetransmitted bool) {
 
 // OnConnectionMigration is called when the connection is migrated (?)
 func (c *cubicSender) OnConnectionMigration() {
-	c.hybridSlowStart.Restart()
+	c.slowStart.Restart()
 	c.prr = PrrSender{}
 	c.largestSentPacketNumber = 0
 	c.largestAckedPacketNumber = 0
 	c.largestSentAtLastCutback = 0
 	c.lastCutbackExitedSlowstart = false
+	c.numLostSinceLastCutback = 0
 	c.cubic.Reset()
+	c.bandwidthEstimator = NewBandwidthEstimator()
 	c.congestionWindowCount = 0
+	c.slowStartAckCount = 0
+	c.lastSentTime = time.Time{}
 	c.congestionWindow = c.initialCongestionWindow
 	c.slowstartThreshold = c.initialMaxCongestionWindow
 	c.maxTCPCongestionWindow = c.initialMaxCongestionWindow
@@ -292,11 +412,3 @@ func (c *cubicSender) OnConnectionMigration() {
 func (c *cubicSender) SetSlowStartLargeReduction(enabled bool) {
 	c.slowStartLargeReduction = enabled
 }
-
-// RetransmissionDelay gives the time to retransmission
-func (c *cubicSender) RetransmissionDelay() time.Duration {
-	if c.rttStats.SmoothedRTT() == 0 {
-		return 0
-	}
-	return c.rttStats.SmoothedRTT() + c.rttStats.MeanDeviation()*4
-}
@@ -4,7 +4,6 @@ import (
 	"time"
 
 	"github.com/lucas-clemente/quic-go/internal/protocol"
-	"github.com/lucas-clemente/quic-go/internal/utils"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -40,17 +39,17 @@ var _ = Describe("Cubic Sender", func() {
 		ackedPacketNumber = 0
 		clock = mockClock{}
 		rttStats = NewRTTStats()
-		sender = NewCubicSender(&clock, rttStats, true /*reno*/, initialCongestionWindowPackets, MaxCongestionWindow)
+		sender = NewCubicSender(&clock, rttStats, true /*reno*/, &HybridSlowStart{}, initialCongestionWindowPackets, MaxCongestionWindow)
 	})
 
 	SendAvailableSendWindowLen := func(packetLength protocol.ByteCount) int {
 		// Send as long as TimeUntilSend returns InfDuration.
 		packets_sent := 0
 		for bytesInFlight < sender.GetCongestionWindow() {
+			bytesInFlight += packetLength
 			sender.OnPacketSent(clock.Now(), bytesInFlight, packetNumber, packetLength, true)
 			packetNumber++
 			packets_sent++
-			bytesInFlight += packetLength
 		}
 		return packets_sent
 	}
@@ -89,9 +88,9 @@ var _ = Describe("Cubic Sender", func() {
 		// At startup make sure we are at the default.
 		Expect(sender.GetCongestionWindow()).To(Equal(defaultWindowTCP))
 		// At startup make sure we can send.
-		Expect(sender.TimeUntilSend(0)).To(BeZero())
+		Expect(sender.PacingRate(0)).To(BeZero())
 		// Make sure we can send.
-		Expect(sender.TimeUntilSend(0)).To(BeZero())
+		Expect(sender.PacingRate(0)).To(BeZero())
 		// And that window is un-affected.
 		Expect(sender.GetCongestionWindow()).To(Equal(defaultWindowTCP))
 	})
@@ -101,18 +100,29 @@ var _ = Describe("Cubic Sender", func() {
 		// Fill the send window with data, then verify that we can't send.
 		SendAvailableSendWindow()
 		AckNPackets(1)
-		delay := sender.TimeUntilSend(bytesInFlight)
-		Expect(delay).ToNot(BeZero())
-		Expect(delay).ToNot(Equal(utils.InfDuration))
+		// in slow start, the pacing rate is twice the congestion window per RTT
+		Expect(sender.PacingRate(bytesInFlight)).To(Equal(2 * BandwidthFromDelta(sender.GetCongestionWindow(), rttStats.SmoothedRTT())))
+	})
+
+	It("paces at 1.25 times the congestion window per RTT in congestion avoidance", func() {
+		SendAvailableSendWindow()
+		LoseNPackets(1)
+		// leave recovery by acknowledging a packet sent after the loss
+		AckNPackets(int(bytesInFlight / protocol.DefaultTCPMSS))
+		SendAvailableSendWindow()
+		AckNPackets(1)
+		Expect(sender.(*cubicSender).InSlowStart()).To(BeFalse())
+		Expect(sender.InRecovery()).To(BeFalse())
+		Expect(sender.PacingRate(bytesInFlight)).To(Equal(BandwidthFromDelta(sender.GetCongestionWindow(), rttStats.SmoothedRTT()) * 5 / 4))
 	})
 
 	It("application limited slow start", func() {
 		// Send exactly 10 packets and ensure the CWND ends at 14 packets.
 		const kNumberOfAcks = 5
 		// At startup make sure we can send.
-		Expect(sender.TimeUntilSend(0)).To(BeZero())
+		Expect(sender.PacingRate(0)).To(BeZero())
 		// Make sure we can send.
-		Expect(sender.TimeUntilSend(0)).To(BeZero())
+		Expect(sender.PacingRate(0)).To(BeZero())
 
 		SendAvailableSendWindow()
 		for i := 0; i < kNumberOfAcks; i++ {
@@ -127,10 +137,10 @@ var _ = Describe("Cubic Sender", func() {
 	It("exponential slow start", func() {
 		const kNumberOfAcks = 20
 		// At startup make sure we can send.
-		Expect(sender.TimeUntilSend(0)).To(BeZero())
+		Expect(sender.PacingRate(0)).To(BeZero())
 		Expect(sender.BandwidthEstimate()).To(BeZero())
 		// Make sure we can send.
-		Expect(sender.TimeUntilSend(0)).To(BeZero())
+		Expect(sender.PacingRate(0)).To(BeZero())
 
 		for i := 0; i < kNumberOfAcks; i++ {
 			// Send our full send window.
@@ -139,7 +149,13 @@ var _ = Describe("Cubic Sender", func() {
 		}
 		cwnd := sender.GetCongestionWindow()
 		Expect(cwnd).To(Equal(defaultWindowTCP + protocol.DefaultTCPMSS*2*kNumberOfAcks))
-		Expect(sender.BandwidthEstimate()).To(Equal(BandwidthFromDelta(cwnd, rttStats.SmoothedRTT())))
+	})
+
+	It("estimates the bandwidth from bandwidth samples", func() {
+		Expect(sender.BandwidthEstimate()).To(BeZero())
+		sender.OnBandwidthSample(BandwidthSample{Bandwidth: 1000 * BytesPerSecond, Delivered: 1000})
+		sender.OnBandwidthSample(BandwidthSample{Bandwidth: 500 * BytesPerSecond, Delivered: 1000, PriorDelivered: 1000})
+		Expect(sender.BandwidthEstimate()).To(Equal(1000 * BytesPerSecond))
 	})
 
 	It("slow start packet loss", func() {
@@ -179,10 +195,10 @@ var _ = Describe("Cubic Sender", func() {
 		expected_send_window += protocol.DefaultTCPMSS
 		Expect(sender.GetCongestionWindow()).To(Equal(expected_send_window))
 
-		// Now RTO and ensure slow start gets reset.
-		Expect(sender.HybridSlowStart().Started()).To(BeTrue())
-		sender.OnRetransmissionTimeout(true)
-		Expect(sender.HybridSlowStart().Started()).To(BeFalse())
+		// Now detect persistent congestion and ensure slow start gets reset.
+		Expect(sender.SlowStart().Started()).To(BeTrue())
+		sender.OnPersistentCongestion()
+		Expect(sender.SlowStart().Started()).To(BeFalse())
 	})
 
 	It("slow start packet loss with large reduction", func() {
@@ -230,10 +246,10 @@ var _ = Describe("Cubic Sender", func() {
 		expected_send_window += protocol.DefaultTCPMSS
 		Expect(sender.GetCongestionWindow()).To(Equal(expected_send_window))
 
-		// Now RTO and ensure slow start gets reset.
-		Expect(sender.HybridSlowStart().Started()).To(BeTrue())
-		sender.OnRetransmissionTimeout(true)
-		Expect(sender.HybridSlowStart().Started()).To(BeFalse())
+		// Now detect persistent congestion and ensure slow start gets reset.
+		Expect(sender.SlowStart().Started()).To(BeTrue())
+		sender.OnPersistentCongestion()
+		Expect(sender.SlowStart().Started()).To(BeFalse())
 	})
 
 	It("slow start half packet loss with large reduction", func() {
@@ -273,7 +289,7 @@ var _ = Describe("Cubic Sender", func() {
 		// Simulate abandoning all packets by supplying a bytes_in_flight of 0.
 		// PRR should now allow a packet to be sent, even though prr's state
 		// variables believe it has sent enough packets.
-		Expect(sender.TimeUntilSend(0)).To(BeZero())
+		Expect(sender.PacingRate(0)).To(BeZero())
 	})
 
 	It("slow start packet loss PRR", func() {
@@ -346,7 +362,7 @@ var _ = Describe("Cubic Sender", func() {
 		LoseNPackets(int(num_packets_to_lose))
 		// Immediately after the loss, ensure at least one packet can be sent.
 		// Losses without subsequent acks can occur with timer based loss detection.
-		Expect(sender.TimeUntilSend(bytesInFlight)).To(BeZero())
+		Expect(sender.PacingRate(bytesInFlight)).To(BeZero())
 		AckNPackets(1)
 
 		// We should now have fallen out of slow start with a reduced window.
@@ -377,57 +393,21 @@ var _ = Describe("Cubic Sender", func() {
 		}
 	})
 
-	It("RTO congestion window", func() {
+	It("persistent congestion congestion window", func() {
 		Expect(sender.GetCongestionWindow()).To(Equal(defaultWindowTCP))
 		Expect(sender.SlowstartThreshold()).To(Equal(MaxCongestionWindow))
 
-		// Expect the window to decrease to the minimum once the RTO fires
+		// Expect the window to decrease to the minimum once persistent congestion is detected
 		// and slow start threshold to be set to 1/2 of the CWND.
-		sender.OnRetransmissionTimeout(true)
+		sender.OnPersistentCongestion()
 		Expect(sender.GetCongestionWindow()).To(Equal(protocol.ByteCount(2 * protocol.DefaultTCPMSS)))
 		Expect(sender.SlowstartThreshold()).To(Equal(protocol.PacketNumber(5)))
 	})
 
-	It("RTO congestion window no retransmission", func() {
-		Expect(sender.GetCongestionWindow()).To(Equal(defaultWindowTCP))
-
-		// Expect the window to remain unchanged if the RTO fires but no
-		// packets are retransmitted.
-		sender.OnRetransmissionTimeout(false)
-		Expect(sender.GetCongestionWindow()).To(Equal(defaultWindowTCP))
-	})
-
-	It("retransmission delay", func() {
-		const kRttMs = 10 * time.Millisecond
-		const kDeviationMs = 3 * time.Millisecond
-		Expect(sender.RetransmissionDelay()).To(BeZero())
-
-		rttStats.UpdateRTT(kRttMs, 0, clock.Now())
-
-		// Initial value is to set the median deviation to half of the initial
-		// rtt, the median in then multiplied by a factor of 4 and finally the
-		// smoothed rtt is added which is the initial rtt.
-		expected_delay := kRttMs + kRttMs/2*4
-		Expect(sender.RetransmissionDelay()).To(Equal(expected_delay))
-
-		for i := 0; i < 100; i++ {
-			// run to make sure that we converge.
-			rttStats.UpdateRTT(kRttMs+kDeviationMs, 0, clock.Now())
-			rttStats.UpdateRTT(kRttMs-kDeviationMs, 0, clock.Now())
-		}
-		expected_delay = kRttMs + kDeviationMs*4
-
-		Expect(rttStats.SmoothedRTT()).To(BeNumerically("~", kRttMs, time.Millisecond))
-		Expect(sender.RetransmissionDelay()).To(BeNumerically("~", expected_delay, time.Millisecond))
-		Expect(sender.BandwidthEstimate() / BytesPerSecond).To(Equal(Bandwidth(
-			sender.GetCongestionWindow() * protocol.ByteCount(time.Second) / protocol.ByteCount(rttStats.SmoothedRTT()),
-		)))
-	})
-
 	It("slow start max send window", func() {
 		const kMaxCongestionWindowTCP = 50
 		const kNumberOfAcks = 100
-		sender = NewCubicSender(&clock, rttStats, false, initialCongestionWindowPackets, kMaxCongestionWindowTCP)
+		sender = NewCubicSender(&clock, rttStats, false, &HybridSlowStart{}, initialCongestionWindowPackets, kMaxCongestionWindowTCP)
 
 		for i := 0; i < kNumberOfAcks; i++ {
 			// Send our full send window.
@@ -441,7 +421,7 @@ var _ = Describe("Cubic Sender", func() {
 	It("tcp reno max congestion window", func() {
 		const kMaxCongestionWindowTCP = 50
 		const kNumberOfAcks = 1000
-		sender = NewCubicSender(&clock, rttStats, false, initialCongestionWindowPackets, kMaxCongestionWindowTCP)
+		sender = NewCubicSender(&clock, rttStats, false, &HybridSlowStart{}, initialCongestionWindowPackets, kMaxCongestionWindowTCP)
 
 		SendAvailableSendWindow()
 		AckNPackets(2)
@@ -463,7 +443,7 @@ var _ = Describe("Cubic Sender", func() {
 		// Set to 10000 to compensate for small cubic alpha.
 		const kNumberOfAcks = 10000
 
-		sender = NewCubicSender(&clock, rttStats, false, initialCongestionWindowPackets, kMaxCongestionWindowTCP)
+		sender = NewCubicSender(&clock, rttStats, false, &HybridSlowStart{}, initialCongestionWindowPackets, kMaxCongestionWindowTCP)
 
 		SendAvailableSendWindow()
 		AckNPackets(2)
@@ -483,7 +463,7 @@ var _ = Describe("Cubic Sender", func() {
 	It("tcp cubic reset epoch on quiescence", func() {
 		const kMaxCongestionWindow = 50
 		const kMaxCongestionWindowBytes = kMaxCongestionWindow * protocol.DefaultTCPMSS
-		sender = NewCubicSender(&clock, rttStats, false, initialCongestionWindowPackets, kMaxCongestionWindow)
+		sender = NewCubicSender(&clock, rttStats, false, &HybridSlowStart{}, initialCongestionWindowPackets, kMaxCongestionWindow)
 
 		num_sent := SendAvailableSendWindow()
 
@@ -523,7 +503,7 @@ var _ = Describe("Cubic Sender", func() {
 	It("tcp cubic shifted epoch on quiescence", func() {
 		const kMaxCongestionWindow = 50
 		const kMaxCongestionWindowBytes = kMaxCongestionWindow * protocol.DefaultTCPMSS
-		sender = NewCubicSender(&clock, rttStats, false, initialCongestionWindowPackets, kMaxCongestionWindow)
+		sender = NewCubicSender(&clock, rttStats, false, &HybridSlowStart{}, initialCongestionWindowPackets, kMaxCongestionWindow)
 
 		num_sent := SendAvailableSendWindow()
 
@@ -576,6 +556,112 @@ var _ = Describe("Cubic Sender", func() {
 		Expect(post_loss_window).To(BeNumerically(">", sender.GetCongestionWindow()))
 	})
 
+	It("reduces the congestion window when packets are marked CE", func() {
+		SendAvailableSendWindow()
+		initialWindow := sender.GetCongestionWindow()
+		sender.OnCongestionExperienced(ackedPacketNumber+1, bytesInFlight)
+		postCEWindow := sender.GetCongestionWindow()
+		Expect(initialWindow).To(BeNumerically(">", postCEWindow))
+		// CE marks reported for packets sent before the reduction don't reduce the window again
+		sender.OnCongestionExperienced(packetNumber-1, bytesInFlight)
+		Expect(sender.GetCongestionWindow()).To(Equal(postCEWindow))
+		// a loss in the same window doesn't reduce the window either
+		LosePacket(packetNumber - 1)
+		Expect(sender.GetCongestionWindow()).To(Equal(postCEWindow))
+		// CE marks for a later packet reduce the window
+		sender.OnCongestionExperienced(packetNumber, bytesInFlight)
+		Expect(sender.GetCongestionWindow()).To(BeNumerically("<", postCEWindow))
+	})
+
+	It("undoes the congestion window reduction if all losses were spurious", func() {
+		SendAvailableSendWindow()
+		AckNPackets(2)
+		initialWindow := sender.GetCongestionWindow()
+		initialThreshold := sender.SlowstartThreshold()
+		LosePacket(ackedPacketNumber + 1)
+		LosePacket(ackedPacketNumber + 2)
+		Expect(sender.GetCongestionWindow()).To(BeNumerically("<", initialWindow))
+		Expect(sender.InRecovery()).To(BeTrue())
+		sender.OnSpuriousLoss(ackedPacketNumber + 2)
+		Expect(sender.GetCongestionWindow()).To(BeNumerically("<", initialWindow))
+		sender.OnSpuriousLoss(ackedPacketNumber + 1)
+		Expect(sender.GetCongestionWindow()).To(Equal(initialWindow))
+		Expect(sender.SlowstartThreshold()).To(Equal(initialThreshold))
+		Expect(sender.InRecovery()).To(BeFalse())
+	})
+
+	It("continues growing the congestion window like Cubic after undoing a spurious loss", func() {
+		growCongestionWindow := func(spuriousLoss bool) protocol.ByteCount {
+			clock = mockClock{}
+			bytesInFlight = 0
+			packetNumber = 1
+			ackedPacketNumber = 0
+			rttStats = NewRTTStats()
+			sender = NewCubicSender(&clock, rttStats, false, &HybridSlowStart{}, initialCongestionWindowPackets, MaxCongestionWindow)
+			SendAvailableSendWindow()
+			AckNPackets(2)
+			// Make sure we fall out of slow start.
+			LoseNPackets(1)
+			for i := 0; i < 100; i++ {
+				SendAvailableSendWindow()
+				AckNPackets(2)
+			}
+			if spuriousLoss {
+				LosePacket(ackedPacketNumber + 1)
+				sender.OnSpuriousLoss(ackedPacketNumber + 1)
+				// the packet will be acknowledged later
+				bytesInFlight += protocol.DefaultTCPMSS
+			}
+			for i := 0; i < 100; i++ {
+				SendAvailableSendWindow()
+				AckNPackets(2)
+			}
+			return sender.GetCongestionWindow()
+		}
+
+		Expect(growCongestionWindow(true)).To(Equal(growCongestionWindow(false)))
+	})
+
+	It("doesn't undo the congestion window reduction if not all lost packets were acknowledged", func() {
+		SendAvailableSendWindow()
+		AckNPackets(2)
+		initialWindow := sender.GetCongestionWindow()
+		LosePacket(ackedPacketNumber + 1)
+		postLossWindow := sender.GetCongestionWindow()
+		Expect(postLossWindow).To(BeNumerically("<", initialWindow))
+		LosePacket(ackedPacketNumber + 2)
+		sender.OnSpuriousLoss(ackedPacketNumber + 1)
+		Expect(sender.GetCongestionWindow()).To(Equal(postLossWindow))
+		Expect(sender.InRecovery()).To(BeTrue())
+	})
+
+	It("ignores spurious losses of packets lost in an earlier loss event", func() {
+		SendAvailableSendWindow()
+		AckNPackets(2)
+		LosePacket(ackedPacketNumber + 1)
+		firstLost := ackedPacketNumber + 1
+		// lose a packet sent after the cutback, this starts a new loss event
+		sender.OnPacketSent(clock.Now(), bytesInFlight, packetNumber, protocol.DefaultTCPMSS, true)
+		LosePacket(packetNumber)
+		postLossWindow := sender.GetCongestionWindow()
+		sender.OnSpuriousLoss(firstLost)
+		Expect(sender.GetCongestionWindow()).To(Equal(postLossWindow))
+	})
+
+	It("doesn't undo a congestion window reduction caused by CE marks", func() {
+		SendAvailableSendWindow()
+		AckNPackets(2)
+		initialWindow := sender.GetCongestionWindow()
+		LosePacket(ackedPacketNumber + 1)
+		// CE marks for a packet sent after the cutback reduce the window again
+		sender.OnPacketSent(clock.Now(), bytesInFlight, packetNumber, protocol.DefaultTCPMSS, true)
+		sender.OnCongestionExperienced(packetNumber, bytesInFlight)
+		postCEWindow := sender.GetCongestionWindow()
+		Expect(postCEWindow).To(BeNumerically("<", initialWindow))
+		sender.OnSpuriousLoss(ackedPacketNumber + 1)
+		Expect(sender.GetCongestionWindow()).To(Equal(postCEWindow))
+	})
+
 	It("don't track ack packets", func() {
 		// Send a packet with no retransmittable data, and ensure it's not tracked.
 		Expect(sender.OnPacketSent(clock.Now(), bytesInFlight, packetNumber, protocol.DefaultTCPMSS, false)).To(BeFalse())
@@ -777,13 +863,13 @@ var _ = Describe("Cubic Sender", func() {
 	//   sender.OnRetransmissionTimeout(true);
 	//   Expect( sender.congestion_window()).To(Equal(1u))
 	//   EXPECT_TRUE(
-	//       sender.TimeUntilSend(QuicTime::Zero(), protocol.DefaultTCPMSS).IsZero());
+	//       sender.PacingRate(QuicTime::Zero(), protocol.DefaultTCPMSS).IsZero());
 	//   EXPECT_TRUE(
-	//       sender.TimeUntilSend(QuicTime::Zero(), 2 * protocol.DefaultTCPMSS).IsZero());
+	//       sender.PacingRate(QuicTime::Zero(), 2 * protocol.DefaultTCPMSS).IsZero());
 	//   EXPECT_TRUE(
-	//       sender.TimeUntilSend(QuicTime::Zero(), 3 * protocol.DefaultTCPMSS).IsZero());
+	//       sender.PacingRate(QuicTime::Zero(), 3 * protocol.DefaultTCPMSS).IsZero());
 	//   EXPECT_FALSE(
-	//       sender.TimeUntilSend(QuicTime::Zero(), 4 * protocol.DefaultTCPMSS).IsZero());
+	//       sender.PacingRate(QuicTime::Zero(), 4 * protocol.DefaultTCPMSS).IsZero());
 	// }
 
 	It("reset after connection migration", func() {
@@ -815,6 +901,129 @@ var _ = Describe("Cubic Sender", func() {
 		sender.OnConnectionMigration()
 		Expect(sender.GetCongestionWindow()).To(Equal(defaultWindowTCP))
 		Expect(sender.SlowstartThreshold()).To(Equal(MaxCongestionWindow))
-		Expect(sender.HybridSlowStart().Started()).To(BeFalse())
+		Expect(sender.SlowStart().Started()).To(BeFalse())
+	})
+
+	It("grows the congestion window more slowly when HyStart++ is in conservative slow start", func() {
+		hystart := NewHyStartPlusPlus(nil)
+		sender = NewCubicSender(&clock, rttStats, true /*reno*/, hystart, initialCongestionWindowPackets, MaxCongestionWindow)
+		ackWithRTT := func(rtt time.Duration) {
+			rttStats.UpdateRTT(rtt, 0, clock.Now())
+			sender.MaybeExitSlowStart()
+			ackedPacketNumber++
+			sender.OnPacketAcked(ackedPacketNumber, protocol.DefaultTCPMSS, bytesInFlight)
+			bytesInFlight -= protocol.DefaultTCPMSS
+			SendAvailableSendWindow()
+		}
+
+		SendAvailableSendWindow()
+		// two rounds without an RTT increase
+		for i := 0; i < 30; i++ {
+			ackWithRTT(60 * time.Millisecond)
+		}
+		Expect(hystart.InConservativeSlowStart()).To(BeFalse())
+		Expect(sender.GetCongestionWindow()).To(Equal(defaultWindowTCP + 30*protocol.DefaultTCPMSS))
+
+		// the RTT increases
+		for i := 0; i < 8; i++ {
+			ackWithRTT(80 * time.Millisecond)
+		}
+		Expect(hystart.InConservativeSlowStart()).To(BeTrue())
+		cwnd := sender.GetCongestionWindow()
+		for i := 0; i < 8; i++ {
+			ackWithRTT(80 * time.Millisecond)
+		}
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd + 2*protocol.DefaultTCPMSS))
+
+		// exit slow start after 5 rounds in conservative slow start
+		var acks int
+		for sender.(*cubicSender).InSlowStart() {
+			ackWithRTT(80 * time.Millisecond)
+			acks++
+			Expect(acks).To(BeNumerically("<", 1000))
+		}
+		Expect(sender.SlowstartThreshold()).To(Equal(protocol.PacketNumber(sender.GetCongestionWindow() / protocol.DefaultTCPMSS)))
+		Expect(sender.SlowstartThreshold()).To(BeNumerically("<", MaxCongestionWindow))
+	})
+
+	Context("congestion window validation", func() {
+		// growWindow grows the congestion window in slow start, and then acknowledges all packets in flight
+		growWindow := func() protocol.PacketNumber {
+			for i := 0; i < 10; i++ {
+				SendAvailableSendWindow()
+				AckNPackets(2)
+			}
+			AckNPackets(int(bytesInFlight / protocol.DefaultTCPMSS))
+			Expect(bytesInFlight).To(BeZero())
+			return protocol.PacketNumber(sender.GetCongestionWindow() / protocol.DefaultTCPMSS)
+		}
+
+		It("doesn't decay the congestion window after an idle period shorter than the RTO", func() {
+			cwnd := growWindow()
+			clock.Advance(sender.(*cubicSender).retransmissionTimeout() / 2)
+			SendAvailableSendWindow()
+			Expect(sender.GetCongestionWindow()).To(Equal(protocol.ByteCount(cwnd) * protocol.DefaultTCPMSS))
+		})
+
+		It("halves the congestion window after an idle period longer than the RTO", func() {
+			cwnd := growWindow()
+			Expect(cwnd).To(BeNumerically(">", 2*initialCongestionWindowPackets))
+			clock.Advance(sender.(*cubicSender).retransmissionTimeout() + time.Millisecond)
+			Expect(SendAvailableSendWindow()).To(BeEquivalentTo(cwnd / 2))
+			Expect(sender.GetCongestionWindow()).To(Equal(protocol.ByteCount(cwnd/2) * protocol.DefaultTCPMSS))
+			Expect(sender.(*cubicSender).InSlowStart()).To(BeTrue())
+		})
+
+		It("halves the congestion window for every RTO, but not below the initial window", func() {
+			cwnd := growWindow()
+			clock.Advance(10 * sender.(*cubicSender).retransmissionTimeout())
+			SendAvailableSendWindow()
+			Expect(cwnd / 8).To(BeNumerically("<", initialCongestionWindowPackets))
+			Expect(sender.GetCongestionWindow()).To(Equal(defaultWindowTCP))
+		})
+
+		It("raises the slow start threshold, such that the window grows back quickly", func() {
+			growWindow()
+			// exit slow start
+			SendAvailableSendWindow()
+			LoseNPackets(1)
+			AckNPackets(int(bytesInFlight / protocol.DefaultTCPMSS))
+			Expect(sender.(*cubicSender).InSlowStart()).To(BeFalse())
+			// the slow start threshold is smaller than 3/4 of the congestion window
+			sender.(*cubicSender).slowstartThreshold = sender.(*cubicSender).congestionWindow / 2
+			cwnd := sender.(*cubicSender).congestionWindow
+			clock.Advance(10 * sender.(*cubicSender).retransmissionTimeout())
+			SendAvailableSendWindow()
+			Expect(sender.GetCongestionWindow()).To(Equal(defaultWindowTCP))
+			Expect(sender.SlowstartThreshold()).To(Equal(3 * cwnd / 4))
+			Expect(sender.(*cubicSender).InSlowStart()).To(BeTrue())
+		})
+
+		It("doesn't decay the congestion window when the sender is application-limited, but not idle", func() {
+			cwnd := growWindow()
+			rto := sender.(*cubicSender).retransmissionTimeout()
+			// send a single packet every RTT, for 10 RTOs
+			bytesInFlight += protocol.DefaultTCPMSS
+			sender.OnPacketSent(clock.Now(), bytesInFlight, packetNumber, protocol.DefaultTCPMSS, true)
+			packetNumber++
+			for start := clock.Now(); clock.Now().Sub(start) < 10*rto; {
+				clock.Advance(rttStats.SmoothedRTT())
+				bytesInFlight += protocol.DefaultTCPMSS
+				sender.OnPacketSent(clock.Now(), bytesInFlight, packetNumber, protocol.DefaultTCPMSS, true)
+				packetNumber++
+				AckNPackets(1)
+			}
+			Expect(sender.GetCongestionWindow()).To(Equal(protocol.ByteCount(cwnd) * protocol.DefaultTCPMSS))
+		})
+
+		It("doesn't decay the congestion window without an RTT estimate", func() {
+			SendAvailableSendWindow()
+			LoseNPackets(int(bytesInFlight / protocol.DefaultTCPMSS))
+			Expect(rttStats.SmoothedRTT()).To(BeZero())
+			cwnd := sender.GetCongestionWindow()
+			clock.Advance(time.Hour)
+			SendAvailableSendWindow()
+			Expect(sender.GetCongestionWindow()).To(Equal(cwnd))
+		})
 	})
 })
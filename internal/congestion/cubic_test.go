@@ -109,4 +109,50 @@ var _ = Describe("Cubic", func() {
 		expected_cwnd = 422
 		Expect(current_cwnd).To(Equal(expected_cwnd))
 	})
+
+	It("doesn't shift a new epoch by an app-limited period that started before it", func() {
+		rtt_min := 100 * time.Millisecond
+		referenceClock := mockClock{}
+		reference := NewCubic(&referenceClock)
+		current_cwnd := protocol.PacketNumber(422)
+		clock.Advance(time.Millisecond)
+		referenceClock.Advance(time.Millisecond)
+		Expect(cubic.CongestionWindowAfterAck(current_cwnd, rtt_min)).To(Equal(reference.CongestionWindowAfterAck(current_cwnd, rtt_min)))
+		current_cwnd = cubic.CongestionWindowAfterPacketLoss(current_cwnd)
+		Expect(reference.CongestionWindowAfterPacketLoss(422)).To(Equal(current_cwnd))
+		// The sender is app-limited for 10 seconds, before the first ACK of the new epoch arrives.
+		cubic.OnApplicationLimited()
+		clock.Advance(10 * time.Second)
+		referenceClock.Advance(10 * time.Second)
+		reference_cwnd := current_cwnd
+		for i := 0; i < 40; i++ {
+			current_cwnd = cubic.CongestionWindowAfterAck(current_cwnd, rtt_min)
+			reference_cwnd = reference.CongestionWindowAfterAck(reference_cwnd, rtt_min)
+			Expect(current_cwnd).To(Equal(reference_cwnd))
+			clock.Advance(100 * time.Millisecond)
+			referenceClock.Advance(100 * time.Millisecond)
+		}
+	})
+
+	It("grows back towards the window used before the idle period after a decay", func() {
+		rtt_min := 100 * time.Millisecond
+		clock.Advance(time.Millisecond)
+		Expect(cubic.CongestionWindowAfterAck(100, rtt_min)).To(Equal(protocol.PacketNumber(100)))
+		// The sender is idle, and the congestion window is decayed.
+		cubic.OnApplicationLimited()
+		clock.Advance(10 * time.Second)
+		cubic.OnCongestionWindowDecay(100)
+		current_cwnd := cubic.CongestionWindowAfterAck(25, rtt_min)
+		Expect(current_cwnd).To(BeNumerically("<", 30))
+		for i := 0; i < 20; i++ {
+			clock.Advance(100 * time.Millisecond)
+			current_cwnd = cubic.CongestionWindowAfterAck(current_cwnd, rtt_min)
+		}
+		Expect(current_cwnd).To(BeNumerically("<", 100))
+		for i := 0; i < 50; i++ {
+			clock.Advance(100 * time.Millisecond)
+			current_cwnd = cubic.CongestionWindowAfterAck(current_cwnd, rtt_min)
+		}
+		Expect(current_cwnd).To(BeNumerically(">=", 100))
+	})
 })
@@ -0,0 +1,64 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+)
+
+// The fixedWindowSender uses a congestion window of a fixed size.
+// It doesn't react to packet loss or congestion, and is only intended for testing.
+type fixedWindowSender struct {
+	rttStats           *RTTStats
+	window             protocol.ByteCount
+	bandwidthEstimator *BandwidthEstimator
+}
+
+var _ SendAlgorithm = &fixedWindowSender{}
+
+// NewFixedWindowSender makes a new sender with a fixed congestion window
+func NewFixedWindowSender(rttStats *RTTStats, window protocol.ByteCount) SendAlgorithm {
+	return &fixedWindowSender{
+		rttStats:           rttStats,
+		window:             utils.MaxByteCount(window, protocol.DefaultTCPMSS),
+		bandwidthEstimator: NewBandwidthEstimator(),
+	}
+}
+
+// PacingRate paces the packets at 1.25 times the congestion window per RTT
+func (s *fixedWindowSender) PacingRate(protocol.ByteCount) Bandwidth {
+	srtt := s.rttStats.SmoothedRTT()
+	if srtt == 0 {
+		return 0
+	}
+	return BandwidthFromDelta(s.window, srtt) * 5 / 4
+}
+
+func (s *fixedWindowSender) OnPacketSent(_ time.Time, _ protocol.ByteCount, _ protocol.PacketNumber, _ protocol.ByteCount, isRetransmittable bool) bool {
+	return isRetransmittable
+}
+
+func (s *fixedWindowSender) GetCongestionWindow() protocol.ByteCount {
+	return s.window
+}
+
+func (s *fixedWindowSender) MaybeExitSlowStart() {}
+
+func (s *fixedWindowSender) OnBandwidthSample(sample BandwidthSample) {
+	s.bandwidthEstimator.OnBandwidthSample(sample)
+}
+
+func (s *fixedWindowSender) OnPacketAcked(_ protocol.PacketNumber, _, _ protocol.ByteCount) {}
+
+func (s *fixedWindowSender) OnPacketLost(_ protocol.PacketNumber, _, _ protocol.ByteCount) {}
+
+func (s *fixedWindowSender) OnSpuriousLoss(protocol.PacketNumber) {}
+
+func (s *fixedWindowSender) OnCongestionExperienced(protocol.PacketNumber, protocol.ByteCount) {}
+
+func (s *fixedWindowSender) OnPersistentCongestion() {}
+
+func (s *fixedWindowSender) BandwidthEstimate() Bandwidth {
+	return s.bandwidthEstimator.BandwidthEstimate()
+}
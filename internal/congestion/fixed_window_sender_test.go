@@ -0,0 +1,51 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fixed window sender", func() {
+	var (
+		sender   SendAlgorithm
+		rttStats *RTTStats
+	)
+
+	BeforeEach(func() {
+		rttStats = NewRTTStats()
+		sender = NewFixedWindowSender(rttStats, 10*protocol.DefaultTCPMSS)
+	})
+
+	It("has a fixed congestion window", func() {
+		Expect(sender.GetCongestionWindow()).To(Equal(10 * protocol.DefaultTCPMSS))
+		for i := 1; i <= 100; i++ {
+			Expect(sender.OnPacketSent(time.Now(), 0, protocol.PacketNumber(i), protocol.DefaultTCPMSS, true)).To(BeTrue())
+			sender.OnPacketAcked(protocol.PacketNumber(i), protocol.DefaultTCPMSS, 0)
+		}
+		Expect(sender.GetCongestionWindow()).To(Equal(10 * protocol.DefaultTCPMSS))
+		sender.OnPacketLost(101, protocol.DefaultTCPMSS, 0)
+		sender.OnCongestionExperienced(101, 0)
+		sender.OnPersistentCongestion()
+		Expect(sender.GetCongestionWindow()).To(Equal(10 * protocol.DefaultTCPMSS))
+	})
+
+	It("uses a congestion window of at least one packet", func() {
+		sender = NewFixedWindowSender(rttStats, 100)
+		Expect(sender.GetCongestionWindow()).To(Equal(protocol.DefaultTCPMSS))
+	})
+
+	It("doesn't count non-retransmittable packets as bytes in flight", func() {
+		Expect(sender.OnPacketSent(time.Now(), 0, 1, protocol.DefaultTCPMSS, false)).To(BeFalse())
+	})
+
+	It("paces packets", func() {
+		Expect(sender.PacingRate(0)).To(BeZero())
+		rttStats.UpdateRTT(100*time.Millisecond, 0, time.Time{})
+		// 10 packets are sent within 4/5 of an RTT
+		Expect(sender.PacingRate(0)).To(Equal(BandwidthFromDelta(10*protocol.DefaultTCPMSS, 80*time.Millisecond)))
+	})
+})
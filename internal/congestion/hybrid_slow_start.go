@@ -30,6 +30,8 @@ type HybridSlowStart struct {
 	hystartFound         bool
 }
 
+var _ SlowStart = &HybridSlowStart{}
+
 // StartReceiveRound is called for the start of each receive round (burst) in the slow start phase.
 func (s *HybridSlowStart) StartReceiveRound(lastSent protocol.PacketNumber) {
 	s.endPacketNumber = lastSent
@@ -99,6 +101,11 @@ func (s *HybridSlowStart) OnPacketAcked(ackedPacketNumber protocol.PacketNumber)
 	}
 }
 
+// GrowthDivisor returns 1, the congestion window grows by one packet for every packet acknowledged
+func (s *HybridSlowStart) GrowthDivisor() int {
+	return 1
+}
+
 // Started returns true if started
 func (s *HybridSlowStart) Started() bool {
 	return s.started
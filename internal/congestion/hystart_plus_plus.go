@@ -0,0 +1,195 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+)
+
+// Default values of the HyStart++ parameters, as recommended by RFC 9406.
+const (
+	hyStartPlusPlusMinRTTThreshold  = 4 * time.Millisecond
+	hyStartPlusPlusMaxRTTThreshold  = 16 * time.Millisecond
+	hyStartPlusPlusMinRTTDivisor    = 8
+	hyStartPlusPlusNumRTTSamples    = 8
+	hyStartPlusPlusCSSGrowthDivisor = 4
+	hyStartPlusPlusCSSRounds        = 5
+)
+
+// SlowStartConfig configures the slow start of the Cubic and Reno senders.
+// Zero values are replaced by the defaults.
+type SlowStartConfig struct {
+	// UseHybridSlowStart selects the HyStart algorithm used by earlier versions, which exits slow start as soon as it detects an RTT increase.
+	// The other fields only apply to HyStart++.
+	UseHybridSlowStart bool
+	// MinRTTThreshold and MaxRTTThreshold clamp the RTT increase that is treated as a sign of congestion.
+	// The threshold is 1/8 of the minimum RTT of the last round. Defaults to 4ms and 16ms.
+	MinRTTThreshold time.Duration
+	MaxRTTThreshold time.Duration
+	// NumRTTSamples is the number of RTT samples needed in a round to detect an RTT increase. Defaults to 8.
+	NumRTTSamples int
+	// CSSGrowthDivisor is the factor by which conservative slow start grows slower than slow start. Defaults to 4.
+	CSSGrowthDivisor int
+	// CSSRounds is the number of rounds spent in conservative slow start before exiting slow start. Defaults to 5.
+	CSSRounds int
+}
+
+// NewSlowStart creates the slow start algorithm for the config.
+// If config is nil, HyStart++ is used with the default parameters.
+func NewSlowStart(config *SlowStartConfig) SlowStart {
+	if config != nil && config.UseHybridSlowStart {
+		return &HybridSlowStart{}
+	}
+	return NewHyStartPlusPlus(config)
+}
+
+// HyStartPlusPlus implements HyStart++ (RFC 9406).
+// When the RTT increases during slow start, it doesn't exit slow start right away, but enters conservative slow start (CSS),
+// in which the congestion window grows slower. If the RTT decreases again, the increase was caused by jitter, and it goes back to slow start.
+// Otherwise it exits slow start after a number of rounds in CSS.
+type HyStartPlusPlus struct {
+	minRTTThreshold  time.Duration
+	maxRTTThreshold  time.Duration
+	numRTTSamples    int
+	cssGrowthDivisor int
+	cssRounds        int
+
+	lastSentPacketNumber protocol.PacketNumber
+	// the round ends when this packet is acknowledged
+	endPacketNumber protocol.PacketNumber
+	started         bool
+
+	// The minimum RTTs of the last and the current round. 0 if there is no sample.
+	lastRoundMinRTT    time.Duration
+	currentRoundMinRTT time.Duration
+	rttSampleCount     int
+
+	// The minimum RTT of the round in which CSS was entered. 0 if not in CSS.
+	cssBaselineMinRTT time.Duration
+	// the number of rounds completed in CSS
+	cssRoundCount int
+}
+
+var _ SlowStart = &HyStartPlusPlus{}
+
+// NewHyStartPlusPlus creates a new HyStart++ instance.
+// If config is nil, the default parameters are used.
+func NewHyStartPlusPlus(config *SlowStartConfig) *HyStartPlusPlus {
+	if config == nil {
+		config = &SlowStartConfig{}
+	}
+	s := &HyStartPlusPlus{
+		minRTTThreshold:  config.MinRTTThreshold,
+		maxRTTThreshold:  config.MaxRTTThreshold,
+		numRTTSamples:    config.NumRTTSamples,
+		cssGrowthDivisor: config.CSSGrowthDivisor,
+		cssRounds:        config.CSSRounds,
+	}
+	if s.minRTTThreshold == 0 {
+		s.minRTTThreshold = hyStartPlusPlusMinRTTThreshold
+	}
+	if s.maxRTTThreshold == 0 {
+		s.maxRTTThreshold = utils.MaxDuration(hyStartPlusPlusMaxRTTThreshold, s.minRTTThreshold)
+	}
+	if s.numRTTSamples == 0 {
+		s.numRTTSamples = hyStartPlusPlusNumRTTSamples
+	}
+	if s.cssGrowthDivisor == 0 {
+		s.cssGrowthDivisor = hyStartPlusPlusCSSGrowthDivisor
+	}
+	if s.cssRounds == 0 {
+		s.cssRounds = hyStartPlusPlusCSSRounds
+	}
+	return s
+}
+
+// StartReceiveRound is called for the start of each round in the slow start phase.
+// The round ends when lastSent is acknowledged.
+func (s *HyStartPlusPlus) StartReceiveRound(lastSent protocol.PacketNumber) {
+	s.endPacketNumber = lastSent
+	s.lastRoundMinRTT = s.currentRoundMinRTT
+	s.currentRoundMinRTT = 0
+	s.rttSampleCount = 0
+	s.started = true
+}
+
+// ShouldExitSlowStart should be called on every new ack frame, since a new RTT measurement can be made then.
+// minRTT and congestionWindow are not used by HyStart++.
+func (s *HyStartPlusPlus) ShouldExitSlowStart(latestRTT time.Duration, _ time.Duration, _ protocol.ByteCount) bool {
+	if !s.started {
+		s.StartReceiveRound(s.lastSentPacketNumber)
+	}
+	if s.InConservativeSlowStart() && s.cssRoundCount >= s.cssRounds {
+		return true
+	}
+	if latestRTT == 0 {
+		return false
+	}
+	if s.currentRoundMinRTT == 0 || latestRTT < s.currentRoundMinRTT {
+		s.currentRoundMinRTT = latestRTT
+	}
+	s.rttSampleCount++
+	if s.rttSampleCount < s.numRTTSamples || s.lastRoundMinRTT == 0 {
+		return false
+	}
+	if s.InConservativeSlowStart() {
+		// The RTT increase was spurious, e.g. caused by jitter. Go back to slow start.
+		if s.currentRoundMinRTT < s.cssBaselineMinRTT {
+			s.cssBaselineMinRTT = 0
+			s.cssRoundCount = 0
+		}
+		return false
+	}
+	threshold := utils.MaxDuration(s.minRTTThreshold, utils.MinDuration(s.lastRoundMinRTT/hyStartPlusPlusMinRTTDivisor, s.maxRTTThreshold))
+	if s.currentRoundMinRTT >= s.lastRoundMinRTT+threshold {
+		s.cssBaselineMinRTT = s.currentRoundMinRTT
+		s.cssRoundCount = 0
+	}
+	return false
+}
+
+// OnPacketSent is called when a packet was sent
+func (s *HyStartPlusPlus) OnPacketSent(packetNumber protocol.PacketNumber) {
+	s.lastSentPacketNumber = packetNumber
+}
+
+// OnPacketAcked is called after ShouldExitSlowStart. It ends the round when its last packet is acknowledged.
+// The next round is started on the next incoming ack.
+func (s *HyStartPlusPlus) OnPacketAcked(ackedPacketNumber protocol.PacketNumber) {
+	if !s.started || ackedPacketNumber < s.endPacketNumber {
+		return
+	}
+	s.started = false
+	if s.InConservativeSlowStart() {
+		s.cssRoundCount++
+	}
+}
+
+// GrowthDivisor returns the factor by which the congestion window grows slower than in slow start.
+func (s *HyStartPlusPlus) GrowthDivisor() int {
+	if s.InConservativeSlowStart() {
+		return s.cssGrowthDivisor
+	}
+	return 1
+}
+
+// InConservativeSlowStart returns true if an RTT increase was detected, and the congestion window grows conservatively
+func (s *HyStartPlusPlus) InConservativeSlowStart() bool {
+	return s.cssBaselineMinRTT != 0
+}
+
+// Started returns true if started
+func (s *HyStartPlusPlus) Started() bool {
+	return s.started
+}
+
+// Restart the slow start phase
+func (s *HyStartPlusPlus) Restart() {
+	s.started = false
+	s.lastRoundMinRTT = 0
+	s.currentRoundMinRTT = 0
+	s.rttSampleCount = 0
+	s.cssBaselineMinRTT = 0
+	s.cssRoundCount = 0
+}
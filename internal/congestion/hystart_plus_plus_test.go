@@ -0,0 +1,231 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// ms converts a sequence of RTTs in milliseconds
+func ms(rtts ...int) []time.Duration {
+	durations := make([]time.Duration, len(rtts))
+	for i, rtt := range rtts {
+		durations[i] = time.Duration(rtt) * time.Millisecond
+	}
+	return durations
+}
+
+// A download over a link with a bottleneck buffer.
+// The queue builds up from the 4th round on, and the RTT keeps increasing.
+var rttSequenceQueueBuildup = [][]time.Duration{
+	ms(41, 40, 42, 40, 43, 41, 40, 42, 41, 40),
+	ms(40, 42, 41, 40, 41, 43, 40, 42, 40, 41),
+	ms(41, 40, 42, 41, 40, 42, 43, 40, 41, 42),
+	ms(47, 48, 47, 49, 48, 50, 48, 49, 47, 48),
+	ms(52, 53, 52, 54, 53, 55, 53, 52, 54, 53),
+	ms(57, 58, 57, 59, 58, 60, 58, 57, 59, 58),
+	ms(62, 63, 62, 64, 63, 65, 63, 62, 64, 63),
+	ms(67, 68, 67, 69, 68, 70, 68, 67, 69, 68),
+	ms(72, 73, 72, 74, 73, 75, 73, 72, 74, 73),
+	ms(77, 78, 77, 79, 78, 80, 78, 77, 79, 78),
+}
+
+// A download over a Wi-Fi link.
+// In the 4th round, all RTT samples are increased by the frame aggregation of the access point.
+// The RTT goes back to normal in the 5th round.
+var rttSequenceWiFiJitter = [][]time.Duration{
+	ms(31, 30, 34, 30, 32, 36, 30, 31, 33, 30),
+	ms(30, 33, 31, 35, 30, 32, 30, 34, 31, 30),
+	ms(32, 30, 31, 30, 36, 30, 33, 31, 30, 32),
+	ms(44, 41, 47, 42, 45, 41, 48, 43, 42, 46),
+	ms(36, 33, 30, 35, 31, 30, 34, 32, 30, 31),
+	ms(30, 32, 31, 30, 35, 30, 33, 31, 30, 34),
+	ms(31, 30, 33, 30, 32, 36, 30, 31, 32, 30),
+	ms(30, 34, 31, 30, 33, 30, 32, 35, 30, 31),
+}
+
+var _ = Describe("HyStart++", func() {
+	var (
+		slowStart *HyStartPlusPlus
+		lastSent  protocol.PacketNumber
+		minRTT    time.Duration
+	)
+
+	BeforeEach(func() {
+		slowStart = NewHyStartPlusPlus(nil)
+		lastSent = 0
+		minRTT = 0
+	})
+
+	// runRound sends a packet for every RTT sample, and then acknowledges them.
+	// It returns true if the slow start algorithm exits slow start in this round.
+	runRound := func(s SlowStart, rtts []time.Duration) bool {
+		first := lastSent + 1
+		for range rtts {
+			lastSent++
+			s.OnPacketSent(lastSent)
+		}
+		for i, rtt := range rtts {
+			if minRTT == 0 || rtt < minRTT {
+				minRTT = rtt
+			}
+			if s.ShouldExitSlowStart(rtt, minRTT, 100) {
+				return true
+			}
+			s.OnPacketAcked(first + protocol.PacketNumber(i))
+		}
+		return false
+	}
+
+	// runRounds runs all rounds of the RTT sequence.
+	// It returns the index of the round in which slow start was exited, or -1.
+	runRounds := func(s SlowStart, rounds [][]time.Duration) int {
+		for i, rtts := range rounds {
+			if runRound(s, rtts) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	It("uses the default parameters", func() {
+		Expect(slowStart.minRTTThreshold).To(Equal(4 * time.Millisecond))
+		Expect(slowStart.maxRTTThreshold).To(Equal(16 * time.Millisecond))
+		Expect(slowStart.numRTTSamples).To(Equal(8))
+		Expect(slowStart.cssGrowthDivisor).To(Equal(4))
+		Expect(slowStart.cssRounds).To(Equal(5))
+	})
+
+	It("uses the parameters from the config", func() {
+		slowStart = NewHyStartPlusPlus(&SlowStartConfig{
+			MinRTTThreshold:  2 * time.Millisecond,
+			MaxRTTThreshold:  10 * time.Millisecond,
+			NumRTTSamples:    4,
+			CSSGrowthDivisor: 2,
+			CSSRounds:        3,
+		})
+		Expect(slowStart.minRTTThreshold).To(Equal(2 * time.Millisecond))
+		Expect(slowStart.maxRTTThreshold).To(Equal(10 * time.Millisecond))
+		Expect(slowStart.numRTTSamples).To(Equal(4))
+		Expect(slowStart.cssGrowthDivisor).To(Equal(2))
+		Expect(slowStart.cssRounds).To(Equal(3))
+	})
+
+	It("doesn't use a maximum threshold smaller than the minimum threshold", func() {
+		slowStart = NewHyStartPlusPlus(&SlowStartConfig{MinRTTThreshold: 20 * time.Millisecond})
+		Expect(slowStart.maxRTTThreshold).To(Equal(20 * time.Millisecond))
+	})
+
+	It("creates the slow start algorithm selected by the config", func() {
+		Expect(NewSlowStart(nil)).To(BeAssignableToTypeOf(&HyStartPlusPlus{}))
+		Expect(NewSlowStart(&SlowStartConfig{})).To(BeAssignableToTypeOf(&HyStartPlusPlus{}))
+		Expect(NewSlowStart(&SlowStartConfig{UseHybridSlowStart: true})).To(BeAssignableToTypeOf(&HybridSlowStart{}))
+	})
+
+	It("ends a round when the last packet sent at its start is acknowledged", func() {
+		for i := 1; i <= 10; i++ {
+			slowStart.OnPacketSent(protocol.PacketNumber(i))
+		}
+		Expect(slowStart.ShouldExitSlowStart(40*time.Millisecond, 40*time.Millisecond, 100)).To(BeFalse())
+		Expect(slowStart.Started()).To(BeTrue())
+		for i := 1; i < 10; i++ {
+			slowStart.OnPacketAcked(protocol.PacketNumber(i))
+			Expect(slowStart.Started()).To(BeTrue())
+		}
+		slowStart.OnPacketAcked(10)
+		Expect(slowStart.Started()).To(BeFalse())
+	})
+
+	It("doesn't detect an RTT increase in the first round", func() {
+		Expect(runRound(slowStart, ms(40, 40, 40, 40, 40, 40, 40, 40, 40, 40))).To(BeFalse())
+		Expect(slowStart.InConservativeSlowStart()).To(BeFalse())
+	})
+
+	It("needs enough RTT samples to detect an RTT increase", func() {
+		Expect(runRound(slowStart, ms(40, 40, 40, 40, 40, 40, 40, 40))).To(BeFalse())
+		Expect(runRound(slowStart, ms(60, 60, 60, 60, 60, 60, 60))).To(BeFalse())
+		Expect(slowStart.InConservativeSlowStart()).To(BeFalse())
+		Expect(runRound(slowStart, ms(80, 80, 80, 80, 80, 80, 80, 80))).To(BeFalse())
+		Expect(slowStart.InConservativeSlowStart()).To(BeTrue())
+	})
+
+	It("uses the minimum threshold for small RTTs", func() {
+		Expect(runRound(slowStart, ms(10, 10, 10, 10, 10, 10, 10, 10))).To(BeFalse())
+		// 1/8 of 10ms is smaller than 4ms
+		Expect(runRound(slowStart, ms(13, 13, 13, 13, 13, 13, 13, 13))).To(BeFalse())
+		Expect(slowStart.InConservativeSlowStart()).To(BeFalse())
+		Expect(runRound(slowStart, ms(17, 17, 17, 17, 17, 17, 17, 17))).To(BeFalse())
+		Expect(slowStart.InConservativeSlowStart()).To(BeTrue())
+	})
+
+	It("uses the maximum threshold for large RTTs", func() {
+		Expect(runRound(slowStart, ms(400, 400, 400, 400, 400, 400, 400, 400))).To(BeFalse())
+		// 1/8 of 400ms is larger than 16ms
+		Expect(runRound(slowStart, ms(416, 416, 416, 416, 416, 416, 416, 416))).To(BeFalse())
+		Expect(slowStart.InConservativeSlowStart()).To(BeTrue())
+	})
+
+	It("slows down the growth of the congestion window in conservative slow start", func() {
+		Expect(slowStart.GrowthDivisor()).To(Equal(1))
+		Expect(runRound(slowStart, ms(40, 40, 40, 40, 40, 40, 40, 40))).To(BeFalse())
+		Expect(runRound(slowStart, ms(50, 50, 50, 50, 50, 50, 50, 50))).To(BeFalse())
+		Expect(slowStart.GrowthDivisor()).To(Equal(4))
+	})
+
+	It("enters conservative slow start, and exits slow start if the RTT keeps increasing", func() {
+		Expect(runRounds(slowStart, rttSequenceQueueBuildup)).To(Equal(8))
+	})
+
+	It("exits slow start after the configured number of rounds in conservative slow start", func() {
+		slowStart = NewHyStartPlusPlus(&SlowStartConfig{CSSRounds: 2})
+		Expect(runRounds(slowStart, rttSequenceQueueBuildup)).To(Equal(5))
+	})
+
+	It("goes back to slow start if the RTT increase was caused by jitter", func() {
+		for i, rtts := range rttSequenceWiFiJitter {
+			Expect(runRound(slowStart, rtts)).To(BeFalse())
+			switch i {
+			case 3:
+				Expect(slowStart.InConservativeSlowStart()).To(BeTrue())
+				Expect(slowStart.GrowthDivisor()).To(Equal(4))
+			case 4:
+				Expect(slowStart.InConservativeSlowStart()).To(BeFalse())
+				Expect(slowStart.GrowthDivisor()).To(Equal(1))
+			}
+		}
+		Expect(slowStart.InConservativeSlowStart()).To(BeFalse())
+	})
+
+	It("detects an RTT increase after going back to slow start", func() {
+		rounds := append(rttSequenceWiFiJitter[:5:5], rttSequenceQueueBuildup[3:]...)
+		Expect(runRounds(slowStart, rounds)).To(Equal(10))
+	})
+
+	It("resets the state on restart", func() {
+		Expect(runRound(slowStart, ms(40, 40, 40, 40, 40, 40, 40, 40))).To(BeFalse())
+		Expect(runRound(slowStart, ms(50, 50, 50, 50, 50, 50, 50, 50))).To(BeFalse())
+		Expect(slowStart.InConservativeSlowStart()).To(BeTrue())
+		slowStart.Restart()
+		Expect(slowStart.Started()).To(BeFalse())
+		Expect(slowStart.InConservativeSlowStart()).To(BeFalse())
+		// the first round after the restart doesn't compare to the RTT before the restart
+		Expect(runRound(slowStart, ms(60, 60, 60, 60, 60, 60, 60, 60))).To(BeFalse())
+		Expect(slowStart.InConservativeSlowStart()).To(BeFalse())
+	})
+
+	Context("compared to hybrid slow start", func() {
+		It("exits slow start on the Wi-Fi jitter, which HyStart++ ignores", func() {
+			Expect(runRounds(&HybridSlowStart{}, rttSequenceWiFiJitter)).To(Equal(3))
+			minRTT = 0
+			Expect(runRounds(slowStart, rttSequenceWiFiJitter)).To(Equal(-1))
+		})
+
+		It("exits slow start earlier than HyStart++ when the queue builds up", func() {
+			Expect(runRounds(&HybridSlowStart{}, rttSequenceQueueBuildup)).To(Equal(3))
+			minRTT = 0
+			Expect(runRounds(slowStart, rttSequenceQueueBuildup)).To(Equal(8))
+		})
+	})
+})
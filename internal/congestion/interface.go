@@ -8,29 +8,65 @@ import (
 
 // A SendAlgorithm performs congestion control and calculates the congestion window
 type SendAlgorithm interface {
-	TimeUntilSend(bytesInFlight protocol.ByteCount) time.Duration
+	// PacingRate returns the rate at which packets are sent.
+	// It is used to set the rate of the pacer. If it returns 0, packets are not paced.
+	PacingRate(bytesInFlight protocol.ByteCount) Bandwidth
+	// OnPacketSent is called for every packet that is sent.
+	// bytesInFlight includes the packet that was sent, if it is retransmittable.
+	// It returns if the packet counts towards the bytes in flight.
 	OnPacketSent(sentTime time.Time, bytesInFlight protocol.ByteCount, packetNumber protocol.PacketNumber, bytes protocol.ByteCount, isRetransmittable bool) bool
+	// GetCongestionWindow returns the congestion window.
+	// No more packets are sent if the bytes in flight exceed the congestion window.
 	GetCongestionWindow() protocol.ByteCount
+	// MaybeExitSlowStart is called when an ACK frame was received that updated the RTT.
 	MaybeExitSlowStart()
+	// OnBandwidthSample is called with the bandwidth sample for a packet, right before OnPacketAcked is called for that packet.
+	OnBandwidthSample(BandwidthSample)
+	// OnPacketAcked is called for every packet that is acknowledged.
 	OnPacketAcked(number protocol.PacketNumber, ackedBytes protocol.ByteCount, bytesInFlight protocol.ByteCount)
+	// OnPacketLost is called for every packet that is declared lost.
 	OnPacketLost(number protocol.PacketNumber, lostBytes protocol.ByteCount, bytesInFlight protocol.ByteCount)
-	SetNumEmulatedConnections(n int)
-	OnRetransmissionTimeout(packetsRetransmitted bool)
-	OnConnectionMigration()
-	RetransmissionDelay() time.Duration
+	// OnSpuriousLoss is called when a packet that was declared lost is acknowledged later.
+	// The loss was caused by reordering, and the reaction to it can be undone.
+	OnSpuriousLoss(number protocol.PacketNumber)
+	// OnCongestionExperienced is called when the peer reports CE-marked packets
+	OnCongestionExperienced(largestAcked protocol.PacketNumber, bytesInFlight protocol.ByteCount)
+	// OnPersistentCongestion is called when all packets sent during a period longer than the persistent congestion duration were lost.
+	// The congestion window should then be reduced to the minimum.
+	OnPersistentCongestion()
+	// BandwidthEstimate returns the estimated bandwidth of the connection.
+	// It returns 0 if the bandwidth is not known yet.
+	BandwidthEstimate() Bandwidth
+}
 
-	// Experiments
-	SetSlowStartLargeReduction(enabled bool)
+// A SlowStart algorithm decides when the Cubic and Reno senders exit slow start
+type SlowStart interface {
+	// OnPacketSent is called for every packet sent
+	OnPacketSent(packetNumber protocol.PacketNumber)
+	// OnPacketAcked is called for every packet acknowledged during slow start, after ShouldExitSlowStart
+	OnPacketAcked(ackedPacketNumber protocol.PacketNumber)
+	// ShouldExitSlowStart is called for every ACK frame that updated the RTT.
+	// congestionWindow is the congestion window in packets.
+	ShouldExitSlowStart(latestRTT time.Duration, minRTT time.Duration, congestionWindow protocol.ByteCount) bool
+	// GrowthDivisor is the number of acknowledged packets that increase the congestion window by one packet.
+	GrowthDivisor() int
+	Started() bool
+	// Restart is called when the sender enters slow start again
+	Restart()
 }
 
 // SendAlgorithmWithDebugInfo adds some debug functions to SendAlgorithm
 type SendAlgorithmWithDebugInfo interface {
 	SendAlgorithm
-	BandwidthEstimate() Bandwidth
+	SetNumEmulatedConnections(n int)
+	OnConnectionMigration()
+
+	// Experiments
+	SetSlowStartLargeReduction(enabled bool)
 
 	// Stuff only used in testing
 
-	HybridSlowStart() *HybridSlowStart
+	SlowStart() SlowStart
 	SlowstartThreshold() protocol.PacketNumber
 	RenoBeta() float32
 	InRecovery() bool
@@ -0,0 +1,183 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+)
+
+// LEDBAT (Low Extra Delay Background Transport, RFC 6817) is a scavenger congestion controller for background transfers.
+// It measures the queuing delay as the difference between the current RTT and the base RTT (the minimum RTT of the connection).
+// The congestion window grows as long as the queuing delay is below the target, and shrinks when it exceeds the target.
+// Since loss-based congestion controllers only back off when the queue overflows, a LEDBAT connection yields to them.
+
+const (
+	// ledbatDefaultTarget is the default target queuing delay.
+	// RFC 6817 requires it to be 100ms or less. LEDBAT++ (draft-irtf-iccrg-ledbat-plus-plus) recommends 60ms.
+	ledbatDefaultTarget = 60 * time.Millisecond
+	// ledbatGain is the number of packets the congestion window grows by per RTT, if there's no queuing delay at all.
+	ledbatGain = 1
+	// ledbatAllowedIncrease is the number of packets the congestion window may exceed the bytes in flight by.
+	ledbatAllowedIncrease = 1
+	// ledbatCurrentDelayFilter is the number of RTT samples the current delay is the minimum of.
+	// This filters out samples that were increased by delayed ACKs.
+	ledbatCurrentDelayFilter = 4
+	// ledbatMinCongestionWindow is the minimum congestion window
+	ledbatMinCongestionWindow = 2 * protocol.DefaultTCPMSS
+)
+
+type ledbatSender struct {
+	rttStats           *RTTStats
+	bandwidthEstimator *BandwidthEstimator
+
+	// target is the queuing delay that the sender aims for
+	target time.Duration
+
+	congestionWindow    protocol.ByteCount
+	maxCongestionWindow protocol.ByteCount
+	// In slow start, the congestion window grows by one packet for every packet acknowledged,
+	// until the queuing delay exceeds 3/4 of the target, or a packet is lost.
+	inSlowStart bool
+
+	// The most recent RTT samples, used to calculate the current delay.
+	delaySamples    [ledbatCurrentDelayFilter]time.Duration
+	numDelaySamples int
+
+	largestSentPacketNumber protocol.PacketNumber
+	// Losses of packets sent before the last cutback are part of the same loss event.
+	largestSentAtLastCutback protocol.PacketNumber
+}
+
+var _ SendAlgorithm = &ledbatSender{}
+
+// NewLEDBATSender makes a new LEDBAT sender.
+// If target is 0, the default target queuing delay is used.
+func NewLEDBATSender(rttStats *RTTStats, target time.Duration, initialCongestionWindow, initialMaxCongestionWindow protocol.PacketNumber) SendAlgorithm {
+	if target == 0 {
+		target = ledbatDefaultTarget
+	}
+	return &ledbatSender{
+		rttStats:            rttStats,
+		bandwidthEstimator:  NewBandwidthEstimator(),
+		target:              target,
+		congestionWindow:    protocol.ByteCount(initialCongestionWindow) * protocol.DefaultTCPMSS,
+		maxCongestionWindow: protocol.ByteCount(initialMaxCongestionWindow) * protocol.DefaultTCPMSS,
+		inSlowStart:         true,
+	}
+}
+
+// PacingRate returns the pacing rate.
+// It is 2*cwnd/rtt in slow start, and 1.25*cwnd/rtt afterwards.
+func (l *ledbatSender) PacingRate(protocol.ByteCount) Bandwidth {
+	srtt := l.rttStats.SmoothedRTT()
+	if srtt == 0 {
+		return 0
+	}
+	rate := BandwidthFromDelta(l.congestionWindow, srtt)
+	if l.inSlowStart {
+		return 2 * rate
+	}
+	return rate * 5 / 4
+}
+
+func (l *ledbatSender) OnPacketSent(_ time.Time, _ protocol.ByteCount, packetNumber protocol.PacketNumber, _ protocol.ByteCount, isRetransmittable bool) bool {
+	if !isRetransmittable {
+		return false
+	}
+	l.largestSentPacketNumber = packetNumber
+	return true
+}
+
+func (l *ledbatSender) GetCongestionWindow() protocol.ByteCount {
+	return l.congestionWindow
+}
+
+// MaybeExitSlowStart records the latest RTT sample.
+// Slow start is exited when the queuing delay exceeds 3/4 of the target.
+func (l *ledbatSender) MaybeExitSlowStart() {
+	l.delaySamples[l.numDelaySamples%ledbatCurrentDelayFilter] = l.rttStats.LatestRTT()
+	l.numDelaySamples++
+	if l.inSlowStart && l.queuingDelay() > l.target*3/4 {
+		l.inSlowStart = false
+	}
+}
+
+// queuingDelay is the difference between the current delay and the base delay
+func (l *ledbatSender) queuingDelay() time.Duration {
+	baseDelay := l.rttStats.MinRTT()
+	if baseDelay == 0 || l.numDelaySamples == 0 {
+		return 0
+	}
+	currentDelay := l.delaySamples[0]
+	for i := 1; i < utils.Min(l.numDelaySamples, ledbatCurrentDelayFilter); i++ {
+		currentDelay = utils.MinDuration(currentDelay, l.delaySamples[i])
+	}
+	return utils.MaxDuration(currentDelay-baseDelay, 0)
+}
+
+func (l *ledbatSender) OnBandwidthSample(sample BandwidthSample) {
+	l.bandwidthEstimator.OnBandwidthSample(sample)
+}
+
+// OnPacketAcked adjusts the congestion window proportionally to the distance of the queuing delay from the target.
+// The congestion window is at most halved per RTT. It doesn't grow while the sender is application-limited.
+func (l *ledbatSender) OnPacketAcked(packetNumber protocol.PacketNumber, ackedBytes protocol.ByteCount, bytesInFlight protocol.ByteCount) {
+	// Like NewReno, don't increase the window while recovering from a loss.
+	if packetNumber <= l.largestSentAtLastCutback {
+		return
+	}
+	congestionWindow := l.congestionWindow
+	if l.inSlowStart {
+		congestionWindow += ackedBytes
+	} else {
+		offTarget := float64(l.target-l.queuingDelay()) / float64(l.target)
+		change := int64(ledbatGain * offTarget * float64(ackedBytes) * float64(protocol.DefaultTCPMSS) / float64(l.congestionWindow))
+		if change >= 0 {
+			congestionWindow += protocol.ByteCount(change)
+		} else {
+			congestionWindow -= utils.MinByteCount(protocol.ByteCount(-change), ackedBytes/2)
+		}
+	}
+	// The window only grows if it is used. bytesInFlight doesn't include the acknowledged packet any more.
+	if maxAllowed := bytesInFlight + ackedBytes + ledbatAllowedIncrease*protocol.DefaultTCPMSS; congestionWindow > l.congestionWindow && congestionWindow > maxAllowed {
+		congestionWindow = utils.MaxByteCount(l.congestionWindow, maxAllowed)
+	}
+	l.congestionWindow = utils.MinByteCount(utils.MaxByteCount(congestionWindow, ledbatMinCongestionWindow), l.maxCongestionWindow)
+}
+
+// OnPacketLost halves the congestion window, once per loss event
+func (l *ledbatSender) OnPacketLost(packetNumber protocol.PacketNumber, _ protocol.ByteCount, _ protocol.ByteCount) {
+	if packetNumber <= l.largestSentAtLastCutback {
+		return
+	}
+	l.reduceCongestionWindow()
+}
+
+// OnSpuriousLoss does nothing.
+// As a scavenger, LEDBAT doesn't undo the window reduction.
+func (l *ledbatSender) OnSpuriousLoss(protocol.PacketNumber) {}
+
+// OnCongestionExperienced halves the congestion window, like a loss
+func (l *ledbatSender) OnCongestionExperienced(largestAcked protocol.PacketNumber, _ protocol.ByteCount) {
+	if largestAcked <= l.largestSentAtLastCutback {
+		return
+	}
+	l.reduceCongestionWindow()
+}
+
+func (l *ledbatSender) reduceCongestionWindow() {
+	l.inSlowStart = false
+	l.congestionWindow = utils.MaxByteCount(l.congestionWindow/2, ledbatMinCongestionWindow)
+	l.largestSentAtLastCutback = l.largestSentPacketNumber
+}
+
+// OnPersistentCongestion reduces the congestion window to the minimum
+func (l *ledbatSender) OnPersistentCongestion() {
+	l.inSlowStart = false
+	l.congestionWindow = ledbatMinCongestionWindow
+}
+
+func (l *ledbatSender) BandwidthEstimate() Bandwidth {
+	return l.bandwidthEstimator.BandwidthEstimate()
+}
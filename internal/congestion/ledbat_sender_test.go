@@ -0,0 +1,247 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LEDBAT sender", func() {
+	const (
+		baseRTT = 40 * time.Millisecond
+		target  = 60 * time.Millisecond
+	)
+
+	var (
+		sender            *ledbatSender
+		rttStats          *RTTStats
+		clock             mockClock
+		bytesInFlight     protocol.ByteCount
+		packetNumber      protocol.PacketNumber
+		ackedPacketNumber protocol.PacketNumber
+	)
+
+	BeforeEach(func() {
+		clock = mockClock{}
+		rttStats = NewRTTStats()
+		sender = NewLEDBATSender(rttStats, 0, 10, 1000).(*ledbatSender)
+		bytesInFlight = 0
+		packetNumber = 0
+		ackedPacketNumber = 0
+	})
+
+	// sendWindow sends packets until the congestion window is full
+	sendWindow := func() {
+		for bytesInFlight < sender.GetCongestionWindow() {
+			packetNumber++
+			bytesInFlight += protocol.DefaultTCPMSS
+			Expect(sender.OnPacketSent(clock.Now(), bytesInFlight, packetNumber, protocol.DefaultTCPMSS, true)).To(BeTrue())
+		}
+	}
+
+	// ackPacket acknowledges the next packet, with an RTT sample
+	ackPacket := func(rtt time.Duration) {
+		rttStats.UpdateRTT(rtt, 0, clock.Now())
+		sender.MaybeExitSlowStart()
+		ackedPacketNumber++
+		bytesInFlight -= protocol.DefaultTCPMSS
+		sender.OnPacketAcked(ackedPacketNumber, protocol.DefaultTCPMSS, bytesInFlight)
+	}
+
+	// ack acknowledges n packets, without sending new packets
+	ack := func(n int, rtt time.Duration) {
+		for i := 0; i < n; i++ {
+			clock.Advance(rtt / time.Duration(n))
+			ackPacket(rtt)
+		}
+	}
+
+	// round acknowledges all packets in flight.
+	// After every ACK, the congestion window is filled with new packets.
+	round := func(rtt time.Duration) {
+		sendWindow()
+		n := int(bytesInFlight / protocol.DefaultTCPMSS)
+		for i := 0; i < n; i++ {
+			clock.Advance(rtt / time.Duration(n))
+			ackPacket(rtt)
+			sendWindow()
+		}
+	}
+
+	// exitSlowStart builds up a queuing delay above 3/4 of the target
+	exitSlowStart := func() {
+		round(baseRTT)
+		round(baseRTT + target)
+		Expect(sender.inSlowStart).To(BeFalse())
+	}
+
+	It("uses the default target", func() {
+		Expect(sender.target).To(Equal(60 * time.Millisecond))
+		Expect(NewLEDBATSender(rttStats, 25*time.Millisecond, 10, 1000).(*ledbatSender).target).To(Equal(25 * time.Millisecond))
+	})
+
+	It("doesn't count non-retransmittable packets", func() {
+		Expect(sender.OnPacketSent(clock.Now(), 0, 1, protocol.DefaultTCPMSS, false)).To(BeFalse())
+	})
+
+	It("doubles the congestion window every RTT in slow start", func() {
+		Expect(sender.GetCongestionWindow()).To(Equal(10 * protocol.DefaultTCPMSS))
+		round(baseRTT)
+		Expect(sender.GetCongestionWindow()).To(Equal(20 * protocol.DefaultTCPMSS))
+		round(baseRTT + 10*time.Millisecond)
+		Expect(sender.GetCongestionWindow()).To(Equal(40 * protocol.DefaultTCPMSS))
+		Expect(sender.inSlowStart).To(BeTrue())
+	})
+
+	It("exits slow start when the queuing delay exceeds 3/4 of the target", func() {
+		round(baseRTT)
+		round(baseRTT + target*3/4)
+		Expect(sender.inSlowStart).To(BeTrue())
+		round(baseRTT + target*3/4 + time.Millisecond)
+		Expect(sender.inSlowStart).To(BeFalse())
+	})
+
+	It("uses the minimum of the recent RTT samples as the current delay", func() {
+		round(baseRTT)
+		// a single delayed ACK doesn't indicate a queue
+		sendWindow()
+		ack(1, baseRTT+target)
+		Expect(sender.queuingDelay()).To(BeZero())
+		ack(3, baseRTT+target)
+		Expect(sender.queuingDelay()).To(Equal(target))
+		Expect(sender.inSlowStart).To(BeFalse())
+	})
+
+	It("grows the congestion window by one packet per RTT if there's no queuing delay", func() {
+		exitSlowStart()
+		// wait until the queue has drained
+		ack(int(bytesInFlight/protocol.DefaultTCPMSS), baseRTT)
+		round(baseRTT)
+		cwnd := sender.GetCongestionWindow()
+		round(baseRTT)
+		Expect(sender.GetCongestionWindow()).To(BeNumerically("~", cwnd+protocol.DefaultTCPMSS, protocol.DefaultTCPMSS/10))
+	})
+
+	It("grows the congestion window more slowly when the queuing delay approaches the target", func() {
+		exitSlowStart()
+		round(baseRTT + target/2)
+		cwnd := sender.GetCongestionWindow()
+		round(baseRTT + target/2)
+		Expect(sender.GetCongestionWindow()).To(BeNumerically("~", cwnd+protocol.DefaultTCPMSS/2, protocol.DefaultTCPMSS/10))
+	})
+
+	It("keeps the congestion window when the queuing delay is at the target", func() {
+		exitSlowStart()
+		round(baseRTT + target)
+		cwnd := sender.GetCongestionWindow()
+		round(baseRTT + target)
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd))
+	})
+
+	It("reduces the congestion window when the queuing delay exceeds the target", func() {
+		exitSlowStart()
+		round(baseRTT + 2*target)
+		cwnd := sender.GetCongestionWindow()
+		round(baseRTT + 2*target)
+		Expect(sender.GetCongestionWindow()).To(BeNumerically("~", cwnd-protocol.DefaultTCPMSS, protocol.DefaultTCPMSS/10))
+	})
+
+	It("halves the congestion window per RTT at most", func() {
+		exitSlowStart()
+		round(baseRTT + 100*target)
+		cwnd := sender.GetCongestionWindow()
+		round(baseRTT + 100*target)
+		Expect(sender.GetCongestionWindow()).To(BeNumerically(">=", cwnd/2-protocol.DefaultTCPMSS))
+		Expect(sender.GetCongestionWindow()).To(BeNumerically("<", cwnd*3/4))
+	})
+
+	It("doesn't reduce the congestion window below the minimum", func() {
+		exitSlowStart()
+		for i := 0; i < 20; i++ {
+			round(baseRTT + 100*target)
+		}
+		Expect(sender.GetCongestionWindow()).To(Equal(ledbatMinCongestionWindow))
+	})
+
+	It("doesn't grow the congestion window beyond the maximum", func() {
+		sender = NewLEDBATSender(rttStats, 0, 10, 30).(*ledbatSender)
+		for i := 0; i < 5; i++ {
+			round(baseRTT)
+		}
+		Expect(sender.GetCongestionWindow()).To(Equal(30 * protocol.DefaultTCPMSS))
+	})
+
+	It("doesn't grow the congestion window when the sender is application-limited", func() {
+		// send only 2 packets per RTT
+		for i := 0; i < 10; i++ {
+			for j := 0; j < 2; j++ {
+				packetNumber++
+				bytesInFlight += protocol.DefaultTCPMSS
+				sender.OnPacketSent(clock.Now(), bytesInFlight, packetNumber, protocol.DefaultTCPMSS, true)
+			}
+			ack(2, baseRTT)
+		}
+		Expect(sender.GetCongestionWindow()).To(Equal(10 * protocol.DefaultTCPMSS))
+	})
+
+	It("halves the congestion window on a loss, once per loss event", func() {
+		round(baseRTT)
+		sendWindow()
+		cwnd := sender.GetCongestionWindow()
+		sender.OnPacketLost(ackedPacketNumber+1, protocol.DefaultTCPMSS, bytesInFlight)
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd / 2))
+		Expect(sender.inSlowStart).To(BeFalse())
+		sender.OnPacketLost(ackedPacketNumber+2, protocol.DefaultTCPMSS, bytesInFlight)
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd / 2))
+		// the window doesn't grow while recovering from the loss
+		ack(int(bytesInFlight/protocol.DefaultTCPMSS), baseRTT)
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd / 2))
+		// a packet sent after the loss was detected starts a new loss event
+		sendWindow()
+		sender.OnPacketLost(packetNumber, protocol.DefaultTCPMSS, bytesInFlight)
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd / 4))
+	})
+
+	It("doesn't undo the window reduction on a spurious loss", func() {
+		round(baseRTT)
+		sendWindow()
+		cwnd := sender.GetCongestionWindow()
+		sender.OnPacketLost(ackedPacketNumber+1, protocol.DefaultTCPMSS, bytesInFlight)
+		sender.OnSpuriousLoss(ackedPacketNumber + 1)
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd / 2))
+	})
+
+	It("halves the congestion window on CE marks, once per window", func() {
+		round(baseRTT)
+		sendWindow()
+		cwnd := sender.GetCongestionWindow()
+		sender.OnCongestionExperienced(ackedPacketNumber+1, bytesInFlight)
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd / 2))
+		sender.OnCongestionExperienced(ackedPacketNumber+2, bytesInFlight)
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd / 2))
+	})
+
+	It("reduces the congestion window to the minimum on persistent congestion", func() {
+		round(baseRTT)
+		sender.OnPersistentCongestion()
+		Expect(sender.GetCongestionWindow()).To(Equal(ledbatMinCongestionWindow))
+		Expect(sender.inSlowStart).To(BeFalse())
+	})
+
+	It("paces packets", func() {
+		Expect(sender.PacingRate(0)).To(BeZero())
+		round(baseRTT)
+		Expect(sender.PacingRate(0)).To(Equal(2 * BandwidthFromDelta(sender.GetCongestionWindow(), rttStats.SmoothedRTT())))
+		exitSlowStart()
+		Expect(sender.PacingRate(0)).To(Equal(BandwidthFromDelta(sender.GetCongestionWindow(), rttStats.SmoothedRTT()) * 5 / 4))
+	})
+
+	It("estimates the bandwidth", func() {
+		Expect(sender.BandwidthEstimate()).To(BeZero())
+		sender.OnBandwidthSample(BandwidthSample{Bandwidth: 1000})
+		Expect(sender.BandwidthEstimate()).To(BeEquivalentTo(1000))
+	})
+})
@@ -0,0 +1,89 @@
+package congestion
+
+import (
+	"math"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+)
+
+// A Pacer spreads out the packets of a connection over time, using a token bucket.
+// The bucket is filled at the pacing rate, which is set by the congestion controller.
+// It holds enough data to send at least MinPacingBurst bytes at once, and to make up for a pacing timer that fires late.
+// Initially, it contains the initial burst, such that the first packets of a connection are sent without delay.
+type Pacer struct {
+	// rate is the pacing rate. Packets are not paced if it is 0.
+	rate Bandwidth
+	// budgetAtLastSent is the number of bytes that could be sent right after the last packet was sent
+	budgetAtLastSent protocol.ByteCount
+	lastSentTime     time.Time
+}
+
+// NewPacer creates a new Pacer, which allows sending initialBurst bytes without delay
+func NewPacer(initialBurst protocol.ByteCount) *Pacer {
+	return &Pacer{budgetAtLastSent: initialBurst}
+}
+
+// SetPacingRate sets the pacing rate.
+// If the rate is 0, packets are sent as fast as the congestion window allows.
+func (p *Pacer) SetPacingRate(rate Bandwidth) {
+	p.rate = rate
+}
+
+// PacingRate returns the pacing rate
+func (p *Pacer) PacingRate() Bandwidth {
+	return p.rate
+}
+
+// SentPacket is called for every packet sent
+func (p *Pacer) SentPacket(sendTime time.Time, size protocol.ByteCount) {
+	budget := p.Budget(sendTime)
+	if size > budget {
+		p.budgetAtLastSent = 0
+	} else {
+		p.budgetAtLastSent = budget - size
+	}
+	p.lastSentTime = sendTime
+}
+
+// Budget returns the number of bytes that can be sent at the given time.
+// It is only meaningful if packets are paced, i.e. if the pacing rate is not 0.
+func (p *Pacer) Budget(now time.Time) protocol.ByteCount {
+	if p.lastSentTime.IsZero() {
+		return p.budgetAtLastSent
+	}
+	// The initial burst might be larger than the maximum burst size. It is used up before the maximum applies.
+	maxBudget := utils.MaxByteCount(p.maxBurstSize(), p.budgetAtLastSent)
+	elapsed := now.Sub(p.lastSentTime)
+	if elapsed <= 0 {
+		return p.budgetAtLastSent
+	}
+	budget := float64(p.budgetAtLastSent) + p.bytesPerSecond()*elapsed.Seconds()
+	if budget >= float64(maxBudget) {
+		return maxBudget
+	}
+	// round to the nearest byte, such that floating point errors don't prevent sending a packet at the time returned by TimeUntilSend
+	return protocol.ByteCount(math.Floor(budget + 0.5))
+}
+
+// maxBurstSize is the maximum number of bytes that can be sent at once, after the initial burst was used up
+func (p *Pacer) maxBurstSize() protocol.ByteCount {
+	burst := protocol.ByteCount(p.bytesPerSecond() * (protocol.MinPacingDelay + protocol.PacingTimerGranularity).Seconds())
+	return utils.MaxByteCount(burst, protocol.MinPacingBurst)
+}
+
+// TimeUntilSend returns the time when the budget allows sending the next full-sized packet.
+// If a packet can be sent right away, it returns the zero time.
+// To avoid waking up for every single packet at high rates, the next packet is never sent earlier than MinPacingDelay after the last one.
+func (p *Pacer) TimeUntilSend() time.Time {
+	if p.rate == 0 || p.budgetAtLastSent >= protocol.DefaultTCPMSS {
+		return time.Time{}
+	}
+	delay := time.Duration(math.Ceil(float64(protocol.DefaultTCPMSS-p.budgetAtLastSent) * float64(time.Second) / p.bytesPerSecond()))
+	return p.lastSentTime.Add(utils.MaxDuration(delay, protocol.MinPacingDelay))
+}
+
+func (p *Pacer) bytesPerSecond() float64 {
+	return float64(p.rate) / float64(BytesPerSecond)
+}
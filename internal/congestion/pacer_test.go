@@ -0,0 +1,105 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Pacer", func() {
+	const initialBurst = 10 * protocol.DefaultTCPMSS
+
+	var (
+		pacer *Pacer
+		clock mockClock
+	)
+
+	// 1 packet every 10ms
+	rate := BandwidthFromDelta(protocol.DefaultTCPMSS, 10*time.Millisecond)
+
+	BeforeEach(func() {
+		clock = mockClock{}
+		clock.Advance(time.Hour)
+		pacer = NewPacer(initialBurst)
+		pacer.SetPacingRate(rate)
+	})
+
+	// sendBurst sends packets until the budget is used up
+	sendBurst := func() int {
+		var n int
+		for !pacer.TimeUntilSend().After(clock.Now()) {
+			pacer.SentPacket(clock.Now(), protocol.DefaultTCPMSS)
+			n++
+		}
+		return n
+	}
+
+	It("sends the initial burst without pacing", func() {
+		Expect(pacer.Budget(clock.Now())).To(Equal(initialBurst))
+		Expect(sendBurst()).To(Equal(10))
+		Expect(pacer.TimeUntilSend()).To(Equal(clock.Now().Add(10 * time.Millisecond)))
+	})
+
+	It("doesn't pace packets if the pacing rate is 0", func() {
+		pacer.SetPacingRate(0)
+		for i := 0; i < 100; i++ {
+			pacer.SentPacket(clock.Now(), protocol.DefaultTCPMSS)
+		}
+		Expect(pacer.TimeUntilSend()).To(BeZero())
+	})
+
+	It("paces packets at the pacing rate", func() {
+		sendBurst()
+		start := clock.Now()
+		var sent int
+		for clock.Now().Before(start.Add(time.Second)) {
+			clock.Advance(pacer.TimeUntilSend().Sub(clock.Now()))
+			sent += sendBurst()
+		}
+		Expect(sent).To(Equal(100))
+	})
+
+	It("allows sending a packet exactly at the time returned by TimeUntilSend", func() {
+		pacer.SetPacingRate(BandwidthFromDelta(1000, 3*time.Millisecond))
+		sendBurst()
+		for i := 0; i < 10; i++ {
+			t := pacer.TimeUntilSend()
+			clock.Advance(t.Sub(clock.Now()))
+			Expect(pacer.Budget(clock.Now())).To(BeNumerically(">=", protocol.DefaultTCPMSS))
+			Expect(sendBurst()).To(Equal(1))
+		}
+	})
+
+	It("uses a new pacing rate", func() {
+		sendBurst()
+		pacer.SetPacingRate(2 * rate)
+		Expect(pacer.TimeUntilSend()).To(Equal(clock.Now().Add(5 * time.Millisecond)))
+	})
+
+	It("limits the budget after an idle period", func() {
+		sendBurst()
+		clock.Advance(time.Minute)
+		Expect(pacer.Budget(clock.Now())).To(Equal(protocol.MinPacingBurst))
+		Expect(sendBurst()).To(Equal(2))
+	})
+
+	It("allows sending the data that accumulated during the timer granularity at high rates", func() {
+		// 100 packets per millisecond
+		pacer.SetPacingRate(BandwidthFromDelta(100*protocol.DefaultTCPMSS, time.Millisecond))
+		sendBurst()
+		clock.Advance(time.Second)
+		Expect(pacer.Budget(clock.Now())).To(Equal(110 * protocol.DefaultTCPMSS))
+	})
+
+	It("doesn't wake up earlier than the minimum pacing delay", func() {
+		// 100 packets per millisecond
+		pacer.SetPacingRate(BandwidthFromDelta(100*protocol.DefaultTCPMSS, time.Millisecond))
+		sendBurst()
+		Expect(pacer.TimeUntilSend()).To(Equal(clock.Now().Add(protocol.MinPacingDelay)))
+		clock.Advance(protocol.MinPacingDelay)
+		Expect(sendBurst()).To(Equal(10))
+	})
+})
@@ -0,0 +1,76 @@
+package congestion
+
+// A maxBandwidthFilter tracks the maximum bandwidth seen within a window of round trips.
+// It uses Kathleen Nichols' algorithm, keeping the best, second best and third best estimate,
+// which only needs constant memory, independent of the number of samples in the window.
+// This is the same algorithm as used by the Linux kernel's lib/minmax.c.
+type maxBandwidthFilter struct {
+	// windowLength is the length of the window, in round trips
+	windowLength uint64
+	estimates    [3]bandwidthEstimate
+}
+
+type bandwidthEstimate struct {
+	bandwidth Bandwidth
+	round     uint64
+}
+
+func newMaxBandwidthFilter(windowLength uint64) *maxBandwidthFilter {
+	return &maxBandwidthFilter{windowLength: windowLength}
+}
+
+// Update adds a new sample, taken in the given round trip.
+func (f *maxBandwidthFilter) Update(bandwidth Bandwidth, round uint64) {
+	sample := bandwidthEstimate{bandwidth: bandwidth, round: round}
+	// Reset all estimates if there are none yet, if the new sample is a new maximum,
+	// or if nothing was sampled for an entire window.
+	if f.estimates[0].bandwidth == 0 || bandwidth >= f.estimates[0].bandwidth || round-f.estimates[2].round > f.windowLength {
+		f.Reset(bandwidth, round)
+		return
+	}
+
+	if bandwidth >= f.estimates[1].bandwidth {
+		f.estimates[1] = sample
+		f.estimates[2] = sample
+	} else if bandwidth >= f.estimates[2].bandwidth {
+		f.estimates[2] = sample
+	}
+
+	// Expire and update the estimates as necessary.
+	if round-f.estimates[0].round > f.windowLength {
+		// The best estimate hasn't been updated for an entire window, so promote the second and third best estimates.
+		f.estimates[0] = f.estimates[1]
+		f.estimates[1] = f.estimates[2]
+		f.estimates[2] = sample
+		// Need to iterate one more time. Check if the new best estimate is outside the window as well,
+		// since it may also have been recorded a long time ago.
+		if round-f.estimates[0].round > f.windowLength {
+			f.estimates[0] = f.estimates[1]
+			f.estimates[1] = f.estimates[2]
+		}
+		return
+	}
+	if f.estimates[1] == f.estimates[0] && round-f.estimates[1].round > f.windowLength/4 {
+		// A quarter of the window has passed without a better sample, so the second best estimate is taken from the second quarter of the window.
+		f.estimates[1] = sample
+		f.estimates[2] = sample
+		return
+	}
+	if f.estimates[2] == f.estimates[1] && round-f.estimates[2].round > f.windowLength/2 {
+		// We've passed half of the window without a better estimate, so take a third best estimate from the second half of the window.
+		f.estimates[2] = sample
+	}
+}
+
+// Reset resets all estimates to a new sample.
+func (f *maxBandwidthFilter) Reset(bandwidth Bandwidth, round uint64) {
+	sample := bandwidthEstimate{bandwidth: bandwidth, round: round}
+	f.estimates[0] = sample
+	f.estimates[1] = sample
+	f.estimates[2] = sample
+}
+
+// GetBest returns the maximum bandwidth within the window.
+func (f *maxBandwidthFilter) GetBest() Bandwidth {
+	return f.estimates[0].bandwidth
+}
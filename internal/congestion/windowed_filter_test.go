@@ -0,0 +1,71 @@
+package congestion
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Max bandwidth filter", func() {
+	var filter *maxBandwidthFilter
+
+	BeforeEach(func() {
+		filter = newMaxBandwidthFilter(10)
+	})
+
+	It("is zero before the first sample", func() {
+		Expect(filter.GetBest()).To(BeZero())
+	})
+
+	It("returns the maximum", func() {
+		filter.Update(100, 1)
+		filter.Update(300, 2)
+		filter.Update(200, 3)
+		Expect(filter.GetBest()).To(Equal(Bandwidth(300)))
+	})
+
+	It("forgets the maximum after the window", func() {
+		filter.Update(300, 1)
+		for round := uint64(2); round <= 11; round++ {
+			filter.Update(100, round)
+			Expect(filter.GetBest()).To(Equal(Bandwidth(300)))
+		}
+		filter.Update(100, 12)
+		Expect(filter.GetBest()).To(Equal(Bandwidth(100)))
+	})
+
+	It("uses the second best estimate, when the maximum expires", func() {
+		filter.Update(500, 1)
+		filter.Update(400, 4)
+		for round := uint64(5); round <= 11; round++ {
+			filter.Update(100, round)
+		}
+		Expect(filter.GetBest()).To(Equal(Bandwidth(500)))
+		filter.Update(100, 12)
+		Expect(filter.GetBest()).To(Equal(Bandwidth(400)))
+	})
+
+	It("takes a new second best estimate after a quarter of the window", func() {
+		filter.Update(500, 1)
+		// the second best estimate is taken from the second quarter of the window
+		filter.Update(100, 2)
+		filter.Update(100, 3)
+		filter.Update(200, 4)
+		for round := uint64(5); round <= 11; round++ {
+			filter.Update(100, round)
+		}
+		filter.Update(100, 12)
+		Expect(filter.GetBest()).To(Equal(Bandwidth(200)))
+	})
+
+	It("resets after a long time without samples", func() {
+		filter.Update(500, 1)
+		filter.Update(100, 20)
+		Expect(filter.GetBest()).To(Equal(Bandwidth(100)))
+	})
+
+	It("resets", func() {
+		filter.Update(500, 1)
+		filter.Reset(100, 2)
+		Expect(filter.GetBest()).To(Equal(Bandwidth(100)))
+	})
+})
@@ -10,11 +10,17 @@ import (
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 )
 
+// aeadAESGCM12 is not safe for concurrent use by multiple goroutines.
+// Open and Seal may be called concurrently though.
 type aeadAESGCM12 struct {
 	otherIV   []byte
 	myIV      []byte
 	encrypter cipher.AEAD
 	decrypter cipher.AEAD
+
+	// the nonces are reused for every packet, to avoid allocations
+	openNonce [12]byte
+	sealNonce [12]byte
 }
 
 var _ AEAD = &aeadAESGCM12{}
@@ -53,18 +59,17 @@ func NewAEADAESGCM12(otherKey []byte, myKey []byte, otherIV []byte, myIV []byte)
 }
 
 func (aead *aeadAESGCM12) Open(dst, src []byte, packetNumber protocol.PacketNumber, associatedData []byte) ([]byte, error) {
-	return aead.decrypter.Open(dst, aead.makeNonce(aead.otherIV, packetNumber), src, associatedData)
+	return aead.decrypter.Open(dst, aead.makeNonce(aead.openNonce[:], aead.otherIV, packetNumber), src, associatedData)
 }
 
 func (aead *aeadAESGCM12) Seal(dst, src []byte, packetNumber protocol.PacketNumber, associatedData []byte) []byte {
-	return aead.encrypter.Seal(dst, aead.makeNonce(aead.myIV, packetNumber), src, associatedData)
+	return aead.encrypter.Seal(dst, aead.makeNonce(aead.sealNonce[:], aead.myIV, packetNumber), src, associatedData)
 }
 
-func (aead *aeadAESGCM12) makeNonce(iv []byte, packetNumber protocol.PacketNumber) []byte {
-	res := make([]byte, 12)
-	copy(res[0:4], iv)
-	binary.LittleEndian.PutUint64(res[4:12], uint64(packetNumber))
-	return res
+func (aead *aeadAESGCM12) makeNonce(nonce, iv []byte, packetNumber protocol.PacketNumber) []byte {
+	copy(nonce[0:4], iv)
+	binary.LittleEndian.PutUint64(nonce[4:12], uint64(packetNumber))
+	return nonce
 }
 
 func (aead *aeadAESGCM12) Overhead() int {
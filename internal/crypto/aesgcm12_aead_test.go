@@ -2,6 +2,9 @@ package crypto
 
 import (
 	"crypto/rand"
+	"testing"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -54,6 +57,34 @@ var _ = Describe("AES-GCM", func() {
 		Expect(err).To(HaveOccurred())
 	})
 
+	It("seals and opens packets with different packet numbers", func() {
+		sealed := make([][]byte, 0, 10)
+		for pn := protocol.PacketNumber(1); pn <= 10; pn++ {
+			sealed = append(sealed, alice.Seal(nil, []byte("foobar"), pn, []byte("aad")))
+		}
+		Expect(sealed[0]).ToNot(Equal(sealed[1]))
+		for i := len(sealed) - 1; i >= 0; i-- {
+			text, err := bob.Open(nil, sealed[i], protocol.PacketNumber(i+1), []byte("aad"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(text).To(Equal([]byte("foobar")))
+		}
+	})
+
+	It("doesn't allocate when sealing and opening", func() {
+		plaintext := make([]byte, 1200)
+		sealed := make([]byte, 0, 1200+alice.Overhead())
+		opened := make([]byte, 0, 1200)
+		aad := []byte("aad")
+		allocs := testing.AllocsPerRun(100, func() {
+			b := alice.Seal(sealed, plaintext, 42, aad)
+			_, err := bob.Open(opened, b, 42, aad)
+			if err != nil {
+				Fail(err.Error())
+			}
+		})
+		Expect(allocs).To(BeZero())
+	})
+
 	It("rejects wrong key and iv sizes", func() {
 		var err error
 		e := "AES-GCM: expected 16-byte keys and 4-byte IVs"
@@ -9,11 +9,17 @@ import (
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 )
 
+// aeadAESGCM is not safe for concurrent use by multiple goroutines.
+// Open and Seal may be called concurrently though.
 type aeadAESGCM struct {
 	otherIV   []byte
 	myIV      []byte
 	encrypter cipher.AEAD
 	decrypter cipher.AEAD
+
+	// the nonces are reused for every packet, to avoid allocations
+	openNonce [ivLen]byte
+	sealNonce [ivLen]byte
 }
 
 var _ AEAD = &aeadAESGCM{}
@@ -53,15 +59,17 @@ func NewAEADAESGCM(otherKey []byte, myKey []byte, otherIV []byte, myIV []byte) (
 }
 
 func (aead *aeadAESGCM) Open(dst, src []byte, packetNumber protocol.PacketNumber, associatedData []byte) ([]byte, error) {
-	return aead.decrypter.Open(dst, aead.makeNonce(aead.otherIV, packetNumber), src, associatedData)
+	return aead.decrypter.Open(dst, aead.makeNonce(aead.openNonce[:], aead.otherIV, packetNumber), src, associatedData)
 }
 
 func (aead *aeadAESGCM) Seal(dst, src []byte, packetNumber protocol.PacketNumber, associatedData []byte) []byte {
-	return aead.encrypter.Seal(dst, aead.makeNonce(aead.myIV, packetNumber), src, associatedData)
+	return aead.encrypter.Seal(dst, aead.makeNonce(aead.sealNonce[:], aead.myIV, packetNumber), src, associatedData)
 }
 
-func (aead *aeadAESGCM) makeNonce(iv []byte, packetNumber protocol.PacketNumber) []byte {
-	nonce := make([]byte, ivLen)
+func (aead *aeadAESGCM) makeNonce(nonce, iv []byte, packetNumber protocol.PacketNumber) []byte {
+	for i := 0; i < ivLen-8; i++ {
+		nonce[i] = 0
+	}
 	binary.BigEndian.PutUint64(nonce[ivLen-8:], uint64(packetNumber))
 	for i := 0; i < ivLen; i++ {
 		nonce[i] ^= iv[i]
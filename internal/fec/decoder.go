@@ -0,0 +1,228 @@
+package fec
+
+import (
+	"errors"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/wire"
+)
+
+var (
+	errInvalidBlock        = errors.New("FEC: invalid block size")
+	errInconsistentSymbols = errors.New("FEC: inconsistent repair symbols")
+	errInvalidSymbol       = errors.New("FEC: invalid repair symbol")
+)
+
+type receivedPacket struct {
+	packetNumber protocol.PacketNumber
+	data         []byte
+	received     bool
+}
+
+type block struct {
+	packetNumbers []protocol.PacketNumber
+	numRepair     uint8
+	symbolLen     int
+	// the repair symbols, indexed by the repair index
+	repairSymbols map[uint8][]byte
+}
+
+// A Decoder recovers lost packets from the repair symbols received in FEC frames.
+// It keeps the packets received within the last protocol.FECReceiveWindow packet numbers.
+type Decoder struct {
+	largestReceived protocol.PacketNumber
+	packets         [protocol.FECReceiveWindow]receivedPacket
+
+	// the blocks that we received repair symbols for, indexed by the first packet number of the block
+	blocks map[protocol.PacketNumber]*block
+}
+
+// NewDecoder creates a new Decoder
+func NewDecoder() *Decoder {
+	return &Decoder{blocks: make(map[protocol.PacketNumber]*block)}
+}
+
+// ReceivedPacket saves a copy of a received packet, consisting of the header and the (encrypted) payload.
+// It returns the packets that could be recovered, now that this packet was received.
+func (d *Decoder) ReceivedPacket(pn protocol.PacketNumber, header, data []byte) ([][]byte, error) {
+	if d.isTooOld(pn) || d.hasPacket(pn) {
+		return nil, nil
+	}
+	if pn > d.largestReceived {
+		d.largestReceived = pn
+	}
+	p := &d.packets[pn%protocol.FECReceiveWindow]
+	p.packetNumber = pn
+	p.data = append(append(p.data[:0], header...), data...)
+	p.received = true
+
+	for first, b := range d.blocks {
+		if d.isTooOld(b.packetNumbers[len(b.packetNumbers)-1]) {
+			delete(d.blocks, first)
+			continue
+		}
+		if pn < first || pn > b.packetNumbers[len(b.packetNumbers)-1] {
+			continue
+		}
+		return d.tryRecover(first, b)
+	}
+	return nil, nil
+}
+
+// ReceivedFECFrame processes a repair symbol.
+// It returns the packets that could be recovered, now that this repair symbol was received.
+func (d *Decoder) ReceivedFECFrame(f *wire.FECFrame) ([][]byte, error) {
+	numData := len(f.PacketNumbers)
+	if numData == 0 || numData+int(f.NumRepairSymbols) > 256 {
+		return nil, errInvalidBlock
+	}
+	if len(f.Data) < 2 {
+		return nil, errInvalidSymbol
+	}
+	if d.isTooOld(f.PacketNumbers[numData-1]) {
+		return nil, nil
+	}
+	first := f.PacketNumbers[0]
+	b, ok := d.blocks[first]
+	if ok {
+		if b.numRepair != f.NumRepairSymbols || b.symbolLen != len(f.Data) || !equalPacketNumbers(b.packetNumbers, f.PacketNumbers) {
+			return nil, errInconsistentSymbols
+		}
+	} else {
+		b = &block{
+			packetNumbers: f.PacketNumbers,
+			numRepair:     f.NumRepairSymbols,
+			symbolLen:     len(f.Data),
+			repairSymbols: make(map[uint8][]byte),
+		}
+		d.blocks[first] = b
+	}
+	b.repairSymbols[f.RepairIndex] = f.Data
+	return d.tryRecover(first, b)
+}
+
+func (d *Decoder) tryRecover(first protocol.PacketNumber, b *block) ([][]byte, error) {
+	var missing []int // the indices of the missing packets in the block
+	for i, pn := range b.packetNumbers {
+		if !d.hasPacket(pn) {
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) == 0 {
+		// all packets were received, the repair symbols are not needed any more
+		delete(d.blocks, first)
+		return nil, nil
+	}
+	if len(missing) > len(b.repairSymbols) {
+		return nil, nil
+	}
+	delete(d.blocks, first)
+
+	numData := len(b.packetNumbers)
+	numRepair := int(b.numRepair)
+	// use the repair symbols with the lowest indices
+	repairIndices := make([]int, 0, len(missing))
+	for i := 0; i < numRepair && len(repairIndices) < len(missing); i++ {
+		if _, ok := b.repairSymbols[uint8(i)]; ok {
+			repairIndices = append(repairIndices, i)
+		}
+	}
+
+	// For every repair symbol, subtract the contributions of the received packets.
+	// The result is a linear combination of the missing packets.
+	symbol := make([]byte, b.symbolLen)
+	rhs := make([][]byte, len(missing))
+	matrix := make([][]byte, len(missing))
+	for r, repairIndex := range repairIndices {
+		rhs[r] = append([]byte(nil), b.repairSymbols[uint8(repairIndex)]...)
+		matrix[r] = make([]byte, len(missing))
+		for c, dataIndex := range missing {
+			matrix[r][c] = coefficient(repairIndex, dataIndex, numData, numRepair)
+		}
+		m := 0
+		for i, pn := range b.packetNumbers {
+			if m < len(missing) && missing[m] == i {
+				m++
+				continue
+			}
+			packet := d.getPacket(pn)
+			if symbolLength(packet) > b.symbolLen {
+				return nil, errInvalidSymbol
+			}
+			writeSymbol(symbol, packet)
+			gfMulAdd(rhs[r], symbol, coefficient(repairIndex, i, numData, numRepair))
+		}
+	}
+
+	if err := solve(matrix, rhs); err != nil {
+		return nil, err
+	}
+
+	recovered := make([][]byte, len(rhs))
+	for i, s := range rhs {
+		length := int(s[0])<<8 + int(s[1])
+		if length == 0 || 2+length > len(s) {
+			return nil, errInvalidSymbol
+		}
+		recovered[i] = s[2 : 2+length]
+	}
+	return recovered, nil
+}
+
+// solve solves the system of linear equations matrix * x = rhs, using Gaussian elimination.
+// The solution is written to rhs.
+func solve(matrix [][]byte, rhs [][]byte) error {
+	n := len(matrix)
+	for c := 0; c < n; c++ {
+		pivot := -1
+		for r := c; r < n; r++ {
+			if matrix[r][c] != 0 {
+				pivot = r
+				break
+			}
+		}
+		if pivot == -1 {
+			return errors.New("FEC BUG: singular matrix")
+		}
+		matrix[c], matrix[pivot] = matrix[pivot], matrix[c]
+		rhs[c], rhs[pivot] = rhs[pivot], rhs[c]
+
+		inv := gfInv(matrix[c][c])
+		gfMulSlice(matrix[c], inv)
+		gfMulSlice(rhs[c], inv)
+		for r := 0; r < n; r++ {
+			if r == c || matrix[r][c] == 0 {
+				continue
+			}
+			factor := matrix[r][c]
+			gfMulAdd(matrix[r], matrix[c], factor)
+			gfMulAdd(rhs[r], rhs[c], factor)
+		}
+	}
+	return nil
+}
+
+func (d *Decoder) isTooOld(pn protocol.PacketNumber) bool {
+	return pn+protocol.FECReceiveWindow <= d.largestReceived
+}
+
+func (d *Decoder) hasPacket(pn protocol.PacketNumber) bool {
+	p := &d.packets[pn%protocol.FECReceiveWindow]
+	return p.received && p.packetNumber == pn
+}
+
+func (d *Decoder) getPacket(pn protocol.PacketNumber) []byte {
+	return d.packets[pn%protocol.FECReceiveWindow].data
+}
+
+func equalPacketNumbers(a, b []protocol.PacketNumber) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,183 @@
+package fec
+
+import (
+	"bytes"
+	"math/rand"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/wire"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Decoder", func() {
+	var d *Decoder
+
+	BeforeEach(func() {
+		d = NewDecoder()
+	})
+
+	// receive passes a packet to the decoder, splitting it into header and payload
+	receive := func(pn protocol.PacketNumber, p []byte) ([][]byte, error) {
+		return d.ReceivedPacket(pn, p[:1], p[1:])
+	}
+
+	// encode returns the packets in a block, and the FEC frames for it
+	encode := func(first protocol.PacketNumber, numData, numRepair int) ([][]byte, []*wire.FECFrame) {
+		e := NewEncoder(numData, numRepair)
+		var packets [][]byte
+		var frames []*wire.FECFrame
+		for i := 0; i < numData; i++ {
+			p := make([]byte, 10+rand.Intn(100))
+			rand.Read(p)
+			packets = append(packets, p)
+			frames = e.AddPacket(first+protocol.PacketNumber(i), p)
+		}
+		Expect(frames).To(HaveLen(numRepair))
+		return packets, frames
+	}
+
+	It("doesn't recover anything if all packets were received", func() {
+		packets, frames := encode(1, 3, 1)
+		for i, p := range packets {
+			recovered, err := receive(protocol.PacketNumber(1+i), p)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(recovered).To(BeEmpty())
+		}
+		recovered, err := d.ReceivedFECFrame(frames[0])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recovered).To(BeEmpty())
+		Expect(d.blocks).To(BeEmpty())
+	})
+
+	It("recovers a single lost packet using XOR", func() {
+		packets, frames := encode(1, 3, 1)
+		receive(1, packets[0])
+		receive(3, packets[2])
+		recovered, err := d.ReceivedFECFrame(frames[0])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recovered).To(Equal([][]byte{packets[1]}))
+		Expect(d.blocks).To(BeEmpty())
+	})
+
+	It("recovers multiple lost packets", func() {
+		packets, frames := encode(100, 10, 4)
+		for i, p := range packets {
+			if i == 0 || i == 3 || i == 4 || i == 9 {
+				continue
+			}
+			receive(protocol.PacketNumber(100+i), p)
+		}
+		// use repair symbols 0, 1, 3 and 2, in this order
+		for _, i := range []int{0, 1, 3} {
+			recovered, err := d.ReceivedFECFrame(frames[i])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(recovered).To(BeEmpty())
+		}
+		recovered, err := d.ReceivedFECFrame(frames[2])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recovered).To(Equal([][]byte{packets[0], packets[3], packets[4], packets[9]}))
+	})
+
+	It("recovers packets when receiving the repair symbols before the packets", func() {
+		packets, frames := encode(1, 4, 2)
+		for _, f := range frames {
+			recovered, err := d.ReceivedFECFrame(f)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(recovered).To(BeEmpty())
+		}
+		recovered, err := receive(1, packets[0])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recovered).To(BeEmpty())
+		recovered, err = receive(4, packets[3])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recovered).To(Equal([][]byte{packets[1], packets[2]}))
+	})
+
+	It("recovers packets from a block with gaps in the packet numbers", func() {
+		e := NewEncoder(3, 1)
+		e.AddPacket(5, []byte("foo"))
+		e.AddPacket(8, []byte("foobar"))
+		frames := e.AddPacket(12, []byte("bar"))
+		receive(5, []byte("foo"))
+		receive(12, []byte("bar"))
+		recovered, err := d.ReceivedFECFrame(frames[0])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recovered).To(Equal([][]byte{[]byte("foobar")}))
+	})
+
+	It("doesn't recover packets if too many were lost", func() {
+		packets, frames := encode(1, 4, 1)
+		receive(1, packets[0])
+		receive(2, packets[1])
+		recovered, err := d.ReceivedFECFrame(frames[0])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recovered).To(BeEmpty())
+		Expect(d.blocks).To(HaveLen(1))
+	})
+
+	It("copies the packets", func() {
+		packets, frames := encode(1, 2, 1)
+		p := append([]byte(nil), packets[0]...)
+		receive(1, p)
+		p[0]++
+		recovered, err := d.ReceivedFECFrame(frames[0])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recovered).To(Equal([][]byte{packets[1]}))
+	})
+
+	It("forgets packets that are too old", func() {
+		packets, frames := encode(1, 2, 1)
+		receive(1, packets[0])
+		receive(1+protocol.FECReceiveWindow, []byte("foobar"))
+		recovered, err := d.ReceivedFECFrame(frames[0])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recovered).To(BeEmpty())
+	})
+
+	It("deletes blocks that are too old", func() {
+		_, frames := encode(1, 2, 1)
+		_, err := d.ReceivedFECFrame(frames[0])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(d.blocks).To(HaveLen(1))
+		receive(2+protocol.FECReceiveWindow, []byte("foobar"))
+		Expect(d.blocks).To(BeEmpty())
+	})
+
+	It("errors on inconsistent repair symbols", func() {
+		_, frames := encode(1, 3, 2)
+		_, err := d.ReceivedFECFrame(frames[0])
+		Expect(err).ToNot(HaveOccurred())
+		frames[1].PacketNumbers = []protocol.PacketNumber{1, 2, 4}
+		_, err = d.ReceivedFECFrame(frames[1])
+		Expect(err).To(MatchError(errInconsistentSymbols))
+	})
+
+	It("errors on blocks that are too large", func() {
+		_, err := d.ReceivedFECFrame(&wire.FECFrame{
+			PacketNumbers:    make([]protocol.PacketNumber, 250),
+			NumRepairSymbols: 10,
+			Data:             []byte("foobar"),
+		})
+		Expect(err).To(MatchError(errInvalidBlock))
+	})
+
+	It("errors on repair symbols that are too short", func() {
+		_, err := d.ReceivedFECFrame(&wire.FECFrame{
+			PacketNumbers:    []protocol.PacketNumber{1},
+			NumRepairSymbols: 1,
+			Data:             []byte{0},
+		})
+		Expect(err).To(MatchError(errInvalidSymbol))
+	})
+
+	It("errors if a received packet is larger than the repair symbol", func() {
+		e := NewEncoder(2, 1)
+		e.AddPacket(1, []byte("foo"))
+		frames := e.AddPacket(2, []byte("bar"))
+		receive(1, bytes.Repeat([]byte{'f'}, 10))
+		_, err := d.ReceivedFECFrame(frames[0])
+		Expect(err).To(MatchError(errInvalidSymbol))
+	})
+})
@@ -0,0 +1,100 @@
+package fec
+
+import (
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/wire"
+)
+
+// maxPacketNumberDelta is the maximum distance between the first and the last packet number of a block.
+// This makes sure that the receiver still keeps the first packets of a block when receiving the last one.
+const maxPacketNumberDelta = protocol.FECReceiveWindow / 2
+
+// An Encoder groups sent packets into blocks, and generates the repair symbols for every block.
+type Encoder struct {
+	numData   int
+	numRepair int
+
+	packetNumbers []protocol.PacketNumber
+	packets       [][]byte
+}
+
+// NewEncoder creates a new Encoder.
+// It generates numRepair repair symbols for every numData packets.
+func NewEncoder(numData, numRepair int) *Encoder {
+	return &Encoder{
+		numData:   numData,
+		numRepair: numRepair,
+	}
+}
+
+// AddPacket adds a packet to the current block.
+// Packets must be added in ascending order of their packet numbers.
+// The data is copied, so the caller may reuse it.
+// It returns the FEC frames for the block, if the block is complete.
+func (e *Encoder) AddPacket(pn protocol.PacketNumber, data []byte) []*wire.FECFrame {
+	var frames []*wire.FECFrame
+	// if this packet can't be encoded in the current block, finish the block early
+	if len(e.packetNumbers) > 0 && pn-e.packetNumbers[0] > maxPacketNumberDelta {
+		frames = e.Flush()
+	}
+	e.packetNumbers = append(e.packetNumbers, pn)
+	e.packets = append(e.packets, append([]byte(nil), data...))
+	if len(e.packetNumbers) >= e.numData {
+		frames = append(frames, e.Flush()...)
+	}
+	return frames
+}
+
+// Len returns the number of packets in the current block
+func (e *Encoder) Len() int {
+	return len(e.packets)
+}
+
+// Flush finishes the current block, even if it doesn't contain the full number of packets yet.
+// It returns the FEC frames for the block, or nil if no packets were added since the last block was finished.
+func (e *Encoder) Flush() []*wire.FECFrame {
+	if len(e.packets) == 0 {
+		return nil
+	}
+	var symbolLen int
+	for _, p := range e.packets {
+		if l := symbolLength(p); l > symbolLen {
+			symbolLen = l
+		}
+	}
+	frames := make([]*wire.FECFrame, e.numRepair)
+	symbol := make([]byte, symbolLen)
+	for i := range frames {
+		data := make([]byte, symbolLen)
+		for j, p := range e.packets {
+			writeSymbol(symbol, p)
+			gfMulAdd(data, symbol, coefficient(i, j, len(e.packets), e.numRepair))
+		}
+		frames[i] = &wire.FECFrame{
+			PacketNumbers:    e.packetNumbers,
+			NumRepairSymbols: uint8(e.numRepair),
+			RepairIndex:      uint8(i),
+			Data:             data,
+		}
+	}
+	e.packetNumbers = nil
+	e.packets = nil
+	return frames
+}
+
+// a symbol consists of the length of the packet (encoded as a 2 byte integer),
+// followed by the packet, padded with zeros to the length of the largest symbol in the block
+func symbolLength(packet []byte) int {
+	return 2 + len(packet)
+}
+
+// writeSymbol writes the symbol for a packet to b.
+// b must be at least symbolLength(packet) bytes long.
+func writeSymbol(b []byte, packet []byte) {
+	b[0] = uint8(len(packet) >> 8)
+	b[1] = uint8(len(packet))
+	n := copy(b[2:], packet)
+	for i := 2 + n; i < len(b); i++ {
+		b[i] = 0
+	}
+}
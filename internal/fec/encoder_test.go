@@ -0,0 +1,69 @@
+package fec
+
+import (
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Encoder", func() {
+	It("generates repair symbols when a block is complete", func() {
+		e := NewEncoder(3, 2)
+		Expect(e.AddPacket(10, []byte("foo"))).To(BeEmpty())
+		Expect(e.AddPacket(11, []byte("foobar"))).To(BeEmpty())
+		frames := e.AddPacket(13, []byte("bar"))
+		Expect(frames).To(HaveLen(2))
+		for i, f := range frames {
+			Expect(f.PacketNumbers).To(Equal([]protocol.PacketNumber{10, 11, 13}))
+			Expect(f.NumRepairSymbols).To(BeEquivalentTo(2))
+			Expect(f.RepairIndex).To(BeEquivalentTo(i))
+			Expect(f.Data).To(HaveLen(2 + 6))
+		}
+		// the next block starts empty
+		Expect(e.Flush()).To(BeNil())
+	})
+
+	It("XORs the packets if there's only a single repair symbol", func() {
+		e := NewEncoder(2, 1)
+		Expect(e.AddPacket(1, []byte{0x1, 0x2})).To(BeEmpty())
+		frames := e.AddPacket(2, []byte{0x3})
+		Expect(frames).To(HaveLen(1))
+		Expect(frames[0].Data).To(Equal([]byte{
+			0x0, 0x2 ^ 0x1, // lengths
+			0x1 ^ 0x3, 0x2, // data
+		}))
+	})
+
+	It("copies the data", func() {
+		e := NewEncoder(2, 1)
+		data := []byte{0x1, 0x2}
+		e.AddPacket(1, data)
+		data[0] = 0x42
+		frames := e.AddPacket(2, []byte{0x0, 0x0})
+		Expect(frames[0].Data[2:]).To(Equal([]byte{0x1, 0x2}))
+	})
+
+	It("flushes an incomplete block", func() {
+		e := NewEncoder(10, 1)
+		e.AddPacket(1, []byte("foo"))
+		e.AddPacket(2, []byte("bar"))
+		Expect(e.Len()).To(Equal(2))
+		frames := e.Flush()
+		Expect(frames).To(HaveLen(1))
+		Expect(frames[0].PacketNumbers).To(Equal([]protocol.PacketNumber{1, 2}))
+		Expect(e.Len()).To(BeZero())
+		Expect(e.Flush()).To(BeNil())
+	})
+
+	It("finishes a block early if the packet numbers are too far apart", func() {
+		e := NewEncoder(10, 1)
+		e.AddPacket(1, []byte("foo"))
+		frames := e.AddPacket(1+maxPacketNumberDelta+1, []byte("bar"))
+		Expect(frames).To(HaveLen(1))
+		Expect(frames[0].PacketNumbers).To(Equal([]protocol.PacketNumber{1}))
+		frames = e.Flush()
+		Expect(frames).To(HaveLen(1))
+		Expect(frames[0].PacketNumbers).To(Equal([]protocol.PacketNumber{1 + maxPacketNumberDelta + 1}))
+	})
+})
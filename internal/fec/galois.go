@@ -0,0 +1,72 @@
+package fec
+
+// arithmetic in GF(2^8), using the primitive polynomial x^8 + x^4 + x^3 + x^2 + 1
+const gfPolynomial = 0x11d
+
+var (
+	gfExp [510]byte
+	gfLog [256]byte
+)
+
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		gfExp[i] = byte(x)
+		gfExp[i+255] = byte(x)
+		gfLog[x] = byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= gfPolynomial
+		}
+	}
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+// gfInv returns the multiplicative inverse of a. a must not be 0.
+func gfInv(a byte) byte {
+	return gfExp[255-int(gfLog[a])]
+}
+
+// gfMulAdd adds c * src to dst
+func gfMulAdd(dst, src []byte, c byte) {
+	if c == 0 {
+		return
+	}
+	if c == 1 {
+		for i, b := range src {
+			dst[i] ^= b
+		}
+		return
+	}
+	logC := int(gfLog[c])
+	for i, b := range src {
+		if b != 0 {
+			dst[i] ^= gfExp[logC+int(gfLog[b])]
+		}
+	}
+}
+
+// gfMulSlice multiplies all elements of s by c
+func gfMulSlice(s []byte, c byte) {
+	for i, b := range s {
+		s[i] = gfMul(b, c)
+	}
+}
+
+// coefficient returns the coefficient of the data symbol dataIndex in the repair symbol repairIndex.
+// With a single repair symbol, this is a simple XOR of all data symbols.
+// Otherwise, the coefficients form a Cauchy matrix, such that any numRepair lost data symbols can be recovered
+// from numRepair repair symbols.
+func coefficient(repairIndex, dataIndex, numData, numRepair int) byte {
+	if numRepair == 1 {
+		return 1
+	}
+	// x_i = numData + repairIndex and y_j = dataIndex are distinct, so x_i + y_j is never 0
+	return gfInv(byte(numData+repairIndex) ^ byte(dataIndex))
+}
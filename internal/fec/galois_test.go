@@ -0,0 +1,47 @@
+package fec
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Galois field arithmetic", func() {
+	It("multiplies", func() {
+		Expect(gfMul(0, 0x42)).To(BeZero())
+		Expect(gfMul(0x42, 1)).To(Equal(byte(0x42)))
+		Expect(gfMul(2, 0x80)).To(Equal(byte(0x1d))) // reduced by the polynomial
+		Expect(gfMul(0x13, 0x37)).To(Equal(gfMul(0x37, 0x13)))
+	})
+
+	It("calculates the inverse", func() {
+		for i := 1; i < 256; i++ {
+			Expect(gfMul(byte(i), gfInv(byte(i)))).To(Equal(byte(1)))
+		}
+	})
+
+	It("multiplies and adds slices", func() {
+		dst := []byte{1, 2, 3}
+		gfMulAdd(dst, []byte{1, 2, 3}, 1)
+		Expect(dst).To(Equal([]byte{0, 0, 0}))
+		gfMulAdd(dst, []byte{1, 0, 0x80}, 2)
+		Expect(dst).To(Equal([]byte{2, 0, 0x1d}))
+	})
+
+	It("uses distinct, non-zero coefficients", func() {
+		for i := 0; i < 4; i++ {
+			seen := make(map[byte]bool)
+			for j := 0; j < 10; j++ {
+				c := coefficient(i, j, 10, 4)
+				Expect(c).ToNot(BeZero())
+				Expect(seen).ToNot(HaveKey(c))
+				seen[c] = true
+			}
+		}
+	})
+
+	It("uses XOR for a single repair symbol", func() {
+		for j := 0; j < 10; j++ {
+			Expect(coefficient(0, j, 10, 1)).To(Equal(byte(1)))
+		}
+	})
+})
@@ -31,6 +31,16 @@ const (
 	TagSVID Tag = 'S' + 'V'<<8 + 'I'<<16 + 'D'<<24
 	// TagTCID is truncation of the connection ID
 	TagTCID Tag = 'T' + 'C'<<8 + 'I'<<16 + 'D'<<24
+	// TagFECS signals support for forward error correction (unofficial tag by us :)
+	TagFECS Tag = 'F' + 'E'<<8 + 'C'<<16 + 'S'<<24
+	// TagMPTH signals support for multipath (unofficial tag by us :)
+	TagMPTH Tag = 'M' + 'P'<<8 + 'T'<<16 + 'H'<<24
+	// TagMXAD is the maximum ACK delay in milliseconds (unofficial tag by us :)
+	TagMXAD Tag = 'M' + 'X'<<8 + 'A'<<16 + 'D'<<24
+	// TagAFRQ signals support for ACK_FREQUENCY frames (unofficial tag by us :)
+	TagAFRQ Tag = 'A' + 'F'<<8 + 'R'<<16 + 'Q'<<24
+	// TagEXSD signals support for EXPIRED_STREAM_DATA frames (unofficial tag by us :)
+	TagEXSD Tag = 'E' + 'X'<<8 + 'S'<<16 + 'D'<<24
 	// TagPDMD is the proof demand
 	TagPDMD Tag = 'P' + 'D'<<8 + 'M'<<16 + 'D'<<24
 	// TagSRBF is the socket receive buffer
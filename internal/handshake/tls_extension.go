@@ -17,6 +17,15 @@ const (
 	maxPacketSizeParameterID          transportParameterID = 0x5
 	statelessResetTokenParameterID    transportParameterID = 0x6
 	initialMaxStreamIDUniParameterID  transportParameterID = 0x8
+	maxAckDelayParameterID            transportParameterID = 0xc
+	// not part of any QUIC draft, used by quic-go to negotiate forward error correction
+	fecParameterID transportParameterID = 0xfec
+	// not part of any QUIC draft, used by quic-go to negotiate multipath
+	multipathParameterID transportParameterID = 0xfed
+	// not part of any QUIC draft, used by quic-go to negotiate ACK_FREQUENCY frames
+	ackFrequencyParameterID transportParameterID = 0xfee
+	// not part of any QUIC draft, used by quic-go to negotiate EXPIRED_STREAM_DATA frames
+	expiredStreamDataParameterID transportParameterID = 0xfef
 )
 
 type transportParameter struct {
@@ -34,6 +34,54 @@ var _ = Describe("Transport Parameters", func() {
 				Expect(params.OmitConnectionID).To(BeTrue())
 			})
 
+			It("reads if the peer supports FEC", func() {
+				params, err := readHelloMap(map[Tag][]byte{})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(params.FEC).To(BeFalse())
+				params, err = readHelloMap(map[Tag][]byte{TagFECS: {}})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(params.FEC).To(BeTrue())
+			})
+
+			It("reads if the peer supports multipath", func() {
+				params, err := readHelloMap(map[Tag][]byte{})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(params.Multipath).To(BeFalse())
+				params, err = readHelloMap(map[Tag][]byte{TagMPTH: {}})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(params.Multipath).To(BeTrue())
+			})
+
+			It("reads the maximum ACK delay", func() {
+				params, err := readHelloMap(map[Tag][]byte{TagMXAD: {40, 0, 0, 0}})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(params.MaxAckDelay).To(Equal(40 * time.Millisecond))
+			})
+
+			It("limits the maximum ACK delay", func() {
+				params, err := readHelloMap(map[Tag][]byte{TagMXAD: {0, 0x10, 0, 0}})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(params.MaxAckDelay).To(Equal(protocol.MaxAckDelayLimit))
+			})
+
+			It("reads if the peer supports ACK_FREQUENCY frames", func() {
+				params, err := readHelloMap(map[Tag][]byte{})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(params.AckFrequency).To(BeFalse())
+				params, err = readHelloMap(map[Tag][]byte{TagAFRQ: {}})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(params.AckFrequency).To(BeTrue())
+			})
+
+			It("reads if the peer supports EXPIRED_STREAM_DATA frames", func() {
+				params, err := readHelloMap(map[Tag][]byte{})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(params.ExpiredStreamData).To(BeFalse())
+				params, err = readHelloMap(map[Tag][]byte{TagEXSD: {}})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(params.ExpiredStreamData).To(BeTrue())
+			})
+
 			It("doesn't allow idle timeouts below the minimum remote idle timeout", func() {
 				t := 2 * time.Second
 				Expect(t).To(BeNumerically("<", protocol.MinRemoteIdleTimeout))
@@ -74,6 +122,12 @@ var _ = Describe("Transport Parameters", func() {
 				_, err := readHelloMap(values)
 				Expect(err).To(MatchError(errMalformedTag))
 			})
+
+			It("errors when given an invalid MXAD value", func() {
+				values := map[Tag][]byte{TagMXAD: {2, 0, 0}} // 1 byte too short
+				_, err := readHelloMap(values)
+				Expect(err).To(MatchError(errMalformedTag))
+			})
 		})
 
 		Context("writing", func() {
@@ -98,6 +152,36 @@ var _ = Describe("Transport Parameters", func() {
 				entryMap := params.getHelloMap()
 				Expect(entryMap).To(HaveKeyWithValue(TagTCID, []byte{0, 0, 0, 0}))
 			})
+
+			It("announces support for FEC", func() {
+				params := &TransportParameters{FEC: true}
+				entryMap := params.getHelloMap()
+				Expect(entryMap).To(HaveKeyWithValue(TagFECS, []byte{}))
+			})
+
+			It("announces support for multipath", func() {
+				params := &TransportParameters{Multipath: true}
+				entryMap := params.getHelloMap()
+				Expect(entryMap).To(HaveKeyWithValue(TagMPTH, []byte{}))
+			})
+
+			It("announces the maximum ACK delay", func() {
+				params := &TransportParameters{MaxAckDelay: 40 * time.Millisecond}
+				entryMap := params.getHelloMap()
+				Expect(entryMap).To(HaveKeyWithValue(TagMXAD, []byte{40, 0, 0, 0}))
+			})
+
+			It("announces support for ACK_FREQUENCY frames", func() {
+				params := &TransportParameters{AckFrequency: true}
+				entryMap := params.getHelloMap()
+				Expect(entryMap).To(HaveKeyWithValue(TagAFRQ, []byte{}))
+			})
+
+			It("announces support for EXPIRED_STREAM_DATA frames", func() {
+				params := &TransportParameters{ExpiredStreamData: true}
+				entryMap := params.getHelloMap()
+				Expect(entryMap).To(HaveKeyWithValue(TagEXSD, []byte{}))
+			})
 		})
 	})
 
@@ -127,8 +211,29 @@ var _ = Describe("Transport Parameters", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(params.StreamFlowControlWindow).To(Equal(protocol.ByteCount(0x11223344)))
 				Expect(params.ConnectionFlowControlWindow).To(Equal(protocol.ByteCount(0x22334455)))
+				Expect(params.MaxBidiStreamID).To(Equal(protocol.StreamID(0x33445566)))
 				Expect(params.IdleTimeout).To(Equal(0x1337 * time.Second))
 				Expect(params.OmitConnectionID).To(BeFalse())
+				Expect(params.MaxPacketSize).To(BeZero())
+			})
+
+			It("reads the max_packet_size", func() {
+				parameters[maxPacketSizeParameterID] = []byte{0x5, 0xdc} // 1500
+				params, err := readTransportParamters(paramsMapToList(parameters))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(params.MaxPacketSize).To(Equal(protocol.ByteCount(1500)))
+			})
+
+			It("rejects the parameters if the max_packet_size is too small", func() {
+				parameters[maxPacketSizeParameterID] = []byte{0x4, 0xaf} // 1199
+				_, err := readTransportParamters(paramsMapToList(parameters))
+				Expect(err).To(MatchError("invalid value for max_packet_size: 1199 (minimum 1200)"))
+			})
+
+			It("rejects the parameters if the max_packet_size has the wrong length", func() {
+				parameters[maxPacketSizeParameterID] = []byte{0x5, 0xdc, 0x0} // should be 2 bytes
+				_, err := readTransportParamters(paramsMapToList(parameters))
+				Expect(err).To(MatchError("wrong length for max_packet_size: 3 (expected 2)"))
 			})
 
 			It("saves if it should omit the connection ID", func() {
@@ -138,6 +243,86 @@ var _ = Describe("Transport Parameters", func() {
 				Expect(params.OmitConnectionID).To(BeTrue())
 			})
 
+			It("saves if the peer supports FEC", func() {
+				params, err := readTransportParamters(paramsMapToList(parameters))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(params.FEC).To(BeFalse())
+				parameters[fecParameterID] = []byte{}
+				params, err = readTransportParamters(paramsMapToList(parameters))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(params.FEC).To(BeTrue())
+			})
+
+			It("rejects the parameters if the fec parameter has a value", func() {
+				parameters[fecParameterID] = []byte{0x1}
+				_, err := readTransportParamters(paramsMapToList(parameters))
+				Expect(err).To(MatchError("wrong length for fec: 1 (expected empty)"))
+			})
+
+			It("saves if the peer supports multipath", func() {
+				params, err := readTransportParamters(paramsMapToList(parameters))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(params.Multipath).To(BeFalse())
+				parameters[multipathParameterID] = []byte{}
+				params, err = readTransportParamters(paramsMapToList(parameters))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(params.Multipath).To(BeTrue())
+			})
+
+			It("rejects the parameters if the multipath parameter has a value", func() {
+				parameters[multipathParameterID] = []byte{0x1}
+				_, err := readTransportParamters(paramsMapToList(parameters))
+				Expect(err).To(MatchError("wrong length for multipath: 1 (expected empty)"))
+			})
+
+			It("reads the max_ack_delay", func() {
+				params, err := readTransportParamters(paramsMapToList(parameters))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(params.MaxAckDelay).To(BeZero())
+				parameters[maxAckDelayParameterID] = []byte{40}
+				params, err = readTransportParamters(paramsMapToList(parameters))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(params.MaxAckDelay).To(Equal(40 * time.Millisecond))
+			})
+
+			It("rejects the parameters if the max_ack_delay has the wrong length", func() {
+				parameters[maxAckDelayParameterID] = []byte{0, 40} // should be 1 byte
+				_, err := readTransportParamters(paramsMapToList(parameters))
+				Expect(err).To(MatchError("wrong length for max_ack_delay: 2 (expected 1)"))
+			})
+
+			It("saves if the peer supports ACK_FREQUENCY frames", func() {
+				params, err := readTransportParamters(paramsMapToList(parameters))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(params.AckFrequency).To(BeFalse())
+				parameters[ackFrequencyParameterID] = []byte{}
+				params, err = readTransportParamters(paramsMapToList(parameters))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(params.AckFrequency).To(BeTrue())
+			})
+
+			It("rejects the parameters if the ack_frequency parameter has a value", func() {
+				parameters[ackFrequencyParameterID] = []byte{0x1}
+				_, err := readTransportParamters(paramsMapToList(parameters))
+				Expect(err).To(MatchError("wrong length for ack_frequency: 1 (expected empty)"))
+			})
+
+			It("saves if the peer supports EXPIRED_STREAM_DATA frames", func() {
+				params, err := readTransportParamters(paramsMapToList(parameters))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(params.ExpiredStreamData).To(BeFalse())
+				parameters[expiredStreamDataParameterID] = []byte{}
+				params, err = readTransportParamters(paramsMapToList(parameters))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(params.ExpiredStreamData).To(BeTrue())
+			})
+
+			It("rejects the parameters if the expired_stream_data parameter has a value", func() {
+				parameters[expiredStreamDataParameterID] = []byte{0x1}
+				_, err := readTransportParamters(paramsMapToList(parameters))
+				Expect(err).To(MatchError("wrong length for expired_stream_data: 1 (expected empty)"))
+			})
+
 			It("rejects the parameters if the initial_max_stream_data is missing", func() {
 				delete(parameters, initialMaxStreamDataParameterID)
 				_, err := readTransportParamters(paramsMapToList(parameters))
@@ -223,6 +408,7 @@ var _ = Describe("Transport Parameters", func() {
 				params = &TransportParameters{
 					StreamFlowControlWindow:     0xdeadbeef,
 					ConnectionFlowControlWindow: 0xdecafbad,
+					MaxBidiStreamID:             0x1337,
 					IdleTimeout:                 0xcafe * time.Second,
 				}
 			})
@@ -232,17 +418,53 @@ var _ = Describe("Transport Parameters", func() {
 				Expect(values).To(HaveLen(5))
 				Expect(values).To(HaveKeyWithValue(initialMaxStreamDataParameterID, []byte{0xde, 0xad, 0xbe, 0xef}))
 				Expect(values).To(HaveKeyWithValue(initialMaxDataParameterID, []byte{0xde, 0xca, 0xfb, 0xad}))
-				Expect(values).To(HaveKeyWithValue(initialMaxStreamIDBiDiParameterID, []byte{0xff, 0xff, 0xff, 0xff}))
+				Expect(values).To(HaveKeyWithValue(initialMaxStreamIDBiDiParameterID, []byte{0, 0, 0x13, 0x37}))
 				Expect(values).To(HaveKeyWithValue(idleTimeoutParameterID, []byte{0xca, 0xfe}))
 				Expect(values).To(HaveKeyWithValue(maxPacketSizeParameterID, []byte{0x5, 0xac})) // 1452 = 0x5ac
 				Expect(values).ToNot(HaveKey(initialMaxStreamIDUniParameterID))
 			})
 
+			It("announces the maximum packet size", func() {
+				params.MaxPacketSize = 9000
+				values := paramsListToMap(params.getTransportParameters())
+				Expect(values).To(HaveKeyWithValue(maxPacketSizeParameterID, []byte{0x23, 0x28})) // 9000 = 0x2328
+			})
+
 			It("request ommision of the connection ID", func() {
 				params.OmitConnectionID = true
 				values := paramsListToMap(params.getTransportParameters())
 				Expect(values).To(HaveKeyWithValue(omitConnectionIDParameterID, []byte{}))
 			})
+
+			It("announces support for FEC", func() {
+				params.FEC = true
+				values := paramsListToMap(params.getTransportParameters())
+				Expect(values).To(HaveKeyWithValue(fecParameterID, []byte{}))
+			})
+
+			It("announces support for multipath", func() {
+				params.Multipath = true
+				values := paramsListToMap(params.getTransportParameters())
+				Expect(values).To(HaveKeyWithValue(multipathParameterID, []byte{}))
+			})
+
+			It("announces the max_ack_delay", func() {
+				params.MaxAckDelay = 40 * time.Millisecond
+				values := paramsListToMap(params.getTransportParameters())
+				Expect(values).To(HaveKeyWithValue(maxAckDelayParameterID, []byte{40}))
+			})
+
+			It("announces support for ACK_FREQUENCY frames", func() {
+				params.AckFrequency = true
+				values := paramsListToMap(params.getTransportParameters())
+				Expect(values).To(HaveKeyWithValue(ackFrequencyParameterID, []byte{}))
+			})
+
+			It("announces support for EXPIRED_STREAM_DATA frames", func() {
+				params.ExpiredStreamData = true
+				values := paramsListToMap(params.getTransportParameters())
+				Expect(values).To(HaveKeyWithValue(expiredStreamDataParameterID, []byte{}))
+			})
 		})
 	})
 })
@@ -5,7 +5,6 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
-	"math"
 	"time"
 
 	"github.com/lucas-clemente/quic-go/internal/protocol"
@@ -22,9 +21,25 @@ type TransportParameters struct {
 	ConnectionFlowControlWindow protocol.ByteCount
 
 	MaxStreams uint32
+	// MaxBidiStreamID is the highest stream ID that the peer allows us to open (only used for IETF QUIC)
+	MaxBidiStreamID protocol.StreamID
+	// MaxPacketSize is the largest packet that the peer is willing to receive (only used for IETF QUIC)
+	// It is zero if the peer didn't send a limit. When sending the parameters, zero means protocol.MaxReceivePacketSize.
+	MaxPacketSize protocol.ByteCount
 
 	OmitConnectionID bool
 	IdleTimeout      time.Duration
+	// FEC is set if the peer is able to recover lost packets from repair packets
+	FEC bool
+	// Multipath is set if the peer is able to use multiple paths
+	Multipath bool
+	// MaxAckDelay is the maximum time that the peer delays ACKs for retransmittable packets.
+	// It is zero if the peer didn't announce it.
+	MaxAckDelay time.Duration
+	// AckFrequency is set if the peer accepts ACK_FREQUENCY frames, which change its ACK policy
+	AckFrequency bool
+	// ExpiredStreamData is set if the peer accepts EXPIRED_STREAM_DATA frames, which skip data past its delivery deadline
+	ExpiredStreamData bool
 }
 
 // readHelloMap reads the transport parameters from the tags sent in a gQUIC handshake message
@@ -37,6 +52,25 @@ func readHelloMap(tags map[Tag][]byte) (*TransportParameters, error) {
 		}
 		params.OmitConnectionID = (v == 0)
 	}
+	if _, ok := tags[TagFECS]; ok {
+		params.FEC = true
+	}
+	if _, ok := tags[TagMPTH]; ok {
+		params.Multipath = true
+	}
+	if _, ok := tags[TagAFRQ]; ok {
+		params.AckFrequency = true
+	}
+	if _, ok := tags[TagEXSD]; ok {
+		params.ExpiredStreamData = true
+	}
+	if value, ok := tags[TagMXAD]; ok {
+		v, err := utils.LittleEndian.ReadUint32(bytes.NewBuffer(value))
+		if err != nil {
+			return nil, errMalformedTag
+		}
+		params.MaxAckDelay = utils.MinDuration(time.Duration(v)*time.Millisecond, protocol.MaxAckDelayLimit)
+	}
 	if value, ok := tags[TagMIDS]; ok {
 		v, err := utils.LittleEndian.ReadUint32(bytes.NewBuffer(value))
 		if err != nil {
@@ -88,6 +122,23 @@ func (p *TransportParameters) getHelloMap() map[Tag][]byte {
 	if p.OmitConnectionID {
 		tags[TagTCID] = []byte{0, 0, 0, 0}
 	}
+	if p.FEC {
+		tags[TagFECS] = []byte{}
+	}
+	if p.Multipath {
+		tags[TagMPTH] = []byte{}
+	}
+	if p.MaxAckDelay != 0 {
+		mxad := bytes.NewBuffer([]byte{})
+		utils.LittleEndian.WriteUint32(mxad, uint32(p.MaxAckDelay/time.Millisecond))
+		tags[TagMXAD] = mxad.Bytes()
+	}
+	if p.AckFrequency {
+		tags[TagAFRQ] = []byte{}
+	}
+	if p.ExpiredStreamData {
+		tags[TagEXSD] = []byte{}
+	}
 	return tags
 }
 
@@ -117,7 +168,7 @@ func readTransportParamters(paramsList []transportParameter) (*TransportParamete
 			if len(p.Value) != 4 {
 				return nil, fmt.Errorf("wrong length for initial_max_stream_id_bidi: %d (expected 4)", len(p.Value))
 			}
-			// TODO: handle this value
+			params.MaxBidiStreamID = protocol.StreamID(binary.BigEndian.Uint32(p.Value))
 		case initialMaxStreamIDUniParameterID:
 			if len(p.Value) != 4 {
 				return nil, fmt.Errorf("wrong length for initial_max_stream_id_uni: %d (expected 4)", len(p.Value))
@@ -129,11 +180,45 @@ func readTransportParamters(paramsList []transportParameter) (*TransportParamete
 				return nil, fmt.Errorf("wrong length for idle_timeout: %d (expected 2)", len(p.Value))
 			}
 			params.IdleTimeout = utils.MaxDuration(protocol.MinRemoteIdleTimeout, time.Duration(binary.BigEndian.Uint16(p.Value))*time.Second)
+		case maxPacketSizeParameterID:
+			if len(p.Value) != 2 {
+				return nil, fmt.Errorf("wrong length for max_packet_size: %d (expected 2)", len(p.Value))
+			}
+			maxPacketSize := protocol.ByteCount(binary.BigEndian.Uint16(p.Value))
+			if maxPacketSize < protocol.MaxPacketSize {
+				return nil, fmt.Errorf("invalid value for max_packet_size: %d (minimum %d)", maxPacketSize, protocol.MaxPacketSize)
+			}
+			params.MaxPacketSize = maxPacketSize
 		case omitConnectionIDParameterID:
 			if len(p.Value) != 0 {
 				return nil, fmt.Errorf("wrong length for omit_connection_id: %d (expected empty)", len(p.Value))
 			}
 			params.OmitConnectionID = true
+		case fecParameterID:
+			if len(p.Value) != 0 {
+				return nil, fmt.Errorf("wrong length for fec: %d (expected empty)", len(p.Value))
+			}
+			params.FEC = true
+		case multipathParameterID:
+			if len(p.Value) != 0 {
+				return nil, fmt.Errorf("wrong length for multipath: %d (expected empty)", len(p.Value))
+			}
+			params.Multipath = true
+		case maxAckDelayParameterID:
+			if len(p.Value) != 1 {
+				return nil, fmt.Errorf("wrong length for max_ack_delay: %d (expected 1)", len(p.Value))
+			}
+			params.MaxAckDelay = time.Duration(p.Value[0]) * time.Millisecond
+		case ackFrequencyParameterID:
+			if len(p.Value) != 0 {
+				return nil, fmt.Errorf("wrong length for ack_frequency: %d (expected empty)", len(p.Value))
+			}
+			params.AckFrequency = true
+		case expiredStreamDataParameterID:
+			if len(p.Value) != 0 {
+				return nil, fmt.Errorf("wrong length for expired_stream_data: %d (expected empty)", len(p.Value))
+			}
+			params.ExpiredStreamData = true
 		}
 	}
 
@@ -151,12 +236,15 @@ func (p *TransportParameters) getTransportParameters() []transportParameter {
 	initialMaxData := make([]byte, 4)
 	binary.BigEndian.PutUint32(initialMaxData, uint32(p.ConnectionFlowControlWindow))
 	initialMaxStreamIDBiDi := make([]byte, 4)
-	// TODO: use a reasonable value here
-	binary.BigEndian.PutUint32(initialMaxStreamIDBiDi, math.MaxUint32)
+	binary.BigEndian.PutUint32(initialMaxStreamIDBiDi, uint32(p.MaxBidiStreamID))
 	idleTimeout := make([]byte, 2)
 	binary.BigEndian.PutUint16(idleTimeout, uint16(p.IdleTimeout/time.Second))
 	maxPacketSize := make([]byte, 2)
-	binary.BigEndian.PutUint16(maxPacketSize, uint16(protocol.MaxReceivePacketSize))
+	if p.MaxPacketSize != 0 {
+		binary.BigEndian.PutUint16(maxPacketSize, uint16(p.MaxPacketSize))
+	} else {
+		binary.BigEndian.PutUint16(maxPacketSize, uint16(protocol.MaxReceivePacketSize))
+	}
 	params := []transportParameter{
 		{initialMaxStreamDataParameterID, initialMaxStreamData},
 		{initialMaxDataParameterID, initialMaxData},
@@ -167,5 +255,20 @@ func (p *TransportParameters) getTransportParameters() []transportParameter {
 	if p.OmitConnectionID {
 		params = append(params, transportParameter{omitConnectionIDParameterID, []byte{}})
 	}
+	if p.FEC {
+		params = append(params, transportParameter{fecParameterID, []byte{}})
+	}
+	if p.Multipath {
+		params = append(params, transportParameter{multipathParameterID, []byte{}})
+	}
+	if p.MaxAckDelay != 0 {
+		params = append(params, transportParameter{maxAckDelayParameterID, []byte{uint8(p.MaxAckDelay / time.Millisecond)}})
+	}
+	if p.AckFrequency {
+		params = append(params, transportParameter{ackFrequencyParameterID, []byte{}})
+	}
+	if p.ExpiredStreamData {
+		params = append(params, transportParameter{expiredStreamDataParameterID, []byte{}})
+	}
 	return params
 }
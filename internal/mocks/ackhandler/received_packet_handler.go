@@ -70,6 +70,16 @@ func (mr *MockReceivedPacketHandlerMockRecorder) IgnoreBelow(arg0 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IgnoreBelow", reflect.TypeOf((*MockReceivedPacketHandler)(nil).IgnoreBelow), arg0)
 }
 
+// ReceivedECN mocks base method
+func (m *MockReceivedPacketHandler) ReceivedECN(arg0 protocol.ECN) {
+	m.ctrl.Call(m, "ReceivedECN", arg0)
+}
+
+// ReceivedECN indicates an expected call of ReceivedECN
+func (mr *MockReceivedPacketHandlerMockRecorder) ReceivedECN(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceivedECN", reflect.TypeOf((*MockReceivedPacketHandler)(nil).ReceivedECN), arg0)
+}
+
 // ReceivedPacket mocks base method
 func (m *MockReceivedPacketHandler) ReceivedPacket(arg0 protocol.PacketNumber, arg1 time.Time, arg2 bool) error {
 	ret := m.ctrl.Call(m, "ReceivedPacket", arg0, arg1, arg2)
@@ -81,3 +91,13 @@ func (m *MockReceivedPacketHandler) ReceivedPacket(arg0 protocol.PacketNumber, a
 func (mr *MockReceivedPacketHandlerMockRecorder) ReceivedPacket(arg0, arg1, arg2 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceivedPacket", reflect.TypeOf((*MockReceivedPacketHandler)(nil).ReceivedPacket), arg0, arg1, arg2)
 }
+
+// SetAckPolicy mocks base method
+func (m *MockReceivedPacketHandler) SetAckPolicy(arg0 int, arg1 time.Duration, arg2 bool) {
+	m.ctrl.Call(m, "SetAckPolicy", arg0, arg1, arg2)
+}
+
+// SetAckPolicy indicates an expected call of SetAckPolicy
+func (mr *MockReceivedPacketHandlerMockRecorder) SetAckPolicy(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAckPolicy", reflect.TypeOf((*MockReceivedPacketHandler)(nil).SetAckPolicy), arg0, arg1, arg2)
+}
@@ -10,6 +10,7 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	ackhandler "github.com/lucas-clemente/quic-go/internal/ackhandler"
+	congestion "github.com/lucas-clemente/quic-go/internal/congestion"
 	protocol "github.com/lucas-clemente/quic-go/internal/protocol"
 	wire "github.com/lucas-clemente/quic-go/internal/wire"
 )
@@ -37,6 +38,18 @@ func (m *MockSentPacketHandler) EXPECT() *MockSentPacketHandlerMockRecorder {
 	return m.recorder
 }
 
+// BandwidthEstimate mocks base method
+func (m *MockSentPacketHandler) BandwidthEstimate() congestion.Bandwidth {
+	ret := m.ctrl.Call(m, "BandwidthEstimate")
+	ret0, _ := ret[0].(congestion.Bandwidth)
+	return ret0
+}
+
+// BandwidthEstimate indicates an expected call of BandwidthEstimate
+func (mr *MockSentPacketHandlerMockRecorder) BandwidthEstimate() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BandwidthEstimate", reflect.TypeOf((*MockSentPacketHandler)(nil).BandwidthEstimate))
+}
+
 // DequeuePacketForRetransmission mocks base method
 func (m *MockSentPacketHandler) DequeuePacketForRetransmission() *ackhandler.Packet {
 	ret := m.ctrl.Call(m, "DequeuePacketForRetransmission")
@@ -49,6 +62,16 @@ func (mr *MockSentPacketHandlerMockRecorder) DequeuePacketForRetransmission() *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DequeuePacketForRetransmission", reflect.TypeOf((*MockSentPacketHandler)(nil).DequeuePacketForRetransmission))
 }
 
+// EnableECN mocks base method
+func (m *MockSentPacketHandler) EnableECN() {
+	m.ctrl.Call(m, "EnableECN")
+}
+
+// EnableECN indicates an expected call of EnableECN
+func (mr *MockSentPacketHandlerMockRecorder) EnableECN() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableECN", reflect.TypeOf((*MockSentPacketHandler)(nil).EnableECN))
+}
+
 // GetAlarmTimeout mocks base method
 func (m *MockSentPacketHandler) GetAlarmTimeout() time.Time {
 	ret := m.ctrl.Call(m, "GetAlarmTimeout")
@@ -143,6 +166,16 @@ func (mr *MockSentPacketHandlerMockRecorder) SentPacket(arg0 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SentPacket", reflect.TypeOf((*MockSentPacketHandler)(nil).SentPacket), arg0)
 }
 
+// SetAppLimited mocks base method
+func (m *MockSentPacketHandler) SetAppLimited() {
+	m.ctrl.Call(m, "SetAppLimited")
+}
+
+// SetAppLimited indicates an expected call of SetAppLimited
+func (mr *MockSentPacketHandlerMockRecorder) SetAppLimited() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAppLimited", reflect.TypeOf((*MockSentPacketHandler)(nil).SetAppLimited))
+}
+
 // SetHandshakeComplete mocks base method
 func (m *MockSentPacketHandler) SetHandshakeComplete() {
 	m.ctrl.Call(m, "SetHandshakeComplete")
@@ -153,6 +186,16 @@ func (mr *MockSentPacketHandlerMockRecorder) SetHandshakeComplete() *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHandshakeComplete", reflect.TypeOf((*MockSentPacketHandler)(nil).SetHandshakeComplete))
 }
 
+// SetMaxAckDelay mocks base method
+func (m *MockSentPacketHandler) SetMaxAckDelay(arg0 time.Duration) {
+	m.ctrl.Call(m, "SetMaxAckDelay", arg0)
+}
+
+// SetMaxAckDelay indicates an expected call of SetMaxAckDelay
+func (mr *MockSentPacketHandlerMockRecorder) SetMaxAckDelay(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMaxAckDelay", reflect.TypeOf((*MockSentPacketHandler)(nil).SetMaxAckDelay), arg0)
+}
+
 // ShouldSendNumPackets mocks base method
 func (m *MockSentPacketHandler) ShouldSendNumPackets() int {
 	ret := m.ctrl.Call(m, "ShouldSendNumPackets")
@@ -165,6 +208,18 @@ func (mr *MockSentPacketHandlerMockRecorder) ShouldSendNumPackets() *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShouldSendNumPackets", reflect.TypeOf((*MockSentPacketHandler)(nil).ShouldSendNumPackets))
 }
 
+// ShouldSendProbePacket mocks base method
+func (m *MockSentPacketHandler) ShouldSendProbePacket() bool {
+	ret := m.ctrl.Call(m, "ShouldSendProbePacket")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// ShouldSendProbePacket indicates an expected call of ShouldSendProbePacket
+func (mr *MockSentPacketHandlerMockRecorder) ShouldSendProbePacket() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShouldSendProbePacket", reflect.TypeOf((*MockSentPacketHandler)(nil).ShouldSendProbePacket))
+}
+
 // TimeUntilSend mocks base method
 func (m *MockSentPacketHandler) TimeUntilSend() time.Time {
 	ret := m.ctrl.Call(m, "TimeUntilSend")
@@ -9,6 +9,7 @@ import (
 	time "time"
 
 	gomock "github.com/golang/mock/gomock"
+	congestion "github.com/lucas-clemente/quic-go/internal/congestion"
 	protocol "github.com/lucas-clemente/quic-go/internal/protocol"
 )
 
@@ -35,6 +36,18 @@ func (m *MockSendAlgorithm) EXPECT() *MockSendAlgorithmMockRecorder {
 	return m.recorder
 }
 
+// BandwidthEstimate mocks base method
+func (m *MockSendAlgorithm) BandwidthEstimate() congestion.Bandwidth {
+	ret := m.ctrl.Call(m, "BandwidthEstimate")
+	ret0, _ := ret[0].(congestion.Bandwidth)
+	return ret0
+}
+
+// BandwidthEstimate indicates an expected call of BandwidthEstimate
+func (mr *MockSendAlgorithmMockRecorder) BandwidthEstimate() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BandwidthEstimate", reflect.TypeOf((*MockSendAlgorithm)(nil).BandwidthEstimate))
+}
+
 // GetCongestionWindow mocks base method
 func (m *MockSendAlgorithm) GetCongestionWindow() protocol.ByteCount {
 	ret := m.ctrl.Call(m, "GetCongestionWindow")
@@ -57,14 +70,24 @@ func (mr *MockSendAlgorithmMockRecorder) MaybeExitSlowStart() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaybeExitSlowStart", reflect.TypeOf((*MockSendAlgorithm)(nil).MaybeExitSlowStart))
 }
 
-// OnConnectionMigration mocks base method
-func (m *MockSendAlgorithm) OnConnectionMigration() {
-	m.ctrl.Call(m, "OnConnectionMigration")
+// OnBandwidthSample mocks base method
+func (m *MockSendAlgorithm) OnBandwidthSample(arg0 congestion.BandwidthSample) {
+	m.ctrl.Call(m, "OnBandwidthSample", arg0)
+}
+
+// OnBandwidthSample indicates an expected call of OnBandwidthSample
+func (mr *MockSendAlgorithmMockRecorder) OnBandwidthSample(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnBandwidthSample", reflect.TypeOf((*MockSendAlgorithm)(nil).OnBandwidthSample), arg0)
+}
+
+// OnCongestionExperienced mocks base method
+func (m *MockSendAlgorithm) OnCongestionExperienced(arg0 protocol.PacketNumber, arg1 protocol.ByteCount) {
+	m.ctrl.Call(m, "OnCongestionExperienced", arg0, arg1)
 }
 
-// OnConnectionMigration indicates an expected call of OnConnectionMigration
-func (mr *MockSendAlgorithmMockRecorder) OnConnectionMigration() *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnConnectionMigration", reflect.TypeOf((*MockSendAlgorithm)(nil).OnConnectionMigration))
+// OnCongestionExperienced indicates an expected call of OnCongestionExperienced
+func (mr *MockSendAlgorithmMockRecorder) OnCongestionExperienced(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnCongestionExperienced", reflect.TypeOf((*MockSendAlgorithm)(nil).OnCongestionExperienced), arg0, arg1)
 }
 
 // OnPacketAcked mocks base method
@@ -99,56 +122,34 @@ func (mr *MockSendAlgorithmMockRecorder) OnPacketSent(arg0, arg1, arg2, arg3, ar
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnPacketSent", reflect.TypeOf((*MockSendAlgorithm)(nil).OnPacketSent), arg0, arg1, arg2, arg3, arg4)
 }
 
-// OnRetransmissionTimeout mocks base method
-func (m *MockSendAlgorithm) OnRetransmissionTimeout(arg0 bool) {
-	m.ctrl.Call(m, "OnRetransmissionTimeout", arg0)
-}
-
-// OnRetransmissionTimeout indicates an expected call of OnRetransmissionTimeout
-func (mr *MockSendAlgorithmMockRecorder) OnRetransmissionTimeout(arg0 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnRetransmissionTimeout", reflect.TypeOf((*MockSendAlgorithm)(nil).OnRetransmissionTimeout), arg0)
-}
-
-// RetransmissionDelay mocks base method
-func (m *MockSendAlgorithm) RetransmissionDelay() time.Duration {
-	ret := m.ctrl.Call(m, "RetransmissionDelay")
-	ret0, _ := ret[0].(time.Duration)
-	return ret0
-}
-
-// RetransmissionDelay indicates an expected call of RetransmissionDelay
-func (mr *MockSendAlgorithmMockRecorder) RetransmissionDelay() *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetransmissionDelay", reflect.TypeOf((*MockSendAlgorithm)(nil).RetransmissionDelay))
-}
-
-// SetNumEmulatedConnections mocks base method
-func (m *MockSendAlgorithm) SetNumEmulatedConnections(arg0 int) {
-	m.ctrl.Call(m, "SetNumEmulatedConnections", arg0)
+// OnPersistentCongestion mocks base method
+func (m *MockSendAlgorithm) OnPersistentCongestion() {
+	m.ctrl.Call(m, "OnPersistentCongestion")
 }
 
-// SetNumEmulatedConnections indicates an expected call of SetNumEmulatedConnections
-func (mr *MockSendAlgorithmMockRecorder) SetNumEmulatedConnections(arg0 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNumEmulatedConnections", reflect.TypeOf((*MockSendAlgorithm)(nil).SetNumEmulatedConnections), arg0)
+// OnPersistentCongestion indicates an expected call of OnPersistentCongestion
+func (mr *MockSendAlgorithmMockRecorder) OnPersistentCongestion() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnPersistentCongestion", reflect.TypeOf((*MockSendAlgorithm)(nil).OnPersistentCongestion))
 }
 
-// SetSlowStartLargeReduction mocks base method
-func (m *MockSendAlgorithm) SetSlowStartLargeReduction(arg0 bool) {
-	m.ctrl.Call(m, "SetSlowStartLargeReduction", arg0)
+// OnSpuriousLoss mocks base method
+func (m *MockSendAlgorithm) OnSpuriousLoss(arg0 protocol.PacketNumber) {
+	m.ctrl.Call(m, "OnSpuriousLoss", arg0)
 }
 
-// SetSlowStartLargeReduction indicates an expected call of SetSlowStartLargeReduction
-func (mr *MockSendAlgorithmMockRecorder) SetSlowStartLargeReduction(arg0 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSlowStartLargeReduction", reflect.TypeOf((*MockSendAlgorithm)(nil).SetSlowStartLargeReduction), arg0)
+// OnSpuriousLoss indicates an expected call of OnSpuriousLoss
+func (mr *MockSendAlgorithmMockRecorder) OnSpuriousLoss(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnSpuriousLoss", reflect.TypeOf((*MockSendAlgorithm)(nil).OnSpuriousLoss), arg0)
 }
 
-// TimeUntilSend mocks base method
-func (m *MockSendAlgorithm) TimeUntilSend(arg0 protocol.ByteCount) time.Duration {
-	ret := m.ctrl.Call(m, "TimeUntilSend", arg0)
-	ret0, _ := ret[0].(time.Duration)
+// PacingRate mocks base method
+func (m *MockSendAlgorithm) PacingRate(arg0 protocol.ByteCount) congestion.Bandwidth {
+	ret := m.ctrl.Call(m, "PacingRate", arg0)
+	ret0, _ := ret[0].(congestion.Bandwidth)
 	return ret0
 }
 
-// TimeUntilSend indicates an expected call of TimeUntilSend
-func (mr *MockSendAlgorithmMockRecorder) TimeUntilSend(arg0 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TimeUntilSend", reflect.TypeOf((*MockSendAlgorithm)(nil).TimeUntilSend), arg0)
+// PacingRate indicates an expected call of PacingRate
+func (mr *MockSendAlgorithmMockRecorder) PacingRate(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PacingRate", reflect.TypeOf((*MockSendAlgorithm)(nil).PacingRate), arg0)
 }
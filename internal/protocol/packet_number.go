@@ -13,6 +13,13 @@ func InferPacketNumber(packetNumberLength PacketNumberLen, lastPacketNumber Pack
 	)
 }
 
+// NoncePacketNumber returns the packet number that is used to derive the AEAD nonce of a packet.
+// Every path uses its own packet number space. To make sure that nonces are never reused,
+// the path ID is encoded in the most significant byte.
+func NoncePacketNumber(pathID PathID, pn PacketNumber) PacketNumber {
+	return PacketNumber(pathID)<<56 | pn
+}
+
 func closestTo(target, a, b PacketNumber) PacketNumber {
 	if delta(target, a) < delta(target, b) {
 		return a
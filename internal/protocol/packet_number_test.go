@@ -220,4 +220,14 @@ var _ = Describe("packet number calculation", func() {
 			Expect(GetPacketNumberLength(0xFFFFFFFFFFFF)).To(Equal(PacketNumberLen6))
 		})
 	})
+
+	Context("nonces", func() {
+		It("doesn't change the packet number for the initial path", func() {
+			Expect(NoncePacketNumber(InitialPathID, 0x1337)).To(Equal(PacketNumber(0x1337)))
+		})
+
+		It("encodes the path ID in the most significant byte", func() {
+			Expect(NoncePacketNumber(3, 0x1337)).To(Equal(PacketNumber(0x0300000000001337)))
+		})
+	})
 })
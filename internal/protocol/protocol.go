@@ -52,9 +52,44 @@ func (t PacketType) String() string {
 	}
 }
 
+// ECN is the ECN codepoint of a packet, i.e. the two least significant bits of the IPv4 TOS or the IPv6 Traffic Class field
+type ECN uint8
+
+const (
+	// ECNNon is the Not-ECT codepoint, used for packets that are not ECN-capable
+	ECNNon ECN = 0
+	// ECT1 is the ECT(1) codepoint
+	ECT1 ECN = 1
+	// ECT0 is the ECT(0) codepoint
+	ECT0 ECN = 2
+	// ECNCE is the Congestion Experienced codepoint, set by routers instead of dropping a packet
+	ECNCE ECN = 3
+)
+
+func (e ECN) String() string {
+	switch e {
+	case ECNNon:
+		return "Not-ECT"
+	case ECT1:
+		return "ECT(1)"
+	case ECT0:
+		return "ECT(0)"
+	case ECNCE:
+		return "CE"
+	default:
+		return fmt.Sprintf("invalid ECN value: %d", e)
+	}
+}
+
 // A ConnectionID in QUIC
 type ConnectionID uint64
 
+// A PathID identifies a path of a multipath connection
+type PathID uint8
+
+// InitialPathID is the ID of the path that the handshake is performed on
+const InitialPathID PathID = 0
+
 // A StreamID in QUIC
 type StreamID uint64
 
@@ -73,6 +108,10 @@ type ApplicationErrorCode uint16
 // Ethernet's max packet size is 1500 bytes,  1500 - 48 = 1452.
 const MaxReceivePacketSize ByteCount = 1452
 
+// MaxUDPPayloadSize is the largest payload of a UDP datagram.
+// Packets larger than MaxReceivePacketSize can be used if the path supports jumbo frames.
+const MaxUDPPayloadSize ByteCount = 65527
+
 // DefaultTCPMSS is the default maximum packet size used in the Linux TCP implementation.
 // Used in QUIC for congestion window computations in bytes.
 const DefaultTCPMSS ByteCount = 1460
@@ -15,4 +15,14 @@ var _ = Describe("Protocol", func() {
 			Expect(PacketType(10).String()).To(Equal("unknown packet type: 10"))
 		})
 	})
+
+	Context("ECN", func() {
+		It("has the correct string representation", func() {
+			Expect(ECNNon.String()).To(Equal("Not-ECT"))
+			Expect(ECT1.String()).To(Equal("ECT(1)"))
+			Expect(ECT0.String()).To(Equal("ECT(0)"))
+			Expect(ECNCE.String()).To(Equal("CE"))
+			Expect(ECN(42).String()).To(Equal("invalid ECN value: 42"))
+		})
+	})
 })
@@ -2,7 +2,7 @@ package protocol
 
 import "time"
 
-// MaxPacketSize is the maximum packet size that we use for sending packets.
+// MaxPacketSize is the maximum packet size that we use for sending packets, unless path MTU discovery finds that larger packets can be sent.
 // It includes the QUIC packet header, but excludes the UDP and IP header.
 const MaxPacketSize ByteCount = 1200
 
@@ -24,10 +24,14 @@ const MaxUndecryptablePackets = 10
 // This timeout allows the Go scheduler to switch to the Go rountine that reads the crypto stream and to escalate the crypto
 const PublicResetTimeout = 500 * time.Millisecond
 
-// AckSendDelay is the maximum delay that can be applied to an ACK for a retransmittable packet
+// AckSendDelay is the default maximum delay that can be applied to an ACK for a retransmittable packet
 // This is the value Chromium is using
 const AckSendDelay = 25 * time.Millisecond
 
+// MaxAckDelayLimit is the largest maximum ACK delay that can be used.
+// The maximum ACK delay is announced to the peer in milliseconds, in a single byte.
+const MaxAckDelayLimit = 255 * time.Millisecond
+
 // ReceiveStreamFlowControlWindow is the stream-level flow control window for receiving data
 // This is the value that Google servers are using
 const ReceiveStreamFlowControlWindow = (1 << 10) * 32 // 32 kB
@@ -59,8 +63,8 @@ const ConnectionFlowControlMultiplier = 1.5
 // WindowUpdateThreshold is the fraction of the receive window that has to be consumed before an higher offset is advertised to the client
 const WindowUpdateThreshold = 0.25
 
-// MaxIncomingStreams is the maximum number of streams that a peer may open
-const MaxIncomingStreams = 100
+// DefaultMaxIncomingStreams is the maximum number of streams that a peer may open, if not configured otherwise
+const DefaultMaxIncomingStreams = 100
 
 // MaxStreamsMultiplier is the slack the client is allowed for the maximum number of streams per connection, needed e.g. when packets are out of order or dropped. The minimum of this procentual increase and the absolute increment specified by MaxStreamsMinimumIncrement is used.
 const MaxStreamsMultiplier = 1.1
@@ -70,17 +74,28 @@ const MaxStreamsMinimumIncrement = 10
 
 // MaxNewStreamIDDelta is the maximum difference between and a newly opened Stream and the highest StreamID that a client has ever opened
 // note that the number of streams is half this value, since the client can only open streams with open StreamID
-const MaxNewStreamIDDelta = 4 * MaxIncomingStreams
+const MaxNewStreamIDDelta = 4 * DefaultMaxIncomingStreams
 
 // MaxSessionUnprocessedPackets is the max number of packets stored in each session that are not yet processed.
 const MaxSessionUnprocessedPackets = DefaultMaxCongestionWindow
 
+// MaxServerUnprocessedPackets is the max number of packets queued for each packet handling worker of the server.
+// Packets are dropped when the queue is full.
+const MaxServerUnprocessedPackets = 4 * MaxBatchSize
+
+// MaxServerUnprocessedInitialPackets is the max number of IETF QUIC Initial packets queued for the server's handshake workers.
+// Initial packets are dropped when the queue is full.
+const MaxServerUnprocessedInitialPackets = 256
+
 // SkipPacketAveragePeriodLength is the average period length in which one packet number is skipped to prevent an Optimistic ACK attack
 const SkipPacketAveragePeriodLength PacketNumber = 500
 
 // MaxTrackedSkippedPackets is the maximum number of skipped packet numbers the SentPacketHandler keep track of for Optimistic ACK attack mitigation
 const MaxTrackedSkippedPackets = 10
 
+// MaxTrackedLostPackets is the maximum number of packets declared lost that the SentPacketHandler keeps track of to detect spurious losses
+const MaxTrackedLostPackets = 50
+
 // CookieExpiryTime is the valid time of a cookie
 const CookieExpiryTime = 24 * time.Hour
 
@@ -93,12 +108,16 @@ const MaxTrackedReceivedAckRanges = DefaultMaxCongestionWindow
 // MaxNonRetransmittableAcks is the maximum number of packets containing an ACK, but no retransmittable frames, that we send in a row
 const MaxNonRetransmittableAcks = 19
 
-// RetransmittablePacketsBeforeAck is the number of retransmittable that an ACK is sent for
+// RetransmittablePacketsBeforeAck is the default number of retransmittable packets that an ACK is sent for
 const RetransmittablePacketsBeforeAck = 10
 
-// MaxStreamFrameSorterGaps is the maximum number of gaps between received StreamFrames
-// prevents DoS attacks against the streamFrameSorter
-const MaxStreamFrameSorterGaps = 1000
+// MaxStreamReassemblyBufferOverhead is the number of bytes by which the memory used for buffering received stream data
+// may exceed the range of offsets the data lies in (which is limited by the flow control window).
+// It prevents DoS attacks using many small STREAM frames.
+const MaxStreamReassemblyBufferOverhead ByteCount = 64 * (1 << 10) // 64 kB
+
+// MaxExpiredStreamDataRanges is the maximum number of ranges announced in EXPIRED_STREAM_DATA frames that a stream keeps track of
+const MaxExpiredStreamDataRanges = 1000
 
 // CryptoMaxParams is the upper limit for the number of parameters in a crypto message.
 // Value taken from Chrome.
@@ -132,7 +151,64 @@ const NumCachedCertificates = 128
 // 2. it reduces the head-of-line blocking, when a packet is lost
 const MinStreamFrameSize ByteCount = 128
 
+// MaxStreamDataOverhead is the number of bytes of a packet that are not available for stream data.
+// It accounts for the packet header, the AEAD overhead and the STREAM frame header.
+const MaxStreamDataOverhead ByteCount = 64
+
 // MinPacingDelay is the minimum duration that is used for packet pacing
 // If the packet packing frequency is higher, multiple packets might be sent at once.
 // Example: For a packet pacing delay of 20 microseconds, we would send 5 packets at once, wait for 100 microseconds, and so forth.
 const MinPacingDelay time.Duration = 100 * time.Microsecond
+
+// PacingTimerGranularity is the time that the pacing timer might fire late.
+// The pacer allows sending the data that accumulated during that time at once, so that a late timer doesn't reduce the sending rate.
+const PacingTimerGranularity = time.Millisecond
+
+// MinPacingBurst is the minimum number of bytes that the pacer allows to be sent at once
+const MinPacingBurst = 2 * DefaultTCPMSS
+
+// DefaultInitialPacingBurst is the default number of packets that are sent without pacing at the beginning of a connection
+const DefaultInitialPacingBurst = 10
+
+// FECPacketSizeReduction is the number of bytes a packet protected by FEC has to be smaller than MaxPacketSize.
+// This makes sure that a repair packet, which carries a complete protected packet, fits into MaxPacketSize.
+const FECPacketSizeReduction ByteCount = 100
+
+// DefaultFECDataPackets is the default number of packets protected by one block of repair packets
+const DefaultFECDataPackets = 10
+
+// DefaultFECRepairPackets is the default number of repair packets sent for every block
+const DefaultFECRepairPackets = 1
+
+// FECFlushDelay is the time after which an incomplete FEC block is finished, if no more packets are sent
+const FECFlushDelay = 25 * time.Millisecond
+
+// MaxFECDataPackets is the maximum number of packets protected by one block of repair packets
+const MaxFECDataPackets = 32
+
+// MaxFECRepairPackets is the maximum number of repair packets sent for every block
+const MaxFECRepairPackets = 8
+
+// FECReceiveWindow is the number of packet numbers below the largest received packet number
+// for which received packets and repair packets are kept, in order to recover lost packets.
+const FECReceiveWindow PacketNumber = 8 * MaxFECDataPackets
+
+// MaxPaths is the maximum number of paths of a multipath connection, including the initial path
+const MaxPaths = 8
+
+// MTUDiscoveryThreshold is the precision of path MTU discovery.
+// The search ends when the difference between a working and a non-working packet size is smaller than this value.
+const MTUDiscoveryThreshold ByteCount = 20
+
+// MaxMTUProbes is the number of lost MTU probe packets after which a packet size is considered too large for the path
+const MaxMTUProbes = 3
+
+// MTUDiscoveryRestartInterval is the time after which path MTU discovery is restarted, since the path might have changed
+const MTUDiscoveryRestartInterval = 10 * time.Minute
+
+// MaxBatchSize is the maximum number of packets that are read or written with a single system call
+const MaxBatchSize = 64
+
+// MaxPooledPacketBuffers is the maximum number of packet buffers that are kept for reuse.
+// Buffers that are returned once the pool is full are left to the garbage collector.
+const MaxPooledPacketBuffers = 16 * MaxBatchSize
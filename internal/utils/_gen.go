@@ -1,7 +0,0 @@
-package main
-
-import (
-	_ "github.com/clipperhouse/linkedlist"
-	_ "github.com/clipperhouse/slice"
-	_ "github.com/clipperhouse/stringer"
-)
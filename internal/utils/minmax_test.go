@@ -79,6 +79,11 @@ var _ = Describe("Min / Max", func() {
 			Expect(MinByteCount(5, 7)).To(Equal(protocol.ByteCount(5)))
 		})
 
+		It("returns the maximum ByteCount", func() {
+			Expect(MaxByteCount(7, 5)).To(Equal(protocol.ByteCount(7)))
+			Expect(MaxByteCount(5, 7)).To(Equal(protocol.ByteCount(7)))
+		})
+
 		It("returns packet number min", func() {
 			Expect(MinPacketNumber(1, 2)).To(Equal(protocol.PacketNumber(1)))
 			Expect(MinPacketNumber(2, 1)).To(Equal(protocol.PacketNumber(1)))
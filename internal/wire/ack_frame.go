@@ -12,11 +12,18 @@ import (
 // TODO: use the value sent in the transport parameters
 const ackDelayExponent = 3
 
+// ackECNFrameType is the type byte of an ACK frame that carries ECN counts.
+// The ACK_ECN frame is not part of the QUIC version we implement, the type byte is taken from a later draft.
+const ackECNFrameType = 0x1a
+
 // An AckFrame is an ACK frame
 type AckFrame struct {
 	LargestAcked protocol.PacketNumber
 	LowestAcked  protocol.PacketNumber
 	AckRanges    []AckRange // has to be ordered. The highest ACK range goes first, the lowest ACK range goes last
+	// ECN are the ECN counts of the received packets (only used for IETF QUIC).
+	// If set, the frame is sent as an ACK_ECN frame.
+	ECN *ECNCounts
 
 	// time when the LargestAcked was receiveid
 	// this field will not be set for received ACKs frames
@@ -24,41 +31,59 @@ type AckFrame struct {
 	DelayTime          time.Duration
 }
 
+// ECNCounts are the number of received packets for each ECN codepoint
+type ECNCounts struct {
+	ECT0 uint64
+	ECT1 uint64
+	CE   uint64
+}
+
 // ParseAckFrame reads an ACK frame
 func ParseAckFrame(r *bytes.Reader, version protocol.VersionNumber) (*AckFrame, error) {
-	if !version.UsesIETFFrameFormat() {
-		return parseAckFrameLegacy(r, version)
+	frame := &AckFrame{}
+	if err := ParseAckFrameInto(r, frame, version); err != nil {
+		return nil, err
 	}
+	return frame, nil
+}
 
-	if _, err := r.ReadByte(); err != nil {
-		return nil, err
+// ParseAckFrameInto is like ParseAckFrame, but it parses the frame into frame instead of allocating a new AckFrame.
+// The memory backing frame.AckRanges and the ECNCounts that frame.ECN points to are reused.
+func ParseAckFrameInto(r *bytes.Reader, frame *AckFrame, version protocol.VersionNumber) error {
+	ecn := frame.ECN
+	*frame = AckFrame{AckRanges: frame.AckRanges[:0]}
+	if !version.UsesIETFFrameFormat() {
+		return parseAckFrameLegacy(r, frame, version)
 	}
 
-	frame := &AckFrame{}
+	typeByte, err := r.ReadByte()
+	if err != nil {
+		return err
+	}
 
 	largestAcked, err := utils.ReadVarInt(r)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	frame.LargestAcked = protocol.PacketNumber(largestAcked)
 	delay, err := utils.ReadVarInt(r)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	frame.DelayTime = time.Duration(delay*1<<ackDelayExponent) * time.Microsecond
 	numBlocks, err := utils.ReadVarInt(r)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// read the first ACK range
 	ab, err := utils.ReadVarInt(r)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	ackBlock := protocol.PacketNumber(ab)
 	if ackBlock > frame.LargestAcked {
-		return nil, errors.New("invalid first ACK range")
+		return errors.New("invalid first ACK range")
 	}
 	smallest := frame.LargestAcked - protocol.PacketNumber(ackBlock)
 
@@ -69,22 +94,22 @@ func ParseAckFrame(r *bytes.Reader, version protocol.VersionNumber) (*AckFrame,
 	for i := uint64(0); i < numBlocks; i++ {
 		g, err := utils.ReadVarInt(r)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		gap := protocol.PacketNumber(g)
 		if smallest < gap+2 {
-			return nil, errInvalidAckRanges
+			return errInvalidAckRanges
 		}
 		largest := smallest - gap - 2
 
 		ab, err := utils.ReadVarInt(r)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		ackBlock := protocol.PacketNumber(ab)
 
 		if ackBlock > largest {
-			return nil, errInvalidAckRanges
+			return errInvalidAckRanges
 		}
 		smallest = largest - protocol.PacketNumber(ackBlock)
 		frame.AckRanges = append(frame.AckRanges, AckRange{First: smallest, Last: largest})
@@ -92,10 +117,24 @@ func ParseAckFrame(r *bytes.Reader, version protocol.VersionNumber) (*AckFrame,
 
 	frame.LowestAcked = smallest
 	if !frame.validateAckRanges() {
-		return nil, errInvalidAckRanges
+		return errInvalidAckRanges
 	}
 
-	return frame, nil
+	// an ACK_ECN frame carries the ECN counts after the ACK ranges
+	if typeByte == ackECNFrameType {
+		if ecn == nil {
+			ecn = &ECNCounts{}
+		}
+		frame.ECN = ecn
+		for _, count := range []*uint64{&frame.ECN.ECT0, &frame.ECN.ECT1, &frame.ECN.CE} {
+			c, err := utils.ReadVarInt(r)
+			if err != nil {
+				return err
+			}
+			*count = c
+		}
+	}
+	return nil
 }
 
 // Write writes an ACK frame.
@@ -104,7 +143,11 @@ func (f *AckFrame) Write(b *bytes.Buffer, version protocol.VersionNumber) error
 		return f.writeLegacy(b, version)
 	}
 
-	b.WriteByte(0xe)
+	if f.ECN != nil {
+		b.WriteByte(ackECNFrameType)
+	} else {
+		b.WriteByte(0xe)
+	}
 	utils.WriteVarInt(b, uint64(f.LargestAcked))
 	utils.WriteVarInt(b, encodeAckDelay(f.DelayTime))
 
@@ -122,18 +165,22 @@ func (f *AckFrame) Write(b *bytes.Buffer, version protocol.VersionNumber) error
 	utils.WriteVarInt(b, uint64(f.LargestAcked-lowestInFirstRange))
 
 	// write all the other range
-	if !f.HasMissingRanges() {
-		return nil
-	}
-	var lowest protocol.PacketNumber
-	for i, ackRange := range f.AckRanges {
-		if i == 0 {
-			lowest = lowestInFirstRange
-			continue
+	if f.HasMissingRanges() {
+		var lowest protocol.PacketNumber
+		for i, ackRange := range f.AckRanges {
+			if i == 0 {
+				lowest = lowestInFirstRange
+				continue
+			}
+			utils.WriteVarInt(b, uint64(lowest-ackRange.Last-2))
+			utils.WriteVarInt(b, uint64(ackRange.Last-ackRange.First))
+			lowest = ackRange.First
 		}
-		utils.WriteVarInt(b, uint64(lowest-ackRange.Last-2))
-		utils.WriteVarInt(b, uint64(ackRange.Last-ackRange.First))
-		lowest = ackRange.First
+	}
+	if f.ECN != nil {
+		utils.WriteVarInt(b, f.ECN.ECT0)
+		utils.WriteVarInt(b, f.ECN.ECT1)
+		utils.WriteVarInt(b, f.ECN.CE)
 	}
 	return nil
 }
@@ -156,18 +203,20 @@ func (f *AckFrame) MinLength(version protocol.VersionNumber) protocol.ByteCount
 	}
 	length += utils.VarIntLen(uint64(f.LargestAcked - lowestInFirstRange))
 
-	if !f.HasMissingRanges() {
-		return length
-	}
-	var lowest protocol.PacketNumber
-	for i, ackRange := range f.AckRanges {
-		if i == 0 {
+	if f.HasMissingRanges() {
+		var lowest protocol.PacketNumber
+		for i, ackRange := range f.AckRanges {
+			if i == 0 {
+				lowest = ackRange.First
+				continue
+			}
+			length += utils.VarIntLen(uint64(lowest - ackRange.Last - 2))
+			length += utils.VarIntLen(uint64(ackRange.Last - ackRange.First))
 			lowest = ackRange.First
-			continue
 		}
-		length += utils.VarIntLen(uint64(lowest - ackRange.Last - 2))
-		length += utils.VarIntLen(uint64(ackRange.Last - ackRange.First))
-		lowest = ackRange.First
+	}
+	if f.ECN != nil {
+		length += utils.VarIntLen(f.ECN.ECT0) + utils.VarIntLen(f.ECN.ECT1) + utils.VarIntLen(f.ECN.CE)
 	}
 	return length
 }
@@ -15,12 +15,10 @@ var (
 	errInvalidAckRanges            = errors.New("AckFrame: ACK frame contains invalid ACK ranges")
 )
 
-func parseAckFrameLegacy(r *bytes.Reader, _ protocol.VersionNumber) (*AckFrame, error) {
-	frame := &AckFrame{}
-
+func parseAckFrameLegacy(r *bytes.Reader, frame *AckFrame, _ protocol.VersionNumber) error {
 	typeByte, err := r.ReadByte()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	hasMissingRanges := false
@@ -40,13 +38,13 @@ func parseAckFrameLegacy(r *bytes.Reader, _ protocol.VersionNumber) (*AckFrame,
 
 	largestAcked, err := utils.BigEndian.ReadUintN(r, largestAckedLen)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	frame.LargestAcked = protocol.PacketNumber(largestAcked)
 
 	delay, err := utils.BigEndian.ReadUfloat16(r)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	frame.DelayTime = time.Duration(delay) * time.Microsecond
 
@@ -54,24 +52,24 @@ func parseAckFrameLegacy(r *bytes.Reader, _ protocol.VersionNumber) (*AckFrame,
 	if hasMissingRanges {
 		numAckBlocks, err = r.ReadByte()
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
 
 	if hasMissingRanges && numAckBlocks == 0 {
-		return nil, errInvalidAckRanges
+		return errInvalidAckRanges
 	}
 
 	ackBlockLength, err := utils.BigEndian.ReadUintN(r, missingSequenceNumberDeltaLen)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if frame.LargestAcked > 0 && ackBlockLength < 1 {
-		return nil, errors.New("invalid first ACK range")
+		return errors.New("invalid first ACK range")
 	}
 
 	if ackBlockLength > largestAcked+1 {
-		return nil, errInvalidAckRanges
+		return errInvalidAckRanges
 	}
 
 	if hasMissingRanges {
@@ -87,12 +85,12 @@ func parseAckFrameLegacy(r *bytes.Reader, _ protocol.VersionNumber) (*AckFrame,
 			var gap uint8
 			gap, err = r.ReadByte()
 			if err != nil {
-				return nil, err
+				return err
 			}
 
 			ackBlockLength, err = utils.BigEndian.ReadUintN(r, missingSequenceNumberDeltaLen)
 			if err != nil {
-				return nil, err
+				return err
 			}
 
 			length := protocol.PacketNumber(ackBlockLength)
@@ -132,42 +130,42 @@ func parseAckFrameLegacy(r *bytes.Reader, _ protocol.VersionNumber) (*AckFrame,
 	}
 
 	if !frame.validateAckRanges() {
-		return nil, errInvalidAckRanges
+		return errInvalidAckRanges
 	}
 
 	var numTimestamp byte
 	numTimestamp, err = r.ReadByte()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if numTimestamp > 0 {
 		// Delta Largest acked
 		_, err = r.ReadByte()
 		if err != nil {
-			return nil, err
+			return err
 		}
 		// First Timestamp
 		_, err = utils.BigEndian.ReadUint32(r)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		for i := 0; i < int(numTimestamp)-1; i++ {
 			// Delta Largest acked
 			_, err = r.ReadByte()
 			if err != nil {
-				return nil, err
+				return err
 			}
 
 			// Time Since Previous Timestamp
 			_, err = utils.BigEndian.ReadUint16(r)
 			if err != nil {
-				return nil, err
+				return err
 			}
 		}
 	}
-	return frame, nil
+	return nil
 }
 
 func (f *AckFrame) writeLegacy(b *bytes.Buffer, _ protocol.VersionNumber) error {
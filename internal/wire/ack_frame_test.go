@@ -113,6 +113,77 @@ var _ = Describe("ACK Frame (for IETF QUIC)", func() {
 			Expect(b.Len()).To(BeZero())
 		})
 
+		It("parses an ACK_ECN frame", func() {
+			data := []byte{0x1a}
+			data = append(data, encodeVarInt(100)...)  // largest acked
+			data = append(data, encodeVarInt(0)...)    // delay
+			data = append(data, encodeVarInt(0)...)    // num blocks
+			data = append(data, encodeVarInt(10)...)   // first ack block
+			data = append(data, encodeVarInt(42)...)   // ECT(0)
+			data = append(data, encodeVarInt(1)...)    // ECT(1)
+			data = append(data, encodeVarInt(1337)...) // CE
+			b := bytes.NewReader(data)
+			frame, err := ParseAckFrame(b, versionIETFFrames)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame.LargestAcked).To(Equal(protocol.PacketNumber(100)))
+			Expect(frame.LowestAcked).To(Equal(protocol.PacketNumber(90)))
+			Expect(frame.ECN).To(Equal(&ECNCounts{ECT0: 42, ECT1: 1, CE: 1337}))
+			Expect(b.Len()).To(BeZero())
+		})
+
+		It("errors on EOF in the ECN counts", func() {
+			data := []byte{0x1a}
+			data = append(data, encodeVarInt(100)...) // largest acked
+			data = append(data, encodeVarInt(0)...)   // delay
+			data = append(data, encodeVarInt(0)...)   // num blocks
+			data = append(data, encodeVarInt(10)...)  // first ack block
+			data = append(data, encodeVarInt(42)...)  // ECT(0)
+			data = append(data, encodeVarInt(1)...)   // ECT(1)
+			data = append(data, encodeVarInt(3)...)   // CE
+			_, err := ParseAckFrame(bytes.NewReader(data), versionIETFFrames)
+			Expect(err).NotTo(HaveOccurred())
+			for i := range data {
+				_, err := ParseAckFrame(bytes.NewReader(data[0:i]), versionIETFFrames)
+				Expect(err).To(MatchError(io.EOF))
+			}
+		})
+
+		It("parses into an existing frame, reusing its ACK ranges and ECN counts", func() {
+			data := []byte{0x1a}
+			data = append(data, encodeVarInt(1000)...) // largest acked
+			data = append(data, encodeVarInt(0)...)    // delay
+			data = append(data, encodeVarInt(1)...)    // num blocks
+			data = append(data, encodeVarInt(100)...)  // first ack block
+			data = append(data, encodeVarInt(98)...)   // gap
+			data = append(data, encodeVarInt(50)...)   // ack block
+			data = append(data, encodeVarInt(42)...)   // ECT(0)
+			data = append(data, encodeVarInt(1)...)    // ECT(1)
+			data = append(data, encodeVarInt(3)...)    // CE
+			ranges := make([]AckRange, 0, 5)
+			ecn := &ECNCounts{}
+			frame := &AckFrame{AckRanges: ranges, ECN: ecn, DelayTime: time.Second}
+			Expect(ParseAckFrameInto(bytes.NewReader(data), frame, versionIETFFrames)).To(Succeed())
+			Expect(frame.LargestAcked).To(Equal(protocol.PacketNumber(1000)))
+			Expect(frame.LowestAcked).To(Equal(protocol.PacketNumber(750)))
+			Expect(frame.DelayTime).To(BeZero())
+			Expect(frame.AckRanges).To(Equal([]AckRange{
+				{Last: 1000, First: 900},
+				{Last: 800, First: 750},
+			}))
+			Expect(&frame.AckRanges[0]).To(BeIdenticalTo(&ranges[:1][0]))
+			Expect(frame.ECN).To(BeIdenticalTo(ecn))
+			Expect(*ecn).To(Equal(ECNCounts{ECT0: 42, ECT1: 1, CE: 3}))
+			// parse an ACK frame without ranges and ECN counts into the same frame
+			data = []byte{0xe}
+			data = append(data, encodeVarInt(100)...) // largest acked
+			data = append(data, encodeVarInt(0)...)   // delay
+			data = append(data, encodeVarInt(0)...)   // num blocks
+			data = append(data, encodeVarInt(10)...)  // first ack block
+			Expect(ParseAckFrameInto(bytes.NewReader(data), frame, versionIETFFrames)).To(Succeed())
+			Expect(frame.HasMissingRanges()).To(BeFalse())
+			Expect(frame.ECN).To(BeNil())
+		})
+
 		It("errors on EOF", func() {
 			data := []byte{0xe}
 			data = append(data, encodeVarInt(1000)...) // largest acked
@@ -211,6 +282,28 @@ var _ = Describe("ACK Frame (for IETF QUIC)", func() {
 			Expect(frame.HasMissingRanges()).To(BeTrue())
 			Expect(b.Len()).To(BeZero())
 		})
+
+		It("writes an ACK_ECN frame", func() {
+			buf := &bytes.Buffer{}
+			f := &AckFrame{
+				LargestAcked: 10,
+				LowestAcked:  1,
+				AckRanges: []AckRange{
+					{First: 8, Last: 10},
+					{First: 1, Last: 3},
+				},
+				ECN: &ECNCounts{ECT0: 0x1337, ECT1: 0, CE: 0xdeadbeef},
+			}
+			err := f.Write(buf, versionIETFFrames)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(buf.Bytes()[0]).To(BeEquivalentTo(0x1a))
+			Expect(f.MinLength(versionIETFFrames)).To(BeEquivalentTo(buf.Len()))
+			b := bytes.NewReader(buf.Bytes())
+			frame, err := ParseAckFrame(b, versionIETFFrames)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame).To(Equal(f))
+			Expect(b.Len()).To(BeZero())
+		})
 	})
 
 	Context("ACK range validator", func() {
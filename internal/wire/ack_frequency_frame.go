@@ -0,0 +1,109 @@
+package wire
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+)
+
+// An AckFrequencyFrame is an ACK_FREQUENCY frame.
+// It is not part of any QUIC version, but an extension used to change the ACK policy of the receiver.
+// The receiver only applies a frame if its sequence number is larger than the sequence numbers of all frames received before,
+// so that retransmitted frames don't overwrite a more recent policy.
+type AckFrequencyFrame struct {
+	SequenceNumber uint64
+	// PacketThreshold is the number of retransmittable packets that the receiver receives before it sends an ACK
+	PacketThreshold uint64
+	// MaxAckDelay is the maximum time that the receiver delays an ACK for a retransmittable packet
+	MaxAckDelay time.Duration
+	// IgnoreReordering tells the receiver not to send an ACK immediately when packets arrive out of order
+	IgnoreReordering bool
+}
+
+// ParseAckFrequencyFrame parses an ACK_FREQUENCY frame
+func ParseAckFrequencyFrame(r *bytes.Reader, version protocol.VersionNumber) (*AckFrequencyFrame, error) {
+	if _, err := r.ReadByte(); err != nil { // read the TypeByte
+		return nil, err
+	}
+
+	frame := &AckFrequencyFrame{}
+	if version.UsesIETFFrameFormat() {
+		seq, err := utils.ReadVarInt(r)
+		if err != nil {
+			return nil, err
+		}
+		frame.SequenceNumber = seq
+		threshold, err := utils.ReadVarInt(r)
+		if err != nil {
+			return nil, err
+		}
+		frame.PacketThreshold = threshold
+		delay, err := utils.ReadVarInt(r)
+		if err != nil {
+			return nil, err
+		}
+		frame.MaxAckDelay = time.Duration(delay) * time.Microsecond
+	} else {
+		seq, err := utils.BigEndian.ReadUint32(r)
+		if err != nil {
+			return nil, err
+		}
+		frame.SequenceNumber = uint64(seq)
+		threshold, err := utils.BigEndian.ReadUint32(r)
+		if err != nil {
+			return nil, err
+		}
+		frame.PacketThreshold = uint64(threshold)
+		delay, err := utils.BigEndian.ReadUint32(r)
+		if err != nil {
+			return nil, err
+		}
+		frame.MaxAckDelay = time.Duration(delay) * time.Microsecond
+	}
+	ignoreReordering, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch ignoreReordering {
+	case 0:
+	case 1:
+		frame.IgnoreReordering = true
+	default:
+		return nil, errors.New("ACK_FREQUENCY: invalid value for ignore reordering")
+	}
+	if frame.PacketThreshold == 0 {
+		return nil, errors.New("ACK_FREQUENCY: packet threshold must not be 0")
+	}
+	return frame, nil
+}
+
+// Write writes an ACK_FREQUENCY frame
+func (f *AckFrequencyFrame) Write(b *bytes.Buffer, version protocol.VersionNumber) error {
+	b.WriteByte(0x1b)
+	if version.UsesIETFFrameFormat() {
+		utils.WriteVarInt(b, f.SequenceNumber)
+		utils.WriteVarInt(b, f.PacketThreshold)
+		utils.WriteVarInt(b, uint64(f.MaxAckDelay/time.Microsecond))
+	} else {
+		utils.BigEndian.WriteUint32(b, uint32(f.SequenceNumber))
+		utils.BigEndian.WriteUint32(b, uint32(f.PacketThreshold))
+		utils.BigEndian.WriteUint32(b, uint32(f.MaxAckDelay/time.Microsecond))
+	}
+	if f.IgnoreReordering {
+		b.WriteByte(1)
+	} else {
+		b.WriteByte(0)
+	}
+	return nil
+}
+
+// MinLength of a written frame
+func (f *AckFrequencyFrame) MinLength(version protocol.VersionNumber) protocol.ByteCount {
+	if version.UsesIETFFrameFormat() {
+		return 1 + utils.VarIntLen(f.SequenceNumber) + utils.VarIntLen(f.PacketThreshold) + utils.VarIntLen(uint64(f.MaxAckDelay/time.Microsecond)) + 1
+	}
+	return 1 + 4 + 4 + 4 + 1
+}
@@ -0,0 +1,148 @@
+package wire
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ACK_FREQUENCY frame", func() {
+	Context("when parsing", func() {
+		Context("in varint encoding", func() {
+			It("accepts sample frame", func() {
+				data := []byte{0x1b}
+				data = append(data, encodeVarInt(0x42)...)   // sequence number
+				data = append(data, encodeVarInt(0x1337)...) // packet threshold
+				data = append(data, encodeVarInt(25000)...)  // max ack delay, in microseconds
+				data = append(data, 0x1)                     // ignore reordering
+				b := bytes.NewReader(data)
+				frame, err := ParseAckFrequencyFrame(b, versionIETFFrames)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(frame.SequenceNumber).To(Equal(uint64(0x42)))
+				Expect(frame.PacketThreshold).To(Equal(uint64(0x1337)))
+				Expect(frame.MaxAckDelay).To(Equal(25 * time.Millisecond))
+				Expect(frame.IgnoreReordering).To(BeTrue())
+				Expect(b.Len()).To(BeZero())
+			})
+
+			It("errors on EOFs", func() {
+				data := []byte{0x1b}
+				data = append(data, encodeVarInt(0x42)...)   // sequence number
+				data = append(data, encodeVarInt(0x1337)...) // packet threshold
+				data = append(data, encodeVarInt(25000)...)  // max ack delay, in microseconds
+				data = append(data, 0x0)                     // ignore reordering
+				_, err := ParseAckFrequencyFrame(bytes.NewReader(data), versionIETFFrames)
+				Expect(err).NotTo(HaveOccurred())
+				for i := range data {
+					_, err := ParseAckFrequencyFrame(bytes.NewReader(data[0:i]), versionIETFFrames)
+					Expect(err).To(HaveOccurred())
+				}
+			})
+		})
+
+		Context("in big endian", func() {
+			It("accepts sample frame", func() {
+				b := bytes.NewReader([]byte{0x1b,
+					0x0, 0x0, 0x0, 0x42, // sequence number
+					0x0, 0x0, 0x13, 0x37, // packet threshold
+					0x0, 0x0, 0x61, 0xa8, // max ack delay, in microseconds
+					0x0, // ignore reordering
+				})
+				frame, err := ParseAckFrequencyFrame(b, versionBigEndian)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(frame.SequenceNumber).To(Equal(uint64(0x42)))
+				Expect(frame.PacketThreshold).To(Equal(uint64(0x1337)))
+				Expect(frame.MaxAckDelay).To(Equal(25 * time.Millisecond))
+				Expect(frame.IgnoreReordering).To(BeFalse())
+				Expect(b.Len()).To(BeZero())
+			})
+
+			It("errors on EOFs", func() {
+				data := []byte{0x1b,
+					0x0, 0x0, 0x0, 0x42, // sequence number
+					0x0, 0x0, 0x13, 0x37, // packet threshold
+					0x0, 0x0, 0x61, 0xa8, // max ack delay, in microseconds
+					0x1, // ignore reordering
+				}
+				_, err := ParseAckFrequencyFrame(bytes.NewReader(data), versionBigEndian)
+				Expect(err).NotTo(HaveOccurred())
+				for i := range data {
+					_, err := ParseAckFrequencyFrame(bytes.NewReader(data[0:i]), versionBigEndian)
+					Expect(err).To(HaveOccurred())
+				}
+			})
+		})
+
+		It("errors on an invalid value for ignore reordering", func() {
+			data := []byte{0x1b}
+			data = append(data, encodeVarInt(0x42)...)   // sequence number
+			data = append(data, encodeVarInt(0x1337)...) // packet threshold
+			data = append(data, encodeVarInt(25000)...)  // max ack delay, in microseconds
+			data = append(data, 0x2)                     // ignore reordering
+			_, err := ParseAckFrequencyFrame(bytes.NewReader(data), versionIETFFrames)
+			Expect(err).To(MatchError("ACK_FREQUENCY: invalid value for ignore reordering"))
+		})
+
+		It("errors when the packet threshold is 0", func() {
+			data := []byte{0x1b}
+			data = append(data, encodeVarInt(0x42)...)  // sequence number
+			data = append(data, encodeVarInt(0)...)     // packet threshold
+			data = append(data, encodeVarInt(25000)...) // max ack delay, in microseconds
+			data = append(data, 0x0)                    // ignore reordering
+			_, err := ParseAckFrequencyFrame(bytes.NewReader(data), versionIETFFrames)
+			Expect(err).To(MatchError("ACK_FREQUENCY: packet threshold must not be 0"))
+		})
+	})
+
+	Context("when writing", func() {
+		It("writes a sample frame, in varint encoding", func() {
+			frame := &AckFrequencyFrame{
+				SequenceNumber:   0xdecafbad,
+				PacketThreshold:  0x1337,
+				MaxAckDelay:      10 * time.Millisecond,
+				IgnoreReordering: true,
+			}
+			b := &bytes.Buffer{}
+			err := frame.Write(b, versionIETFFrames)
+			Expect(err).ToNot(HaveOccurred())
+			expected := []byte{0x1b}
+			expected = append(expected, encodeVarInt(0xdecafbad)...)
+			expected = append(expected, encodeVarInt(0x1337)...)
+			expected = append(expected, encodeVarInt(10000)...)
+			expected = append(expected, 0x1)
+			Expect(b.Bytes()).To(Equal(expected))
+			Expect(frame.MinLength(versionIETFFrames)).To(Equal(protocol.ByteCount(len(expected))))
+		})
+
+		It("writes a sample frame, in big endian", func() {
+			frame := &AckFrequencyFrame{
+				SequenceNumber:  0xdecafbad,
+				PacketThreshold: 0x1337,
+				MaxAckDelay:     10 * time.Millisecond,
+			}
+			b := &bytes.Buffer{}
+			err := frame.Write(b, versionBigEndian)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b.Bytes()).To(Equal([]byte{0x1b,
+				0xde, 0xca, 0xfb, 0xad,
+				0x0, 0x0, 0x13, 0x37,
+				0x0, 0x0, 0x27, 0x10,
+				0x0,
+			}))
+			Expect(frame.MinLength(versionBigEndian)).To(Equal(protocol.ByteCount(b.Len())))
+		})
+
+		It("has the correct min length, in varint encoding", func() {
+			frame := &AckFrequencyFrame{
+				SequenceNumber:  0x1337,
+				PacketThreshold: 0x42,
+				MaxAckDelay:     time.Second,
+			}
+			Expect(frame.MinLength(versionIETFFrames)).To(Equal(1 + utils.VarIntLen(0x1337) + utils.VarIntLen(0x42) + utils.VarIntLen(1000000) + 1))
+		})
+	})
+})
@@ -0,0 +1,88 @@
+package wire
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+)
+
+// An ExpiredStreamDataFrame is an EXPIRED_STREAM_DATA frame.
+// It is not part of any QUIC version, but an extension used for partially reliable streams.
+// It tells the receiver that the stream data between Offset and MinimumOffset won't be retransmitted,
+// so that it skips ahead to MinimumOffset once it has read all data up to Offset.
+type ExpiredStreamDataFrame struct {
+	StreamID      protocol.StreamID
+	Offset        protocol.ByteCount
+	MinimumOffset protocol.ByteCount
+}
+
+// ParseExpiredStreamDataFrame parses an EXPIRED_STREAM_DATA frame
+func ParseExpiredStreamDataFrame(r *bytes.Reader, version protocol.VersionNumber) (*ExpiredStreamDataFrame, error) {
+	if _, err := r.ReadByte(); err != nil { // read the TypeByte
+		return nil, err
+	}
+
+	frame := &ExpiredStreamDataFrame{}
+	if version.UsesIETFFrameFormat() {
+		sid, err := utils.ReadVarInt(r)
+		if err != nil {
+			return nil, err
+		}
+		frame.StreamID = protocol.StreamID(sid)
+		offset, err := utils.ReadVarInt(r)
+		if err != nil {
+			return nil, err
+		}
+		frame.Offset = protocol.ByteCount(offset)
+		minOffset, err := utils.ReadVarInt(r)
+		if err != nil {
+			return nil, err
+		}
+		frame.MinimumOffset = protocol.ByteCount(minOffset)
+	} else {
+		sid, err := utils.BigEndian.ReadUint32(r)
+		if err != nil {
+			return nil, err
+		}
+		frame.StreamID = protocol.StreamID(sid)
+		offset, err := utils.BigEndian.ReadUint64(r)
+		if err != nil {
+			return nil, err
+		}
+		frame.Offset = protocol.ByteCount(offset)
+		minOffset, err := utils.BigEndian.ReadUint64(r)
+		if err != nil {
+			return nil, err
+		}
+		frame.MinimumOffset = protocol.ByteCount(minOffset)
+	}
+	if frame.MinimumOffset < frame.Offset {
+		return nil, errors.New("EXPIRED_STREAM_DATA: minimum offset smaller than offset")
+	}
+	return frame, nil
+}
+
+// Write writes an EXPIRED_STREAM_DATA frame
+func (f *ExpiredStreamDataFrame) Write(b *bytes.Buffer, version protocol.VersionNumber) error {
+	b.WriteByte(0x18)
+	if version.UsesIETFFrameFormat() {
+		utils.WriteVarInt(b, uint64(f.StreamID))
+		utils.WriteVarInt(b, uint64(f.Offset))
+		utils.WriteVarInt(b, uint64(f.MinimumOffset))
+	} else {
+		utils.BigEndian.WriteUint32(b, uint32(f.StreamID))
+		utils.BigEndian.WriteUint64(b, uint64(f.Offset))
+		utils.BigEndian.WriteUint64(b, uint64(f.MinimumOffset))
+	}
+	return nil
+}
+
+// MinLength of a written frame
+func (f *ExpiredStreamDataFrame) MinLength(version protocol.VersionNumber) protocol.ByteCount {
+	if version.UsesIETFFrameFormat() {
+		return 1 + utils.VarIntLen(uint64(f.StreamID)) + utils.VarIntLen(uint64(f.Offset)) + utils.VarIntLen(uint64(f.MinimumOffset))
+	}
+	return 1 + 4 + 8 + 8
+}
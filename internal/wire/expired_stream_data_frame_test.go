@@ -0,0 +1,127 @@
+package wire
+
+import (
+	"bytes"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EXPIRED_STREAM_DATA frame", func() {
+	Context("when parsing", func() {
+		Context("in varint encoding", func() {
+			It("accepts sample frame", func() {
+				data := []byte{0x18}
+				data = append(data, encodeVarInt(0xdeadbeef)...) // stream ID
+				data = append(data, encodeVarInt(0x1337)...)     // offset
+				data = append(data, encodeVarInt(0x987654)...)   // minimum offset
+				b := bytes.NewReader(data)
+				frame, err := ParseExpiredStreamDataFrame(b, versionIETFFrames)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(frame.StreamID).To(Equal(protocol.StreamID(0xdeadbeef)))
+				Expect(frame.Offset).To(Equal(protocol.ByteCount(0x1337)))
+				Expect(frame.MinimumOffset).To(Equal(protocol.ByteCount(0x987654)))
+				Expect(b.Len()).To(BeZero())
+			})
+
+			It("errors on EOFs", func() {
+				data := []byte{0x18}
+				data = append(data, encodeVarInt(0xdeadbeef)...) // stream ID
+				data = append(data, encodeVarInt(0x1337)...)     // offset
+				data = append(data, encodeVarInt(0x987654)...)   // minimum offset
+				_, err := ParseExpiredStreamDataFrame(bytes.NewReader(data), versionIETFFrames)
+				Expect(err).NotTo(HaveOccurred())
+				for i := range data {
+					_, err := ParseExpiredStreamDataFrame(bytes.NewReader(data[0:i]), versionIETFFrames)
+					Expect(err).To(HaveOccurred())
+				}
+			})
+		})
+
+		Context("in big endian", func() {
+			It("accepts sample frame", func() {
+				b := bytes.NewReader([]byte{0x18,
+					0xde, 0xad, 0xbe, 0xef, // stream ID
+					0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x13, 0x37, // offset
+					0x0, 0x0, 0x0, 0x0, 0x0, 0x98, 0x76, 0x54, // minimum offset
+				})
+				frame, err := ParseExpiredStreamDataFrame(b, versionBigEndian)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(frame.StreamID).To(Equal(protocol.StreamID(0xdeadbeef)))
+				Expect(frame.Offset).To(Equal(protocol.ByteCount(0x1337)))
+				Expect(frame.MinimumOffset).To(Equal(protocol.ByteCount(0x987654)))
+				Expect(b.Len()).To(BeZero())
+			})
+
+			It("errors on EOFs", func() {
+				data := []byte{0x18,
+					0xde, 0xad, 0xbe, 0xef, // stream ID
+					0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x13, 0x37, // offset
+					0x0, 0x0, 0x0, 0x0, 0x0, 0x98, 0x76, 0x54, // minimum offset
+				}
+				_, err := ParseExpiredStreamDataFrame(bytes.NewReader(data), versionBigEndian)
+				Expect(err).NotTo(HaveOccurred())
+				for i := range data {
+					_, err := ParseExpiredStreamDataFrame(bytes.NewReader(data[0:i]), versionBigEndian)
+					Expect(err).To(HaveOccurred())
+				}
+			})
+		})
+
+		It("errors when the minimum offset is smaller than the offset", func() {
+			data := []byte{0x18}
+			data = append(data, encodeVarInt(0xdeadbeef)...) // stream ID
+			data = append(data, encodeVarInt(0x1337)...)     // offset
+			data = append(data, encodeVarInt(0x1336)...)     // minimum offset
+			_, err := ParseExpiredStreamDataFrame(bytes.NewReader(data), versionIETFFrames)
+			Expect(err).To(MatchError("EXPIRED_STREAM_DATA: minimum offset smaller than offset"))
+		})
+	})
+
+	Context("when writing", func() {
+		It("writes a sample frame, in varint encoding", func() {
+			frame := &ExpiredStreamDataFrame{
+				StreamID:      0xdecafbad,
+				Offset:        0x1337,
+				MinimumOffset: 0xdeadbeefcafe,
+			}
+			b := &bytes.Buffer{}
+			err := frame.Write(b, versionIETFFrames)
+			Expect(err).ToNot(HaveOccurred())
+			expected := []byte{0x18}
+			expected = append(expected, encodeVarInt(0xdecafbad)...)
+			expected = append(expected, encodeVarInt(0x1337)...)
+			expected = append(expected, encodeVarInt(0xdeadbeefcafe)...)
+			Expect(b.Bytes()).To(Equal(expected))
+			Expect(frame.MinLength(versionIETFFrames)).To(Equal(protocol.ByteCount(len(expected))))
+		})
+
+		It("writes a sample frame, in big endian", func() {
+			frame := &ExpiredStreamDataFrame{
+				StreamID:      0xdecafbad,
+				Offset:        0x1337,
+				MinimumOffset: 0xdeadbeefcafe,
+			}
+			b := &bytes.Buffer{}
+			err := frame.Write(b, versionBigEndian)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b.Bytes()).To(Equal([]byte{0x18,
+				0xde, 0xca, 0xfb, 0xad,
+				0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x13, 0x37,
+				0x0, 0x0, 0xde, 0xad, 0xbe, 0xef, 0xca, 0xfe,
+			}))
+			Expect(frame.MinLength(versionBigEndian)).To(Equal(protocol.ByteCount(b.Len())))
+		})
+
+		It("has the correct min length, in varint encoding", func() {
+			frame := &ExpiredStreamDataFrame{
+				StreamID:      0x1337,
+				Offset:        0x42,
+				MinimumOffset: 0x123456,
+			}
+			Expect(frame.MinLength(versionIETFFrames)).To(Equal(1 + utils.VarIntLen(0x1337) + utils.VarIntLen(0x42) + utils.VarIntLen(0x123456)))
+		})
+	})
+})
@@ -0,0 +1,132 @@
+package wire
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+)
+
+// A FECFrame is a FEC frame.
+// It is not part of any QUIC version, but an extension used for forward error correction.
+// It carries the RepairIndex-th of NumRepairSymbols repair symbols for the block of packets listed in PacketNumbers.
+type FECFrame struct {
+	PacketNumbers    []protocol.PacketNumber
+	NumRepairSymbols uint8
+	RepairIndex      uint8
+	Data             []byte
+}
+
+// ParseFECFrame parses a FEC frame
+func ParseFECFrame(r *bytes.Reader, version protocol.VersionNumber) (*FECFrame, error) {
+	if _, err := r.ReadByte(); err != nil { // read the TypeByte
+		return nil, err
+	}
+
+	frame := &FECFrame{}
+	var firstPacketNumber uint64
+	var err error
+	if version.UsesIETFFrameFormat() {
+		firstPacketNumber, err = utils.ReadVarInt(r)
+	} else {
+		firstPacketNumber, err = utils.BigEndian.ReadUint64(r)
+	}
+	if err != nil {
+		return nil, err
+	}
+	numPackets, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if numPackets == 0 {
+		return nil, errors.New("FEC frame: no packets protected")
+	}
+	frame.PacketNumbers = make([]protocol.PacketNumber, numPackets)
+	frame.PacketNumbers[0] = protocol.PacketNumber(firstPacketNumber)
+	for i := 1; i < int(numPackets); i++ {
+		delta, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		pn := frame.PacketNumbers[0] + protocol.PacketNumber(delta)
+		if pn <= frame.PacketNumbers[i-1] {
+			return nil, errors.New("FEC frame: packet numbers not in ascending order")
+		}
+		frame.PacketNumbers[i] = pn
+	}
+	if frame.NumRepairSymbols, err = r.ReadByte(); err != nil {
+		return nil, err
+	}
+	if frame.RepairIndex, err = r.ReadByte(); err != nil {
+		return nil, err
+	}
+	if frame.RepairIndex >= frame.NumRepairSymbols {
+		return nil, errors.New("FEC frame: invalid repair index")
+	}
+	var dataLen uint64
+	if version.UsesIETFFrameFormat() {
+		dataLen, err = utils.ReadVarInt(r)
+	} else {
+		var l uint16
+		l, err = utils.BigEndian.ReadUint16(r)
+		dataLen = uint64(l)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if dataLen > uint64(r.Len()) {
+		return nil, io.EOF
+	}
+	frame.Data = make([]byte, dataLen)
+	if _, err := io.ReadFull(r, frame.Data); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
+
+// Write writes a FEC frame
+func (f *FECFrame) Write(b *bytes.Buffer, version protocol.VersionNumber) error {
+	if len(f.PacketNumbers) == 0 || len(f.PacketNumbers) > 255 {
+		return errors.New("FEC frame: invalid number of packets")
+	}
+	first := f.PacketNumbers[0]
+	for i := 1; i < len(f.PacketNumbers); i++ {
+		if f.PacketNumbers[i] <= f.PacketNumbers[i-1] || f.PacketNumbers[i]-first > 255 {
+			return errors.New("FEC frame: invalid packet numbers")
+		}
+	}
+	b.WriteByte(0x19)
+	if version.UsesIETFFrameFormat() {
+		utils.WriteVarInt(b, uint64(first))
+	} else {
+		utils.BigEndian.WriteUint64(b, uint64(first))
+	}
+	b.WriteByte(uint8(len(f.PacketNumbers)))
+	for _, pn := range f.PacketNumbers[1:] {
+		b.WriteByte(uint8(pn - first))
+	}
+	b.WriteByte(f.NumRepairSymbols)
+	b.WriteByte(f.RepairIndex)
+	if version.UsesIETFFrameFormat() {
+		utils.WriteVarInt(b, uint64(len(f.Data)))
+	} else {
+		utils.BigEndian.WriteUint16(b, uint16(len(f.Data)))
+	}
+	b.Write(f.Data)
+	return nil
+}
+
+// MinLength of a written frame
+func (f *FECFrame) MinLength(version protocol.VersionNumber) protocol.ByteCount {
+	length := 1 + protocol.ByteCount(len(f.PacketNumbers)) + 2 + protocol.ByteCount(len(f.Data))
+	if version.UsesIETFFrameFormat() {
+		var first uint64
+		if len(f.PacketNumbers) > 0 {
+			first = uint64(f.PacketNumbers[0])
+		}
+		return length + utils.VarIntLen(first) + utils.VarIntLen(uint64(len(f.Data)))
+	}
+	return length + 8 + 2
+}
@@ -0,0 +1,181 @@
+package wire
+
+import (
+	"bytes"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FEC frame", func() {
+	Context("when parsing", func() {
+		Context("in varint encoding", func() {
+			It("accepts sample frame", func() {
+				data := []byte{0x19}
+				data = append(data, encodeVarInt(0x1337)...) // first packet number
+				data = append(data, []byte{3, 1, 5}...)      // packet numbers
+				data = append(data, []byte{2, 1}...)         // number of repair symbols, repair index
+				data = append(data, encodeVarInt(6)...)      // data length
+				data = append(data, []byte("foobar")...)
+				b := bytes.NewReader(data)
+				frame, err := ParseFECFrame(b, versionIETFFrames)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(frame.PacketNumbers).To(Equal([]protocol.PacketNumber{0x1337, 0x1338, 0x133c}))
+				Expect(frame.NumRepairSymbols).To(BeEquivalentTo(2))
+				Expect(frame.RepairIndex).To(BeEquivalentTo(1))
+				Expect(frame.Data).To(Equal([]byte("foobar")))
+				Expect(b.Len()).To(BeZero())
+			})
+
+			It("errors on EOFs", func() {
+				data := []byte{0x19}
+				data = append(data, encodeVarInt(0x1337)...) // first packet number
+				data = append(data, []byte{2, 1}...)         // packet numbers
+				data = append(data, []byte{1, 0}...)         // number of repair symbols, repair index
+				data = append(data, encodeVarInt(6)...)      // data length
+				data = append(data, []byte("foobar")...)
+				_, err := ParseFECFrame(bytes.NewReader(data), versionIETFFrames)
+				Expect(err).NotTo(HaveOccurred())
+				for i := range data {
+					_, err := ParseFECFrame(bytes.NewReader(data[0:i]), versionIETFFrames)
+					Expect(err).To(HaveOccurred())
+				}
+			})
+		})
+
+		Context("in big endian", func() {
+			It("accepts sample frame", func() {
+				b := bytes.NewReader([]byte{0x19,
+					0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x13, 0x37, // first packet number
+					0x2, 0x4, // packet numbers
+					0x1, 0x0, // number of repair symbols, repair index
+					0x0, 0x3, // data length
+					'f', 'o', 'o',
+				})
+				frame, err := ParseFECFrame(b, versionBigEndian)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(frame.PacketNumbers).To(Equal([]protocol.PacketNumber{0x1337, 0x133b}))
+				Expect(frame.NumRepairSymbols).To(BeEquivalentTo(1))
+				Expect(frame.RepairIndex).To(BeZero())
+				Expect(frame.Data).To(Equal([]byte("foo")))
+				Expect(b.Len()).To(BeZero())
+			})
+
+			It("errors on EOFs", func() {
+				data := []byte{0x19,
+					0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x13, 0x37, // first packet number
+					0x2, 0x4, // packet numbers
+					0x1, 0x0, // number of repair symbols, repair index
+					0x0, 0x3, // data length
+					'f', 'o', 'o',
+				}
+				_, err := ParseFECFrame(bytes.NewReader(data), versionBigEndian)
+				Expect(err).NotTo(HaveOccurred())
+				for i := range data {
+					_, err := ParseFECFrame(bytes.NewReader(data[0:i]), versionBigEndian)
+					Expect(err).To(HaveOccurred())
+				}
+			})
+		})
+
+		It("errors if no packets are protected", func() {
+			data := []byte{0x19}
+			data = append(data, encodeVarInt(0x1337)...)
+			data = append(data, []byte{0, 1, 0, 0}...)
+			_, err := ParseFECFrame(bytes.NewReader(data), versionIETFFrames)
+			Expect(err).To(MatchError("FEC frame: no packets protected"))
+		})
+
+		It("errors if the packet numbers are not ascending", func() {
+			data := []byte{0x19}
+			data = append(data, encodeVarInt(0x1337)...)
+			data = append(data, []byte{3, 2, 2, 1, 0, 0}...)
+			_, err := ParseFECFrame(bytes.NewReader(data), versionIETFFrames)
+			Expect(err).To(MatchError("FEC frame: packet numbers not in ascending order"))
+		})
+
+		It("errors if the repair index is too large", func() {
+			data := []byte{0x19}
+			data = append(data, encodeVarInt(0x1337)...)
+			data = append(data, []byte{1, 2, 2, 0}...)
+			_, err := ParseFECFrame(bytes.NewReader(data), versionIETFFrames)
+			Expect(err).To(MatchError("FEC frame: invalid repair index"))
+		})
+	})
+
+	Context("when writing", func() {
+		It("writes a sample frame, in varint encoding", func() {
+			frame := &FECFrame{
+				PacketNumbers:    []protocol.PacketNumber{0x1337, 0x1338, 0x1340},
+				NumRepairSymbols: 3,
+				RepairIndex:      2,
+				Data:             []byte("foobar"),
+			}
+			b := &bytes.Buffer{}
+			err := frame.Write(b, versionIETFFrames)
+			Expect(err).ToNot(HaveOccurred())
+			expected := []byte{0x19}
+			expected = append(expected, encodeVarInt(0x1337)...)
+			expected = append(expected, []byte{3, 1, 9, 3, 2}...)
+			expected = append(expected, encodeVarInt(6)...)
+			expected = append(expected, []byte("foobar")...)
+			Expect(b.Bytes()).To(Equal(expected))
+		})
+
+		It("writes a sample frame, in big endian", func() {
+			frame := &FECFrame{
+				PacketNumbers:    []protocol.PacketNumber{0x1337, 0x133b},
+				NumRepairSymbols: 1,
+				Data:             []byte("foo"),
+			}
+			b := &bytes.Buffer{}
+			err := frame.Write(b, versionBigEndian)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b.Bytes()).To(Equal([]byte{0x19,
+				0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x13, 0x37, // first packet number
+				0x2, 0x4, // packet numbers
+				0x1, 0x0, // number of repair symbols, repair index
+				0x0, 0x3, // data length
+				'f', 'o', 'o',
+			}))
+		})
+
+		It("refuses to write a frame that doesn't protect any packets", func() {
+			frame := &FECFrame{NumRepairSymbols: 1}
+			err := frame.Write(&bytes.Buffer{}, versionIETFFrames)
+			Expect(err).To(MatchError("FEC frame: invalid number of packets"))
+		})
+
+		It("refuses to write packet numbers that are too far apart", func() {
+			frame := &FECFrame{
+				PacketNumbers:    []protocol.PacketNumber{10, 10 + 256},
+				NumRepairSymbols: 1,
+			}
+			err := frame.Write(&bytes.Buffer{}, versionIETFFrames)
+			Expect(err).To(MatchError("FEC frame: invalid packet numbers"))
+		})
+
+		It("has the correct min length, in varint encoding", func() {
+			frame := &FECFrame{
+				PacketNumbers:    []protocol.PacketNumber{0x1337, 0x1338, 0x1340},
+				NumRepairSymbols: 1,
+				Data:             []byte("foobar"),
+			}
+			b := &bytes.Buffer{}
+			Expect(frame.Write(b, versionIETFFrames)).To(Succeed())
+			Expect(frame.MinLength(versionIETFFrames)).To(BeEquivalentTo(b.Len()))
+		})
+
+		It("has the correct min length, in big endian", func() {
+			frame := &FECFrame{
+				PacketNumbers:    []protocol.PacketNumber{0x1337, 0x1338, 0x1340},
+				NumRepairSymbols: 1,
+				Data:             []byte("foobar"),
+			}
+			b := &bytes.Buffer{}
+			Expect(frame.Write(b, versionBigEndian)).To(Succeed())
+			Expect(frame.MinLength(versionBigEndian)).To(BeEquivalentTo(b.Len()))
+		})
+	})
+})
@@ -15,6 +15,9 @@ type Header struct {
 	PacketNumberLen  protocol.PacketNumberLen
 	PacketNumber     protocol.PacketNumber
 	Version          protocol.VersionNumber // VersionNumber sent by the client
+	// PathID is the path that a packet of a multipath connection was sent on.
+	// It is only present in the header for paths other than the initial path.
+	PathID protocol.PathID
 
 	IsVersionNegotiation bool
 	SupportedVersions    []protocol.VersionNumber // Version Number sent in a Version Negotiation Packet by the server
@@ -35,9 +38,19 @@ type Header struct {
 
 // ParseHeaderSentByServer parses the header for a packet that was sent by the server.
 func ParseHeaderSentByServer(b *bytes.Reader, version protocol.VersionNumber) (*Header, error) {
+	h := &Header{}
+	if err := ParseHeaderSentByServerInto(b, version, h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// ParseHeaderSentByServerInto is like ParseHeaderSentByServer, but it parses the header into h instead of allocating a new Header.
+// All fields of h are overwritten.
+func ParseHeaderSentByServerInto(b *bytes.Reader, version protocol.VersionNumber, h *Header) error {
 	typeByte, err := b.ReadByte()
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_ = b.UnreadByte() // unread the type byte
 
@@ -50,37 +63,49 @@ func ParseHeaderSentByServer(b *bytes.Reader, version protocol.VersionNumber) (*
 		// the client knows the version that this packet was sent with
 		isPublicHeader = !version.UsesTLS()
 	}
-	return parsePacketHeader(b, protocol.PerspectiveServer, isPublicHeader)
+	return parsePacketHeader(b, protocol.PerspectiveServer, isPublicHeader, h)
 }
 
 // ParseHeaderSentByClient parses the header for a packet that was sent by the client.
 func ParseHeaderSentByClient(b *bytes.Reader) (*Header, error) {
+	h := &Header{}
+	if err := ParseHeaderSentByClientInto(b, h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// ParseHeaderSentByClientInto is like ParseHeaderSentByClient, but it parses the header into h instead of allocating a new Header.
+// All fields of h are overwritten.
+func ParseHeaderSentByClientInto(b *bytes.Reader, h *Header) error {
 	typeByte, err := b.ReadByte()
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_ = b.UnreadByte() // unread the type byte
 
-	// If this is a gQUIC header 0x80 and 0x40 will be set to 0.
+	// If this is a gQUIC header 0x80 will be set to 0.
+	// 0x40 will be set to 0, unless the packet was sent on an additional path of a multipath connection.
+	// In that case 0x08 (the Connection ID Flag) will be 1, since the client never omits the connection ID.
 	// If this is an IETF QUIC header there are two options:
 	// * either 0x80 will be 1 (for the Long Header)
-	// * or 0x40 (the Connection ID Flag) will be 0 (for the Short Header), since we don't the client to omit it
-	isPublicHeader := typeByte&0xc0 == 0
+	// * or 0x40 (the Connection ID Flag) will be 1 and 0x08 will be 0 (for the Short Header), since we don't the client to omit it
+	isPublicHeader := typeByte&0x80 == 0 && (typeByte&0x40 == 0 || typeByte&0x08 > 0)
 
-	return parsePacketHeader(b, protocol.PerspectiveClient, isPublicHeader)
+	return parsePacketHeader(b, protocol.PerspectiveClient, isPublicHeader, h)
 }
 
-func parsePacketHeader(b *bytes.Reader, sentBy protocol.Perspective, isPublicHeader bool) (*Header, error) {
+func parsePacketHeader(b *bytes.Reader, sentBy protocol.Perspective, isPublicHeader bool, h *Header) error {
+	*h = Header{}
 	// This is a gQUIC Public Header.
 	if isPublicHeader {
-		hdr, err := parsePublicHeader(b, sentBy)
-		if err != nil {
-			return nil, err
+		if err := parsePublicHeaderInto(b, sentBy, h); err != nil {
+			return err
 		}
-		hdr.isPublicHeader = true // save that this is a Public Header, so we can log it correctly later
-		return hdr, nil
+		h.isPublicHeader = true // save that this is a Public Header, so we can log it correctly later
+		return nil
 	}
-	return parseHeader(b, sentBy)
+	return parseHeaderInto(b, sentBy, h)
 }
 
 // Write writes the Header.
@@ -5,6 +5,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"testing"
 
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/internal/utils"
@@ -85,6 +86,39 @@ var _ = Describe("Header", func() {
 			Expect(hdr.isPublicHeader).To(BeTrue())
 		})
 
+		It("parses a gQUIC Public Header containing a path ID", func() {
+			buf := &bytes.Buffer{}
+			err := (&Header{
+				ConnectionID:    0x42,
+				PathID:          3,
+				PacketNumber:    0x1337,
+				PacketNumberLen: protocol.PacketNumberLen2,
+			}).writePublicHeader(buf, protocol.PerspectiveClient, versionPublicHeader)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(buf.Bytes()[0] & 0x40).ToNot(BeZero())
+			hdr, err := ParseHeaderSentByClient(bytes.NewReader(buf.Bytes()))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hdr.PathID).To(Equal(protocol.PathID(3)))
+			Expect(hdr.PacketNumber).To(Equal(protocol.PacketNumber(0x1337)))
+			Expect(hdr.isPublicHeader).To(BeTrue())
+		})
+
+		It("parses an IETF draft Short Header containing a path ID", func() {
+			buf := &bytes.Buffer{}
+			err := (&Header{
+				ConnectionID:    0x42,
+				PathID:          3,
+				PacketNumber:    0x1337,
+				PacketNumberLen: protocol.PacketNumberLen2,
+			}).writeHeader(buf)
+			Expect(err).ToNot(HaveOccurred())
+			hdr, err := ParseHeaderSentByClient(bytes.NewReader(buf.Bytes()))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hdr.PathID).To(Equal(protocol.PathID(3)))
+			Expect(hdr.PacketNumber).To(Equal(protocol.PacketNumber(0x1337)))
+			Expect(hdr.isPublicHeader).To(BeFalse())
+		})
+
 		It("parses a gQUIC Public Header, when the version is known", func() {
 			buf := &bytes.Buffer{}
 			err := (&Header{
@@ -150,6 +184,75 @@ var _ = Describe("Header", func() {
 				Expect(hdr.SupportedVersions).To(ContainElement(version))
 			}
 		})
+
+		Context("parsing into an existing Header", func() {
+			It("overwrites all fields", func() {
+				buf := &bytes.Buffer{}
+				err := (&Header{
+					ConnectionID:    0x42,
+					KeyPhase:        1,
+					PacketNumber:    0x42,
+					PacketNumberLen: protocol.PacketNumberLen2,
+				}).writeHeader(buf)
+				Expect(err).ToNot(HaveOccurred())
+				hdr := &Header{
+					VersionFlag:          true,
+					Version:              versionPublicHeader,
+					ConnectionID:         0x1337,
+					DiversificationNonce: []byte("foobar"),
+					isPublicHeader:       true,
+				}
+				Expect(ParseHeaderSentByServerInto(bytes.NewReader(buf.Bytes()), versionIETFHeader, hdr)).To(Succeed())
+				Expect(*hdr).To(Equal(Header{
+					ConnectionID:    0x42,
+					KeyPhase:        1,
+					PacketNumber:    0x42,
+					PacketNumberLen: protocol.PacketNumberLen2,
+				}))
+			})
+
+			It("doesn't allocate when parsing a Short Header", func() {
+				buf := &bytes.Buffer{}
+				err := (&Header{
+					ConnectionID:    0x1337,
+					PacketNumber:    0x42,
+					PacketNumberLen: protocol.PacketNumberLen2,
+				}).writeHeader(buf)
+				Expect(err).ToNot(HaveOccurred())
+				data := buf.Bytes()
+				r := &bytes.Reader{}
+				hdr := &Header{}
+				allocs := testing.AllocsPerRun(100, func() {
+					r.Reset(data)
+					if err := ParseHeaderSentByClientInto(r, hdr); err != nil {
+						Fail(err.Error())
+					}
+				})
+				Expect(allocs).To(BeZero())
+				Expect(hdr.ConnectionID).To(Equal(protocol.ConnectionID(0x1337)))
+			})
+
+			It("doesn't allocate when parsing a Public Header", func() {
+				buf := &bytes.Buffer{}
+				err := (&Header{
+					ConnectionID:    0x1337,
+					PacketNumber:    0x42,
+					PacketNumberLen: protocol.PacketNumberLen2,
+				}).writePublicHeader(buf, protocol.PerspectiveServer, versionPublicHeader)
+				Expect(err).ToNot(HaveOccurred())
+				data := buf.Bytes()
+				r := &bytes.Reader{}
+				hdr := &Header{}
+				allocs := testing.AllocsPerRun(100, func() {
+					r.Reset(data)
+					if err := ParseHeaderSentByServerInto(r, versionPublicHeader, hdr); err != nil {
+						Fail(err.Error())
+					}
+				})
+				Expect(allocs).To(BeZero())
+				Expect(hdr.ConnectionID).To(Equal(protocol.ConnectionID(0x1337)))
+			})
+		})
 	})
 
 	Context("writing", func() {
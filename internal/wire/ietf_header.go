@@ -11,87 +11,103 @@ import (
 
 // parseHeader parses the header.
 func parseHeader(b *bytes.Reader, packetSentBy protocol.Perspective) (*Header, error) {
+	h := &Header{}
+	if err := parseHeaderInto(b, packetSentBy, h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// parseHeaderInto parses the header into h.
+// h must be zeroed.
+func parseHeaderInto(b *bytes.Reader, packetSentBy protocol.Perspective, h *Header) error {
 	typeByte, err := b.ReadByte()
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if typeByte&0x80 > 0 {
-		return parseLongHeader(b, packetSentBy, typeByte)
+		return parseLongHeader(b, packetSentBy, typeByte, h)
 	}
-	return parseShortHeader(b, typeByte)
+	return parseShortHeader(b, typeByte, h)
 }
 
 // parse long header and version negotiation packets
-func parseLongHeader(b *bytes.Reader, sentBy protocol.Perspective, typeByte byte) (*Header, error) {
+func parseLongHeader(b *bytes.Reader, sentBy protocol.Perspective, typeByte byte, h *Header) error {
 	connID, err := utils.BigEndian.ReadUint64(b)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	v, err := utils.BigEndian.ReadUint32(b)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	pn, err := utils.BigEndian.ReadUint32(b)
 	if err != nil {
-		return nil, err
-	}
-	h := &Header{
-		ConnectionID:    protocol.ConnectionID(connID),
-		PacketNumber:    protocol.PacketNumber(pn),
-		PacketNumberLen: protocol.PacketNumberLen4,
-		Version:         protocol.VersionNumber(v),
+		return err
 	}
+	h.ConnectionID = protocol.ConnectionID(connID)
+	h.PacketNumber = protocol.PacketNumber(pn)
+	h.PacketNumberLen = protocol.PacketNumberLen4
+	h.Version = protocol.VersionNumber(v)
 	if v == 0 { // version negotiation packet
 		if sentBy == protocol.PerspectiveClient {
-			return nil, qerr.InvalidVersion
+			return qerr.InvalidVersion
 		}
 		if b.Len() == 0 {
-			return nil, qerr.Error(qerr.InvalidVersionNegotiationPacket, "empty version list")
+			return qerr.Error(qerr.InvalidVersionNegotiationPacket, "empty version list")
 		}
 		h.IsVersionNegotiation = true
 		h.SupportedVersions = make([]protocol.VersionNumber, b.Len()/4)
 		for i := 0; b.Len() > 0; i++ {
 			v, err := utils.BigEndian.ReadUint32(b)
 			if err != nil {
-				return nil, qerr.InvalidVersionNegotiationPacket
+				return qerr.InvalidVersionNegotiationPacket
 			}
 			h.SupportedVersions[i] = protocol.VersionNumber(v)
 		}
-		return h, nil
+		return nil
 	}
 	h.IsLongHeader = true
 	h.Type = protocol.PacketType(typeByte & 0x7f)
 	if sentBy == protocol.PerspectiveClient && (h.Type != protocol.PacketTypeInitial && h.Type != protocol.PacketTypeHandshake && h.Type != protocol.PacketType0RTT) {
-		return nil, qerr.Error(qerr.InvalidPacketHeader, fmt.Sprintf("Received packet with invalid packet type: %d", h.Type))
+		return qerr.Error(qerr.InvalidPacketHeader, fmt.Sprintf("Received packet with invalid packet type: %d", h.Type))
 	}
 	if sentBy == protocol.PerspectiveServer && (h.Type != protocol.PacketTypeRetry && h.Type != protocol.PacketTypeHandshake) {
-		return nil, qerr.Error(qerr.InvalidPacketHeader, fmt.Sprintf("Received packet with invalid packet type: %d", h.Type))
+		return qerr.Error(qerr.InvalidPacketHeader, fmt.Sprintf("Received packet with invalid packet type: %d", h.Type))
 	}
-	return h, nil
+	return nil
 }
 
-func parseShortHeader(b *bytes.Reader, typeByte byte) (*Header, error) {
+func parseShortHeader(b *bytes.Reader, typeByte byte, h *Header) error {
 	hasConnID := typeByte&0x40 > 0
 	var connID uint64
 	if hasConnID {
 		var err error
 		connID, err = utils.BigEndian.ReadUint64(b)
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
+	var pathID protocol.PathID
+	if typeByte&0x10 > 0 {
+		p, err := b.ReadByte()
+		if err != nil {
+			return err
+		}
+		pathID = protocol.PathID(p)
+	}
 	pnLen := 1 << ((typeByte & 0x3) - 1)
 	pn, err := utils.BigEndian.ReadUintN(b, uint8(pnLen))
 	if err != nil {
-		return nil, err
-	}
-	return &Header{
-		KeyPhase:         int(typeByte&0x20) >> 5,
-		OmitConnectionID: !hasConnID,
-		ConnectionID:     protocol.ConnectionID(connID),
-		PacketNumber:     protocol.PacketNumber(pn),
-		PacketNumberLen:  protocol.PacketNumberLen(pnLen),
-	}, nil
+		return err
+	}
+	h.KeyPhase = int(typeByte&0x20) >> 5
+	h.OmitConnectionID = !hasConnID
+	h.ConnectionID = protocol.ConnectionID(connID)
+	h.PathID = pathID
+	h.PacketNumber = protocol.PacketNumber(pn)
+	h.PacketNumberLen = protocol.PacketNumberLen(pnLen)
+	return nil
 }
 
 // writeHeader writes the Header.
@@ -116,6 +132,9 @@ func (h *Header) writeShortHeader(b *bytes.Buffer) error {
 	if !h.OmitConnectionID {
 		typeByte ^= 0x40
 	}
+	if h.PathID != protocol.InitialPathID {
+		typeByte ^= 0x10
+	}
 	switch h.PacketNumberLen {
 	case protocol.PacketNumberLen1:
 		typeByte ^= 0x1
@@ -131,6 +150,9 @@ func (h *Header) writeShortHeader(b *bytes.Buffer) error {
 	if !h.OmitConnectionID {
 		utils.BigEndian.WriteUint64(b, uint64(h.ConnectionID))
 	}
+	if h.PathID != protocol.InitialPathID {
+		b.WriteByte(uint8(h.PathID))
+	}
 	switch h.PacketNumberLen {
 	case protocol.PacketNumberLen1:
 		b.WriteByte(uint8(h.PacketNumber))
@@ -152,6 +174,9 @@ func (h *Header) getHeaderLength() (protocol.ByteCount, error) {
 	if !h.OmitConnectionID {
 		length += 8
 	}
+	if h.PathID != protocol.InitialPathID {
+		length++
+	}
 	if h.PacketNumberLen != protocol.PacketNumberLen1 && h.PacketNumberLen != protocol.PacketNumberLen2 && h.PacketNumberLen != protocol.PacketNumberLen4 {
 		return 0, fmt.Errorf("invalid packet number length: %d", h.PacketNumberLen)
 	}
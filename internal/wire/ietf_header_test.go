@@ -132,6 +132,22 @@ var _ = Describe("IETF draft Header", func() {
 				Expect(b.Len()).To(BeZero())
 			})
 
+			It("reads the path ID", func() {
+				data := []byte{
+					0x40 ^ 0x10 ^ 0x1,
+					0xde, 0xad, 0xbe, 0xef, 0xca, 0xfe, 0x13, 0x37, // connection ID
+					0x3,  // path ID
+					0x42, // packet number
+				}
+				b := bytes.NewReader(data)
+				h, err := parseHeader(b, protocol.PerspectiveClient)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(h.ConnectionID).To(Equal(protocol.ConnectionID(0xdeadbeefcafe1337)))
+				Expect(h.PathID).To(Equal(protocol.PathID(3)))
+				Expect(h.PacketNumber).To(Equal(protocol.PacketNumber(0x42)))
+				Expect(b.Len()).To(BeZero())
+			})
+
 			It("reads the Key Phase Bit", func() {
 				data := []byte{
 					0x20 ^ 0x1,
@@ -243,6 +259,22 @@ var _ = Describe("IETF draft Header", func() {
 				}))
 			})
 
+			It("writes a header with a path ID", func() {
+				err := (&Header{
+					ConnectionID:    0xdeadbeefcafe1337,
+					PathID:          3,
+					PacketNumberLen: protocol.PacketNumberLen1,
+					PacketNumber:    0x42,
+				}).writeHeader(buf)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(buf.Bytes()).To(Equal([]byte{
+					0x40 ^ 0x10 ^ 0x1,
+					0xde, 0xad, 0xbe, 0xef, 0xca, 0xfe, 0x13, 0x37, // connection ID
+					0x3,  // path ID
+					0x42, // packet number
+				}))
+			})
+
 			It("writes a header without connection ID", func() {
 				err := (&Header{
 					OmitConnectionID: true,
@@ -332,6 +364,17 @@ var _ = Describe("IETF draft Header", func() {
 			Expect(buf.Len()).To(Equal(10))
 		})
 
+		It("has the right length for a short header containing a path ID", func() {
+			h := &Header{
+				PathID:          1,
+				PacketNumberLen: protocol.PacketNumberLen1,
+			}
+			Expect(h.getHeaderLength()).To(Equal(protocol.ByteCount(1 + 8 + 1 + 1)))
+			err := h.writeHeader(buf)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(buf.Len()).To(Equal(11))
+		})
+
 		It("has the right length for a short header without a connection ID", func() {
 			h := &Header{
 				OmitConnectionID: true,
@@ -21,7 +21,13 @@ func LogFrame(frame Frame, sent bool) {
 			utils.Debugf("\t%s &wire.StopWaitingFrame{LeastUnacked: 0x%x}", dir, f.LeastUnacked)
 		}
 	case *AckFrame:
-		utils.Debugf("\t%s &wire.AckFrame{LargestAcked: 0x%x, LowestAcked: 0x%x, AckRanges: %#v, DelayTime: %s}", dir, f.LargestAcked, f.LowestAcked, f.AckRanges, f.DelayTime.String())
+		if f.ECN != nil {
+			utils.Debugf("\t%s &wire.AckFrame{LargestAcked: 0x%x, LowestAcked: 0x%x, AckRanges: %#v, DelayTime: %s, ECT(0): %d, ECT(1): %d, CE: %d}", dir, f.LargestAcked, f.LowestAcked, f.AckRanges, f.DelayTime.String(), f.ECN.ECT0, f.ECN.ECT1, f.ECN.CE)
+		} else {
+			utils.Debugf("\t%s &wire.AckFrame{LargestAcked: 0x%x, LowestAcked: 0x%x, AckRanges: %#v, DelayTime: %s}", dir, f.LargestAcked, f.LowestAcked, f.AckRanges, f.DelayTime.String())
+		}
+	case *FECFrame:
+		utils.Debugf("\t%s &wire.FECFrame{PacketNumbers: %#v, NumRepairSymbols: %d, RepairIndex: %d, Data length: 0x%x}", dir, f.PacketNumbers, f.NumRepairSymbols, f.RepairIndex, len(f.Data))
 	default:
 		utils.Debugf("\t%s %#v", dir, frame)
 	}
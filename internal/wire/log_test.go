@@ -81,4 +81,15 @@ var _ = Describe("Frame logging", func() {
 		LogFrame(frame, true)
 		Expect(buf.Bytes()).To(ContainSubstring("\t-> &wire.StopWaitingFrame{LeastUnacked: 0x1337, PacketNumberLen: 0x4}\n"))
 	})
+
+	It("logs FEC frames", func() {
+		frame := &FECFrame{
+			PacketNumbers:    []protocol.PacketNumber{1, 2},
+			NumRepairSymbols: 2,
+			RepairIndex:      1,
+			Data:             bytes.Repeat([]byte{'f'}, 0x100),
+		}
+		LogFrame(frame, true)
+		Expect(buf.Bytes()).To(ContainSubstring("\t-> &wire.FECFrame{PacketNumbers: []protocol.PacketNumber{0x1, 0x2}, NumRepairSymbols: 2, RepairIndex: 1, Data length: 0x100}\n"))
+	})
 })
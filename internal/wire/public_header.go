@@ -34,6 +34,9 @@ func (h *Header) writePublicHeader(b *bytes.Buffer, pers protocol.Perspective, _
 	if !h.OmitConnectionID {
 		publicFlagByte |= 0x08
 	}
+	if h.PathID != protocol.InitialPathID {
+		publicFlagByte |= 0x40
+	}
 	if len(h.DiversificationNonce) > 0 {
 		if len(h.DiversificationNonce) != 32 {
 			return errors.New("invalid diversification nonce length")
@@ -58,6 +61,9 @@ func (h *Header) writePublicHeader(b *bytes.Buffer, pers protocol.Perspective, _
 	if !h.OmitConnectionID {
 		utils.BigEndian.WriteUint64(b, uint64(h.ConnectionID))
 	}
+	if h.PathID != protocol.InitialPathID {
+		b.WriteByte(uint8(h.PathID))
+	}
 	if h.VersionFlag && pers == protocol.PerspectiveClient {
 		utils.BigEndian.WriteUint32(b, uint32(h.Version))
 	}
@@ -89,11 +95,19 @@ func (h *Header) writePublicHeader(b *bytes.Buffer, pers protocol.Perspective, _
 // The packetSentBy is the perspective of the peer that sent this PublicHeader, i.e. if we're the server, packetSentBy should be PerspectiveClient.
 func parsePublicHeader(b *bytes.Reader, packetSentBy protocol.Perspective) (*Header, error) {
 	header := &Header{}
+	if err := parsePublicHeaderInto(b, packetSentBy, header); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
 
+// parsePublicHeaderInto parses a QUIC packet's Public Header into header.
+// header must be zeroed.
+func parsePublicHeaderInto(b *bytes.Reader, packetSentBy protocol.Perspective, header *Header) error {
 	// First byte
 	publicFlagByte, err := b.ReadByte()
 	if err != nil {
-		return nil, err
+		return err
 	}
 	header.ResetFlag = publicFlagByte&0x02 > 0
 	header.VersionFlag = publicFlagByte&0x01 > 0
@@ -106,7 +120,7 @@ func parsePublicHeader(b *bytes.Reader, packetSentBy protocol.Perspective) (*Hea
 
 	header.OmitConnectionID = publicFlagByte&0x08 == 0
 	if header.OmitConnectionID && packetSentBy == protocol.PerspectiveClient {
-		return nil, errReceivedOmittedConnectionID
+		return errReceivedOmittedConnectionID
 	}
 	if header.hasPacketNumber(packetSentBy) {
 		switch publicFlagByte & 0x30 {
@@ -126,14 +140,23 @@ func parsePublicHeader(b *bytes.Reader, packetSentBy protocol.Perspective) (*Hea
 		var connID uint64
 		connID, err = utils.BigEndian.ReadUint64(b)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		header.ConnectionID = protocol.ConnectionID(connID)
 		if header.ConnectionID == 0 {
-			return nil, errInvalidConnectionID
+			return errInvalidConnectionID
 		}
 	}
 
+	// Path ID (optional)
+	if publicFlagByte&0x40 > 0 {
+		pathID, err := b.ReadByte()
+		if err != nil {
+			return err
+		}
+		header.PathID = protocol.PathID(pathID)
+	}
+
 	if packetSentBy == protocol.PerspectiveServer && publicFlagByte&0x04 > 0 {
 		// TODO: remove the if once the Google servers send the correct value
 		// assume that a packet doesn't contain a diversification nonce if the version flag or the reset flag is set, no matter what the public flag says
@@ -141,7 +164,7 @@ func parsePublicHeader(b *bytes.Reader, packetSentBy protocol.Perspective) (*Hea
 		if !header.VersionFlag && !header.ResetFlag {
 			header.DiversificationNonce = make([]byte, 32)
 			if _, err := io.ReadFull(b, header.DiversificationNonce); err != nil {
-				return nil, err
+				return err
 			}
 		}
 	}
@@ -150,10 +173,10 @@ func parsePublicHeader(b *bytes.Reader, packetSentBy protocol.Perspective) (*Hea
 	if !header.ResetFlag && header.VersionFlag {
 		if packetSentBy == protocol.PerspectiveServer { // parse the version negotiaton packet
 			if b.Len() == 0 {
-				return nil, qerr.Error(qerr.InvalidVersionNegotiationPacket, "empty version list")
+				return qerr.Error(qerr.InvalidVersionNegotiationPacket, "empty version list")
 			}
 			if b.Len()%4 != 0 {
-				return nil, qerr.InvalidVersionNegotiationPacket
+				return qerr.InvalidVersionNegotiationPacket
 			}
 			header.IsVersionNegotiation = true
 			header.SupportedVersions = make([]protocol.VersionNumber, 0)
@@ -167,13 +190,13 @@ func parsePublicHeader(b *bytes.Reader, packetSentBy protocol.Perspective) (*Hea
 				header.SupportedVersions = append(header.SupportedVersions, v)
 			}
 			// a version negotiation packet doesn't have a packet number
-			return header, nil
+			return nil
 		}
 		// packet was sent by the client. Read the version number
 		var versionTag uint32
 		versionTag, err = utils.BigEndian.ReadUint32(b)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		header.Version = protocol.VersionNumber(versionTag)
 	}
@@ -182,11 +205,11 @@ func parsePublicHeader(b *bytes.Reader, packetSentBy protocol.Perspective) (*Hea
 	if header.hasPacketNumber(packetSentBy) {
 		packetNumber, err := utils.BigEndian.ReadUintN(b, uint8(header.PacketNumberLen))
 		if err != nil {
-			return nil, err
+			return err
 		}
 		header.PacketNumber = protocol.PacketNumber(packetNumber)
 	}
-	return header, nil
+	return nil
 }
 
 // getPublicHeaderLength gets the length of the publicHeader in bytes.
@@ -209,6 +232,9 @@ func (h *Header) getPublicHeaderLength(pers protocol.Perspective) (protocol.Byte
 	if !h.OmitConnectionID {
 		length += 8 // 8 bytes for the connection ID
 	}
+	if h.PathID != protocol.InitialPathID {
+		length++ // 1 byte for the path ID
+	}
 	// Version Number in packets sent by the client
 	if h.VersionFlag {
 		length += 4
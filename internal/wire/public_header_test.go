@@ -3,6 +3,7 @@ package wire
 import (
 	"bytes"
 	"encoding/binary"
+	"io"
 	"log"
 	"os"
 
@@ -52,6 +53,22 @@ var _ = Describe("Public Header", func() {
 			Expect(err).To(MatchError(errInvalidConnectionID))
 		})
 
+		It("reads the path ID", func() {
+			b := bytes.NewReader([]byte{0x48, 0x4c, 0xfa, 0x9f, 0x9b, 0x66, 0x86, 0x19, 0xf6, 0x3, 0x42})
+			hdr, err := parsePublicHeader(b, protocol.PerspectiveClient)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hdr.ConnectionID).To(Equal(protocol.ConnectionID(0x4cfa9f9b668619f6)))
+			Expect(hdr.PathID).To(Equal(protocol.PathID(3)))
+			Expect(hdr.PacketNumber).To(Equal(protocol.PacketNumber(0x42)))
+			Expect(b.Len()).To(BeZero())
+		})
+
+		It("errors on EOF when reading the path ID", func() {
+			data := []byte{0x48, 0x4c, 0xfa, 0x9f, 0x9b, 0x66, 0x86, 0x19, 0xf6, 0x3, 0x42}
+			_, err := parsePublicHeader(bytes.NewReader(data[:9]), protocol.PerspectiveClient)
+			Expect(err).To(MatchError(io.EOF))
+		})
+
 		It("reads a PublicReset packet", func() {
 			b := bytes.NewReader([]byte{0xa, 0x1, 0x2, 0x3, 0x4, 0x5, 0x6, 0x7, 0x8})
 			hdr, err := parsePublicHeader(b, protocol.PerspectiveServer)
@@ -205,6 +222,19 @@ var _ = Describe("Public Header", func() {
 			Expect(b.Bytes()).To(Equal([]byte{0x38, 0x4c, 0xfa, 0x9f, 0x9b, 0x66, 0x86, 0x19, 0xf6, 0x0, 0x0, 0x0, 0x0, 0x13, 0x37}))
 		})
 
+		It("writes the path ID", func() {
+			b := &bytes.Buffer{}
+			hdr := Header{
+				ConnectionID:    0x4cfa9f9b668619f6,
+				PathID:          3,
+				PacketNumber:    0x42,
+				PacketNumberLen: protocol.PacketNumberLen1,
+			}
+			err := hdr.writePublicHeader(b, protocol.PerspectiveClient, versionBigEndian)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b.Bytes()).To(Equal([]byte{0x48, 0x4c, 0xfa, 0x9f, 0x9b, 0x66, 0x86, 0x19, 0xf6, 0x3, 0x42}))
+		})
+
 		It("refuses to write a Public Header if the PacketNumberLen is not set", func() {
 			hdr := Header{
 				ConnectionID: 1,
@@ -397,6 +427,18 @@ var _ = Describe("Public Header", func() {
 				Expect(length).To(Equal(protocol.ByteCount(1 + 8 + 2))) // 1 byte public flag, 8 byte connectionID, and packet number
 			})
 
+			It("gets the length of a packet with a path ID", func() {
+				hdr := Header{
+					ConnectionID:    0x4cfa9f9b668619f6,
+					PathID:          1,
+					PacketNumber:    0xDECAFBAD,
+					PacketNumberLen: protocol.PacketNumberLen2,
+				}
+				length, err := hdr.getPublicHeaderLength(protocol.PerspectiveServer)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(length).To(Equal(protocol.ByteCount(1 + 8 + 1 + 2))) // 1 byte public flag, 8 byte connectionID, path ID, and packet number
+			})
+
 			It("works with diversification nonce", func() {
 				hdr := Header{
 					DiversificationNonce: []byte("foo"),
@@ -21,15 +21,24 @@ type StreamFrame struct {
 
 // ParseStreamFrame reads a STREAM frame
 func ParseStreamFrame(r *bytes.Reader, version protocol.VersionNumber) (*StreamFrame, error) {
-	if !version.UsesIETFFrameFormat() {
-		return parseLegacyStreamFrame(r, version)
+	frame := &StreamFrame{}
+	if err := ParseStreamFrameInto(r, frame, version); err != nil {
+		return nil, err
 	}
+	return frame, nil
+}
 
-	frame := &StreamFrame{}
+// ParseStreamFrameInto is like ParseStreamFrame, but it parses the frame into frame instead of allocating a new StreamFrame.
+// The data is copied into the memory backing frame.Data, if it is large enough.
+func ParseStreamFrameInto(r *bytes.Reader, frame *StreamFrame, version protocol.VersionNumber) error {
+	*frame = StreamFrame{Data: frame.Data[:0]}
+	if !version.UsesIETFFrameFormat() {
+		return parseLegacyStreamFrame(r, frame, version)
+	}
 
 	typeByte, err := r.ReadByte()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	frame.FinBit = typeByte&0x1 > 0
@@ -38,13 +47,13 @@ func ParseStreamFrame(r *bytes.Reader, version protocol.VersionNumber) (*StreamF
 
 	streamID, err := utils.ReadVarInt(r)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	frame.StreamID = protocol.StreamID(streamID)
 	if hasOffset {
 		offset, err := utils.ReadVarInt(r)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		frame.Offset = protocol.ByteCount(offset)
 	}
@@ -54,32 +63,42 @@ func ParseStreamFrame(r *bytes.Reader, version protocol.VersionNumber) (*StreamF
 		var err error
 		dataLen, err = utils.ReadVarInt(r)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		// shortcut to prevent the unneccessary allocation of dataLen bytes
 		// if the dataLen is larger than the remaining length of the packet
 		// reading the packet contents would result in EOF when attempting to READ
 		if dataLen > uint64(r.Len()) {
-			return nil, io.EOF
+			return io.EOF
 		}
 	} else {
 		// The rest of the packet is data
 		dataLen = uint64(r.Len())
 	}
 	if dataLen != 0 {
-		frame.Data = make([]byte, dataLen)
-		if _, err := io.ReadFull(r, frame.Data); err != nil {
+		if err := frame.readData(r, int(dataLen)); err != nil {
 			// this should never happen, since we already checked the dataLen earlier
-			return nil, err
+			return err
 		}
 	}
 	if frame.Offset+frame.DataLen() > protocol.MaxByteCount {
-		return nil, qerr.Error(qerr.InvalidStreamData, "data overflows maximum offset")
+		return qerr.Error(qerr.InvalidStreamData, "data overflows maximum offset")
 	}
 	if !frame.FinBit && frame.DataLen() == 0 {
-		return nil, qerr.EmptyStreamFrameNoFin
+		return qerr.EmptyStreamFrameNoFin
 	}
-	return frame, nil
+	return nil
+}
+
+// readData reads n bytes of data, reusing the memory backing f.Data if possible
+func (f *StreamFrame) readData(r *bytes.Reader, n int) error {
+	if cap(f.Data) < n {
+		f.Data = make([]byte, n)
+	} else {
+		f.Data = f.Data[:n]
+	}
+	_, err := io.ReadFull(r, f.Data)
+	return err
 }
 
 // Write writes a STREAM frame
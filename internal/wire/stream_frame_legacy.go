@@ -16,12 +16,10 @@ var (
 )
 
 // parseLegacyStreamFrame reads a stream frame. The type byte must not have been read yet.
-func parseLegacyStreamFrame(r *bytes.Reader, _ protocol.VersionNumber) (*StreamFrame, error) {
-	frame := &StreamFrame{}
-
+func parseLegacyStreamFrame(r *bytes.Reader, frame *StreamFrame, _ protocol.VersionNumber) error {
 	typeByte, err := r.ReadByte()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	frame.FinBit = typeByte&0x40 > 0
@@ -34,13 +32,13 @@ func parseLegacyStreamFrame(r *bytes.Reader, _ protocol.VersionNumber) (*StreamF
 
 	sid, err := utils.BigEndian.ReadUintN(r, streamIDLen)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	frame.StreamID = protocol.StreamID(sid)
 
 	offset, err := utils.BigEndian.ReadUintN(r, offsetLen)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	frame.Offset = protocol.ByteCount(offset)
 
@@ -48,7 +46,7 @@ func parseLegacyStreamFrame(r *bytes.Reader, _ protocol.VersionNumber) (*StreamF
 	if frame.DataLenPresent {
 		dataLen, err = utils.BigEndian.ReadUint16(r)
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
 
@@ -56,7 +54,7 @@ func parseLegacyStreamFrame(r *bytes.Reader, _ protocol.VersionNumber) (*StreamF
 	// if the dataLen is larger than the remaining length of the packet
 	// reading the packet contents would result in EOF when attempting to READ
 	if int(dataLen) > r.Len() {
-		return nil, io.EOF
+		return io.EOF
 	}
 
 	if !frame.DataLenPresent {
@@ -64,22 +62,21 @@ func parseLegacyStreamFrame(r *bytes.Reader, _ protocol.VersionNumber) (*StreamF
 		dataLen = uint16(r.Len())
 	}
 	if dataLen != 0 {
-		frame.Data = make([]byte, dataLen)
-		if _, err := io.ReadFull(r, frame.Data); err != nil {
+		if err := frame.readData(r, int(dataLen)); err != nil {
 			// this should never happen, since we already checked the dataLen earlier
-			return nil, err
+			return err
 		}
 	}
 
 	// MaxByteCount is the highest value that can be encoded with the IETF QUIC variable integer encoding (2^62-1).
 	// Note that this value is smaller than the maximum value that could be encoded in the gQUIC STREAM frame (2^64-1).
 	if frame.Offset+frame.DataLen() > protocol.MaxByteCount {
-		return nil, qerr.Error(qerr.InvalidStreamData, "data overflows maximum offset")
+		return qerr.Error(qerr.InvalidStreamData, "data overflows maximum offset")
 	}
 	if !frame.FinBit && frame.DataLen() == 0 {
-		return nil, qerr.EmptyStreamFrameNoFin
+		return qerr.EmptyStreamFrameNoFin
 	}
-	return frame, nil
+	return nil
 }
 
 // writeLegacy writes a stream frame.
@@ -87,6 +87,26 @@ var _ = Describe("STREAM frame (for IETF QUIC)", func() {
 				Expect(err).To(HaveOccurred())
 			}
 		})
+
+		It("parses into an existing frame, reusing its data buffer", func() {
+			data := []byte{0x10 ^ 0x1}
+			data = append(data, encodeVarInt(9)...) // stream ID
+			data = append(data, []byte("foo")...)
+			buf := make([]byte, 0, 10)
+			frame := &StreamFrame{StreamID: 1, Offset: 1337, DataLenPresent: true, Data: buf}
+			Expect(ParseStreamFrameInto(bytes.NewReader(data), frame, versionIETFFrames)).To(Succeed())
+			Expect(frame).To(Equal(&StreamFrame{StreamID: 9, FinBit: true, Data: []byte("foo")}))
+			Expect(&frame.Data[0]).To(BeIdenticalTo(&buf[:1][0]))
+		})
+
+		It("allocates a new data buffer, if the buffer of the existing frame is too small", func() {
+			data := []byte{0x10}
+			data = append(data, encodeVarInt(9)...) // stream ID
+			data = append(data, []byte("foobar")...)
+			frame := &StreamFrame{Data: make([]byte, 0, 3)}
+			Expect(ParseStreamFrameInto(bytes.NewReader(data), frame, versionIETFFrames)).To(Succeed())
+			Expect(frame.Data).To(Equal([]byte("foobar")))
+		})
 	})
 
 	Context("when writing", func() {
@@ -61,6 +61,21 @@ func (mr *MockReceiveStreamIMockRecorder) Read(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockReceiveStreamI)(nil).Read), arg0)
 }
 
+// ReadChunk mocks base method
+func (m *MockReceiveStreamI) ReadChunk() (uint64, []byte, bool, error) {
+	ret := m.ctrl.Call(m, "ReadChunk")
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].([]byte)
+	ret2, _ := ret[2].(bool)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// ReadChunk indicates an expected call of ReadChunk
+func (mr *MockReceiveStreamIMockRecorder) ReadChunk() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadChunk", reflect.TypeOf((*MockReceiveStreamI)(nil).ReadChunk))
+}
+
 // SetReadDeadline mocks base method
 func (m *MockReceiveStreamI) SetReadDeadline(arg0 time.Time) error {
 	ret := m.ctrl.Call(m, "SetReadDeadline", arg0)
@@ -107,6 +122,18 @@ func (mr *MockReceiveStreamIMockRecorder) getWindowUpdate() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "getWindowUpdate", reflect.TypeOf((*MockReceiveStreamI)(nil).getWindowUpdate))
 }
 
+// handleExpiredStreamDataFrame mocks base method
+func (m *MockReceiveStreamI) handleExpiredStreamDataFrame(arg0 *wire.ExpiredStreamDataFrame) error {
+	ret := m.ctrl.Call(m, "handleExpiredStreamDataFrame", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// handleExpiredStreamDataFrame indicates an expected call of handleExpiredStreamDataFrame
+func (mr *MockReceiveStreamIMockRecorder) handleExpiredStreamDataFrame(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "handleExpiredStreamDataFrame", reflect.TypeOf((*MockReceiveStreamI)(nil).handleExpiredStreamDataFrame), arg0)
+}
+
 // handleRstStreamFrame mocks base method
 func (m *MockReceiveStreamI) handleRstStreamFrame(arg0 *wire.RstStreamFrame) error {
 	ret := m.ctrl.Call(m, "handleRstStreamFrame", arg0)
@@ -73,6 +73,42 @@ func (mr *MockSendStreamIMockRecorder) Context() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockSendStreamI)(nil).Context))
 }
 
+// Flush mocks base method
+func (m *MockSendStreamI) Flush() error {
+	ret := m.ctrl.Call(m, "Flush")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Flush indicates an expected call of Flush
+func (mr *MockSendStreamIMockRecorder) Flush() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Flush", reflect.TypeOf((*MockSendStreamI)(nil).Flush))
+}
+
+// SetDeliveryDeadline mocks base method
+func (m *MockSendStreamI) SetDeliveryDeadline(arg0 time.Time) error {
+	ret := m.ctrl.Call(m, "SetDeliveryDeadline", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDeliveryDeadline indicates an expected call of SetDeliveryDeadline
+func (mr *MockSendStreamIMockRecorder) SetDeliveryDeadline(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDeliveryDeadline", reflect.TypeOf((*MockSendStreamI)(nil).SetDeliveryDeadline), arg0)
+}
+
+// SetWriteCoalescingDelay mocks base method
+func (m *MockSendStreamI) SetWriteCoalescingDelay(arg0 time.Duration) error {
+	ret := m.ctrl.Call(m, "SetWriteCoalescingDelay", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetWriteCoalescingDelay indicates an expected call of SetWriteCoalescingDelay
+func (mr *MockSendStreamIMockRecorder) SetWriteCoalescingDelay(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWriteCoalescingDelay", reflect.TypeOf((*MockSendStreamI)(nil).SetWriteCoalescingDelay), arg0)
+}
+
 // SetWriteDeadline mocks base method
 func (m *MockSendStreamI) SetWriteDeadline(arg0 time.Time) error {
 	ret := m.ctrl.Call(m, "SetWriteDeadline", arg0)
@@ -140,6 +176,41 @@ func (mr *MockSendStreamIMockRecorder) handleStopSendingFrame(arg0 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "handleStopSendingFrame", reflect.TypeOf((*MockSendStreamI)(nil).handleStopSendingFrame), arg0)
 }
 
+// onDataAcked mocks base method
+func (m *MockSendStreamI) onDataAcked(arg0, arg1 protocol.ByteCount, arg2 bool) {
+	m.ctrl.Call(m, "onDataAcked", arg0, arg1, arg2)
+}
+
+// onDataAcked indicates an expected call of onDataAcked
+func (mr *MockSendStreamIMockRecorder) onDataAcked(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "onDataAcked", reflect.TypeOf((*MockSendStreamI)(nil).onDataAcked), arg0, arg1, arg2)
+}
+
+// onDataLost mocks base method
+func (m *MockSendStreamI) onDataLost(arg0, arg1 protocol.ByteCount, arg2 bool) bool {
+	ret := m.ctrl.Call(m, "onDataLost", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// onDataLost indicates an expected call of onDataLost
+func (mr *MockSendStreamIMockRecorder) onDataLost(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "onDataLost", reflect.TypeOf((*MockSendStreamI)(nil).onDataLost), arg0, arg1, arg2)
+}
+
+// popRetransmissionFrame mocks base method
+func (m *MockSendStreamI) popRetransmissionFrame(arg0 protocol.ByteCount) (*wire.StreamFrame, bool) {
+	ret := m.ctrl.Call(m, "popRetransmissionFrame", arg0)
+	ret0, _ := ret[0].(*wire.StreamFrame)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// popRetransmissionFrame indicates an expected call of popRetransmissionFrame
+func (mr *MockSendStreamIMockRecorder) popRetransmissionFrame(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "popRetransmissionFrame", reflect.TypeOf((*MockSendStreamI)(nil).popRetransmissionFrame), arg0)
+}
+
 // popStreamFrame mocks base method
 func (m *MockSendStreamI) popStreamFrame(arg0 protocol.ByteCount) (*wire.StreamFrame, bool) {
 	ret := m.ctrl.Call(m, "popStreamFrame", arg0)
@@ -85,6 +85,18 @@ func (mr *MockStreamIMockRecorder) Context() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockStreamI)(nil).Context))
 }
 
+// Flush mocks base method
+func (m *MockStreamI) Flush() error {
+	ret := m.ctrl.Call(m, "Flush")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Flush indicates an expected call of Flush
+func (mr *MockStreamIMockRecorder) Flush() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Flush", reflect.TypeOf((*MockStreamI)(nil).Flush))
+}
+
 // Read mocks base method
 func (m *MockStreamI) Read(arg0 []byte) (int, error) {
 	ret := m.ctrl.Call(m, "Read", arg0)
@@ -98,6 +110,21 @@ func (mr *MockStreamIMockRecorder) Read(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockStreamI)(nil).Read), arg0)
 }
 
+// ReadChunk mocks base method
+func (m *MockStreamI) ReadChunk() (uint64, []byte, bool, error) {
+	ret := m.ctrl.Call(m, "ReadChunk")
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].([]byte)
+	ret2, _ := ret[2].(bool)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// ReadChunk indicates an expected call of ReadChunk
+func (mr *MockStreamIMockRecorder) ReadChunk() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadChunk", reflect.TypeOf((*MockStreamI)(nil).ReadChunk))
+}
+
 // SetDeadline mocks base method
 func (m *MockStreamI) SetDeadline(arg0 time.Time) error {
 	ret := m.ctrl.Call(m, "SetDeadline", arg0)
@@ -110,6 +137,18 @@ func (mr *MockStreamIMockRecorder) SetDeadline(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDeadline", reflect.TypeOf((*MockStreamI)(nil).SetDeadline), arg0)
 }
 
+// SetDeliveryDeadline mocks base method
+func (m *MockStreamI) SetDeliveryDeadline(arg0 time.Time) error {
+	ret := m.ctrl.Call(m, "SetDeliveryDeadline", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDeliveryDeadline indicates an expected call of SetDeliveryDeadline
+func (mr *MockStreamIMockRecorder) SetDeliveryDeadline(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDeliveryDeadline", reflect.TypeOf((*MockStreamI)(nil).SetDeliveryDeadline), arg0)
+}
+
 // SetReadDeadline mocks base method
 func (m *MockStreamI) SetReadDeadline(arg0 time.Time) error {
 	ret := m.ctrl.Call(m, "SetReadDeadline", arg0)
@@ -122,6 +161,18 @@ func (mr *MockStreamIMockRecorder) SetReadDeadline(arg0 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReadDeadline", reflect.TypeOf((*MockStreamI)(nil).SetReadDeadline), arg0)
 }
 
+// SetWriteCoalescingDelay mocks base method
+func (m *MockStreamI) SetWriteCoalescingDelay(arg0 time.Duration) error {
+	ret := m.ctrl.Call(m, "SetWriteCoalescingDelay", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetWriteCoalescingDelay indicates an expected call of SetWriteCoalescingDelay
+func (mr *MockStreamIMockRecorder) SetWriteCoalescingDelay(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWriteCoalescingDelay", reflect.TypeOf((*MockStreamI)(nil).SetWriteCoalescingDelay), arg0)
+}
+
 // SetWriteDeadline mocks base method
 func (m *MockStreamI) SetWriteDeadline(arg0 time.Time) error {
 	ret := m.ctrl.Call(m, "SetWriteDeadline", arg0)
@@ -181,6 +232,18 @@ func (mr *MockStreamIMockRecorder) getWindowUpdate() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "getWindowUpdate", reflect.TypeOf((*MockStreamI)(nil).getWindowUpdate))
 }
 
+// handleExpiredStreamDataFrame mocks base method
+func (m *MockStreamI) handleExpiredStreamDataFrame(arg0 *wire.ExpiredStreamDataFrame) error {
+	ret := m.ctrl.Call(m, "handleExpiredStreamDataFrame", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// handleExpiredStreamDataFrame indicates an expected call of handleExpiredStreamDataFrame
+func (mr *MockStreamIMockRecorder) handleExpiredStreamDataFrame(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "handleExpiredStreamDataFrame", reflect.TypeOf((*MockStreamI)(nil).handleExpiredStreamDataFrame), arg0)
+}
+
 // handleMaxStreamDataFrame mocks base method
 func (m *MockStreamI) handleMaxStreamDataFrame(arg0 *wire.MaxStreamDataFrame) {
 	m.ctrl.Call(m, "handleMaxStreamDataFrame", arg0)
@@ -225,6 +288,41 @@ func (mr *MockStreamIMockRecorder) handleStreamFrame(arg0 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "handleStreamFrame", reflect.TypeOf((*MockStreamI)(nil).handleStreamFrame), arg0)
 }
 
+// onDataAcked mocks base method
+func (m *MockStreamI) onDataAcked(arg0, arg1 protocol.ByteCount, arg2 bool) {
+	m.ctrl.Call(m, "onDataAcked", arg0, arg1, arg2)
+}
+
+// onDataAcked indicates an expected call of onDataAcked
+func (mr *MockStreamIMockRecorder) onDataAcked(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "onDataAcked", reflect.TypeOf((*MockStreamI)(nil).onDataAcked), arg0, arg1, arg2)
+}
+
+// onDataLost mocks base method
+func (m *MockStreamI) onDataLost(arg0, arg1 protocol.ByteCount, arg2 bool) bool {
+	ret := m.ctrl.Call(m, "onDataLost", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// onDataLost indicates an expected call of onDataLost
+func (mr *MockStreamIMockRecorder) onDataLost(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "onDataLost", reflect.TypeOf((*MockStreamI)(nil).onDataLost), arg0, arg1, arg2)
+}
+
+// popRetransmissionFrame mocks base method
+func (m *MockStreamI) popRetransmissionFrame(arg0 protocol.ByteCount) (*wire.StreamFrame, bool) {
+	ret := m.ctrl.Call(m, "popRetransmissionFrame", arg0)
+	ret0, _ := ret[0].(*wire.StreamFrame)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// popRetransmissionFrame indicates an expected call of popRetransmissionFrame
+func (mr *MockStreamIMockRecorder) popRetransmissionFrame(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "popRetransmissionFrame", reflect.TypeOf((*MockStreamI)(nil).popRetransmissionFrame), arg0)
+}
+
 // popStreamFrame mocks base method
 func (m *MockStreamI) popStreamFrame(arg0 protocol.ByteCount) (*wire.StreamFrame, bool) {
 	ret := m.ctrl.Call(m, "popStreamFrame", arg0)
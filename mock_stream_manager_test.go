@@ -10,6 +10,7 @@ import (
 	gomock "github.com/golang/mock/gomock"
 	handshake "github.com/lucas-clemente/quic-go/internal/handshake"
 	protocol "github.com/lucas-clemente/quic-go/internal/protocol"
+	wire "github.com/lucas-clemente/quic-go/internal/wire"
 )
 
 // MockStreamManager is a mock of StreamManager interface
@@ -109,6 +110,18 @@ func (mr *MockStreamManagerMockRecorder) GetOrOpenStream(arg0 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrOpenStream", reflect.TypeOf((*MockStreamManager)(nil).GetOrOpenStream), arg0)
 }
 
+// HandleMaxStreamIDFrame mocks base method
+func (m *MockStreamManager) HandleMaxStreamIDFrame(arg0 *wire.MaxStreamIDFrame) error {
+	ret := m.ctrl.Call(m, "HandleMaxStreamIDFrame", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HandleMaxStreamIDFrame indicates an expected call of HandleMaxStreamIDFrame
+func (mr *MockStreamManagerMockRecorder) HandleMaxStreamIDFrame(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleMaxStreamIDFrame", reflect.TypeOf((*MockStreamManager)(nil).HandleMaxStreamIDFrame), arg0)
+}
+
 // OpenStream mocks base method
 func (m *MockStreamManager) OpenStream() (Stream, error) {
 	ret := m.ctrl.Call(m, "OpenStream")
@@ -135,6 +148,18 @@ func (mr *MockStreamManagerMockRecorder) OpenStreamSync() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenStreamSync", reflect.TypeOf((*MockStreamManager)(nil).OpenStreamSync))
 }
 
+// SetMaxIncomingStreams mocks base method
+func (m *MockStreamManager) SetMaxIncomingStreams(arg0 int) error {
+	ret := m.ctrl.Call(m, "SetMaxIncomingStreams", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMaxIncomingStreams indicates an expected call of SetMaxIncomingStreams
+func (mr *MockStreamManagerMockRecorder) SetMaxIncomingStreams(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMaxIncomingStreams", reflect.TypeOf((*MockStreamManager)(nil).SetMaxIncomingStreams), arg0)
+}
+
 // UpdateLimits mocks base method
 func (m *MockStreamManager) UpdateLimits(arg0 *handshake.TransportParameters) {
 	m.ctrl.Call(m, "UpdateLimits", arg0)
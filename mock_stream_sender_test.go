@@ -35,6 +35,18 @@ func (m *MockStreamSender) EXPECT() *MockStreamSenderMockRecorder {
 	return m.recorder
 }
 
+// maxStreamDataPerPacket mocks base method
+func (m *MockStreamSender) maxStreamDataPerPacket() protocol.ByteCount {
+	ret := m.ctrl.Call(m, "maxStreamDataPerPacket")
+	ret0, _ := ret[0].(protocol.ByteCount)
+	return ret0
+}
+
+// maxStreamDataPerPacket indicates an expected call of maxStreamDataPerPacket
+func (mr *MockStreamSenderMockRecorder) maxStreamDataPerPacket() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "maxStreamDataPerPacket", reflect.TypeOf((*MockStreamSender)(nil).maxStreamDataPerPacket))
+}
+
 // onHasStreamData mocks base method
 func (m *MockStreamSender) onHasStreamData(arg0 protocol.StreamID) {
 	m.ctrl.Call(m, "onHasStreamData", arg0)
@@ -65,6 +77,18 @@ func (mr *MockStreamSenderMockRecorder) onStreamCompleted(arg0 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "onStreamCompleted", reflect.TypeOf((*MockStreamSender)(nil).onStreamCompleted), arg0)
 }
 
+// peerSupportsExpiredStreamData mocks base method
+func (m *MockStreamSender) peerSupportsExpiredStreamData() bool {
+	ret := m.ctrl.Call(m, "peerSupportsExpiredStreamData")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// peerSupportsExpiredStreamData indicates an expected call of peerSupportsExpiredStreamData
+func (mr *MockStreamSenderMockRecorder) peerSupportsExpiredStreamData() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "peerSupportsExpiredStreamData", reflect.TypeOf((*MockStreamSender)(nil).peerSupportsExpiredStreamData))
+}
+
 // queueControlFrame mocks base method
 func (m *MockStreamSender) queueControlFrame(arg0 wire.Frame) {
 	m.ctrl.Call(m, "queueControlFrame", arg0)
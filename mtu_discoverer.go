@@ -0,0 +1,103 @@
+package quic
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+	"github.com/lucas-clemente/quic-go/internal/wire"
+)
+
+// The mtuDiscoverer performs packetization layer path MTU discovery.
+// It does a binary search for the largest packet size that can be sent on the path,
+// by sending probe packets (a PING frame plus padding) of increasing size.
+// An acknowledged probe raises the packet size. Lost probes are never treated as a congestion signal.
+type mtuDiscoverer struct {
+	current protocol.ByteCount // the largest packet size that is known to work
+	limit   protocol.ByteCount // the largest packet size that is probed
+	high    protocol.ByteCount // the upper end of the search interval
+
+	probeInFlight     bool
+	probePacketNumber protocol.PacketNumber
+	probeSize         protocol.ByteCount
+	numLostProbes     int // the number of lost probes of probeSize
+
+	nextProbeTime time.Time
+}
+
+func newMTUDiscoverer(current, limit protocol.ByteCount) *mtuDiscoverer {
+	return &mtuDiscoverer{
+		current: current,
+		limit:   limit,
+		high:    limit,
+	}
+}
+
+// ShouldSendProbe says if a probe packet should be sent now
+func (d *mtuDiscoverer) ShouldSendProbe(now time.Time) bool {
+	if d.probeInFlight || now.Before(d.nextProbeTime) {
+		return false
+	}
+	return d.high-d.current >= protocol.MTUDiscoveryThreshold
+}
+
+// NextProbeSize returns the size of the next probe packet
+func (d *mtuDiscoverer) NextProbeSize() protocol.ByteCount {
+	if d.numLostProbes > 0 { // retry the same size
+		return d.probeSize
+	}
+	return d.current + (d.high-d.current+1)/2
+}
+
+// SentProbe is called when a probe packet was sent
+func (d *mtuDiscoverer) SentProbe(pn protocol.PacketNumber, size protocol.ByteCount) {
+	d.probeInFlight = true
+	d.probePacketNumber = pn
+	d.probeSize = size
+}
+
+// ReceivedAck is called for every ACK frame received.
+// It returns true if the ACK acknowledged the probe packet, i.e. if the packet size increased.
+func (d *mtuDiscoverer) ReceivedAck(frame *wire.AckFrame, now time.Time) bool {
+	if !d.probeInFlight || !frame.AcksPacket(d.probePacketNumber) {
+		return false
+	}
+	utils.Debugf("MTU probe of %d bytes acknowledged", d.probeSize)
+	d.probeInFlight = false
+	d.numLostProbes = 0
+	d.current = d.probeSize
+	d.maybeFinish(now)
+	return true
+}
+
+// LostProbe is called when a probe packet was declared lost
+func (d *mtuDiscoverer) LostProbe(pn protocol.PacketNumber, now time.Time) {
+	if !d.probeInFlight || pn != d.probePacketNumber {
+		return
+	}
+	d.probeInFlight = false
+	d.numLostProbes++
+	if d.numLostProbes < protocol.MaxMTUProbes {
+		return
+	}
+	utils.Debugf("%d MTU probes of %d bytes lost", d.numLostProbes, d.probeSize)
+	d.numLostProbes = 0
+	d.high = d.probeSize - 1
+	d.maybeFinish(now)
+}
+
+// CurrentSize returns the largest packet size that is known to work
+func (d *mtuDiscoverer) CurrentSize() protocol.ByteCount {
+	return d.current
+}
+
+// maybeFinish ends the search if it converged.
+// The search is restarted after protocol.MTUDiscoveryRestartInterval, since the path might have changed.
+func (d *mtuDiscoverer) maybeFinish(now time.Time) {
+	if d.high-d.current >= protocol.MTUDiscoveryThreshold {
+		return
+	}
+	utils.Debugf("MTU discovery finished, using a packet size of %d bytes", d.current)
+	d.high = d.limit
+	d.nextProbeTime = now.Add(protocol.MTUDiscoveryRestartInterval)
+}
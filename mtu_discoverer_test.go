@@ -0,0 +1,90 @@
+package quic
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/wire"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MTU Discoverer", func() {
+	var (
+		d   *mtuDiscoverer
+		now time.Time
+	)
+
+	BeforeEach(func() {
+		d = newMTUDiscoverer(1200, 1400)
+		now = time.Now()
+	})
+
+	ack := func(pn protocol.PacketNumber) *wire.AckFrame {
+		return &wire.AckFrame{LargestAcked: pn, LowestAcked: pn}
+	}
+
+	It("probes the middle of the search interval", func() {
+		Expect(d.ShouldSendProbe(now)).To(BeTrue())
+		Expect(d.NextProbeSize()).To(Equal(protocol.ByteCount(1300)))
+	})
+
+	It("doesn't send a probe while another probe is in flight", func() {
+		d.SentProbe(1, 1300)
+		Expect(d.ShouldSendProbe(now)).To(BeFalse())
+	})
+
+	It("increases the packet size when a probe is acknowledged", func() {
+		d.SentProbe(1, 1300)
+		Expect(d.ReceivedAck(ack(2), now)).To(BeFalse())
+		Expect(d.CurrentSize()).To(Equal(protocol.ByteCount(1200)))
+		Expect(d.ReceivedAck(ack(1), now)).To(BeTrue())
+		Expect(d.CurrentSize()).To(Equal(protocol.ByteCount(1300)))
+		Expect(d.ShouldSendProbe(now)).To(BeTrue())
+		Expect(d.NextProbeSize()).To(Equal(protocol.ByteCount(1350)))
+	})
+
+	It("retries a probe size before considering it too large", func() {
+		for i := 1; i < protocol.MaxMTUProbes; i++ {
+			d.SentProbe(protocol.PacketNumber(i), 1300)
+			d.LostProbe(protocol.PacketNumber(i), now)
+			Expect(d.ShouldSendProbe(now)).To(BeTrue())
+			Expect(d.NextProbeSize()).To(Equal(protocol.ByteCount(1300)))
+		}
+		d.SentProbe(100, 1300)
+		d.LostProbe(100, now)
+		Expect(d.NextProbeSize()).To(Equal(protocol.ByteCount(1250)))
+		Expect(d.CurrentSize()).To(Equal(protocol.ByteCount(1200)))
+	})
+
+	It("ignores losses of packets other than the probe", func() {
+		d.SentProbe(1, 1300)
+		d.LostProbe(2, now)
+		Expect(d.probeInFlight).To(BeTrue())
+	})
+
+	It("finishes the search when the interval is small enough", func() {
+		var pn protocol.PacketNumber
+		for d.ShouldSendProbe(now) {
+			pn++
+			d.SentProbe(pn, d.NextProbeSize())
+			Expect(d.ReceivedAck(ack(pn), now)).To(BeTrue())
+		}
+		Expect(d.CurrentSize()).To(BeNumerically(">", 1400-protocol.MTUDiscoveryThreshold))
+		Expect(d.CurrentSize()).To(BeNumerically("<=", 1400))
+		// the search won't be restarted, since the packet size is close enough to the maximum
+		Expect(d.ShouldSendProbe(now.Add(protocol.MTUDiscoveryRestartInterval))).To(BeFalse())
+	})
+
+	It("restarts the search after a probe size was found to be too large", func() {
+		d = newMTUDiscoverer(1200, 1220)
+		for i := 0; i < protocol.MaxMTUProbes; i++ {
+			d.SentProbe(protocol.PacketNumber(i), 1210)
+			d.LostProbe(protocol.PacketNumber(i), now)
+		}
+		Expect(d.ShouldSendProbe(now)).To(BeFalse())
+		Expect(d.ShouldSendProbe(now.Add(protocol.MTUDiscoveryRestartInterval))).To(BeTrue())
+		Expect(d.NextProbeSize()).To(Equal(protocol.ByteCount(1210)))
+	})
+})
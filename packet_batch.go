@@ -0,0 +1,39 @@
+package quic
+
+import "github.com/lucas-clemente/quic-go/internal/protocol"
+
+// A packetBatch collects packets that are sent on the same connection,
+// so they can be written using as few system calls as possible.
+type packetBatch struct {
+	conn    connection // nil if packets are currently not batched
+	ecn     protocol.ECN
+	packets [][]byte
+}
+
+// Add adds a packet to the batch.
+// All packets of a batch use the same ECN codepoint, so the batch is flushed if the codepoint changes.
+// The packet buffer is returned to the buffer pool once the packet was written.
+func (b *packetBatch) Add(raw []byte, ecn protocol.ECN) error {
+	if len(b.packets) > 0 && (ecn != b.ecn || len(b.packets) >= protocol.MaxBatchSize) {
+		if err := b.Flush(); err != nil {
+			return err
+		}
+	}
+	b.ecn = ecn
+	b.packets = append(b.packets, raw)
+	return nil
+}
+
+// Flush writes all packets of the batch
+func (b *packetBatch) Flush() error {
+	if len(b.packets) == 0 {
+		return nil
+	}
+	err := b.conn.WriteBatch(b.packets, b.ecn)
+	for i, p := range b.packets {
+		putPacketBuffer(p)
+		b.packets[i] = nil
+	}
+	b.packets = b.packets[:0]
+	return err
+}
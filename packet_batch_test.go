@@ -0,0 +1,61 @@
+package quic
+
+import (
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Packet batch", func() {
+	var (
+		batch *packetBatch
+		mconn *mockConnection
+	)
+
+	BeforeEach(func() {
+		mconn = newMockConnection()
+		batch = &packetBatch{conn: mconn}
+	})
+
+	getPacket := func(b byte) []byte {
+		return append(getPacketBuffer(protocol.MaxReceivePacketSize), b)
+	}
+
+	It("writes packets when flushing", func() {
+		Expect(batch.Add(getPacket(1), protocol.ECT0)).To(Succeed())
+		Expect(batch.Add(getPacket(2), protocol.ECT0)).To(Succeed())
+		Expect(mconn.written).To(BeEmpty())
+		Expect(batch.Flush()).To(Succeed())
+		Expect(mconn.written).To(Receive(Equal([]byte{1})))
+		Expect(mconn.written).To(Receive(Equal([]byte{2})))
+		Expect(mconn.ecn).To(Equal(protocol.ECT0))
+		Expect(batch.packets).To(BeEmpty())
+	})
+
+	It("doesn't write anything if the batch is empty", func() {
+		Expect(batch.Flush()).To(Succeed())
+		Expect(mconn.written).To(BeEmpty())
+	})
+
+	It("flushes when the ECN codepoint changes", func() {
+		Expect(batch.Add(getPacket(1), protocol.ECT0)).To(Succeed())
+		Expect(batch.Add(getPacket(2), protocol.ECNNon)).To(Succeed())
+		Expect(mconn.written).To(Receive(Equal([]byte{1})))
+		Expect(mconn.ecn).To(Equal(protocol.ECT0))
+		Expect(mconn.written).To(BeEmpty())
+		Expect(batch.Flush()).To(Succeed())
+		Expect(mconn.written).To(Receive(Equal([]byte{2})))
+		Expect(mconn.ecn).To(Equal(protocol.ECNNon))
+	})
+
+	It("flushes when the batch is full", func() {
+		for i := 0; i < protocol.MaxBatchSize; i++ {
+			Expect(batch.Add(getPacket(byte(i)), protocol.ECNNon)).To(Succeed())
+		}
+		Expect(mconn.written).To(BeEmpty())
+		Expect(batch.Add(getPacket(0xff), protocol.ECNNon)).To(Succeed())
+		Expect(mconn.written).To(HaveLen(protocol.MaxBatchSize))
+		Expect(batch.packets).To(HaveLen(1))
+	})
+})
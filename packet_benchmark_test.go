@@ -0,0 +1,134 @@
+package quic
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/lucas-clemente/quic-go/internal/crypto"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/wire"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// forwardSecureAEAD is a quicAEAD that opens all packets with a forward-secure AEAD
+type forwardSecureAEAD struct {
+	crypto.AEAD
+}
+
+func (a *forwardSecureAEAD) Open(dst, src []byte, packetNumber protocol.PacketNumber, associatedData []byte) ([]byte, protocol.EncryptionLevel, error) {
+	data, err := a.AEAD.Open(dst, src, packetNumber, associatedData)
+	return data, protocol.EncryptionForwardSecure, err
+}
+
+// A packetRoundTrip packs, seals, parses and unpacks a forward-secure packet containing an ACK and a STREAM frame,
+// as the server sends it and the client receives it.
+type packetRoundTrip struct {
+	version protocol.VersionNumber
+
+	packer *packetPacker
+	sealer crypto.AEAD
+	header *wire.Header
+	frames []wire.Frame
+
+	unpacker                *packetUnpacker
+	reader                  bytes.Reader
+	hdr                     wire.Header
+	largestRcvdPacketNumber protocol.PacketNumber
+}
+
+func newPacketRoundTrip(version protocol.VersionNumber) (*packetRoundTrip, error) {
+	keyLen := 16
+	ivLen := 12
+	newAEAD := crypto.NewAEADAESGCM
+	if !version.UsesTLS() {
+		ivLen = 4
+		newAEAD = crypto.NewAEADAESGCM12
+	}
+	clientKey, serverKey := bytes.Repeat([]byte{1}, keyLen), bytes.Repeat([]byte{2}, keyLen)
+	clientIV, serverIV := bytes.Repeat([]byte{3}, ivLen), bytes.Repeat([]byte{4}, ivLen)
+	sealer, err := newAEAD(clientKey, serverKey, clientIV, serverIV)
+	if err != nil {
+		return nil, err
+	}
+	opener, err := newAEAD(serverKey, clientKey, serverIV, clientIV)
+	if err != nil {
+		return nil, err
+	}
+
+	packer := newPacketPacker(0x1337, 1, &mockCryptoSetup{encLevelSeal: protocol.EncryptionForwardSecure}, nil, protocol.PerspectiveServer, version)
+	packer.hasSentPacket = true
+	return &packetRoundTrip{
+		version: version,
+		packer:  packer,
+		sealer:  sealer,
+		header:  packer.getHeader(protocol.EncryptionForwardSecure),
+		frames: []wire.Frame{
+			&wire.AckFrame{LargestAcked: 100, LowestAcked: 1},
+			&wire.StreamFrame{StreamID: 5, Offset: 1 << 20, Data: bytes.Repeat([]byte{'f'}, 1000)},
+		},
+		unpacker: &packetUnpacker{aead: &forwardSecureAEAD{AEAD: opener}, version: version},
+	}, nil
+}
+
+func (t *packetRoundTrip) run() error {
+	t.header.PacketNumber = t.packer.packetNumberGenerator.Peek()
+	raw, err := t.packer.writeAndSealPacket(t.header, t.frames, t.sealer)
+	if err != nil {
+		return err
+	}
+	defer putPacketBuffer(raw)
+
+	t.reader.Reset(raw)
+	if err := wire.ParseHeaderSentByServerInto(&t.reader, t.version, &t.hdr); err != nil {
+		return err
+	}
+	t.hdr.Raw = raw[:len(raw)-t.reader.Len()]
+	t.hdr.PacketNumber = protocol.InferPacketNumber(t.hdr.PacketNumberLen, t.largestRcvdPacketNumber, t.hdr.PacketNumber)
+	t.largestRcvdPacketNumber = t.hdr.PacketNumber
+	packet, err := t.unpacker.Unpack(t.hdr.Raw, &t.hdr, raw[len(t.hdr.Raw):])
+	if err != nil {
+		return err
+	}
+	if len(packet.frames) != 2 {
+		return errors.New("unexpected number of frames")
+	}
+	return nil
+}
+
+var _ = Describe("Packet processing allocations", func() {
+	for _, v := range []protocol.VersionNumber{protocol.Version39, protocol.VersionTLS} {
+		version := v
+
+		It("doesn't allocate when sending and receiving STREAM and ACK frames, for "+version.String(), func() {
+			t, err := newPacketRoundTrip(version)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(t.run()).To(Succeed()) // warm up the buffers
+			allocs := testing.AllocsPerRun(100, func() {
+				if err := t.run(); err != nil {
+					Fail(err.Error())
+				}
+			})
+			Expect(allocs).To(BeZero())
+		})
+	}
+})
+
+func benchmarkPacketRoundTrip(b *testing.B, version protocol.VersionNumber) {
+	t, err := newPacketRoundTrip(version)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := t.run(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPacketRoundTripGQUIC(b *testing.B) { benchmarkPacketRoundTrip(b, protocol.Version39) }
+func BenchmarkPacketRoundTripIETF(b *testing.B)  { benchmarkPacketRoundTrip(b, protocol.VersionTLS) }
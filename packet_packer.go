@@ -9,14 +9,17 @@ import (
 	"github.com/lucas-clemente/quic-go/internal/ackhandler"
 	"github.com/lucas-clemente/quic-go/internal/handshake"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
 	"github.com/lucas-clemente/quic-go/internal/wire"
 )
 
 type packedPacket struct {
-	header          *wire.Header
-	raw             []byte
-	frames          []wire.Frame
-	encryptionLevel protocol.EncryptionLevel
+	header           *wire.Header
+	raw              []byte
+	frames           []wire.Frame
+	encryptionLevel  protocol.EncryptionLevel
+	isMTUProbePacket bool
+	isRepairPacket   bool
 }
 
 type streamFrameSource interface {
@@ -31,8 +34,12 @@ type packetPacker struct {
 	version      protocol.VersionNumber
 	cryptoSetup  handshake.CryptoSetup
 
+	// the path that packets are currently packed for, and the packet number generator of this path
+	pathID                protocol.PathID
 	packetNumberGenerator *packetNumberGenerator
-	streams               streamFrameSource
+	// every path has its own packet number space
+	packetNumberGenerators map[protocol.PathID]*packetNumberGenerator
+	streams                streamFrameSource
 
 	controlFrameMutex sync.Mutex
 	controlFrames     []wire.Frame
@@ -41,10 +48,18 @@ type packetPacker struct {
 	ackFrame                  *wire.AckFrame
 	leastUnacked              protocol.PacketNumber
 	omitConnectionID          bool
+	fecEnabled                bool
+	maxPacketSize             protocol.ByteCount
 	hasSentPacket             bool // has the packetPacker already sent a packet
 	numNonRetransmittableAcks int
+
+	// buffer is reused for every packet, to avoid allocations
+	buffer bytes.Buffer
 }
 
+// paddingBytes are written to pad packets, to avoid allocating a new slice of zeros for every padded packet
+var paddingBytes [protocol.MaxPacketSize]byte
+
 func newPacketPacker(connectionID protocol.ConnectionID,
 	initialPacketNumber protocol.PacketNumber,
 	cryptoSetup handshake.CryptoSetup,
@@ -52,13 +67,16 @@ func newPacketPacker(connectionID protocol.ConnectionID,
 	perspective protocol.Perspective,
 	version protocol.VersionNumber,
 ) *packetPacker {
+	png := newPacketNumberGenerator(initialPacketNumber, protocol.SkipPacketAveragePeriodLength)
 	return &packetPacker{
-		cryptoSetup:           cryptoSetup,
-		connectionID:          connectionID,
-		perspective:           perspective,
-		version:               version,
-		streams:               streamFramer,
-		packetNumberGenerator: newPacketNumberGenerator(initialPacketNumber, protocol.SkipPacketAveragePeriodLength),
+		cryptoSetup:            cryptoSetup,
+		connectionID:           connectionID,
+		perspective:            perspective,
+		version:                version,
+		streams:                streamFramer,
+		maxPacketSize:          protocol.MaxPacketSize,
+		packetNumberGenerator:  png,
+		packetNumberGenerators: map[protocol.PathID]*packetNumberGenerator{protocol.InitialPathID: png},
 	}
 }
 
@@ -99,6 +117,80 @@ func (p *packetPacker) PackAckPacket() (*packedPacket, error) {
 	}, err
 }
 
+// PackRepairPacket packs a packet that ONLY contains a FECFrame
+func (p *packetPacker) PackRepairPacket(f *wire.FECFrame) (*packedPacket, error) {
+	encLevel, sealer := p.cryptoSetup.GetSealer()
+	if encLevel != protocol.EncryptionForwardSecure {
+		return nil, errors.New("PacketPacker BUG: repair packets must be sent forward-secure")
+	}
+	header := p.getHeader(encLevel)
+	frames := []wire.Frame{f}
+	raw, err := p.writeAndSealPacket(header, frames, sealer)
+	return &packedPacket{
+		header:          header,
+		raw:             raw,
+		frames:          frames,
+		encryptionLevel: encLevel,
+		isRepairPacket:  true,
+	}, err
+}
+
+// PackMTUProbePacket packs a packet of the given size, consisting of a PING frame and padding.
+// The size may exceed the maximum packet size, since the packet is used to probe if larger packets can be sent on the path.
+func (p *packetPacker) PackMTUProbePacket(size protocol.ByteCount) (*packedPacket, error) {
+	encLevel, sealer := p.cryptoSetup.GetSealer()
+	if encLevel != protocol.EncryptionForwardSecure {
+		return nil, errors.New("PacketPacker BUG: MTU probe packets must be sent forward-secure")
+	}
+	header := p.getHeader(encLevel)
+	frames := []wire.Frame{&wire.PingFrame{}}
+	raw, err := p.writeAndSealPaddedPacket(header, frames, sealer, size)
+	if err != nil {
+		return nil, err
+	}
+	return &packedPacket{
+		header:           header,
+		raw:              raw,
+		frames:           frames,
+		encryptionLevel:  encLevel,
+		isMTUProbePacket: true,
+	}, nil
+}
+
+// PackRedundantPacket packs a packet containing the retransmittable frames of a packet that was already sent on a different path
+func (p *packetPacker) PackRedundantPacket(frames []wire.Frame) (*packedPacket, error) {
+	encLevel, sealer := p.cryptoSetup.GetSealer()
+	if encLevel != protocol.EncryptionForwardSecure {
+		return nil, errors.New("PacketPacker BUG: redundant packets must be sent forward-secure")
+	}
+	header := p.getHeader(encLevel)
+	var redundantFrames []wire.Frame
+	for _, frame := range frames {
+		if !ackhandler.IsFrameRetransmittable(frame) {
+			continue
+		}
+		// STREAM frames might be split when they are retransmitted, so every path needs its own copy
+		if sf, ok := frame.(*wire.StreamFrame); ok {
+			sfCopy := *sf
+			frame = &sfCopy
+		}
+		redundantFrames = append(redundantFrames, frame)
+	}
+	if len(redundantFrames) == 0 {
+		return nil, nil
+	}
+	raw, err := p.writeAndSealPacket(header, redundantFrames, sealer)
+	if err != nil {
+		return nil, err
+	}
+	return &packedPacket{
+		header:          header,
+		raw:             raw,
+		frames:          redundantFrames,
+		encryptionLevel: encLevel,
+	}, nil
+}
+
 // PackHandshakeRetransmission retransmits a handshake packet, that was sent with less than forward-secure encryption
 func (p *packetPacker) PackHandshakeRetransmission(packet *ackhandler.Packet) (*packedPacket, error) {
 	if packet.EncryptionLevel == protocol.EncryptionForwardSecure {
@@ -155,7 +247,16 @@ func (p *packetPacker) PackPacket() (*packedPacket, error) {
 		p.stopWaiting.PacketNumberLen = header.PacketNumberLen
 	}
 
-	maxSize := protocol.MaxPacketSize - protocol.ByteCount(sealer.Overhead()) - headerLength
+	maxSize := p.maxPacketSize - protocol.ByteCount(sealer.Overhead()) - headerLength
+	if p.fecEnabled && encLevel == protocol.EncryptionForwardSecure {
+		// leave enough space for sending this packet in a repair packet
+		maxSize -= protocol.FECPacketSizeReduction
+	}
+	if len(p.packetNumberGenerators) > 1 {
+		// The frames might be sent redundantly on a different path.
+		// The header for that path might contain a path ID and a longer packet number.
+		maxSize -= 1 + protocol.ByteCount(protocol.PacketNumberLen4-protocol.PacketNumberLen2)
+	}
 	payloadFrames, err := p.composeNextPacket(maxSize, p.canSendData(encLevel))
 	if err != nil {
 		return nil, err
@@ -204,7 +305,7 @@ func (p *packetPacker) packCryptoPacket() (*packedPacket, error) {
 	if err != nil {
 		return nil, err
 	}
-	maxLen := protocol.MaxPacketSize - protocol.ByteCount(sealer.Overhead()) - protocol.NonForwardSecurePacketSizeReduction - headerLength
+	maxLen := p.maxPacketSize - protocol.ByteCount(sealer.Overhead()) - protocol.NonForwardSecurePacketSizeReduction - headerLength
 	sf := p.streams.PopCryptoStreamFrame(maxLen)
 	sf.DataLenPresent = false
 	frames := []wire.Frame{sf}
@@ -300,6 +401,7 @@ func (p *packetPacker) getHeader(encLevel protocol.EncryptionLevel) *wire.Header
 
 	header := &wire.Header{
 		ConnectionID:    p.connectionID,
+		PathID:          p.pathID,
 		PacketNumber:    pnum,
 		PacketNumberLen: packetNumberLen,
 	}
@@ -338,8 +440,20 @@ func (p *packetPacker) writeAndSealPacket(
 	payloadFrames []wire.Frame,
 	sealer handshake.Sealer,
 ) ([]byte, error) {
-	raw := getPacketBuffer()
-	buffer := bytes.NewBuffer(raw)
+	return p.writeAndSealPaddedPacket(header, payloadFrames, sealer, 0)
+}
+
+// writeAndSealPaddedPacket pads the packet to paddedSize bytes, if it is smaller than that.
+// A padded packet may exceed the maximum packet size.
+func (p *packetPacker) writeAndSealPaddedPacket(
+	header *wire.Header,
+	payloadFrames []wire.Frame,
+	sealer handshake.Sealer,
+	paddedSize protocol.ByteCount,
+) ([]byte, error) {
+	raw := getPacketBuffer(utils.MaxByteCount(p.maxPacketSize, paddedSize))
+	buffer := &p.buffer
+	*buffer = *bytes.NewBuffer(raw)
 
 	if err := header.Write(buffer, p.perspective, p.version); err != nil {
 		return nil, err
@@ -361,17 +475,19 @@ func (p *packetPacker) writeAndSealPacket(
 	// if this is an IETF QUIC Initial packet, we need to pad it to fulfill the minimum size requirement
 	// in gQUIC, padding is handled in the CHLO
 	if header.Type == protocol.PacketTypeInitial {
-		paddingLen := protocol.MinInitialPacketSize - sealer.Overhead() - buffer.Len()
-		if paddingLen > 0 {
-			buffer.Write(bytes.Repeat([]byte{0}, paddingLen))
-		}
+		paddedSize = protocol.MinInitialPacketSize
+	}
+	for paddingLen := int(paddedSize) - sealer.Overhead() - buffer.Len(); paddingLen > 0; {
+		n := utils.Min(paddingLen, len(paddingBytes))
+		buffer.Write(paddingBytes[:n])
+		paddingLen -= n
 	}
-	if protocol.ByteCount(buffer.Len()+sealer.Overhead()) > protocol.MaxPacketSize {
+	if protocol.ByteCount(buffer.Len()+sealer.Overhead()) > utils.MaxByteCount(p.maxPacketSize, paddedSize) {
 		return nil, errors.New("PacketPacker BUG: packet too large")
 	}
 
 	raw = raw[0:buffer.Len()]
-	_ = sealer.Seal(raw[payloadStartIndex:payloadStartIndex], raw[payloadStartIndex:], header.PacketNumber, raw[:payloadStartIndex])
+	_ = sealer.Seal(raw[payloadStartIndex:payloadStartIndex], raw[payloadStartIndex:], protocol.NoncePacketNumber(header.PathID, header.PacketNumber), raw[:payloadStartIndex])
 	raw = raw[0 : buffer.Len()+sealer.Overhead()]
 
 	num := p.packetNumberGenerator.Pop()
@@ -396,3 +512,36 @@ func (p *packetPacker) SetLeastUnacked(leastUnacked protocol.PacketNumber) {
 func (p *packetPacker) SetOmitConnectionID() {
 	p.omitConnectionID = true
 }
+
+// SetPath sets the path that the following packets are packed for.
+// Every path uses its own packet number space.
+func (p *packetPacker) SetPath(pathID protocol.PathID) {
+	if pathID == p.pathID {
+		return
+	}
+	png, ok := p.packetNumberGenerators[pathID]
+	if !ok {
+		png = newPacketNumberGenerator(1, protocol.SkipPacketAveragePeriodLength)
+		p.packetNumberGenerators[pathID] = png
+	}
+	p.pathID = pathID
+	p.packetNumberGenerator = png
+	// ACK and STOP_WAITING frames refer to the packet number space of the previous path
+	p.ackFrame = nil
+	p.stopWaiting = nil
+}
+
+// EnableFEC makes sure that forward-secure packets are small enough to be sent in a repair packet
+func (p *packetPacker) EnableFEC() {
+	p.fecEnabled = true
+}
+
+// SetMaxPacketSize sets the maximum size of the packets that are packed
+func (p *packetPacker) SetMaxPacketSize(size protocol.ByteCount) {
+	p.maxPacketSize = size
+}
+
+// MaxPacketSize returns the maximum size of the packets that are packed
+func (p *packetPacker) MaxPacketSize() protocol.ByteCount {
+	return p.maxPacketSize
+}
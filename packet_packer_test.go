@@ -6,6 +6,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/lucas-clemente/quic-go/internal/ackhandler"
+	"github.com/lucas-clemente/quic-go/internal/fec"
 	"github.com/lucas-clemente/quic-go/internal/flowcontrol"
 	"github.com/lucas-clemente/quic-go/internal/handshake"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
@@ -738,4 +739,144 @@ var _ = Describe("Packet packer", func() {
 			}))
 		})
 	})
+
+	Context("path MTU discovery", func() {
+		It("uses the maximum packet size", func() {
+			packer.SetMaxPacketSize(protocol.MaxPacketSize + 100)
+			mockStreamFramer.EXPECT().HasCryptoStreamData()
+			mockStreamFramer.EXPECT().PopStreamFrames(maxFrameSize + 100 + 2)
+			_, err := packer.PackPacket()
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("packs MTU probe packets", func() {
+			p, err := packer.PackMTUProbePacket(1400)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p.raw).To(HaveLen(1400))
+			Expect(p.frames).To(Equal([]wire.Frame{&wire.PingFrame{}}))
+			Expect(p.isMTUProbePacket).To(BeTrue())
+			Expect(p.encryptionLevel).To(Equal(protocol.EncryptionForwardSecure))
+			Expect(packer.MaxPacketSize()).To(Equal(protocol.MaxPacketSize))
+		})
+
+		It("refuses to pack MTU probe packets before the handshake completes", func() {
+			packer.cryptoSetup.(*mockCryptoSetup).encLevelSeal = protocol.EncryptionSecure
+			_, err := packer.PackMTUProbePacket(1400)
+			Expect(err).To(MatchError("PacketPacker BUG: MTU probe packets must be sent forward-secure"))
+		})
+	})
+
+	Context("FEC", func() {
+		It("packs repair packets", func() {
+			f := &wire.FECFrame{
+				PacketNumbers:    []protocol.PacketNumber{1, 2},
+				NumRepairSymbols: 1,
+				Data:             []byte("foobar"),
+			}
+			p, err := packer.PackRepairPacket(f)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p.frames).To(Equal([]wire.Frame{f}))
+			Expect(p.encryptionLevel).To(Equal(protocol.EncryptionForwardSecure))
+		})
+
+		It("refuses to pack repair packets before the handshake completes", func() {
+			packer.cryptoSetup.(*mockCryptoSetup).encLevelSeal = protocol.EncryptionSecure
+			_, err := packer.PackRepairPacket(&wire.FECFrame{})
+			Expect(err).To(MatchError("PacketPacker BUG: repair packets must be sent forward-secure"))
+		})
+
+		It("leaves space for sending a packet in a repair packet", func() {
+			packer.EnableFEC()
+			mockStreamFramer.EXPECT().HasCryptoStreamData()
+			mockStreamFramer.EXPECT().PopStreamFrames(maxFrameSize - protocol.FECPacketSizeReduction + 2)
+			_, err := packer.PackPacket()
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("fits a maximum size packet into a repair packet", func() {
+			packer.EnableFEC()
+			mockStreamFramer.EXPECT().HasCryptoStreamData()
+			mockStreamFramer.EXPECT().PopStreamFrames(gomock.Any()).DoAndReturn(func(maxLen protocol.ByteCount) []*wire.StreamFrame {
+				f := &wire.StreamFrame{StreamID: 5, DataLenPresent: true}
+				f.Data = bytes.Repeat([]byte{'f'}, int(maxLen-f.MinLength(packer.version)))
+				return []*wire.StreamFrame{f}
+			})
+			p, err := packer.PackPacket()
+			Expect(err).ToNot(HaveOccurred())
+			e := fec.NewEncoder(protocol.MaxFECDataPackets, 1)
+			var frames []*wire.FECFrame
+			for i := 0; i < protocol.MaxFECDataPackets; i++ {
+				frames = e.AddPacket(p.header.PacketNumber+protocol.PacketNumber(i), p.raw)
+			}
+			Expect(frames).To(HaveLen(1))
+			packer.packetNumberGenerator.next = 0xdecafbad // use a large packet number, to maximize the header length
+			repairPacket, err := packer.PackRepairPacket(frames[0])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(repairPacket.raw).ToNot(BeEmpty())
+		})
+	})
+
+	Context("multipath", func() {
+		It("uses a separate packet number space for every path", func() {
+			packer.packetNumberGenerator.next = 0x1000
+			packer.SetPath(1)
+			mockStreamFramer.EXPECT().HasCryptoStreamData()
+			mockStreamFramer.EXPECT().PopStreamFrames(gomock.Any()).Return([]*wire.StreamFrame{{StreamID: 5, Data: []byte("foobar")}})
+			p, err := packer.PackPacket()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p.header.PathID).To(Equal(protocol.PathID(1)))
+			Expect(p.header.PacketNumber).To(BeNumerically("<", 0x1000))
+			packer.SetPath(protocol.InitialPathID)
+			mockStreamFramer.EXPECT().HasCryptoStreamData()
+			mockStreamFramer.EXPECT().PopStreamFrames(gomock.Any()).Return([]*wire.StreamFrame{{StreamID: 5, Data: []byte("foobar")}})
+			p, err = packer.PackPacket()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p.header.PathID).To(Equal(protocol.InitialPathID))
+			Expect(p.header.PacketNumber).To(BeNumerically(">=", 0x1000))
+		})
+
+		It("doesn't send an ACK frame on a different path", func() {
+			packer.QueueControlFrame(&wire.AckFrame{LargestAcked: 10, LowestAcked: 1})
+			packer.SetPath(1)
+			mockStreamFramer.EXPECT().HasCryptoStreamData()
+			mockStreamFramer.EXPECT().PopStreamFrames(gomock.Any())
+			p, err := packer.PackPacket()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p).To(BeNil())
+		})
+
+		It("leaves space for a longer header", func() {
+			packer.SetPath(1)
+			packer.SetPath(protocol.InitialPathID)
+			mockStreamFramer.EXPECT().HasCryptoStreamData()
+			// 1 byte for the path ID, and 2 bytes for a longer packet number
+			mockStreamFramer.EXPECT().PopStreamFrames(maxFrameSize - 3 + 2)
+			_, err := packer.PackPacket()
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("packs redundant packets", func() {
+			packer.SetPath(1)
+			sf := &wire.StreamFrame{StreamID: 5, Data: []byte("foobar")}
+			frames := []wire.Frame{&wire.AckFrame{LargestAcked: 10, LowestAcked: 1}, sf, &wire.PingFrame{}}
+			p, err := packer.PackRedundantPacket(frames)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p.header.PathID).To(Equal(protocol.PathID(1)))
+			Expect(p.frames).To(Equal([]wire.Frame{sf, &wire.PingFrame{}}))
+			Expect(p.frames[0]).ToNot(BeIdenticalTo(sf))
+			Expect(p.encryptionLevel).To(Equal(protocol.EncryptionForwardSecure))
+		})
+
+		It("doesn't pack redundant packets without retransmittable frames", func() {
+			p, err := packer.PackRedundantPacket([]wire.Frame{&wire.AckFrame{LargestAcked: 10, LowestAcked: 1}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p).To(BeNil())
+		})
+
+		It("refuses to pack redundant packets before the handshake completes", func() {
+			packer.cryptoSetup.(*mockCryptoSetup).encLevelSeal = protocol.EncryptionSecure
+			_, err := packer.PackRedundantPacket([]wire.Frame{&wire.PingFrame{}})
+			Expect(err).To(MatchError("PacketPacker BUG: redundant packets must be sent forward-secure"))
+		})
+	})
 })
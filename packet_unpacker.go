@@ -21,23 +21,37 @@ type quicAEAD interface {
 type packetUnpacker struct {
 	version protocol.VersionNumber
 	aead    quicAEAD
+
+	// The unpackedPacket, the frames it contains and the reader are reused for every packet, to avoid allocations.
+	// The unpackedPacket returned by Unpack is only valid until the next call to Unpack.
+	packet          unpackedPacket
+	reader          bytes.Reader
+	streamFrames    []*wire.StreamFrame
+	numStreamFrames int
+	ackFrames       []*wire.AckFrame
+	numAckFrames    int
 }
 
+// Unpack decrypts a packet and parses its frames.
+// The returned unpackedPacket and its frames are reused by the next call to Unpack.
 func (u *packetUnpacker) Unpack(headerBinary []byte, hdr *wire.Header, data []byte) (*unpackedPacket, error) {
-	buf := getPacketBuffer()
+	buf := getPacketBuffer(protocol.ByteCount(len(data)))
 	defer putPacketBuffer(buf)
-	decrypted, encryptionLevel, err := u.aead.Open(buf, data, hdr.PacketNumber, headerBinary)
+	decrypted, encryptionLevel, err := u.aead.Open(buf, data, protocol.NoncePacketNumber(hdr.PathID, hdr.PacketNumber), headerBinary)
 	if err != nil {
 		// Wrap err in quicError so that public reset is sent by session
 		return nil, qerr.Error(qerr.DecryptionFailure, err.Error())
 	}
-	r := bytes.NewReader(decrypted)
+	r := &u.reader
+	r.Reset(decrypted)
 
 	if r.Len() == 0 {
 		return nil, qerr.MissingPayload
 	}
 
-	fs := make([]wire.Frame, 0, 2)
+	fs := u.packet.frames[:0]
+	u.numStreamFrames = 0
+	u.numAckFrames = 0
 
 	// Read all frames in the packet
 	for r.Len() > 0 {
@@ -61,10 +75,45 @@ func (u *packetUnpacker) Unpack(headerBinary []byte, hdr *wire.Header, data []by
 		}
 	}
 
-	return &unpackedPacket{
-		encryptionLevel: encryptionLevel,
-		frames:          fs,
-	}, nil
+	u.packet.encryptionLevel = encryptionLevel
+	u.packet.frames = fs
+	return &u.packet, nil
+}
+
+// nextStreamFrame returns a StreamFrame that a STREAM frame of the current packet can be parsed into
+func (u *packetUnpacker) nextStreamFrame() *wire.StreamFrame {
+	if u.numStreamFrames == len(u.streamFrames) {
+		u.streamFrames = append(u.streamFrames, &wire.StreamFrame{})
+	}
+	frame := u.streamFrames[u.numStreamFrames]
+	u.numStreamFrames++
+	return frame
+}
+
+// nextAckFrame returns an AckFrame that an ACK frame of the current packet can be parsed into
+func (u *packetUnpacker) nextAckFrame() *wire.AckFrame {
+	if u.numAckFrames == len(u.ackFrames) {
+		u.ackFrames = append(u.ackFrames, &wire.AckFrame{})
+	}
+	frame := u.ackFrames[u.numAckFrames]
+	u.numAckFrames++
+	return frame
+}
+
+func (u *packetUnpacker) parseStreamFrame(r *bytes.Reader) (wire.Frame, error) {
+	frame := u.nextStreamFrame()
+	if err := wire.ParseStreamFrameInto(r, frame, u.version); err != nil {
+		return nil, qerr.Error(qerr.InvalidStreamData, err.Error())
+	}
+	return frame, nil
+}
+
+func (u *packetUnpacker) parseAckFrame(r *bytes.Reader) (wire.Frame, error) {
+	frame := u.nextAckFrame()
+	if err := wire.ParseAckFrameInto(r, frame, u.version); err != nil {
+		return nil, qerr.Error(qerr.InvalidAckData, err.Error())
+	}
+	return frame, nil
 }
 
 func (u *packetUnpacker) parseFrame(r *bytes.Reader, typeByte byte, hdr *wire.Header) (wire.Frame, error) {
@@ -78,11 +127,7 @@ func (u *packetUnpacker) parseIETFFrame(r *bytes.Reader, typeByte byte, hdr *wir
 	var frame wire.Frame
 	var err error
 	if typeByte&0xf8 == 0x10 {
-		frame, err = wire.ParseStreamFrame(r, u.version)
-		if err != nil {
-			err = qerr.Error(qerr.InvalidStreamData, err.Error())
-		}
-		return frame, err
+		return u.parseStreamFrame(r)
 	}
 	// TODO: implement all IETF QUIC frame types
 	switch typeByte {
@@ -133,10 +178,22 @@ func (u *packetUnpacker) parseIETFFrame(r *bytes.Reader, typeByte byte, hdr *wir
 		if err != nil {
 			err = qerr.Error(qerr.InvalidFrameData, err.Error())
 		}
-	case 0xe:
-		frame, err = wire.ParseAckFrame(r, u.version)
+	case 0xe, 0x1a:
+		return u.parseAckFrame(r)
+	case 0x18:
+		frame, err = wire.ParseExpiredStreamDataFrame(r, u.version)
 		if err != nil {
-			err = qerr.Error(qerr.InvalidAckData, err.Error())
+			err = qerr.Error(qerr.InvalidFrameData, err.Error())
+		}
+	case 0x19:
+		frame, err = wire.ParseFECFrame(r, u.version)
+		if err != nil {
+			err = qerr.Error(qerr.InvalidFrameData, err.Error())
+		}
+	case 0x1b:
+		frame, err = wire.ParseAckFrequencyFrame(r, u.version)
+		if err != nil {
+			err = qerr.Error(qerr.InvalidFrameData, err.Error())
 		}
 	default:
 		err = qerr.Error(qerr.InvalidFrameData, fmt.Sprintf("unknown type byte 0x%x", typeByte))
@@ -148,17 +205,9 @@ func (u *packetUnpacker) parseGQUICFrame(r *bytes.Reader, typeByte byte, hdr *wi
 	var frame wire.Frame
 	var err error
 	if typeByte&0x80 == 0x80 {
-		frame, err = wire.ParseStreamFrame(r, u.version)
-		if err != nil {
-			err = qerr.Error(qerr.InvalidStreamData, err.Error())
-		}
-		return frame, err
+		return u.parseStreamFrame(r)
 	} else if typeByte&0xc0 == 0x40 {
-		frame, err = wire.ParseAckFrame(r, u.version)
-		if err != nil {
-			err = qerr.Error(qerr.InvalidAckData, err.Error())
-		}
-		return frame, err
+		return u.parseAckFrame(r)
 	}
 	switch typeByte {
 	case 0x1:
@@ -193,6 +242,21 @@ func (u *packetUnpacker) parseGQUICFrame(r *bytes.Reader, typeByte byte, hdr *wi
 		}
 	case 0x7:
 		frame, err = wire.ParsePingFrame(r, u.version)
+	case 0x18:
+		frame, err = wire.ParseExpiredStreamDataFrame(r, u.version)
+		if err != nil {
+			err = qerr.Error(qerr.InvalidFrameData, err.Error())
+		}
+	case 0x19:
+		frame, err = wire.ParseFECFrame(r, u.version)
+		if err != nil {
+			err = qerr.Error(qerr.InvalidFrameData, err.Error())
+		}
+	case 0x1b:
+		frame, err = wire.ParseAckFrequencyFrame(r, u.version)
+		if err != nil {
+			err = qerr.Error(qerr.InvalidFrameData, err.Error())
+		}
 	default:
 		err = qerr.Error(qerr.InvalidFrameData, fmt.Sprintf("unknown type byte 0x%x", typeByte))
 	}
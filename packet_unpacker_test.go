@@ -2,6 +2,7 @@ package quic
 
 import (
 	"bytes"
+	"time"
 
 	"github.com/lucas-clemente/quic-go/internal/crypto"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
@@ -203,6 +204,52 @@ var _ = Describe("Packet unpacker", func() {
 			}))
 		})
 
+		It("unpacks EXPIRED_STREAM_DATA frames", func() {
+			f := &wire.ExpiredStreamDataFrame{
+				StreamID:      0xdeadbeef,
+				Offset:        0x1337,
+				MinimumOffset: 0x4200,
+			}
+			buf := &bytes.Buffer{}
+			err := f.Write(buf, versionGQUICFrames)
+			Expect(err).ToNot(HaveOccurred())
+			setData(buf.Bytes())
+			packet, err := unpacker.Unpack(hdrBin, hdr, data)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(packet.frames).To(Equal([]wire.Frame{f}))
+		})
+
+		It("unpacks ACK_FREQUENCY frames", func() {
+			f := &wire.AckFrequencyFrame{
+				SequenceNumber:   0x42,
+				PacketThreshold:  20,
+				MaxAckDelay:      50 * time.Millisecond,
+				IgnoreReordering: true,
+			}
+			buf := &bytes.Buffer{}
+			err := f.Write(buf, versionGQUICFrames)
+			Expect(err).ToNot(HaveOccurred())
+			setData(buf.Bytes())
+			packet, err := unpacker.Unpack(hdrBin, hdr, data)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(packet.frames).To(Equal([]wire.Frame{f}))
+		})
+
+		It("unpacks FEC frames", func() {
+			f := &wire.FECFrame{
+				PacketNumbers:    []protocol.PacketNumber{0x1337, 0x1338},
+				NumRepairSymbols: 1,
+				Data:             []byte("foobar"),
+			}
+			buf := &bytes.Buffer{}
+			err := f.Write(buf, versionGQUICFrames)
+			Expect(err).ToNot(HaveOccurred())
+			setData(buf.Bytes())
+			packet, err := unpacker.Unpack(hdrBin, hdr, data)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(packet.frames).To(Equal([]wire.Frame{f}))
+		})
+
 		It("errors on invalid type", func() {
 			setData([]byte{0xf})
 			_, err := unpacker.Unpack(hdrBin, hdr, data)
@@ -219,6 +266,9 @@ var _ = Describe("Packet unpacker", func() {
 				0x04: qerr.InvalidWindowUpdateData,
 				0x05: qerr.InvalidBlockedData,
 				0x06: qerr.InvalidStopWaitingData,
+				0x18: qerr.InvalidFrameData,
+				0x19: qerr.InvalidFrameData,
+				0x1b: qerr.InvalidFrameData,
 			} {
 				setData([]byte{b})
 				_, err := unpacker.Unpack(hdrBin, hdr, data)
@@ -242,6 +292,27 @@ var _ = Describe("Packet unpacker", func() {
 			Expect(readFrame.LargestAcked).To(Equal(protocol.PacketNumber(0x13)))
 		})
 
+		It("reuses the frames for the next packet", func() {
+			unpacker.aead.(*mockAEAD).encLevelOpen = protocol.EncryptionForwardSecure
+			f1 := &wire.StreamFrame{StreamID: 3, Data: []byte("foo"), DataLenPresent: true}
+			f2 := &wire.StreamFrame{StreamID: 5, Data: []byte("bar")}
+			Expect(f1.Write(buf, versionGQUICFrames)).To(Succeed())
+			Expect(f2.Write(buf, versionGQUICFrames)).To(Succeed())
+			setData(buf.Bytes())
+			packet, err := unpacker.Unpack(hdrBin, hdr, data)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(packet.frames).To(Equal([]wire.Frame{f1, f2}))
+			firstFrame := packet.frames[0]
+			// unpack a packet containing only the second frame
+			buf.Reset()
+			Expect(f2.Write(buf, versionGQUICFrames)).To(Succeed())
+			setData(buf.Bytes())
+			packet, err = unpacker.Unpack(hdrBin, hdr, data)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(packet.frames).To(Equal([]wire.Frame{f2}))
+			Expect(packet.frames[0]).To(BeIdenticalTo(firstFrame))
+		})
+
 		Context("unpacking STREAM frames", func() {
 			It("unpacks unencrypted STREAM frames on the crypto stream", func() {
 				unpacker.aead.(*mockAEAD).encLevelOpen = protocol.EncryptionUnencrypted
@@ -400,6 +471,52 @@ var _ = Describe("Packet unpacker", func() {
 			Expect(packet.frames).To(Equal([]wire.Frame{f}))
 		})
 
+		It("unpacks EXPIRED_STREAM_DATA frames", func() {
+			f := &wire.ExpiredStreamDataFrame{
+				StreamID:      0x42,
+				Offset:        0x1337,
+				MinimumOffset: 0x4200,
+			}
+			buf := &bytes.Buffer{}
+			err := f.Write(buf, versionIETFFrames)
+			Expect(err).ToNot(HaveOccurred())
+			setData(buf.Bytes())
+			packet, err := unpacker.Unpack(hdrBin, hdr, data)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(packet.frames).To(Equal([]wire.Frame{f}))
+		})
+
+		It("unpacks ACK_FREQUENCY frames", func() {
+			f := &wire.AckFrequencyFrame{
+				SequenceNumber:   0x42,
+				PacketThreshold:  20,
+				MaxAckDelay:      50 * time.Millisecond,
+				IgnoreReordering: true,
+			}
+			buf := &bytes.Buffer{}
+			err := f.Write(buf, versionIETFFrames)
+			Expect(err).ToNot(HaveOccurred())
+			setData(buf.Bytes())
+			packet, err := unpacker.Unpack(hdrBin, hdr, data)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(packet.frames).To(Equal([]wire.Frame{f}))
+		})
+
+		It("unpacks FEC frames", func() {
+			f := &wire.FECFrame{
+				PacketNumbers:    []protocol.PacketNumber{0x1337, 0x1338},
+				NumRepairSymbols: 1,
+				Data:             []byte("foobar"),
+			}
+			buf := &bytes.Buffer{}
+			err := f.Write(buf, versionIETFFrames)
+			Expect(err).ToNot(HaveOccurred())
+			setData(buf.Bytes())
+			packet, err := unpacker.Unpack(hdrBin, hdr, data)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(packet.frames).To(Equal([]wire.Frame{f}))
+		})
+
 		It("unpacks ACK frames", func() {
 			f := &wire.AckFrame{
 				LargestAcked: 0x13,
@@ -416,6 +533,22 @@ var _ = Describe("Packet unpacker", func() {
 			Expect(readFrame.LargestAcked).To(Equal(protocol.PacketNumber(0x13)))
 		})
 
+		It("unpacks ACK frames with ECN counts", func() {
+			f := &wire.AckFrame{
+				LargestAcked: 0x13,
+				LowestAcked:  1,
+				ECN:          &wire.ECNCounts{ECT0: 10, ECT1: 2, CE: 3},
+			}
+			err := f.Write(buf, versionIETFFrames)
+			Expect(err).ToNot(HaveOccurred())
+			setData(buf.Bytes())
+			packet, err := unpacker.Unpack(hdrBin, hdr, data)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(packet.frames).To(HaveLen(1))
+			readFrame := packet.frames[0].(*wire.AckFrame)
+			Expect(readFrame.ECN).To(Equal(f.ECN))
+		})
+
 		It("errors on invalid type", func() {
 			setData([]byte{0xf})
 			_, err := unpacker.Unpack(hdrBin, hdr, data)
@@ -435,6 +568,9 @@ var _ = Describe("Packet unpacker", func() {
 				0x0c: qerr.InvalidFrameData,
 				0x0e: qerr.InvalidAckData,
 				0x10: qerr.InvalidStreamData,
+				0x18: qerr.InvalidFrameData,
+				0x19: qerr.InvalidFrameData,
+				0x1b: qerr.InvalidFrameData,
 			} {
 				setData([]byte{b})
 				_, err := unpacker.Unpack(hdrBin, hdr, data)
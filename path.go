@@ -0,0 +1,96 @@
+package quic
+
+import (
+	"sort"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/ackhandler"
+	"github.com/lucas-clemente/quic-go/internal/congestion"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/wire"
+)
+
+// A path is one of the paths of a multipath connection.
+// Every path has its own packet number space, RTT estimate, congestion controller and loss detection.
+type path struct {
+	id   protocol.PathID
+	conn connection
+
+	rttStats              *congestion.RTTStats
+	sentPacketHandler     ackhandler.SentPacketHandler
+	receivedPacketHandler ackhandler.ReceivedPacketHandler
+
+	lastRcvdPacketNumber protocol.PacketNumber
+	// Used to calculate the next packet number from the truncated wire representation
+	largestRcvdPacketNumber protocol.PacketNumber
+}
+
+// newPath creates a new path.
+// Paths are only added after the handshake completed.
+func newPath(
+	id protocol.PathID,
+	conn connection,
+	config *Config,
+	version protocol.VersionNumber,
+) *path {
+	rttStats := &congestion.RTTStats{}
+	sentPacketHandler := ackhandler.NewSentPacketHandler(
+		rttStats,
+		config.CongestionControl(conn.RemoteAddr(), rttStats),
+		protocol.ByteCount(config.InitialPacingBurst)*protocol.DefaultTCPMSS,
+	)
+	sentPacketHandler.SetHandshakeComplete()
+	if version.UsesIETFFrameFormat() {
+		sentPacketHandler.EnableECN()
+	}
+	return &path{
+		id:                    id,
+		conn:                  conn,
+		rttStats:              rttStats,
+		sentPacketHandler:     sentPacketHandler,
+		receivedPacketHandler: ackhandler.NewReceivedPacketHandler(version),
+	}
+}
+
+func (p *path) handleAckFrame(frame *wire.AckFrame, encLevel protocol.EncryptionLevel, rcvTime time.Time) error {
+	if err := p.sentPacketHandler.ReceivedAck(frame, p.lastRcvdPacketNumber, encLevel, rcvTime); err != nil {
+		return err
+	}
+	p.receivedPacketHandler.IgnoreBelow(p.sentPacketHandler.GetLowestPacketNotConfirmedAcked())
+	return nil
+}
+
+// canSend says if a packet can be sent on this path now, i.e. if it is neither congestion limited nor paced
+func (p *path) canSend(now time.Time) bool {
+	return p.sentPacketHandler.SendingAllowed() && !p.sentPacketHandler.TimeUntilSend().After(now)
+}
+
+// hasLowerRTT says if p has a lower RTT than other.
+// Paths without an RTT estimate are considered slower than all paths that have one.
+func (p *path) hasLowerRTT(other *path) bool {
+	rtt := p.rttStats.SmoothedRTT()
+	otherRTT := other.rttStats.SmoothedRTT()
+	if rtt == 0 || otherRTT == 0 {
+		return rtt != 0 && otherRTT == 0
+	}
+	return rtt < otherRTT
+}
+
+// schedulePaths returns the paths that the next packet should be sent on, or nil if no path can be used right now.
+// The packet is packed for the first path. With the redundant scheduler, copies of it are sent on the other paths.
+func schedulePaths(scheduler PathScheduler, paths []*path, now time.Time) []*path {
+	var available []*path
+	for _, p := range paths {
+		if p.canSend(now) {
+			available = append(available, p)
+		}
+	}
+	if len(available) == 0 {
+		return nil
+	}
+	sort.SliceStable(available, func(i, j int) bool { return available[i].hasLowerRTT(available[j]) })
+	if scheduler == SchedulerRedundant {
+		return available
+	}
+	return available[:1]
+}
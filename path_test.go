@@ -0,0 +1,84 @@
+package quic
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/congestion"
+	"github.com/lucas-clemente/quic-go/internal/mocks/ackhandler"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Path", func() {
+	var now time.Time
+
+	newMockPath := func(id protocol.PathID, rtt time.Duration, sendingAllowed bool, timeUntilSend time.Time) *path {
+		sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+		sph.EXPECT().SendingAllowed().Return(sendingAllowed).AnyTimes()
+		sph.EXPECT().TimeUntilSend().Return(timeUntilSend).AnyTimes()
+		rttStats := &congestion.RTTStats{}
+		if rtt > 0 {
+			rttStats.UpdateRTT(rtt, 0, now)
+		}
+		return &path{
+			id:                id,
+			rttStats:          rttStats,
+			sentPacketHandler: sph,
+		}
+	}
+
+	BeforeEach(func() {
+		now = time.Now()
+	})
+
+	It("creates new paths", func() {
+		p := newPath(3, newMockConnection(), populateClientConfig(&Config{}), versionGQUICFrames)
+		Expect(p.id).To(Equal(protocol.PathID(3)))
+		Expect(p.sentPacketHandler).ToNot(BeNil())
+		Expect(p.receivedPacketHandler).ToNot(BeNil())
+		Expect(p.rttStats.SmoothedRTT()).To(BeZero())
+	})
+
+	Context("scheduling", func() {
+		It("chooses the path with the lowest RTT", func() {
+			p1 := newMockPath(0, 20*time.Millisecond, true, time.Time{})
+			p2 := newMockPath(1, 10*time.Millisecond, true, time.Time{})
+			p3 := newMockPath(2, 30*time.Millisecond, true, time.Time{})
+			Expect(schedulePaths(SchedulerLowestRTT, []*path{p1, p2, p3}, now)).To(Equal([]*path{p2}))
+		})
+
+		It("prefers paths with an RTT estimate", func() {
+			p1 := newMockPath(0, 0, true, time.Time{})
+			p2 := newMockPath(1, 50*time.Millisecond, true, time.Time{})
+			Expect(schedulePaths(SchedulerLowestRTT, []*path{p1, p2}, now)).To(Equal([]*path{p2}))
+		})
+
+		It("skips congestion limited paths", func() {
+			p1 := newMockPath(0, 10*time.Millisecond, false, time.Time{})
+			p2 := newMockPath(1, 20*time.Millisecond, true, time.Time{})
+			Expect(schedulePaths(SchedulerLowestRTT, []*path{p1, p2}, now)).To(Equal([]*path{p2}))
+		})
+
+		It("skips paced paths", func() {
+			p1 := newMockPath(0, 10*time.Millisecond, true, now.Add(time.Millisecond))
+			p2 := newMockPath(1, 20*time.Millisecond, true, now)
+			Expect(schedulePaths(SchedulerLowestRTT, []*path{p1, p2}, now)).To(Equal([]*path{p2}))
+		})
+
+		It("returns nil if no path can be used", func() {
+			p1 := newMockPath(0, 10*time.Millisecond, false, time.Time{})
+			p2 := newMockPath(1, 20*time.Millisecond, true, now.Add(time.Millisecond))
+			Expect(schedulePaths(SchedulerLowestRTT, []*path{p1, p2}, now)).To(BeNil())
+		})
+
+		It("returns all available paths for the redundant scheduler, sorted by RTT", func() {
+			p1 := newMockPath(0, 30*time.Millisecond, true, time.Time{})
+			p2 := newMockPath(1, 10*time.Millisecond, true, time.Time{})
+			p3 := newMockPath(2, 5*time.Millisecond, false, time.Time{})
+			p4 := newMockPath(3, 20*time.Millisecond, true, time.Time{})
+			Expect(schedulePaths(SchedulerRedundant, []*path{p1, p2, p3, p4}, now)).To(Equal([]*path{p2, p4, p1}))
+		})
+	})
+})
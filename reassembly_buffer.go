@@ -0,0 +1,216 @@
+package quic
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+	"github.com/lucas-clemente/quic-go/internal/wire"
+)
+
+// The reassemblyBuffer holds the data received on a stream until it is read.
+// STREAM frames may arrive in any order and overlap arbitrarily: only the parts of a frame that weren't received before are buffered.
+// Data that arrives directly behind buffered data is appended to it, so that data received in order is read from a single slice.
+type reassemblyBuffer struct {
+	chunks []reassemblyChunk // data that was not read yet, sorted by offset, non-overlapping
+	// received are the ranges above the readPosition that were received.
+	// This includes data that was already read in out-of-order mode, and data that was discarded,
+	// such that retransmissions of this data are not buffered again.
+	received []utils.ByteInterval
+
+	readPosition  protocol.ByteCount // all data below this offset was read or discarded
+	highestOffset protocol.ByteCount // the highest offset received
+	dataLen       protocol.ByteCount // the number of bytes held in chunks
+}
+
+type reassemblyChunk struct {
+	offset protocol.ByteCount
+	data   []byte
+}
+
+// The memory used for bookkeeping, per chunk and per received range.
+const (
+	reassemblyChunkOverhead    protocol.ByteCount = 40
+	reassemblyIntervalOverhead protocol.ByteCount = 16
+)
+
+var (
+	errReassemblyBufferFull = errors.New("Too much memory used for buffering received stream data")
+	errEmptyStreamData      = errors.New("Stream Data empty")
+)
+
+func newReassemblyBuffer() *reassemblyBuffer {
+	return &reassemblyBuffer{}
+}
+
+// Push buffers the data of a STREAM frame.
+// The data is copied, since the frames are owned by the caller (the packet unpacker reuses them for the next packet).
+// Data that was already received is ignored.
+func (b *reassemblyBuffer) Push(frame *wire.StreamFrame) error {
+	if frame.DataLen() == 0 {
+		if frame.FinBit {
+			return nil
+		}
+		return errEmptyStreamData
+	}
+	start := utils.MaxByteCount(frame.Offset, b.readPosition)
+	end := frame.Offset + frame.DataLen()
+	if start >= end {
+		return nil
+	}
+	b.highestOffset = utils.MaxByteCount(b.highestOffset, end)
+
+	// buffer the parts of the frame that fall into the gaps between the received ranges
+	pos := start
+	for i := b.searchReceived(start); i < len(b.received) && b.received[i].Start < end; i++ {
+		r := b.received[i]
+		if r.Start > pos {
+			b.insertData(pos, frame.Data[pos-frame.Offset:r.Start-frame.Offset])
+		}
+		pos = utils.MaxByteCount(pos, r.End)
+	}
+	if pos < end {
+		b.insertData(pos, frame.Data[pos-frame.Offset:])
+	}
+	b.received = addByteInterval(b.received, utils.ByteInterval{Start: start, End: end})
+
+	// Flow control limits the range of offsets that data can be received for.
+	// Only allow the bookkeeping for many small chunks to use a limited amount of memory on top of that.
+	span := utils.MaxByteCount(b.highestOffset, b.readPosition) - b.readPosition
+	if b.memoryUsed() > span+protocol.MaxStreamReassemblyBufferOverhead {
+		return errReassemblyBufferFull
+	}
+	return nil
+}
+
+// searchReceived returns the index of the first received range that ends after offset
+func (b *reassemblyBuffer) searchReceived(offset protocol.ByteCount) int {
+	return sort.Search(len(b.received), func(i int) bool { return b.received[i].End > offset })
+}
+
+// insertData buffers a copy of data at offset.
+// The range must not overlap with any buffered data.
+func (b *reassemblyBuffer) insertData(offset protocol.ByteCount, data []byte) {
+	b.dataLen += protocol.ByteCount(len(data))
+	i := sort.Search(len(b.chunks), func(i int) bool { return b.chunks[i].offset > offset })
+	if i > 0 {
+		prev := &b.chunks[i-1]
+		if prev.offset+protocol.ByteCount(len(prev.data)) == offset {
+			prev.data = append(prev.data, data...)
+			return
+		}
+	}
+	c := reassemblyChunk{offset: offset, data: make([]byte, len(data))}
+	copy(c.data, data)
+	b.chunks = append(b.chunks, reassemblyChunk{})
+	copy(b.chunks[i+1:], b.chunks[i:])
+	b.chunks[i] = c
+}
+
+func (b *reassemblyBuffer) memoryUsed() protocol.ByteCount {
+	return b.dataLen +
+		protocol.ByteCount(len(b.chunks))*reassemblyChunkOverhead +
+		protocol.ByteCount(len(b.received))*reassemblyIntervalOverhead
+}
+
+// HasData says if there's data that can be read at the readPosition
+func (b *reassemblyBuffer) HasData() bool {
+	return len(b.chunks) > 0 && b.chunks[0].offset == b.readPosition
+}
+
+// Read reads the data at the readPosition into p, until p is full or a gap is reached.
+// It returns the number of bytes read.
+func (b *reassemblyBuffer) Read(p []byte) int {
+	var n int
+	for n < len(p) && b.HasData() {
+		c := &b.chunks[0]
+		m := copy(p[n:], c.data)
+		n += m
+		b.readPosition += protocol.ByteCount(m)
+		b.dataLen -= protocol.ByteCount(m)
+		if m < len(c.data) {
+			c.offset += protocol.ByteCount(m)
+			c.data = c.data[m:]
+			break
+		}
+		b.popChunk()
+	}
+	b.advanceReadPosition()
+	return n
+}
+
+// PopUnordered removes and returns the buffered chunk with the lowest offset, even if there's a gap in front of it.
+// The returned slice is owned by the caller.
+// Retransmissions of the data are ignored after it was popped.
+func (b *reassemblyBuffer) PopUnordered() (protocol.ByteCount, []byte, bool) {
+	if len(b.chunks) == 0 {
+		return 0, nil, false
+	}
+	c := b.chunks[0]
+	b.dataLen -= protocol.ByteCount(len(c.data))
+	b.popChunk()
+	b.advanceReadPosition()
+	// make sure that later appends to a chunk don't write into the slice returned
+	return c.offset, c.data[:len(c.data):len(c.data)], true
+}
+
+func (b *reassemblyBuffer) popChunk() {
+	b.chunks[0] = reassemblyChunk{}
+	b.chunks = b.chunks[1:]
+}
+
+// advanceReadPosition moves the readPosition past data that was read out of order or discarded,
+// and removes the received ranges below the readPosition.
+func (b *reassemblyBuffer) advanceReadPosition() {
+	for len(b.received) > 0 && b.received[0].Start <= b.readPosition {
+		r := b.received[0]
+		if len(b.chunks) > 0 && b.chunks[0].offset < r.End {
+			b.readPosition = utils.MaxByteCount(b.readPosition, b.chunks[0].offset)
+			b.received[0].Start = b.readPosition
+			return
+		}
+		b.readPosition = utils.MaxByteCount(b.readPosition, r.End)
+		b.received = b.received[1:]
+	}
+}
+
+// Discard drops all data in the range [start, end), no matter if it was already received or not.
+// Data in this range that is received later is ignored.
+// It returns the number of bytes that were discarded, i.e. all bytes in the range that were not read yet.
+func (b *reassemblyBuffer) Discard(start, end protocol.ByteCount) protocol.ByteCount {
+	start = utils.MaxByteCount(start, b.readPosition)
+	if end <= start {
+		return 0
+	}
+	discarded := end - start
+	// don't count data that was already read out of order
+	for i := b.searchReceived(start); i < len(b.received) && b.received[i].Start < end; i++ {
+		r := b.received[i]
+		discarded -= utils.MinByteCount(r.End, end) - utils.MaxByteCount(r.Start, start)
+	}
+	// drop the buffered data
+	var chunks []reassemblyChunk
+	for _, c := range b.chunks {
+		cEnd := c.offset + protocol.ByteCount(len(c.data))
+		if cEnd <= start || c.offset >= end {
+			chunks = append(chunks, c)
+			continue
+		}
+		dropped := utils.MinByteCount(cEnd, end) - utils.MaxByteCount(c.offset, start)
+		discarded += dropped
+		b.dataLen -= dropped
+		if c.offset < start {
+			l := start - c.offset
+			chunks = append(chunks, reassemblyChunk{offset: c.offset, data: c.data[:l:l]})
+		}
+		if cEnd > end {
+			chunks = append(chunks, reassemblyChunk{offset: end, data: c.data[end-c.offset:]})
+		}
+	}
+	b.chunks = chunks
+	b.received = addByteInterval(b.received, utils.ByteInterval{Start: start, End: end})
+	b.highestOffset = utils.MaxByteCount(b.highestOffset, end)
+	b.advanceReadPosition()
+	return discarded
+}
@@ -0,0 +1,271 @@
+package quic
+
+import (
+	"bytes"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+	"github.com/lucas-clemente/quic-go/internal/wire"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reassembly buffer", func() {
+	var b *reassemblyBuffer
+
+	BeforeEach(func() {
+		b = newReassemblyBuffer()
+	})
+
+	push := func(offset protocol.ByteCount, data string) {
+		ExpectWithOffset(1, b.Push(&wire.StreamFrame{Offset: offset, Data: []byte(data)})).To(Succeed())
+	}
+
+	readAll := func() string {
+		p := make([]byte, 1000)
+		n := b.Read(p)
+		return string(p[:n])
+	}
+
+	checkChunks := func(expected map[protocol.ByteCount]string) {
+		chunks := make(map[protocol.ByteCount]string)
+		var dataLen protocol.ByteCount
+		for _, c := range b.chunks {
+			chunks[c.offset] = string(c.data)
+			dataLen += protocol.ByteCount(len(c.data))
+		}
+		ExpectWithOffset(1, chunks).To(Equal(expected))
+		ExpectWithOffset(1, b.dataLen).To(Equal(dataLen))
+	}
+
+	Context("reading in order", func() {
+		It("reads data", func() {
+			push(0, "foobar")
+			Expect(b.HasData()).To(BeTrue())
+			Expect(readAll()).To(Equal("foobar"))
+			Expect(b.HasData()).To(BeFalse())
+			Expect(b.readPosition).To(Equal(protocol.ByteCount(6)))
+			Expect(b.chunks).To(BeEmpty())
+			Expect(b.received).To(BeEmpty())
+		})
+
+		It("appends data that arrives in order to the buffered data", func() {
+			push(0, "foo")
+			push(3, "bar")
+			push(6, "baz")
+			checkChunks(map[protocol.ByteCount]string{0: "foobarbaz"})
+			Expect(readAll()).To(Equal("foobarbaz"))
+		})
+
+		It("stores a copy of the data, since the caller reuses the frame", func() {
+			f := &wire.StreamFrame{Data: []byte("foobar")}
+			Expect(b.Push(f)).To(Succeed())
+			copy(f.Data, "raboof")
+			Expect(readAll()).To(Equal("foobar"))
+		})
+
+		It("reads partially", func() {
+			push(0, "foobar")
+			p := make([]byte, 4)
+			Expect(b.Read(p)).To(Equal(4))
+			Expect(p).To(Equal([]byte("foob")))
+			Expect(b.readPosition).To(Equal(protocol.ByteCount(4)))
+			Expect(readAll()).To(Equal("ar"))
+		})
+
+		It("reads across chunks", func() {
+			push(3, "bar")
+			push(0, "foo")
+			checkChunks(map[protocol.ByteCount]string{0: "foo", 3: "bar"})
+			Expect(readAll()).To(Equal("foobar"))
+		})
+
+		It("stops reading at a gap", func() {
+			push(0, "foo")
+			push(6, "baz")
+			Expect(readAll()).To(Equal("foo"))
+			Expect(b.HasData()).To(BeFalse())
+			Expect(readAll()).To(BeEmpty())
+			push(3, "bar")
+			Expect(readAll()).To(Equal("barbaz"))
+		})
+
+		It("rejects empty frames", func() {
+			Expect(b.Push(&wire.StreamFrame{Offset: 3})).To(MatchError(errEmptyStreamData))
+		})
+
+		It("accepts frames that only have the FIN bit set", func() {
+			Expect(b.Push(&wire.StreamFrame{Offset: 3, FinBit: true})).To(Succeed())
+			Expect(b.HasData()).To(BeFalse())
+		})
+	})
+
+	Context("overlapping data", func() {
+		It("ignores duplicate data", func() {
+			push(0, "foobar")
+			push(0, "foobar")
+			push(2, "ob")
+			checkChunks(map[protocol.ByteCount]string{0: "foobar"})
+		})
+
+		It("ignores data that was already read", func() {
+			push(0, "foobar")
+			Expect(readAll()).To(Equal("foobar"))
+			push(0, "foobar")
+			push(3, "bar")
+			Expect(b.HasData()).To(BeFalse())
+			Expect(b.chunks).To(BeEmpty())
+		})
+
+		It("buffers the new data of a frame that overlaps with data that was read", func() {
+			push(0, "foo")
+			Expect(readAll()).To(Equal("foo"))
+			push(0, "foobar")
+			Expect(readAll()).To(Equal("bar"))
+		})
+
+		It("buffers the new data at the beginning and at the end of a frame", func() {
+			push(2, "ob")
+			push(0, "foobar")
+			checkChunks(map[protocol.ByteCount]string{0: "fo", 2: "obar"})
+			Expect(readAll()).To(Equal("foobar"))
+		})
+
+		It("fills multiple gaps with one frame", func() {
+			push(1, "o")
+			push(4, "a")
+			push(8, "z")
+			push(0, "foobarbazqux")
+			checkChunks(map[protocol.ByteCount]string{0: "f", 1: "oob", 4: "arba", 8: "zqux"})
+			Expect(b.received).To(Equal([]utils.ByteInterval{{Start: 0, End: 12}}))
+			Expect(readAll()).To(Equal("foobarbazqux"))
+		})
+
+		It("handles frames with random offsets and overlaps", func() {
+			data := bytes.Repeat([]byte("foobar"), 100)
+			for i := 0; i < 500; i++ {
+				offset := protocol.ByteCount((i * 7919) % len(data))
+				end := utils.MinByteCount(offset+protocol.ByteCount(i%37+1), protocol.ByteCount(len(data)))
+				Expect(b.Push(&wire.StreamFrame{Offset: offset, Data: data[offset:end]})).To(Succeed())
+			}
+			Expect(b.Push(&wire.StreamFrame{Data: data})).To(Succeed())
+			Expect(b.received).To(HaveLen(1))
+			p := make([]byte, len(data))
+			Expect(b.Read(p)).To(Equal(len(data)))
+			Expect(p).To(Equal(data))
+			Expect(b.dataLen).To(BeZero())
+		})
+	})
+
+	Context("reading out of order", func() {
+		It("pops the data with the lowest offset", func() {
+			push(6, "baz")
+			push(3, "bar")
+			offset, data, ok := b.PopUnordered()
+			Expect(ok).To(BeTrue())
+			Expect(offset).To(Equal(protocol.ByteCount(3)))
+			Expect(data).To(Equal([]byte("bar")))
+			offset, data, ok = b.PopUnordered()
+			Expect(ok).To(BeTrue())
+			Expect(offset).To(Equal(protocol.ByteCount(6)))
+			Expect(data).To(Equal([]byte("baz")))
+			_, _, ok = b.PopUnordered()
+			Expect(ok).To(BeFalse())
+		})
+
+		It("ignores retransmissions of data that was popped", func() {
+			push(3, "bar")
+			_, _, ok := b.PopUnordered()
+			Expect(ok).To(BeTrue())
+			push(0, "foobarbaz")
+			checkChunks(map[protocol.ByteCount]string{0: "foo", 6: "baz"})
+		})
+
+		It("advances the read position when the gaps are filled", func() {
+			push(3, "bar")
+			_, _, ok := b.PopUnordered()
+			Expect(ok).To(BeTrue())
+			Expect(b.readPosition).To(BeZero())
+			push(0, "foo")
+			_, data, ok := b.PopUnordered()
+			Expect(ok).To(BeTrue())
+			Expect(data).To(Equal([]byte("foo")))
+			Expect(b.readPosition).To(Equal(protocol.ByteCount(6)))
+			Expect(b.received).To(BeEmpty())
+		})
+
+		It("returns a slice that can't be appended to in place", func() {
+			push(3, "b")
+			push(4, "ar")
+			_, data, ok := b.PopUnordered()
+			Expect(ok).To(BeTrue())
+			Expect(data).To(Equal([]byte("bar")))
+			Expect(cap(data)).To(Equal(len(data)))
+		})
+	})
+
+	Context("discarding data", func() {
+		It("discards data that wasn't received yet", func() {
+			Expect(b.Discard(0, 3)).To(Equal(protocol.ByteCount(3)))
+			Expect(b.readPosition).To(Equal(protocol.ByteCount(3)))
+			push(0, "foobar")
+			Expect(readAll()).To(Equal("bar"))
+		})
+
+		It("discards buffered data", func() {
+			push(0, "foobarbaz")
+			Expect(b.Discard(3, 6)).To(Equal(protocol.ByteCount(3)))
+			checkChunks(map[protocol.ByteCount]string{0: "foo", 6: "baz"})
+			// the caller accounts for the discarded bytes, so reading stops in front of them
+			Expect(readAll()).To(Equal("foo"))
+			Expect(readAll()).To(Equal("baz"))
+		})
+
+		It("discards buffered data and gaps", func() {
+			push(2, "ob")
+			push(6, "baz")
+			Expect(b.Discard(1, 8)).To(Equal(protocol.ByteCount(7)))
+			checkChunks(map[protocol.ByteCount]string{8: "z"})
+			push(0, "foobarbazqux")
+			Expect(readAll()).To(Equal("f"))
+			Expect(readAll()).To(Equal("zqux"))
+		})
+
+		It("doesn't count data that was already read", func() {
+			push(0, "foo")
+			Expect(readAll()).To(Equal("foo"))
+			push(6, "baz")
+			_, _, ok := b.PopUnordered()
+			Expect(ok).To(BeTrue())
+			Expect(b.Discard(0, 12)).To(Equal(protocol.ByteCount(6)))
+			Expect(b.readPosition).To(Equal(protocol.ByteCount(12)))
+		})
+	})
+
+	Context("limiting the memory usage", func() {
+		It("accepts data received with gaps", func() {
+			for i := protocol.ByteCount(0); i < 10000; i++ {
+				Expect(b.Push(&wire.StreamFrame{Offset: 1000 * i, Data: make([]byte, 500)})).To(Succeed())
+			}
+			Expect(b.chunks).To(HaveLen(10000))
+		})
+
+		It("accepts data received in small frames in order", func() {
+			for i := protocol.ByteCount(0); i < 100000; i++ {
+				Expect(b.Push(&wire.StreamFrame{Offset: i, Data: []byte{'f'}})).To(Succeed())
+			}
+			Expect(b.chunks).To(HaveLen(1))
+		})
+
+		It("errors when too much memory is used for small frames", func() {
+			for i := protocol.ByteCount(0); ; i++ {
+				err := b.Push(&wire.StreamFrame{Offset: 2 * i, Data: []byte{'f'}})
+				if err != nil {
+					Expect(err).To(MatchError(errReassemblyBufferFull))
+					Expect(b.memoryUsed()).To(BeNumerically(">", protocol.MaxStreamReassemblyBufferOverhead))
+					break
+				}
+			}
+		})
+	})
+})
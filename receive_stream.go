@@ -1,6 +1,7 @@
 package quic
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"sync"
@@ -17,6 +18,7 @@ type receiveStreamI interface {
 
 	handleStreamFrame(*wire.StreamFrame) error
 	handleRstStreamFrame(*wire.RstStreamFrame) error
+	handleExpiredStreamDataFrame(*wire.ExpiredStreamDataFrame) error
 	closeForShutdown(error)
 	getWindowUpdate() protocol.ByteCount
 }
@@ -28,9 +30,14 @@ type receiveStream struct {
 
 	sender streamSender
 
-	frameQueue     *streamFrameSorter
-	readPosInFrame int
-	readOffset     protocol.ByteCount
+	reassemblyBuffer *reassemblyBuffer
+	// readOffset is the offset up to which Read consumed data.
+	// In out-of-order mode, it counts the bytes returned by ReadChunk (and the bytes read before) instead.
+	readOffset  protocol.ByteCount
+	finalOffset protocol.ByteCount
+
+	// expiredData are the ranges announced in EXPIRED_STREAM_DATA frames that were not yet skipped
+	expiredData []utils.ByteInterval
 
 	closeForShutdownErr error
 	cancelReadErr       error
@@ -40,6 +47,7 @@ type receiveStream struct {
 	finRead           bool // set once we read a frame with a FinBit
 	canceledRead      bool // set when CancelRead() is called
 	resetRemotely     bool // set when HandleRstStreamFrame() is called
+	unordered         bool // set when ReadChunk() is called
 
 	readChan     chan struct{}
 	readDeadline time.Time
@@ -51,6 +59,8 @@ type receiveStream struct {
 var _ ReceiveStream = &receiveStream{}
 var _ receiveStreamI = &receiveStream{}
 
+var errTooManyExpiredRanges = errors.New("too many expired ranges on a stream")
+
 func newReceiveStream(
 	streamID protocol.StreamID,
 	sender streamSender,
@@ -58,12 +68,13 @@ func newReceiveStream(
 	version protocol.VersionNumber,
 ) *receiveStream {
 	return &receiveStream{
-		streamID:       streamID,
-		sender:         sender,
-		flowController: flowController,
-		frameQueue:     newStreamFrameSorter(),
-		readChan:       make(chan struct{}, 1),
-		version:        version,
+		streamID:         streamID,
+		sender:           sender,
+		flowController:   flowController,
+		reassemblyBuffer: newReassemblyBuffer(),
+		readChan:         make(chan struct{}, 1),
+		finalOffset:      protocol.MaxByteCount,
+		version:          version,
 	}
 }
 
@@ -79,6 +90,9 @@ func (s *receiveStream) Read(p []byte) (int, error) {
 	if s.finRead {
 		return 0, io.EOF
 	}
+	if s.unordered {
+		return 0, fmt.Errorf("Read called on stream %d after ReadChunk", s.streamID)
+	}
 	if s.canceledRead {
 		return 0, s.cancelReadErr
 	}
@@ -91,8 +105,14 @@ func (s *receiveStream) Read(p []byte) (int, error) {
 
 	bytesRead := 0
 	for bytesRead < len(p) {
-		frame := s.frameQueue.Head()
-		if frame == nil && bytesRead > 0 {
+		s.skipExpiredData()
+		// all data was read
+		if s.readOffset == s.finalOffset {
+			s.finRead = true
+			s.sender.onStreamCompleted(s.streamID)
+			return bytesRead, io.EOF
+		}
+		if !s.reassemblyBuffer.HasData() && bytesRead > 0 {
 			return bytesRead, s.closeForShutdownErr
 		}
 
@@ -113,8 +133,7 @@ func (s *receiveStream) Read(p []byte) (int, error) {
 				return bytesRead, errDeadline
 			}
 
-			if frame != nil {
-				s.readPosInFrame = int(s.readOffset - frame.Offset)
+			if s.reassemblyBuffer.HasData() || s.readOffset == s.finalOffset {
 				break
 			}
 
@@ -128,25 +147,16 @@ func (s *receiveStream) Read(p []byte) (int, error) {
 				}
 			}
 			s.mutex.Lock()
-			frame = s.frameQueue.Head()
+			s.skipExpiredData()
 		}
-
-		if bytesRead > len(p) {
-			return bytesRead, fmt.Errorf("BUG: bytesRead (%d) > len(p) (%d) in stream.Read", bytesRead, len(p))
-		}
-		if s.readPosInFrame > int(frame.DataLen()) {
-			return bytesRead, fmt.Errorf("BUG: readPosInFrame (%d) > frame.DataLen (%d) in stream.Read", s.readPosInFrame, frame.DataLen())
+		if !s.reassemblyBuffer.HasData() { // all data was read
+			continue
 		}
 
-		s.mutex.Unlock()
-
-		copy(p[bytesRead:], frame.Data[s.readPosInFrame:])
-		m := utils.Min(len(p)-bytesRead, int(frame.DataLen())-s.readPosInFrame)
-		s.readPosInFrame += m
+		m := s.reassemblyBuffer.Read(p[bytesRead:])
 		bytesRead += m
 		s.readOffset += protocol.ByteCount(m)
 
-		s.mutex.Lock()
 		// when a RST_STREAM was received, the was already informed about the final byteOffset for this stream
 		if !s.resetRemotely {
 			s.flowController.AddBytesRead(protocol.ByteCount(m))
@@ -155,17 +165,103 @@ func (s *receiveStream) Read(p []byte) (int, error) {
 		if s.flowController.HasWindowUpdate() {
 			s.sender.onHasWindowUpdate(s.streamID)
 		}
+	}
+	if s.readOffset == s.finalOffset {
+		s.finRead = true
+		s.sender.onStreamCompleted(s.streamID)
+		return bytesRead, io.EOF
+	}
+	return bytesRead, nil
+}
+
+// ReadChunk implements the out-of-order receive mode.
+// It returns the data of the next STREAM frame that arrived, regardless of the data at lower offsets.
+func (s *receiveStream) ReadChunk() (uint64, []byte, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.unordered = true
+	if s.finRead {
+		return 0, nil, false, io.EOF
+	}
+
+	for {
+		// Stop waiting on errors
+		if s.closedForShutdown {
+			return 0, nil, false, s.closeForShutdownErr
+		}
+		if s.canceledRead {
+			return 0, nil, false, s.cancelReadErr
+		}
+		if s.resetRemotely {
+			return 0, nil, false, s.resetRemotelyErr
+		}
+
+		deadline := s.readDeadline
+		if !deadline.IsZero() && !time.Now().Before(deadline) {
+			return 0, nil, false, errDeadline
+		}
+
+		s.skipExpiredData()
+		// all data was read or expired, and the FIN was received after that
+		if s.readOffset == s.finalOffset {
+			s.finRead = true
+			s.sender.onStreamCompleted(s.streamID)
+			return uint64(s.readOffset), nil, true, nil
+		}
 
-		if s.readPosInFrame >= int(frame.DataLen()) {
-			s.frameQueue.Pop()
-			s.finRead = frame.FinBit
-			if frame.FinBit {
+		if offset, data, ok := s.reassemblyBuffer.PopUnordered(); ok {
+			s.readOffset += protocol.ByteCount(len(data))
+			s.flowController.AddBytesRead(protocol.ByteCount(len(data)))
+			if s.flowController.HasWindowUpdate() {
+				s.sender.onHasWindowUpdate(s.streamID)
+			}
+			// all data up to the final offset was read
+			if s.readOffset == s.finalOffset {
+				s.finRead = true
 				s.sender.onStreamCompleted(s.streamID)
-				return bytesRead, io.EOF
+				return uint64(offset), data, true, nil
 			}
+			return uint64(offset), data, false, nil
 		}
+
+		s.mutex.Unlock()
+		if deadline.IsZero() {
+			<-s.readChan
+		} else {
+			select {
+			case <-s.readChan:
+			case <-time.After(deadline.Sub(time.Now())):
+			}
+		}
+		s.mutex.Lock()
+	}
+}
+
+// skipExpiredData discards data that the peer announced as expired.
+// When reading in order, this happens once all data in front of the expired range was read.
+// It must be called with the mutex held.
+func (s *receiveStream) skipExpiredData() {
+	var skipped protocol.ByteCount
+	for i := 0; i < len(s.expiredData); i++ {
+		r := s.expiredData[i]
+		if !s.unordered && r.Start > s.readOffset {
+			continue
+		}
+		s.expiredData = append(s.expiredData[:i], s.expiredData[i+1:]...)
+		n := s.reassemblyBuffer.Discard(r.Start, r.End)
+		s.readOffset += n
+		skipped += n
+		// the read offset might have moved into another expired range
+		i = -1
+	}
+	if skipped == 0 {
+		return
+	}
+	s.flowController.AddBytesRead(skipped)
+	if s.flowController.HasWindowUpdate() {
+		s.sender.onHasWindowUpdate(s.streamID)
 	}
-	return bytesRead, nil
 }
 
 func (s *receiveStream) CancelRead(errorCode protocol.ApplicationErrorCode) error {
@@ -198,9 +294,30 @@ func (s *receiveStream) handleStreamFrame(frame *wire.StreamFrame) error {
 
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
-	if err := s.frameQueue.Push(frame); err != nil && err != errDuplicateStreamData {
+	if frame.FinBit {
+		s.finalOffset = maxOffset
+	}
+	if err := s.reassemblyBuffer.Push(frame); err != nil {
+		return err
+	}
+	s.signalRead()
+	return nil
+}
+
+func (s *receiveStream) handleExpiredStreamDataFrame(frame *wire.ExpiredStreamDataFrame) error {
+	if err := s.flowController.UpdateHighestReceived(frame.MinimumOffset, false); err != nil {
 		return err
 	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.finRead || (!s.unordered && frame.MinimumOffset <= s.readOffset) {
+		return nil
+	}
+	if len(s.expiredData) >= protocol.MaxExpiredStreamDataRanges {
+		return errTooManyExpiredRanges
+	}
+	s.expiredData = append(s.expiredData, utils.ByteInterval{Start: frame.Offset, End: frame.MinimumOffset})
 	s.signalRead()
 	return nil
 }
@@ -82,8 +82,8 @@ var _ = Describe("Receive Stream", func() {
 		It("reads all data available", func() {
 			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(2), false)
 			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(4), false)
-			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(2)).Times(2)
-			mockFC.EXPECT().HasWindowUpdate().Times(2)
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(4))
+			mockFC.EXPECT().HasWindowUpdate()
 			frame1 := wire.StreamFrame{
 				Offset: 0,
 				Data:   []byte{0xDE, 0xAD},
@@ -106,8 +106,8 @@ var _ = Describe("Receive Stream", func() {
 		It("assembles multiple STREAM frames", func() {
 			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(2), false)
 			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(4), false)
-			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(2)).Times(2)
-			mockFC.EXPECT().HasWindowUpdate().Times(2)
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(4))
+			mockFC.EXPECT().HasWindowUpdate()
 			frame1 := wire.StreamFrame{
 				Offset: 0,
 				Data:   []byte{0xDE, 0xAD},
@@ -147,8 +147,8 @@ var _ = Describe("Receive Stream", func() {
 		It("handles STREAM frames in wrong order", func() {
 			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(2), false)
 			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(4), false)
-			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(2)).Times(2)
-			mockFC.EXPECT().HasWindowUpdate().Times(2)
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(4))
+			mockFC.EXPECT().HasWindowUpdate()
 			frame1 := wire.StreamFrame{
 				Offset: 2,
 				Data:   []byte{0xBE, 0xEF},
@@ -172,8 +172,8 @@ var _ = Describe("Receive Stream", func() {
 			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(2), false)
 			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(2), false)
 			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(4), false)
-			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(2)).Times(2)
-			mockFC.EXPECT().HasWindowUpdate().Times(2)
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(4))
+			mockFC.EXPECT().HasWindowUpdate()
 			frame1 := wire.StreamFrame{
 				Offset: 0,
 				Data:   []byte{0xDE, 0xAD},
@@ -202,9 +202,8 @@ var _ = Describe("Receive Stream", func() {
 		It("doesn't rejects a STREAM frames with an overlapping data range", func() {
 			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(4), false)
 			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
-			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(2))
-			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(4))
-			mockFC.EXPECT().HasWindowUpdate().Times(2)
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(6))
+			mockFC.EXPECT().HasWindowUpdate()
 			frame1 := wire.StreamFrame{
 				Offset: 0,
 				Data:   []byte("foob"),
@@ -224,7 +223,7 @@ var _ = Describe("Receive Stream", func() {
 			Expect(b).To(Equal([]byte("foobar")))
 		})
 
-		It("passes on errors from the streamFrameSorter", func() {
+		It("passes on errors from the reassembly buffer", func() {
 			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(0), false)
 			err := str.handleStreamFrame(&wire.StreamFrame{StreamID: streamID}) // STREAM frame without data
 			Expect(err).To(MatchError(errEmptyStreamData))
@@ -338,8 +337,8 @@ var _ = Describe("Receive Stream", func() {
 				It("handles out-of-order frames", func() {
 					mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(2), false)
 					mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(4), true)
-					mockFC.EXPECT().AddBytesRead(protocol.ByteCount(2)).Times(2)
-					mockFC.EXPECT().HasWindowUpdate().Times(2)
+					mockFC.EXPECT().AddBytesRead(protocol.ByteCount(4))
+					mockFC.EXPECT().HasWindowUpdate()
 					frame1 := wire.StreamFrame{
 						Offset: 2,
 						Data:   []byte{0xBE, 0xEF},
@@ -384,8 +383,6 @@ var _ = Describe("Receive Stream", func() {
 
 				It("handles immediate FINs", func() {
 					mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(0), true)
-					mockFC.EXPECT().AddBytesRead(protocol.ByteCount(0))
-					mockFC.EXPECT().HasWindowUpdate()
 					err := str.handleStreamFrame(&wire.StreamFrame{
 						Offset: 0,
 						FinBit: true,
@@ -401,8 +398,6 @@ var _ = Describe("Receive Stream", func() {
 
 			It("closes when CloseRemote is called", func() {
 				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(0), true)
-				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(0))
-				mockFC.EXPECT().HasWindowUpdate()
 				str.CloseRemote(0)
 				mockSender.EXPECT().onStreamCompleted(streamID)
 				b := make([]byte, 8)
@@ -440,6 +435,336 @@ var _ = Describe("Receive Stream", func() {
 		})
 	})
 
+	Context("reading out of order", func() {
+		It("returns STREAM frames in the order they arrive", func() {
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(10), false)
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(4), false)
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(2))
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(4))
+			mockFC.EXPECT().HasWindowUpdate().Times(2)
+			err := str.handleStreamFrame(&wire.StreamFrame{
+				Offset: 8,
+				Data:   []byte{0xBE, 0xEF},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			offset, data, fin, err := str.ReadChunk()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(offset).To(Equal(uint64(8)))
+			Expect(data).To(Equal([]byte{0xBE, 0xEF}))
+			Expect(fin).To(BeFalse())
+			err = str.handleStreamFrame(&wire.StreamFrame{
+				Offset: 0,
+				Data:   []byte{0xDE, 0xAD, 0xBE, 0xEF},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			offset, data, fin, err = str.ReadChunk()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(offset).To(BeZero())
+			Expect(data).To(Equal([]byte{0xDE, 0xAD, 0xBE, 0xEF}))
+			Expect(fin).To(BeFalse())
+		})
+
+		It("doesn't return duplicate data", func() {
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(10), false).Times(2)
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(2))
+			mockFC.EXPECT().HasWindowUpdate()
+			frame := wire.StreamFrame{
+				Offset: 8,
+				Data:   []byte{0xBE, 0xEF},
+			}
+			Expect(str.handleStreamFrame(&frame)).To(Succeed())
+			_, data, _, err := str.ReadChunk()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(Equal([]byte{0xBE, 0xEF}))
+			Expect(str.handleStreamFrame(&frame)).To(Succeed())
+			str.SetReadDeadline(time.Now().Add(scaleDuration(20 * time.Millisecond)))
+			_, _, _, err = str.ReadChunk()
+			Expect(err).To(MatchError(errDeadline))
+		})
+
+		It("waits until data is available", func() {
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(2))
+			mockFC.EXPECT().HasWindowUpdate()
+			go func() {
+				defer GinkgoRecover()
+				time.Sleep(10 * time.Millisecond)
+				err := str.handleStreamFrame(&wire.StreamFrame{
+					Offset: 4,
+					Data:   []byte{0xDE, 0xAD},
+				})
+				Expect(err).ToNot(HaveOccurred())
+			}()
+			offset, data, _, err := str.ReadChunk()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(offset).To(Equal(uint64(4)))
+			Expect(data).To(Equal([]byte{0xDE, 0xAD}))
+		})
+
+		It("doesn't return data that was already consumed by Read", func() {
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(4), false)
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(1))
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(3))
+			mockFC.EXPECT().HasWindowUpdate().Times(2)
+			err := str.handleStreamFrame(&wire.StreamFrame{
+				Offset: 0,
+				Data:   []byte{0xDE, 0xAD, 0xBE, 0xEF},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			b := make([]byte, 1)
+			n, err := strWithTimeout.Read(b)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(Equal(1))
+			offset, data, _, err := str.ReadChunk()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(offset).To(Equal(uint64(1)))
+			Expect(data).To(Equal([]byte{0xAD, 0xBE, 0xEF}))
+		})
+
+		It("errors when Read is called after ReadChunk", func() {
+			str.SetReadDeadline(time.Now().Add(-time.Second))
+			_, _, _, err := str.ReadChunk()
+			Expect(err).To(MatchError(errDeadline))
+			_, err = strWithTimeout.Read(make([]byte, 1))
+			Expect(err).To(MatchError("Read called on stream 1337 after ReadChunk"))
+		})
+
+		It("returns the FIN once all data was read", func() {
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(4), true)
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(2), false)
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(2)).Times(2)
+			mockFC.EXPECT().HasWindowUpdate().Times(2)
+			err := str.handleStreamFrame(&wire.StreamFrame{
+				Offset: 2,
+				Data:   []byte{0xBE, 0xEF},
+				FinBit: true,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			offset, data, fin, err := str.ReadChunk()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(offset).To(Equal(uint64(2)))
+			Expect(data).To(Equal([]byte{0xBE, 0xEF}))
+			Expect(fin).To(BeFalse())
+			err = str.handleStreamFrame(&wire.StreamFrame{
+				Offset: 0,
+				Data:   []byte{0xDE, 0xAD},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			mockSender.EXPECT().onStreamCompleted(streamID)
+			offset, data, fin, err = str.ReadChunk()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(offset).To(BeZero())
+			Expect(data).To(Equal([]byte{0xDE, 0xAD}))
+			Expect(fin).To(BeTrue())
+			_, _, _, err = str.ReadChunk()
+			Expect(err).To(MatchError(io.EOF))
+		})
+
+		It("handles a FIN without data", func() {
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(0), true)
+			mockSender.EXPECT().onStreamCompleted(streamID)
+			err := str.handleStreamFrame(&wire.StreamFrame{FinBit: true})
+			Expect(err).ToNot(HaveOccurred())
+			_, data, fin, err := str.ReadChunk()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(BeEmpty())
+			Expect(fin).To(BeTrue())
+		})
+
+		It("unblocks when the stream is canceled", func() {
+			mockSender.EXPECT().queueControlFrame(gomock.Any())
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				_, _, _, err := str.ReadChunk()
+				Expect(err).To(MatchError("Read on stream 1337 canceled with error code 1234"))
+				close(done)
+			}()
+			Consistently(done).ShouldNot(BeClosed())
+			Expect(str.CancelRead(1234)).To(Succeed())
+			Eventually(done).Should(BeClosed())
+		})
+	})
+
+	Context("expired data", func() {
+		It("skips expired data", func() {
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(10), false)
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(4))
+			mockFC.EXPECT().HasWindowUpdate()
+			err := str.handleExpiredStreamDataFrame(&wire.ExpiredStreamDataFrame{
+				StreamID:      streamID,
+				Offset:        0,
+				MinimumOffset: 6,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			err = str.handleStreamFrame(&wire.StreamFrame{
+				Offset: 6,
+				Data:   []byte{0xDE, 0xAD, 0xBE, 0xEF},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(6))
+			mockFC.EXPECT().HasWindowUpdate()
+			b := make([]byte, 4)
+			n, err := strWithTimeout.Read(b)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(Equal(4))
+			Expect(b).To(Equal([]byte{0xDE, 0xAD, 0xBE, 0xEF}))
+		})
+
+		It("only skips expired data once all data in front of it was read", func() {
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(2), false)
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(8), false)
+			err := str.handleExpiredStreamDataFrame(&wire.ExpiredStreamDataFrame{
+				StreamID:      streamID,
+				Offset:        2,
+				MinimumOffset: 6,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			err = str.handleStreamFrame(&wire.StreamFrame{
+				Offset: 6,
+				Data:   []byte{0xBE, 0xEF},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				b := make([]byte, 4)
+				n, err := strWithTimeout.Read(b)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(n).To(Equal(4))
+				Expect(b).To(Equal([]byte{0xDE, 0xAD, 0xBE, 0xEF}))
+				close(done)
+			}()
+			Consistently(done).ShouldNot(BeClosed())
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(2)).Times(2)
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(4))
+			mockFC.EXPECT().HasWindowUpdate().Times(3)
+			err = str.handleStreamFrame(&wire.StreamFrame{
+				Offset: 0,
+				Data:   []byte{0xDE, 0xAD},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(done).Should(BeClosed())
+		})
+
+		It("ignores expired data that was already read", func() {
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(4), false).Times(2)
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(4))
+			mockFC.EXPECT().HasWindowUpdate()
+			err := str.handleStreamFrame(&wire.StreamFrame{
+				Offset: 0,
+				Data:   []byte{0xDE, 0xAD, 0xBE, 0xEF},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			b := make([]byte, 4)
+			_, err = strWithTimeout.Read(b)
+			Expect(err).ToNot(HaveOccurred())
+			err = str.handleExpiredStreamDataFrame(&wire.ExpiredStreamDataFrame{
+				StreamID:      streamID,
+				Offset:        0,
+				MinimumOffset: 4,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.expiredData).To(BeEmpty())
+		})
+
+		It("returns the FIN after skipping expired data", func() {
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(4), true)
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(4), false)
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(4))
+			mockFC.EXPECT().HasWindowUpdate()
+			err := str.handleStreamFrame(&wire.StreamFrame{
+				Offset: 2,
+				Data:   []byte{0xBE, 0xEF},
+				FinBit: true,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			err = str.handleExpiredStreamDataFrame(&wire.ExpiredStreamDataFrame{
+				StreamID:      streamID,
+				Offset:        0,
+				MinimumOffset: 4,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			mockSender.EXPECT().onStreamCompleted(streamID)
+			n, err := strWithTimeout.Read(make([]byte, 4))
+			Expect(err).To(MatchError(io.EOF))
+			Expect(n).To(BeZero())
+		})
+
+		It("passes on errors from the flow controller", func() {
+			testErr := errors.New("flow control violation")
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false).Return(testErr)
+			err := str.handleExpiredStreamDataFrame(&wire.ExpiredStreamDataFrame{
+				StreamID:      streamID,
+				MinimumOffset: 6,
+			})
+			Expect(err).To(MatchError(testErr))
+		})
+
+		Context("reading out of order", func() {
+			It("skips expired data immediately", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(4), false)
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(8), false)
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(2))
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(4))
+				mockFC.EXPECT().HasWindowUpdate().Times(2)
+				err := str.handleStreamFrame(&wire.StreamFrame{
+					Offset: 2,
+					Data:   []byte{0xDE, 0xAD},
+				})
+				Expect(err).ToNot(HaveOccurred())
+				err = str.handleExpiredStreamDataFrame(&wire.ExpiredStreamDataFrame{
+					StreamID:      streamID,
+					Offset:        2,
+					MinimumOffset: 6,
+				})
+				Expect(err).ToNot(HaveOccurred())
+				err = str.handleStreamFrame(&wire.StreamFrame{
+					Offset: 6,
+					Data:   []byte{0xBE, 0xEF},
+				})
+				Expect(err).ToNot(HaveOccurred())
+				offset, data, fin, err := str.ReadChunk()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(offset).To(Equal(uint64(6)))
+				Expect(data).To(Equal([]byte{0xBE, 0xEF}))
+				Expect(fin).To(BeFalse())
+			})
+
+			It("completes the stream when the remaining data expires", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(4), true)
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(2), false)
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(2)).Times(2)
+				mockFC.EXPECT().HasWindowUpdate().Times(2)
+				err := str.handleStreamFrame(&wire.StreamFrame{
+					Offset: 2,
+					Data:   []byte{0xBE, 0xEF},
+					FinBit: true,
+				})
+				Expect(err).ToNot(HaveOccurred())
+				_, data, fin, err := str.ReadChunk()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(data).To(Equal([]byte{0xBE, 0xEF}))
+				Expect(fin).To(BeFalse())
+				err = str.handleExpiredStreamDataFrame(&wire.ExpiredStreamDataFrame{
+					StreamID:      streamID,
+					Offset:        0,
+					MinimumOffset: 2,
+				})
+				Expect(err).ToNot(HaveOccurred())
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				offset, data, fin, err := str.ReadChunk()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(offset).To(Equal(uint64(4)))
+				Expect(data).To(BeEmpty())
+				Expect(fin).To(BeTrue())
+			})
+		})
+	})
+
 	Context("stream cancelations", func() {
 		Context("canceling read", func() {
 			It("unblocks Read", func() {
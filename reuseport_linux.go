@@ -0,0 +1,97 @@
+// +build linux
+
+package quic
+
+import (
+	"net"
+	"os"
+	"runtime"
+	"strings"
+	"syscall"
+)
+
+// soReusePort is the value of SO_REUSEPORT.
+// The syscall package only defines it for some architectures.
+func soReusePort() int {
+	if strings.HasPrefix(runtime.GOARCH, "mips") {
+		return 0x200
+	}
+	return 0xf
+}
+
+// listenUDPSockets opens n UDP sockets on addr, using SO_REUSEPORT.
+// If the port of addr is 0, all sockets use the port that was chosen for the first socket.
+func listenUDPSockets(addr *net.UDPAddr, n int) ([]net.PacketConn, error) {
+	if n <= 1 {
+		conn, err := net.ListenUDP("udp", addr)
+		if err != nil {
+			return nil, err
+		}
+		return []net.PacketConn{conn}, nil
+	}
+	conns := make([]net.PacketConn, 0, n)
+	for i := 0; i < n; i++ {
+		conn, err := listenUDPReusePort(addr)
+		if err != nil {
+			for _, c := range conns {
+				c.Close()
+			}
+			return nil, err
+		}
+		conns = append(conns, conn)
+		if i == 0 {
+			addr = &net.UDPAddr{IP: addr.IP, Port: conn.LocalAddr().(*net.UDPAddr).Port, Zone: addr.Zone}
+		}
+	}
+	return conns, nil
+}
+
+// listenUDPReusePort opens a UDP socket with SO_REUSEPORT set.
+// Like net.ListenUDP, it opens a dual-stack socket if addr doesn't contain an IPv4 address.
+func listenUDPReusePort(addr *net.UDPAddr) (net.PacketConn, error) {
+	family := syscall.AF_INET6
+	var sa syscall.Sockaddr
+	if ip4 := addr.IP.To4(); ip4 != nil {
+		family = syscall.AF_INET
+		sa4 := &syscall.SockaddrInet4{Port: addr.Port}
+		copy(sa4.Addr[:], ip4)
+		sa = sa4
+	} else {
+		sa6 := &syscall.SockaddrInet6{Port: addr.Port}
+		copy(sa6.Addr[:], addr.IP.To16())
+		if addr.Zone != "" {
+			ifi, err := net.InterfaceByName(addr.Zone)
+			if err != nil {
+				return nil, err
+			}
+			sa6.ZoneId = uint32(ifi.Index)
+		}
+		sa = sa6
+	}
+	fd, err := syscall.Socket(family, syscall.SOCK_DGRAM|syscall.SOCK_NONBLOCK|syscall.SOCK_CLOEXEC, syscall.IPPROTO_UDP)
+	if err != nil {
+		return nil, os.NewSyscallError("socket", err)
+	}
+	if err := listenUDPReusePortFD(fd, family, addr, sa); err != nil {
+		syscall.Close(fd)
+		return nil, &net.OpError{Op: "listen", Net: "udp", Addr: addr, Err: err}
+	}
+	f := os.NewFile(uintptr(fd), "")
+	defer f.Close() // net.FilePacketConn duplicates the file descriptor
+	return net.FilePacketConn(f)
+}
+
+func listenUDPReusePortFD(fd, family int, addr *net.UDPAddr, sa syscall.Sockaddr) error {
+	if err := syscall.SetsockoptInt(fd, syscall.SOL_SOCKET, soReusePort(), 1); err != nil {
+		return os.NewSyscallError("setsockopt", err)
+	}
+	if family == syscall.AF_INET6 && (addr.IP == nil || addr.IP.IsUnspecified()) {
+		if err := syscall.SetsockoptInt(fd, syscall.IPPROTO_IPV6, syscall.IPV6_V6ONLY, 0); err != nil {
+			return os.NewSyscallError("setsockopt", err)
+		}
+	}
+	if err := syscall.Bind(fd, sa); err != nil {
+		return os.NewSyscallError("bind", err)
+	}
+	return nil
+}
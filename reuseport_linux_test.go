@@ -0,0 +1,115 @@
+// +build linux
+
+package quic
+
+import (
+	"net"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/testdata"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SO_REUSEPORT", func() {
+	It("opens multiple sockets on the same port", func() {
+		conns, err := listenUDPSockets(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}, 4)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(conns).To(HaveLen(4))
+		defer func() {
+			for _, c := range conns {
+				c.Close()
+			}
+		}()
+		port := conns[0].LocalAddr().(*net.UDPAddr).Port
+		Expect(port).ToNot(BeZero())
+		for _, c := range conns {
+			Expect(c).To(BeAssignableToTypeOf(&net.UDPConn{}))
+			Expect(c.LocalAddr().(*net.UDPAddr).Port).To(Equal(port))
+		}
+
+		// packets from many different source ports are distributed over all sockets
+		received := make(chan int, 1000)
+		for i, c := range conns {
+			go func(i int, c net.PacketConn) {
+				b := make([]byte, 100)
+				for {
+					if _, _, err := c.ReadFrom(b); err != nil {
+						return
+					}
+					received <- i
+				}
+			}(i, c)
+		}
+		for i := 0; i < 100; i++ {
+			client, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+			Expect(err).ToNot(HaveOccurred())
+			_, err = client.WriteTo([]byte("foobar"), conns[0].LocalAddr())
+			Expect(err).ToNot(HaveOccurred())
+			client.Close()
+		}
+		sockets := make(map[int]struct{})
+		for i := 0; i < 100; i++ {
+			var idx int
+			Eventually(received).Should(Receive(&idx))
+			sockets[idx] = struct{}{}
+		}
+		Expect(len(sockets)).To(BeNumerically(">", 1))
+	})
+
+	It("opens a dual-stack socket if no IP is given", func() {
+		conns, err := listenUDPSockets(&net.UDPAddr{}, 2)
+		if err != nil {
+			Skip("can't listen on a dual-stack socket")
+		}
+		defer func() {
+			for _, c := range conns {
+				c.Close()
+			}
+		}()
+		port := conns[0].LocalAddr().(*net.UDPAddr).Port
+		client, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		Expect(err).ToNot(HaveOccurred())
+		defer client.Close()
+		_, err = client.WriteTo([]byte("foobar"), &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: port})
+		Expect(err).ToNot(HaveOccurred())
+		received := make(chan struct{}, len(conns))
+		for _, c := range conns {
+			go func(c net.PacketConn) {
+				b := make([]byte, 100)
+				if _, _, err := c.ReadFrom(b); err == nil {
+					received <- struct{}{}
+				}
+			}(c)
+		}
+		Eventually(received).Should(Receive())
+	})
+
+	It("errors if the port is already in use by a socket without SO_REUSEPORT", func() {
+		conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.Close()
+		_, err = listenUDPSockets(conn.LocalAddr().(*net.UDPAddr), 2)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("listens on multiple sockets", func() {
+		ln, err := ListenAddr("127.0.0.1:0", testdata.GetTLSConfig(), &Config{NumSockets: 3})
+		Expect(err).ToNot(HaveOccurred())
+		serv := ln.(*server)
+		Expect(serv.additionalConns).To(HaveLen(2))
+		for _, c := range serv.additionalConns {
+			Expect(c.LocalAddr()).To(Equal(serv.Addr()))
+		}
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			_, err := ln.Accept()
+			Expect(err).To(HaveOccurred())
+			close(done)
+		}()
+		Expect(ln.Close()).To(Succeed())
+		Eventually(done, time.Second).Should(BeClosed())
+	})
+})
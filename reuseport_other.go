@@ -0,0 +1,15 @@
+// +build !linux
+
+package quic
+
+import "net"
+
+// listenUDPSockets opens a UDP socket on addr.
+// Multiple sockets on the same port are only supported on Linux, so n is ignored.
+func listenUDPSockets(addr *net.UDPAddr, n int) ([]net.PacketConn, error) {
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return []net.PacketConn{conn}, nil
+}
@@ -0,0 +1,222 @@
+package quic
+
+import (
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+)
+
+// A sendBuffer holds the data sent on a send stream until it is acknowledged by the peer.
+// When a packet containing stream data is lost, the lost byte range is marked in the buffer,
+// and the STREAM frames for the retransmission are generated from the buffered data.
+type sendBuffer struct {
+	chunks []sendBufferChunk // contiguous, sorted by offset
+
+	lowestUnacked protocol.ByteCount   // all data below this offset was acknowledged
+	acked         []utils.ByteInterval // acknowledged data above lowestUnacked, sorted by offset
+	lost          []utils.ByteInterval // data that needs to be retransmitted, sorted by offset
+}
+
+type sendBufferChunk struct {
+	offset protocol.ByteCount
+	data   []byte
+}
+
+// Append adds data that is going to be sent at offset.
+// The buffer takes ownership of the data slice.
+// Buffered data at and above offset was never sent (because a Write timed out) and is dropped.
+func (b *sendBuffer) Append(offset protocol.ByteCount, data []byte) {
+	for i := len(b.chunks) - 1; i >= 0; i-- {
+		c := &b.chunks[i]
+		if c.offset >= offset {
+			b.chunks[i] = sendBufferChunk{}
+			b.chunks = b.chunks[:i]
+			continue
+		}
+		if c.offset+protocol.ByteCount(len(c.data)) > offset {
+			c.data = c.data[:offset-c.offset]
+		}
+		break
+	}
+	b.chunks = append(b.chunks, sendBufferChunk{offset: offset, data: data})
+}
+
+// Acked marks the data from start to end as acknowledged.
+// Chunks that were acknowledged completely are released.
+func (b *sendBuffer) Acked(start, end protocol.ByteCount) {
+	start = utils.MaxByteCount(start, b.lowestUnacked)
+	if start >= end {
+		return
+	}
+	r := utils.ByteInterval{Start: start, End: end}
+	b.lost = removeByteInterval(b.lost, r)
+	b.acked = addByteInterval(b.acked, r)
+	if b.acked[0].Start != b.lowestUnacked {
+		return
+	}
+	b.lowestUnacked = b.acked[0].End
+	b.acked = b.acked[1:]
+	for len(b.chunks) > 0 {
+		c := b.chunks[0]
+		if c.offset+protocol.ByteCount(len(c.data)) > b.lowestUnacked {
+			break
+		}
+		b.chunks[0] = sendBufferChunk{}
+		b.chunks = b.chunks[1:]
+	}
+}
+
+// Lost marks the data from start to end for retransmission.
+// Data that was already acknowledged is not retransmitted.
+func (b *sendBuffer) Lost(start, end protocol.ByteCount) {
+	start = utils.MaxByteCount(start, b.lowestUnacked)
+	for _, a := range b.acked {
+		if a.Start >= end {
+			break
+		}
+		if a.End <= start {
+			continue
+		}
+		if start < a.Start {
+			b.lost = addByteInterval(b.lost, utils.ByteInterval{Start: start, End: a.Start})
+		}
+		start = a.End
+	}
+	if start < end {
+		b.lost = addByteInterval(b.lost, utils.ByteInterval{Start: start, End: end})
+	}
+}
+
+// DropLost removes lost data from start to end, such that it is not retransmitted, and treats it as acknowledged.
+// It returns the ranges that were dropped.
+func (b *sendBuffer) DropLost(start, end protocol.ByteCount) []utils.ByteInterval {
+	var dropped []utils.ByteInterval
+	for _, l := range b.lost {
+		if l.Start >= end {
+			break
+		}
+		if l.End <= start {
+			continue
+		}
+		dropped = append(dropped, utils.ByteInterval{
+			Start: utils.MaxByteCount(l.Start, start),
+			End:   utils.MinByteCount(l.End, end),
+		})
+	}
+	for _, d := range dropped {
+		b.Acked(d.Start, d.End)
+	}
+	return dropped
+}
+
+// HasLostData says if there's data that needs to be retransmitted
+func (b *sendBuffer) HasLostData() bool {
+	return len(b.lost) > 0
+}
+
+// NextLostOffset returns the offset of the lost data that PopLost will return next.
+// It must only be called if HasLostData returns true.
+func (b *sendBuffer) NextLostOffset() protocol.ByteCount {
+	return b.lost[0].Start
+}
+
+// PopLost returns up to maxLen bytes of lost data, starting at NextLostOffset.
+// Adjacent lost ranges were merged when they were marked as lost, so they are returned at once.
+// It must only be called if HasLostData returns true.
+func (b *sendBuffer) PopLost(maxLen protocol.ByteCount) []byte {
+	r := &b.lost[0]
+	start := r.Start
+	end := utils.MinByteCount(r.End, r.Start+maxLen)
+	if end == r.End {
+		b.lost = b.lost[1:]
+	} else {
+		r.Start = end
+	}
+	return b.get(start, end)
+}
+
+// LowestUnacked returns the offset below which all data was acknowledged
+func (b *sendBuffer) LowestUnacked() protocol.ByteCount {
+	return b.lowestUnacked
+}
+
+// Len returns the number of bytes held in the buffer
+func (b *sendBuffer) Len() protocol.ByteCount {
+	var l protocol.ByteCount
+	for _, c := range b.chunks {
+		l += protocol.ByteCount(len(c.data))
+	}
+	return l
+}
+
+// get returns the data from start to end.
+// If the data is contained in a single chunk, no copy is made.
+func (b *sendBuffer) get(start, end protocol.ByteCount) []byte {
+	var data []byte
+	for _, c := range b.chunks {
+		chunkEnd := c.offset + protocol.ByteCount(len(c.data))
+		if chunkEnd <= start {
+			continue
+		}
+		if c.offset >= end {
+			break
+		}
+		from := utils.MaxByteCount(start, c.offset) - c.offset
+		to := utils.MinByteCount(end, chunkEnd) - c.offset
+		if data == nil && c.offset <= start && chunkEnd >= end {
+			return c.data[from:to]
+		}
+		if data == nil {
+			data = make([]byte, 0, end-start)
+		}
+		data = append(data, c.data[from:to]...)
+	}
+	return data
+}
+
+// addByteInterval adds r to a sorted list of non-overlapping intervals.
+// Overlapping and adjacent intervals are merged.
+func addByteInterval(list []utils.ByteInterval, r utils.ByteInterval) []utils.ByteInterval {
+	i := 0
+	for i < len(list) && list[i].End < r.Start {
+		i++
+	}
+	j := i
+	for j < len(list) && list[j].Start <= r.End {
+		r.Start = utils.MinByteCount(r.Start, list[j].Start)
+		r.End = utils.MaxByteCount(r.End, list[j].End)
+		j++
+	}
+	if i == j {
+		list = append(list, utils.ByteInterval{})
+		copy(list[i+1:], list[i:])
+		list[i] = r
+		return list
+	}
+	list[i] = r
+	return append(list[:i+1], list[j:]...)
+}
+
+// removeByteInterval removes r from a sorted list of non-overlapping intervals
+func removeByteInterval(list []utils.ByteInterval, r utils.ByteInterval) []utils.ByteInterval {
+	res := list[:0]
+	var tail []utils.ByteInterval
+	for k, l := range list {
+		if l.End <= r.Start {
+			res = append(res, l)
+			continue
+		}
+		if l.Start >= r.End {
+			tail = list[k:]
+			break
+		}
+		if l.Start < r.Start {
+			res = append(res, utils.ByteInterval{Start: l.Start, End: r.Start})
+		}
+		if l.End > r.End {
+			// This interval covers all of r. All the following intervals are unaffected.
+			rest := append([]utils.ByteInterval{{Start: r.End, End: l.End}}, list[k+1:]...)
+			return append(res, rest...)
+		}
+	}
+	return append(res, tail...)
+}
@@ -0,0 +1,157 @@
+package quic
+
+import (
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Send Buffer", func() {
+	var b *sendBuffer
+
+	BeforeEach(func() {
+		b = &sendBuffer{}
+	})
+
+	popAllLost := func(maxLen protocol.ByteCount) map[protocol.ByteCount][]byte {
+		res := make(map[protocol.ByteCount][]byte)
+		for b.HasLostData() {
+			offset := b.NextLostOffset()
+			res[offset] = b.PopLost(maxLen)
+		}
+		return res
+	}
+
+	Context("acknowledging data", func() {
+		It("releases data once it is acknowledged", func() {
+			b.Append(0, []byte("foo"))
+			b.Append(3, []byte("bar"))
+			Expect(b.Len()).To(Equal(protocol.ByteCount(6)))
+			b.Acked(0, 3)
+			Expect(b.LowestUnacked()).To(Equal(protocol.ByteCount(3)))
+			Expect(b.Len()).To(Equal(protocol.ByteCount(3)))
+			b.Acked(3, 6)
+			Expect(b.LowestUnacked()).To(Equal(protocol.ByteCount(6)))
+			Expect(b.Len()).To(BeZero())
+		})
+
+		It("releases data when a gap is acknowledged", func() {
+			b.Append(0, []byte("foo"))
+			b.Append(3, []byte("bar"))
+			b.Acked(3, 6)
+			Expect(b.LowestUnacked()).To(BeZero())
+			Expect(b.Len()).To(Equal(protocol.ByteCount(6)))
+			b.Acked(0, 3)
+			Expect(b.LowestUnacked()).To(Equal(protocol.ByteCount(6)))
+			Expect(b.Len()).To(BeZero())
+		})
+
+		It("keeps a chunk until it was acknowledged completely", func() {
+			b.Append(0, []byte("foobar"))
+			b.Acked(0, 3)
+			Expect(b.LowestUnacked()).To(Equal(protocol.ByteCount(3)))
+			Expect(b.Len()).To(Equal(protocol.ByteCount(6)))
+		})
+
+		It("handles duplicate acknowledgements", func() {
+			b.Append(0, []byte("foobar"))
+			b.Acked(2, 4)
+			b.Acked(2, 4)
+			b.Acked(0, 4)
+			Expect(b.LowestUnacked()).To(Equal(protocol.ByteCount(4)))
+			Expect(b.acked).To(BeEmpty())
+			b.Acked(0, 2)
+			Expect(b.LowestUnacked()).To(Equal(protocol.ByteCount(4)))
+		})
+
+		It("drops data that was never sent when appending", func() {
+			b.Append(0, []byte("foobar"))
+			b.Append(3, []byte("baz"))
+			Expect(b.Len()).To(Equal(protocol.ByteCount(6)))
+			b.Lost(0, 6)
+			Expect(popAllLost(100)).To(Equal(map[protocol.ByteCount][]byte{0: []byte("foobaz")}))
+		})
+	})
+
+	Context("retransmitting lost data", func() {
+		It("returns lost data", func() {
+			b.Append(0, []byte("foobar"))
+			Expect(b.HasLostData()).To(BeFalse())
+			b.Lost(1, 4)
+			Expect(b.HasLostData()).To(BeTrue())
+			Expect(b.NextLostOffset()).To(Equal(protocol.ByteCount(1)))
+			Expect(b.PopLost(100)).To(Equal([]byte("oob")))
+			Expect(b.HasLostData()).To(BeFalse())
+		})
+
+		It("doesn't copy data contained in a single chunk", func() {
+			data := []byte("foobar")
+			b.Append(0, data)
+			b.Lost(0, 6)
+			Expect(&b.PopLost(100)[0]).To(BeIdenticalTo(&data[0]))
+		})
+
+		It("merges adjacent lost ranges, across chunks", func() {
+			b.Append(0, []byte("foo"))
+			b.Append(3, []byte("bar"))
+			b.Lost(3, 6)
+			b.Lost(0, 3)
+			Expect(b.lost).To(Equal([]utils.ByteInterval{{Start: 0, End: 6}}))
+			Expect(popAllLost(100)).To(Equal(map[protocol.ByteCount][]byte{0: []byte("foobar")}))
+		})
+
+		It("returns non-adjacent lost ranges separately, in order", func() {
+			b.Append(0, []byte("foobar"))
+			b.Lost(4, 5)
+			b.Lost(0, 2)
+			Expect(b.NextLostOffset()).To(BeZero())
+			Expect(b.PopLost(100)).To(Equal([]byte("fo")))
+			Expect(b.NextLostOffset()).To(Equal(protocol.ByteCount(4)))
+			Expect(b.PopLost(100)).To(Equal([]byte("a")))
+			Expect(b.HasLostData()).To(BeFalse())
+		})
+
+		It("splits lost ranges", func() {
+			b.Append(0, []byte("foobar"))
+			b.Lost(0, 6)
+			Expect(b.PopLost(4)).To(Equal([]byte("foob")))
+			Expect(b.NextLostOffset()).To(Equal(protocol.ByteCount(4)))
+			Expect(b.PopLost(4)).To(Equal([]byte("ar")))
+			Expect(b.HasLostData()).To(BeFalse())
+		})
+
+		It("doesn't retransmit data that was acknowledged", func() {
+			b.Append(0, []byte("foobar"))
+			b.Acked(0, 1)
+			b.Acked(2, 3)
+			b.Acked(5, 6)
+			b.Lost(0, 6)
+			Expect(b.lost).To(Equal([]utils.ByteInterval{{Start: 1, End: 2}, {Start: 3, End: 5}}))
+			b.Acked(3, 4)
+			Expect(b.lost).To(Equal([]utils.ByteInterval{{Start: 1, End: 2}, {Start: 4, End: 5}}))
+		})
+
+		It("removes acknowledged data from the middle of a lost range", func() {
+			b.Append(0, []byte("foobar"))
+			b.Lost(0, 6)
+			b.Acked(2, 4)
+			Expect(popAllLost(100)).To(Equal(map[protocol.ByteCount][]byte{
+				0: []byte("fo"),
+				4: []byte("ar"),
+			}))
+		})
+
+		It("drops lost data", func() {
+			b.Append(0, []byte("foobar"))
+			b.Lost(0, 2)
+			b.Lost(3, 6)
+			Expect(b.DropLost(1, 5)).To(Equal([]utils.ByteInterval{{Start: 1, End: 2}, {Start: 3, End: 5}}))
+			Expect(popAllLost(100)).To(Equal(map[protocol.ByteCount][]byte{
+				0: []byte("f"),
+				5: []byte("r"),
+			}))
+			Expect(b.DropLost(0, 10)).To(BeEmpty())
+		})
+	})
+})
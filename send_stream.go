@@ -1,11 +1,14 @@
 package quic
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
+	"github.com/lucas-clemente/quic-go/internal/ackhandler"
 	"github.com/lucas-clemente/quic-go/internal/flowcontrol"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/internal/utils"
@@ -18,6 +21,47 @@ type sendStreamI interface {
 	popStreamFrame(maxBytes protocol.ByteCount) (*wire.StreamFrame, bool)
 	closeForShutdown(error)
 	handleMaxStreamDataFrame(*wire.MaxStreamDataFrame)
+	popRetransmissionFrame(maxBytes protocol.ByteCount) (*wire.StreamFrame, bool)
+	onDataAcked(offset, length protocol.ByteCount, fin bool)
+	onDataLost(offset, length protocol.ByteCount, fin bool) bool
+}
+
+// sentStreamData takes the place of a STREAM frame in the history of sent packets.
+// It only references the data, which is kept in the send buffer of the stream until it is acknowledged.
+type sentStreamData struct {
+	stream sendStreamI
+	offset protocol.ByteCount
+	length protocol.ByteCount
+	fin    bool
+}
+
+var _ ackhandler.AckListener = &sentStreamData{}
+
+func newSentStreamData(str sendStreamI, f *wire.StreamFrame) *sentStreamData {
+	return &sentStreamData{
+		stream: str,
+		offset: f.Offset,
+		length: f.DataLen(),
+		fin:    f.FinBit,
+	}
+}
+
+func (d *sentStreamData) Write(*bytes.Buffer, protocol.VersionNumber) error {
+	return errors.New("sentStreamData BUG: stream data must be retransmitted from the send buffer")
+}
+
+func (d *sentStreamData) MinLength(protocol.VersionNumber) protocol.ByteCount {
+	return 0
+}
+
+func (d *sentStreamData) OnAcked() {
+	d.stream.onDataAcked(d.offset, d.length, d.fin)
+}
+
+// expiringData is data that was written with a delivery deadline
+type expiringData struct {
+	utils.ByteInterval
+	deadline time.Time
 }
 
 type sendStream struct {
@@ -38,11 +82,27 @@ type sendStream struct {
 	finishedWriting   bool // set once Close() is called
 	canceledWrite     bool // set when CancelWrite() is called, or a STOP_SENDING frame is received
 	finSent           bool // set when a STREAM_FRAME with FIN bit has b
+	finLost           bool // set when a STREAM_FRAME with FIN bit was lost, and the FIN needs to be retransmitted
+	finAcked          bool // set when a STREAM_FRAME with FIN bit was acknowledged
+
+	// sendBuffer holds the data that was not yet acknowledged, such that lost data can be retransmitted.
+	// It is not used for the crypto stream, whose data is retransmitted together with the handshake packets.
+	sendBuffer sendBuffer
 
 	dataForWriting []byte
 	writeChan      chan struct{}
 	writeDeadline  time.Time
 
+	// coalescingDelay is the time that small writes are held back, see SetWriteCoalescingDelay.
+	coalescingDelay time.Duration
+	// coalescingTimer is set while data is held back.
+	// The session is only told about the data when the timer fires, or once enough data for a packet was written.
+	coalescingTimer *time.Timer
+
+	deliveryDeadline time.Time
+	expiringData     []expiringData       // data with a delivery deadline, sorted by offset
+	expiredData      []utils.ByteInterval // data whose delivery deadline has passed, sorted by offset
+
 	flowController flowcontrol.StreamFlowController
 
 	version protocol.VersionNumber
@@ -92,17 +152,47 @@ func (s *sendStream) Write(p []byte) (int, error) {
 		return 0, nil
 	}
 
-	s.dataForWriting = make([]byte, len(p))
-	copy(s.dataForWriting, p)
+	// data held back by write coalescing is sent in front of p
+	offset := s.writeOffset + protocol.ByteCount(len(s.dataForWriting))
+	data := make([]byte, len(p))
+	copy(data, p)
+	if s.streamID != s.version.CryptoStreamID() {
+		s.sendBuffer.Append(offset, data)
+	}
+	if !s.deliveryDeadline.IsZero() {
+		s.addExpiringData(offset, offset+protocol.ByteCount(len(p)), s.deliveryDeadline)
+	}
+	if s.dataForWriting == nil {
+		s.dataForWriting = data
+	} else {
+		s.dataForWriting = append(s.dataForWriting, data...)
+	}
+	// Hold back small writes, if write coalescing is enabled.
+	// Once the data fills a packet, it is sent immediately.
+	if s.coalescingDelay > 0 && protocol.ByteCount(len(s.dataForWriting)) < s.sender.maxStreamDataPerPacket() {
+		if s.coalescingTimer == nil {
+			var timer *time.Timer
+			timer = time.AfterFunc(s.coalescingDelay, func() { s.onCoalescingTimer(timer) })
+			s.coalescingTimer = timer
+		}
+		return len(p), nil
+	}
+	s.stopCoalescing()
 	s.sender.onHasStreamData(s.streamID)
 
 	var bytesWritten int
 	var err error
 	for {
-		bytesWritten = len(p) - len(s.dataForWriting)
+		// the data of this Write is at the end of dataForWriting
+		bytesWritten = len(p) - utils.Min(len(p), len(s.dataForWriting))
 		deadline := s.writeDeadline
 		if !deadline.IsZero() && !time.Now().Before(deadline) {
-			s.dataForWriting = nil
+			// keep the data held back by previous writes, Write already returned for it
+			if held := len(s.dataForWriting) - (len(p) - bytesWritten); held > 0 {
+				s.dataForWriting = s.dataForWriting[:held]
+			} else {
+				s.dataForWriting = nil
+			}
 			err = errDeadline
 			break
 		}
@@ -203,6 +293,85 @@ func (s *sendStream) getDataForWriting(maxBytes protocol.ByteCount) ([]byte, boo
 	return ret, s.finishedWriting && s.dataForWriting == nil && !s.finSent
 }
 
+// popRetransmissionFrame returns the next STREAM frame retransmitting data that was lost.
+// Adjacent lost byte ranges are sent in a single frame.
+// maxBytes is the maximum length this frame (including frame header) will have.
+func (s *sendStream) popRetransmissionFrame(maxBytes protocol.ByteCount) (*wire.StreamFrame, bool /* has more retransmissions */) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.canceledWrite || s.closeForShutdownErr != nil {
+		return nil, false
+	}
+	s.dropExpiredLostData(time.Now())
+	frame := &wire.StreamFrame{
+		StreamID:       s.streamID,
+		Offset:         s.writeOffset,
+		DataLenPresent: true,
+	}
+	if !s.sendBuffer.HasLostData() {
+		if !s.finLost {
+			return nil, false
+		}
+		if frame.MinLength(s.version) > maxBytes {
+			return nil, true
+		}
+		frame.FinBit = true
+		s.finLost = false
+		return frame, false
+	}
+	frame.Offset = s.sendBuffer.NextLostOffset()
+	frameLen := frame.MinLength(s.version)
+	if frameLen >= maxBytes { // a STREAM frame must have at least one byte of data
+		return nil, true
+	}
+	frame.Data = s.sendBuffer.PopLost(maxBytes - frameLen)
+	// The DataLen field might need more bytes than estimated by MinLength before the data was added.
+	// Give back the bytes that don't fit, they will be sent in the next frame.
+	if l := frame.MinLength(s.version) + frame.DataLen(); l > maxBytes {
+		end := frame.Offset + frame.DataLen()
+		frame.Data = frame.Data[:frame.DataLen()-(l-maxBytes)]
+		s.sendBuffer.Lost(end-(l-maxBytes), end)
+	}
+	// retransmit the FIN together with the last bytes of the stream
+	if s.finLost && frame.Offset+frame.DataLen() == s.writeOffset {
+		frame.FinBit = true
+		s.finLost = false
+	}
+	return frame, s.sendBuffer.HasLostData() || s.finLost
+}
+
+// onDataAcked is called when a packet containing stream data was acknowledged
+func (s *sendStream) onDataAcked(offset, length protocol.ByteCount, fin bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.canceledWrite || s.closeForShutdownErr != nil {
+		return
+	}
+	s.sendBuffer.Acked(offset, offset+length)
+	if fin {
+		s.finAcked = true
+		s.finLost = false
+	}
+}
+
+// onDataLost is called when a packet containing stream data was lost.
+// It returns true if there's data that needs to be retransmitted.
+func (s *sendStream) onDataLost(offset, length protocol.ByteCount, fin bool) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.canceledWrite || s.closeForShutdownErr != nil {
+		return false
+	}
+	s.sendBuffer.Lost(offset, offset+length)
+	if fin && !s.finAcked {
+		s.finLost = true
+	}
+	return s.sendBuffer.HasLostData() || s.finLost
+}
+
 func (s *sendStream) Close() error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -211,6 +380,7 @@ func (s *sendStream) Close() error {
 		return fmt.Errorf("Close called for canceled stream %d", s.streamID)
 	}
 	s.finishedWriting = true
+	s.stopCoalescing()
 	s.sender.onHasStreamData(s.streamID) // need to send the FIN
 	s.ctxCancel()
 	return nil
@@ -233,13 +403,16 @@ func (s *sendStream) cancelWriteImpl(errorCode protocol.ApplicationErrorCode, wr
 	}
 	s.canceledWrite = true
 	s.cancelWriteErr = writeErr
+	s.stopCoalescing()
 	s.signalWrite()
 	s.sender.queueControlFrame(&wire.RstStreamFrame{
 		StreamID:   s.streamID,
 		ByteOffset: s.writeOffset,
 		ErrorCode:  errorCode,
 	})
-	// TODO(#991): cancel retransmissions for this stream
+	// data is not retransmitted after the stream was reset
+	s.sendBuffer = sendBuffer{}
+	s.finLost = false
 	s.ctxCancel()
 	s.sender.onStreamCompleted(s.streamID)
 	return nil
@@ -254,7 +427,7 @@ func (s *sendStream) handleStopSendingFrame(frame *wire.StopSendingFrame) {
 func (s *sendStream) handleMaxStreamDataFrame(frame *wire.MaxStreamDataFrame) {
 	s.flowController.UpdateSendWindow(frame.ByteOffset)
 	s.mutex.Lock()
-	if s.dataForWriting != nil {
+	if s.dataForWriting != nil && s.coalescingTimer == nil {
 		s.sender.onHasStreamData(s.streamID)
 	}
 	s.mutex.Unlock()
@@ -288,6 +461,140 @@ func (s *sendStream) SetWriteDeadline(t time.Time) error {
 	return nil
 }
 
+func (s *sendStream) SetDeliveryDeadline(t time.Time) error {
+	if !t.IsZero() && !s.sender.peerSupportsExpiredStreamData() {
+		return errors.New("the peer doesn't support EXPIRED_STREAM_DATA frames")
+	}
+	s.mutex.Lock()
+	s.deliveryDeadline = t
+	s.mutex.Unlock()
+	return nil
+}
+
+func (s *sendStream) SetWriteCoalescingDelay(d time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.coalescingDelay = d
+	if d == 0 {
+		s.flush()
+	}
+	return nil
+}
+
+func (s *sendStream) Flush() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closeForShutdownErr != nil {
+		return s.closeForShutdownErr
+	}
+	if s.canceledWrite {
+		return s.cancelWriteErr
+	}
+	s.flush()
+	return nil
+}
+
+func (s *sendStream) onCoalescingTimer(timer *time.Timer) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	// the timer might have been stopped after it already fired
+	if s.coalescingTimer == timer {
+		s.flush()
+	}
+}
+
+// flush tells the session about the data held back by write coalescing
+// must be called after locking the mutex
+func (s *sendStream) flush() {
+	if s.coalescingTimer == nil {
+		return
+	}
+	s.stopCoalescing()
+	if s.dataForWriting != nil && !s.canceledWrite && !s.closedForShutdown {
+		s.sender.onHasStreamData(s.streamID)
+	}
+}
+
+// must be called after locking the mutex
+func (s *sendStream) stopCoalescing() {
+	if s.coalescingTimer != nil {
+		s.coalescingTimer.Stop()
+		s.coalescingTimer = nil
+	}
+}
+
+// must be called after locking the mutex
+func (s *sendStream) addExpiringData(start, end protocol.ByteCount, deadline time.Time) {
+	if l := len(s.expiringData); l > 0 {
+		last := &s.expiringData[l-1]
+		if last.End == start && last.deadline.Equal(deadline) {
+			last.End = end
+			return
+		}
+	}
+	s.expiringData = append(s.expiringData, expiringData{
+		ByteInterval: utils.ByteInterval{Start: start, End: end},
+		deadline:     deadline,
+	})
+}
+
+// updateExpiredData moves all data whose delivery deadline has passed to the expiredData.
+// must be called after locking the mutex
+func (s *sendStream) updateExpiredData(now time.Time) {
+	var j int
+	for _, d := range s.expiringData {
+		if now.Before(d.deadline) {
+			s.expiringData[j] = d
+			j++
+			continue
+		}
+		s.addExpiredData(d.ByteInterval)
+	}
+	s.expiringData = s.expiringData[:j]
+}
+
+// must be called after locking the mutex
+func (s *sendStream) addExpiredData(r utils.ByteInterval) {
+	i := len(s.expiredData)
+	for i > 0 && s.expiredData[i-1].Start > r.Start {
+		i--
+	}
+	s.expiredData = append(s.expiredData, utils.ByteInterval{})
+	copy(s.expiredData[i+1:], s.expiredData[i:])
+	s.expiredData[i] = r
+	// merge with the following range
+	if i+1 < len(s.expiredData) && s.expiredData[i+1].Start == r.End {
+		s.expiredData[i].End = s.expiredData[i+1].End
+		s.expiredData = append(s.expiredData[:i+1], s.expiredData[i+2:]...)
+	}
+	// merge with the preceding range
+	if i > 0 && s.expiredData[i-1].End == r.Start {
+		s.expiredData[i-1].End = s.expiredData[i].End
+		s.expiredData = append(s.expiredData[:i], s.expiredData[i+1:]...)
+	}
+}
+
+// dropExpiredLostData drops lost data whose delivery deadline has passed, instead of retransmitting it.
+// The peer is told to skip the data.
+// must be called after locking the mutex
+func (s *sendStream) dropExpiredLostData(now time.Time) {
+	if len(s.expiringData) == 0 && len(s.expiredData) == 0 {
+		return
+	}
+	s.updateExpiredData(now)
+	for _, r := range s.expiredData {
+		for _, d := range s.sendBuffer.DropLost(r.Start, r.End) {
+			s.sender.queueControlFrame(&wire.ExpiredStreamDataFrame{
+				StreamID:      s.streamID,
+				Offset:        d.Start,
+				MinimumOffset: d.End,
+			})
+		}
+	}
+}
+
 // CloseForShutdown closes a stream abruptly.
 // It makes Write unblock (and return the error) immediately.
 // The peer will NOT be informed about this: the stream is closed without sending a FIN or RST.
@@ -295,6 +602,7 @@ func (s *sendStream) closeForShutdown(err error) {
 	s.mutex.Lock()
 	s.closedForShutdown = true
 	s.closeForShutdownErr = err
+	s.stopCoalescing()
 	s.mutex.Unlock()
 	s.signalWrite()
 	s.ctxCancel()
@@ -10,6 +10,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/lucas-clemente/quic-go/internal/mocks"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
 	"github.com/lucas-clemente/quic-go/internal/wire"
 
 	. "github.com/onsi/ginkgo"
@@ -45,6 +46,24 @@ var _ = Describe("Send Stream", func() {
 		}).ShouldNot(BeEmpty())
 	}
 
+	writeAndPop := func(data []byte) *wire.StreamFrame {
+		mockFC.EXPECT().SendWindowSize().Return(protocol.ByteCount(9999))
+		mockFC.EXPECT().AddBytesSent(protocol.ByteCount(len(data)))
+		mockFC.EXPECT().IsBlocked()
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			_, err := strWithTimeout.Write(data)
+			Expect(err).ToNot(HaveOccurred())
+			close(done)
+		}()
+		waitForWrite()
+		f, _ := str.popStreamFrame(1000)
+		Expect(f).ToNot(BeNil())
+		Eventually(done).Should(BeClosed())
+		return f
+	}
+
 	It("gets stream id", func() {
 		Expect(str.StreamID()).To(Equal(protocol.StreamID(1337)))
 	})
@@ -509,6 +528,363 @@ var _ = Describe("Send Stream", func() {
 		})
 	})
 
+	Context("acknowledgements and retransmissions", func() {
+		It("releases acknowledged data", func() {
+			mockSender.EXPECT().onHasStreamData(streamID)
+			writeAndPop([]byte("foobar"))
+			Expect(str.sendBuffer.Len()).To(Equal(protocol.ByteCount(6)))
+			str.onDataAcked(0, 6, false)
+			Expect(str.sendBuffer.Len()).To(BeZero())
+		})
+
+		It("retransmits lost data", func() {
+			mockSender.EXPECT().onHasStreamData(streamID)
+			writeAndPop([]byte("foobar"))
+			Expect(str.onDataLost(0, 6, false)).To(BeTrue())
+			f, hasMoreData := str.popRetransmissionFrame(1000)
+			Expect(f).ToNot(BeNil())
+			Expect(f.StreamID).To(Equal(streamID))
+			Expect(f.Offset).To(BeZero())
+			Expect(f.Data).To(Equal([]byte("foobar")))
+			Expect(f.DataLenPresent).To(BeTrue())
+			Expect(f.FinBit).To(BeFalse())
+			Expect(hasMoreData).To(BeFalse())
+			f, _ = str.popRetransmissionFrame(1000)
+			Expect(f).To(BeNil())
+		})
+
+		It("merges adjacent lost ranges into one frame", func() {
+			mockSender.EXPECT().onHasStreamData(streamID).Times(2)
+			writeAndPop([]byte("foo"))
+			writeAndPop([]byte("bar"))
+			Expect(str.onDataLost(3, 3, false)).To(BeTrue())
+			Expect(str.onDataLost(0, 3, false)).To(BeTrue())
+			f, hasMoreData := str.popRetransmissionFrame(1000)
+			Expect(f.Offset).To(BeZero())
+			Expect(f.Data).To(Equal([]byte("foobar")))
+			Expect(hasMoreData).To(BeFalse())
+		})
+
+		It("splits retransmissions that don't fit", func() {
+			mockSender.EXPECT().onHasStreamData(streamID)
+			writeAndPop([]byte("foobar"))
+			Expect(str.onDataLost(0, 6, false)).To(BeTrue())
+			frameHeaderLen := (&wire.StreamFrame{StreamID: streamID, DataLenPresent: true}).MinLength(str.version)
+			f, hasMoreData := str.popRetransmissionFrame(frameHeaderLen + 4)
+			Expect(f.Data).To(Equal([]byte("foob")))
+			Expect(hasMoreData).To(BeTrue())
+			f, hasMoreData = str.popRetransmissionFrame(1000)
+			Expect(f.Offset).To(Equal(protocol.ByteCount(4)))
+			Expect(f.Data).To(Equal([]byte("ar")))
+			Expect(hasMoreData).To(BeFalse())
+		})
+
+		It("doesn't retransmit acknowledged data", func() {
+			mockSender.EXPECT().onHasStreamData(streamID)
+			writeAndPop([]byte("foobar"))
+			str.onDataAcked(2, 2, false)
+			Expect(str.onDataLost(0, 6, false)).To(BeTrue())
+			f, hasMoreData := str.popRetransmissionFrame(1000)
+			Expect(f.Data).To(Equal([]byte("fo")))
+			Expect(hasMoreData).To(BeTrue())
+			str.onDataAcked(4, 2, false)
+			f, hasMoreData = str.popRetransmissionFrame(1000)
+			Expect(f).To(BeNil())
+			Expect(hasMoreData).To(BeFalse())
+		})
+
+		It("retransmits the FIN together with the last bytes", func() {
+			mockSender.EXPECT().onHasStreamData(streamID).Times(2)
+			mockSender.EXPECT().onStreamCompleted(streamID)
+			writeAndPop([]byte("foobar"))
+			str.Close()
+			f, _ := str.popStreamFrame(1000)
+			Expect(f.FinBit).To(BeTrue())
+			Expect(str.onDataLost(6, 0, true)).To(BeTrue())
+			Expect(str.onDataLost(3, 3, false)).To(BeTrue())
+			f, hasMoreData := str.popRetransmissionFrame(1000)
+			Expect(f.Offset).To(Equal(protocol.ByteCount(3)))
+			Expect(f.Data).To(Equal([]byte("bar")))
+			Expect(f.FinBit).To(BeTrue())
+			Expect(hasMoreData).To(BeFalse())
+		})
+
+		It("doesn't retransmit a FIN that was acknowledged", func() {
+			mockSender.EXPECT().onHasStreamData(streamID)
+			mockSender.EXPECT().onStreamCompleted(streamID)
+			str.Close()
+			str.popStreamFrame(1000)
+			str.onDataAcked(0, 0, true)
+			Expect(str.onDataLost(0, 0, true)).To(BeFalse())
+		})
+
+		It("doesn't retransmit data after writing was canceled", func() {
+			mockSender.EXPECT().onHasStreamData(streamID)
+			writeAndPop([]byte("foobar"))
+			mockSender.EXPECT().queueControlFrame(gomock.Any())
+			mockSender.EXPECT().onStreamCompleted(streamID)
+			Expect(str.CancelWrite(1234)).To(Succeed())
+			Expect(str.sendBuffer.Len()).To(BeZero())
+			Expect(str.onDataLost(0, 6, false)).To(BeFalse())
+			f, hasMoreData := str.popRetransmissionFrame(1000)
+			Expect(f).To(BeNil())
+			Expect(hasMoreData).To(BeFalse())
+		})
+
+		It("doesn't buffer data of the crypto stream", func() {
+			str = newSendStream(str.version.CryptoStreamID(), mockSender, mockFC, str.version)
+			mockSender.EXPECT().onHasStreamData(str.version.CryptoStreamID())
+			mockFC.EXPECT().AddBytesSent(protocol.ByteCount(6))
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				_, err := str.Write([]byte("foobar"))
+				Expect(err).ToNot(HaveOccurred())
+				close(done)
+			}()
+			waitForWrite()
+			f, _ := str.popStreamFrame(1000)
+			Expect(f.Data).To(Equal([]byte("foobar")))
+			Eventually(done).Should(BeClosed())
+			Expect(str.sendBuffer.Len()).To(BeZero())
+		})
+	})
+
+	Context("delivery deadlines", func() {
+		It("refuses to set a delivery deadline if the peer doesn't support EXPIRED_STREAM_DATA frames", func() {
+			mockSender.EXPECT().peerSupportsExpiredStreamData().Return(false)
+			err := str.SetDeliveryDeadline(time.Now().Add(time.Second))
+			Expect(err).To(MatchError("the peer doesn't support EXPIRED_STREAM_DATA frames"))
+			Expect(str.deliveryDeadline).To(BeZero())
+		})
+
+		It("allows clearing the delivery deadline if the peer doesn't support EXPIRED_STREAM_DATA frames", func() {
+			Expect(str.SetDeliveryDeadline(time.Time{})).To(Succeed())
+		})
+
+		Context("if the peer supports EXPIRED_STREAM_DATA frames", func() {
+			BeforeEach(func() {
+				mockSender.EXPECT().peerSupportsExpiredStreamData().Return(true).AnyTimes()
+			})
+
+			It("drops retransmissions of expired data", func() {
+				mockSender.EXPECT().onHasStreamData(streamID)
+				str.SetDeliveryDeadline(time.Now().Add(-time.Second))
+				writeAndPop([]byte("foobar"))
+				Expect(str.onDataLost(0, 6, false)).To(BeTrue())
+				mockSender.EXPECT().queueControlFrame(&wire.ExpiredStreamDataFrame{
+					StreamID:      streamID,
+					Offset:        0,
+					MinimumOffset: 6,
+				})
+				f, hasMoreData := str.popRetransmissionFrame(1000)
+				Expect(f).To(BeNil())
+				Expect(hasMoreData).To(BeFalse())
+				Expect(str.sendBuffer.Len()).To(BeZero())
+			})
+
+			It("doesn't drop retransmissions before the deadline", func() {
+				mockSender.EXPECT().onHasStreamData(streamID)
+				str.SetDeliveryDeadline(time.Now().Add(time.Hour))
+				writeAndPop([]byte("foobar"))
+				Expect(str.onDataLost(0, 6, false)).To(BeTrue())
+				f, _ := str.popRetransmissionFrame(1000)
+				Expect(f).ToNot(BeNil())
+				Expect(f.Data).To(Equal([]byte("foobar")))
+			})
+
+			It("only drops the expired part of lost data", func() {
+				mockSender.EXPECT().onHasStreamData(streamID).Times(2)
+				writeAndPop([]byte("foo"))
+				str.SetDeliveryDeadline(time.Now().Add(-time.Second))
+				writeAndPop([]byte("bar"))
+				Expect(str.onDataLost(0, 6, false)).To(BeTrue())
+				mockSender.EXPECT().queueControlFrame(&wire.ExpiredStreamDataFrame{
+					StreamID:      streamID,
+					Offset:        3,
+					MinimumOffset: 6,
+				})
+				f, hasMoreData := str.popRetransmissionFrame(1000)
+				Expect(f).ToNot(BeNil())
+				Expect(f.Offset).To(BeZero())
+				Expect(f.Data).To(Equal([]byte("foo")))
+				Expect(hasMoreData).To(BeFalse())
+			})
+
+			It("retransmits the FIN when dropping expired data", func() {
+				mockSender.EXPECT().onHasStreamData(streamID).Times(2)
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				str.SetDeliveryDeadline(time.Now().Add(-time.Second))
+				writeAndPop([]byte("foobar"))
+				str.Close()
+				f, _ := str.popStreamFrame(1000)
+				Expect(f.FinBit).To(BeTrue())
+				Expect(str.onDataLost(0, 6, false)).To(BeTrue())
+				Expect(str.onDataLost(6, 0, true)).To(BeTrue())
+				mockSender.EXPECT().queueControlFrame(gomock.Any())
+				f, hasMoreData := str.popRetransmissionFrame(1000)
+				Expect(f).ToNot(BeNil())
+				Expect(f.Offset).To(Equal(protocol.ByteCount(6)))
+				Expect(f.Data).To(BeEmpty())
+				Expect(f.FinBit).To(BeTrue())
+				Expect(hasMoreData).To(BeFalse())
+			})
+
+			It("merges expired ranges", func() {
+				now := time.Now()
+				str.addExpiringData(10, 20, now)
+				str.addExpiringData(20, 25, now.Add(time.Hour))
+				str.addExpiringData(30, 40, now.Add(-time.Second))
+				str.addExpiringData(40, 50, now.Add(-time.Second))
+				Expect(str.expiringData).To(HaveLen(3))
+				str.updateExpiredData(now)
+				Expect(str.expiredData).To(Equal([]utils.ByteInterval{
+					{Start: 10, End: 20},
+					{Start: 30, End: 50},
+				}))
+				str.addExpiredData(utils.ByteInterval{Start: 20, End: 30})
+				Expect(str.expiredData).To(Equal([]utils.ByteInterval{{Start: 10, End: 50}}))
+				str.addExpiredData(utils.ByteInterval{Start: 0, End: 5})
+				Expect(str.expiredData).To(Equal([]utils.ByteInterval{
+					{Start: 0, End: 5},
+					{Start: 10, End: 50},
+				}))
+			})
+		})
+	})
+
+	Context("write coalescing", func() {
+		var (
+			delay               time.Duration
+			streamDataPerPacket protocol.ByteCount
+		)
+
+		BeforeEach(func() {
+			delay = scaleDuration(50 * time.Millisecond)
+			Expect(str.SetWriteCoalescingDelay(delay)).To(Succeed())
+			streamDataPerPacket = 1000
+			mockSender.EXPECT().maxStreamDataPerPacket().DoAndReturn(func() protocol.ByteCount { return streamDataPerPacket }).AnyTimes()
+		})
+
+		popAll := func() *wire.StreamFrame {
+			mockFC.EXPECT().SendWindowSize().Return(protocol.ByteCount(9999))
+			mockFC.EXPECT().AddBytesSent(gomock.Any())
+			mockFC.EXPECT().IsBlocked()
+			f, hasMoreData := str.popStreamFrame(2000)
+			Expect(f).ToNot(BeNil())
+			Expect(hasMoreData).To(BeFalse())
+			return f
+		}
+
+		It("holds back small writes until the delay expires", func() {
+			called := make(chan struct{})
+			mockSender.EXPECT().onHasStreamData(streamID).Do(func(protocol.StreamID) { close(called) })
+			start := time.Now()
+			n, err := strWithTimeout.Write([]byte("foo"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(Equal(3))
+			n, err = strWithTimeout.Write([]byte("bar"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(Equal(3))
+			Eventually(called).Should(BeClosed())
+			Expect(time.Now()).To(BeTemporally(">=", start.Add(delay)))
+			f := popAll()
+			Expect(f.Offset).To(BeZero())
+			Expect(f.Data).To(Equal([]byte("foobar")))
+		})
+
+		It("sends immediately once the data fills a packet", func() {
+			mockSender.EXPECT().onHasStreamData(streamID)
+			_, err := strWithTimeout.Write([]byte("foo"))
+			Expect(err).ToNot(HaveOccurred())
+			data := bytes.Repeat([]byte{'f'}, int(streamDataPerPacket)-3)
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				n, err := strWithTimeout.Write(data)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(n).To(Equal(len(data)))
+				close(done)
+			}()
+			Consistently(done).ShouldNot(BeClosed())
+			f := popAll()
+			Expect(f.Data).To(Equal(append([]byte("foo"), data...)))
+			Eventually(done).Should(BeClosed())
+			// make sure the timer was stopped
+			time.Sleep(2 * delay)
+		})
+
+		It("uses the current packet size of the connection", func() {
+			// path MTU discovery found that larger packets can be sent
+			streamDataPerPacket = 5000
+			called := make(chan struct{})
+			mockSender.EXPECT().onHasStreamData(streamID).Do(func(protocol.StreamID) { close(called) })
+			start := time.Now()
+			data := bytes.Repeat([]byte{'f'}, 2*int(protocol.MaxPacketSize))
+			n, err := strWithTimeout.Write(data)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(Equal(len(data)))
+			Eventually(called).Should(BeClosed())
+			Expect(time.Now()).To(BeTemporally(">=", start.Add(delay)))
+		})
+
+		It("sends immediately when flushed", func() {
+			_, err := strWithTimeout.Write([]byte("foobar"))
+			Expect(err).ToNot(HaveOccurred())
+			mockSender.EXPECT().onHasStreamData(streamID)
+			Expect(str.Flush()).To(Succeed())
+			Expect(popAll().Data).To(Equal([]byte("foobar")))
+			time.Sleep(2 * delay)
+		})
+
+		It("sends immediately when the delay is set to zero", func() {
+			_, err := strWithTimeout.Write([]byte("foo"))
+			Expect(err).ToNot(HaveOccurred())
+			mockSender.EXPECT().onHasStreamData(streamID)
+			Expect(str.SetWriteCoalescingDelay(0)).To(Succeed())
+			Expect(popAll().Data).To(Equal([]byte("foo")))
+			time.Sleep(2 * delay)
+		})
+
+		It("sends the data held back together with the FIN", func() {
+			_, err := strWithTimeout.Write([]byte("foobar"))
+			Expect(err).ToNot(HaveOccurred())
+			mockSender.EXPECT().onHasStreamData(streamID)
+			Expect(str.Close()).To(Succeed())
+			mockSender.EXPECT().onStreamCompleted(streamID)
+			mockFC.EXPECT().SendWindowSize().Return(protocol.ByteCount(9999))
+			mockFC.EXPECT().AddBytesSent(protocol.ByteCount(6))
+			f, _ := str.popStreamFrame(1000)
+			Expect(f.Data).To(Equal([]byte("foobar")))
+			Expect(f.FinBit).To(BeTrue())
+			time.Sleep(2 * delay)
+		})
+
+		It("doesn't send data held back after writing was canceled", func() {
+			_, err := strWithTimeout.Write([]byte("foobar"))
+			Expect(err).ToNot(HaveOccurred())
+			mockSender.EXPECT().queueControlFrame(gomock.Any())
+			mockSender.EXPECT().onStreamCompleted(streamID)
+			Expect(str.CancelWrite(1234)).To(Succeed())
+			Expect(str.Flush()).To(MatchError("Write on stream 1337 canceled with error code 1234"))
+			time.Sleep(2 * delay)
+		})
+
+		It("retransmits data from multiple writes", func() {
+			mockSender.EXPECT().onHasStreamData(streamID)
+			_, err := strWithTimeout.Write([]byte("foo"))
+			Expect(err).ToNot(HaveOccurred())
+			_, err = strWithTimeout.Write([]byte("bar"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Flush()).To(Succeed())
+			popAll()
+			Expect(str.onDataLost(0, 6, false)).To(BeTrue())
+			f, _ := str.popRetransmissionFrame(1000)
+			Expect(f.Offset).To(BeZero())
+			Expect(f.Data).To(Equal([]byte("foobar")))
+		})
+	})
+
 	Context("stream cancelations", func() {
 		Context("canceling writing", func() {
 			It("queues a RST_STREAM frame", func() {
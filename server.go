@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"errors"
 	"net"
+	"runtime"
 	"sync"
 	"time"
 
@@ -33,6 +34,8 @@ type server struct {
 	config  *Config
 
 	conn net.PacketConn
+	// additionalConns are the other sockets listening on the same port, see Config.NumSockets
+	additionalConns []net.PacketConn
 
 	supportsTLS bool
 	serverTLS   *serverTLS
@@ -40,13 +43,21 @@ type server struct {
 	certChain crypto.CertChain
 	scfg      *handshake.ServerConfig
 
-	sessionsMutex sync.RWMutex
-	sessions      map[protocol.ConnectionID]packetHandler
-	closed        bool
+	sessions *sessionMap
+
+	// packets read from the sockets are handled by a pool of workers, each with its own queue
+	packetQueues []chan serverPacket
+	// IETF QUIC Initial packets are handled by a separate pool of workers,
+	// such that a flood of new connections doesn't delay packets for established connections
+	initialPackets chan initialPacket
+
+	mutex  sync.Mutex // protects closed
+	closed bool
 
 	serverError  error
 	sessionQueue chan Session
 	errorChan    chan struct{}
+	errorOnce    sync.Once
 
 	// set as members, so they can be set in the tests
 	newSession                func(conn connection, v protocol.VersionNumber, connectionID protocol.ConnectionID, sCfg *handshake.ServerConfig, tlsConf *tls.Config, config *Config) (packetHandler, error)
@@ -55,6 +66,20 @@ type server struct {
 
 var _ Listener = &server{}
 
+// A serverPacket is a packet that was read from one of the server's sockets
+type serverPacket struct {
+	pconn      net.PacketConn
+	remoteAddr net.Addr
+	data       []byte
+	ecn        protocol.ECN
+}
+
+type initialPacket struct {
+	remoteAddr net.Addr
+	header     *wire.Header
+	data       []byte
+}
+
 // ListenAddr creates a QUIC server listening on a given address.
 // The listener is not active until Serve() is called.
 // The tls.Config must not be nil, the quic.Config may be nil.
@@ -63,17 +88,34 @@ func ListenAddr(addr string, tlsConf *tls.Config, config *Config) (Listener, err
 	if err != nil {
 		return nil, err
 	}
-	conn, err := net.ListenUDP("udp", udpAddr)
+	numSockets := 1
+	if config != nil && config.NumSockets > 1 {
+		numSockets = config.NumSockets
+	}
+	conns, err := listenUDPSockets(udpAddr, numSockets)
 	if err != nil {
 		return nil, err
 	}
-	return Listen(conn, tlsConf, config)
+	ln, err := listen(conns, tlsConf, config)
+	if err != nil {
+		for _, c := range conns {
+			c.Close()
+		}
+		return nil, err
+	}
+	return ln, nil
 }
 
 // Listen listens for QUIC connections on a given net.PacketConn.
 // The listener is not active until Serve() is called.
 // The tls.Config must not be nil, the quic.Config may be nil.
 func Listen(conn net.PacketConn, tlsConf *tls.Config, config *Config) (Listener, error) {
+	return listen([]net.PacketConn{conn}, tlsConf, config)
+}
+
+// listen listens for QUIC connections on one or more net.PacketConns bound to the same address.
+// Every net.PacketConn is read from in its own go routine.
+func listen(conns []net.PacketConn, tlsConf *tls.Config, config *Config) (Listener, error) {
 	certChain := crypto.NewCertChain(tlsConf)
 	kex, err := crypto.NewCurve25519KEX()
 	if err != nil {
@@ -84,6 +126,9 @@ func Listen(conn net.PacketConn, tlsConf *tls.Config, config *Config) (Listener,
 		return nil, err
 	}
 	config = populateServerConfig(config)
+	for _, c := range conns {
+		enableECN(c)
+	}
 
 	// check if any of the supported versions supports TLS
 	var supportsTLS bool
@@ -95,12 +140,13 @@ func Listen(conn net.PacketConn, tlsConf *tls.Config, config *Config) (Listener,
 	}
 
 	s := &server{
-		conn:                      conn,
+		conn:                      conns[0],
+		additionalConns:           conns[1:],
 		tlsConf:                   tlsConf,
 		config:                    config,
 		certChain:                 certChain,
 		scfg:                      scfg,
-		sessions:                  map[protocol.ConnectionID]packetHandler{},
+		sessions:                  newSessionMap(),
 		newSession:                newSession,
 		deleteClosedSessionsAfter: protocol.ClosedSessionDeleteTimeout,
 		sessionQueue:              make(chan Session, 5),
@@ -112,8 +158,12 @@ func Listen(conn net.PacketConn, tlsConf *tls.Config, config *Config) (Listener,
 			return nil, err
 		}
 	}
-	go s.serve()
-	utils.Debugf("Listening for %s connections on %s", conn.LocalAddr().Network(), conn.LocalAddr().String())
+	s.startWorkers(runtime.GOMAXPROCS(0))
+	go s.serve(s.conn)
+	for _, c := range s.additionalConns {
+		go s.serve(c)
+	}
+	utils.Debugf("Listening for %s connections on %s (%d sockets)", s.conn.LocalAddr().Network(), s.conn.LocalAddr().String(), len(conns))
 	return s, nil
 }
 
@@ -134,11 +184,15 @@ func (s *server) setupTLS() error {
 			case <-s.errorChan:
 				return
 			case sess := <-sessionChan:
-				// TODO: think about what to do with connection ID collisions
 				connID := sess.(*session).connectionID
-				s.sessionsMutex.Lock()
-				s.sessions[connID] = sess
-				s.sessionsMutex.Unlock()
+				if !s.sessions.Add(connID, sess) {
+					// Initial packets for known connection IDs are dropped.
+					// We only get here if another client chose the same connection ID, and both handshakes were handled concurrently.
+					utils.Infof("Connection ID %x is already in use. Closing the new connection.", connID)
+					go sess.run()
+					go sess.Close(qerr.Error(qerr.InternalError, "connection ID collision"))
+					continue
+				}
 				s.runHandshakeAndSession(sess, connID)
 			}
 		}
@@ -195,6 +249,24 @@ func populateServerConfig(config *Config) *Config {
 	if maxReceiveConnectionFlowControlWindow == 0 {
 		maxReceiveConnectionFlowControlWindow = protocol.DefaultMaxReceiveConnectionFlowControlWindowServer
 	}
+	maxIncomingStreams := config.MaxIncomingStreams
+	if maxIncomingStreams <= 0 {
+		maxIncomingStreams = protocol.DefaultMaxIncomingStreams
+	}
+	minPacketSize, maxPacketSize := populatePacketSizes(config.MinPacketSize, config.MaxPacketSize)
+	numSockets := config.NumSockets
+	if numSockets < 1 {
+		numSockets = 1
+	}
+
+	congestionControl := config.CongestionControl
+	if congestionControl == nil {
+		congestionControl = NewCubic
+	}
+	initialPacingBurst := config.InitialPacingBurst
+	if initialPacingBurst <= 0 {
+		initialPacingBurst = protocol.DefaultInitialPacingBurst
+	}
 
 	return &Config{
 		Versions:                              versions,
@@ -204,26 +276,122 @@ func populateServerConfig(config *Config) *Config {
 		KeepAlive:                             config.KeepAlive,
 		MaxReceiveStreamFlowControlWindow:     maxReceiveStreamFlowControlWindow,
 		MaxReceiveConnectionFlowControlWindow: maxReceiveConnectionFlowControlWindow,
+		MaxIncomingStreams:                    maxIncomingStreams,
+		MinPacketSize:                         minPacketSize,
+		MaxPacketSize:                         maxPacketSize,
+		FEC:                                   populateFECConfig(config.FEC),
+		Multipath:                             config.Multipath,
+		NumSockets:                            numSockets,
+		WriteCoalescingDelay:                  config.WriteCoalescingDelay,
+		CongestionControl:                     congestionControl,
+		InitialPacingBurst:                    initialPacingBurst,
+		AckPolicy:                             populateAckPolicy(config.AckPolicy),
 	}
 }
 
-// serve listens on an existing PacketConn
-func (s *server) serve() {
+// startWorkers starts n workers for handling packets, and n workers for handling IETF QUIC Initial packets.
+// The workers return when the server is closed.
+func (s *server) startWorkers(n int) {
+	s.packetQueues = make([]chan serverPacket, n)
+	for i := range s.packetQueues {
+		queue := make(chan serverPacket, protocol.MaxServerUnprocessedPackets)
+		s.packetQueues[i] = queue
+		go s.runPacketWorker(queue)
+	}
+	if s.supportsTLS {
+		s.initialPackets = make(chan initialPacket, protocol.MaxServerUnprocessedInitialPackets)
+		for i := 0; i < n; i++ {
+			go s.runInitialWorker()
+		}
+	}
+}
+
+func (s *server) runPacketWorker(queue <-chan serverPacket) {
 	for {
-		data := getPacketBuffer()
-		data = data[:protocol.MaxReceivePacketSize]
-		// The packet size should not exceed protocol.MaxReceivePacketSize bytes
+		select {
+		case p := <-queue:
+			if err := s.handlePacket(p.pconn, p.remoteAddr, p.data, p.ecn); err != nil {
+				utils.Errorf("error handling packet: %s", err.Error())
+			}
+		case <-s.errorChan:
+			return
+		}
+	}
+}
+
+func (s *server) runInitialWorker() {
+	for {
+		select {
+		case p := <-s.initialPackets:
+			s.serverTLS.HandleInitial(p.remoteAddr, p.header, p.data)
+		case <-s.errorChan:
+			return
+		}
+	}
+}
+
+// packetQueue returns the queue of the worker that handles packets from remoteAddr.
+// All packets from the same address are handled by the same worker, so they are not reordered.
+func (s *server) packetQueue(remoteAddr net.Addr) chan<- serverPacket {
+	if len(s.packetQueues) == 1 {
+		return s.packetQueues[0]
+	}
+	// FNV-1a
+	h := uint32(2166136261)
+	if addr, ok := remoteAddr.(*net.UDPAddr); ok {
+		for _, b := range addr.IP {
+			h = (h ^ uint32(b)) * 16777619
+		}
+		h = (h ^ uint32(addr.Port)) * 16777619
+	} else if remoteAddr != nil {
+		str := remoteAddr.String()
+		for i := 0; i < len(str); i++ {
+			h = (h ^ uint32(str[i])) * 16777619
+		}
+	}
+	return s.packetQueues[h%uint32(len(s.packetQueues))]
+}
+
+// serve reads packets from a PacketConn, and passes them to the packet handling workers.
+// When listening on multiple sockets, it is run once for every socket.
+func (s *server) serve(pconn net.PacketConn) {
+	conn := newBatchConn(pconn)
+	msgs := make([]datagram, protocol.MaxBatchSize)
+	for {
+		for i := range msgs {
+			if msgs[i].data == nil {
+				msgs[i].data = getPacketBuffer(receiveBufferSize(s.config))
+			}
+		}
+		// The packet size should not exceed the receive buffer size
 		// If it does, we only read a truncated packet, which will then end up undecryptable
-		n, remoteAddr, err := s.conn.ReadFrom(data)
+		n, err := conn.ReadBatch(msgs)
 		if err != nil {
-			s.serverError = err
-			close(s.errorChan)
+			// Once one socket fails, the server is closed, which makes the read loops of all other sockets return as well.
+			s.errorOnce.Do(func() {
+				s.serverError = err
+				close(s.errorChan)
+			})
 			_ = s.Close()
 			return
 		}
-		data = data[:n]
-		if err := s.handlePacket(s.conn, remoteAddr, data); err != nil {
-			utils.Errorf("error handling packet: %s", err.Error())
+		for i := 0; i < n; i++ {
+			// the sessions map is shared between all sockets,
+			// so a packet is routed to its session no matter which socket it was received on
+			p := serverPacket{
+				pconn:      pconn,
+				remoteAddr: msgs[i].remoteAddr,
+				data:       msgs[i].data,
+				ecn:        msgs[i].ecn,
+			}
+			select {
+			case s.packetQueue(p.remoteAddr) <- p:
+				// the buffer is now owned by the worker that handles the packet
+			default:
+				// the worker is overloaded
+				putPacketBuffer(p.data)
+			}
+			msgs[i].data = nil
 		}
 	}
 }
@@ -241,28 +409,31 @@ func (s *server) Accept() (Session, error) {
 
 // Close the server
 func (s *server) Close() error {
-	s.sessionsMutex.Lock()
+	s.mutex.Lock()
 	if s.closed {
-		s.sessionsMutex.Unlock()
+		s.mutex.Unlock()
 		return nil
 	}
 	s.closed = true
+	s.mutex.Unlock()
 
 	var wg sync.WaitGroup
-	for _, session := range s.sessions {
-		if session != nil {
-			wg.Add(1)
-			go func(sess packetHandler) {
-				// session.Close() blocks until the CONNECTION_CLOSE has been sent and the run-loop has stopped
-				_ = sess.Close(nil)
-				wg.Done()
-			}(session)
-		}
+	for _, session := range s.sessions.Sessions() {
+		wg.Add(1)
+		go func(sess packetHandler) {
+			// session.Close() blocks until the CONNECTION_CLOSE has been sent and the run-loop has stopped
+			_ = sess.Close(nil)
+			wg.Done()
+		}(session)
 	}
-	s.sessionsMutex.Unlock()
 	wg.Wait()
 
 	err := s.conn.Close()
+	for _, c := range s.additionalConns {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}
 	<-s.errorChan // wait for serve() to return
 	return err
 }
@@ -272,11 +443,13 @@ func (s *server) Addr() net.Addr {
 	return s.conn.LocalAddr()
 }
 
-func (s *server) handlePacket(pconn net.PacketConn, remoteAddr net.Addr, packet []byte) error {
-	rcvTime := time.Now()
+func (s *server) handlePacket(pconn net.PacketConn, remoteAddr net.Addr, packet []byte, ecn protocol.ECN) error {
+	p := getReceivedPacket()
+	p.rcvTime = time.Now()
 
 	r := bytes.NewReader(packet)
-	hdr, err := wire.ParseHeaderSentByClient(r)
+	hdr := p.header
+	err := wire.ParseHeaderSentByClientInto(r, hdr)
 	if err != nil {
 		return qerr.Error(qerr.InvalidPacketHeader, err.Error())
 	}
@@ -284,17 +457,21 @@ func (s *server) handlePacket(pconn net.PacketConn, remoteAddr net.Addr, packet
 	packetData := packet[len(packet)-r.Len():]
 	connID := hdr.ConnectionID
 
+	session, sessionKnown := s.sessions.Get(connID)
+
 	if hdr.Type == protocol.PacketTypeInitial {
-		if s.supportsTLS {
-			go s.serverTLS.HandleInitial(remoteAddr, hdr, packetData)
+		// Initial packets for known connection IDs are either retransmissions,
+		// or another client chose a connection ID that is already in use.
+		if s.supportsTLS && !sessionKnown {
+			select {
+			case s.initialPackets <- initialPacket{remoteAddr: remoteAddr, header: hdr, data: packetData}:
+			default:
+				utils.Debugf("Dropping Initial packet for connection %x. Too many handshakes in progress.", connID)
+			}
 		}
 		return nil
 	}
 
-	s.sessionsMutex.RLock()
-	session, sessionKnown := s.sessions[connID]
-	s.sessionsMutex.RUnlock()
-
 	if sessionKnown && session == nil {
 		// Late packet for closed session
 		return nil
@@ -331,6 +508,15 @@ func (s *server) handlePacket(pconn net.PacketConn, remoteAddr net.Addr, packet
 		return nil
 	}
 
+	// The client sets the Version Flag until it receives the first packet from the server.
+	// If such a packet arrives from a different address than the one the session was created for,
+	// two clients chose the same connection ID. The second one is rejected with a Public Reset.
+	if sessionKnown && hdr.VersionFlag && !sameAddr(session.RemoteAddr(), remoteAddr) {
+		utils.Infof("Connection ID %x is already in use. Sending a Public Reset to %s.", connID, remoteAddr)
+		_, err = pconn.WriteTo(wire.WritePublicReset(connID, 0, 0), remoteAddr)
+		return err
+	}
+
 	// send a Version Negotiation Packet if the client is speaking a different protocol version
 	// since the client send a Public Header (only gQUIC has a Version Flag), we need to send a gQUIC Version Negotiation Packet
 	if hdr.VersionFlag && !protocol.IsSupportedVersion(s.config.Versions, hdr.Version) {
@@ -367,18 +553,18 @@ func (s *server) handlePacket(pconn net.PacketConn, remoteAddr net.Addr, packet
 		if err != nil {
 			return err
 		}
-		s.sessionsMutex.Lock()
-		s.sessions[connID] = session
-		s.sessionsMutex.Unlock()
-
+		if !s.sessions.Add(connID, session) {
+			// Another worker created a session for a packet from a different address.
+			utils.Infof("Connection ID %x is already in use. Sending a Public Reset to %s.", connID, remoteAddr)
+			_, err = pconn.WriteTo(wire.WritePublicReset(connID, 0, 0), remoteAddr)
+			return err
+		}
 		s.runHandshakeAndSession(session, connID)
 	}
-	session.handlePacket(&receivedPacket{
-		remoteAddr: remoteAddr,
-		header:     hdr,
-		data:       packetData,
-		rcvTime:    rcvTime,
-	})
+	p.remoteAddr = remoteAddr
+	p.data = packetData
+	p.ecn = ecn
+	session.handlePacket(p)
 	return nil
 }
 
@@ -398,13 +584,21 @@ func (s *server) runHandshakeAndSession(session packetHandler, connID protocol.C
 }
 
 func (s *server) removeConnection(id protocol.ConnectionID) {
-	s.sessionsMutex.Lock()
-	s.sessions[id] = nil
-	s.sessionsMutex.Unlock()
-
+	s.sessions.Retire(id)
 	time.AfterFunc(s.deleteClosedSessionsAfter, func() {
-		s.sessionsMutex.Lock()
-		delete(s.sessions, id)
-		s.sessionsMutex.Unlock()
+		s.sessions.Remove(id)
 	})
 }
+
+// sameAddr checks if two addresses are equal
+func sameAddr(a, b net.Addr) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	ua, ok1 := a.(*net.UDPAddr)
+	ub, ok2 := b.(*net.UDPAddr)
+	if ok1 && ok2 {
+		return ua.Port == ub.Port && ua.IP.Equal(ub.IP)
+	}
+	return a.String() == b.String()
+}
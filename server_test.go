@@ -23,6 +23,7 @@ import (
 
 type mockSession struct {
 	connectionID  protocol.ConnectionID
+	remoteAddr    net.Addr
 	packetCount   int
 	closed        bool
 	closeReason   error
@@ -60,9 +61,14 @@ func (s *mockSession) OpenStream() (Stream, error) {
 func (s *mockSession) AcceptStream() (Stream, error)    { panic("not implemented") }
 func (s *mockSession) OpenStreamSync() (Stream, error)  { panic("not implemented") }
 func (s *mockSession) LocalAddr() net.Addr              { panic("not implemented") }
-func (s *mockSession) RemoteAddr() net.Addr             { panic("not implemented") }
+func (s *mockSession) RemoteAddr() net.Addr             { return s.remoteAddr }
 func (*mockSession) Context() context.Context           { panic("not implemented") }
 func (*mockSession) ConnectionState() ConnectionState   { panic("not implemented") }
+func (*mockSession) AddPath(net.PacketConn) error       { panic("not implemented") }
+func (*mockSession) SetMaxIncomingStreams(int) error    { panic("not implemented") }
+func (*mockSession) StreamIDBlockedCount() uint64       { panic("not implemented") }
+func (*mockSession) BandwidthEstimate() Bandwidth       { panic("not implemented") }
+func (*mockSession) SetPeerAckPolicy(*AckPolicy) error  { panic("not implemented") }
 func (*mockSession) GetVersion() protocol.VersionNumber { return protocol.VersionWhatever }
 func (s *mockSession) handshakeStatus() <-chan error    { return s.handshakeChan }
 func (*mockSession) getCryptoStream() cryptoStreamI     { panic("not implemented") }
@@ -70,7 +76,7 @@ func (*mockSession) getCryptoStream() cryptoStreamI     { panic("not implemented
 var _ Session = &mockSession{}
 
 func newMockSession(
-	_ connection,
+	conn connection,
 	_ protocol.VersionNumber,
 	connectionID protocol.ConnectionID,
 	_ *handshake.ServerConfig,
@@ -82,6 +88,9 @@ func newMockSession(
 		handshakeChan: make(chan error),
 		stopRunLoop:   make(chan struct{}),
 	}
+	if conn != nil {
+		s.remoteAddr = conn.RemoteAddr()
+	}
 	return &s, nil
 }
 
@@ -105,9 +114,14 @@ var _ = Describe("Server", func() {
 			connID      = protocol.ConnectionID(0x4cfa9f9b668619f6)
 		)
 
+		getSession := func(id protocol.ConnectionID) packetHandler {
+			sess, _ := serv.sessions.Get(id)
+			return sess
+		}
+
 		BeforeEach(func() {
 			serv = &server{
-				sessions:     make(map[protocol.ConnectionID]packetHandler),
+				sessions:     newSessionMap(),
 				newSession:   newMockSession,
 				conn:         conn,
 				config:       config,
@@ -130,10 +144,10 @@ var _ = Describe("Server", func() {
 		})
 
 		It("creates new sessions", func() {
-			err := serv.handlePacket(nil, nil, firstPacket)
+			err := serv.handlePacket(nil, nil, firstPacket, protocol.ECNNon)
 			Expect(err).ToNot(HaveOccurred())
-			Expect(serv.sessions).To(HaveLen(1))
-			sess := serv.sessions[connID].(*mockSession)
+			Expect(serv.sessions.Len()).To(Equal(1))
+			sess := getSession(connID).(*mockSession)
 			Expect(sess.connectionID).To(Equal(connID))
 			Expect(sess.packetCount).To(Equal(1))
 		})
@@ -146,10 +160,10 @@ var _ = Describe("Server", func() {
 				acceptedSess, err = serv.Accept()
 				Expect(err).ToNot(HaveOccurred())
 			}()
-			err := serv.handlePacket(nil, nil, firstPacket)
+			err := serv.handlePacket(nil, nil, firstPacket, protocol.ECNNon)
 			Expect(err).ToNot(HaveOccurred())
-			Expect(serv.sessions).To(HaveLen(1))
-			sess := serv.sessions[connID].(*mockSession)
+			Expect(serv.sessions.Len()).To(Equal(1))
+			sess := getSession(connID).(*mockSession)
 			Consistently(func() Session { return acceptedSess }).Should(BeNil())
 			close(sess.handshakeChan)
 			Eventually(func() Session { return acceptedSess }).Should(Equal(sess))
@@ -163,74 +177,197 @@ var _ = Describe("Server", func() {
 				serv.Accept()
 				accepted = true
 			}()
-			err := serv.handlePacket(nil, nil, firstPacket)
+			err := serv.handlePacket(nil, nil, firstPacket, protocol.ECNNon)
 			Expect(err).ToNot(HaveOccurred())
-			Expect(serv.sessions).To(HaveLen(1))
-			sess := serv.sessions[connID].(*mockSession)
+			Expect(serv.sessions.Len()).To(Equal(1))
+			sess := getSession(connID).(*mockSession)
 			sess.handshakeChan <- errors.New("handshake failed")
 			Consistently(func() bool { return accepted }).Should(BeFalse())
 			close(done)
 		})
 
 		It("assigns packets to existing sessions", func() {
-			err := serv.handlePacket(nil, nil, firstPacket)
+			err := serv.handlePacket(nil, nil, firstPacket, protocol.ECNNon)
+			Expect(err).ToNot(HaveOccurred())
+			err = serv.handlePacket(nil, nil, []byte{0x08, 0x4c, 0xfa, 0x9f, 0x9b, 0x66, 0x86, 0x19, 0xf6, 0x01}, protocol.ECNNon)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(serv.sessions.Len()).To(Equal(1))
+			Expect(getSession(connID).(*mockSession).connectionID).To(Equal(connID))
+			Expect(getSession(connID).(*mockSession).packetCount).To(Equal(2))
+		})
+
+		It("sends a Public Reset to a client that uses the connection ID of another client", func() {
+			addr1 := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1234}
+			addr2 := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 2), Port: 1234}
+			err := serv.handlePacket(conn, addr1, firstPacket, protocol.ECNNon)
+			Expect(err).ToNot(HaveOccurred())
+			err = serv.handlePacket(conn, addr2, firstPacket, protocol.ECNNon)
 			Expect(err).ToNot(HaveOccurred())
-			err = serv.handlePacket(nil, nil, []byte{0x08, 0x4c, 0xfa, 0x9f, 0x9b, 0x66, 0x86, 0x19, 0xf6, 0x01})
+			Expect(conn.dataWrittenTo).To(Equal(addr2))
+			hdr, err := wire.ParseHeaderSentByServer(bytes.NewReader(conn.dataWritten.Bytes()), protocol.VersionUnknown)
 			Expect(err).ToNot(HaveOccurred())
-			Expect(serv.sessions).To(HaveLen(1))
-			Expect(serv.sessions[connID].(*mockSession).connectionID).To(Equal(connID))
-			Expect(serv.sessions[connID].(*mockSession).packetCount).To(Equal(2))
+			Expect(hdr.ResetFlag).To(BeTrue())
+			Expect(hdr.ConnectionID).To(Equal(connID))
+			Expect(serv.sessions.Len()).To(Equal(1))
+			sess := getSession(connID).(*mockSession)
+			Expect(sess.remoteAddr).To(Equal(addr1))
+			Expect(sess.packetCount).To(Equal(1))
+		})
+
+		It("passes retransmitted handshake packets from the same address to the session", func() {
+			addr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1234}
+			err := serv.handlePacket(conn, addr, firstPacket, protocol.ECNNon)
+			Expect(err).ToNot(HaveOccurred())
+			err = serv.handlePacket(conn, &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1234}, firstPacket, protocol.ECNNon)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(conn.dataWritten.Len()).To(BeZero())
+			Expect(getSession(connID).(*mockSession).packetCount).To(Equal(2))
+		})
+
+		Context("handling IETF QUIC Initial packets", func() {
+			var initial []byte
+
+			BeforeEach(func() {
+				serv.supportsTLS = true
+				serv.initialPackets = make(chan initialPacket, 1)
+				b := &bytes.Buffer{}
+				hdr := wire.Header{
+					Type:         protocol.PacketTypeInitial,
+					IsLongHeader: true,
+					ConnectionID: connID,
+					PacketNumber: 1,
+					Version:      protocol.VersionTLS,
+				}
+				Expect(hdr.Write(b, protocol.PerspectiveClient, protocol.VersionTLS)).To(Succeed())
+				b.Write(bytes.Repeat([]byte{0}, protocol.MinInitialPacketSize))
+				initial = b.Bytes()
+			})
+
+			It("queues Initial packets for the handshake workers", func() {
+				err := serv.handlePacket(conn, udpAddr, initial, protocol.ECNNon)
+				Expect(err).ToNot(HaveOccurred())
+				var p initialPacket
+				Expect(serv.initialPackets).To(Receive(&p))
+				Expect(p.remoteAddr).To(Equal(udpAddr))
+				Expect(p.header.ConnectionID).To(Equal(connID))
+				Expect(serv.sessions.Len()).To(BeZero())
+			})
+
+			It("drops Initial packets if too many handshakes are in progress", func() {
+				err := serv.handlePacket(conn, udpAddr, initial, protocol.ECNNon)
+				Expect(err).ToNot(HaveOccurred())
+				err = serv.handlePacket(conn, udpAddr, initial, protocol.ECNNon)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(serv.initialPackets).To(HaveLen(1))
+			})
+
+			It("drops Initial packets for connection IDs that are already in use", func() {
+				session, _ := newMockSession(nil, 0, connID, nil, nil, nil)
+				serv.sessions.Add(connID, session)
+				err := serv.handlePacket(conn, udpAddr, initial, protocol.ECNNon)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(serv.initialPackets).To(BeEmpty())
+				Expect(session.(*mockSession).packetCount).To(BeZero())
+			})
+		})
+
+		It("handles all packets from the same address on the same worker", func() {
+			serv.startWorkers(8)
+			defer close(serv.errorChan) // stop the workers
+			queues := make(map[chan<- serverPacket]struct{})
+			for i := 0; i < 100; i++ {
+				addr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, byte(i)), Port: 1000 + i}
+				queue := serv.packetQueue(addr)
+				Expect(serv.packetQueue(&net.UDPAddr{IP: net.IPv4(192, 168, 0, byte(i)), Port: 1000 + i})).To(Equal(queue))
+				queues[queue] = struct{}{}
+			}
+			Expect(len(queues)).To(BeNumerically(">", 1))
 		})
 
 		It("closes and deletes sessions", func() {
 			serv.deleteClosedSessionsAfter = time.Second // make sure that the nil value for the closed session doesn't get deleted in this test
 			nullAEAD, err := crypto.NewNullAEAD(protocol.PerspectiveServer, connID, protocol.VersionWhatever)
 			Expect(err).ToNot(HaveOccurred())
-			err = serv.handlePacket(nil, nil, append(firstPacket, nullAEAD.Seal(nil, nil, 0, firstPacket)...))
+			err = serv.handlePacket(nil, nil, append(firstPacket, nullAEAD.Seal(nil, nil, 0, firstPacket)...), protocol.ECNNon)
 			Expect(err).ToNot(HaveOccurred())
-			Expect(serv.sessions).To(HaveLen(1))
-			Expect(serv.sessions[connID]).ToNot(BeNil())
+			Expect(serv.sessions.Len()).To(Equal(1))
+			Expect(getSession(connID)).ToNot(BeNil())
 			// make session.run() return
-			serv.sessions[connID].(*mockSession).stopRunLoop <- struct{}{}
+			getSession(connID).(*mockSession).stopRunLoop <- struct{}{}
 			// The server should now have closed the session, leaving a nil value in the sessions map
-			Consistently(func() map[protocol.ConnectionID]packetHandler { return serv.sessions }).Should(HaveLen(1))
-			Expect(serv.sessions[connID]).To(BeNil())
+			Consistently(func() int { return serv.sessions.Len() }).Should(Equal(1))
+			Expect(getSession(connID)).To(BeNil())
 		})
 
 		It("deletes nil session entries after a wait time", func() {
 			serv.deleteClosedSessionsAfter = 25 * time.Millisecond
 			nullAEAD, err := crypto.NewNullAEAD(protocol.PerspectiveServer, connID, protocol.VersionWhatever)
 			Expect(err).ToNot(HaveOccurred())
-			err = serv.handlePacket(nil, nil, append(firstPacket, nullAEAD.Seal(nil, nil, 0, firstPacket)...))
+			err = serv.handlePacket(nil, nil, append(firstPacket, nullAEAD.Seal(nil, nil, 0, firstPacket)...), protocol.ECNNon)
 			Expect(err).ToNot(HaveOccurred())
-			Expect(serv.sessions).To(HaveLen(1))
-			Expect(serv.sessions).To(HaveKey(connID))
+			Expect(serv.sessions.Len()).To(Equal(1))
+			_, ok := serv.sessions.Get(connID)
+			Expect(ok).To(BeTrue())
 			// make session.run() return
-			serv.sessions[connID].(*mockSession).stopRunLoop <- struct{}{}
+			getSession(connID).(*mockSession).stopRunLoop <- struct{}{}
 			Eventually(func() bool {
-				serv.sessionsMutex.Lock()
-				_, ok := serv.sessions[connID]
-				serv.sessionsMutex.Unlock()
+				_, ok := serv.sessions.Get(connID)
 				return ok
 			}).Should(BeFalse())
 		})
 
 		It("closes sessions and the connection when Close is called", func() {
-			go serv.serve()
+			go serv.serve(conn)
 			session, _ := newMockSession(nil, 0, 0, nil, nil, nil)
-			serv.sessions[1] = session
+			serv.sessions.Add(1, session)
 			err := serv.Close()
 			Expect(err).NotTo(HaveOccurred())
 			Expect(session.(*mockSession).closed).To(BeTrue())
 			Expect(conn.closed).To(BeTrue())
 		})
 
+		It("routes packets received on different sockets to the same session", func() {
+			conn2 := newMockPacketConn()
+			conn2.addr = &net.UDPAddr{}
+			serv.additionalConns = []net.PacketConn{conn2}
+			serv.startWorkers(2)
+			go serv.serve(conn)
+			go serv.serve(conn2)
+			conn2.dataToRead <- firstPacket
+			Eventually(func() bool {
+				_, ok := serv.sessions.Get(connID)
+				return ok
+			}).Should(BeTrue())
+			conn.dataToRead <- firstPacket
+			sess := getSession(connID).(*mockSession)
+			Eventually(func() int { return sess.packetCount }).Should(Equal(2))
+			Expect(serv.Close()).To(Succeed())
+			Expect(conn.closed).To(BeTrue())
+			Expect(conn2.closed).To(BeTrue())
+		})
+
+		It("only reports the first error if multiple sockets fail", func(done Done) {
+			conn2 := newMockPacketConn()
+			testErr := errors.New("connection error")
+			conn.readErr = testErr
+			conn2.readErr = errors.New("another connection error")
+			serv.additionalConns = []net.PacketConn{conn2}
+			go serv.serve(conn)
+			_, err := serv.Accept()
+			Expect(err).To(MatchError(testErr))
+			go serv.serve(conn2)
+			Expect(serv.Close()).To(Succeed())
+			_, err = serv.Accept()
+			Expect(err).To(MatchError(testErr))
+			close(done)
+		}, 0.5)
+
 		It("ignores packets for closed sessions", func() {
-			serv.sessions[connID] = nil
-			err := serv.handlePacket(nil, nil, []byte{0x08, 0x4c, 0xfa, 0x9f, 0x9b, 0x66, 0x86, 0x19, 0xf6, 0x01})
+			serv.sessions.Add(connID, nil)
+			err := serv.handlePacket(nil, nil, []byte{0x08, 0x4c, 0xfa, 0x9f, 0x9b, 0x66, 0x86, 0x19, 0xf6, 0x01}, protocol.ECNNon)
 			Expect(err).ToNot(HaveOccurred())
-			Expect(serv.sessions).To(HaveLen(1))
-			Expect(serv.sessions[connID]).To(BeNil())
+			Expect(serv.sessions.Len()).To(Equal(1))
+			Expect(getSession(connID)).To(BeNil())
 		})
 
 		It("works if no quic.Config is given", func(done Done) {
@@ -259,7 +396,7 @@ var _ = Describe("Server", func() {
 		It("errors when encountering a connection error", func(done Done) {
 			testErr := errors.New("connection error")
 			conn.readErr = testErr
-			go serv.serve()
+			go serv.serve(conn)
 			_, err := serv.Accept()
 			Expect(err).To(MatchError(testErr))
 			Expect(serv.Close()).To(Succeed())
@@ -268,63 +405,63 @@ var _ = Describe("Server", func() {
 
 		It("closes all sessions when encountering a connection error", func() {
 			session, _ := newMockSession(nil, 0, 0, nil, nil, nil)
-			serv.sessions[0x12345] = session
-			Expect(serv.sessions[0x12345].(*mockSession).closed).To(BeFalse())
+			serv.sessions.Add(0x12345, session)
+			Expect(getSession(0x12345).(*mockSession).closed).To(BeFalse())
 			testErr := errors.New("connection error")
 			conn.readErr = testErr
-			go serv.serve()
-			Eventually(func() Session { return serv.sessions[connID] }).Should(BeNil())
+			go serv.serve(conn)
+			Eventually(func() Session { return getSession(connID) }).Should(BeNil())
 			Eventually(func() bool { return session.(*mockSession).closed }).Should(BeTrue())
 			Expect(serv.Close()).To(Succeed())
 		})
 
 		It("ignores delayed packets with mismatching versions", func() {
-			err := serv.handlePacket(nil, nil, firstPacket)
+			err := serv.handlePacket(nil, nil, firstPacket, protocol.ECNNon)
 			Expect(err).ToNot(HaveOccurred())
-			Expect(serv.sessions[connID].(*mockSession).packetCount).To(Equal(1))
+			Expect(getSession(connID).(*mockSession).packetCount).To(Equal(1))
 			b := &bytes.Buffer{}
 			// add an unsupported version
 			data := []byte{0x09, 0x4c, 0xfa, 0x9f, 0x9b, 0x66, 0x86, 0x19, 0xf6}
 			utils.BigEndian.WriteUint32(b, uint32(protocol.SupportedVersions[0]+1))
 			data = append(append(data, b.Bytes()...), 0x01)
-			err = serv.handlePacket(nil, nil, data)
+			err = serv.handlePacket(nil, nil, data, protocol.ECNNon)
 			Expect(err).ToNot(HaveOccurred())
 			// if we didn't ignore the packet, the server would try to send a version negotation packet, which would make the test panic because it doesn't have a udpConn
 			Expect(conn.dataWritten.Bytes()).To(BeEmpty())
 			// make sure the packet was *not* passed to session.handlePacket()
-			Expect(serv.sessions[connID].(*mockSession).packetCount).To(Equal(1))
+			Expect(getSession(connID).(*mockSession).packetCount).To(Equal(1))
 		})
 
 		It("errors on invalid public header", func() {
-			err := serv.handlePacket(nil, nil, nil)
+			err := serv.handlePacket(nil, nil, nil, protocol.ECNNon)
 			Expect(err.(*qerr.QuicError).ErrorCode).To(Equal(qerr.InvalidPacketHeader))
 		})
 
 		It("ignores public resets for unknown connections", func() {
-			err := serv.handlePacket(nil, nil, wire.WritePublicReset(999, 1, 1337))
+			err := serv.handlePacket(nil, nil, wire.WritePublicReset(999, 1, 1337), protocol.ECNNon)
 			Expect(err).ToNot(HaveOccurred())
-			Expect(serv.sessions).To(BeEmpty())
+			Expect(serv.sessions.Len()).To(BeZero())
 		})
 
 		It("ignores public resets for known connections", func() {
-			err := serv.handlePacket(nil, nil, firstPacket)
-			Expect(serv.sessions).To(HaveLen(1))
-			Expect(serv.sessions[connID].(*mockSession).packetCount).To(Equal(1))
-			err = serv.handlePacket(nil, nil, wire.WritePublicReset(connID, 1, 1337))
+			err := serv.handlePacket(nil, nil, firstPacket, protocol.ECNNon)
+			Expect(serv.sessions.Len()).To(Equal(1))
+			Expect(getSession(connID).(*mockSession).packetCount).To(Equal(1))
+			err = serv.handlePacket(nil, nil, wire.WritePublicReset(connID, 1, 1337), protocol.ECNNon)
 			Expect(err).ToNot(HaveOccurred())
-			Expect(serv.sessions).To(HaveLen(1))
-			Expect(serv.sessions[connID].(*mockSession).packetCount).To(Equal(1))
+			Expect(serv.sessions.Len()).To(Equal(1))
+			Expect(getSession(connID).(*mockSession).packetCount).To(Equal(1))
 		})
 
 		It("ignores invalid public resets for known connections", func() {
-			err := serv.handlePacket(nil, nil, firstPacket)
-			Expect(serv.sessions).To(HaveLen(1))
-			Expect(serv.sessions[connID].(*mockSession).packetCount).To(Equal(1))
+			err := serv.handlePacket(nil, nil, firstPacket, protocol.ECNNon)
+			Expect(serv.sessions.Len()).To(Equal(1))
+			Expect(getSession(connID).(*mockSession).packetCount).To(Equal(1))
 			data := wire.WritePublicReset(connID, 1, 1337)
-			err = serv.handlePacket(nil, nil, data[:len(data)-2])
+			err = serv.handlePacket(nil, nil, data[:len(data)-2], protocol.ECNNon)
 			Expect(err).ToNot(HaveOccurred())
-			Expect(serv.sessions).To(HaveLen(1))
-			Expect(serv.sessions[connID].(*mockSession).packetCount).To(Equal(1))
+			Expect(serv.sessions.Len()).To(Equal(1))
+			Expect(getSession(connID).(*mockSession).packetCount).To(Equal(1))
 		})
 
 		It("doesn't try to process a packet after sending a gQUIC Version Negotiation Packet", func() {
@@ -338,7 +475,7 @@ var _ = Describe("Server", func() {
 			}
 			hdr.Write(b, protocol.PerspectiveClient, 13 /* not a valid QUIC version */)
 			b.Write(bytes.Repeat([]byte{0}, protocol.MinClientHelloSize)) // add a fake CHLO
-			err := serv.handlePacket(conn, nil, b.Bytes())
+			err := serv.handlePacket(conn, nil, b.Bytes(), protocol.ECNNon)
 			Expect(conn.dataWritten.Bytes()).ToNot(BeEmpty())
 			Expect(err).ToNot(HaveOccurred())
 		})
@@ -353,7 +490,7 @@ var _ = Describe("Server", func() {
 			}
 			hdr.Write(b, protocol.PerspectiveClient, 13 /* not a valid QUIC version */)
 			b.Write(bytes.Repeat([]byte{0}, protocol.MinClientHelloSize-1)) // this packet is 1 byte too small
-			err := serv.handlePacket(conn, udpAddr, b.Bytes())
+			err := serv.handlePacket(conn, udpAddr, b.Bytes(), protocol.ECNNon)
 			Expect(err).To(MatchError("dropping small packet with unknown version"))
 			Expect(conn.dataWritten.Len()).Should(BeZero())
 		})
@@ -362,12 +499,20 @@ var _ = Describe("Server", func() {
 	It("setups with the right values", func() {
 		supportedVersions := []protocol.VersionNumber{1, 3, 5}
 		acceptCookie := func(_ net.Addr, _ *Cookie) bool { return true }
+		congestionControl := NewFixedWindow(10000)
 		config := Config{
-			Versions:         supportedVersions,
-			AcceptCookie:     acceptCookie,
-			HandshakeTimeout: 1337 * time.Hour,
-			IdleTimeout:      42 * time.Minute,
-			KeepAlive:        true,
+			Versions:             supportedVersions,
+			AcceptCookie:         acceptCookie,
+			HandshakeTimeout:     1337 * time.Hour,
+			IdleTimeout:          42 * time.Minute,
+			KeepAlive:            true,
+			MaxIncomingStreams:   1234,
+			MinPacketSize:        1300,
+			MaxPacketSize:        1400,
+			NumSockets:           4,
+			WriteCoalescingDelay: 10 * time.Millisecond,
+			CongestionControl:    congestionControl,
+			InitialPacingBurst:   20,
 		}
 		ln, err := Listen(conn, &tls.Config{}, &config)
 		Expect(err).ToNot(HaveOccurred())
@@ -380,6 +525,13 @@ var _ = Describe("Server", func() {
 		Expect(server.config.IdleTimeout).To(Equal(42 * time.Minute))
 		Expect(reflect.ValueOf(server.config.AcceptCookie)).To(Equal(reflect.ValueOf(acceptCookie)))
 		Expect(server.config.KeepAlive).To(BeTrue())
+		Expect(server.config.MaxIncomingStreams).To(Equal(1234))
+		Expect(server.config.MinPacketSize).To(BeEquivalentTo(1300))
+		Expect(server.config.MaxPacketSize).To(BeEquivalentTo(1400))
+		Expect(server.config.NumSockets).To(Equal(4))
+		Expect(server.config.WriteCoalescingDelay).To(Equal(10 * time.Millisecond))
+		Expect(reflect.ValueOf(server.config.CongestionControl)).To(Equal(reflect.ValueOf(congestionControl)))
+		Expect(server.config.InitialPacingBurst).To(Equal(20))
 	})
 
 	It("fills in default values if options are not set in the Config", func() {
@@ -391,6 +543,12 @@ var _ = Describe("Server", func() {
 		Expect(server.config.IdleTimeout).To(Equal(protocol.DefaultIdleTimeout))
 		Expect(reflect.ValueOf(server.config.AcceptCookie)).To(Equal(reflect.ValueOf(defaultAcceptCookie)))
 		Expect(server.config.KeepAlive).To(BeFalse())
+		Expect(server.config.MaxIncomingStreams).To(Equal(protocol.DefaultMaxIncomingStreams))
+		Expect(server.config.MinPacketSize).To(BeEquivalentTo(protocol.MaxPacketSize))
+		Expect(server.config.MaxPacketSize).To(BeEquivalentTo(protocol.MaxReceivePacketSize))
+		Expect(server.config.NumSockets).To(Equal(1))
+		Expect(reflect.ValueOf(server.config.CongestionControl)).To(Equal(reflect.ValueOf(NewCubic)))
+		Expect(server.config.InitialPacingBurst).To(Equal(protocol.DefaultInitialPacingBurst))
 	})
 
 	It("listens on a given address", func() {
@@ -523,7 +681,7 @@ var _ = Describe("Server", func() {
 		Eventually(func() int { return conn.dataWritten.Len() }).ShouldNot(BeZero())
 		Expect(conn.dataWrittenTo).To(Equal(udpAddr))
 		Expect(conn.dataWritten.Bytes()[0] & 0x02).ToNot(BeZero()) // check that the ResetFlag is set
-		Expect(ln.(*server).sessions).To(BeEmpty())
+		Expect(ln.(*server).sessions.Len()).To(BeZero())
 	})
 })
 
@@ -66,8 +66,15 @@ func newServerTLS(
 		params: &handshake.TransportParameters{
 			StreamFlowControlWindow:     protocol.ReceiveStreamFlowControlWindow,
 			ConnectionFlowControlWindow: protocol.ReceiveConnectionFlowControlWindow,
-			MaxStreams:                  protocol.MaxIncomingStreams,
+			MaxStreams:                  uint32(config.MaxIncomingStreams),
+			MaxBidiStreamID:             initialMaxIncomingStreamID(config.MaxIncomingStreams, protocol.PerspectiveServer),
 			IdleTimeout:                 config.IdleTimeout,
+			FEC:                         config.FEC != nil,
+			Multipath:                   config.Multipath != nil,
+			MaxAckDelay:                 config.AckPolicy.MaxAckDelay,
+			AckFrequency:                true,
+			ExpiredStreamData:           true,
+			MaxPacketSize:               receiveBufferSize(config),
 		},
 	}
 	s.newMintConn = s.newMintConnImpl
@@ -32,9 +32,9 @@ var _ = Describe("Stateless TLS handling", func() {
 		mintTLS = mockhandshake.NewMockMintTLS(mockCtrl)
 		extHandler = mocks.NewMockTLSExtensionHandler(mockCtrl)
 		conn = newMockPacketConn()
-		config := &Config{
+		config := populateServerConfig(&Config{
 			Versions: []protocol.VersionNumber{protocol.VersionTLS},
-		}
+		})
 		var err error
 		server, sessionChan, err = newServerTLS(conn, config, nil, testdata.GetTLSConfig())
 		Expect(err).ToNot(HaveOccurred())
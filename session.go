@@ -1,16 +1,21 @@
 package quic
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"errors"
+	"fmt"
+	"math"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/lucas-clemente/quic-go/internal/ackhandler"
 	"github.com/lucas-clemente/quic-go/internal/congestion"
 	"github.com/lucas-clemente/quic-go/internal/crypto"
+	"github.com/lucas-clemente/quic-go/internal/fec"
 	"github.com/lucas-clemente/quic-go/internal/flowcontrol"
 	"github.com/lucas-clemente/quic-go/internal/handshake"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
@@ -36,6 +41,8 @@ type streamManager interface {
 	OpenStreamSync() (Stream, error)
 	AcceptStream() (Stream, error)
 	DeleteStream(protocol.StreamID) error
+	SetMaxIncomingStreams(int) error
+	HandleMaxStreamIDFrame(*wire.MaxStreamIDFrame) error
 	UpdateLimits(*handshake.TransportParameters)
 	CloseWithError(error)
 }
@@ -45,6 +52,10 @@ type receivedPacket struct {
 	header     *wire.Header
 	data       []byte
 	rcvTime    time.Time
+	ecn        protocol.ECN
+
+	// hdr is the storage for the header of packets obtained from getReceivedPacket
+	hdr wire.Header
 }
 
 var (
@@ -57,8 +68,26 @@ type closeError struct {
 	remote bool
 }
 
+type addPathRequest struct {
+	conn net.PacketConn
+	err  chan<- error
+}
+
+type ackPolicyRequest struct {
+	policy *AckPolicy
+	err    chan<- error
+}
+
 // A Session is a QUIC session
 type session struct {
+	// streamIDBlockedCount is the number of STREAM_ID_BLOCKED frames received. It must be accessed atomically.
+	// It is the first field of the struct, so that it is 64 bit aligned on 32 bit platforms.
+	streamIDBlockedCount uint64
+	// bandwidthEstimate is the bandwidth estimate of the congestion controller(s), in bits per second. It must be accessed atomically.
+	bandwidthEstimate uint64
+	// streamDataPerPacket is the amount of stream data that fits into a packet of the current maximum packet size. It must be accessed atomically.
+	streamDataPerPacket uint64
+
 	connectionID protocol.ConnectionID
 	perspective  protocol.Perspective
 	version      protocol.VersionNumber
@@ -66,6 +95,26 @@ type session struct {
 
 	conn connection
 
+	// paths are the additional paths of a multipath connection, indexed by their path ID.
+	// The initial path is not contained in this map. It uses the conn, sentPacketHandler and receivedPacketHandler of the session.
+	paths      map[protocol.PathID]*path
+	nextPathID protocol.PathID
+	// addPathChan passes the paths added by the application to the run loop
+	addPathChan chan addPathRequest
+
+	// ackPolicy is the policy used to acknowledge packets on all paths.
+	// It is initialized from the config, and can be changed by the peer using ACK_FREQUENCY frames.
+	ackPolicy AckPolicy
+	// nextPeerAckFrequencySeq is the lowest sequence number of an ACK_FREQUENCY frame that we still accept from the peer
+	nextPeerAckFrequencySeq uint64
+	// nextAckFrequencySeq is the sequence number of the next ACK_FREQUENCY frame that we send
+	nextAckFrequencySeq uint64
+	// peerMaxAckDelay is the maximum time that the peer delays its ACKs.
+	// It is zero, if the peer didn't announce a value.
+	peerMaxAckDelay time.Duration
+	// ackPolicyChan passes the ACK policies requested by the application to the run loop
+	ackPolicyChan chan ackPolicyRequest
+
 	streamsMap   streamManager
 	cryptoStream cryptoStreamI
 
@@ -80,6 +129,27 @@ type session struct {
 	unpacker unpacker
 	packer   *packetPacker
 
+	// fecEncoder generates the repair packets for the packets we send.
+	// It is only set if both peers enabled FEC.
+	fecEncoder *fec.Encoder
+	// fecFlushDeadline is the time when the current FEC block is finished, if it isn't complete by then.
+	// It is zero if the current block is empty.
+	fecFlushDeadline time.Time
+	// repairFrames are the FEC frames of finished blocks, which haven't been sent yet
+	repairFrames []*wire.FECFrame
+	// fecDecoder recovers lost packets from the repair packets sent by the peer.
+	// It is only set if FEC is enabled.
+	fecDecoder *fec.Decoder
+	// recoveredPackets are the packets recovered by the fecDecoder, which haven't been processed yet
+	recoveredPackets [][]byte
+
+	// mtuDiscoverer searches for the largest packet size that can be sent on the path.
+	// It is set when the handshake completes, unless path MTU discovery is disabled.
+	mtuDiscoverer *mtuDiscoverer
+
+	// sendBatch collects the packets sent on the initial path by sendPackets
+	sendBatch packetBatch
+
 	cryptoSetup handshake.CryptoSetup
 
 	receivedPackets  chan *receivedPacket
@@ -152,8 +222,13 @@ func newSession(
 	transportParams := &handshake.TransportParameters{
 		StreamFlowControlWindow:     protocol.ReceiveStreamFlowControlWindow,
 		ConnectionFlowControlWindow: protocol.ReceiveConnectionFlowControlWindow,
-		MaxStreams:                  protocol.MaxIncomingStreams,
+		MaxStreams:                  uint32(s.config.MaxIncomingStreams),
 		IdleTimeout:                 s.config.IdleTimeout,
+		FEC:                         s.config.FEC != nil,
+		Multipath:                   s.config.Multipath != nil,
+		MaxAckDelay:                 s.config.AckPolicy.MaxAckDelay,
+		AckFrequency:                true,
+		ExpiredStreamData:           true,
 	}
 	cs, err := newCryptoSetup(
 		s.cryptoStream,
@@ -200,9 +275,14 @@ var newClientSession = func(
 	transportParams := &handshake.TransportParameters{
 		StreamFlowControlWindow:     protocol.ReceiveStreamFlowControlWindow,
 		ConnectionFlowControlWindow: protocol.ReceiveConnectionFlowControlWindow,
-		MaxStreams:                  protocol.MaxIncomingStreams,
+		MaxStreams:                  uint32(s.config.MaxIncomingStreams),
 		IdleTimeout:                 s.config.IdleTimeout,
 		OmitConnectionID:            s.config.RequestConnectionIDOmission,
+		FEC:                         s.config.FEC != nil,
+		Multipath:                   s.config.Multipath != nil,
+		MaxAckDelay:                 s.config.AckPolicy.MaxAckDelay,
+		AckFrequency:                true,
+		ExpiredStreamData:           true,
 	}
 	cs, err := newCryptoSetupClient(
 		s.cryptoStream,
@@ -313,6 +393,10 @@ func (s *session) postSetup(initialPacketNumber protocol.PacketNumber) error {
 	s.receivedPackets = make(chan *receivedPacket, protocol.MaxSessionUnprocessedPackets)
 	s.closeChan = make(chan closeError, 1)
 	s.sendingScheduled = make(chan struct{}, 1)
+	s.addPathChan = make(chan addPathRequest)
+	s.ackPolicyChan = make(chan ackPolicyRequest)
+	s.paths = make(map[protocol.PathID]*path)
+	s.nextPathID = protocol.InitialPathID + 1
 	s.undecryptablePackets = make([]*receivedPacket, 0, protocol.MaxUndecryptablePackets)
 	s.ctx, s.ctxCancel = context.WithCancel(context.Background())
 
@@ -321,13 +405,22 @@ func (s *session) postSetup(initialPacketNumber protocol.PacketNumber) error {
 	s.lastNetworkActivityTime = now
 	s.sessionCreationTime = now
 
-	s.sentPacketHandler = ackhandler.NewSentPacketHandler(s.rttStats)
+	s.sentPacketHandler = ackhandler.NewSentPacketHandler(
+		s.rttStats,
+		s.config.CongestionControl(s.conn.RemoteAddr(), s.rttStats),
+		protocol.ByteCount(s.config.InitialPacingBurst)*protocol.DefaultTCPMSS,
+	)
+	if s.version.UsesIETFFrameFormat() {
+		s.sentPacketHandler.EnableECN()
+	}
 	s.receivedPacketHandler = ackhandler.NewReceivedPacketHandler(s.version)
+	s.ackPolicy = *s.config.AckPolicy
+	s.applyAckPolicy(s.initialPath())
 
 	if s.version.UsesTLS() {
-		s.streamsMap = newStreamsMap(s.newStream, s.perspective)
+		s.streamsMap = newStreamsMap(s.newStream, s.queueControlFrame, s.config.MaxIncomingStreams, s.perspective)
 	} else {
-		s.streamsMap = newStreamsMapLegacy(s.newStream, s.perspective)
+		s.streamsMap = newStreamsMapLegacy(s.newStream, uint32(s.config.MaxIncomingStreams), s.perspective)
 	}
 	s.streamFramer = newStreamFramer(s.cryptoStream, s.streamsMap, s.version)
 	s.packer = newPacketPacker(s.connectionID,
@@ -337,8 +430,12 @@ func (s *session) postSetup(initialPacketNumber protocol.PacketNumber) error {
 		s.perspective,
 		s.version,
 	)
+	s.setMaxPacketSize(protocol.ByteCount(s.config.MinPacketSize))
 	s.windowUpdateQueue = newWindowUpdateQueue(s.streamsMap, s.cryptoStream, s.packer.QueueControlFrame)
 	s.unpacker = &packetUnpacker{aead: s.cryptoSetup, version: s.version}
+	if s.config.FEC != nil {
+		s.fecDecoder = fec.NewDecoder()
+	}
 	return nil
 }
 
@@ -390,13 +487,23 @@ runLoop:
 			// This is a bit unclean, but works properly, since the packet always
 			// begins with the public header and we never copy it.
 			putPacketBuffer(p.header.Raw)
+			putReceivedPacket(p)
+			if err := s.handleRecoveredPackets(); err != nil {
+				s.closeLocal(err)
+				continue
+			}
 		case p := <-s.paramsChan:
 			s.processTransportParameters(&p)
+		case req := <-s.addPathChan:
+			req.err <- s.addPath(req.conn)
+		case req := <-s.ackPolicyChan:
+			req.err <- s.setPeerAckPolicy(req.policy)
 		case _, ok := <-handshakeEvent:
 			if !ok { // the aeadChanged chan was closed. This means that the handshake is completed.
 				s.handshakeComplete = true
 				handshakeEvent = nil // prevent this case from ever being selected again
 				s.sentPacketHandler.SetHandshakeComplete()
+				s.startMTUDiscovery()
 				if !s.version.UsesTLS() && s.perspective == protocol.PerspectiveClient {
 					// In gQUIC, there's no equivalent to the Finished message in TLS
 					// The server knows that the handshake is complete when it receives the first forward-secure packet sent by the client.
@@ -410,14 +517,23 @@ runLoop:
 		}
 
 		now := time.Now()
+		if !s.fecFlushDeadline.IsZero() && !now.Before(s.fecFlushDeadline) {
+			s.flushFECBlock()
+		}
 		if timeout := s.sentPacketHandler.GetAlarmTimeout(); !timeout.IsZero() && timeout.Before(now) {
 			// This could cause packets to be retransmitted, so check it before trying
 			// to send packets.
 			s.sentPacketHandler.OnAlarm()
 		}
+		for _, pth := range s.paths {
+			if timeout := pth.sentPacketHandler.GetAlarmTimeout(); !timeout.IsZero() && timeout.Before(now) {
+				pth.sentPacketHandler.OnAlarm()
+			}
+		}
 
 		var pacingDeadline time.Time
-		if s.pacingDeadline.IsZero() { // the timer didn't have a pacing deadline set
+		// When using multiple paths, sendPackets takes care of pacing on every path.
+		if s.pacingDeadline.IsZero() && len(s.paths) == 0 { // the timer didn't have a pacing deadline set
 			pacingDeadline = s.sentPacketHandler.TimeUntilSend()
 		}
 		if s.config.KeepAlive && !s.keepAlivePingSent && s.handshakeComplete && time.Since(s.lastNetworkActivityTime) >= s.peerParams.IdleTimeout/2 {
@@ -453,6 +569,7 @@ runLoop:
 		s.handshakeChan <- closeErr.err
 	}
 	s.handleCloseError(closeErr)
+	s.closePaths()
 	return closeErr.err
 }
 
@@ -478,6 +595,17 @@ func (s *session) maybeResetTimer() {
 	if lossTime := s.sentPacketHandler.GetAlarmTimeout(); !lossTime.IsZero() {
 		deadline = utils.MinTime(deadline, lossTime)
 	}
+	if !s.fecFlushDeadline.IsZero() {
+		deadline = utils.MinTime(deadline, s.fecFlushDeadline)
+	}
+	for _, pth := range s.paths {
+		if ackAlarm := pth.receivedPacketHandler.GetAlarmTimeout(); !ackAlarm.IsZero() {
+			deadline = utils.MinTime(deadline, ackAlarm)
+		}
+		if lossTime := pth.sentPacketHandler.GetAlarmTimeout(); !lossTime.IsZero() {
+			deadline = utils.MinTime(deadline, lossTime)
+		}
+	}
 	if !s.handshakeComplete {
 		handshakeDeadline := s.sessionCreationTime.Add(s.config.HandshakeTimeout)
 		deadline = utils.MinTime(deadline, handshakeDeadline)
@@ -510,6 +638,10 @@ func (s *session) handlePacketImpl(p *receivedPacket) error {
 	hdr := p.header
 	data := p.data
 
+	if hdr.PathID != protocol.InitialPathID {
+		return s.handlePathPacket(p)
+	}
+
 	// Calculate packet number
 	hdr.PacketNumber = protocol.InferPacketNumber(
 		hdr.PacketNumberLen,
@@ -535,15 +667,77 @@ func (s *session) handlePacketImpl(p *receivedPacket) error {
 	// Only do this after decrypting, so we are sure the packet is not attacker-controlled
 	s.largestRcvdPacketNumber = utils.MaxPacketNumber(s.largestRcvdPacketNumber, hdr.PacketNumber)
 
-	isRetransmittable := ackhandler.HasRetransmittableFrames(packet.frames)
-	if err = s.receivedPacketHandler.ReceivedPacket(hdr.PacketNumber, p.rcvTime, isRetransmittable); err != nil {
+	shouldInstigateAck := ackhandler.ShouldInstigateAck(packet.frames)
+	if err = s.receivedPacketHandler.ReceivedPacket(hdr.PacketNumber, p.rcvTime, shouldInstigateAck); err != nil {
 		return err
 	}
+	if p.ecn != protocol.ECNNon {
+		s.receivedPacketHandler.ReceivedECN(p.ecn)
+	}
+	if s.fecDecoder != nil && packet.encryptionLevel == protocol.EncryptionForwardSecure {
+		recovered, err := s.fecDecoder.ReceivedPacket(hdr.PacketNumber, hdr.Raw, data)
+		if err != nil {
+			return qerr.Error(qerr.InvalidFrameData, err.Error())
+		}
+		s.recoveredPackets = append(s.recoveredPackets, recovered...)
+	}
 
 	return s.handleFrames(packet.frames, packet.encryptionLevel)
 }
 
+// handlePathPacket handles a packet received on an additional path
+func (s *session) handlePathPacket(p *receivedPacket) error {
+	hdr := p.header
+	pth, pathExists := s.paths[hdr.PathID]
+	var largestRcvdPacketNumber protocol.PacketNumber
+	if pathExists {
+		largestRcvdPacketNumber = pth.largestRcvdPacketNumber
+	}
+	hdr.PacketNumber = protocol.InferPacketNumber(hdr.PacketNumberLen, largestRcvdPacketNumber, hdr.PacketNumber)
+
+	packet, err := s.unpacker.Unpack(hdr.Raw, hdr, p.data)
+	if utils.Debug() {
+		if err != nil {
+			utils.Debugf("<- Reading packet 0x%x (%d bytes) for connection %x on path %d", hdr.PacketNumber, len(p.data)+len(hdr.Raw), hdr.ConnectionID, hdr.PathID)
+		} else {
+			utils.Debugf("<- Reading packet 0x%x (%d bytes) for connection %x on path %d, %s", hdr.PacketNumber, len(p.data)+len(hdr.Raw), hdr.ConnectionID, hdr.PathID, packet.encryptionLevel)
+		}
+		hdr.Log()
+	}
+	if err != nil {
+		return err
+	}
+	if packet.encryptionLevel != protocol.EncryptionForwardSecure {
+		return qerr.Error(qerr.BadMultipathFlag, fmt.Sprintf("received a packet on path %d before completing the handshake", hdr.PathID))
+	}
+	// Only open the path after decrypting, so we are sure the packet is not attacker-controlled
+	if !pathExists {
+		pth, err = s.acceptPath(hdr.PathID, p.remoteAddr)
+		if err != nil {
+			return err
+		}
+	}
+
+	pth.lastRcvdPacketNumber = hdr.PacketNumber
+	pth.largestRcvdPacketNumber = utils.MaxPacketNumber(pth.largestRcvdPacketNumber, hdr.PacketNumber)
+	isRetransmittable := ackhandler.HasRetransmittableFrames(packet.frames)
+	if err := pth.receivedPacketHandler.ReceivedPacket(hdr.PacketNumber, p.rcvTime, isRetransmittable); err != nil {
+		return err
+	}
+	if p.ecn != protocol.ECNNon {
+		pth.receivedPacketHandler.ReceivedECN(p.ecn)
+	}
+	return s.handleFramesOnPath(packet.frames, packet.encryptionLevel, pth)
+}
+
+// handleFrames handles the frames of a packet received on the initial path
 func (s *session) handleFrames(fs []wire.Frame, encLevel protocol.EncryptionLevel) error {
+	return s.handleFramesOnPath(fs, encLevel, nil)
+}
+
+// handleFramesOnPath handles the frames of a packet received on pth.
+// ACK frames always refer to the path that they were received on. pth is nil for the initial path.
+func (s *session) handleFramesOnPath(fs []wire.Frame, encLevel protocol.EncryptionLevel, pth *path) error {
 	for _, ff := range fs {
 		var err error
 		wire.LogFrame(ff, false)
@@ -551,7 +745,14 @@ func (s *session) handleFrames(fs []wire.Frame, encLevel protocol.EncryptionLeve
 		case *wire.StreamFrame:
 			err = s.handleStreamFrame(frame)
 		case *wire.AckFrame:
-			err = s.handleAckFrame(frame, encLevel)
+			if pth == nil {
+				err = s.handleAckFrame(frame, encLevel)
+			} else {
+				err = pth.handleAckFrame(frame, encLevel, s.lastNetworkActivityTime)
+			}
+			if err == nil {
+				s.updateBandwidthEstimate()
+			}
 		case *wire.ConnectionCloseFrame:
 			s.closeRemote(qerr.Error(frame.ErrorCode, frame.ReasonPhrase))
 		case *wire.GoawayFrame:
@@ -559,14 +760,24 @@ func (s *session) handleFrames(fs []wire.Frame, encLevel protocol.EncryptionLeve
 		case *wire.StopWaitingFrame: // ignore STOP_WAITINGs
 		case *wire.RstStreamFrame:
 			err = s.handleRstStreamFrame(frame)
+		case *wire.ExpiredStreamDataFrame:
+			err = s.handleExpiredStreamDataFrame(frame)
+		case *wire.FECFrame:
+			err = s.handleFECFrame(frame)
 		case *wire.MaxDataFrame:
 			s.handleMaxDataFrame(frame)
 		case *wire.MaxStreamDataFrame:
 			err = s.handleMaxStreamDataFrame(frame)
 		case *wire.BlockedFrame:
 		case *wire.StreamBlockedFrame:
+		case *wire.MaxStreamIDFrame:
+			err = s.streamsMap.HandleMaxStreamIDFrame(frame)
+		case *wire.StreamIDBlockedFrame:
+			atomic.AddUint64(&s.streamIDBlockedCount, 1)
 		case *wire.StopSendingFrame:
 			err = s.handleStopSendingFrame(frame)
+		case *wire.AckFrequencyFrame:
+			s.handleAckFrequencyFrame(frame)
 		case *wire.PingFrame:
 		default:
 			return errors.New("Session BUG: unexpected frame type")
@@ -584,6 +795,43 @@ func (s *session) handleFrames(fs []wire.Frame, encLevel protocol.EncryptionLeve
 	return nil
 }
 
+// handleRecoveredPackets processes the packets recovered by FEC, as if they had been received from the network
+func (s *session) handleRecoveredPackets() error {
+	for len(s.recoveredPackets) > 0 {
+		data := s.recoveredPackets[0]
+		s.recoveredPackets = s.recoveredPackets[1:]
+
+		r := bytes.NewReader(data)
+		var hdr *wire.Header
+		var err error
+		if s.perspective == protocol.PerspectiveServer {
+			hdr, err = wire.ParseHeaderSentByClient(r)
+		} else {
+			hdr, err = wire.ParseHeaderSentByServer(r, s.version)
+		}
+		if err != nil {
+			utils.Debugf("error parsing recovered packet: %s", err.Error())
+			continue
+		}
+		hdr.Raw = data[:len(data)-r.Len()]
+		utils.Debugf("Recovered packet 0x%x (%d bytes)", hdr.PacketNumber, len(data))
+		p := &receivedPacket{
+			remoteAddr: s.conn.RemoteAddr(),
+			header:     hdr,
+			data:       data[len(hdr.Raw):],
+			rcvTime:    time.Now(),
+		}
+		if err := s.handlePacketImpl(p); err != nil {
+			if qErr, ok := err.(*qerr.QuicError); ok && qErr.ErrorCode == qerr.DecryptionFailure {
+				utils.Debugf("Dropping undecryptable recovered packet 0x%x", hdr.PacketNumber)
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
 // handlePacket is called by the server with a new packet
 func (s *session) handlePacket(p *receivedPacket) {
 	// Discard packets once the amount of queued packets is larger than
@@ -649,6 +897,33 @@ func (s *session) handleRstStreamFrame(frame *wire.RstStreamFrame) error {
 	return str.handleRstStreamFrame(frame)
 }
 
+func (s *session) handleExpiredStreamDataFrame(frame *wire.ExpiredStreamDataFrame) error {
+	if frame.StreamID == s.version.CryptoStreamID() {
+		return errors.New("Received EXPIRED_STREAM_DATA frame for the crypto stream")
+	}
+	str, err := s.streamsMap.GetOrOpenReceiveStream(frame.StreamID)
+	if err != nil {
+		return err
+	}
+	if str == nil {
+		// stream is closed and already garbage collected
+		return nil
+	}
+	return str.handleExpiredStreamDataFrame(frame)
+}
+
+func (s *session) handleFECFrame(frame *wire.FECFrame) error {
+	if s.fecDecoder == nil {
+		return qerr.Error(qerr.InvalidFrameData, "received FEC frame, but FEC is not enabled")
+	}
+	recovered, err := s.fecDecoder.ReceivedFECFrame(frame)
+	if err != nil {
+		return qerr.Error(qerr.InvalidFrameData, err.Error())
+	}
+	s.recoveredPackets = append(s.recoveredPackets, recovered...)
+	return nil
+}
+
 func (s *session) handleStopSendingFrame(frame *wire.StopSendingFrame) error {
 	if frame.StreamID == s.version.CryptoStreamID() {
 		return errors.New("Received a STOP_SENDING frame for the crypto stream")
@@ -665,14 +940,51 @@ func (s *session) handleStopSendingFrame(frame *wire.StopSendingFrame) error {
 	return nil
 }
 
+// handleAckFrequencyFrame changes the ACK policy on all paths, as requested by the peer.
+// Frames that arrive out of order are ignored.
+func (s *session) handleAckFrequencyFrame(frame *wire.AckFrequencyFrame) {
+	if frame.SequenceNumber < s.nextPeerAckFrequencySeq {
+		return
+	}
+	s.nextPeerAckFrequencySeq = frame.SequenceNumber + 1
+	s.ackPolicy = AckPolicy{
+		PacketThreshold:  int(utils.MinUint64(frame.PacketThreshold, math.MaxInt32)),
+		MaxAckDelay:      utils.MinDuration(frame.MaxAckDelay, protocol.MaxAckDelayLimit),
+		IgnoreReordering: frame.IgnoreReordering,
+	}
+	for _, pth := range s.allPaths() {
+		s.applyAckPolicy(pth)
+	}
+}
+
 func (s *session) handleAckFrame(frame *wire.AckFrame, encLevel protocol.EncryptionLevel) error {
 	if err := s.sentPacketHandler.ReceivedAck(frame, s.lastRcvdPacketNumber, encLevel, s.lastNetworkActivityTime); err != nil {
 		return err
 	}
 	s.receivedPacketHandler.IgnoreBelow(s.sentPacketHandler.GetLowestPacketNotConfirmedAcked())
+	if s.mtuDiscoverer != nil && s.mtuDiscoverer.ReceivedAck(frame, s.lastNetworkActivityTime) {
+		s.setMaxPacketSize(s.mtuDiscoverer.CurrentSize())
+	}
 	return nil
 }
 
+// setMaxPacketSize sets the maximum size of the packets that are packed.
+// The streams use it to decide if a write fills a packet.
+func (s *session) setMaxPacketSize(size protocol.ByteCount) {
+	s.packer.SetMaxPacketSize(size)
+	atomic.StoreUint64(&s.streamDataPerPacket, uint64(size-protocol.MaxStreamDataOverhead))
+}
+
+// updateBandwidthEstimate updates the bandwidth estimate returned by BandwidthEstimate.
+// For multipath connections, it is the sum of the estimates of all paths.
+func (s *session) updateBandwidthEstimate() {
+	bandwidth := s.sentPacketHandler.BandwidthEstimate()
+	for _, pth := range s.paths {
+		bandwidth += pth.sentPacketHandler.BandwidthEstimate()
+	}
+	atomic.StoreUint64(&s.bandwidthEstimate, uint64(bandwidth))
+}
+
 func (s *session) closeLocal(e error) {
 	s.closeOnce.Do(func() {
 		s.closeChan <- closeError{err: e, remote: false}
@@ -736,42 +1048,178 @@ func (s *session) processTransportParameters(params *handshake.TransportParamete
 	if params.OmitConnectionID {
 		s.packer.SetOmitConnectionID()
 	}
+	if params.FEC && s.config.FEC != nil {
+		s.fecEncoder = fec.NewEncoder(s.config.FEC.DataPackets, s.config.FEC.RepairPackets)
+		s.packer.EnableFEC()
+	}
+	if params.MaxAckDelay != 0 {
+		s.peerMaxAckDelay = params.MaxAckDelay
+		s.sentPacketHandler.SetMaxAckDelay(params.MaxAckDelay)
+	}
 	s.connFlowController.UpdateSendWindow(params.ConnectionFlowControlWindow)
 	// the crypto stream is the only open stream at this moment
 	// so we don't need to update stream flow control windows
 }
 
+// startMTUDiscovery starts path MTU discovery, if the configuration and the peer allow sending packets larger than the minimum packet size.
+// Path MTU discovery is not used for multipath connections, since every path might have a different MTU.
+func (s *session) startMTUDiscovery() {
+	if s.config.Multipath != nil {
+		return
+	}
+	maxPacketSize := protocol.ByteCount(s.config.MaxPacketSize)
+	if s.peerParams != nil && s.peerParams.MaxPacketSize != 0 {
+		maxPacketSize = utils.MinByteCount(maxPacketSize, s.peerParams.MaxPacketSize)
+	}
+	if maxPacketSize <= s.packer.MaxPacketSize() {
+		return
+	}
+	s.mtuDiscoverer = newMTUDiscoverer(s.packer.MaxPacketSize(), maxPacketSize)
+}
+
 func (s *session) sendPackets() error {
 	s.pacingDeadline = time.Time{}
+	if len(s.paths) > 0 {
+		return s.sendPacketsMultipath()
+	}
+	// The packets are collected, and written to the connection when we're done packing.
+	s.sendBatch.conn = s.conn
+	err := s.sendPacketBatch()
+	if flushErr := s.sendBatch.Flush(); err == nil {
+		err = flushErr
+	}
+	s.sendBatch.conn = nil
+	return err
+}
+
+func (s *session) sendPacketBatch() error {
 	if !s.sentPacketHandler.SendingAllowed() { // if congestion limited, at least try sending an ACK frame
 		return s.maybeSendAckOnlyPacket()
 	}
+	if s.mtuDiscoverer != nil && s.mtuDiscoverer.ShouldSendProbe(time.Now()) {
+		if err := s.sendMTUProbe(); err != nil {
+			return err
+		}
+	}
 	numPackets := s.sentPacketHandler.ShouldSendNumPackets()
 	for i := 0; i < numPackets; i++ {
-		sentPacket, err := s.sendPacket()
-		if err != nil {
-			return err
+		// Repair packets are sent before new data, since they protect the packets that were already sent.
+		if len(s.repairFrames) > 0 {
+			if err := s.sendRepairPacket(); err != nil {
+				return err
+			}
+		} else {
+			sentPacket, err := s.sendPacket()
+			if err != nil {
+				return err
+			}
+			// If the PTO expired, a probe packet is sent, even if there's no data to send.
+			if !sentPacket && s.sentPacketHandler.ShouldSendProbePacket() {
+				s.packer.QueueControlFrame(&wire.PingFrame{})
+				if sentPacket, err = s.sendPacket(); err != nil {
+					return err
+				}
+			}
+			// If no packet was sent, we ran out of data.
+			if !sentPacket {
+				s.sentPacketHandler.SetAppLimited()
+				return nil
+			}
 		}
-		// If no packet was sent, or we're congestion limit, we're done here.
-		if !sentPacket || !s.sentPacketHandler.SendingAllowed() {
+		// If we're congestion limited, we're done here.
+		if !s.sentPacketHandler.SendingAllowed() {
 			return nil
 		}
 	}
 	// Only start the pacing timer if we sent as many packets as we were allowed.
 	// There will probably be more to send when calling sendPacket again.
 	s.pacingDeadline = s.sentPacketHandler.TimeUntilSend()
+	if s.pacingDeadline.IsZero() {
+		// the pacer allows sending more packets right away
+		s.scheduleSending()
+	}
+	return nil
+}
+
+// sendPacketsMultipath sends packets on all paths, using the scheduler to decide which path is used for every packet
+func (s *session) sendPacketsMultipath() error {
+	paths := s.allPaths()
+	// Paths are only added after the handshake completed, so all retransmissions can be sent on any path.
+	for _, pth := range paths {
+		for p := pth.sentPacketHandler.DequeuePacketForRetransmission(); p != nil; p = pth.sentPacketHandler.DequeuePacketForRetransmission() {
+			utils.Debugf("\tDequeueing retransmission for packet 0x%x on path %d", p.PacketNumber, pth.id)
+			s.queueFramesForRetransmission(p)
+		}
+	}
+
+	for {
+		now := time.Now()
+		scheduled := schedulePaths(s.config.Multipath.Scheduler, paths, now)
+		if len(scheduled) == 0 {
+			// all paths are congestion limited or paced
+			for _, pth := range paths {
+				if pth.sentPacketHandler.SendingAllowed() {
+					if t := pth.sentPacketHandler.TimeUntilSend(); s.pacingDeadline.IsZero() || t.Before(s.pacingDeadline) {
+						s.pacingDeadline = t
+					}
+				}
+			}
+			break
+		}
+		// repair packets are only sent on the initial path, as soon as it may send
+		if len(s.repairFrames) > 0 && scheduled[0].id == protocol.InitialPathID {
+			if err := s.sendRepairPacket(); err != nil {
+				return err
+			}
+			continue
+		}
+		packet, err := s.sendPacketOnPath(scheduled[0])
+		if err != nil {
+			return err
+		}
+		if packet == nil {
+			for _, pth := range scheduled {
+				pth.sentPacketHandler.SetAppLimited()
+			}
+			break
+		}
+		for _, pth := range scheduled[1:] {
+			if err := s.sendRedundantPacket(pth, packet.frames); err != nil {
+				return err
+			}
+		}
+	}
+
+	// send ACKs on the paths that were not used for sending packets, and probe packets on the paths whose PTO expired
+	for _, pth := range paths {
+		if pth.sentPacketHandler.ShouldSendProbePacket() {
+			s.packer.QueueControlFrame(&wire.PingFrame{})
+			if _, err := s.sendPacketOnPath(pth); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := s.maybeSendAckOnlyPacketOnPath(pth); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func (s *session) maybeSendAckOnlyPacket() error {
-	ack := s.receivedPacketHandler.GetAckFrame()
+	return s.maybeSendAckOnlyPacketOnPath(s.initialPath())
+}
+
+func (s *session) maybeSendAckOnlyPacketOnPath(pth *path) error {
+	ack := pth.receivedPacketHandler.GetAckFrame()
 	if ack == nil {
 		return nil
 	}
+	s.packer.SetPath(pth.id)
 	s.packer.QueueControlFrame(ack)
 
 	if !s.version.UsesIETFFrameFormat() { // for gQUIC, maybe add a STOP_WAITING
-		if swf := s.sentPacketHandler.GetStopWaitingFrame(false); swf != nil {
+		if swf := pth.sentPacketHandler.GetStopWaitingFrame(false); swf != nil {
 			s.packer.QueueControlFrame(swf)
 		}
 	}
@@ -779,11 +1227,19 @@ func (s *session) maybeSendAckOnlyPacket() error {
 	if err != nil {
 		return err
 	}
-	return s.sendPackedPacket(packet)
+	return s.sendPackedPacketOnPath(packet, pth)
 }
 
 func (s *session) sendPacket() (bool, error) {
-	s.packer.SetLeastUnacked(s.sentPacketHandler.GetLeastUnacked())
+	packet, err := s.sendPacketOnPath(s.initialPath())
+	return packet != nil, err
+}
+
+// sendPacketOnPath packs a packet and sends it on pth.
+// It returns the packet that was sent, or nil if there was nothing to send.
+func (s *session) sendPacketOnPath(pth *path) (*packedPacket, error) {
+	s.packer.SetPath(pth.id)
+	s.packer.SetLeastUnacked(pth.sentPacketHandler.GetLeastUnacked())
 
 	if offset := s.connFlowController.GetWindowUpdate(); offset != 0 {
 		s.packer.QueueControlFrame(&wire.MaxDataFrame{ByteOffset: offset})
@@ -793,80 +1249,228 @@ func (s *session) sendPacket() (bool, error) {
 	}
 	s.windowUpdateQueue.QueueAll()
 
-	ack := s.receivedPacketHandler.GetAckFrame()
+	ack := pth.receivedPacketHandler.GetAckFrame()
 	if ack != nil {
 		s.packer.QueueControlFrame(ack)
 	}
 
 	// check for retransmissions first
 	for {
-		retransmitPacket := s.sentPacketHandler.DequeuePacketForRetransmission()
+		retransmitPacket := pth.sentPacketHandler.DequeuePacketForRetransmission()
 		if retransmitPacket == nil {
 			break
 		}
 
+		// MTU probe packets are never retransmitted
+		if retransmitPacket.IsMTUProbePacket {
+			s.mtuDiscoverer.LostProbe(retransmitPacket.PacketNumber, time.Now())
+			continue
+		}
+
 		// retransmit handshake packets
 		if retransmitPacket.EncryptionLevel != protocol.EncryptionForwardSecure {
+			// Stream data is retransmitted from the send buffers of the streams, in the next regular packet.
+			// All other frames have to be retransmitted with the same encryption level.
+			if !s.queueStreamDataForRetransmission(retransmitPacket) {
+				continue
+			}
 			utils.Debugf("\tDequeueing handshake retransmission for packet 0x%x", retransmitPacket.PacketNumber)
 			if !s.version.UsesIETFFrameFormat() {
-				s.packer.QueueControlFrame(s.sentPacketHandler.GetStopWaitingFrame(true))
+				s.packer.QueueControlFrame(pth.sentPacketHandler.GetStopWaitingFrame(true))
 			}
 			packet, err := s.packer.PackHandshakeRetransmission(retransmitPacket)
 			if err != nil {
-				return false, err
+				return nil, err
 			}
-			if err := s.sendPackedPacket(packet); err != nil {
-				return false, err
+			if err := s.sendPackedPacketOnPath(packet, pth); err != nil {
+				return nil, err
 			}
-			return true, nil
+			return packet, nil
 		}
 
 		// queue all retransmittable frames sent in forward-secure packets
 		utils.Debugf("\tDequeueing retransmission for packet 0x%x", retransmitPacket.PacketNumber)
-		// resend the frames that were in the packet
-		for _, frame := range retransmitPacket.GetFramesForRetransmission() {
-			// TODO: only retransmit WINDOW_UPDATEs if they actually enlarge the window
-			switch f := frame.(type) {
-			case *wire.StreamFrame:
-				s.streamFramer.AddFrameForRetransmission(f)
-			default:
-				s.packer.QueueControlFrame(frame)
-			}
-		}
+		s.queueFramesForRetransmission(retransmitPacket)
 	}
 
 	hasRetransmission := s.streamFramer.HasFramesForRetransmission()
 	if !s.version.UsesIETFFrameFormat() && (ack != nil || hasRetransmission) {
-		if swf := s.sentPacketHandler.GetStopWaitingFrame(hasRetransmission); swf != nil {
+		if swf := pth.sentPacketHandler.GetStopWaitingFrame(hasRetransmission); swf != nil {
 			s.packer.QueueControlFrame(swf)
 		}
 	}
 	packet, err := s.packer.PackPacket()
 	if err != nil || packet == nil {
-		return false, err
+		return nil, err
 	}
-	if err := s.sendPackedPacket(packet); err != nil {
-		return false, err
+	if err := s.sendPackedPacketOnPath(packet, pth); err != nil {
+		return nil, err
 	}
-	return true, nil
+	return packet, nil
 }
 
-func (s *session) sendPackedPacket(packet *packedPacket) error {
-	defer putPacketBuffer(packet.raw)
-	err := s.sentPacketHandler.SentPacket(&ackhandler.Packet{
-		PacketNumber:    packet.header.PacketNumber,
-		Frames:          packet.frames,
-		Length:          protocol.ByteCount(len(packet.raw)),
-		EncryptionLevel: packet.encryptionLevel,
-	})
+// sendMTUProbe sends a probe packet for path MTU discovery
+func (s *session) sendMTUProbe() error {
+	s.packer.SetPath(protocol.InitialPathID)
+	s.packer.SetLeastUnacked(s.sentPacketHandler.GetLeastUnacked())
+	size := s.mtuDiscoverer.NextProbeSize()
+	packet, err := s.packer.PackMTUProbePacket(size)
 	if err != nil {
 		return err
 	}
+	utils.Debugf("\tSending MTU probe of %d bytes", size)
+	s.mtuDiscoverer.SentProbe(packet.header.PacketNumber, size)
+	return s.sendPackedPacket(packet)
+}
+
+// queueFramesForRetransmission queues the frames of a lost forward-secure packet, such that they are sent again
+func (s *session) queueFramesForRetransmission(p *ackhandler.Packet) {
+	for _, frame := range p.GetFramesForRetransmission() {
+		// TODO: only retransmit WINDOW_UPDATEs if they actually enlarge the window
+		switch f := frame.(type) {
+		case *sentStreamData:
+			s.streamFramer.AddDataForRetransmission(f)
+		case *wire.StreamFrame:
+			s.streamFramer.AddFrameForRetransmission(f)
+		default:
+			s.packer.QueueControlFrame(frame)
+		}
+	}
+}
+
+// queueStreamDataForRetransmission queues the stream data sent in a lost packet for retransmission, and removes it from the packet.
+// It returns if the packet still contains frames that need to be retransmitted.
+func (s *session) queueStreamDataForRetransmission(p *ackhandler.Packet) bool {
+	frames := p.Frames[:0]
+	for _, frame := range p.Frames {
+		if f, ok := frame.(*sentStreamData); ok {
+			s.streamFramer.AddDataForRetransmission(f)
+			continue
+		}
+		frames = append(frames, frame)
+	}
+	p.Frames = frames
+	return ackhandler.HasRetransmittableFrames(frames)
+}
+
+// referenceStreamData replaces the STREAM frames in a packet by references to the data.
+// The data is kept in the send buffers of the streams until it is acknowledged,
+// so the history of sent packets doesn't need to keep the frames alive.
+// STREAM frames of the crypto stream are kept, since they are retransmitted as they were sent.
+func (s *session) referenceStreamData(frames []wire.Frame) []wire.Frame {
+	var res []wire.Frame
+	for i, frame := range frames {
+		if f, ok := frame.(*wire.StreamFrame); ok {
+			if str := s.streamFramer.GetPoppedStream(f.StreamID); str != nil {
+				if res == nil {
+					res = make([]wire.Frame, i, len(frames))
+					copy(res, frames[:i])
+				}
+				res = append(res, newSentStreamData(str, f))
+				continue
+			}
+		}
+		if res != nil {
+			res = append(res, frame)
+		}
+	}
+	if res == nil {
+		return frames
+	}
+	return res
+}
+
+// sendRedundantPacket sends the retransmittable frames of a packet that was already sent on a different path on pth
+func (s *session) sendRedundantPacket(pth *path, frames []wire.Frame) error {
+	s.packer.SetPath(pth.id)
+	s.packer.SetLeastUnacked(pth.sentPacketHandler.GetLeastUnacked())
+	packet, err := s.packer.PackRedundantPacket(frames)
+	if err != nil || packet == nil {
+		return err
+	}
+	return s.sendPackedPacketOnPath(packet, pth)
+}
+
+func (s *session) sendPackedPacket(packet *packedPacket) error {
+	return s.sendPackedPacketOnPath(packet, s.initialPath())
+}
+
+func (s *session) sendPackedPacketOnPath(packet *packedPacket, pth *path) error {
+	batched := pth.conn == s.sendBatch.conn
+	if !batched {
+		defer putPacketBuffer(packet.raw)
+	}
+	p := &ackhandler.Packet{
+		PacketNumber:     packet.header.PacketNumber,
+		Frames:           s.referenceStreamData(packet.frames),
+		Length:           protocol.ByteCount(len(packet.raw)),
+		EncryptionLevel:  packet.encryptionLevel,
+		IsMTUProbePacket: packet.isMTUProbePacket,
+		IsRepairPacket:   packet.isRepairPacket,
+	}
+	if err := pth.sentPacketHandler.SentPacket(p); err != nil {
+		return err
+	}
 	s.logPacket(packet)
-	return s.conn.Write(packet.raw)
+	if batched {
+		if err := s.sendBatch.Add(packet.raw, p.ECN); err != nil {
+			return err
+		}
+	} else if err := pth.conn.WriteWithECN(packet.raw, p.ECN); err != nil {
+		return err
+	}
+	return s.maybeProtectPacket(packet)
+}
+
+// maybeProtectPacket adds a packet to the current FEC block.
+// When the block is complete, the repair packets are queued. They are sent when congestion control and pacing allow it.
+func (s *session) maybeProtectPacket(packet *packedPacket) error {
+	if s.fecEncoder == nil ||
+		packet.encryptionLevel != protocol.EncryptionForwardSecure ||
+		packet.isMTUProbePacket ||
+		// every path has its own packet number space, only packets sent on the initial path are protected
+		packet.header.PathID != protocol.InitialPathID ||
+		!ackhandler.HasRetransmittableFrames(packet.frames) ||
+		// packets sent before FEC was enabled might be too large to fit into a repair packet
+		protocol.ByteCount(len(packet.raw)) > s.packer.MaxPacketSize()-protocol.FECPacketSizeReduction {
+		return nil
+	}
+	s.repairFrames = append(s.repairFrames, s.fecEncoder.AddPacket(packet.header.PacketNumber, packet.raw)...)
+	switch s.fecEncoder.Len() {
+	case 0:
+		s.fecFlushDeadline = time.Time{}
+	case 1:
+		// This packet started a new block.
+		// If the sender becomes application-limited, the block is finished after a short delay, so that the last packets are protected as well.
+		s.fecFlushDeadline = time.Now().Add(protocol.FECFlushDelay)
+	}
+	return nil
+}
+
+// flushFECBlock finishes the current FEC block, and queues its repair packets
+func (s *session) flushFECBlock() {
+	s.fecFlushDeadline = time.Time{}
+	if frames := s.fecEncoder.Flush(); len(frames) > 0 {
+		s.repairFrames = append(s.repairFrames, frames...)
+		s.scheduleSending()
+	}
+}
+
+func (s *session) sendRepairPacket() error {
+	f := s.repairFrames[0]
+	s.repairFrames[0] = nil
+	s.repairFrames = s.repairFrames[1:]
+	s.packer.SetPath(protocol.InitialPathID)
+	s.packer.SetLeastUnacked(s.sentPacketHandler.GetLeastUnacked())
+	packet, err := s.packer.PackRepairPacket(f)
+	if err != nil {
+		return err
+	}
+	return s.sendPackedPacket(packet)
 }
 
 func (s *session) sendConnectionClose(quicErr *qerr.QuicError) error {
+	s.packer.SetPath(protocol.InitialPathID)
 	s.packer.SetLeastUnacked(s.sentPacketHandler.GetLeastUnacked())
 	packet, err := s.packer.PackConnectionClose(&wire.ConnectionCloseFrame{
 		ErrorCode:    quicErr.ErrorCode,
@@ -930,7 +1534,11 @@ func (s *session) newStream(id protocol.StreamID) streamI {
 		initialSendWindow,
 		s.rttStats,
 	)
-	return newStream(id, s, flowController, s.version)
+	str := newStream(id, s, flowController, s.version)
+	if s.config.WriteCoalescingDelay > 0 {
+		str.SetWriteCoalescingDelay(s.config.WriteCoalescingDelay)
+	}
+	return str
 }
 
 func (s *session) newCryptoStream() cryptoStreamI {
@@ -1006,6 +1614,238 @@ func (s *session) onStreamCompleted(id protocol.StreamID) {
 	}
 }
 
+func (s *session) peerSupportsExpiredStreamData() bool {
+	return s.peerParams != nil && s.peerParams.ExpiredStreamData
+}
+
+func (s *session) maxStreamDataPerPacket() protocol.ByteCount {
+	return protocol.ByteCount(atomic.LoadUint64(&s.streamDataPerPacket))
+}
+
+func (s *session) SetMaxIncomingStreams(n int) error {
+	return s.streamsMap.SetMaxIncomingStreams(n)
+}
+
+func (s *session) StreamIDBlockedCount() uint64 {
+	return atomic.LoadUint64(&s.streamIDBlockedCount)
+}
+
+func (s *session) BandwidthEstimate() Bandwidth {
+	return Bandwidth(atomic.LoadUint64(&s.bandwidthEstimate))
+}
+
+// SetPeerAckPolicy asks the peer to use a different ACK policy
+func (s *session) SetPeerAckPolicy(policy *AckPolicy) error {
+	errChan := make(chan error, 1)
+	select {
+	case s.ackPolicyChan <- ackPolicyRequest{policy: policy, err: errChan}:
+	case <-s.ctx.Done():
+		return errors.New("session already closed")
+	}
+	return <-errChan
+}
+
+// setPeerAckPolicy is called from the run loop when the application changes the peer's ACK policy.
+// From now on, the loss detection on all paths assumes that the peer delays ACKs by the new maximum ACK delay.
+func (s *session) setPeerAckPolicy(policy *AckPolicy) error {
+	if !s.handshakeComplete {
+		return errors.New("the ACK policy can only be changed after the handshake completed")
+	}
+	if !s.peerParams.AckFrequency {
+		return errors.New("the peer doesn't support ACK_FREQUENCY frames")
+	}
+	policy = populateAckPolicy(policy)
+	s.queueControlFrame(&sentAckFrequencyFrame{
+		AckFrequencyFrame: &wire.AckFrequencyFrame{
+			SequenceNumber:   s.nextAckFrequencySeq,
+			PacketThreshold:  uint64(policy.PacketThreshold),
+			MaxAckDelay:      policy.MaxAckDelay,
+			IgnoreReordering: policy.IgnoreReordering,
+		},
+		sess: s,
+	})
+	s.nextAckFrequencySeq++
+	// Until the peer acknowledges the frame, it might still be using the old maximum ACK delay.
+	// Only raise the delay now, and lower it when the frame is acknowledged.
+	maxAckDelay := s.peerMaxAckDelay
+	if maxAckDelay == 0 {
+		maxAckDelay = protocol.AckSendDelay
+	}
+	if policy.MaxAckDelay > maxAckDelay {
+		s.setPeerMaxAckDelay(policy.MaxAckDelay)
+	}
+	return nil
+}
+
+// onAckFrequencyFrameAcked is called when the peer acknowledged an ACK_FREQUENCY frame
+func (s *session) onAckFrequencyFrameAcked(f *wire.AckFrequencyFrame) {
+	// The peer ignores ACK_FREQUENCY frames with a lower sequence number than the highest one it received.
+	// Only when the most recent frame is acknowledged, we know which maximum ACK delay the peer is using.
+	if f.SequenceNumber+1 != s.nextAckFrequencySeq {
+		return
+	}
+	s.setPeerMaxAckDelay(f.MaxAckDelay)
+}
+
+func (s *session) setPeerMaxAckDelay(d time.Duration) {
+	s.peerMaxAckDelay = d
+	for _, pth := range s.allPaths() {
+		pth.sentPacketHandler.SetMaxAckDelay(d)
+	}
+}
+
+// sentAckFrequencyFrame takes the place of an ACK_FREQUENCY frame in the history of sent packets.
+// It notifies the session when the frame is acknowledged.
+type sentAckFrequencyFrame struct {
+	*wire.AckFrequencyFrame
+	sess *session
+}
+
+var _ ackhandler.AckListener = &sentAckFrequencyFrame{}
+
+func (f *sentAckFrequencyFrame) OnAcked() {
+	f.sess.onAckFrequencyFrameAcked(f.AckFrequencyFrame)
+}
+
+// applyAckPolicy configures the ACK policy and the peer's maximum ACK delay for a path
+func (s *session) applyAckPolicy(pth *path) {
+	pth.receivedPacketHandler.SetAckPolicy(s.ackPolicy.PacketThreshold, s.ackPolicy.MaxAckDelay, s.ackPolicy.IgnoreReordering)
+	if s.peerMaxAckDelay != 0 {
+		pth.sentPacketHandler.SetMaxAckDelay(s.peerMaxAckDelay)
+	}
+}
+
+// AddPath adds a path, sending and receiving packets on the given PacketConn
+func (s *session) AddPath(pconn net.PacketConn) error {
+	if s.perspective == protocol.PerspectiveServer {
+		return errors.New("only the client can add paths")
+	}
+	errChan := make(chan error, 1)
+	select {
+	case s.addPathChan <- addPathRequest{conn: pconn, err: errChan}:
+	case <-s.ctx.Done():
+		return errors.New("session already closed")
+	}
+	return <-errChan
+}
+
+// addPath is called from the run loop when the application adds a path
+func (s *session) addPath(pconn net.PacketConn) error {
+	if s.config.Multipath == nil {
+		return errors.New("multipath is not enabled")
+	}
+	if !s.handshakeComplete {
+		return errors.New("paths can only be added after the handshake completed")
+	}
+	if !s.peerParams.Multipath {
+		return errors.New("the peer doesn't support multipath")
+	}
+	if len(s.paths)+1 >= protocol.MaxPaths {
+		return fmt.Errorf("too many paths (maximum %d)", protocol.MaxPaths)
+	}
+	enableECN(pconn)
+	pth := newPath(s.nextPathID, &conn{pconn: pconn, currentAddr: s.conn.RemoteAddr()}, s.config, s.version)
+	s.nextPathID++
+	s.applyAckPolicy(pth)
+	s.paths[pth.id] = pth
+	utils.Infof("Adding path %d (%s) to connection %x", pth.id, pth.conn.LocalAddr(), s.connectionID)
+	go s.listenOnPath(pth)
+	return s.probePath(pth)
+}
+
+// acceptPath opens a path that the client started using
+func (s *session) acceptPath(id protocol.PathID, remoteAddr net.Addr) (*path, error) {
+	if s.perspective == protocol.PerspectiveClient {
+		return nil, qerr.Error(qerr.BadMultipathFlag, fmt.Sprintf("received a packet on unknown path %d", id))
+	}
+	if s.config.Multipath == nil {
+		return nil, qerr.Error(qerr.BadMultipathFlag, fmt.Sprintf("received a packet on path %d, but multipath is not enabled", id))
+	}
+	if len(s.paths)+1 >= protocol.MaxPaths {
+		return nil, qerr.Error(qerr.BadMultipathFlag, fmt.Sprintf("too many paths (maximum %d)", protocol.MaxPaths))
+	}
+	// All paths use the server's socket. Packets are sent to the address that the client uses for this path.
+	c, ok := s.conn.(*conn)
+	if !ok {
+		return nil, errors.New("session BUG: can't open a path on this connection")
+	}
+	pth := newPath(id, &conn{pconn: c.pconn, currentAddr: remoteAddr}, s.config, s.version)
+	s.applyAckPolicy(pth)
+	s.paths[id] = pth
+	utils.Infof("Accepting path %d (%s) for connection %x", id, remoteAddr, s.connectionID)
+	return pth, s.probePath(pth)
+}
+
+// probePath sends a PING on a new path, to obtain a first RTT estimate
+func (s *session) probePath(pth *path) error {
+	s.packer.SetPath(pth.id)
+	s.packer.QueueControlFrame(&wire.PingFrame{})
+	_, err := s.sendPacketOnPath(pth)
+	return err
+}
+
+// listenOnPath reads the packets that the server sends on an additional path
+func (s *session) listenOnPath(pth *path) {
+	for {
+		data := getPacketBuffer(receiveBufferSize(s.config))
+		data = data[:cap(data)]
+		n, remoteAddr, ecn, err := pth.conn.Read(data)
+		if err != nil {
+			return
+		}
+		data = data[:n]
+		p := getReceivedPacket()
+		p.rcvTime = time.Now()
+		r := bytes.NewReader(data)
+		if err := wire.ParseHeaderSentByServerInto(r, s.version, p.header); err != nil {
+			utils.Debugf("error parsing packet received on path %d: %s", pth.id, err.Error())
+			continue
+		}
+		if p.header.ConnectionID != s.connectionID && !p.header.OmitConnectionID {
+			continue
+		}
+		p.header.Raw = data[:len(data)-r.Len()]
+		p.remoteAddr = remoteAddr
+		p.data = data[len(p.header.Raw):]
+		p.ecn = ecn
+		s.handlePacket(p)
+	}
+}
+
+// closePaths closes the connections of the paths added by the client.
+// On the server side, all paths use the server's socket.
+func (s *session) closePaths() {
+	if s.perspective == protocol.PerspectiveServer {
+		return
+	}
+	for _, pth := range s.paths {
+		pth.conn.Close()
+	}
+}
+
+// initialPath returns the path that the handshake was performed on
+func (s *session) initialPath() *path {
+	return &path{
+		id:                    protocol.InitialPathID,
+		conn:                  s.conn,
+		rttStats:              s.rttStats,
+		sentPacketHandler:     s.sentPacketHandler,
+		receivedPacketHandler: s.receivedPacketHandler,
+	}
+}
+
+// allPaths returns all paths, ordered by their path ID
+func (s *session) allPaths() []*path {
+	paths := make([]*path, 1, len(s.paths)+1)
+	paths[0] = s.initialPath()
+	for id := protocol.InitialPathID + 1; len(paths) <= len(s.paths); id++ {
+		if pth, ok := s.paths[id]; ok {
+			paths = append(paths, pth)
+		}
+	}
+	return paths
+}
+
 func (s *session) LocalAddr() net.Addr {
 	return s.conn.LocalAddr()
 }
@@ -0,0 +1,102 @@
+package quic
+
+import (
+	"sync"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+)
+
+// sessionMapShardBits is log2 of the number of shards of a sessionMap
+const sessionMapShardBits = 6
+
+// A sessionMap maps connection IDs to sessions.
+// It is sharded by connection ID, so that looking up sessions for different connections doesn't contend on a single lock.
+// A nil session is stored for connections that were closed recently, such that late packets for these connections can be dropped.
+type sessionMap struct {
+	shards [1 << sessionMapShardBits]sessionMapShard
+}
+
+type sessionMapShard struct {
+	mutex    sync.RWMutex
+	sessions map[protocol.ConnectionID]packetHandler
+}
+
+func newSessionMap() *sessionMap {
+	m := &sessionMap{}
+	for i := range m.shards {
+		m.shards[i].sessions = make(map[protocol.ConnectionID]packetHandler)
+	}
+	return m
+}
+
+func (m *sessionMap) shard(id protocol.ConnectionID) *sessionMapShard {
+	// Fibonacci hashing, so that connection IDs that only differ in the lower bits end up on different shards
+	return &m.shards[(uint64(id)*0x9e3779b97f4a7c15)>>(64-sessionMapShardBits)]
+}
+
+// Get returns the session for a connection ID.
+// The session is nil if the connection was closed recently.
+func (m *sessionMap) Get(id protocol.ConnectionID) (packetHandler, bool) {
+	shard := m.shard(id)
+	shard.mutex.RLock()
+	sess, ok := shard.sessions[id]
+	shard.mutex.RUnlock()
+	return sess, ok
+}
+
+// Add adds a session, if there's no (open or recently closed) session for this connection ID yet.
+// It returns false if the connection ID is already in use.
+func (m *sessionMap) Add(id protocol.ConnectionID, sess packetHandler) bool {
+	shard := m.shard(id)
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+	if _, ok := shard.sessions[id]; ok {
+		return false
+	}
+	shard.sessions[id] = sess
+	return true
+}
+
+// Retire marks a connection as closed
+func (m *sessionMap) Retire(id protocol.ConnectionID) {
+	shard := m.shard(id)
+	shard.mutex.Lock()
+	shard.sessions[id] = nil
+	shard.mutex.Unlock()
+}
+
+// Remove deletes a connection ID
+func (m *sessionMap) Remove(id protocol.ConnectionID) {
+	shard := m.shard(id)
+	shard.mutex.Lock()
+	delete(shard.sessions, id)
+	shard.mutex.Unlock()
+}
+
+// Len returns the number of connection IDs, including those of recently closed connections
+func (m *sessionMap) Len() int {
+	var n int
+	for i := range m.shards {
+		shard := &m.shards[i]
+		shard.mutex.RLock()
+		n += len(shard.sessions)
+		shard.mutex.RUnlock()
+	}
+	return n
+}
+
+// Sessions returns all sessions that are not closed
+func (m *sessionMap) Sessions() []packetHandler {
+	var sessions []packetHandler
+	for i := range m.shards {
+		shard := &m.shards[i]
+		shard.mutex.RLock()
+		for _, sess := range shard.sessions {
+			if sess != nil {
+				sessions = append(sessions, sess)
+			}
+		}
+		shard.mutex.RUnlock()
+	}
+	return sessions
+}
@@ -0,0 +1,73 @@
+package quic
+
+import (
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Session Map", func() {
+	var m *sessionMap
+
+	BeforeEach(func() {
+		m = newSessionMap()
+	})
+
+	It("adds and gets sessions", func() {
+		sess1, _ := newMockSession(nil, 0, 1, nil, nil, nil)
+		sess2, _ := newMockSession(nil, 0, 2, nil, nil, nil)
+		Expect(m.Add(1, sess1)).To(BeTrue())
+		Expect(m.Add(2, sess2)).To(BeTrue())
+		sess, ok := m.Get(1)
+		Expect(ok).To(BeTrue())
+		Expect(sess).To(Equal(sess1))
+		sess, ok = m.Get(2)
+		Expect(ok).To(BeTrue())
+		Expect(sess).To(Equal(sess2))
+		_, ok = m.Get(3)
+		Expect(ok).To(BeFalse())
+		Expect(m.Len()).To(Equal(2))
+	})
+
+	It("doesn't replace sessions for connection IDs that are already in use", func() {
+		sess1, _ := newMockSession(nil, 0, 1, nil, nil, nil)
+		sess2, _ := newMockSession(nil, 0, 1, nil, nil, nil)
+		Expect(m.Add(1, sess1)).To(BeTrue())
+		Expect(m.Add(1, sess2)).To(BeFalse())
+		sess, _ := m.Get(1)
+		Expect(sess).To(Equal(sess1))
+	})
+
+	It("retires and removes sessions", func() {
+		sess, _ := newMockSession(nil, 0, 1, nil, nil, nil)
+		Expect(m.Add(1, sess)).To(BeTrue())
+		m.Retire(1)
+		s, ok := m.Get(1)
+		Expect(ok).To(BeTrue())
+		Expect(s).To(BeNil())
+		// the connection ID can't be reused until it is removed
+		Expect(m.Add(1, sess)).To(BeFalse())
+		m.Remove(1)
+		_, ok = m.Get(1)
+		Expect(ok).To(BeFalse())
+		Expect(m.Len()).To(BeZero())
+	})
+
+	It("returns all open sessions", func() {
+		sess1, _ := newMockSession(nil, 0, 1, nil, nil, nil)
+		sess2, _ := newMockSession(nil, 0, 2, nil, nil, nil)
+		m.Add(1, sess1)
+		m.Add(2, sess2)
+		m.Add(3, nil)
+		Expect(m.Sessions()).To(ConsistOf(sess1, sess2))
+	})
+
+	It("distributes connection IDs over all shards", func() {
+		shards := make(map[*sessionMapShard]struct{})
+		for i := 0; i < 10000; i++ {
+			shards[m.shard(protocol.ConnectionID(i))] = struct{}{}
+		}
+		Expect(shards).To(HaveLen(len(m.shards)))
+	})
+})
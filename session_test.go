@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/tls"
 	"errors"
+	"fmt"
 	"io"
 	"net"
 	"runtime/pprof"
@@ -16,7 +17,9 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/lucas-clemente/quic-go/internal/ackhandler"
+	"github.com/lucas-clemente/quic-go/internal/congestion"
 	"github.com/lucas-clemente/quic-go/internal/crypto"
+	"github.com/lucas-clemente/quic-go/internal/fec"
 	"github.com/lucas-clemente/quic-go/internal/handshake"
 	"github.com/lucas-clemente/quic-go/internal/mocks"
 	"github.com/lucas-clemente/quic-go/internal/mocks/ackhandler"
@@ -30,6 +33,7 @@ type mockConnection struct {
 	remoteAddr net.Addr
 	localAddr  net.Addr
 	written    chan []byte
+	ecn        protocol.ECN // the ECN codepoint of the last packet written
 }
 
 func newMockConnection() *mockConnection {
@@ -49,7 +53,21 @@ func (m *mockConnection) Write(p []byte) error {
 	}
 	return nil
 }
-func (m *mockConnection) Read([]byte) (int, net.Addr, error) { panic("not implemented") }
+func (m *mockConnection) WriteWithECN(p []byte, ecn protocol.ECN) error {
+	m.ecn = ecn
+	return m.Write(p)
+}
+func (m *mockConnection) WriteBatch(packets [][]byte, ecn protocol.ECN) error {
+	for _, p := range packets {
+		if err := m.WriteWithECN(p, ecn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (m *mockConnection) Read([]byte) (int, net.Addr, protocol.ECN, error) {
+	panic("not implemented")
+}
 
 func (m *mockConnection) SetCurrentRemoteAddr(addr net.Addr) {
 	m.remoteAddr = addr
@@ -60,6 +78,7 @@ func (*mockConnection) Close() error           { panic("not implemented") }
 
 type mockUnpacker struct {
 	unpackErr error
+	encLevel  protocol.EncryptionLevel
 }
 
 func (m *mockUnpacker) Unpack(headerBinary []byte, hdr *wire.Header, data []byte) (*unpackedPacket, error) {
@@ -67,7 +86,8 @@ func (m *mockUnpacker) Unpack(headerBinary []byte, hdr *wire.Header, data []byte
 		return nil, m.unpackErr
 	}
 	return &unpackedPacket{
-		frames: nil,
+		encryptionLevel: m.encLevel,
+		frames:          nil,
 	}, nil
 }
 
@@ -262,6 +282,18 @@ var _ = Describe("Session", func() {
 				err := sess.handleAckFrame(&wire.AckFrame{LargestAcked: 3, LowestAcked: 2}, protocol.EncryptionUnencrypted)
 				Expect(err).ToNot(HaveOccurred())
 			})
+
+			It("updates the bandwidth estimate", func() {
+				Expect(sess.BandwidthEstimate()).To(BeZero())
+				sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+				sph.EXPECT().ReceivedAck(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				sph.EXPECT().GetLowestPacketNotConfirmedAcked()
+				sph.EXPECT().BandwidthEstimate().Return(1337 * congestion.BitsPerSecond)
+				sess.sentPacketHandler = sph
+				err := sess.handleFrames([]wire.Frame{&wire.AckFrame{LargestAcked: 3, LowestAcked: 2}}, protocol.EncryptionForwardSecure)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(sess.BandwidthEstimate()).To(Equal(1337 * congestion.BitsPerSecond))
+			})
 		})
 
 		Context("handling RST_STREAM frames", func() {
@@ -309,6 +341,43 @@ var _ = Describe("Session", func() {
 			})
 		})
 
+		Context("handling EXPIRED_STREAM_DATA frames", func() {
+			It("passes the frame to the stream", func() {
+				f := &wire.ExpiredStreamDataFrame{
+					StreamID:      5,
+					Offset:        0x1000,
+					MinimumOffset: 0x1337,
+				}
+				str := NewMockReceiveStreamI(mockCtrl)
+				streamManager.EXPECT().GetOrOpenReceiveStream(protocol.StreamID(5)).Return(str, nil)
+				str.EXPECT().handleExpiredStreamDataFrame(f)
+				err := sess.handleFrames([]wire.Frame{f}, protocol.EncryptionForwardSecure)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("ignores EXPIRED_STREAM_DATA frames for closed streams", func() {
+				streamManager.EXPECT().GetOrOpenReceiveStream(protocol.StreamID(3)).Return(nil, nil)
+				err := sess.handleExpiredStreamDataFrame(&wire.ExpiredStreamDataFrame{StreamID: 3})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("errors for the crypto stream", func() {
+				err := sess.handleExpiredStreamDataFrame(&wire.ExpiredStreamDataFrame{
+					StreamID: sess.version.CryptoStreamID(),
+				})
+				Expect(err).To(MatchError("Received EXPIRED_STREAM_DATA frame for the crypto stream"))
+			})
+
+			It("tells the streams if the peer supports EXPIRED_STREAM_DATA frames", func() {
+				sess.peerParams = nil
+				Expect(sess.peerSupportsExpiredStreamData()).To(BeFalse())
+				sess.peerParams = &handshake.TransportParameters{}
+				Expect(sess.peerSupportsExpiredStreamData()).To(BeFalse())
+				sess.peerParams = &handshake.TransportParameters{ExpiredStreamData: true}
+				Expect(sess.peerSupportsExpiredStreamData()).To(BeTrue())
+			})
+		})
+
 		Context("handling MAX_DATA and MAX_STREAM_DATA frames", func() {
 			var connFC *mocks.MockConnectionFlowController
 
@@ -398,6 +467,63 @@ var _ = Describe("Session", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 
+		It("handles MAX_STREAM_ID frames", func() {
+			f := &wire.MaxStreamIDFrame{StreamID: 10}
+			streamManager.EXPECT().HandleMaxStreamIDFrame(f)
+			err := sess.handleFrames([]wire.Frame{f}, protocol.EncryptionUnspecified)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns errors that occur when handling MAX_STREAM_ID frames", func() {
+			testErr := errors.New("test error")
+			streamManager.EXPECT().HandleMaxStreamIDFrame(gomock.Any()).Return(testErr)
+			err := sess.handleFrames([]wire.Frame{&wire.MaxStreamIDFrame{}}, protocol.EncryptionUnspecified)
+			Expect(err).To(MatchError(testErr))
+		})
+
+		It("counts STREAM_ID_BLOCKED frames", func() {
+			Expect(sess.StreamIDBlockedCount()).To(BeZero())
+			err := sess.handleFrames([]wire.Frame{&wire.StreamIDBlockedFrame{StreamID: 10}}, protocol.EncryptionUnspecified)
+			Expect(err).NotTo(HaveOccurred())
+			err = sess.handleFrames([]wire.Frame{&wire.StreamIDBlockedFrame{StreamID: 10}}, protocol.EncryptionUnspecified)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sess.StreamIDBlockedCount()).To(BeEquivalentTo(2))
+		})
+
+		Context("handling ACK_FREQUENCY frames", func() {
+			It("changes the ACK policy", func() {
+				rph := mockackhandler.NewMockReceivedPacketHandler(mockCtrl)
+				rph.EXPECT().SetAckPolicy(2, 5*time.Millisecond, true)
+				sess.receivedPacketHandler = rph
+				err := sess.handleFrames([]wire.Frame{&wire.AckFrequencyFrame{
+					PacketThreshold:  2,
+					MaxAckDelay:      5 * time.Millisecond,
+					IgnoreReordering: true,
+				}}, protocol.EncryptionForwardSecure)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(sess.ackPolicy).To(Equal(AckPolicy{PacketThreshold: 2, MaxAckDelay: 5 * time.Millisecond, IgnoreReordering: true}))
+			})
+
+			It("limits the maximum ACK delay", func() {
+				rph := mockackhandler.NewMockReceivedPacketHandler(mockCtrl)
+				rph.EXPECT().SetAckPolicy(2, protocol.MaxAckDelayLimit, false)
+				sess.receivedPacketHandler = rph
+				err := sess.handleFrames([]wire.Frame{&wire.AckFrequencyFrame{PacketThreshold: 2, MaxAckDelay: time.Second}}, protocol.EncryptionForwardSecure)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("ignores frames that arrive out of order", func() {
+				rph := mockackhandler.NewMockReceivedPacketHandler(mockCtrl)
+				rph.EXPECT().SetAckPolicy(3, 10*time.Millisecond, false)
+				sess.receivedPacketHandler = rph
+				err := sess.handleFrames([]wire.Frame{&wire.AckFrequencyFrame{SequenceNumber: 5, PacketThreshold: 3, MaxAckDelay: 10 * time.Millisecond}}, protocol.EncryptionForwardSecure)
+				Expect(err).ToNot(HaveOccurred())
+				err = sess.handleFrames([]wire.Frame{&wire.AckFrequencyFrame{SequenceNumber: 4, PacketThreshold: 2, MaxAckDelay: 5 * time.Millisecond}}, protocol.EncryptionForwardSecure)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(sess.ackPolicy.PacketThreshold).To(Equal(3))
+			})
+		})
+
 		It("errors on GOAWAY frames", func() {
 			err := sess.handleFrames([]wire.Frame{&wire.GoawayFrame{}}, protocol.EncryptionUnspecified)
 			Expect(err).To(MatchError("unimplemented: handling GOAWAY frames"))
@@ -542,6 +668,16 @@ var _ = Describe("Session", func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 
+		It("informs the ReceivedPacketHandler about the ECN codepoint", func() {
+			rph := mockackhandler.NewMockReceivedPacketHandler(mockCtrl)
+			rph.EXPECT().ReceivedPacket(protocol.PacketNumber(5), gomock.Any(), false)
+			rph.EXPECT().ReceivedECN(protocol.ECNCE)
+			sess.receivedPacketHandler = rph
+			hdr.PacketNumber = 5
+			err := sess.handlePacketImpl(&receivedPacket{header: hdr, ecn: protocol.ECNCE})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
 		It("closes when handling a packet fails", func(done Done) {
 			streamManager.EXPECT().CloseWithError(gomock.Any())
 			testErr := errors.New("unpack error")
@@ -628,6 +764,22 @@ var _ = Describe("Session", func() {
 			Expect(mconn.written).To(Receive(ContainSubstring(string([]byte{0x03, 0x5e}))))
 		})
 
+		It("sends packets with the ECN codepoint set by the SentPacketHandler", func() {
+			sess.packer.QueueControlFrame(&wire.PingFrame{})
+			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sph.EXPECT().GetLeastUnacked().AnyTimes()
+			sph.EXPECT().DequeuePacketForRetransmission()
+			sph.EXPECT().SentPacket(gomock.Any()).Do(func(p *ackhandler.Packet) {
+				p.ECN = protocol.ECT0
+			})
+			sess.sentPacketHandler = sph
+			sent, err := sess.sendPacket()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sent).To(BeTrue())
+			Expect(mconn.written).To(HaveLen(1))
+			Expect(mconn.ecn).To(Equal(protocol.ECT0))
+		})
+
 		It("adds a MAX_DATA frames", func() {
 			fc := mocks.NewMockConnectionFlowController(mockCtrl)
 			fc.EXPECT().GetWindowUpdate().Return(protocol.ByteCount(0x1337))
@@ -692,7 +844,7 @@ var _ = Describe("Session", func() {
 
 		It("informs the SentPacketHandler about sent packets", func() {
 			f := &wire.StreamFrame{
-				StreamID: 5,
+				StreamID: sess.version.CryptoStreamID(),
 				Data:     []byte("foobar"),
 			}
 			var sentPacket *ackhandler.Packet
@@ -717,6 +869,283 @@ var _ = Describe("Session", func() {
 			Expect(sentPacket.EncryptionLevel).To(Equal(protocol.EncryptionForwardSecure))
 			Expect(sentPacket.Length).To(BeEquivalentTo(len(<-mconn.written)))
 		})
+
+		It("only references the data of STREAM frames in the history of sent packets", func() {
+			f := &wire.StreamFrame{
+				StreamID: 5,
+				Offset:   0x42,
+				Data:     []byte("foobar"),
+				FinBit:   true,
+			}
+			str := NewMockSendStreamI(mockCtrl)
+			str.EXPECT().StreamID().Return(protocol.StreamID(5)).AnyTimes()
+			str.EXPECT().onDataLost(protocol.ByteCount(0x42), protocol.ByteCount(6), true).Return(true)
+			str.EXPECT().popRetransmissionFrame(gomock.Any()).Return(f, false)
+			var sentPacket *ackhandler.Packet
+			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sph.EXPECT().GetLeastUnacked().AnyTimes()
+			sph.EXPECT().GetStopWaitingFrame(gomock.Any())
+			sph.EXPECT().DequeuePacketForRetransmission()
+			sph.EXPECT().SentPacket(gomock.Any()).Do(func(p *ackhandler.Packet) {
+				sentPacket = p
+			})
+			sess.sentPacketHandler = sph
+			sess.packer.cryptoSetup = &mockCryptoSetup{encLevelSeal: protocol.EncryptionForwardSecure}
+
+			sess.streamFramer.AddDataForRetransmission(&sentStreamData{stream: str, offset: 0x42, length: 6, fin: true})
+			sent, err := sess.sendPacket()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sent).To(BeTrue())
+			Expect(mconn.written).To(Receive(ContainSubstring("foobar")))
+			Expect(sentPacket.Frames).To(Equal([]wire.Frame{&sentStreamData{stream: str, offset: 0x42, length: 6, fin: true}}))
+			// the stream is notified when the packet is acknowledged
+			str.EXPECT().onDataAcked(protocol.ByteCount(0x42), protocol.ByteCount(6), true)
+			sentPacket.Frames[0].(ackhandler.AckListener).OnAcked()
+		})
+	})
+
+	Context("forward error correction", func() {
+		BeforeEach(func() {
+			sess.packer.hasSentPacket = true
+			sess.packer.cryptoSetup = &mockCryptoSetup{encLevelSeal: protocol.EncryptionForwardSecure}
+		})
+
+		// sendAll calls sendPackets until all queued packets are sent.
+		// Without an RTT estimate, the sentPacketHandler only allows sending a single packet at a time.
+		sendAll := func() {
+			for {
+				n := len(mconn.written)
+				Expect(sess.sendPackets()).To(Succeed())
+				if len(mconn.written) == n {
+					return
+				}
+			}
+		}
+
+		It("enables FEC if both peers support it", func() {
+			sess.config.FEC = &FECConfig{DataPackets: 5, RepairPackets: 2}
+			streamManager.EXPECT().UpdateLimits(gomock.Any())
+			sess.processTransportParameters(&handshake.TransportParameters{FEC: true})
+			Expect(sess.fecEncoder).ToNot(BeNil())
+			Expect(sess.packer.fecEnabled).To(BeTrue())
+		})
+
+		It("doesn't enable FEC if the peer doesn't support it", func() {
+			sess.config.FEC = &FECConfig{DataPackets: 5, RepairPackets: 2}
+			streamManager.EXPECT().UpdateLimits(gomock.Any())
+			sess.processTransportParameters(&handshake.TransportParameters{})
+			Expect(sess.fecEncoder).To(BeNil())
+			Expect(sess.packer.fecEnabled).To(BeFalse())
+		})
+
+		It("sends repair packets when a block is complete", func() {
+			sess.packer.connectionID = 0x1337 // the header parser rejects a connection ID of 0
+			sess.fecEncoder = fec.NewEncoder(2, 1)
+			for i := 0; i < 2; i++ {
+				sess.streamFramer.AddFrameForRetransmission(&wire.StreamFrame{StreamID: sess.version.CryptoStreamID(), Data: []byte("foobar")})
+				sent, err := sess.sendPacket()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(sent).To(BeTrue())
+			}
+			Expect(mconn.written).To(HaveLen(2))
+			// the repair packet is sent on the next call to sendPackets
+			Expect(sess.repairFrames).To(HaveLen(1))
+			Expect(sess.fecFlushDeadline).To(BeZero())
+			sendAll()
+			Expect(mconn.written).To(HaveLen(3))
+			Expect(sess.repairFrames).To(BeEmpty())
+			p1 := <-mconn.written
+			p2 := <-mconn.written
+			// parse the FEC frame from the repair packet, and use it to recover the second packet
+			r := bytes.NewReader(<-mconn.written)
+			_, err := wire.ParseHeaderSentByServer(r, sess.version)
+			Expect(err).ToNot(HaveOccurred())
+			frame, err := wire.ParseFECFrame(r, sess.version)
+			Expect(err).ToNot(HaveOccurred())
+			decoder := fec.NewDecoder()
+			_, err = decoder.ReceivedPacket(frame.PacketNumbers[0], nil, p1)
+			Expect(err).ToNot(HaveOccurred())
+			recovered, err := decoder.ReceivedFECFrame(frame)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(recovered).To(Equal([][]byte{p2}))
+		})
+
+		It("doesn't protect packets that don't contain retransmittable frames", func() {
+			sess.fecEncoder = fec.NewEncoder(1, 1)
+			err := sess.receivedPacketHandler.ReceivedPacket(1, time.Now(), true)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sess.maybeSendAckOnlyPacket()).To(Succeed())
+			Expect(mconn.written).To(HaveLen(1))
+		})
+
+		It("finishes an incomplete block after the flush delay", func() {
+			sess.fecEncoder = fec.NewEncoder(10, 2)
+			sess.streamFramer.AddFrameForRetransmission(&wire.StreamFrame{StreamID: sess.version.CryptoStreamID(), Data: []byte("foobar")})
+			sendAll()
+			Expect(mconn.written).To(HaveLen(1))
+			Expect(sess.fecFlushDeadline).To(BeTemporally("~", time.Now().Add(protocol.FECFlushDelay), scaleDuration(10*time.Millisecond)))
+			// running out of data doesn't finish the block
+			Expect(sess.sendPackets()).To(Succeed())
+			Expect(mconn.written).To(HaveLen(1))
+			// the flush timer fires
+			sess.flushFECBlock()
+			Expect(sess.fecFlushDeadline).To(BeZero())
+			sendAll()
+			Expect(mconn.written).To(HaveLen(3))
+		})
+
+		It("doesn't multiply the packet count when the application sends a single packet at a time", func() {
+			sess.fecEncoder = fec.NewEncoder(4, 2)
+			for i := 0; i < 8; i++ {
+				sess.streamFramer.AddFrameForRetransmission(&wire.StreamFrame{StreamID: sess.version.CryptoStreamID(), Data: []byte("foobar")})
+				sendAll()
+			}
+			// 8 data packets, and 2 repair packets for each of the 2 blocks
+			Expect(mconn.written).To(HaveLen(8 + 2*2))
+		})
+
+		It("counts repair packets towards the bytes in flight", func() {
+			sess.fecEncoder = fec.NewEncoder(1, 1)
+			sess.streamFramer.AddFrameForRetransmission(&wire.StreamFrame{StreamID: sess.version.CryptoStreamID(), Data: []byte("foobar")})
+			var sentPackets []*ackhandler.Packet
+			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sph.EXPECT().GetLeastUnacked().AnyTimes()
+			sph.EXPECT().GetStopWaitingFrame(gomock.Any()).AnyTimes()
+			sph.EXPECT().DequeuePacketForRetransmission().AnyTimes()
+			sph.EXPECT().ShouldSendNumPackets().Return(10)
+			sph.EXPECT().ShouldSendProbePacket()
+			sph.EXPECT().SendingAllowed().Return(true).Times(3)
+			sph.EXPECT().SentPacket(gomock.Any()).Do(func(p *ackhandler.Packet) { sentPackets = append(sentPackets, p) }).Times(2)
+			sph.EXPECT().SetAppLimited()
+			sess.sentPacketHandler = sph
+			Expect(sess.sendPackets()).To(Succeed())
+			Expect(sentPackets).To(HaveLen(2))
+			Expect(sentPackets[0].IsRepairPacket).To(BeFalse())
+			Expect(sentPackets[1].IsRepairPacket).To(BeTrue())
+		})
+
+		It("doesn't send repair packets when congestion limited", func() {
+			sess.repairFrames = []*wire.FECFrame{{PacketNumbers: []protocol.PacketNumber{1}, NumRepairSymbols: 1, Data: []byte("foobar")}}
+			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sph.EXPECT().GetLeastUnacked().AnyTimes()
+			sph.EXPECT().SendingAllowed().Return(false)
+			sess.sentPacketHandler = sph
+			Expect(sess.sendPackets()).To(Succeed())
+			Expect(mconn.written).To(BeEmpty())
+			Expect(sess.repairFrames).To(HaveLen(1))
+		})
+
+		It("errors when receiving a FEC frame, if FEC is disabled", func() {
+			err := sess.handleFrames([]wire.Frame{&wire.FECFrame{}}, protocol.EncryptionForwardSecure)
+			Expect(err).To(MatchError(qerr.Error(qerr.InvalidFrameData, "received FEC frame, but FEC is not enabled")))
+		})
+
+		It("recovers lost packets, and passes them to the ReceivedPacketHandler", func() {
+			sess.unpacker = &mockUnpacker{encLevel: protocol.EncryptionForwardSecure}
+			sess.fecDecoder = fec.NewDecoder()
+			rph := mockackhandler.NewMockReceivedPacketHandler(mockCtrl)
+			sess.receivedPacketHandler = rph
+			packet := func(pn protocol.PacketNumber, payload string) []byte {
+				buf := &bytes.Buffer{}
+				hdr := &wire.Header{
+					ConnectionID:    0x1337,
+					PacketNumber:    pn,
+					PacketNumberLen: protocol.PacketNumberLen6,
+				}
+				Expect(hdr.Write(buf, protocol.PerspectiveClient, sess.version)).To(Succeed())
+				return append(buf.Bytes(), []byte(payload)...)
+			}
+			p1 := packet(1, "foo")
+			p2 := packet(2, "foobar")
+			encoder := fec.NewEncoder(2, 1)
+			encoder.AddPacket(1, p1)
+			frames := encoder.AddPacket(2, p2)
+			Expect(frames).To(HaveLen(1))
+
+			r := bytes.NewReader(p1)
+			hdr, err := wire.ParseHeaderSentByClient(r)
+			Expect(err).ToNot(HaveOccurred())
+			hdr.Raw = p1[:len(p1)-r.Len()]
+			rph.EXPECT().ReceivedPacket(protocol.PacketNumber(1), gomock.Any(), false)
+			Expect(sess.handlePacketImpl(&receivedPacket{header: hdr, data: p1[len(hdr.Raw):]})).To(Succeed())
+			Expect(sess.handleFrames([]wire.Frame{frames[0]}, protocol.EncryptionForwardSecure)).To(Succeed())
+			Expect(sess.recoveredPackets).To(Equal([][]byte{p2}))
+			rph.EXPECT().ReceivedPacket(protocol.PacketNumber(2), gomock.Any(), false)
+			Expect(sess.handleRecoveredPackets()).To(Succeed())
+			Expect(sess.recoveredPackets).To(BeEmpty())
+		})
+	})
+
+	Context("path MTU discovery", func() {
+		BeforeEach(func() {
+			sess.packer.hasSentPacket = true
+			sess.packer.cryptoSetup = &mockCryptoSetup{encLevelSeal: protocol.EncryptionForwardSecure}
+		})
+
+		It("uses the minimum packet size before path MTU discovery started", func() {
+			Expect(sess.packer.MaxPacketSize()).To(Equal(protocol.MaxPacketSize))
+			Expect(sess.maxStreamDataPerPacket()).To(Equal(protocol.MaxPacketSize - protocol.MaxStreamDataOverhead))
+		})
+
+		It("starts path MTU discovery", func() {
+			sess.startMTUDiscovery()
+			Expect(sess.mtuDiscoverer).ToNot(BeNil())
+			Expect(sess.mtuDiscoverer.CurrentSize()).To(Equal(protocol.MaxPacketSize))
+			Expect(sess.mtuDiscoverer.limit).To(Equal(protocol.MaxReceivePacketSize))
+		})
+
+		It("doesn't probe packet sizes larger than what the peer accepts", func() {
+			sess.peerParams = &handshake.TransportParameters{MaxPacketSize: 1300}
+			sess.startMTUDiscovery()
+			Expect(sess.mtuDiscoverer.limit).To(Equal(protocol.ByteCount(1300)))
+		})
+
+		It("doesn't start path MTU discovery if the maximum packet size is not larger than the minimum", func() {
+			sess.config.MaxPacketSize = sess.config.MinPacketSize
+			sess.startMTUDiscovery()
+			Expect(sess.mtuDiscoverer).To(BeNil())
+		})
+
+		It("doesn't start path MTU discovery for multipath connections", func() {
+			sess.config.Multipath = &MultipathConfig{}
+			sess.startMTUDiscovery()
+			Expect(sess.mtuDiscoverer).To(BeNil())
+		})
+
+		It("sends a probe packet, and increases the packet size when it is acknowledged", func() {
+			sess.mtuDiscoverer = newMTUDiscoverer(1200, 1400)
+			Expect(sess.sendPackets()).To(Succeed())
+			Expect(mconn.written).To(HaveLen(1))
+			Expect(<-mconn.written).To(HaveLen(1300))
+			Expect(sess.mtuDiscoverer.probeInFlight).To(BeTrue())
+			pn := sess.mtuDiscoverer.probePacketNumber
+			sess.lastRcvdPacketNumber = 1
+			err := sess.handleAckFrame(&wire.AckFrame{LargestAcked: pn, LowestAcked: pn}, protocol.EncryptionForwardSecure)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sess.packer.MaxPacketSize()).To(Equal(protocol.ByteCount(1300)))
+			Expect(sess.maxStreamDataPerPacket()).To(Equal(1300 - protocol.MaxStreamDataOverhead))
+		})
+
+		It("doesn't retransmit lost probe packets", func() {
+			sess.mtuDiscoverer = newMTUDiscoverer(1200, 1400)
+			sess.mtuDiscoverer.SentProbe(10, 1300)
+			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sph.EXPECT().GetLeastUnacked().AnyTimes()
+			sph.EXPECT().DequeuePacketForRetransmission().Return(&ackhandler.Packet{
+				PacketNumber:     10,
+				Frames:           []wire.Frame{&wire.PingFrame{}},
+				EncryptionLevel:  protocol.EncryptionForwardSecure,
+				IsMTUProbePacket: true,
+			})
+			sph.EXPECT().DequeuePacketForRetransmission()
+			sess.sentPacketHandler = sph
+			sent, err := sess.sendPacket()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sent).To(BeFalse())
+			Expect(mconn.written).To(BeEmpty())
+			Expect(sess.mtuDiscoverer.probeInFlight).To(BeFalse())
+			Expect(sess.packer.MaxPacketSize()).To(Equal(protocol.MaxPacketSize))
+		})
 	})
 
 	Context("packet pacing", func() {
@@ -727,6 +1156,7 @@ var _ = Describe("Session", func() {
 			sph.EXPECT().GetAlarmTimeout().AnyTimes()
 			sph.EXPECT().GetLeastUnacked().AnyTimes()
 			sph.EXPECT().DequeuePacketForRetransmission().AnyTimes()
+			sph.EXPECT().ShouldSendProbePacket().AnyTimes()
 			sess.sentPacketHandler = sph
 			sess.packer.hasSentPacket = true
 			streamManager.EXPECT().CloseWithError(gomock.Any())
@@ -784,6 +1214,29 @@ var _ = Describe("Session", func() {
 			Eventually(done).Should(BeClosed())
 		})
 
+		It("continues sending, if the pacer allows sending more packets right away", func() {
+			sph.EXPECT().SentPacket(gomock.Any()).Times(2)
+			sph.EXPECT().ShouldSendNumPackets().Return(1).Times(2)
+			sph.EXPECT().TimeUntilSend().Return(time.Time{}).Times(3)
+			sph.EXPECT().TimeUntilSend().Return(time.Now().Add(time.Hour))
+			sph.EXPECT().SendingAllowed().Do(func() {
+				// make sure there's something to send
+				sess.packer.QueueControlFrame(&wire.MaxDataFrame{ByteOffset: 1})
+			}).Return(true).AnyTimes()
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				sess.run()
+				close(done)
+			}()
+			sess.scheduleSending()
+			Eventually(mconn.written).Should(HaveLen(2))
+			Consistently(mconn.written).Should(HaveLen(2))
+			// make the go routine return
+			sess.Close(nil)
+			Eventually(done).Should(BeClosed())
+		})
+
 		It("sends multiple packets at once", func() {
 			sph.EXPECT().SentPacket(gomock.Any()).Times(3)
 			sph.EXPECT().ShouldSendNumPackets().Return(3)
@@ -809,6 +1262,7 @@ var _ = Describe("Session", func() {
 		It("doesn't set a pacing timer when there is no data to send", func() {
 			sph.EXPECT().TimeUntilSend().Return(time.Now())
 			sph.EXPECT().ShouldSendNumPackets().Return(1)
+			sph.EXPECT().SetAppLimited() // we ran out of data to send
 			sph.EXPECT().SendingAllowed().Return(true).AnyTimes()
 			done := make(chan struct{})
 			go func() {
@@ -929,7 +1383,7 @@ var _ = Describe("Session", func() {
 			It("retransmits an unencrypted packet, and doesn't add a STOP_WAITING frame (for IETF QUIC)", func() {
 				sess.version = versionIETFFrames
 				sess.packer.version = versionIETFFrames
-				sf := &wire.StreamFrame{StreamID: 1, Data: []byte("foobar")}
+				sf := &wire.StreamFrame{StreamID: versionIETFFrames.CryptoStreamID(), Data: []byte("foobar")}
 				sph.EXPECT().DequeuePacketForRetransmission().Return(&ackhandler.Packet{
 					Frames:          []wire.Frame{sf},
 					EncryptionLevel: protocol.EncryptionUnencrypted,
@@ -945,10 +1399,67 @@ var _ = Describe("Session", func() {
 			})
 		})
 
+		Context("for handshake packets containing stream data", func() {
+			var str *MockSendStreamI
+
+			BeforeEach(func() {
+				str = NewMockSendStreamI(mockCtrl)
+				str.EXPECT().StreamID().Return(protocol.StreamID(5)).AnyTimes()
+				str.EXPECT().onDataLost(protocol.ByteCount(0), protocol.ByteCount(6), false).Return(true)
+				str.EXPECT().popRetransmissionFrame(gomock.Any()).Return(&wire.StreamFrame{StreamID: 5, Data: []byte("foobar")}, false)
+			})
+
+			It("retransmits the stream data in a forward-secure packet", func() {
+				sph.EXPECT().DequeuePacketForRetransmission().Return(&ackhandler.Packet{
+					Frames:          []wire.Frame{&sentStreamData{stream: str, length: 6}},
+					EncryptionLevel: protocol.EncryptionSecure,
+				})
+				sph.EXPECT().DequeuePacketForRetransmission()
+				sph.EXPECT().GetStopWaitingFrame(true)
+				sph.EXPECT().SentPacket(gomock.Any()).Do(func(p *ackhandler.Packet) {
+					Expect(p.EncryptionLevel).To(Equal(protocol.EncryptionForwardSecure))
+					Expect(p.Frames).To(Equal([]wire.Frame{&sentStreamData{stream: str, length: 6}}))
+				})
+				sent, err := sess.sendPacket()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(sent).To(BeTrue())
+				Expect(mconn.written).To(Receive(ContainSubstring("foobar")))
+			})
+
+			It("retransmits the other frames in a handshake packet", func() {
+				sf := &wire.StreamFrame{StreamID: 1, Data: []byte("handshake")}
+				swf := &wire.StopWaitingFrame{LeastUnacked: 0x1337}
+				sph.EXPECT().GetStopWaitingFrame(true).Return(swf)
+				sph.EXPECT().DequeuePacketForRetransmission().Return(&ackhandler.Packet{
+					Frames:          []wire.Frame{&sentStreamData{stream: str, length: 6}, sf},
+					EncryptionLevel: protocol.EncryptionSecure,
+				})
+				sph.EXPECT().SentPacket(gomock.Any()).Do(func(p *ackhandler.Packet) {
+					Expect(p.EncryptionLevel).To(Equal(protocol.EncryptionSecure))
+					Expect(p.Frames).To(Equal([]wire.Frame{swf, sf}))
+				})
+				sent, err := sess.sendPacket()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(sent).To(BeTrue())
+				Expect(mconn.written).To(Receive(ContainSubstring("handshake")))
+				// the stream data is sent in the next packet
+				sph.EXPECT().DequeuePacketForRetransmission()
+				sph.EXPECT().GetStopWaitingFrame(true)
+				sph.EXPECT().SentPacket(gomock.Any()).Do(func(p *ackhandler.Packet) {
+					Expect(p.EncryptionLevel).To(Equal(protocol.EncryptionForwardSecure))
+					Expect(p.Frames).To(Equal([]wire.Frame{&sentStreamData{stream: str, length: 6}}))
+				})
+				sent, err = sess.sendPacket()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(sent).To(BeTrue())
+				Expect(mconn.written).To(Receive(ContainSubstring("foobar")))
+			})
+		})
+
 		Context("for packets after the handshake", func() {
 			It("sends a STREAM frame from a packet queued for retransmission, and adds a STOP_WAITING (for gQUIC)", func() {
 				f := &wire.StreamFrame{
-					StreamID: 0x5,
+					StreamID: sess.version.CryptoStreamID(),
 					Data:     []byte("foobar"),
 				}
 				swf := &wire.StopWaitingFrame{LeastUnacked: 10}
@@ -973,7 +1484,7 @@ var _ = Describe("Session", func() {
 				sess.version = versionIETFFrames
 				sess.packer.version = versionIETFFrames
 				f := &wire.StreamFrame{
-					StreamID: 0x5,
+					StreamID: sess.version.CryptoStreamID(),
 					Data:     []byte("foobar"),
 				}
 				sph.EXPECT().DequeuePacketForRetransmission().Return(&ackhandler.Packet{
@@ -993,11 +1504,12 @@ var _ = Describe("Session", func() {
 
 			It("sends a STREAM frame from a packet queued for retransmission", func() {
 				f1 := wire.StreamFrame{
-					StreamID: 0x5,
+					StreamID: sess.version.CryptoStreamID(),
 					Data:     []byte("foobar"),
 				}
 				f2 := wire.StreamFrame{
-					StreamID: 0x7,
+					StreamID: sess.version.CryptoStreamID(),
+					Offset:   6,
 					Data:     []byte("loremipsum"),
 				}
 				p1 := &ackhandler.Packet{
@@ -1028,7 +1540,7 @@ var _ = Describe("Session", func() {
 		})
 	})
 
-	It("retransmits RTO packets", func() {
+	It("sends a probe packet when the PTO expires", func() {
 		sess.packer.hasSentPacket = true // make sure this is not the first packet the packer sends
 		sess.sentPacketHandler.SetHandshakeComplete()
 		n := protocol.PacketNumber(10)
@@ -1038,12 +1550,14 @@ var _ = Describe("Session", func() {
 		sess.rttStats.UpdateRTT(rtt, 0, time.Now())
 		Expect(sess.rttStats.SmoothedRTT()).To(Equal(rtt)) // make sure it worked
 		sess.packer.packetNumberGenerator.next = n + 1
-		// Now, we send a single packet, and expect that it was retransmitted later
+		// Now, we send a single packet, and expect that a probe packet is sent later.
+		// The packet is not retransmitted before the peer acknowledges the probe packet.
 		err := sess.sentPacketHandler.SentPacket(&ackhandler.Packet{
 			PacketNumber: n,
 			Length:       1,
 			Frames: []wire.Frame{&wire.StreamFrame{
-				Data: []byte("foobar"),
+				StreamID: sess.version.CryptoStreamID(),
+				Data:     []byte("foobar"),
 			}},
 			EncryptionLevel: protocol.EncryptionForwardSecure,
 		})
@@ -1052,7 +1566,7 @@ var _ = Describe("Session", func() {
 		defer sess.Close(nil)
 		sess.scheduleSending()
 		Eventually(func() int { return len(mconn.written) }).ShouldNot(BeZero())
-		Expect(mconn.written).To(Receive(ContainSubstring("foobar")))
+		Expect(mconn.written).ToNot(Receive(ContainSubstring("foobar")))
 		streamManager.EXPECT().CloseWithError(gomock.Any())
 	})
 
@@ -1070,7 +1584,7 @@ var _ = Describe("Session", func() {
 				close(done)
 			}()
 			sess.streamFramer.AddFrameForRetransmission(&wire.StreamFrame{
-				StreamID: 5,
+				StreamID: sess.version.CryptoStreamID(),
 				Data:     []byte("foobar"),
 			})
 			Consistently(mconn.written).ShouldNot(Receive())
@@ -1260,6 +1774,7 @@ var _ = Describe("Session", func() {
 	})
 
 	It("closes the handshakeChan when the handshake completes", func() {
+		sess.packer.cryptoSetup = &mockCryptoSetup{encLevelSeal: protocol.EncryptionForwardSecure}
 		done := make(chan struct{})
 		go func() {
 			defer GinkgoRecover()
@@ -1316,6 +1831,91 @@ var _ = Describe("Session", func() {
 		Eventually(done).Should(BeClosed())
 	})
 
+	It("uses the maximum ACK delay sent by the peer", func() {
+		sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+		sph.EXPECT().SetMaxAckDelay(42 * time.Millisecond)
+		sess.sentPacketHandler = sph
+		params := &handshake.TransportParameters{MaxAckDelay: 42 * time.Millisecond}
+		streamManager.EXPECT().UpdateLimits(params)
+		sess.processTransportParameters(params)
+		Expect(sess.peerMaxAckDelay).To(Equal(42 * time.Millisecond))
+	})
+
+	Context("changing the peer's ACK policy", func() {
+		BeforeEach(func() {
+			sess.handshakeComplete = true
+			sess.peerParams = &handshake.TransportParameters{AckFrequency: true}
+		})
+
+		It("sends ACK_FREQUENCY frames", func() {
+			Expect(sess.setPeerAckPolicy(&AckPolicy{PacketThreshold: 2, MaxAckDelay: 5 * time.Millisecond, IgnoreReordering: true})).To(Succeed())
+			Expect(sess.setPeerAckPolicy(nil)).To(Succeed())
+			Expect(sess.packer.controlFrames).To(HaveLen(2))
+			Expect(sess.packer.controlFrames[0]).To(BeAssignableToTypeOf(&sentAckFrequencyFrame{}))
+			Expect(sess.packer.controlFrames[0].(*sentAckFrequencyFrame).AckFrequencyFrame).To(Equal(
+				&wire.AckFrequencyFrame{SequenceNumber: 0, PacketThreshold: 2, MaxAckDelay: 5 * time.Millisecond, IgnoreReordering: true},
+			))
+			Expect(sess.packer.controlFrames[1]).To(BeAssignableToTypeOf(&sentAckFrequencyFrame{}))
+			Expect(sess.packer.controlFrames[1].(*sentAckFrequencyFrame).AckFrequencyFrame).To(Equal(
+				&wire.AckFrequencyFrame{SequenceNumber: 1, PacketThreshold: protocol.RetransmittablePacketsBeforeAck, MaxAckDelay: protocol.AckSendDelay},
+			))
+		})
+
+		It("raises the maximum ACK delay immediately", func() {
+			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sph.EXPECT().SetMaxAckDelay(100 * time.Millisecond)
+			sess.sentPacketHandler = sph
+			Expect(sess.setPeerAckPolicy(&AckPolicy{PacketThreshold: 2, MaxAckDelay: 100 * time.Millisecond})).To(Succeed())
+			Expect(sess.peerMaxAckDelay).To(Equal(100 * time.Millisecond))
+			// the delay doesn't change when the frame is acknowledged
+			sph.EXPECT().SetMaxAckDelay(100 * time.Millisecond)
+			sess.packer.controlFrames[0].(ackhandler.AckListener).OnAcked()
+			Expect(sess.peerMaxAckDelay).To(Equal(100 * time.Millisecond))
+		})
+
+		It("lowers the maximum ACK delay when the ACK_FREQUENCY frame is acknowledged", func() {
+			sess.peerMaxAckDelay = 50 * time.Millisecond
+			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sess.sentPacketHandler = sph
+			Expect(sess.setPeerAckPolicy(&AckPolicy{PacketThreshold: 2, MaxAckDelay: 5 * time.Millisecond})).To(Succeed())
+			Expect(sess.peerMaxAckDelay).To(Equal(50 * time.Millisecond))
+			sph.EXPECT().SetMaxAckDelay(5 * time.Millisecond)
+			sess.packer.controlFrames[0].(ackhandler.AckListener).OnAcked()
+			Expect(sess.peerMaxAckDelay).To(Equal(5 * time.Millisecond))
+		})
+
+		It("only uses the maximum ACK delay of the most recent ACK_FREQUENCY frame", func() {
+			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sph.EXPECT().SetMaxAckDelay(100 * time.Millisecond)
+			sess.sentPacketHandler = sph
+			Expect(sess.setPeerAckPolicy(&AckPolicy{PacketThreshold: 2, MaxAckDelay: 100 * time.Millisecond})).To(Succeed())
+			Expect(sess.setPeerAckPolicy(&AckPolicy{PacketThreshold: 2, MaxAckDelay: 5 * time.Millisecond})).To(Succeed())
+			Expect(sess.peerMaxAckDelay).To(Equal(100 * time.Millisecond))
+			// the peer might still receive the second frame, so we can't lower the delay yet
+			sess.packer.controlFrames[0].(ackhandler.AckListener).OnAcked()
+			Expect(sess.peerMaxAckDelay).To(Equal(100 * time.Millisecond))
+			sph.EXPECT().SetMaxAckDelay(5 * time.Millisecond)
+			sess.packer.controlFrames[1].(ackhandler.AckListener).OnAcked()
+			Expect(sess.peerMaxAckDelay).To(Equal(5 * time.Millisecond))
+		})
+
+		It("doesn't change the ACK policy before the handshake completes", func() {
+			sess.handshakeComplete = false
+			Expect(sess.setPeerAckPolicy(&AckPolicy{})).To(MatchError("the ACK policy can only be changed after the handshake completed"))
+		})
+
+		It("doesn't change the ACK policy if the peer doesn't support ACK_FREQUENCY frames", func() {
+			sess.peerParams = &handshake.TransportParameters{}
+			Expect(sess.setPeerAckPolicy(&AckPolicy{})).To(MatchError("the peer doesn't support ACK_FREQUENCY frames"))
+			Expect(sess.packer.controlFrames).To(BeEmpty())
+		})
+
+		It("errors when changing the ACK policy of a closed session", func() {
+			sess.ctxCancel()
+			Expect(sess.SetPeerAckPolicy(&AckPolicy{})).To(MatchError("session already closed"))
+		})
+	})
+
 	Context("keep-alives", func() {
 		// should be shorter than the local timeout for these tests
 		// otherwise we'd send a CONNECTION_CLOSE in the tests where we're testing that no PING is sent
@@ -1422,6 +2022,7 @@ var _ = Describe("Session", func() {
 
 		It("closes the session due to the idle timeout after handshake", func() {
 			sess.config.IdleTimeout = 0
+			sess.config.MaxPacketSize = sess.config.MinPacketSize // don't send MTU probe packets
 			close(handshakeChan)
 			errChan := make(chan error)
 			go func() {
@@ -1463,6 +2064,11 @@ var _ = Describe("Session", func() {
 			Expect(ok).To(BeFalse())
 		})
 
+		It("sets the stream limit", func() {
+			streamManager.EXPECT().SetMaxIncomingStreams(1337)
+			Expect(sess.SetMaxIncomingStreams(1337)).To(Succeed())
+		})
+
 		// all relevant tests for this are in the streamsMap
 		It("opens streams synchronously", func() {
 			mstr := NewMockStreamI(mockCtrl)
@@ -1501,6 +2107,80 @@ var _ = Describe("Session", func() {
 		mconn.remoteAddr = addr
 		Expect(sess.RemoteAddr()).To(Equal(addr))
 	})
+
+	Context("multipath", func() {
+		var (
+			pconn    *mockPacketConn
+			pathAddr *net.UDPAddr
+			hdr      *wire.Header
+		)
+
+		BeforeEach(func() {
+			pconn = newMockPacketConn()
+			sess.conn = &conn{pconn: pconn, currentAddr: &net.UDPAddr{IP: net.IPv4(192, 168, 13, 37), Port: 1000}}
+			sess.config.Multipath = &MultipathConfig{}
+			sess.packer.hasSentPacket = true
+			cryptoSetup.encLevelSeal = protocol.EncryptionForwardSecure
+			sess.unpacker = &mockUnpacker{encLevel: protocol.EncryptionForwardSecure}
+			pathAddr = &net.UDPAddr{IP: net.IPv4(192, 168, 13, 38), Port: 2000}
+			hdr = &wire.Header{
+				ConnectionID:    0x1337,
+				PathID:          1,
+				PacketNumber:    1,
+				PacketNumberLen: protocol.PacketNumberLen6,
+			}
+		})
+
+		It("refuses to add paths", func() {
+			Expect(sess.AddPath(newMockPacketConn())).To(MatchError("only the client can add paths"))
+		})
+
+		It("accepts a new path", func() {
+			err := sess.handlePacketImpl(&receivedPacket{header: hdr, remoteAddr: pathAddr})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sess.paths).To(HaveKey(protocol.PathID(1)))
+			Expect(sess.paths[1].largestRcvdPacketNumber).To(Equal(protocol.PacketNumber(1)))
+			// the path is probed using the server's socket
+			Expect(pconn.dataWritten.Len()).ToNot(BeZero())
+			Expect(pconn.dataWrittenTo).To(Equal(pathAddr))
+		})
+
+		It("applies ACK_FREQUENCY frames to all paths", func() {
+			err := sess.handlePacketImpl(&receivedPacket{header: hdr, remoteAddr: pathAddr})
+			Expect(err).ToNot(HaveOccurred())
+			rph := mockackhandler.NewMockReceivedPacketHandler(mockCtrl)
+			rph.EXPECT().SetAckPolicy(2, 5*time.Millisecond, false)
+			sess.receivedPacketHandler = rph
+			pathRph := mockackhandler.NewMockReceivedPacketHandler(mockCtrl)
+			pathRph.EXPECT().SetAckPolicy(2, 5*time.Millisecond, false)
+			sess.paths[1].receivedPacketHandler = pathRph
+			err = sess.handleFrames([]wire.Frame{&wire.AckFrequencyFrame{PacketThreshold: 2, MaxAckDelay: 5 * time.Millisecond}}, protocol.EncryptionForwardSecure)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("doesn't accept packets on a new path that were not sent forward-secure", func() {
+			sess.unpacker = &mockUnpacker{encLevel: protocol.EncryptionSecure}
+			err := sess.handlePacketImpl(&receivedPacket{header: hdr, remoteAddr: pathAddr})
+			Expect(err).To(MatchError(qerr.Error(qerr.BadMultipathFlag, "received a packet on path 1 before completing the handshake")))
+			Expect(sess.paths).To(BeEmpty())
+		})
+
+		It("errors when receiving a packet on a new path, if multipath is not enabled", func() {
+			sess.config.Multipath = nil
+			err := sess.handlePacketImpl(&receivedPacket{header: hdr, remoteAddr: pathAddr})
+			Expect(err).To(MatchError(qerr.Error(qerr.BadMultipathFlag, "received a packet on path 1, but multipath is not enabled")))
+		})
+
+		It("limits the number of paths", func() {
+			for i := 1; i < protocol.MaxPaths; i++ {
+				hdr.PathID = protocol.PathID(i)
+				Expect(sess.handlePacketImpl(&receivedPacket{header: hdr, remoteAddr: pathAddr})).To(Succeed())
+			}
+			hdr.PathID = protocol.MaxPaths
+			err := sess.handlePacketImpl(&receivedPacket{header: hdr, remoteAddr: pathAddr})
+			Expect(err).To(MatchError(qerr.Error(qerr.BadMultipathFlag, fmt.Sprintf("too many paths (maximum %d)", protocol.MaxPaths))))
+		})
+	})
 })
 
 var _ = Describe("Client Session", func() {
@@ -1553,6 +2233,7 @@ var _ = Describe("Client Session", func() {
 
 	It("sends a forward-secure packet when the handshake completes", func() {
 		sess.packer.hasSentPacket = true
+		cryptoSetup.encLevelSeal = protocol.EncryptionForwardSecure
 		done := make(chan struct{})
 		go func() {
 			defer GinkgoRecover()
@@ -1592,4 +2273,60 @@ var _ = Describe("Client Session", func() {
 			Eventually(done).Should(BeClosed())
 		})
 	})
+
+	Context("adding paths", func() {
+		var pconn *mockPacketConn
+
+		BeforeEach(func() {
+			pconn = newMockPacketConn()
+			sess.config.Multipath = &MultipathConfig{}
+			sess.handshakeComplete = true
+			sess.peerParams = &handshake.TransportParameters{Multipath: true}
+			sess.packer.hasSentPacket = true
+			cryptoSetup.encLevelSeal = protocol.EncryptionForwardSecure
+		})
+
+		AfterEach(func() {
+			pconn.Close()
+		})
+
+		It("adds a path", func() {
+			addr := &net.UDPAddr{IP: net.IPv4(192, 168, 13, 37), Port: 1000}
+			mconn.remoteAddr = addr
+			Expect(sess.addPath(pconn)).To(Succeed())
+			Expect(sess.paths).To(HaveKey(protocol.PathID(1)))
+			// the path is probed
+			Expect(pconn.dataWritten.Len()).ToNot(BeZero())
+			Expect(pconn.dataWrittenTo).To(Equal(addr))
+			Expect(sess.addPath(newMockPacketConn())).To(Succeed())
+			Expect(sess.paths).To(HaveKey(protocol.PathID(2)))
+		})
+
+		It("doesn't add paths if multipath is not enabled", func() {
+			sess.config.Multipath = nil
+			Expect(sess.addPath(pconn)).To(MatchError("multipath is not enabled"))
+		})
+
+		It("doesn't add paths before the handshake completes", func() {
+			sess.handshakeComplete = false
+			Expect(sess.addPath(pconn)).To(MatchError("paths can only be added after the handshake completed"))
+		})
+
+		It("doesn't add paths if the peer doesn't support multipath", func() {
+			sess.peerParams = &handshake.TransportParameters{}
+			Expect(sess.addPath(pconn)).To(MatchError("the peer doesn't support multipath"))
+		})
+
+		It("limits the number of paths", func() {
+			for i := 1; i < protocol.MaxPaths; i++ {
+				Expect(sess.addPath(newMockPacketConn())).To(Succeed())
+			}
+			Expect(sess.addPath(pconn)).To(MatchError(fmt.Sprintf("too many paths (maximum %d)", protocol.MaxPaths)))
+		})
+
+		It("errors when adding a path to a closed session", func() {
+			sess.ctxCancel()
+			Expect(sess.AddPath(pconn)).To(MatchError("session already closed"))
+		})
+	})
 })
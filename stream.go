@@ -21,6 +21,10 @@ type streamSender interface {
 	onHasWindowUpdate(protocol.StreamID)
 	onHasStreamData(protocol.StreamID)
 	onStreamCompleted(protocol.StreamID)
+	// peerSupportsExpiredStreamData says if the peer announced support for EXPIRED_STREAM_DATA frames
+	peerSupportsExpiredStreamData() bool
+	// maxStreamDataPerPacket is the amount of stream data that fits into a packet of the current maximum packet size
+	maxStreamDataPerPacket() protocol.ByteCount
 }
 
 // Each of the both stream halves gets its own uniStreamSender.
@@ -54,11 +58,15 @@ type streamI interface {
 	// for receiving
 	handleStreamFrame(*wire.StreamFrame) error
 	handleRstStreamFrame(*wire.RstStreamFrame) error
+	handleExpiredStreamDataFrame(*wire.ExpiredStreamDataFrame) error
 	getWindowUpdate() protocol.ByteCount
 	// for sending
 	handleStopSendingFrame(*wire.StopSendingFrame)
 	popStreamFrame(maxBytes protocol.ByteCount) (*wire.StreamFrame, bool)
 	handleMaxStreamDataFrame(*wire.MaxStreamDataFrame)
+	popRetransmissionFrame(maxBytes protocol.ByteCount) (*wire.StreamFrame, bool)
+	onDataAcked(offset, length protocol.ByteCount, fin bool)
+	onDataLost(offset, length protocol.ByteCount, fin bool) bool
 }
 
 var _ receiveStreamI = (streamI)(nil)
@@ -12,7 +12,15 @@ type streamFramer struct {
 	cryptoStream cryptoStreamI
 	version      protocol.VersionNumber
 
-	retransmissionQueue []*wire.StreamFrame
+	// STREAM frames of the crypto stream are retransmitted as they were sent.
+	// All other streams retransmit lost data from their send buffers.
+	cryptoRetransmissionQueue  []*wire.StreamFrame
+	retransmissionQueue        []sendStreamI
+	streamsWithRetransmissions map[protocol.StreamID]struct{}
+	// The streams that STREAM frames were popped from in the last call to PopStreamFrames.
+	// Streams are deleted from the streams map once the FIN was sent,
+	// so this is the only way to find the stream when the packet is sent.
+	poppedStreams []sendStreamI
 
 	streamQueueMutex    sync.Mutex
 	activeStreams       map[protocol.StreamID]struct{}
@@ -26,15 +34,30 @@ func newStreamFramer(
 	v protocol.VersionNumber,
 ) *streamFramer {
 	return &streamFramer{
-		streamGetter:  streamGetter,
-		cryptoStream:  cryptoStream,
-		activeStreams: make(map[protocol.StreamID]struct{}),
-		version:       v,
+		streamGetter:               streamGetter,
+		cryptoStream:               cryptoStream,
+		activeStreams:              make(map[protocol.StreamID]struct{}),
+		streamsWithRetransmissions: make(map[protocol.StreamID]struct{}),
+		version:                    v,
 	}
 }
 
 func (f *streamFramer) AddFrameForRetransmission(frame *wire.StreamFrame) {
-	f.retransmissionQueue = append(f.retransmissionQueue, frame)
+	f.cryptoRetransmissionQueue = append(f.cryptoRetransmissionQueue, frame)
+}
+
+// AddDataForRetransmission is called when a packet containing stream data was lost.
+// The data is retransmitted from the send buffer of the stream.
+func (f *streamFramer) AddDataForRetransmission(d *sentStreamData) {
+	if !d.stream.onDataLost(d.offset, d.length, d.fin) {
+		return
+	}
+	id := d.stream.StreamID()
+	if _, ok := f.streamsWithRetransmissions[id]; ok {
+		return
+	}
+	f.streamsWithRetransmissions[id] = struct{}{}
+	f.retransmissionQueue = append(f.retransmissionQueue, d.stream)
 }
 
 func (f *streamFramer) AddActiveStream(id protocol.StreamID) {
@@ -53,12 +76,34 @@ func (f *streamFramer) AddActiveStream(id protocol.StreamID) {
 }
 
 func (f *streamFramer) PopStreamFrames(maxLen protocol.ByteCount) []*wire.StreamFrame {
+	for i := range f.poppedStreams {
+		f.poppedStreams[i] = nil
+	}
+	f.poppedStreams = f.poppedStreams[:0]
 	fs, currentLen := f.maybePopFramesForRetransmission(maxLen)
 	return append(fs, f.maybePopNormalFrames(maxLen-currentLen)...)
 }
 
+// GetPoppedStream returns the stream that a STREAM frame returned by the last call to PopStreamFrames was popped from.
+// It returns nil for frames of the crypto stream.
+func (f *streamFramer) GetPoppedStream(id protocol.StreamID) sendStreamI {
+	for _, str := range f.poppedStreams {
+		if str.StreamID() == id {
+			return str
+		}
+	}
+	return nil
+}
+
+// addPoppedStream must be called every time a STREAM frame is popped from a stream
+func (f *streamFramer) addPoppedStream(str sendStreamI) {
+	if f.GetPoppedStream(str.StreamID()) == nil {
+		f.poppedStreams = append(f.poppedStreams, str)
+	}
+}
+
 func (f *streamFramer) HasFramesForRetransmission() bool {
-	return len(f.retransmissionQueue) > 0
+	return len(f.cryptoRetransmissionQueue) > 0 || len(f.retransmissionQueue) > 0
 }
 
 func (f *streamFramer) HasCryptoStreamData() bool {
@@ -77,8 +122,8 @@ func (f *streamFramer) PopCryptoStreamFrame(maxLen protocol.ByteCount) *wire.Str
 }
 
 func (f *streamFramer) maybePopFramesForRetransmission(maxTotalLen protocol.ByteCount) (res []*wire.StreamFrame, currentLen protocol.ByteCount) {
-	for len(f.retransmissionQueue) > 0 {
-		frame := f.retransmissionQueue[0]
+	for len(f.cryptoRetransmissionQueue) > 0 {
+		frame := f.cryptoRetransmissionQueue[0]
 		frame.DataLenPresent = true
 
 		frameHeaderLen := frame.MinLength(f.version) // can never error
@@ -94,10 +139,31 @@ func (f *streamFramer) maybePopFramesForRetransmission(maxTotalLen protocol.Byte
 			break
 		}
 
-		f.retransmissionQueue = f.retransmissionQueue[1:]
+		f.cryptoRetransmissionQueue = f.cryptoRetransmissionQueue[1:]
 		res = append(res, frame)
 		currentLen += frameHeaderLen + frame.DataLen()
 	}
+
+	for len(f.retransmissionQueue) > 0 {
+		if maxTotalLen-currentLen < protocol.MinStreamFrameSize {
+			break
+		}
+		str := f.retransmissionQueue[0]
+		frame, hasMoreRetransmissions := str.popRetransmissionFrame(maxTotalLen - currentLen)
+		if !hasMoreRetransmissions {
+			f.retransmissionQueue = f.retransmissionQueue[1:]
+			delete(f.streamsWithRetransmissions, str.StreamID())
+		}
+		if frame == nil {
+			if hasMoreRetransmissions { // the frame didn't fit into the packet
+				break
+			}
+			continue
+		}
+		f.addPoppedStream(str)
+		res = append(res, frame)
+		currentLen += frame.MinLength(f.version) + frame.DataLen()
+	}
 	return
 }
 
@@ -130,6 +196,7 @@ func (f *streamFramer) maybePopNormalFrames(maxTotalLen protocol.ByteCount) []*w
 		if frame == nil { // can happen if the receiveStream was canceled after it said it had data
 			continue
 		}
+		f.addPoppedStream(str)
 		frames = append(frames, frame)
 		currentLen += frame.MinLength(f.version) + frame.DataLen()
 	}
@@ -124,6 +124,89 @@ var _ = Describe("Stream Framer", func() {
 			Expect(fs).To(Equal([]*wire.StreamFrame{retransmittedFrame1}))
 		})
 
+		Context("retransmitting from the send buffer", func() {
+			It("says if it has retransmissions", func() {
+				stream1.EXPECT().onDataLost(protocol.ByteCount(10), protocol.ByteCount(20), false).Return(true)
+				framer.AddDataForRetransmission(&sentStreamData{stream: stream1, offset: 10, length: 20})
+				Expect(framer.HasFramesForRetransmission()).To(BeTrue())
+			})
+
+			It("doesn't queue a stream that has nothing to retransmit", func() {
+				stream1.EXPECT().onDataLost(protocol.ByteCount(10), protocol.ByteCount(20), false).Return(false)
+				framer.AddDataForRetransmission(&sentStreamData{stream: stream1, offset: 10, length: 20})
+				Expect(framer.HasFramesForRetransmission()).To(BeFalse())
+				Expect(framer.PopStreamFrames(1000)).To(BeEmpty())
+			})
+
+			It("pops frames for retransmission", func() {
+				f := &wire.StreamFrame{StreamID: 5, Data: []byte("foobar")}
+				stream1.EXPECT().onDataLost(gomock.Any(), gomock.Any(), gomock.Any()).Return(true)
+				stream1.EXPECT().popRetransmissionFrame(protocol.ByteCount(1000)).Return(f, false)
+				framer.AddDataForRetransmission(&sentStreamData{stream: stream1, offset: 10, length: 6})
+				Expect(framer.PopStreamFrames(1000)).To(Equal([]*wire.StreamFrame{f}))
+				Expect(framer.HasFramesForRetransmission()).To(BeFalse())
+				// make sure the stream is actually removed, and not asked a second time
+				Expect(framer.PopStreamFrames(1000)).To(BeEmpty())
+			})
+
+			It("only queues a stream once", func() {
+				f := &wire.StreamFrame{StreamID: 5, Data: []byte("foobar")}
+				stream1.EXPECT().onDataLost(gomock.Any(), gomock.Any(), gomock.Any()).Return(true).Times(2)
+				stream1.EXPECT().popRetransmissionFrame(gomock.Any()).Return(f, false) // only one call to this function
+				framer.AddDataForRetransmission(&sentStreamData{stream: stream1, offset: 10, length: 3})
+				framer.AddDataForRetransmission(&sentStreamData{stream: stream1, offset: 13, length: 3})
+				Expect(framer.PopStreamFrames(1000)).To(Equal([]*wire.StreamFrame{f}))
+			})
+
+			It("pops from a stream multiple times, if it has more retransmissions", func() {
+				f1 := &wire.StreamFrame{StreamID: 5, Data: []byte("foo")}
+				f2 := &wire.StreamFrame{StreamID: 5, Offset: 100, Data: []byte("bar")}
+				stream1.EXPECT().onDataLost(gomock.Any(), gomock.Any(), gomock.Any()).Return(true)
+				gomock.InOrder(
+					stream1.EXPECT().popRetransmissionFrame(protocol.ByteCount(1000)).Return(f1, true),
+					stream1.EXPECT().popRetransmissionFrame(protocol.ByteCount(1000)-f1.MinLength(framer.version)-3).Return(f2, false),
+				)
+				framer.AddDataForRetransmission(&sentStreamData{stream: stream1})
+				Expect(framer.PopStreamFrames(1000)).To(Equal([]*wire.StreamFrame{f1, f2}))
+			})
+
+			It("pops retransmissions of multiple streams in the order the data was lost", func() {
+				f1 := &wire.StreamFrame{StreamID: 5, Data: []byte("foo")}
+				f2 := &wire.StreamFrame{StreamID: 6, Data: []byte("bar")}
+				stream1.EXPECT().onDataLost(gomock.Any(), gomock.Any(), gomock.Any()).Return(true)
+				stream2.EXPECT().onDataLost(gomock.Any(), gomock.Any(), gomock.Any()).Return(true)
+				stream2.EXPECT().popRetransmissionFrame(gomock.Any()).Return(f2, false)
+				stream1.EXPECT().popRetransmissionFrame(gomock.Any()).Return(f1, false)
+				framer.AddDataForRetransmission(&sentStreamData{stream: stream2})
+				framer.AddDataForRetransmission(&sentStreamData{stream: stream1})
+				Expect(framer.PopStreamFrames(1000)).To(Equal([]*wire.StreamFrame{f2, f1}))
+			})
+
+			It("keeps a stream queued, if its retransmission didn't fit into the packet", func() {
+				stream1.EXPECT().onDataLost(gomock.Any(), gomock.Any(), gomock.Any()).Return(true)
+				stream1.EXPECT().popRetransmissionFrame(gomock.Any()).Return(nil, true)
+				framer.AddDataForRetransmission(&sentStreamData{stream: stream1})
+				Expect(framer.PopStreamFrames(1000)).To(BeEmpty())
+				Expect(framer.HasFramesForRetransmission()).To(BeTrue())
+			})
+
+			It("doesn't ask streams for retransmissions, if the remaining size is smaller than the minimum STREAM frame size", func() {
+				stream1.EXPECT().onDataLost(gomock.Any(), gomock.Any(), gomock.Any()).Return(true)
+				framer.AddDataForRetransmission(&sentStreamData{stream: stream1})
+				Expect(framer.PopStreamFrames(protocol.MinStreamFrameSize - 1)).To(BeEmpty())
+				Expect(framer.HasFramesForRetransmission()).To(BeTrue())
+			})
+
+			It("retransmits frames of the crypto stream first", func() {
+				f := &wire.StreamFrame{StreamID: 5, Data: []byte("foobar")}
+				stream1.EXPECT().onDataLost(gomock.Any(), gomock.Any(), gomock.Any()).Return(true)
+				stream1.EXPECT().popRetransmissionFrame(gomock.Any()).Return(f, false)
+				framer.AddDataForRetransmission(&sentStreamData{stream: stream1})
+				framer.AddFrameForRetransmission(retransmittedFrame2)
+				Expect(framer.PopStreamFrames(1000)).To(Equal([]*wire.StreamFrame{retransmittedFrame2, f}))
+			})
+		})
+
 		It("returns normal frames", func() {
 			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil)
 			f := &wire.StreamFrame{
@@ -227,6 +310,22 @@ var _ = Describe("Stream Framer", func() {
 			Expect(framer.PopStreamFrames(1000)).To(HaveLen(1))
 		})
 
+		It("remembers which streams frames were popped from", func() {
+			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil)
+			framer.AddActiveStream(id1)
+			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&wire.StreamFrame{StreamID: 5, Data: []byte("foo")}, false)
+			stream2.EXPECT().onDataLost(gomock.Any(), gomock.Any(), gomock.Any()).Return(true)
+			stream2.EXPECT().popRetransmissionFrame(gomock.Any()).Return(&wire.StreamFrame{StreamID: 6, Data: []byte("bar")}, false)
+			framer.AddDataForRetransmission(&sentStreamData{stream: stream2})
+			Expect(framer.PopStreamFrames(1000)).To(HaveLen(2))
+			Expect(framer.GetPoppedStream(5)).To(Equal(stream1))
+			Expect(framer.GetPoppedStream(6)).To(Equal(stream2))
+			Expect(framer.GetPoppedStream(7)).To(BeNil())
+			// the next call to PopStreamFrames resets the popped streams
+			Expect(framer.PopStreamFrames(1000)).To(BeEmpty())
+			Expect(framer.GetPoppedStream(5)).To(BeNil())
+		})
+
 		It("returns retransmission frames before normal frames", func() {
 			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil)
 			framer.AddActiveStream(id1)
@@ -308,8 +407,8 @@ var _ = Describe("Stream Framer", func() {
 				Expect(fs).To(HaveLen(1))
 				minLength := fs[0].MinLength(framer.version)
 				Expect(minLength + fs[0].DataLen()).To(Equal(protocol.ByteCount(500)))
-				Expect(framer.retransmissionQueue[0].Data).To(HaveLen(int(600 - fs[0].DataLen())))
-				Expect(framer.retransmissionQueue[0].Offset).To(Equal(fs[0].DataLen()))
+				Expect(framer.cryptoRetransmissionQueue[0].Data).To(HaveLen(int(600 - fs[0].DataLen())))
+				Expect(framer.cryptoRetransmissionQueue[0].Offset).To(Equal(fs[0].DataLen()))
 			})
 
 			It("only removes a frame from the framer after returning all split parts", func() {
@@ -318,11 +417,11 @@ var _ = Describe("Stream Framer", func() {
 				framer.AddFrameForRetransmission(frame)
 				fs := framer.PopStreamFrames(500)
 				Expect(fs).To(HaveLen(1))
-				Expect(framer.retransmissionQueue).ToNot(BeEmpty())
+				Expect(framer.cryptoRetransmissionQueue).ToNot(BeEmpty())
 				fs = framer.PopStreamFrames(500)
 				Expect(fs).To(HaveLen(1))
 				Expect(fs[0].DataLen()).To(BeEquivalentTo(1))
-				Expect(framer.retransmissionQueue).To(BeEmpty())
+				Expect(framer.cryptoRetransmissionQueue).To(BeEmpty())
 			})
 		})
 	})
@@ -7,6 +7,7 @@ import (
 
 	"github.com/lucas-clemente/quic-go/internal/handshake"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
 	"github.com/lucas-clemente/quic-go/internal/wire"
 	"github.com/lucas-clemente/quic-go/qerr"
 )
@@ -26,7 +27,14 @@ type streamsMap struct {
 	closeErr           error
 	nextStreamToAccept protocol.StreamID
 
-	newStream newStreamLambda
+	numIncomingStreams  uint64
+	maxIncomingStreams  uint64
+	maxIncomingStreamID protocol.StreamID // the highest stream ID that the peer is allowed to open
+	maxOutgoingStreamID protocol.StreamID // the highest stream ID that we're allowed to open
+	blockedSent         bool              // was a STREAM_ID_BLOCKED frame sent for the current maxOutgoingStreamID
+
+	newStream         newStreamLambda
+	queueControlFrame func(wire.Frame)
 }
 
 var _ streamManager = &streamsMap{}
@@ -35,11 +43,19 @@ type newStreamLambda func(protocol.StreamID) streamI
 
 var errMapAccess = errors.New("streamsMap: Error accessing the streams map")
 
-func newStreamsMap(newStream newStreamLambda, pers protocol.Perspective) streamManager {
+func newStreamsMap(
+	newStream newStreamLambda,
+	queueControlFrame func(wire.Frame),
+	maxIncomingStreams int,
+	pers protocol.Perspective,
+) streamManager {
 	sm := streamsMap{
-		perspective: pers,
-		streams:     make(map[protocol.StreamID]streamI),
-		newStream:   newStream,
+		perspective:         pers,
+		streams:             make(map[protocol.StreamID]streamI),
+		newStream:           newStream,
+		queueControlFrame:   queueControlFrame,
+		maxIncomingStreams:  uint64(utils.Max(maxIncomingStreams, 0)),
+		maxIncomingStreamID: initialMaxIncomingStreamID(maxIncomingStreams, pers),
 	}
 	sm.nextStreamOrErrCond.L = &sm.mutex
 	sm.openStreamOrErrCond.L = &sm.mutex
@@ -56,6 +72,20 @@ func newStreamsMap(newStream newStreamLambda, pers protocol.Perspective) streamM
 	return &sm
 }
 
+// initialMaxIncomingStreamID returns the highest stream ID that the peer may open at the beginning of a connection,
+// if it is allowed to open maxIncomingStreams concurrent streams.
+func initialMaxIncomingStreamID(maxIncomingStreams int, pers protocol.Perspective) protocol.StreamID {
+	if maxIncomingStreams <= 0 {
+		return 0
+	}
+	// the first stream opened by the server is stream 2, the first stream opened by the client is stream 1
+	firstIncomingStream := protocol.StreamID(2)
+	if pers == protocol.PerspectiveServer {
+		firstIncomingStream = 1
+	}
+	return firstIncomingStream + 2*protocol.StreamID(maxIncomingStreams-1)
+}
+
 // getStreamPerspective says which side should initiate a stream
 func (m *streamsMap) streamInitiatedBy(id protocol.StreamID) protocol.Perspective {
 	if id%2 == 0 {
@@ -109,6 +139,9 @@ func (m *streamsMap) GetOrOpenStream(id protocol.StreamID) (streamI, error) {
 	if id <= m.highestStreamOpenedByPeer { // this is a peer-initiated stream that doesn't exist anymore. Must have been closed already
 		return nil, nil
 	}
+	if id > m.maxIncomingStreamID {
+		return nil, qerr.Error(qerr.TooManyOpenStreams, fmt.Sprintf("peer attempted to open stream %d (current limit: %d)", id, m.maxIncomingStreamID))
+	}
 
 	for sid := m.nextStreamID(m.highestStreamOpenedByPeer); sid <= id; sid = m.nextStreamID(sid) {
 		if _, err := m.openRemoteStream(sid); err != nil {
@@ -124,6 +157,7 @@ func (m *streamsMap) openRemoteStream(id protocol.StreamID) (streamI, error) {
 	if id+protocol.MaxNewStreamIDDelta < m.highestStreamOpenedByPeer {
 		return nil, qerr.Error(qerr.InvalidStreamID, fmt.Sprintf("attempted to open stream %d, which is a lot smaller than the highest opened stream, %d", id, m.highestStreamOpenedByPeer))
 	}
+	m.numIncomingStreams++
 	if id > m.highestStreamOpenedByPeer {
 		m.highestStreamOpenedByPeer = id
 	}
@@ -132,6 +166,13 @@ func (m *streamsMap) openRemoteStream(id protocol.StreamID) (streamI, error) {
 }
 
 func (m *streamsMap) openStreamImpl() (streamI, error) {
+	if m.nextStreamToOpen > m.maxOutgoingStreamID {
+		if !m.blockedSent {
+			m.queueControlFrame(&wire.StreamIDBlockedFrame{StreamID: m.maxOutgoingStreamID})
+			m.blockedSent = true
+		}
+		return nil, qerr.TooManyOpenStreams
+	}
 	s := m.newStream(m.nextStreamToOpen)
 	m.nextStreamToOpen = m.nextStreamID(m.nextStreamToOpen)
 	return s, m.putStream(s)
@@ -196,10 +237,59 @@ func (m *streamsMap) DeleteStream(id protocol.StreamID) error {
 		return errMapAccess
 	}
 	delete(m.streams, id)
+	if m.streamInitiatedBy(id) != m.perspective {
+		m.numIncomingStreams--
+		m.maybeIncreaseMaxIncomingStreamID()
+	}
 	m.openStreamOrErrCond.Signal()
 	return nil
 }
 
+// SetMaxIncomingStreams sets the maximum number of concurrent streams that the peer is allowed to open
+func (m *streamsMap) SetMaxIncomingStreams(n int) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.maxIncomingStreams = uint64(utils.Max(n, 0))
+	m.maybeIncreaseMaxIncomingStreamID()
+	return nil
+}
+
+// maybeIncreaseMaxIncomingStreamID increases the stream ID limit for the peer,
+// such that it can open maxIncomingStreams concurrent streams.
+// The limit can never be decreased. A MAX_STREAM_ID frame is queued if the limit changed.
+func (m *streamsMap) maybeIncreaseMaxIncomingStreamID() {
+	if m.numIncomingStreams >= m.maxIncomingStreams {
+		return
+	}
+	numNewStreams := m.maxIncomingStreams - m.numIncomingStreams
+	maxStreamID := m.nextStreamID(m.highestStreamOpenedByPeer) + 2*protocol.StreamID(numNewStreams-1)
+	if maxStreamID <= m.maxIncomingStreamID {
+		return
+	}
+	m.maxIncomingStreamID = maxStreamID
+	m.queueControlFrame(&wire.MaxStreamIDFrame{StreamID: maxStreamID})
+}
+
+// HandleMaxStreamIDFrame handles a MAX_STREAM_ID frame, which allows us to open more streams
+func (m *streamsMap) HandleMaxStreamIDFrame(f *wire.MaxStreamIDFrame) error {
+	if m.streamInitiatedBy(f.StreamID) != m.perspective {
+		return qerr.Error(qerr.InvalidStreamID, fmt.Sprintf("received MAX_STREAM_ID frame for stream %d, which is opened by the peer", f.StreamID))
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.setMaxOutgoingStreamID(f.StreamID)
+	return nil
+}
+
+func (m *streamsMap) setMaxOutgoingStreamID(id protocol.StreamID) {
+	if id <= m.maxOutgoingStreamID {
+		return
+	}
+	m.maxOutgoingStreamID = id
+	m.blockedSent = false
+	m.openStreamOrErrCond.Broadcast()
+}
+
 func (m *streamsMap) putStream(s streamI) error {
 	id := s.StreamID()
 	if _, ok := m.streams[id]; ok {
@@ -223,6 +313,7 @@ func (m *streamsMap) CloseWithError(err error) {
 // TODO(#952): this won't be needed when gQUIC supports stateless handshakes
 func (m *streamsMap) UpdateLimits(params *handshake.TransportParameters) {
 	m.mutex.Lock()
+	m.setMaxOutgoingStreamID(params.MaxBidiStreamID)
 	for id, str := range m.streams {
 		str.handleMaxStreamDataFrame(&wire.MaxStreamDataFrame{
 			StreamID:   id,
@@ -1,6 +1,7 @@
 package quic
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 
@@ -36,9 +37,8 @@ type streamsMapLegacy struct {
 
 var _ streamManager = &streamsMapLegacy{}
 
-func newStreamsMapLegacy(newStream newStreamLambda, pers protocol.Perspective) streamManager {
+func newStreamsMapLegacy(newStream newStreamLambda, maxStreams uint32, pers protocol.Perspective) streamManager {
 	// add some tolerance to the maximum incoming streams value
-	maxStreams := uint32(protocol.MaxIncomingStreams)
 	maxIncomingStreams := utils.MaxUint32(
 		maxStreams+protocol.MaxStreamsMinimumIncrement,
 		uint32(float64(maxStreams)*float64(protocol.MaxStreamsMultiplier)),
@@ -220,6 +220,16 @@ func (m *streamsMapLegacy) DeleteStream(id protocol.StreamID) error {
 	return nil
 }
 
+// SetMaxIncomingStreams is not supported for gQUIC.
+// The stream limit is negotiated during the handshake, and there's no frame to change it afterwards.
+func (m *streamsMapLegacy) SetMaxIncomingStreams(int) error {
+	return errors.New("changing the stream limit is not supported for gQUIC")
+}
+
+func (m *streamsMapLegacy) HandleMaxStreamIDFrame(*wire.MaxStreamIDFrame) error {
+	return errors.New("gQUIC doesn't use MAX_STREAM_ID frames")
+}
+
 func (m *streamsMapLegacy) putStream(s streamI) error {
 	id := s.StreamID()
 	if _, ok := m.streams[id]; ok {
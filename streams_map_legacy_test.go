@@ -23,7 +23,7 @@ var _ = Describe("Streams Map (for gQUIC)", func() {
 	}
 
 	setNewStreamsMap := func(p protocol.Perspective) {
-		m = newStreamsMapLegacy(newStream, p).(*streamsMapLegacy)
+		m = newStreamsMapLegacy(newStream, protocol.DefaultMaxIncomingStreams, p).(*streamsMapLegacy)
 	}
 
 	deleteStream := func(id protocol.StreamID) {
@@ -546,4 +546,16 @@ var _ = Describe("Streams Map (for gQUIC)", func() {
 		})
 		m.UpdateLimits(&handshake.TransportParameters{StreamFlowControlWindow: 321})
 	})
+
+	Context("stream limits", func() {
+		It("uses the configured limit for incoming streams", func() {
+			m = newStreamsMapLegacy(newStream, 20, protocol.PerspectiveServer).(*streamsMapLegacy)
+			Expect(m.maxIncomingStreams).To(BeEquivalentTo(30)) // 20 + MaxStreamsMinimumIncrement
+		})
+
+		It("doesn't allow changing the limit", func() {
+			setNewStreamsMap(protocol.PerspectiveServer)
+			Expect(m.SetMaxIncomingStreams(1000)).To(MatchError("changing the stream limit is not supported for gQUIC"))
+		})
+	})
 })
@@ -7,13 +7,21 @@ import (
 	"github.com/lucas-clemente/quic-go/internal/handshake"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/internal/wire"
+	"github.com/lucas-clemente/quic-go/qerr"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
 
 var _ = Describe("Streams Map (for IETF QUIC)", func() {
-	var m *streamsMap
+	var (
+		m            *streamsMap
+		queuedFrames []wire.Frame
+	)
+
+	queueControlFrame := func(f wire.Frame) {
+		queuedFrames = append(queuedFrames, f)
+	}
 
 	newStream := func(id protocol.StreamID) streamI {
 		str := NewMockStreamI(mockCtrl)
@@ -22,7 +30,9 @@ var _ = Describe("Streams Map (for IETF QUIC)", func() {
 	}
 
 	setNewStreamsMap := func(p protocol.Perspective) {
-		m = newStreamsMap(newStream, p).(*streamsMap)
+		queuedFrames = nil
+		m = newStreamsMap(newStream, queueControlFrame, protocol.DefaultMaxIncomingStreams, p).(*streamsMap)
+		m.UpdateLimits(&handshake.TransportParameters{MaxBidiStreamID: 1000})
 	}
 
 	deleteStream := func(id protocol.StreamID) {
@@ -412,4 +422,132 @@ var _ = Describe("Streams Map (for IETF QUIC)", func() {
 		})
 		m.UpdateLimits(&handshake.TransportParameters{StreamFlowControlWindow: 321})
 	})
+
+	Context("stream limits", func() {
+		It("calculates the initial limit for incoming streams", func() {
+			Expect(initialMaxIncomingStreamID(3, protocol.PerspectiveServer)).To(Equal(protocol.StreamID(5)))
+			Expect(initialMaxIncomingStreamID(3, protocol.PerspectiveClient)).To(Equal(protocol.StreamID(6)))
+			Expect(initialMaxIncomingStreamID(0, protocol.PerspectiveServer)).To(BeZero())
+		})
+
+		Context("for incoming streams", func() {
+			BeforeEach(func() {
+				queuedFrames = nil
+				m = newStreamsMap(newStream, queueControlFrame, 3, protocol.PerspectiveServer).(*streamsMap)
+			})
+
+			It("rejects streams above the limit", func() {
+				_, err := m.GetOrOpenStream(5)
+				Expect(err).ToNot(HaveOccurred())
+				_, err = m.GetOrOpenStream(7)
+				Expect(err).To(MatchError(qerr.Error(qerr.TooManyOpenStreams, "peer attempted to open stream 7 (current limit: 5)")))
+			})
+
+			It("increases the limit when a stream is closed", func() {
+				_, err := m.GetOrOpenStream(5) // opens stream 1, 3 and 5
+				Expect(err).ToNot(HaveOccurred())
+				Expect(queuedFrames).To(BeEmpty())
+				deleteStream(3)
+				Expect(queuedFrames).To(Equal([]wire.Frame{&wire.MaxStreamIDFrame{StreamID: 7}}))
+				_, err = m.GetOrOpenStream(7)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("increases the limit", func() {
+				_, err := m.GetOrOpenStream(3)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(m.SetMaxIncomingStreams(5)).To(Succeed())
+				Expect(queuedFrames).To(Equal([]wire.Frame{&wire.MaxStreamIDFrame{StreamID: 9}}))
+				_, err = m.GetOrOpenStream(9)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("decreases the limit, as soon as streams are closed", func() {
+				_, err := m.GetOrOpenStream(3) // opens stream 1 and 3
+				Expect(err).ToNot(HaveOccurred())
+				Expect(m.SetMaxIncomingStreams(1)).To(Succeed())
+				// the peer is still allowed to open stream 5
+				_, err = m.GetOrOpenStream(5)
+				Expect(err).ToNot(HaveOccurred())
+				deleteStream(1)
+				deleteStream(3)
+				Expect(queuedFrames).To(BeEmpty())
+				deleteStream(5)
+				Expect(queuedFrames).To(Equal([]wire.Frame{&wire.MaxStreamIDFrame{StreamID: 7}}))
+				_, err = m.GetOrOpenStream(9)
+				Expect(err).To(MatchError(qerr.Error(qerr.TooManyOpenStreams, "peer attempted to open stream 9 (current limit: 7)")))
+			})
+
+			It("doesn't allow any streams if the limit is 0", func() {
+				m = newStreamsMap(newStream, queueControlFrame, 0, protocol.PerspectiveServer).(*streamsMap)
+				_, err := m.GetOrOpenStream(1)
+				Expect(err).To(MatchError(qerr.Error(qerr.TooManyOpenStreams, "peer attempted to open stream 1 (current limit: 0)")))
+			})
+		})
+
+		Context("for outgoing streams", func() {
+			BeforeEach(func() {
+				queuedFrames = nil
+				m = newStreamsMap(newStream, queueControlFrame, protocol.DefaultMaxIncomingStreams, protocol.PerspectiveServer).(*streamsMap)
+				m.UpdateLimits(&handshake.TransportParameters{MaxBidiStreamID: 4})
+			})
+
+			It("doesn't open streams before the limit is known", func() {
+				m = newStreamsMap(newStream, queueControlFrame, protocol.DefaultMaxIncomingStreams, protocol.PerspectiveServer).(*streamsMap)
+				_, err := m.OpenStream()
+				Expect(err).To(MatchError(qerr.TooManyOpenStreams))
+			})
+
+			It("sends a STREAM_ID_BLOCKED frame when the limit is reached", func() {
+				_, err := m.OpenStream()
+				Expect(err).ToNot(HaveOccurred())
+				_, err = m.OpenStream()
+				Expect(err).ToNot(HaveOccurred())
+				_, err = m.OpenStream()
+				Expect(err).To(MatchError(qerr.TooManyOpenStreams))
+				Expect(queuedFrames).To(Equal([]wire.Frame{&wire.StreamIDBlockedFrame{StreamID: 4}}))
+				// only send one STREAM_ID_BLOCKED frame per limit
+				_, err = m.OpenStream()
+				Expect(err).To(MatchError(qerr.TooManyOpenStreams))
+				Expect(queuedFrames).To(HaveLen(1))
+			})
+
+			It("opens more streams when receiving a MAX_STREAM_ID frame", func() {
+				m.OpenStream()
+				m.OpenStream()
+				Expect(m.HandleMaxStreamIDFrame(&wire.MaxStreamIDFrame{StreamID: 6})).To(Succeed())
+				str, err := m.OpenStream()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(str.StreamID()).To(Equal(protocol.StreamID(6)))
+			})
+
+			It("unblocks OpenStreamSync when receiving a MAX_STREAM_ID frame", func() {
+				m.OpenStream()
+				m.OpenStream()
+				var str Stream
+				done := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					var err error
+					str, err = m.OpenStreamSync()
+					Expect(err).ToNot(HaveOccurred())
+					close(done)
+				}()
+				Consistently(done).ShouldNot(BeClosed())
+				Expect(m.HandleMaxStreamIDFrame(&wire.MaxStreamIDFrame{StreamID: 6})).To(Succeed())
+				Eventually(done).Should(BeClosed())
+				Expect(str.StreamID()).To(Equal(protocol.StreamID(6)))
+			})
+
+			It("ignores MAX_STREAM_ID frames that decrease the limit", func() {
+				Expect(m.HandleMaxStreamIDFrame(&wire.MaxStreamIDFrame{StreamID: 2})).To(Succeed())
+				Expect(m.maxOutgoingStreamID).To(Equal(protocol.StreamID(4)))
+			})
+
+			It("errors when receiving a MAX_STREAM_ID frame for a stream opened by the peer", func() {
+				err := m.HandleMaxStreamIDFrame(&wire.MaxStreamIDFrame{StreamID: 5})
+				Expect(err).To(MatchError(qerr.Error(qerr.InvalidStreamID, "received MAX_STREAM_ID frame for stream 5, which is opened by the peer")))
+			})
+		})
+	})
 })